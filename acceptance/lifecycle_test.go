@@ -165,7 +165,7 @@ var _ = Describe("bosh", func() {
 		multiWriter := io.MultiWriter(stdout, GinkgoWriter)
 
 		_, _, exitCode, err := sshCmdRunner.RunStreamingCommand(multiWriter, cmdEnv, testEnv.Path("bosh"),
-			"delete-env", "--tty", testEnv.Path("test-manifest.yml"))
+			"delete-env", "-n", "--tty", testEnv.Path("test-manifest.yml"))
 
 		Expect(err).ToNot(HaveOccurred())
 		Expect(exitCode).To(Equal(0))
@@ -286,7 +286,7 @@ var _ = Describe("bosh", func() {
 			flushLog(cmdEnv["BOSH_LOG_PATH"])
 
 			// quietly delete the deployment
-			_, _, exitCode, err := sshCmdRunner.RunCommand(quietCmdEnv, testEnv.Path("bosh"), "delete-env", "--tty", testEnv.Path("test-compiled-manifest.yml"))
+			_, _, exitCode, err := sshCmdRunner.RunCommand(quietCmdEnv, testEnv.Path("bosh"), "delete-env", "-n", "--tty", testEnv.Path("test-compiled-manifest.yml"))
 			if exitCode != 0 || err != nil {
 				// only flush the delete log if the delete failed
 				flushLog(quietCmdEnv["BOSH_LOG_PATH"])
@@ -340,11 +340,13 @@ var _ = Describe("bosh", func() {
 			Expect(deployingSteps[3]).To(MatchRegexp("^  Attaching disk '.*' to VM '.*'" + stageFinishedPattern))
 			Expect(deployingSteps[4]).To(MatchRegexp("^  Rendering job templates" + stageFinishedPattern))
 
-			for _, line := range deployingSteps[5 : numDeployingSteps-3] {
+			for _, line := range deployingSteps[5 : numDeployingSteps-5] {
 				Expect(line).To(MatchRegexp("^  Compiling package '.*/.*'" + stageCompiledPackageSkippedPattern))
 			}
 
-			Expect(deployingSteps[numDeployingSteps-3]).To(MatchRegexp("^  Updating instance 'dummy_compiled_job/0'" + stageFinishedPattern))
+			Expect(deployingSteps[numDeployingSteps-5]).To(MatchRegexp("^  Updating instance 'dummy_compiled_job/0'" + stageFinishedPattern))
+			Expect(deployingSteps[numDeployingSteps-4]).To(MatchRegexp("^  Running the pre-start scripts 'dummy_compiled_job/0'" + stageFinishedPattern))
+			Expect(deployingSteps[numDeployingSteps-3]).To(MatchRegexp("^  Starting jobs on instance 'dummy_compiled_job/0'" + stageFinishedPattern))
 			Expect(deployingSteps[numDeployingSteps-2]).To(MatchRegexp("^  Waiting for instance 'dummy_compiled_job/0' to be running" + stageFinishedPattern))
 			Expect(deployingSteps[numDeployingSteps-1]).To(MatchRegexp("^  Running the post-start scripts 'dummy_compiled_job/0'" + stageFinishedPattern))
 
@@ -386,7 +388,7 @@ var _ = Describe("bosh", func() {
 				append(
 					[]string{
 						testEnv.Path("bosh"),
-						"delete-env", "--tty", testEnv.Path("test-compiled-manifest.yml"),
+						"delete-env", "-n", "--tty", testEnv.Path("test-compiled-manifest.yml"),
 					},
 					extraDeployArgs...,
 				)...,
@@ -446,7 +448,7 @@ var _ = Describe("bosh", func() {
 			flushLog(cmdEnv["BOSH_LOG_PATH"])
 
 			// quietly delete the deployment
-			_, _, exitCode, err := sshCmdRunner.RunCommand(quietCmdEnv, testEnv.Path("bosh"), "delete-env", "--tty", testEnv.Path("test-manifest.yml"))
+			_, _, exitCode, err := sshCmdRunner.RunCommand(quietCmdEnv, testEnv.Path("bosh"), "delete-env", "-n", "--tty", testEnv.Path("test-manifest.yml"))
 			if exitCode != 0 || err != nil {
 				// only flush the delete log if the delete failed
 				flushLog(quietCmdEnv["BOSH_LOG_PATH"])
@@ -501,11 +503,13 @@ var _ = Describe("bosh", func() {
 			Expect(deployingSteps[3]).To(MatchRegexp("^  Attaching disk '.*' to VM '.*'" + stageFinishedPattern))
 			Expect(deployingSteps[4]).To(MatchRegexp("^  Rendering job templates" + stageFinishedPattern))
 
-			for _, line := range deployingSteps[5 : numDeployingSteps-3] {
+			for _, line := range deployingSteps[5 : numDeployingSteps-5] {
 				Expect(line).To(MatchRegexp("^  Compiling package '.*/.*'" + stageFinishedPattern))
 			}
 
-			Expect(deployingSteps[numDeployingSteps-3]).To(MatchRegexp("^  Updating instance 'dummy_job/0'" + stageFinishedPattern))
+			Expect(deployingSteps[numDeployingSteps-5]).To(MatchRegexp("^  Updating instance 'dummy_job/0'" + stageFinishedPattern))
+			Expect(deployingSteps[numDeployingSteps-4]).To(MatchRegexp("^  Running the pre-start scripts 'dummy_job/0'" + stageFinishedPattern))
+			Expect(deployingSteps[numDeployingSteps-3]).To(MatchRegexp("^  Starting jobs on instance 'dummy_job/0'" + stageFinishedPattern))
 			Expect(deployingSteps[numDeployingSteps-2]).To(MatchRegexp("^  Waiting for instance 'dummy_job/0' to be running" + stageFinishedPattern))
 			Expect(deployingSteps[numDeployingSteps-1]).To(MatchRegexp("^  Running the post-start scripts 'dummy_job/0'" + stageFinishedPattern))
 
@@ -611,7 +615,7 @@ var _ = Describe("bosh", func() {
 			flushLog(cmdEnv["BOSH_LOG_PATH"])
 
 			// quietly delete the deployment
-			_, _, exitCode, err := sshCmdRunner.RunCommand(quietCmdEnv, testEnv.Path("bosh"), "delete-env", "--tty", testEnv.Path("test-manifest.yml"))
+			_, _, exitCode, err := sshCmdRunner.RunCommand(quietCmdEnv, testEnv.Path("bosh"), "delete-env", "-n", "--tty", testEnv.Path("test-manifest.yml"))
 			if exitCode != 0 || err != nil {
 				// only flush the delete log if the delete failed
 				flushLog(quietCmdEnv["BOSH_LOG_PATH"])
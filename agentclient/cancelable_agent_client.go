@@ -0,0 +1,227 @@
+package agentclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	biagentclient "github.com/cloudfoundry/bosh-agent/agentclient"
+	"github.com/cloudfoundry/bosh-agent/agentclient/applyspec"
+	bihttpagent "github.com/cloudfoundry/bosh-agent/agentclient/http"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// CallTimeouts limits how long agent calls may take; zero means no limit
+type CallTimeouts struct {
+	// Request limits calls the agent answers right away, e.g. ping and get_state
+	Request time.Duration
+
+	// Task limits calls the agent runs as tasks that are polled until they finish,
+	// e.g. apply, start, stop and mounting disks.
+	// Compiling packages, migrating disks and running scripts are not limited
+	// since they take as long as the package, the disk contents or the job need.
+	Task time.Duration
+}
+
+func NewDefaultCallTimeouts() CallTimeouts {
+	return CallTimeouts{
+		Request: 1 * time.Minute,
+		Task:    10 * time.Minute,
+	}
+}
+
+// InterruptedError is returned by agent calls that were abandoned
+// because the context was canceled or the call timed out
+type InterruptedError struct {
+	Method  string
+	Timeout time.Duration
+}
+
+func (e InterruptedError) Error() string {
+	if e.Timeout > 0 {
+		return fmt.Sprintf("Agent call '%s' did not finish within %s", e.Method, e.Timeout)
+	}
+
+	return fmt.Sprintf("Agent call '%s' was interrupted", e.Method)
+}
+
+// LogsFetcher is implemented by agent clients that can ask the agent to put a tarball
+// of its logs into its blobstore, which the agent client interface does not cover
+type LogsFetcher interface {
+	// FetchLogs bundles job or agent logs matching filters, or all of them without filters;
+	// the returned digest is empty when the agent does not report one
+	FetchLogs(logType string, filters []string) (blobstoreID string, sha1 string, err error)
+}
+
+type asyncTaskSender interface {
+	SendAsyncTaskMessage(method string, arguments []interface{}) (map[string]interface{}, error)
+}
+
+type cancelableAgentClientFactory struct {
+	factory  bihttpagent.AgentClientFactory
+	ctx      context.Context
+	timeouts CallTimeouts
+}
+
+// NewCancelableAgentClientFactory returns a factory of agent clients whose calls return
+// an InterruptedError once ctx is canceled or the call takes longer than its timeout.
+// The HTTP request of an abandoned call is not aborted; its result is discarded.
+func NewCancelableAgentClientFactory(
+	factory bihttpagent.AgentClientFactory,
+	ctx context.Context,
+	timeouts CallTimeouts,
+) bihttpagent.AgentClientFactory {
+	return cancelableAgentClientFactory{
+		factory:  factory,
+		ctx:      ctx,
+		timeouts: timeouts,
+	}
+}
+
+func (f cancelableAgentClientFactory) NewAgentClient(directorID, mbusURL, caCert string) (biagentclient.AgentClient, error) {
+	client, err := f.factory.NewAgentClient(directorID, mbusURL, caCert)
+	if err != nil {
+		return nil, err
+	}
+
+	return cancelableAgentClient{client: client, ctx: f.ctx, timeouts: f.timeouts}, nil
+}
+
+type cancelableAgentClient struct {
+	client   biagentclient.AgentClient
+	ctx      context.Context
+	timeouts CallTimeouts
+}
+
+func (c cancelableAgentClient) Ping() (string, error) {
+	var response string
+	err := c.call("ping", c.timeouts.Request, func() (err error) {
+		response, err = c.client.Ping()
+		return
+	})
+	return response, err
+}
+
+func (c cancelableAgentClient) Stop() error {
+	return c.call("stop", c.timeouts.Task, c.client.Stop)
+}
+
+func (c cancelableAgentClient) Apply(spec applyspec.ApplySpec) error {
+	return c.call("apply", c.timeouts.Task, func() error { return c.client.Apply(spec) })
+}
+
+func (c cancelableAgentClient) Start() error {
+	return c.call("start", c.timeouts.Task, c.client.Start)
+}
+
+func (c cancelableAgentClient) GetState() (biagentclient.AgentState, error) {
+	var state biagentclient.AgentState
+	err := c.call("get_state", c.timeouts.Request, func() (err error) {
+		state, err = c.client.GetState()
+		return
+	})
+	return state, err
+}
+
+func (c cancelableAgentClient) MountDisk(diskCID string) error {
+	return c.call("mount_disk", c.timeouts.Task, func() error { return c.client.MountDisk(diskCID) })
+}
+
+func (c cancelableAgentClient) UnmountDisk(diskCID string) error {
+	return c.call("unmount_disk", c.timeouts.Task, func() error { return c.client.UnmountDisk(diskCID) })
+}
+
+func (c cancelableAgentClient) ListDisk() ([]string, error) {
+	var diskCIDs []string
+	err := c.call("list_disk", c.timeouts.Request, func() (err error) {
+		diskCIDs, err = c.client.ListDisk()
+		return
+	})
+	return diskCIDs, err
+}
+
+func (c cancelableAgentClient) MigrateDisk() error {
+	return c.call("migrate_disk", 0, c.client.MigrateDisk)
+}
+
+func (c cancelableAgentClient) CompilePackage(packageSource biagentclient.BlobRef, compiledPackageDependencies []biagentclient.BlobRef) (biagentclient.BlobRef, error) {
+	var compiledPackageRef biagentclient.BlobRef
+	err := c.call("compile_package", 0, func() (err error) {
+		compiledPackageRef, err = c.client.CompilePackage(packageSource, compiledPackageDependencies)
+		return
+	})
+	return compiledPackageRef, err
+}
+
+func (c cancelableAgentClient) DeleteARPEntries(ips []string) error {
+	return c.call("delete_arp_entries", c.timeouts.Request, func() error { return c.client.DeleteARPEntries(ips) })
+}
+
+func (c cancelableAgentClient) SyncDNS(blobID, sha1 string, version uint64) (string, error) {
+	var response string
+	err := c.call("sync_dns", c.timeouts.Request, func() (err error) {
+		response, err = c.client.SyncDNS(blobID, sha1, version)
+		return
+	})
+	return response, err
+}
+
+func (c cancelableAgentClient) RunScript(scriptName string, options map[string]interface{}) error {
+	return c.call("run_script", 0, func() error { return c.client.RunScript(scriptName, options) })
+}
+
+func (c cancelableAgentClient) FetchLogs(logType string, filters []string) (string, string, error) {
+	sender, ok := c.client.(asyncTaskSender)
+	if !ok {
+		return "", "", bosherr.Error("Fetching logs is not supported by the agent client")
+	}
+
+	var value map[string]interface{}
+	err := c.call("fetch_logs", c.timeouts.Task, func() (err error) {
+		value, err = sender.SendAsyncTaskMessage("fetch_logs", []interface{}{logType, filters})
+		return
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	blobstoreID, ok := value["blobstore_id"].(string)
+	if !ok {
+		return "", "", bosherr.Errorf("Unable to parse 'fetch_logs' response from the agent: %#v", value)
+	}
+
+	sha1, _ := value["sha1"].(string)
+
+	return blobstoreID, sha1, nil
+}
+
+// call runs f unless ctx is already canceled and waits until it returns,
+// ctx is canceled or the timeout passed
+func (c cancelableAgentClient) call(method string, timeout time.Duration, f func() error) error {
+	if c.ctx.Err() != nil {
+		return InterruptedError{Method: method}
+	}
+
+	// Buffered so that an abandoned call does not block forever
+	errCh := make(chan error, 1)
+
+	go func() { errCh <- f() }()
+
+	var timeoutCh <-chan time.Time
+
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		timeoutCh = timer.C
+	}
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-c.ctx.Done():
+		return InterruptedError{Method: method}
+	case <-timeoutCh:
+		return InterruptedError{Method: method, Timeout: timeout}
+	}
+}
@@ -0,0 +1,217 @@
+package agentclient_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	biagentclient "github.com/cloudfoundry/bosh-agent/agentclient"
+	"github.com/cloudfoundry/bosh-agent/agentclient/applyspec"
+	mock_httpagent "github.com/cloudfoundry/bosh-agent/agentclient/http/mocks"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/agentclient"
+	mock_agentclient "github.com/cloudfoundry/bosh-cli/agentclient/mocks"
+)
+
+type taskSendingAgentClient struct {
+	*mock_agentclient.MockAgentClient
+
+	method    string
+	arguments []interface{}
+
+	value map[string]interface{}
+	err   error
+}
+
+func (c *taskSendingAgentClient) SendAsyncTaskMessage(method string, arguments []interface{}) (map[string]interface{}, error) {
+	c.method = method
+	c.arguments = arguments
+	return c.value, c.err
+}
+
+var _ = Describe("CancelableAgentClientFactory", func() {
+	var (
+		mockCtrl               *gomock.Controller
+		mockAgentClientFactory *mock_httpagent.MockAgentClientFactory
+		mockAgentClient        *mock_agentclient.MockAgentClient
+		ctx                    context.Context
+		cancel                 context.CancelFunc
+		timeouts               CallTimeouts
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockAgentClientFactory = mock_httpagent.NewMockAgentClientFactory(mockCtrl)
+		mockAgentClient = mock_agentclient.NewMockAgentClient(mockCtrl)
+
+		ctx, cancel = context.WithCancel(context.Background())
+		timeouts = CallTimeouts{Request: time.Minute, Task: time.Minute}
+	})
+
+	AfterEach(func() {
+		cancel()
+		mockCtrl.Finish()
+	})
+
+	newAgentClient := func() biagentclient.AgentClient {
+		mockAgentClientFactory.EXPECT().NewAgentClient("fake-director-id", "fake-mbus-url", "fake-ca-cert").Return(mockAgentClient, nil)
+
+		client, err := NewCancelableAgentClientFactory(mockAgentClientFactory, ctx, timeouts).NewAgentClient(
+			"fake-director-id", "fake-mbus-url", "fake-ca-cert")
+		Expect(err).ToNot(HaveOccurred())
+
+		return client
+	}
+
+	It("returns errors of the factory", func() {
+		mockAgentClientFactory.EXPECT().NewAgentClient("fake-director-id", "fake-mbus-url", "").Return(nil, errors.New("fake-err"))
+
+		_, err := NewCancelableAgentClientFactory(mockAgentClientFactory, ctx, timeouts).NewAgentClient(
+			"fake-director-id", "fake-mbus-url", "")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("fake-err"))
+	})
+
+	It("returns the results of calls that finish", func() {
+		client := newAgentClient()
+
+		mockAgentClient.EXPECT().Ping().Return("pong", nil)
+		mockAgentClient.EXPECT().Apply(applyspec.ApplySpec{Deployment: "fake-deployment"}).Return(errors.New("fake-apply-err"))
+		mockAgentClient.EXPECT().GetState().Return(biagentclient.AgentState{JobState: "running"}, nil)
+
+		response, err := client.Ping()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response).To(Equal("pong"))
+
+		err = client.Apply(applyspec.ApplySpec{Deployment: "fake-deployment"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("fake-apply-err"))
+
+		state, err := client.GetState()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state).To(Equal(biagentclient.AgentState{JobState: "running"}))
+	})
+
+	It("returns once the context is canceled, naming the interrupted call", func() {
+		client := newAgentClient()
+
+		unblock := make(chan struct{})
+		defer close(unblock)
+
+		mockAgentClient.EXPECT().Apply(gomock.Any()).Do(func(applyspec.ApplySpec) { <-unblock }).Return(nil)
+
+		go func() {
+			defer GinkgoRecover()
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+
+		err := client.Apply(applyspec.ApplySpec{})
+		Expect(err).To(Equal(InterruptedError{Method: "apply"}))
+		Expect(err.Error()).To(Equal("Agent call 'apply' was interrupted"))
+	})
+
+	It("does not make calls once the context is canceled", func() {
+		client := newAgentClient()
+
+		cancel()
+
+		err := client.Start()
+		Expect(err).To(Equal(InterruptedError{Method: "start"}))
+	})
+
+	It("returns once a call takes longer than its timeout", func() {
+		timeouts = CallTimeouts{Request: 10 * time.Millisecond, Task: time.Minute}
+		client := newAgentClient()
+
+		unblock := make(chan struct{})
+		defer close(unblock)
+
+		mockAgentClient.EXPECT().Ping().Do(func() { <-unblock }).Return("pong", nil)
+
+		_, err := client.Ping()
+		Expect(err).To(Equal(InterruptedError{Method: "ping", Timeout: 10 * time.Millisecond}))
+		Expect(err.Error()).To(Equal("Agent call 'ping' did not finish within 10ms"))
+	})
+
+	It("does not limit compiling packages", func() {
+		timeouts = CallTimeouts{Request: time.Nanosecond, Task: time.Nanosecond}
+		client := newAgentClient()
+
+		mockAgentClient.EXPECT().CompilePackage(biagentclient.BlobRef{Name: "fake-package"}, nil).Do(
+			func(biagentclient.BlobRef, []biagentclient.BlobRef) { time.Sleep(10 * time.Millisecond) },
+		).Return(biagentclient.BlobRef{Name: "fake-compiled-package"}, nil)
+
+		compiledPackageRef, err := client.CompilePackage(biagentclient.BlobRef{Name: "fake-package"}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(compiledPackageRef).To(Equal(biagentclient.BlobRef{Name: "fake-compiled-package"}))
+	})
+
+	It("does not limit migrating disks and running scripts", func() {
+		timeouts = CallTimeouts{Request: time.Nanosecond, Task: time.Nanosecond}
+		client := newAgentClient()
+
+		mockAgentClient.EXPECT().MigrateDisk().Do(func() { time.Sleep(10 * time.Millisecond) }).Return(nil)
+		mockAgentClient.EXPECT().RunScript("pre-start", map[string]interface{}{}).Do(
+			func(string, map[string]interface{}) { time.Sleep(10 * time.Millisecond) },
+		).Return(nil)
+
+		Expect(client.MigrateDisk()).To(Succeed())
+		Expect(client.RunScript("pre-start", map[string]interface{}{})).To(Succeed())
+	})
+
+	Describe("FetchLogs", func() {
+		var agentClient *taskSendingAgentClient
+
+		BeforeEach(func() {
+			agentClient = &taskSendingAgentClient{MockAgentClient: mockAgentClient}
+		})
+
+		newLogsFetcher := func() LogsFetcher {
+			mockAgentClientFactory.EXPECT().NewAgentClient("fake-director-id", "fake-mbus-url", "").Return(agentClient, nil)
+
+			client, err := NewCancelableAgentClientFactory(mockAgentClientFactory, ctx, timeouts).NewAgentClient(
+				"fake-director-id", "fake-mbus-url", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			return client.(LogsFetcher)
+		}
+
+		It("sends 'fetch_logs' and returns the blob of the logs", func() {
+			agentClient.value = map[string]interface{}{"blobstore_id": "fake-blob-id", "sha1": "fake-sha1"}
+
+			blobstoreID, sha1, err := newLogsFetcher().FetchLogs("job", []string{"fake-job/**/*"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(blobstoreID).To(Equal("fake-blob-id"))
+			Expect(sha1).To(Equal("fake-sha1"))
+
+			Expect(agentClient.method).To(Equal("fetch_logs"))
+			Expect(agentClient.arguments).To(Equal([]interface{}{"job", []string{"fake-job/**/*"}}))
+		})
+
+		It("returns an error when the response has no blob", func() {
+			agentClient.value = map[string]interface{}{}
+
+			_, _, err := newLogsFetcher().FetchLogs("agent", nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unable to parse 'fetch_logs' response from the agent"))
+		})
+
+		It("returns an error when sending fails", func() {
+			agentClient.err = errors.New("fake-err")
+
+			_, _, err := newLogsFetcher().FetchLogs("agent", nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("fake-err"))
+		})
+
+		It("returns an error when the agent client cannot send tasks", func() {
+			_, _, err := newAgentClient().(LogsFetcher).FetchLogs("agent", nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Fetching logs is not supported by the agent client"))
+		})
+	})
+})
@@ -1,9 +1,13 @@
 package blobstore
 
 import (
+	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 
+	biretry "github.com/cloudfoundry/bosh-cli/retry"
+	biui "github.com/cloudfoundry/bosh-cli/ui"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
@@ -30,15 +34,26 @@ type blobstore struct {
 	davClient     DavCLIClient
 	uuidGenerator boshuuid.Generator
 	fs            boshsys.FileSystem
+	retrier       biretry.Retrier
+	progress      biui.ProgressReporter
 	logger        boshlog.Logger
 	logTag        string
 }
 
-func NewBlobstore(davClient DavCLIClient, uuidGenerator boshuuid.Generator, fs boshsys.FileSystem, logger boshlog.Logger) Blobstore {
+func NewBlobstore(
+	davClient DavCLIClient,
+	uuidGenerator boshuuid.Generator,
+	fs boshsys.FileSystem,
+	retrier biretry.Retrier,
+	progress biui.ProgressReporter,
+	logger boshlog.Logger,
+) Blobstore {
 	return &blobstore{
 		davClient:     davClient,
 		uuidGenerator: uuidGenerator,
 		fs:            fs,
+		retrier:       retrier,
+		progress:      progress,
 		logger:        logger,
 		logTag:        "blobstore",
 	}
@@ -54,9 +69,20 @@ func (b *blobstore) Get(blobID string) (LocalBlob, error) {
 
 	b.logger.Debug(b.logTag, "Downloading blob %s to %s", blobID, destinationPath)
 
+	err = b.retry(fmt.Sprintf("download of blob %s", blobID), func() error {
+		return b.download(blobID, destinationPath)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLocalBlob(destinationPath, b.fs, b.logger), nil
+}
+
+func (b *blobstore) download(blobID, destinationPath string) error {
 	readCloser, err := b.davClient.Get(blobID)
 	if err != nil {
-		return nil, bosherr.WrapErrorf(err, "Getting blob %s from blobstore", blobID)
+		return bosherr.WrapErrorf(err, "Getting blob %s from blobstore", blobID)
 	}
 	defer func() {
 		if err = readCloser.Close(); err != nil {
@@ -66,15 +92,20 @@ func (b *blobstore) Get(blobID string) (LocalBlob, error) {
 
 	targetFile, err := b.fs.OpenFile(destinationPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
 	if err != nil {
-		return nil, bosherr.WrapErrorf(err, "Opening file for blob at %s", destinationPath)
+		return bosherr.WrapErrorf(err, "Opening file for blob at %s", destinationPath)
 	}
+	defer func() {
+		if err = targetFile.Close(); err != nil {
+			b.logger.Warn(b.logTag, "Couldn't close blob file: %s", err.Error())
+		}
+	}()
 
 	_, err = io.Copy(targetFile, readCloser)
 	if err != nil {
-		return nil, bosherr.WrapErrorf(err, "Saving blob to %s", destinationPath)
+		return bosherr.WrapErrorf(err, "Saving blob to %s", destinationPath)
 	}
 
-	return NewLocalBlob(destinationPath, b.fs, b.logger), nil
+	return nil
 }
 
 func (b *blobstore) Add(sourcePath string) (string, error) {
@@ -100,7 +131,21 @@ func (b *blobstore) Add(sourcePath string) (string, error) {
 		return "", bosherr.WrapErrorf(err, "Getting fileInfo from %s", sourcePath)
 	}
 
-	err = b.davClient.Put(blobID, file, fileInfo.Size())
+	if multipartClient, ok := b.davClient.(MultipartDavCLIClient); ok && multipartClient.PartSize() > 0 && fileInfo.Size() > multipartClient.PartSize() {
+		err = b.putMultipart(multipartClient, blobID, file, fileInfo.Size())
+		if err != nil {
+			return "", bosherr.WrapErrorf(err, "Putting file '%s' into blobstore in parts as blobID '%s'", sourcePath, blobID)
+		}
+
+		return blobID, nil
+	}
+
+	err = b.retry(fmt.Sprintf("upload of blob %s", blobID), func() error {
+		// Every attempt reads the file from the start
+		content := ioutil.NopCloser(b.progress.ProgressReader(
+			fmt.Sprintf("Uploading blob %s", blobID), fileInfo.Size(), io.NewSectionReader(file, 0, fileInfo.Size())))
+		return b.davClient.Put(blobID, content, fileInfo.Size())
+	})
 	if err != nil {
 		return "", bosherr.WrapErrorf(err, "Putting file '%s' into blobstore (via DAVClient) as blobID '%s'", sourcePath, blobID)
 	}
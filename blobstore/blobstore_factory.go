@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"net/url"
 
+	biretry "github.com/cloudfoundry/bosh-cli/retry"
+	biui "github.com/cloudfoundry/bosh-cli/ui"
 	boshdavcli "github.com/cloudfoundry/bosh-davcli/client"
 	boshdavcliconf "github.com/cloudfoundry/bosh-davcli/config"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
@@ -20,13 +22,26 @@ type Factory interface {
 type blobstoreFactory struct {
 	uuidGenerator boshuuid.Generator
 	fs            boshsys.FileSystem
+	retrier       biretry.Retrier
+	partSize      int64
+	progress      biui.ProgressReporter
 	logger        boshlog.Logger
 }
 
-func NewBlobstoreFactory(uuidGenerator boshuuid.Generator, fs boshsys.FileSystem, logger boshlog.Logger) Factory {
+func NewBlobstoreFactory(
+	uuidGenerator boshuuid.Generator,
+	fs boshsys.FileSystem,
+	retrier biretry.Retrier,
+	partSize int64,
+	progress biui.ProgressReporter,
+	logger boshlog.Logger,
+) Factory {
 	return blobstoreFactory{
 		uuidGenerator: uuidGenerator,
 		fs:            fs,
+		retrier:       retrier,
+		partSize:      partSize,
+		progress:      progress,
 		logger:        logger,
 	}
 }
@@ -37,13 +52,19 @@ func (f blobstoreFactory) Create(blobstoreURL string, httpClient *http.Client) (
 		return nil, bosherr.WrapError(err, "Creating blobstore config")
 	}
 
-	davClient := boshdavcli.NewClient(boshdavcliconf.Config{
+	davConfig := boshdavcliconf.Config{
 		Endpoint: fmt.Sprintf("%s/blobs", blobstoreConfig.Endpoint),
 		User:     blobstoreConfig.Username,
 		Password: blobstoreConfig.Password,
-	}, httpClient, f.logger)
+	}
+
+	// Uploading in parts needs a blobstore that accepts ranged PUT requests, so it is opt-in
+	var davClient DavCLIClient = boshdavcli.NewClient(davConfig, httpClient, f.logger)
+	if f.partSize > 0 {
+		davClient = NewMultipartDavClient(davConfig, httpClient, f.partSize, f.logger)
+	}
 
-	return NewBlobstore(davClient, f.uuidGenerator, f.fs, f.logger), nil
+	return NewBlobstore(davClient, f.uuidGenerator, f.fs, f.retrier, f.progress, f.logger), nil
 }
 
 func (f blobstoreFactory) parseBlobstoreURL(blobstoreURL string) (Config, error) {
@@ -4,6 +4,12 @@ import (
 	. "github.com/cloudfoundry/bosh-cli/blobstore"
 
 	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	biretry "github.com/cloudfoundry/bosh-cli/retry"
+	biui "github.com/cloudfoundry/bosh-cli/ui"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -21,6 +27,8 @@ var _ = Describe("BlobstoreFactory", func() {
 		fakeUUIDGenerator *fakeuuid.FakeGenerator
 		httpClient        *http.Client
 		fs                *fakesys.FakeFileSystem
+		timeService       *fakeclock.FakeClock
+		retrier           biretry.Retrier
 		logger            boshlog.Logger
 		blobstoreFactory  Factory
 	)
@@ -30,7 +38,9 @@ var _ = Describe("BlobstoreFactory", func() {
 		fs = fakesys.NewFakeFileSystem()
 		logger = boshlog.NewLogger(boshlog.LevelNone)
 		httpClient = bihttpclient.DefaultClient
-		blobstoreFactory = NewBlobstoreFactory(fakeUUIDGenerator, fs, logger)
+		timeService = fakeclock.NewFakeClock(time.Now())
+		retrier = biretry.NewRetrier(biretry.NewDefaultConfig(), timeService, biwarn.NewWarnings(logger), logger)
+		blobstoreFactory = NewBlobstoreFactory(fakeUUIDGenerator, fs, retrier, 0, biui.NewNoopProgressReporter(), logger)
 	})
 
 	Describe("Create", func() {
@@ -43,7 +53,7 @@ var _ = Describe("BlobstoreFactory", func() {
 					User:     "fake-user",
 					Password: "fake-password",
 				}, httpClient, logger)
-				expectedBlobstore := NewBlobstore(davClient, fakeUUIDGenerator, fs, logger)
+				expectedBlobstore := NewBlobstore(davClient, fakeUUIDGenerator, fs, retrier, biui.NewNoopProgressReporter(), logger)
 				Expect(blobstore).To(Equal(expectedBlobstore))
 			})
 		})
@@ -56,7 +66,22 @@ var _ = Describe("BlobstoreFactory", func() {
 					User:     "",
 					Password: "",
 				}, httpClient, logger)
-				expectedBlobstore := NewBlobstore(davClient, fakeUUIDGenerator, fs, logger)
+				expectedBlobstore := NewBlobstore(davClient, fakeUUIDGenerator, fs, retrier, biui.NewNoopProgressReporter(), logger)
+
+				blobstore, err := blobstoreFactory.Create("https://fake-host:1234", httpClient)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(blobstore).To(Equal(expectedBlobstore))
+			})
+		})
+
+		Context("when a part size is configured", func() {
+			It("returns the blobstore uploading in parts", func() {
+				blobstoreFactory = NewBlobstoreFactory(fakeUUIDGenerator, fs, retrier, 1024, biui.NewNoopProgressReporter(), logger)
+
+				davClient := NewMultipartDavClient(boshdavcliconf.Config{
+					Endpoint: "https://fake-host:1234/blobs",
+				}, httpClient, 1024, logger)
+				expectedBlobstore := NewBlobstore(davClient, fakeUUIDGenerator, fs, retrier, biui.NewNoopProgressReporter(), logger)
 
 				blobstore, err := blobstoreFactory.Create("https://fake-host:1234", httpClient)
 				Expect(err).ToNot(HaveOccurred())
@@ -2,16 +2,28 @@ package blobstore_test
 
 import (
 	"errors"
+	"io"
 	"io/ioutil"
+	"net"
+	"os"
 	"strings"
+	"time"
 
+	"code.cloudfoundry.org/clock/fakeclock"
 	. "github.com/cloudfoundry/bosh-cli/blobstore"
+	fakeblobstore "github.com/cloudfoundry/bosh-cli/blobstore/fakes"
+	biretry "github.com/cloudfoundry/bosh-cli/retry"
+	biui "github.com/cloudfoundry/bosh-cli/ui"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 	fakeboshdavcli "github.com/cloudfoundry/bosh-davcli/client/fakes"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
 	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 )
 
 var _ = Describe("Blobstore", func() {
@@ -19,6 +31,10 @@ var _ = Describe("Blobstore", func() {
 		fakeDavClient     *fakeboshdavcli.FakeClient
 		fakeUUIDGenerator *fakeuuid.FakeGenerator
 		fs                *fakesys.FakeFileSystem
+		timeService       *sleepRecordingClock
+		retrier           biretry.Retrier
+		warnings          biwarn.Warnings
+		logger            boshlog.Logger
 		blobstore         Blobstore
 	)
 
@@ -26,9 +42,12 @@ var _ = Describe("Blobstore", func() {
 		fakeDavClient = fakeboshdavcli.NewFakeClient()
 		fakeUUIDGenerator = fakeuuid.NewFakeGenerator()
 		fs = fakesys.NewFakeFileSystem()
-		logger := boshlog.NewLogger(boshlog.LevelNone)
+		timeService = &sleepRecordingClock{FakeClock: fakeclock.NewFakeClock(time.Now())}
+		logger = boshlog.NewLogger(boshlog.LevelNone)
+		warnings = biwarn.NewWarnings(logger)
+		retrier = biretry.NewRetrier(biretry.Config{Retries: 2, Delay: 1 * time.Second, MaxDelay: 30 * time.Second}, timeService, warnings, logger)
 
-		blobstore = NewBlobstore(fakeDavClient, fakeUUIDGenerator, fs, logger)
+		blobstore = NewBlobstore(fakeDavClient, fakeUUIDGenerator, fs, retrier, biui.NewNoopProgressReporter(), logger)
 	})
 
 	Describe("Get", func() {
@@ -91,5 +110,254 @@ var _ = Describe("Blobstore", func() {
 			Expect(fakeDavClient.PutPath).To(Equal("fake-blob-id"))
 			Expect(fakeDavClient.PutContents).To(Equal("fake-contents"))
 		})
+
+		Context("when the blobstore client supports multipart uploads", func() {
+			var (
+				fakeMultipartClient *fakeblobstore.FakeMultipartDavClient
+				sourcePath          string
+			)
+
+			BeforeEach(func() {
+				fakeMultipartClient = fakeblobstore.NewFakeMultipartDavClient(5)
+				fakeUUIDGenerator.GeneratedUUID = "fake-blob-id"
+
+				osFS := boshsys.NewOsFileSystem(boshlog.NewLogger(boshlog.LevelNone))
+
+				sourceFile, err := osFS.TempFile("multipart-source")
+				Expect(err).ToNot(HaveOccurred())
+				sourcePath = sourceFile.Name()
+				Expect(sourceFile.Close()).To(Succeed())
+
+				Expect(osFS.WriteFileString(sourcePath, "fake-contents")).To(Succeed())
+
+				blobstore = NewBlobstore(fakeMultipartClient, fakeUUIDGenerator, osFS, retrier, biui.NewNoopProgressReporter(), logger)
+			})
+
+			AfterEach(func() {
+				Expect(os.RemoveAll(sourcePath)).To(Succeed())
+			})
+
+			It("uploads file in parts and completes the upload with the SHA1 of the parts", func() {
+				blobID, err := blobstore.Add(sourcePath)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(blobID).To(Equal("fake-blob-id"))
+
+				Expect(fakeMultipartClient.PutPartInputs).To(Equal([]fakeblobstore.PutPartInput{
+					{Path: "fake-blob-id", PartNumber: 1, Contents: "fake-"},
+					{Path: "fake-blob-id", PartNumber: 2, Contents: "conte"},
+					{Path: "fake-blob-id", PartNumber: 3, Contents: "nts"},
+				}))
+				Expect(fakeMultipartClient.CompletePartsPath).To(Equal("fake-blob-id"))
+				Expect(fakeMultipartClient.CompletePartsSHA1).To(Equal("978ad524a02039f261773fe93d94973ae7de6470"))
+				Expect(fakeMultipartClient.PutPath).To(BeEmpty())
+			})
+
+			It("retries only the part that failed, with the configured delays", func() {
+				fakeMultipartClient.PutPartErrs = []error{nil, errors.New("Putting part 2 of dav blob fake-blob-id: Wrong response code: 503")}
+
+				_, err := blobstore.Add(sourcePath)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeMultipartClient.PutPartInputs).To(Equal([]fakeblobstore.PutPartInput{
+					{Path: "fake-blob-id", PartNumber: 1, Contents: "fake-"},
+					{Path: "fake-blob-id", PartNumber: 2, Contents: "conte"},
+					{Path: "fake-blob-id", PartNumber: 2, Contents: "conte"},
+					{Path: "fake-blob-id", PartNumber: 3, Contents: "nts"},
+				}))
+				Expect(timeService.Sleeps).To(Equal([]time.Duration{1 * time.Second}))
+				Expect(fakeMultipartClient.CompletePartsSHA1).To(Equal("978ad524a02039f261773fe93d94973ae7de6470"))
+			})
+
+			It("returns an error when a part keeps failing", func() {
+				fakeMultipartClient.PutPartErrs = []error{
+					io.ErrUnexpectedEOF,
+					io.ErrUnexpectedEOF,
+					io.ErrUnexpectedEOF,
+				}
+
+				_, err := blobstore.Add(sourcePath)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Uploading part 1 of blob 'fake-blob-id'"))
+				Expect(err.Error()).To(ContainSubstring("Giving up on upload of part 1 of blob fake-blob-id after 3 attempts"))
+				Expect(fakeMultipartClient.PutPartInputs).To(HaveLen(3))
+				Expect(fakeMultipartClient.CompletePartsPath).To(BeEmpty())
+			})
+
+			It("does not retry a part that failed with an error that is not transient", func() {
+				fakeMultipartClient.PutPartErrs = []error{errors.New("fake-put-part-err")}
+
+				_, err := blobstore.Add(sourcePath)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-put-part-err"))
+				Expect(fakeMultipartClient.PutPartInputs).To(HaveLen(1))
+			})
+
+			It("returns an error when completing the upload fails", func() {
+				fakeMultipartClient.CompletePartsErr = ChecksumMismatchError{BlobID: "fake-blob-id", Expected: "fake-expected", Actual: "fake-actual"}
+
+				_, err := blobstore.Add(sourcePath)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Completing multipart upload of blob 'fake-blob-id'"))
+				Expect(timeService.Sleeps).To(BeEmpty())
+			})
+
+			It("uploads small files in a single put", func() {
+				fakeMultipartClient.Size = 100
+
+				_, err := blobstore.Add(sourcePath)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeMultipartClient.PutPartInputs).To(BeEmpty())
+				Expect(fakeMultipartClient.PutContents).To(Equal("fake-contents"))
+			})
+
+			It("uploads in a single put when the part size is not positive", func() {
+				fakeMultipartClient.Size = 0
+
+				_, err := blobstore.Add(sourcePath)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeMultipartClient.PutPartInputs).To(BeEmpty())
+				Expect(fakeMultipartClient.PutContents).To(Equal("fake-contents"))
+			})
+		})
+	})
+
+	Describe("retrying", func() {
+		var fakeRetryDavClient *fakeblobstore.FakeDavClient
+
+		BeforeEach(func() {
+			fakeRetryDavClient = fakeblobstore.NewFakeDavClient()
+			blobstore = NewBlobstore(fakeRetryDavClient, fakeUUIDGenerator, fs, retrier, biui.NewNoopProgressReporter(), logger)
+		})
+
+		Describe("Get", func() {
+			BeforeEach(func() {
+				fs.ReturnTempFile = fakesys.NewFakeFile("fake-destination-path", fs)
+				fakeRetryDavClient.GetContents = "fake-content"
+			})
+
+			It("retries network errors with increasing delays and warns about each retry", func() {
+				fakeRetryDavClient.GetErrs = []error{
+					bosherr.WrapError(&net.OpError{Op: "dial", Err: errors.New("fake-refused")}, "Getting dav blob fake-blob-id"),
+					errors.New("Getting dav blob fake-blob-id: Wrong response code: 503; body: "),
+				}
+
+				localBlob, err := blobstore.Get("fake-blob-id")
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeRetryDavClient.GetPaths).To(Equal([]string{"fake-blob-id", "fake-blob-id", "fake-blob-id"}))
+				Expect(timeService.Sleeps).To(Equal([]time.Duration{1 * time.Second, 2 * time.Second}))
+
+				contents, err := fs.ReadFileString(localBlob.Path())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(contents).To(Equal("fake-content"))
+
+				Expect(warnings.List()).To(HaveLen(2))
+				Expect(warnings.List()[0].Source).To(Equal("retrier"))
+				Expect(warnings.List()[0].Message).To(ContainSubstring("Retrying download of blob fake-blob-id in 1s after attempt 1 of 3 failed"))
+			})
+
+			It("logs each retry at debug level with the blob id", func() {
+				logBuffer := gbytes.NewBuffer()
+				logger = boshlog.NewWriterLogger(boshlog.LevelDebug, logBuffer)
+				retrier = biretry.NewRetrier(biretry.Config{Retries: 2, Delay: 1 * time.Second, MaxDelay: 30 * time.Second}, timeService, warnings, logger)
+				blobstore = NewBlobstore(fakeRetryDavClient, fakeUUIDGenerator, fs, retrier, biui.NewNoopProgressReporter(), logger)
+
+				fakeRetryDavClient.GetErrs = []error{
+					bosherr.WrapError(&net.OpError{Op: "read", Err: errors.New("fake-reset")}, "Getting dav blob fake-blob-id"),
+				}
+
+				_, err := blobstore.Get("fake-blob-id")
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(logBuffer).To(gbytes.Say(`DEBUG - Attempt 1 of 3 of download of blob fake-blob-id failed, retrying in 1s: .*fake-reset`))
+			})
+
+			It("gives up after the configured number of attempts", func() {
+				fakeRetryDavClient.GetErrs = []error{
+					errors.New("Wrong response code: 503"),
+					errors.New("Wrong response code: 503"),
+					errors.New("Wrong response code: 503"),
+				}
+
+				_, err := blobstore.Get("fake-blob-id")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Giving up on download of blob fake-blob-id after 3 attempts"))
+				Expect(fakeRetryDavClient.GetPaths).To(HaveLen(3))
+				Expect(timeService.Sleeps).To(HaveLen(2))
+			})
+
+			It("does not retry a missing blob", func() {
+				fakeRetryDavClient.GetErrs = []error{
+					errors.New("Getting dav blob fake-blob-id: Wrong response code: 404; body: "),
+				}
+
+				_, err := blobstore.Get("fake-blob-id")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Wrong response code: 404"))
+				Expect(fakeRetryDavClient.GetPaths).To(HaveLen(1))
+				Expect(timeService.Sleeps).To(BeEmpty())
+				Expect(warnings.List()).To(BeEmpty())
+			})
+		})
+
+		Describe("Add", func() {
+			BeforeEach(func() {
+				fakeUUIDGenerator.GeneratedUUID = "fake-blob-id"
+				fs.RegisterOpenFile("fake-source-path", &fakesys.FakeFile{
+					Contents: []byte("fake-contents"),
+				})
+			})
+
+			It("uploads the whole file again on every attempt", func() {
+				fakeRetryDavClient.PutErrs = []error{
+					bosherr.WrapError(errors.New("Wrong response code: 502"), "Putting dav blob fake-blob-id"),
+				}
+
+				blobID, err := blobstore.Add("fake-source-path")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(blobID).To(Equal("fake-blob-id"))
+
+				Expect(fakeRetryDavClient.PutInputs).To(Equal([]fakeblobstore.PutInput{
+					{Path: "fake-blob-id", Contents: "fake-contents"},
+					{Path: "fake-blob-id", Contents: "fake-contents"},
+				}))
+				Expect(timeService.Sleeps).To(Equal([]time.Duration{1 * time.Second}))
+			})
+
+			It("does not retry errors that are not known to be transient", func() {
+				fakeRetryDavClient.PutErrs = []error{errors.New("fake-put-error")}
+
+				_, err := blobstore.Add("fake-source-path")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-put-error"))
+				Expect(fakeRetryDavClient.PutInputs).To(HaveLen(1))
+			})
+		})
 	})
 })
+
+var _ = Describe("IsRetryableError", func() {
+	It("retries network, temporary IO and server errors", func() {
+		Expect(IsRetryableError(&net.OpError{Op: "read", Err: errors.New("fake-reset")})).To(BeTrue())
+		Expect(IsRetryableError(bosherr.WrapError(io.ErrUnexpectedEOF, "Saving blob"))).To(BeTrue())
+		Expect(IsRetryableError(errors.New("Wrong response code: 500; body: "))).To(BeTrue())
+		Expect(IsRetryableError(errors.New("Wrong response code: 429; body: "))).To(BeTrue())
+	})
+
+	It("never retries checksum mismatches, missing blobs or unknown errors", func() {
+		checksumErr := ChecksumMismatchError{BlobID: "fake-blob-id", Expected: "fake-expected", Actual: "fake-actual"}
+		Expect(IsRetryableError(bosherr.WrapError(checksumErr, "Putting blob"))).To(BeFalse())
+		Expect(IsRetryableError(errors.New("Wrong response code: 404; body: "))).To(BeFalse())
+		Expect(IsRetryableError(os.ErrNotExist)).To(BeFalse())
+		Expect(IsRetryableError(errors.New("fake-error"))).To(BeFalse())
+	})
+})
+
+type sleepRecordingClock struct {
+	*fakeclock.FakeClock
+	Sleeps []time.Duration
+}
+
+func (c *sleepRecordingClock) Sleep(d time.Duration) {
+	c.Sleeps = append(c.Sleeps, d)
+}
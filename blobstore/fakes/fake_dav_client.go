@@ -0,0 +1,57 @@
+package fakes
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// FakeDavClient answers each Get and Put with the next queued result,
+// succeeding once the queue is empty.
+type FakeDavClient struct {
+	GetPaths    []string
+	GetContents string
+	GetErrs     []error
+
+	PutInputs []PutInput
+	PutErrs   []error
+}
+
+type PutInput struct {
+	Path     string
+	Contents string
+}
+
+func NewFakeDavClient() *FakeDavClient {
+	return &FakeDavClient{}
+}
+
+func (c *FakeDavClient) Get(path string) (io.ReadCloser, error) {
+	c.GetPaths = append(c.GetPaths, path)
+
+	if len(c.GetErrs) > 0 {
+		var err error
+		err, c.GetErrs = c.GetErrs[0], c.GetErrs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ioutil.NopCloser(strings.NewReader(c.GetContents)), nil
+}
+
+func (c *FakeDavClient) Put(path string, content io.ReadCloser, contentLength int64) error {
+	contentBytes, err := ioutil.ReadAll(content)
+	if err != nil {
+		return err
+	}
+
+	c.PutInputs = append(c.PutInputs, PutInput{Path: path, Contents: string(contentBytes)})
+
+	if len(c.PutErrs) > 0 {
+		err, c.PutErrs = c.PutErrs[0], c.PutErrs[1:]
+		return err
+	}
+
+	return nil
+}
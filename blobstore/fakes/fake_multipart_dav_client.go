@@ -0,0 +1,65 @@
+package fakes
+
+import (
+	"io"
+	"io/ioutil"
+
+	fakeboshdavcli "github.com/cloudfoundry/bosh-davcli/client/fakes"
+)
+
+type FakeMultipartDavClient struct {
+	*fakeboshdavcli.FakeClient
+
+	Size int64
+
+	PutPartInputs []PutPartInput
+	PutPartErrs   []error
+
+	CompletePartsPath string
+	CompletePartsSHA1 string
+	CompletePartsErr  error
+}
+
+type PutPartInput struct {
+	Path       string
+	PartNumber int
+	Contents   string
+}
+
+func NewFakeMultipartDavClient(partSize int64) *FakeMultipartDavClient {
+	return &FakeMultipartDavClient{
+		FakeClient: fakeboshdavcli.NewFakeClient(),
+		Size:       partSize,
+	}
+}
+
+func (c *FakeMultipartDavClient) PartSize() int64 {
+	return c.Size
+}
+
+func (c *FakeMultipartDavClient) PutPart(path string, partNumber int, content io.ReadCloser, contentLength int64) error {
+	contentBytes, err := ioutil.ReadAll(content)
+	if err != nil {
+		return err
+	}
+
+	c.PutPartInputs = append(c.PutPartInputs, PutPartInput{
+		Path:       path,
+		PartNumber: partNumber,
+		Contents:   string(contentBytes),
+	})
+
+	if len(c.PutPartErrs) > 0 {
+		err, c.PutPartErrs = c.PutPartErrs[0], c.PutPartErrs[1:]
+		return err
+	}
+
+	return nil
+}
+
+func (c *FakeMultipartDavClient) CompleteParts(path string, digest string) error {
+	c.CompletePartsPath = path
+	c.CompletePartsSHA1 = digest
+
+	return c.CompletePartsErr
+}
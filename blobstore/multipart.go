@@ -0,0 +1,88 @@
+package blobstore
+
+import (
+	"crypto/sha1"
+	"encoding"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// MultipartDavCLIClient is implemented by remote blobstore clients that can
+// receive a blob as a sequence of individually confirmed parts.
+// Clients that only implement DavCLIClient receive blobs in a single Put.
+type MultipartDavCLIClient interface {
+	DavCLIClient
+
+	// PartSize returns the size of every part except the last one;
+	// blobs are uploaded in a single Put when it is not positive
+	PartSize() int64
+
+	// PutPart uploads a single part of a blob; parts are numbered from 1
+	PutPart(path string, partNumber int, content io.ReadCloser, contentLength int64) error
+
+	// CompleteParts is called once every part was uploaded, with the SHA1 of their contents.
+	// Clients that can tell that the assembled blob differs return ChecksumMismatchError.
+	CompleteParts(path string, digest string) error
+}
+
+func (b *blobstore) putMultipart(client MultipartDavCLIClient, blobID string, file boshsys.File, size int64) error {
+	partSize := client.PartSize()
+	partCount := int((size + partSize - 1) / partSize)
+
+	digest := sha1.New()
+
+	for partNumber := 1; partNumber <= partCount; partNumber++ {
+		offset := int64(partNumber-1) * partSize
+
+		partLength := partSize
+		if offset+partLength > size {
+			partLength = size - offset
+		}
+
+		err := b.putPart(client, blobID, file, partNumber, offset, partLength, digest)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := client.CompleteParts(blobID, fmt.Sprintf("%x", digest.Sum(nil)))
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Completing multipart upload of blob '%s'", blobID)
+	}
+
+	return nil
+}
+
+// putPart adds the contents of the part to digest as they are uploaded.
+// Every attempt starts from the digest of the previous parts,
+// so that bits sent by failed attempts are not digested.
+func (b *blobstore) putPart(client MultipartDavCLIClient, blobID string, file boshsys.File, partNumber int, offset, length int64, digest hash.Hash) error {
+	previousParts, err := digest.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Saving SHA1 of blob '%s'", blobID)
+	}
+
+	err = b.retry(fmt.Sprintf("upload of part %d of blob %s", partNumber, blobID), func() error {
+		err := digest.(encoding.BinaryUnmarshaler).UnmarshalBinary(previousParts)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Restoring SHA1 of blob '%s'", blobID)
+		}
+
+		b.logger.Debug(b.logTag, "Uploading part %d of blob %s", partNumber, blobID)
+
+		content := ioutil.NopCloser(b.progress.ProgressReader(
+			fmt.Sprintf("Uploading part %d of blob %s", partNumber, blobID), length, io.TeeReader(io.NewSectionReader(file, offset, length), digest)))
+
+		return client.PutPart(blobID, partNumber, content, length)
+	})
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Uploading part %d of blob '%s'", partNumber, blobID)
+	}
+
+	return nil
+}
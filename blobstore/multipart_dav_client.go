@@ -0,0 +1,114 @@
+package blobstore
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	boshdavcli "github.com/cloudfoundry/bosh-davcli/client"
+	boshdavcliconf "github.com/cloudfoundry/bosh-davcli/config"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+// DefaultMultipartPartSize is used when a multipart client is created without a part size
+const DefaultMultipartPartSize int64 = 64 * 1024 * 1024
+
+type multipartDavClient struct {
+	boshdavcli.Client
+
+	config     boshdavcliconf.Config
+	httpClient *http.Client
+	partSize   int64
+}
+
+// NewMultipartDavClient returns a DAV client that uploads blobs as a sequence of
+// PUT requests with a Content-Range header, one per part, so that only a failed
+// part is sent again. The DAV server must write ranges into the existing blob.
+// A part size of 0 or less uses DefaultMultipartPartSize.
+func NewMultipartDavClient(config boshdavcliconf.Config, httpClient *http.Client, partSize int64, logger boshlog.Logger) MultipartDavCLIClient {
+	if partSize <= 0 {
+		partSize = DefaultMultipartPartSize
+	}
+
+	return multipartDavClient{
+		Client:     boshdavcli.NewClient(config, httpClient, logger),
+		config:     config,
+		httpClient: httpClient,
+		partSize:   partSize,
+	}
+}
+
+func (c multipartDavClient) PartSize() int64 {
+	return c.partSize
+}
+
+func (c multipartDavClient) PutPart(path string, partNumber int, content io.ReadCloser, contentLength int64) error {
+	defer content.Close()
+
+	if partNumber < 1 {
+		return bosherr.Errorf("Expected part number of dav blob %s to be at least 1 but was %d", path, partNumber)
+	}
+
+	req, err := c.createReq("PUT", path, content)
+	if err != nil {
+		return err
+	}
+
+	offset := int64(partNumber-1) * c.partSize
+
+	req.ContentLength = contentLength
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/*", offset, offset+contentLength-1))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Putting part %d of dav blob %s", partNumber, path)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Putting part %d of dav blob %s: Wrong response code: %d", partNumber, path, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// CompleteParts has nothing to assemble since the DAV server writes every part into
+// the blob as it is received. The agent verifies the SHA1 when it fetches the blob.
+func (c multipartDavClient) CompleteParts(path string, digest string) error {
+	return nil
+}
+
+// createReq addresses blobs the same way as the DAV client, under a directory
+// named after the first byte of the SHA1 of the blob ID
+func (c multipartDavClient) createReq(method, blobID string, body io.Reader) (*http.Request, error) {
+	blobURL, err := url.Parse(c.config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	digester := sha1.New()
+	digester.Write([]byte(blobID))
+	blobPrefix := fmt.Sprintf("%02x", digester.Sum(nil)[0])
+
+	newPath := path.Join(blobURL.Path, blobPrefix, blobID)
+	if !strings.HasPrefix(newPath, "/") {
+		newPath = "/" + newPath
+	}
+
+	blobURL.Path = newPath
+
+	req, err := http.NewRequest(method, blobURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.SetBasicAuth(c.config.User, c.config.Password)
+
+	return req, nil
+}
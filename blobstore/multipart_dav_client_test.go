@@ -0,0 +1,77 @@
+package blobstore_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	boshdavcliconf "github.com/cloudfoundry/bosh-davcli/config"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/cloudfoundry/bosh-cli/blobstore"
+)
+
+var _ = Describe("MultipartDavClient", func() {
+	var (
+		server *ghttp.Server
+		client MultipartDavCLIClient
+	)
+
+	// fake-blob-id is kept in the 80 directory, after the first byte of its SHA1
+	const blobPath = "/blobs/80/fake-blob-id"
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+
+		client = NewMultipartDavClient(boshdavcliconf.Config{
+			Endpoint: server.URL() + "/blobs",
+			User:     "fake-user",
+			Password: "fake-password",
+		}, http.DefaultClient, 5, boshlog.NewLogger(boshlog.LevelNone))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("defaults the part size", func() {
+		client = NewMultipartDavClient(boshdavcliconf.Config{}, http.DefaultClient, 0, boshlog.NewLogger(boshlog.LevelNone))
+		Expect(client.PartSize()).To(Equal(DefaultMultipartPartSize))
+	})
+
+	Describe("PutPart", func() {
+		It("puts the range of the blob covered by the part", func() {
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", blobPath),
+				ghttp.VerifyBasicAuth("fake-user", "fake-password"),
+				ghttp.VerifyHeaderKV("Content-Range", "bytes 5-9/*"),
+				ghttp.VerifyBody([]byte("conte")),
+				ghttp.RespondWith(http.StatusNoContent, nil),
+			))
+
+			err := client.PutPart("fake-blob-id", 2, ioutil.NopCloser(strings.NewReader("conte")), 5)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("returns an error with the response code so that server errors are retried", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusServiceUnavailable, nil))
+
+			err := client.PutPart("fake-blob-id", 1, ioutil.NopCloser(strings.NewReader("fake-")), 5)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Wrong response code: 503"))
+			Expect(IsRetryableError(err)).To(BeTrue())
+		})
+	})
+
+	Describe("CompleteParts", func() {
+		It("does not read the blob back", func() {
+			err := client.CompleteParts("fake-blob-id", "978ad524a02039f261773fe93d94973ae7de6470")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(server.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+})
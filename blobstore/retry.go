@@ -0,0 +1,76 @@
+package blobstore
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"syscall"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// ChecksumMismatchError is returned when the blobstore holds different
+// content than was sent to it. It is never retried.
+type ChecksumMismatchError struct {
+	BlobID   string
+	Expected string
+	Actual   string
+}
+
+func (e ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("Expected assembled blob '%s' to have SHA1 '%s' but was '%s'", e.BlobID, e.Expected, e.Actual)
+}
+
+var responseCodePattern = regexp.MustCompile(`Wrong response code: (\d+)`)
+
+// IsRetryableError reports whether err, or the error it wraps, is a network
+// or temporary IO error that may succeed when attempted again.
+// Checksum mismatches, missing blobs and unrecognised errors are not retryable.
+func IsRetryableError(err error) bool {
+	for err != nil {
+		switch typedErr := err.(type) {
+		case ChecksumMismatchError:
+			return false
+		case bosherr.ComplexError:
+			err = typedErr.Cause
+			continue
+		case net.Error:
+			return true
+		}
+
+		if err == io.ErrUnexpectedEOF || err == syscall.ECONNRESET || err == syscall.EPIPE {
+			return true
+		}
+
+		if os.IsNotExist(err) {
+			return false
+		}
+
+		if matches := responseCodePattern.FindStringSubmatch(err.Error()); matches != nil {
+			statusCode, _ := strconv.Atoi(matches[1])
+			return statusCode >= 500 || statusCode == 408 || statusCode == 429
+		}
+
+		return false
+	}
+
+	return false
+}
+
+// retry makes attempts while they fail with a retryable error, as far as the retrier allows
+func (b *blobstore) retry(description string, attempt func() error) error {
+	attempts := 0
+
+	err := b.retrier.Retry(description, IsRetryableError, func() error {
+		attempts++
+		return attempt()
+	})
+	if err != nil && attempts > 1 {
+		return bosherr.WrapErrorf(err, "Giving up on %s after %d attempts", description, attempts)
+	}
+
+	return err
+}
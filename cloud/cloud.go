@@ -26,6 +26,9 @@ type Cloud interface {
 	AttachDisk(vmCID, diskCID string) error
 	DetachDisk(vmCID, diskCID string) error
 	DeleteDisk(diskCID string) error
+	HasDisk(diskCID string) (bool, error)
+	Quota() (Quota, error)
+	Info() (CPIInfo, error)
 	fmt.Stringer
 }
 
@@ -40,14 +43,24 @@ type VMMetadata map[string]string
 
 type DiskMetadata map[string]string
 
+// Quota is the capacity left in the IaaS account, as reported by CPIs that implement
+// the optional 'quota' method. Limits that the CPI does not report are nil.
+type Quota struct {
+	VMs *int
+
+	// DiskSize is in MB
+	DiskSize *int
+}
+
 func NewCloud(
 	cpiCmdRunner CPICmdRunner,
 	directorID string,
+	apiVersion int,
 	logger boshlog.Logger,
 ) Cloud {
 	return cloud{
 		cpiCmdRunner: cpiCmdRunner,
-		context:      CmdContext{DirectorID: directorID},
+		context:      CmdContext{DirectorID: directorID, APIVersion: apiVersion},
 		logger:       logger,
 		logTag:       "cloud",
 	}
@@ -108,6 +121,24 @@ func (c cloud) HasVM(vmCID string) (bool, error) {
 	return found, nil
 }
 
+func (c cloud) HasDisk(diskCID string) (bool, error) {
+	method := "has_disk"
+	cmdOutput, err := c.cpiCmdRunner.Run(c.context, method, diskCID)
+	if err != nil {
+		return false, err
+	}
+
+	if cmdOutput.Error != nil {
+		return false, NewCPIError(method, *cmdOutput.Error)
+	}
+
+	found, ok := cmdOutput.Result.(bool)
+	if !ok {
+		return false, bosherr.Errorf("Unexpected external CPI command result: '%#v'", cmdOutput.Result)
+	}
+	return found, nil
+}
+
 func (c cloud) CreateVM(
 	agentID string,
 	stemcellCID string,
@@ -135,8 +166,14 @@ func (c cloud) CreateVM(
 		return "", NewCPIError(method, *cmdOutput.Error)
 	}
 
-	// for create_vm, the result is a string of the vm cid
-	cidString, ok := cmdOutput.Result.(string)
+	// for create_vm, the result is a string of the vm cid,
+	// which version 2 of the CPI API returns together with the network settings
+	result := cmdOutput.Result
+	if results, ok := result.([]interface{}); ok && c.context.APIVersion >= 2 && len(results) > 0 {
+		result = results[0]
+	}
+
+	cidString, ok := result.(string)
 	if !ok {
 		return "", bosherr.Errorf("Unexpected external CPI command result: '%#v'", cmdOutput.Result)
 	}
@@ -281,6 +318,75 @@ func (c cloud) DeleteDisk(diskCID string) error {
 	return nil
 }
 
+// Info asks the CPI to describe itself. CPIs that check their IaaS configuration
+// or credentials when they start can be validated this way before anything is created.
+func (c cloud) Info() (CPIInfo, error) {
+	c.logger.Debug(c.logTag, "Getting CPI info")
+
+	method := "info"
+	cmdOutput, err := c.cpiCmdRunner.Run(c.context, method)
+	if err != nil {
+		return CPIInfo{}, err
+	}
+
+	if cmdOutput.Error != nil {
+		return CPIInfo{}, NewCPIError(method, *cmdOutput.Error)
+	}
+
+	if cmdOutput.Result == nil {
+		return CPIInfo{APIVersion: 1}, nil
+	}
+
+	result, ok := cmdOutput.Result.(map[string]interface{})
+	if !ok {
+		return CPIInfo{}, bosherr.Errorf("Unexpected external CPI command result: '%#v'", cmdOutput.Result)
+	}
+
+	return parseCPIInfo(result), nil
+}
+
+func (c cloud) Quota() (Quota, error) {
+	c.logger.Debug(c.logTag, "Getting quota")
+
+	method := "quota"
+	cmdOutput, err := c.cpiCmdRunner.Run(c.context, method)
+	if err != nil {
+		return Quota{}, err
+	}
+
+	if cmdOutput.Error != nil {
+		return Quota{}, NewCPIError(method, *cmdOutput.Error)
+	}
+
+	if cmdOutput.Result == nil {
+		return Quota{}, nil
+	}
+
+	result, ok := cmdOutput.Result.(map[string]interface{})
+	if !ok {
+		return Quota{}, bosherr.Errorf("Unexpected external CPI command result: '%#v'", cmdOutput.Result)
+	}
+
+	var quota Quota
+
+	for key, limit := range map[string]**int{"vms": &quota.VMs, "disk_size": &quota.DiskSize} {
+		value, found := result[key]
+		if !found || value == nil {
+			continue
+		}
+
+		number, ok := value.(float64)
+		if !ok {
+			return Quota{}, bosherr.Errorf("Unexpected external CPI command result for '%s': '%#v'", key, value)
+		}
+
+		available := int(number)
+		*limit = &available
+	}
+
+	return quota, nil
+}
+
 func (c cloud) String() string {
 	return fmt.Sprintf("Cloud{Context=%s}", c.context)
 }
@@ -23,8 +23,8 @@ var _ = Describe("Cloud", func() {
 	BeforeEach(func() {
 		fakeCPICmdRunner = fakebicloud.NewFakeCPICmdRunner()
 		logger := boshlog.NewLogger(boshlog.LevelNone)
-		cloud = NewCloud(fakeCPICmdRunner, "fake-director-id", logger)
-		context = CmdContext{DirectorID: "fake-director-id"}
+		cloud = NewCloud(fakeCPICmdRunner, "fake-director-id", 1, logger)
+		context = CmdContext{DirectorID: "fake-director-id", APIVersion: 1}
 	})
 
 	var itHandlesCPIErrors = func(method string, exec func() error) {
@@ -200,6 +200,51 @@ var _ = Describe("Cloud", func() {
 		})
 	})
 
+	Describe("HasDisk", func() {
+		It("return true when disk exists", func() {
+			fakeCPICmdRunner.RunCmdOutput = CmdOutput{
+				Result: true,
+			}
+
+			found, err := cloud.HasDisk("fake-disk-cid")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+
+			Expect(fakeCPICmdRunner.RunInputs).To(Equal([]fakebicloud.RunInput{
+				{
+					Context:   context,
+					Method:    "has_disk",
+					Arguments: []interface{}{"fake-disk-cid"},
+				},
+			}))
+		})
+
+		It("return false when disk does not exist", func() {
+			fakeCPICmdRunner.RunCmdOutput = CmdOutput{
+				Result: false,
+			}
+
+			found, err := cloud.HasDisk("fake-disk-cid")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+
+		It("returns an error when the result is not a boolean", func() {
+			fakeCPICmdRunner.RunCmdOutput = CmdOutput{
+				Result: "fake-result",
+			}
+
+			_, err := cloud.HasDisk("fake-disk-cid")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unexpected external CPI command result"))
+		})
+
+		itHandlesCPIErrors("has_disk", func() error {
+			_, err := cloud.HasDisk("fake-disk-cid")
+			return err
+		})
+	})
+
 	Describe("CreateVM", func() {
 		var (
 			agentID           string
@@ -260,6 +305,23 @@ var _ = Describe("Cloud", func() {
 			})
 		})
 
+		Context("with version 2 of the CPI API", func() {
+			BeforeEach(func() {
+				cloud = NewCloud(fakeCPICmdRunner, "fake-director-id", 2, boshlog.NewLogger(boshlog.LevelNone))
+				fakeCPICmdRunner.RunCmdOutput = CmdOutput{
+					Result: []interface{}{"fake-vm-cid", map[string]interface{}{"bosh": map[string]interface{}{}}},
+				}
+			})
+
+			It("makes the request with the version and returns the cid next to the network settings", func() {
+				cid, err := cloud.CreateVM(agentID, stemcellCID, cloudProperties, networkInterfaces, env)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cid).To(Equal("fake-vm-cid"))
+
+				Expect(fakeCPICmdRunner.RunInputs[0].Context).To(Equal(CmdContext{DirectorID: "fake-director-id", APIVersion: 2}))
+			})
+		})
+
 		Context("when the result is of an unexpected type", func() {
 			BeforeEach(func() {
 				fakeCPICmdRunner.RunCmdOutput = CmdOutput{
@@ -590,4 +652,124 @@ var _ = Describe("Cloud", func() {
 			return cloud.DeleteDisk("fake-disk-cid")
 		})
 	})
+
+	Describe("Info", func() {
+		It("returns what the CPI reports about itself", func() {
+			fakeCPICmdRunner.RunCmdOutput = CmdOutput{
+				Result: map[string]interface{}{
+					"api_version":      float64(2),
+					"stemcell_formats": []interface{}{"aws-raw"},
+				},
+			}
+
+			info, err := cloud.Info()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info).To(Equal(CPIInfo{APIVersion: 2, StemcellFormats: []string{"aws-raw"}}))
+
+			Expect(fakeCPICmdRunner.RunInputs).To(Equal([]fakebicloud.RunInput{
+				{
+					Context: context,
+					Method:  "info",
+				},
+			}))
+		})
+
+		It("returns API version 1 when the CPI returns nothing", func() {
+			info, err := cloud.Info()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info).To(Equal(CPIInfo{APIVersion: 1}))
+		})
+
+		It("returns an error when the result is not a hash", func() {
+			fakeCPICmdRunner.RunCmdOutput = CmdOutput{Result: "fake-info"}
+
+			_, err := cloud.Info()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unexpected external CPI command result"))
+		})
+
+		Context("when the cpi command execution fails", func() {
+			BeforeEach(func() {
+				fakeCPICmdRunner.RunErr = errors.New("fake-run-error")
+			})
+
+			It("returns an error when executing the CPI command fails", func() {
+				_, err := cloud.Info()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-run-error"))
+			})
+		})
+
+		itHandlesCPIErrors("info", func() error {
+			_, err := cloud.Info()
+			return err
+		})
+	})
+
+	Describe("Quota", func() {
+		It("returns the limits reported by the CPI", func() {
+			fakeCPICmdRunner.RunCmdOutput = CmdOutput{
+				Result: map[string]interface{}{
+					"vms":       float64(2),
+					"disk_size": float64(10240),
+				},
+			}
+
+			quota, err := cloud.Quota()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*quota.VMs).To(Equal(2))
+			Expect(*quota.DiskSize).To(Equal(10240))
+
+			Expect(fakeCPICmdRunner.RunInputs).To(Equal([]fakebicloud.RunInput{
+				{
+					Context: context,
+					Method:  "quota",
+				},
+			}))
+		})
+
+		It("leaves limits that the CPI does not report unset", func() {
+			fakeCPICmdRunner.RunCmdOutput = CmdOutput{
+				Result: map[string]interface{}{"vms": float64(2)},
+			}
+
+			quota, err := cloud.Quota()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*quota.VMs).To(Equal(2))
+			Expect(quota.DiskSize).To(BeNil())
+		})
+
+		It("returns no limits when the CPI returns nothing", func() {
+			quota, err := cloud.Quota()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(quota).To(Equal(Quota{}))
+		})
+
+		It("returns an error when a limit is not a number", func() {
+			fakeCPICmdRunner.RunCmdOutput = CmdOutput{
+				Result: map[string]interface{}{"vms": "fake-vms"},
+			}
+
+			_, err := cloud.Quota()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unexpected external CPI command result for 'vms'"))
+		})
+
+		Context("when the cpi command execution fails", func() {
+			BeforeEach(func() {
+				fakeCPICmdRunner.RunErr = errors.New("fake-run-error")
+			})
+
+			It("returns an error when executing the CPI command fails", func() {
+				_, err := cloud.Quota()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-run-error"))
+			})
+		})
+
+		itHandlesCPIErrors("quota", func() error {
+			_, err := cloud.Quota()
+			return err
+		})
+	})
 })
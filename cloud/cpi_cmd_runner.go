@@ -4,20 +4,31 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"code.cloudfoundry.org/clock"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
+
+	bilog "github.com/cloudfoundry/bosh-cli/logger"
 )
 
 type CmdInput struct {
-	Method    string        `json:"method"`
-	Arguments []interface{} `json:"arguments"`
-	Context   CmdContext    `json:"context"`
+	Method     string        `json:"method"`
+	Arguments  []interface{} `json:"arguments"`
+	Context    CmdContext    `json:"context"`
+	APIVersion int           `json:"api_version,omitempty"`
 }
 
 type CmdContext struct {
 	DirectorID string `json:"director_uuid"`
+
+	// APIVersion is the CPI API version of the request,
+	// which the CPI expects next to the context instead of inside it
+	APIVersion int `json:"-"`
 }
 
 func (c CmdContext) String() string {
@@ -32,6 +43,9 @@ type CmdError struct {
 	Type      string `json:"type"`
 	Message   string `json:"message"`
 	OkToRetry bool   `json:"ok_to_retry"`
+
+	// Stderr is the truncated stderr of the CPI call that responded with the error
+	Stderr string `json:"-"`
 }
 
 func (e CmdError) String() string {
@@ -52,31 +66,50 @@ type CPICmdRunner interface {
 	Run(context CmdContext, method string, args ...interface{}) (CmdOutput, error)
 }
 
+// cpiOutputLimit is how many bytes of CPI output are included in errors;
+// the end of the output is kept since it usually explains the failure
+const cpiOutputLimit = 2000
+
+// cpiKillGracePeriod is how long a timed out CPI process is given to exit after SIGTERM before it is killed
+const cpiKillGracePeriod = 10 * time.Second
+
 type cpiCmdRunner struct {
-	cmdRunner boshsys.CmdRunner
-	cpi       CPI
-	logger    boshlog.Logger
-	logTag    string
+	cmdRunner   boshsys.CmdRunner
+	cpi         CPI
+	timeouts    CPIMethodTimeouts
+	timeService clock.Clock
+	logger      boshlog.Logger
+	logTag      string
 }
 
 func NewCPICmdRunner(
 	cmdRunner boshsys.CmdRunner,
 	cpi CPI,
+	timeouts CPIMethodTimeouts,
+	timeService clock.Clock,
 	logger boshlog.Logger,
 ) CPICmdRunner {
 	return &cpiCmdRunner{
-		cmdRunner: cmdRunner,
-		cpi:       cpi,
-		logger:    logger,
-		logTag:    "cpiCmdRunner",
+		cmdRunner:   cmdRunner,
+		cpi:         cpi,
+		timeouts:    timeouts,
+		timeService: timeService,
+		logger:      logger,
+		logTag:      "cpiCmdRunner",
 	}
 }
 
 func (r *cpiCmdRunner) Run(context CmdContext, method string, args ...interface{}) (CmdOutput, error) {
+	// CPIs expect an array even for methods without arguments, such as 'info'
+	if args == nil {
+		args = []interface{}{}
+	}
+
 	cmdInput := CmdInput{
-		Method:    method,
-		Arguments: args,
-		Context:   context,
+		Method:     method,
+		Arguments:  args,
+		Context:    context,
+		APIVersion: context.APIVersion,
 	}
 	inputBytes, err := json.Marshal(cmdInput)
 	if err != nil {
@@ -94,19 +127,82 @@ func (r *cpiCmdRunner) Run(context CmdContext, method string, args ...interface{
 		UseIsolatedEnv: true,
 		Stdin:          bytes.NewReader(inputBytes),
 	}
-	stdout, stderr, exitCode, err := r.cmdRunner.RunComplexCommand(cmd)
-	r.logger.Debug(r.logTag, "Exit Code %d when executing external CPI command '%s'\nSTDIN: '%s'\nSTDOUT: '%s'\nSTDERR: '%s'", exitCode, cmdPath, string(inputBytes), stdout, stderr)
+	result, err := r.runWithTimeout(cmd, method)
 	if err != nil {
+		if _, ok := err.(TimeoutError); ok {
+			return CmdOutput{}, err
+		}
 		return CmdOutput{}, bosherr.WrapErrorf(err, "Executing external CPI command: '%s'", cmdPath)
 	}
 
+	stdout, stderr := result.Stdout, result.Stderr
+	bilog.DebugWithFields(r.logger, r.logTag, []bilog.Field{
+		{Name: "stdin", Value: string(inputBytes)},
+		{Name: "stdout", Value: stdout},
+		{Name: "stderr", Value: stderr},
+	}, "Exit Code %d when executing external CPI command '%s'", result.ExitStatus, cmdPath)
+	if result.Error != nil {
+		return CmdOutput{}, bosherr.WrapErrorf(result.Error, "Executing external CPI command: '%s', STDERR: '%s'", cmdPath, truncateCPIOutput(stderr))
+	}
+
 	cmdOutput := CmdOutput{}
 	err = json.Unmarshal([]byte(stdout), &cmdOutput)
 	if err != nil {
-		return CmdOutput{}, bosherr.WrapErrorf(err, "Unmarshalling external CPI command output: STDOUT: '%s', STDERR: '%s'", stdout, stderr)
+		return CmdOutput{}, bosherr.WrapErrorf(err, "Unmarshalling external CPI command output: STDOUT: '%s', STDERR: '%s'", truncateCPIOutput(stdout), truncateCPIOutput(stderr))
 	}
 
 	r.logger.Debug(r.logTag, cmdOutput.Log)
 
+	if cmdOutput.Error != nil {
+		cmdOutput.Error.Stderr = truncateCPIOutput(stderr)
+	}
+
 	return cmdOutput, err
 }
+
+func (r *cpiCmdRunner) runWithTimeout(cmd boshsys.Command, method string) (boshsys.Result, error) {
+	process, err := r.cmdRunner.RunComplexCommandAsync(cmd)
+	if err != nil {
+		return boshsys.Result{}, err
+	}
+
+	resultCh := process.Wait()
+
+	timeout := r.timeouts.For(method)
+	if timeout <= 0 {
+		return <-resultCh, nil
+	}
+
+	timer := r.timeService.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case result := <-resultCh:
+		return result, nil
+	case <-timer.C():
+		r.logger.Error(r.logTag, "External CPI command '%s' for method '%s' timed out after %s, terminating", cmd.Name, method, timeout)
+
+		err = process.TerminateNicely(cpiKillGracePeriod)
+		if err != nil {
+			r.logger.Warn(r.logTag, "Failed to terminate external CPI command '%s': %s", cmd.Name, err.Error())
+		}
+
+		return boshsys.Result{}, NewCPITimeoutError(method, timeout)
+	}
+}
+
+// truncateCPIOutput keeps the last cpiOutputLimit bytes of output, without splitting a character
+func truncateCPIOutput(output string) string {
+	output = strings.TrimSpace(output)
+
+	if len(output) <= cpiOutputLimit {
+		return output
+	}
+
+	start := len(output) - cpiOutputLimit
+	for start < len(output) && !utf8.RuneStart(output[start]) {
+		start++
+	}
+
+	return "..." + output[start:]
+}
@@ -4,9 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"io/ioutil"
+	"strings"
+	"time"
 
+	"code.cloudfoundry.org/clock/fakeclock"
 	. "github.com/cloudfoundry/bosh-cli/cloud"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
 	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -18,6 +22,8 @@ var _ = Describe("CpiCmdRunner", func() {
 		context      CmdContext
 		cmdRunner    *fakesys.FakeCmdRunner
 		cpi          CPI
+		timeouts     CPIMethodTimeouts
+		timeService  *fakeclock.FakeClock
 	)
 
 	BeforeEach(func() {
@@ -31,9 +37,18 @@ var _ = Describe("CpiCmdRunner", func() {
 			PackagesDir: "/packages",
 		}
 
+		timeouts = CPIMethodTimeouts{
+			Default: 10 * time.Minute,
+			Methods: map[string]time.Duration{"fast-method": 1 * time.Minute},
+		}
+		timeService = fakeclock.NewFakeClock(time.Now())
+
 		cmdRunner = fakesys.NewFakeCmdRunner()
+	})
+
+	JustBeforeEach(func() {
 		logger := boshlog.NewLogger(boshlog.LevelNone)
-		cpiCmdRunner = NewCPICmdRunner(cmdRunner, cpi, logger)
+		cpiCmdRunner = NewCPICmdRunner(cmdRunner, cpi, timeouts, timeService, logger)
 	})
 
 	Describe("Run", func() {
@@ -42,11 +57,12 @@ var _ = Describe("CpiCmdRunner", func() {
 			outputBytes, err := json.Marshal(cmdOutput)
 			Expect(err).NotTo(HaveOccurred())
 
-			result := fakesys.FakeCmdResult{
-				Stdout:     string(outputBytes),
-				ExitStatus: 0,
-			}
-			cmdRunner.AddCmdResult("/jobs/cpi/bin/cpi", result)
+			cmdRunner.AddProcess("/jobs/cpi/bin/cpi", &fakesys.FakeProcess{
+				WaitResult: boshsys.Result{
+					Stdout:     string(outputBytes),
+					ExitStatus: 0,
+				},
+			})
 
 			_, err = cpiCmdRunner.Run(context, "fake-method", "fake-argument-1", "fake-argument-2")
 			Expect(err).NotTo(HaveOccurred())
@@ -72,6 +88,28 @@ var _ = Describe("CpiCmdRunner", func() {
 			))
 		})
 
+		It("sends the CPI API version next to the context", func() {
+			cmdRunner.AddProcess("/jobs/cpi/bin/cpi", &fakesys.FakeProcess{
+				WaitResult: boshsys.Result{Stdout: `{"result":null}`},
+			})
+
+			context.APIVersion = 2
+
+			_, err := cpiCmdRunner.Run(context, "fake-method")
+			Expect(err).NotTo(HaveOccurred())
+
+			bytes, err := ioutil.ReadAll(cmdRunner.RunComplexCommands[0].Stdin)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(bytes)).To(Equal(
+				`{` +
+					`"method":"fake-method",` +
+					`"arguments":[],` +
+					`"context":{"director_uuid":"fake-director-id"},` +
+					`"api_version":2` +
+					`}`,
+			))
+		})
+
 		Context("when the command succeeds", func() {
 			BeforeEach(func() {
 				cmdOutput := CmdOutput{
@@ -80,11 +118,12 @@ var _ = Describe("CpiCmdRunner", func() {
 				outputBytes, err := json.Marshal(cmdOutput)
 				Expect(err).NotTo(HaveOccurred())
 
-				result := fakesys.FakeCmdResult{
-					Stdout:     string(outputBytes),
-					ExitStatus: 0,
-				}
-				cmdRunner.AddCmdResult("/jobs/cpi/bin/cpi", result)
+				cmdRunner.AddProcess("/jobs/cpi/bin/cpi", &fakesys.FakeProcess{
+					WaitResult: boshsys.Result{
+						Stdout:     string(outputBytes),
+						ExitStatus: 0,
+					},
+				})
 			})
 
 			It("returns the result", func() {
@@ -100,21 +139,30 @@ var _ = Describe("CpiCmdRunner", func() {
 
 		Context("when running the command fails", func() {
 			BeforeEach(func() {
-				result := fakesys.FakeCmdResult{
-					Error: errors.New("fake-error-trying-to-run-command"),
-				}
-				cmdRunner.AddCmdResult("/jobs/cpi/bin/cpi", result)
+				cmdRunner.AddProcess("/jobs/cpi/bin/cpi", &fakesys.FakeProcess{
+					WaitResult: boshsys.Result{
+						Stderr: "fake-cpi-stderr\n",
+						Error:  errors.New("fake-error-trying-to-run-command"),
+					},
+				})
 			})
 
-			It("returns an error", func() {
+			It("returns an error including the stderr of the CPI", func() {
 				_, err := cpiCmdRunner.Run(context, "fake-method", "fake-argument")
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("fake-error-trying-to-run-command"))
+				Expect(err.Error()).To(ContainSubstring("STDERR: 'fake-cpi-stderr'"))
 			})
 		})
 
 		Context("when the command runs but fails", func() {
+			var stderr string
+
 			BeforeEach(func() {
+				stderr = "fake-cpi-stderr\n"
+			})
+
+			JustBeforeEach(func() {
 				cmdOutput := CmdOutput{
 					Error: &CmdError{
 						Message: "fake-run-error",
@@ -124,11 +172,13 @@ var _ = Describe("CpiCmdRunner", func() {
 				outputBytes, err := json.Marshal(cmdOutput)
 				Expect(err).NotTo(HaveOccurred())
 
-				result := fakesys.FakeCmdResult{
-					Stdout:     string(outputBytes),
-					ExitStatus: 0,
-				}
-				cmdRunner.AddCmdResult("/jobs/cpi/bin/cpi", result)
+				cmdRunner.AddProcess("/jobs/cpi/bin/cpi", &fakesys.FakeProcess{
+					WaitResult: boshsys.Result{
+						Stdout:     string(outputBytes),
+						Stderr:     stderr,
+						ExitStatus: 0,
+					},
+				})
 			})
 
 			It("returns the command output and no error", func() {
@@ -136,6 +186,102 @@ var _ = Describe("CpiCmdRunner", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(cmdOutput.Error.Message).To(ContainSubstring("fake-run-error"))
 			})
+
+			It("attaches the stderr of the CPI to the error", func() {
+				cmdOutput, err := cpiCmdRunner.Run(context, "fake-method", "fake-argument")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(cmdOutput.Error.Stderr).To(Equal("fake-cpi-stderr"))
+			})
+
+			Context("when the stderr is long", func() {
+				BeforeEach(func() {
+					stderr = strings.Repeat("a", 3000) + "fake-cause"
+				})
+
+				It("keeps the end of the stderr", func() {
+					cmdOutput, err := cpiCmdRunner.Run(context, "fake-method", "fake-argument")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(cmdOutput.Error.Stderr).To(HavePrefix("...aaa"))
+					Expect(cmdOutput.Error.Stderr).To(HaveSuffix("fake-cause"))
+					Expect(cmdOutput.Error.Stderr).To(HaveLen(2003))
+				})
+			})
+		})
+
+		Context("when starting the command fails", func() {
+			BeforeEach(func() {
+				cmdRunner.AddProcess("/jobs/cpi/bin/cpi", &fakesys.FakeProcess{
+					StartErr: errors.New("fake-start-error"),
+				})
+			})
+
+			It("returns an error", func() {
+				_, err := cpiCmdRunner.Run(context, "fake-method", "fake-argument")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Executing external CPI command: '/jobs/cpi/bin/cpi'"))
+				Expect(err.Error()).To(ContainSubstring("fake-start-error"))
+			})
+		})
+
+		Context("when the command does not finish within the method timeout", func() {
+			var process *fakesys.FakeProcess
+
+			BeforeEach(func() {
+				process = &fakesys.FakeProcess{
+					TerminatedNicelyCallBack: func(p *fakesys.FakeProcess) {
+						p.WaitCh <- boshsys.Result{Error: errors.New("fake-terminated")}
+					},
+				}
+				cmdRunner.AddProcess("/jobs/cpi/bin/cpi", process)
+			})
+
+			run := func(method string, timeout time.Duration) error {
+				errCh := make(chan error, 1)
+				go func() {
+					defer GinkgoRecover()
+					_, err := cpiCmdRunner.Run(context, method)
+					errCh <- err
+				}()
+
+				timeService.WaitForWatcherAndIncrement(timeout)
+
+				var err error
+				Eventually(errCh).Should(Receive(&err))
+				return err
+			}
+
+			It("terminates the command and returns a timeout error naming the method", func() {
+				err := run("fast-method", 1*time.Minute)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("CPI 'fast-method' method timed out after 1m0s"))
+
+				timeoutErr, ok := err.(TimeoutError)
+				Expect(ok).To(BeTrue())
+				Expect(timeoutErr.Method()).To(Equal("fast-method"))
+				Expect(timeoutErr.Timeout()).To(Equal(1 * time.Minute))
+
+				Expect(process.TerminatedNicely).To(BeTrue())
+			})
+
+			It("uses the default timeout for methods without their own timeout", func() {
+				err := run("slow-method", 10*time.Minute)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("CPI 'slow-method' method timed out after 10m0s"))
+				Expect(process.TerminatedNicely).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("CPIMethodTimeouts", func() {
+		It("returns the timeout of the method if configured, and the default otherwise", func() {
+			Expect(timeouts.For("fast-method")).To(Equal(1 * time.Minute))
+			Expect(timeouts.For("other-method")).To(Equal(10 * time.Minute))
+		})
+
+		It("gives fast methods shorter default timeouts than slow methods", func() {
+			defaults := NewDefaultCPIMethodTimeouts()
+			Expect(defaults.For("info")).To(BeNumerically("<", defaults.For("create_vm")))
+			Expect(defaults.For("unknown_method")).To(Equal(defaults.Default))
 		})
 	})
 })
@@ -0,0 +1,65 @@
+package cloud
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// MaxSupportedCPIAPIVersion is the newest version of the CPI API that requests can be made with.
+// Version 2 sends 'api_version' with each request and returns network settings from 'create_vm'.
+const MaxSupportedCPIAPIVersion = 2
+
+// CPIInfo is what CPIs report about themselves through the 'info' method.
+// CPIs that do not report an API version only speak version 1.
+type CPIInfo struct {
+	APIVersion      int
+	StemcellFormats []string
+}
+
+func parseCPIInfo(result map[string]interface{}) CPIInfo {
+	info := CPIInfo{APIVersion: 1}
+
+	if apiVersion, ok := result["api_version"].(float64); ok && apiVersion >= 1 {
+		info.APIVersion = int(apiVersion)
+	}
+
+	if formats, ok := result["stemcell_formats"].([]interface{}); ok {
+		for _, format := range formats {
+			if formatString, ok := format.(string); ok {
+				info.StemcellFormats = append(info.StemcellFormats, formatString)
+			}
+		}
+	}
+
+	return info
+}
+
+// negotiateCPIAPIVersion returns the version to make requests with.
+// A pinned version of 0 picks version 1 without asking the CPI, since the CLI does not keep
+// the disk hints that version 2 CPIs return from 'attach_disk' and expect to be sent to the agent.
+// Other pinned versions are checked against the version the CPI reports through 'info';
+// CPIs that do not implement 'info' only speak version 1.
+func negotiateCPIAPIVersion(pinnedVersion int, cloud Cloud) (int, error) {
+	if pinnedVersion == 0 {
+		return 1, nil
+	}
+
+	if pinnedVersion < 1 || pinnedVersion > MaxSupportedCPIAPIVersion {
+		return 0, bosherr.Errorf("CPI API version %d is not supported, expected a version from 1 to %d", pinnedVersion, MaxSupportedCPIAPIVersion)
+	}
+
+	info, err := cloud.Info()
+	if err != nil {
+		cloudErr, ok := err.(Error)
+		if !ok || cloudErr.Type() != NotImplementedError {
+			return 0, bosherr.WrapError(err, "Requesting CPI info")
+		}
+
+		info = CPIInfo{APIVersion: 1}
+	}
+
+	if pinnedVersion > info.APIVersion {
+		return 0, bosherr.Errorf("CPI API version %d was requested, but the installed CPI only supports version %d", pinnedVersion, info.APIVersion)
+	}
+
+	return pinnedVersion, nil
+}
@@ -0,0 +1,274 @@
+package cloud
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// CPIRecordingOpts selects whether CPI interactions are recorded to, or replayed from, a file.
+// At most one of the paths may be set.
+type CPIRecordingOpts struct {
+	RecordPath string
+	ReplayPath string
+}
+
+const redactedCPIValue = "<redacted>"
+
+// secretCPIKeyFragments are matched against lowercased property names to decide what to redact
+var secretCPIKeyFragments = []string{
+	"password",
+	"secret",
+	"private_key",
+	"token",
+	"credential",
+	"access_key",
+}
+
+type CPIInteraction struct {
+	Method    string        `json:"method"`
+	Arguments []interface{} `json:"arguments"`
+	Context   CmdContext    `json:"context"`
+	Output    CmdOutput     `json:"output"`
+	Error     string        `json:"error,omitempty"`
+}
+
+type CPIRecording struct {
+	Interactions []CPIInteraction `json:"interactions"`
+}
+
+type recordingCPICmdRunner struct {
+	cpiCmdRunner CPICmdRunner
+	path         string
+	fs           boshsys.FileSystem
+	logger       boshlog.Logger
+	logTag       string
+
+	recording CPIRecording
+	lock      sync.Mutex
+}
+
+// NewRecordingCPICmdRunner returns a CPICmdRunner that runs every command with cpiCmdRunner
+// and writes the redacted request and response to path after each one.
+func NewRecordingCPICmdRunner(cpiCmdRunner CPICmdRunner, path string, fs boshsys.FileSystem, logger boshlog.Logger) CPICmdRunner {
+	return &recordingCPICmdRunner{
+		cpiCmdRunner: cpiCmdRunner,
+		path:         path,
+		fs:           fs,
+		logger:       logger,
+		logTag:       "recordingCPICmdRunner",
+		recording:    CPIRecording{Interactions: []CPIInteraction{}},
+	}
+}
+
+func (r *recordingCPICmdRunner) Run(context CmdContext, method string, args ...interface{}) (CmdOutput, error) {
+	output, runErr := r.cpiCmdRunner.Run(context, method, args...)
+
+	interaction := CPIInteraction{
+		Method:  method,
+		Context: context,
+	}
+
+	var err error
+
+	interaction.Arguments, err = redactCPIArguments(args)
+	if err != nil {
+		return CmdOutput{}, bosherr.WrapErrorf(err, "Recording CPI method '%s'", method)
+	}
+
+	interaction.Output, err = redactCPIOutput(output)
+	if err != nil {
+		return CmdOutput{}, bosherr.WrapErrorf(err, "Recording CPI method '%s'", method)
+	}
+
+	if runErr != nil {
+		interaction.Error = runErr.Error()
+	}
+
+	err = r.record(interaction)
+	if err != nil {
+		return CmdOutput{}, err
+	}
+
+	return output, runErr
+}
+
+func (r *recordingCPICmdRunner) record(interaction CPIInteraction) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.recording.Interactions = append(r.recording.Interactions, interaction)
+
+	recordingBytes, err := json.MarshalIndent(r.recording, "", "  ")
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling CPI recording")
+	}
+
+	err = r.fs.WriteFile(r.path, recordingBytes)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing CPI recording '%s'", r.path)
+	}
+
+	r.logger.Debug(r.logTag, "Recorded CPI method '%s' to '%s'", interaction.Method, r.path)
+
+	return nil
+}
+
+type replayingCPICmdRunner struct {
+	path   string
+	fs     boshsys.FileSystem
+	logger boshlog.Logger
+	logTag string
+
+	recording *CPIRecording
+	replayed  []bool
+	lock      sync.Mutex
+}
+
+// NewReplayingCPICmdRunner returns a CPICmdRunner that never runs the CPI and instead answers
+// each command with the first not yet replayed interaction in path with the same method and arguments.
+func NewReplayingCPICmdRunner(path string, fs boshsys.FileSystem, logger boshlog.Logger) CPICmdRunner {
+	return &replayingCPICmdRunner{
+		path:   path,
+		fs:     fs,
+		logger: logger,
+		logTag: "replayingCPICmdRunner",
+	}
+}
+
+func (r *replayingCPICmdRunner) Run(context CmdContext, method string, args ...interface{}) (CmdOutput, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	err := r.load()
+	if err != nil {
+		return CmdOutput{}, err
+	}
+
+	redactedArgs, err := redactCPIArguments(args)
+	if err != nil {
+		return CmdOutput{}, bosherr.WrapErrorf(err, "Replaying CPI method '%s'", method)
+	}
+
+	for i, interaction := range r.recording.Interactions {
+		if r.replayed[i] || interaction.Method != method {
+			continue
+		}
+
+		if !reflect.DeepEqual(interaction.Arguments, redactedArgs) {
+			continue
+		}
+
+		r.replayed[i] = true
+		r.logger.Debug(r.logTag, "Replaying CPI method '%s' from interaction %d of '%s'", method, i, r.path)
+
+		if interaction.Error != "" {
+			return CmdOutput{}, bosherr.Error(interaction.Error)
+		}
+
+		return interaction.Output, nil
+	}
+
+	argsBytes, err := json.Marshal(redactedArgs)
+	if err != nil {
+		return CmdOutput{}, bosherr.WrapErrorf(err, "Replaying CPI method '%s'", method)
+	}
+
+	return CmdOutput{}, bosherr.Errorf("No unreplayed interaction in CPI recording '%s' matches method '%s' with arguments %s", r.path, method, string(argsBytes))
+}
+
+func (r *replayingCPICmdRunner) load() error {
+	if r.recording != nil {
+		return nil
+	}
+
+	recordingBytes, err := r.fs.ReadFile(r.path)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Reading CPI recording '%s'", r.path)
+	}
+
+	recording := CPIRecording{}
+	err = json.Unmarshal(recordingBytes, &recording)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Unmarshalling CPI recording '%s'", r.path)
+	}
+
+	r.recording = &recording
+	r.replayed = make([]bool, len(recording.Interactions))
+
+	return nil
+}
+
+// redactCPIArguments converts args to their JSON form, as sent to the CPI, and redacts secrets
+// so that recorded and live arguments can be compared.
+func redactCPIArguments(args []interface{}) ([]interface{}, error) {
+	argsBytes, err := json.Marshal(args)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Marshalling CPI arguments")
+	}
+
+	redactedArgs := []interface{}{}
+	err = json.Unmarshal(argsBytes, &redactedArgs)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Unmarshalling CPI arguments")
+	}
+
+	for i, arg := range redactedArgs {
+		redactedArgs[i] = redactCPIValue(arg)
+	}
+
+	return redactedArgs, nil
+}
+
+func redactCPIOutput(output CmdOutput) (CmdOutput, error) {
+	resultBytes, err := json.Marshal(output.Result)
+	if err != nil {
+		return CmdOutput{}, bosherr.WrapError(err, "Marshalling CPI result")
+	}
+
+	var result interface{}
+	err = json.Unmarshal(resultBytes, &result)
+	if err != nil {
+		return CmdOutput{}, bosherr.WrapError(err, "Unmarshalling CPI result")
+	}
+
+	output.Result = redactCPIValue(result)
+	// CPI logs are free form and may echo secrets back, so they are not recorded
+	output.Log = ""
+
+	return output, nil
+}
+
+func redactCPIValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		for key, nestedValue := range typedValue {
+			if isSecretCPIKey(key) {
+				typedValue[key] = redactedCPIValue
+			} else {
+				typedValue[key] = redactCPIValue(nestedValue)
+			}
+		}
+	case []interface{}:
+		for i, nestedValue := range typedValue {
+			typedValue[i] = redactCPIValue(nestedValue)
+		}
+	}
+
+	return value
+}
+
+func isSecretCPIKey(key string) bool {
+	lowerKey := strings.ToLower(key)
+	for _, fragment := range secretCPIKeyFragments {
+		if strings.Contains(lowerKey, fragment) {
+			return true
+		}
+	}
+	return false
+}
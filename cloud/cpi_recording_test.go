@@ -0,0 +1,203 @@
+package cloud_test
+
+import (
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cloud"
+	fakebicloud "github.com/cloudfoundry/bosh-cli/cloud/fakes"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+)
+
+var _ = Describe("CPI recording", func() {
+	const recordingPath = "/fake-recording.json"
+
+	var (
+		fs           *fakesys.FakeFileSystem
+		logger       boshlog.Logger
+		cpiCmdRunner *fakebicloud.FakeCPICmdRunner
+		context      CmdContext
+		cloudProps   map[string]interface{}
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		logger = boshlog.NewLogger(boshlog.LevelNone)
+		cpiCmdRunner = fakebicloud.NewFakeCPICmdRunner()
+		context = CmdContext{DirectorID: "fake-director-id"}
+		cloudProps = map[string]interface{}{
+			"instance_type": "m1.small",
+			"credentials": map[string]interface{}{
+				"user": "admin",
+			},
+			"nested": []interface{}{
+				map[string]interface{}{"Password": "fake-password"},
+			},
+		}
+	})
+
+	readRecording := func() CPIRecording {
+		recordingBytes, err := fs.ReadFile(recordingPath)
+		Expect(err).ToNot(HaveOccurred())
+
+		recording := CPIRecording{}
+		err = json.Unmarshal(recordingBytes, &recording)
+		Expect(err).ToNot(HaveOccurred())
+
+		return recording
+	}
+
+	Describe("RecordingCPICmdRunner", func() {
+		var runner CPICmdRunner
+
+		BeforeEach(func() {
+			runner = NewRecordingCPICmdRunner(cpiCmdRunner, recordingPath, fs, logger)
+		})
+
+		It("passes commands through and records them with secrets redacted", func() {
+			cpiCmdRunner.RunCmdOutput = CmdOutput{Result: "fake-vm-cid", Log: "fake-log with fake-password"}
+
+			output, err := runner.Run(context, "create_vm", "fake-agent-id", "fake-stemcell-cid", cloudProps)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(output).To(Equal(CmdOutput{Result: "fake-vm-cid", Log: "fake-log with fake-password"}))
+
+			Expect(cpiCmdRunner.RunInputs).To(Equal([]fakebicloud.RunInput{
+				{
+					Context:   context,
+					Method:    "create_vm",
+					Arguments: []interface{}{"fake-agent-id", "fake-stemcell-cid", cloudProps},
+				},
+			}))
+
+			Expect(readRecording()).To(Equal(CPIRecording{
+				Interactions: []CPIInteraction{
+					{
+						Method: "create_vm",
+						Arguments: []interface{}{
+							"fake-agent-id",
+							"fake-stemcell-cid",
+							map[string]interface{}{
+								"instance_type": "m1.small",
+								"credentials":   "<redacted>",
+								"nested": []interface{}{
+									map[string]interface{}{"Password": "<redacted>"},
+								},
+							},
+						},
+						Context: context,
+						Output:  CmdOutput{Result: "fake-vm-cid"},
+					},
+				},
+			}))
+		})
+
+		It("does not modify the arguments passed to the CPI", func() {
+			_, err := runner.Run(context, "create_vm", cloudProps)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cloudProps["nested"]).To(Equal([]interface{}{
+				map[string]interface{}{"Password": "fake-password"},
+			}))
+		})
+
+		It("records failed commands", func() {
+			cpiCmdRunner.RunErr = errors.New("fake-run-error")
+
+			_, err := runner.Run(context, "delete_vm", "fake-vm-cid")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("fake-run-error"))
+
+			Expect(readRecording().Interactions[0].Error).To(Equal("fake-run-error"))
+		})
+
+		It("returns an error when writing the recording fails", func() {
+			fs.WriteFileError = errors.New("fake-write-error")
+
+			_, err := runner.Run(context, "delete_vm", "fake-vm-cid")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Writing CPI recording '/fake-recording.json'"))
+		})
+	})
+
+	Describe("ReplayingCPICmdRunner", func() {
+		var runner CPICmdRunner
+
+		BeforeEach(func() {
+			recorder := NewRecordingCPICmdRunner(cpiCmdRunner, recordingPath, fs, logger)
+
+			cpiCmdRunner.RunCmdOutput = CmdOutput{Result: "fake-vm-cid-1"}
+			_, err := recorder.Run(context, "create_vm", "fake-agent-id", cloudProps)
+			Expect(err).ToNot(HaveOccurred())
+
+			cpiCmdRunner.RunCmdOutput = CmdOutput{Result: "fake-vm-cid-2"}
+			_, err = recorder.Run(context, "create_vm", "fake-agent-id", cloudProps)
+			Expect(err).ToNot(HaveOccurred())
+
+			cpiCmdRunner.RunCmdOutput = CmdOutput{}
+			cpiCmdRunner.RunErr = errors.New("fake-run-error")
+			_, err = recorder.Run(context, "delete_vm", "fake-vm-cid-1")
+			Expect(err).To(HaveOccurred())
+
+			runner = NewReplayingCPICmdRunner(recordingPath, fs, logger)
+		})
+
+		It("replays matching interactions in recorded order", func() {
+			output, err := runner.Run(context, "create_vm", "fake-agent-id", cloudProps)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(output.Result).To(Equal("fake-vm-cid-1"))
+
+			output, err = runner.Run(context, "create_vm", "fake-agent-id", cloudProps)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(output.Result).To(Equal("fake-vm-cid-2"))
+		})
+
+		It("matches arguments whose secrets differ from the recording", func() {
+			cloudProps["credentials"] = "other-credentials"
+
+			output, err := runner.Run(context, "create_vm", "fake-agent-id", cloudProps)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(output.Result).To(Equal("fake-vm-cid-1"))
+		})
+
+		It("replays recorded errors", func() {
+			_, err := runner.Run(context, "delete_vm", "fake-vm-cid-1")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("fake-run-error"))
+		})
+
+		It("returns an error when no interaction matches the arguments", func() {
+			_, err := runner.Run(context, "delete_vm", "fake-other-vm-cid")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`No unreplayed interaction in CPI recording '/fake-recording.json' matches method 'delete_vm' with arguments ["fake-other-vm-cid"]`))
+		})
+
+		It("returns an error when every matching interaction has been replayed", func() {
+			_, err := runner.Run(context, "delete_vm", "fake-vm-cid-1")
+			Expect(err).To(HaveOccurred())
+
+			_, err = runner.Run(context, "delete_vm", "fake-vm-cid-1")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("No unreplayed interaction in CPI recording '/fake-recording.json' matches method 'delete_vm'"))
+		})
+
+		It("never runs the CPI", func() {
+			cpiCmdRunner.RunInputs = nil
+
+			_, err := runner.Run(context, "create_vm", "fake-agent-id", cloudProps)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cpiCmdRunner.RunInputs).To(BeEmpty())
+		})
+
+		It("returns an error when the recording cannot be read", func() {
+			runner = NewReplayingCPICmdRunner("/fake-missing-recording.json", fs, logger)
+
+			_, err := runner.Run(context, "delete_vm", "fake-vm-cid-1")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Reading CPI recording '/fake-missing-recording.json'"))
+		})
+	})
+})
@@ -0,0 +1,65 @@
+package cloud
+
+import (
+	"time"
+)
+
+// CPIMethodTimeoutDefault names the Default timeout when overriding timeouts
+const CPIMethodTimeoutDefault = "default"
+
+// CPIMethodTimeouts bounds how long a single CPI method invocation may run.
+// Methods without an explicit entry use Default; a zero duration disables the timeout.
+type CPIMethodTimeouts struct {
+	Default time.Duration
+	Methods map[string]time.Duration
+}
+
+func NewDefaultCPIMethodTimeouts() CPIMethodTimeouts {
+	return CPIMethodTimeouts{
+		Default: 30 * time.Minute,
+		Methods: map[string]time.Duration{
+			"info":              1 * time.Minute,
+			"current_vm_id":     1 * time.Minute,
+			"has_vm":            5 * time.Minute,
+			"has_disk":          5 * time.Minute,
+			"set_vm_metadata":   5 * time.Minute,
+			"set_disk_metadata": 5 * time.Minute,
+			"reboot_vm":         15 * time.Minute,
+			"attach_disk":       15 * time.Minute,
+			"detach_disk":       15 * time.Minute,
+			"delete_disk":       15 * time.Minute,
+			"delete_vm":         15 * time.Minute,
+			"delete_stemcell":   15 * time.Minute,
+			"create_disk":       30 * time.Minute,
+			"snapshot_disk":     30 * time.Minute,
+			"create_vm":         60 * time.Minute,
+			"create_stemcell":   60 * time.Minute,
+		},
+	}
+}
+
+func (t CPIMethodTimeouts) For(method string) time.Duration {
+	if timeout, found := t.Methods[method]; found {
+		return timeout
+	}
+
+	return t.Default
+}
+
+// Override returns a copy of the timeouts with the timeout of method replaced,
+// or with Default replaced for CPIMethodTimeoutDefault
+func (t CPIMethodTimeouts) Override(method string, timeout time.Duration) CPIMethodTimeouts {
+	overridden := CPIMethodTimeouts{Default: t.Default, Methods: map[string]time.Duration{}}
+
+	for name, methodTimeout := range t.Methods {
+		overridden.Methods[name] = methodTimeout
+	}
+
+	if method == CPIMethodTimeoutDefault {
+		overridden.Default = timeout
+	} else {
+		overridden.Methods[method] = timeout
+	}
+
+	return overridden
+}
@@ -3,6 +3,9 @@ package cloud
 import (
 	"fmt"
 	"regexp"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 )
 
 const (
@@ -10,8 +13,19 @@ const (
 	DiskNotFoundError     = "Bosh::Clouds::DiskNotFound"
 	StemcellNotFoundError = "Bosh::Clouds::StemcellNotFound"
 	NotImplementedError   = "Bosh::Clouds::NotImplemented"
+	VMCreationFailedError = "Bosh::Clouds::VMCreationFailed"
+	NoDiskSpaceError      = "Bosh::Clouds::NoDiskSpace"
+	DiskNotAttachedError  = "Bosh::Clouds::DiskNotAttached"
 )
 
+// cpiErrorHints suggest what to check for errors that are usually caused by the IaaS
+// or the manifest rather than by the CPI
+var cpiErrorHints = map[string]string{
+	VMCreationFailedError: "The IaaS failed to create the VM. Check the quotas of the IaaS account and the cloud_properties of the resource pool, e.g. the instance type and availability zone.",
+	NoDiskSpaceError:      "The IaaS has no space for the disk. Check the disk quotas of the IaaS account or reduce the disk size.",
+	DiskNotAttachedError:  "The disk is not attached to the VM in the IaaS. Check whether it was detached outside of bosh.",
+}
+
 type Error interface {
 	error
 	Method() string
@@ -37,7 +51,17 @@ func NewCPIError(method string, cmdError CmdError) Error {
 }
 
 func (e cpiError) Error() string {
-	return fmt.Sprintf("CPI '%s' method responded with error: %s", e.method, e.cmdError)
+	message := fmt.Sprintf("CPI '%s' method responded with error: %s", e.method, e.cmdError)
+
+	if hint, found := cpiErrorHints[e.cmdError.Type]; found {
+		message += "\n" + hint
+	}
+
+	if len(e.cmdError.Stderr) > 0 {
+		message += "\nCPI stderr:\n" + e.cmdError.Stderr
+	}
+
+	return message
 }
 
 func (e cpiError) Method() string {
@@ -56,6 +80,56 @@ func (e cpiError) OkToRetry() bool {
 	return e.cmdError.OkToRetry
 }
 
+type TimeoutError interface {
+	error
+	Method() string
+	Timeout() time.Duration
+}
+
+type cpiTimeoutError struct {
+	method  string
+	timeout time.Duration
+}
+
+func NewCPITimeoutError(method string, timeout time.Duration) TimeoutError {
+	return cpiTimeoutError{
+		method:  method,
+		timeout: timeout,
+	}
+}
+
+func (e cpiTimeoutError) Error() string {
+	return fmt.Sprintf("CPI '%s' method timed out after %s", e.method, e.timeout)
+}
+
+func (e cpiTimeoutError) Method() string {
+	return e.method
+}
+
+func (e cpiTimeoutError) Timeout() time.Duration {
+	return e.timeout
+}
+
+// IsRetryable reports whether a failed CPI call may succeed if repeated later,
+// which CPIs signal by flagging the error as ok_to_retry, e.g. when the IaaS
+// reports that a stemcell is still used by a VM that is being torn down.
+func IsRetryable(err error) bool {
+	for {
+		complexErr, ok := err.(bosherr.ComplexError)
+		if !ok {
+			break
+		}
+		err = complexErr.Cause
+	}
+
+	cloudErr, ok := err.(Error)
+	if !ok {
+		return false
+	}
+
+	return cloudErr.OkToRetry()
+}
+
 func mapsToNotImplementedError(method string, cmdError CmdError) bool {
 	matched, _ := regexp.MatchString("^Invalid Method:", cmdError.Message)
 
@@ -74,8 +148,9 @@ func mapsToNotImplementedError(method string, cmdError CmdError) bool {
 
 func newNotImplementedCmdError(method string, cmdError CmdError) CmdError {
 	return CmdError{
-		NotImplementedError,
-		fmt.Sprintf("CPI error '%s' with message '%s' in '%s' CPI method", cmdError.Type, cmdError.Message, method),
-		cmdError.OkToRetry,
+		Type:      NotImplementedError,
+		Message:   fmt.Sprintf("CPI error '%s' with message '%s' in '%s' CPI method", cmdError.Type, cmdError.Message, method),
+		OkToRetry: cmdError.OkToRetry,
+		Stderr:    cmdError.Stderr,
 	}
 }
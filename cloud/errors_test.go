@@ -1,6 +1,10 @@
 package cloud_test
 
 import (
+	"errors"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
 	"github.com/cloudfoundry/bosh-cli/cloud"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -95,3 +99,50 @@ var _ = Describe("Error", func() {
 		})
 	})
 })
+
+var _ = Describe("Error message", func() {
+	It("includes the error of the CPI", func() {
+		err := cloud.NewCPIError("delete_vm", cloud.CmdError{Type: "Bosh::Clouds::CloudError", Message: "fake-message"})
+		Expect(err.Error()).To(Equal(`CPI 'delete_vm' method responded with error: CmdError{"type":"Bosh::Clouds::CloudError","message":"fake-message","ok_to_retry":false}`))
+	})
+
+	It("suggests what to check for errors usually caused by the IaaS", func() {
+		err := cloud.NewCPIError("create_vm", cloud.CmdError{Type: "Bosh::Clouds::VMCreationFailed", Message: "fake-message"})
+		Expect(err.Error()).To(Equal(
+			`CPI 'create_vm' method responded with error: CmdError{"type":"Bosh::Clouds::VMCreationFailed","message":"fake-message","ok_to_retry":false}` +
+				"\nThe IaaS failed to create the VM. Check the quotas of the IaaS account and the cloud_properties of the resource pool, e.g. the instance type and availability zone.",
+		))
+	})
+
+	It("includes the stderr of the CPI", func() {
+		err := cloud.NewCPIError("create_disk", cloud.CmdError{Type: "Bosh::Clouds::CloudError", Message: "fake-message", Stderr: "fake-stderr"})
+		Expect(err.Error()).To(HaveSuffix("\nCPI stderr:\nfake-stderr"))
+	})
+})
+
+var _ = Describe("IsRetryable", func() {
+	It("returns true when the CPI marks the error as ok to retry", func() {
+		err := cloud.NewCPIError("delete_stemcell", cloud.CmdError{Type: "Bosh::Clouds::CloudError", Message: "some-message", OkToRetry: true})
+		Expect(cloud.IsRetryable(err)).To(BeTrue())
+	})
+
+	It("returns false when only the message reports that the resource is in use", func() {
+		err := cloud.NewCPIError("delete_stemcell", cloud.CmdError{Type: "Bosh::Clouds::CloudError", Message: "Image is still In Use"})
+		Expect(cloud.IsRetryable(err)).To(BeFalse())
+	})
+
+	It("returns true when a retryable CPI error is wrapped", func() {
+		err := cloud.NewCPIError("delete_stemcell", cloud.CmdError{Type: "Bosh::Clouds::CloudError", Message: "some-message", OkToRetry: true})
+		Expect(cloud.IsRetryable(bosherr.WrapError(err, "Deleting stemcell from cloud"))).To(BeTrue())
+	})
+
+	It("returns false for other CPI errors", func() {
+		err := cloud.NewCPIError("delete_stemcell", cloud.CmdError{Type: "Bosh::Clouds::CloudError", Message: "some-message"})
+		Expect(cloud.IsRetryable(err)).To(BeFalse())
+	})
+
+	It("returns false for errors that are not CPI errors", func() {
+		Expect(cloud.IsRetryable(errors.New("some-error"))).To(BeFalse())
+		Expect(cloud.IsRetryable(nil)).To(BeFalse())
+	})
+})
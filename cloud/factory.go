@@ -1,7 +1,13 @@
 package cloud
 
 import (
+	"strings"
+
+	"code.cloudfoundry.org/clock"
+
+	bicrypto "github.com/cloudfoundry/bosh-cli/crypto"
 	biinstall "github.com/cloudfoundry/bosh-cli/installation"
+	biretry "github.com/cloudfoundry/bosh-cli/retry"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
@@ -12,20 +18,44 @@ type Factory interface {
 }
 
 type factory struct {
-	fs        boshsys.FileSystem
-	cmdRunner boshsys.CmdRunner
-	logger    boshlog.Logger
+	fs               boshsys.FileSystem
+	cmdRunner        boshsys.CmdRunner
+	timeouts         CPIMethodTimeouts
+	timeService      clock.Clock
+	digestCalculator bicrypto.DigestCalculator
+	recording        CPIRecordingOpts
+	retrier          biretry.Retrier
+	apiVersion       int
+	logger           boshlog.Logger
+	logTag           string
 }
 
+// NewFactory returns a Factory whose digestCalculator must produce SHA256 digests,
+// since that is what installation manifests declare for the CPI executable.
+// A non-zero apiVersion pins the CPI API version of all clouds, taking precedence over installation manifests.
+// Methods of the clouds that are safe to call again are retried by retrier.
 func NewFactory(
 	fs boshsys.FileSystem,
 	cmdRunner boshsys.CmdRunner,
+	timeouts CPIMethodTimeouts,
+	timeService clock.Clock,
+	digestCalculator bicrypto.DigestCalculator,
+	recording CPIRecordingOpts,
+	retrier biretry.Retrier,
+	apiVersion int,
 	logger boshlog.Logger,
 ) Factory {
 	return &factory{
-		fs:        fs,
-		cmdRunner: cmdRunner,
-		logger:    logger,
+		fs:               fs,
+		cmdRunner:        cmdRunner,
+		timeouts:         timeouts,
+		timeService:      timeService,
+		digestCalculator: digestCalculator,
+		recording:        recording,
+		retrier:          retrier,
+		apiVersion:       apiVersion,
+		logger:           logger,
+		logTag:           "cloudFactory",
 	}
 }
 
@@ -43,6 +73,84 @@ func (f *factory) NewCloud(installation biinstall.Installation, directorID strin
 		return nil, bosherr.Errorf("Installed CPI job '%s' does not contain the required executable '%s'", cpiJob.Name, cmdPath)
 	}
 
-	cpiCmdRunner := NewCPICmdRunner(f.cmdRunner, cpi, f.logger)
-	return NewCloud(cpiCmdRunner, directorID, f.logger), nil
+	err := f.verifyExecutable(cmdPath, installation.ExpectedCPIDigest())
+	if err != nil {
+		return nil, err
+	}
+
+	cpiCmdRunner, err := f.newCPICmdRunner(cpi)
+	if err != nil {
+		return nil, err
+	}
+
+	apiVersion, err := f.negotiateAPIVersion(cpiCmdRunner, directorID, installation)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Checking CPI API version of CPI job '%s'", cpiJob.Name)
+	}
+
+	cloud := NewCloud(cpiCmdRunner, directorID, apiVersion, f.logger)
+
+	return NewRetryingCloud(cloud, f.retrier), nil
+}
+
+// negotiateAPIVersion checks a pinned version against the CPI before any other request is made,
+// so that an incompatible CPI is refused up front instead of rejecting requests while deploying
+func (f *factory) negotiateAPIVersion(cpiCmdRunner CPICmdRunner, directorID string, installation biinstall.Installation) (int, error) {
+	pinnedVersion := f.apiVersion
+	if pinnedVersion == 0 {
+		pinnedVersion = installation.CPIAPIVersion()
+	}
+
+	apiVersion, err := negotiateCPIAPIVersion(pinnedVersion, NewCloud(cpiCmdRunner, directorID, 0, f.logger))
+	if err != nil {
+		return 0, err
+	}
+
+	f.logger.Info(f.logTag, "Using CPI API version %d", apiVersion)
+
+	return apiVersion, nil
+}
+
+func (f *factory) newCPICmdRunner(cpi CPI) (CPICmdRunner, error) {
+	if f.recording.RecordPath != "" && f.recording.ReplayPath != "" {
+		return nil, bosherr.Error("CPI interactions cannot be recorded and replayed at the same time")
+	}
+
+	if f.recording.ReplayPath != "" {
+		f.logger.Info(f.logTag, "Replaying CPI interactions from '%s' instead of running '%s'", f.recording.ReplayPath, cpi.ExecutablePath())
+		return NewReplayingCPICmdRunner(f.recording.ReplayPath, f.fs, f.logger), nil
+	}
+
+	cpiCmdRunner := NewCPICmdRunner(f.cmdRunner, cpi, f.timeouts, f.timeService, f.logger)
+
+	if f.recording.RecordPath != "" {
+		f.logger.Info(f.logTag, "Recording CPI interactions to '%s'", f.recording.RecordPath)
+		return NewRecordingCPICmdRunner(cpiCmdRunner, f.recording.RecordPath, f.fs, f.logger), nil
+	}
+
+	return cpiCmdRunner, nil
+}
+
+// verifyExecutable refuses a CPI executable whose SHA256 digest does not match the expected one.
+func (f *factory) verifyExecutable(cmdPath string, expectedDigest string) error {
+	if expectedDigest == "" {
+		f.logger.Debug(f.logTag, "No CPI digest declared, skipping verification of '%s'", cmdPath)
+		return nil
+	}
+
+	expectedDigest = "sha256:" + strings.TrimPrefix(expectedDigest, "sha256:")
+
+	actualDigest, err := f.digestCalculator.Calculate(cmdPath)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Verifying CPI executable '%s'", cmdPath)
+	}
+
+	if actualDigest != expectedDigest {
+		f.logger.Error(f.logTag, "CPI executable '%s' failed verification: expected digest '%s', got '%s'", cmdPath, expectedDigest, actualDigest)
+		return bosherr.Errorf("CPI executable '%s' has digest '%s' but expected '%s', refusing to run it", cmdPath, actualDigest, expectedDigest)
+	}
+
+	f.logger.Info(f.logTag, "Verified CPI executable '%s' with digest '%s'", cmdPath, actualDigest)
+
+	return nil
 }
@@ -0,0 +1,274 @@
+package cloud_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cloud"
+	fakebicrypto "github.com/cloudfoundry/bosh-cli/crypto/fakes"
+	biinstall "github.com/cloudfoundry/bosh-cli/installation"
+	mock_install "github.com/cloudfoundry/bosh-cli/installation/mocks"
+	biretry "github.com/cloudfoundry/bosh-cli/retry"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+)
+
+var _ = Describe("Factory", func() {
+	var (
+		mockCtrl         *gomock.Controller
+		mockInstallation *mock_install.MockInstallation
+		fs               *fakesys.FakeFileSystem
+		cmdRunner        *fakesys.FakeCmdRunner
+		digestCalculator *fakebicrypto.FakeDigestCalculator
+		logger           boshlog.Logger
+		factory          Factory
+
+		expectedDigest   string
+		pinnedAPIVersion int
+		cpiInfoOutput    string
+	)
+
+	const cpiExecutablePath = "/fake-cpi-job/bin/cpi"
+	const cpiDigest = "sha256:3a6eb0790f39ac87c94f3856b2dd2c5d110e6811602261a9a923d3bb23adc8b7"
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockInstallation = mock_install.NewMockInstallation(mockCtrl)
+
+		fs = fakesys.NewFakeFileSystem()
+		fs.WriteFileString(cpiExecutablePath, "fake-cpi")
+
+		digestCalculator = fakebicrypto.NewFakeDigestCalculator()
+		digestCalculator.SetCalculateBehavior(map[string]fakebicrypto.CalculateInput{
+			cpiExecutablePath: {DigestStr: cpiDigest},
+		})
+
+		logger = boshlog.NewLogger(boshlog.LevelNone)
+		cmdRunner = fakesys.NewFakeCmdRunner()
+		factory = NewFactory(fs, cmdRunner, NewDefaultCPIMethodTimeouts(), fakeclock.NewFakeClock(time.Now()), digestCalculator, CPIRecordingOpts{}, biretry.NewRetrier(biretry.NewDefaultConfig(), fakeclock.NewFakeClock(time.Now()), biwarn.NewWarnings(logger), logger), 0, logger)
+
+		expectedDigest = ""
+		pinnedAPIVersion = 0
+		cpiInfoOutput = ""
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	JustBeforeEach(func() {
+		mockInstallation.EXPECT().Job().Return(biinstall.InstalledJob{
+			RenderedJobRef: biinstall.RenderedJobRef{Name: "fake-cpi-job"},
+			Path:           "/fake-cpi-job",
+		}).AnyTimes()
+		mockInstallation.EXPECT().Target().Return(biinstall.NewTarget("/fake-installation")).AnyTimes()
+		mockInstallation.EXPECT().ExpectedCPIDigest().Return(expectedDigest).AnyTimes()
+		mockInstallation.EXPECT().CPIAPIVersion().Return(pinnedAPIVersion).AnyTimes()
+
+		if cpiInfoOutput != "" {
+			cmdRunner.AddProcess(cpiExecutablePath, &fakesys.FakeProcess{
+				WaitResult: boshsys.Result{Stdout: cpiInfoOutput},
+			})
+		}
+	})
+
+	expectAPIVersion := func(cloud Cloud, apiVersion int) {
+		cmdRunner.AddProcess(cpiExecutablePath, &fakesys.FakeProcess{
+			WaitResult: boshsys.Result{Stdout: `{"result":null}`},
+		})
+
+		err := cloud.DeleteVM("fake-vm-cid")
+		Expect(err).ToNot(HaveOccurred())
+
+		lastCommand := cmdRunner.RunComplexCommands[len(cmdRunner.RunComplexCommands)-1]
+		stdin, err := ioutil.ReadAll(lastCommand.Stdin)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(stdin)).To(ContainSubstring(fmt.Sprintf(`"api_version":%d`, apiVersion)))
+	}
+
+	Describe("NewCloud", func() {
+		It("returns a cloud when no CPI digest is declared", func() {
+			cloud, err := factory.NewCloud(mockInstallation, "fake-director-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cloud).ToNot(BeNil())
+		})
+
+		It("returns an error when the CPI executable is missing", func() {
+			err := fs.RemoveAll(cpiExecutablePath)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = factory.NewCloud(mockInstallation, "fake-director-id")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("does not contain the required executable '/fake-cpi-job/bin/cpi'"))
+		})
+
+		Context("when a CPI digest is declared", func() {
+			Context("and the executable matches", func() {
+				BeforeEach(func() {
+					expectedDigest = cpiDigest
+				})
+
+				It("returns a cloud", func() {
+					cloud, err := factory.NewCloud(mockInstallation, "fake-director-id")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(cloud).ToNot(BeNil())
+				})
+			})
+
+			Context("without the algorithm prefix", func() {
+				BeforeEach(func() {
+					expectedDigest = "3a6eb0790f39ac87c94f3856b2dd2c5d110e6811602261a9a923d3bb23adc8b7"
+				})
+
+				It("treats it as a SHA256 digest", func() {
+					_, err := factory.NewCloud(mockInstallation, "fake-director-id")
+					Expect(err).ToNot(HaveOccurred())
+				})
+			})
+
+			Context("and the executable does not match", func() {
+				BeforeEach(func() {
+					expectedDigest = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+				})
+
+				It("refuses to run the CPI", func() {
+					_, err := factory.NewCloud(mockInstallation, "fake-director-id")
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("CPI executable '/fake-cpi-job/bin/cpi' has digest '" + cpiDigest + "' but expected 'sha256:0000000000000000000000000000000000000000000000000000000000000000', refusing to run it"))
+				})
+			})
+
+			Context("and calculating the digest fails", func() {
+				BeforeEach(func() {
+					expectedDigest = cpiDigest
+					digestCalculator.SetCalculateBehavior(map[string]fakebicrypto.CalculateInput{
+						cpiExecutablePath: {Err: errors.New("fake-calculate-error")},
+					})
+				})
+
+				It("returns an error", func() {
+					_, err := factory.NewCloud(mockInstallation, "fake-director-id")
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("fake-calculate-error"))
+				})
+			})
+		})
+
+		Describe("CPI API version", func() {
+			It("uses version 1 without asking the CPI for its info", func() {
+				cloud, err := factory.NewCloud(mockInstallation, "fake-director-id")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(cmdRunner.RunComplexCommands).To(BeEmpty())
+
+				expectAPIVersion(cloud, 1)
+			})
+
+			Context("when the manifest pins a version", func() {
+				BeforeEach(func() {
+					pinnedAPIVersion = 2
+					cpiInfoOutput = `{"result":{"api_version":2,"stemcell_formats":["fake-format"]}}`
+				})
+
+				It("asks the CPI for its info before making other requests", func() {
+					_, err := factory.NewCloud(mockInstallation, "fake-director-id")
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(cmdRunner.RunComplexCommands).To(HaveLen(1))
+					stdin, err := ioutil.ReadAll(cmdRunner.RunComplexCommands[0].Stdin)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(string(stdin)).To(Equal(`{"method":"info","arguments":[],"context":{"director_uuid":"fake-director-id"}}`))
+				})
+
+				It("uses the pinned version", func() {
+					cloud, err := factory.NewCloud(mockInstallation, "fake-director-id")
+					Expect(err).ToNot(HaveOccurred())
+					expectAPIVersion(cloud, 2)
+				})
+
+				It("is overridden by the version the factory pins", func() {
+					factory = NewFactory(fs, cmdRunner, NewDefaultCPIMethodTimeouts(), fakeclock.NewFakeClock(time.Now()), digestCalculator, CPIRecordingOpts{}, biretry.NewRetrier(biretry.NewDefaultConfig(), fakeclock.NewFakeClock(time.Now()), biwarn.NewWarnings(logger), logger), 1, logger)
+
+					cloud, err := factory.NewCloud(mockInstallation, "fake-director-id")
+					Expect(err).ToNot(HaveOccurred())
+					expectAPIVersion(cloud, 1)
+				})
+			})
+
+			Context("when the pinned version is newer than the CPI supports", func() {
+				BeforeEach(func() {
+					pinnedAPIVersion = 2
+					cpiInfoOutput = `{"result":{"api_version":1}}`
+				})
+
+				It("returns an error before making other requests", func() {
+					_, err := factory.NewCloud(mockInstallation, "fake-director-id")
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("Checking CPI API version of CPI job 'fake-cpi-job': CPI API version 2 was requested, but the installed CPI only supports version 1"))
+					Expect(cmdRunner.RunComplexCommands).To(HaveLen(1))
+				})
+			})
+
+			Context("when the CPI does not implement the info method", func() {
+				BeforeEach(func() {
+					pinnedAPIVersion = 1
+					cpiInfoOutput = `{"result":null,"error":{"type":"Bosh::Clouds::CloudError","message":"Invalid Method: info","ok_to_retry":false}}`
+				})
+
+				It("treats the CPI as supporting version 1", func() {
+					cloud, err := factory.NewCloud(mockInstallation, "fake-director-id")
+					Expect(err).ToNot(HaveOccurred())
+					expectAPIVersion(cloud, 1)
+				})
+			})
+
+			Context("when the info method fails", func() {
+				BeforeEach(func() {
+					pinnedAPIVersion = 1
+					cpiInfoOutput = `{"result":null,"error":{"type":"Bosh::Clouds::CloudError","message":"fake-credentials-error","ok_to_retry":false}}`
+				})
+
+				It("returns the error", func() {
+					_, err := factory.NewCloud(mockInstallation, "fake-director-id")
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Requesting CPI info: CPI 'info' method responded with error"))
+					Expect(err.Error()).To(ContainSubstring("fake-credentials-error"))
+				})
+			})
+
+			Context("when the pinned version is not supported by the CLI", func() {
+				BeforeEach(func() {
+					pinnedAPIVersion = 3
+				})
+
+				It("returns an error without asking the CPI", func() {
+					_, err := factory.NewCloud(mockInstallation, "fake-director-id")
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("CPI API version 3 is not supported, expected a version from 1 to 2"))
+					Expect(cmdRunner.RunComplexCommands).To(BeEmpty())
+				})
+			})
+
+			Context("when the info method cannot be called", func() {
+				BeforeEach(func() {
+					pinnedAPIVersion = 1
+					cpiInfoOutput = "fake-invalid-json"
+				})
+
+				It("returns an error", func() {
+					_, err := factory.NewCloud(mockInstallation, "fake-director-id")
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Requesting CPI info"))
+				})
+			})
+		})
+	})
+})
@@ -14,6 +14,10 @@ type FakeCloud struct {
 	HasVMFound bool
 	HasVMErr   error
 
+	HasDiskInput HasDiskInput
+	HasDiskFound bool
+	HasDiskErr   error
+
 	CreateVMInput CreateVMInput
 	CreateVMCID   string
 	CreateVMErr   error
@@ -44,6 +48,12 @@ type FakeCloud struct {
 	SetDiskMetadataCid      string
 	SetDiskMetadataMetadata cloud.DiskMetadata
 	SetDiskMetadataError    error
+
+	QuotaQuota cloud.Quota
+	QuotaErr   error
+
+	InfoInfo cloud.CPIInfo
+	InfoErr  error
 }
 
 type CreateStemcellInput struct {
@@ -55,6 +65,10 @@ type HasVMInput struct {
 	VMCID string
 }
 
+type HasDiskInput struct {
+	DiskCID string
+}
+
 type CreateVMInput struct {
 	AgentID            string
 	StemcellCID        string
@@ -121,6 +135,13 @@ func (c *FakeCloud) HasVM(vmCID string) (bool, error) {
 	return c.HasVMFound, c.HasVMErr
 }
 
+func (c *FakeCloud) HasDisk(diskCID string) (bool, error) {
+	c.HasDiskInput = HasDiskInput{
+		DiskCID: diskCID,
+	}
+	return c.HasDiskFound, c.HasDiskErr
+}
+
 func (c *FakeCloud) CreateVM(
 	agentID string,
 	stemcellCID string,
@@ -195,6 +216,14 @@ func (c *FakeCloud) DeleteDisk(diskCID string) error {
 	return c.DeleteDiskErr
 }
 
+func (c *FakeCloud) Quota() (cloud.Quota, error) {
+	return c.QuotaQuota, c.QuotaErr
+}
+
+func (c *FakeCloud) Info() (cloud.CPIInfo, error) {
+	return c.InfoInfo, c.InfoErr
+}
+
 func (c *FakeCloud) String() string {
 	return "FakeCloud{}"
 }
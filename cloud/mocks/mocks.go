@@ -114,6 +114,17 @@ func (_mr *_MockCloudRecorder) DetachDisk(arg0, arg1 interface{}) *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "DetachDisk", arg0, arg1)
 }
 
+func (_m *MockCloud) HasDisk(_param0 string) (bool, error) {
+	ret := _m.ctrl.Call(_m, "HasDisk", _param0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockCloudRecorder) HasDisk(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "HasDisk", arg0)
+}
+
 func (_m *MockCloud) HasVM(_param0 string) (bool, error) {
 	ret := _m.ctrl.Call(_m, "HasVM", _param0)
 	ret0, _ := ret[0].(bool)
@@ -125,6 +136,28 @@ func (_mr *_MockCloudRecorder) HasVM(arg0 interface{}) *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "HasVM", arg0)
 }
 
+func (_m *MockCloud) Info() (cloud.CPIInfo, error) {
+	ret := _m.ctrl.Call(_m, "Info")
+	ret0, _ := ret[0].(cloud.CPIInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockCloudRecorder) Info() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Info")
+}
+
+func (_m *MockCloud) Quota() (cloud.Quota, error) {
+	ret := _m.ctrl.Call(_m, "Quota")
+	ret0, _ := ret[0].(cloud.Quota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockCloudRecorder) Quota() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Quota")
+}
+
 func (_m *MockCloud) SetDiskMetadata(_param0 string, _param1 cloud.DiskMetadata) error {
 	ret := _m.ctrl.Call(_m, "SetDiskMetadata", _param0, _param1)
 	ret0, _ := ret[0].(error)
@@ -0,0 +1,79 @@
+package cloud
+
+import (
+	biretry "github.com/cloudfoundry/bosh-cli/retry"
+)
+
+// retryingCloud retries the CPI methods that are safe to call again. Methods
+// that create or delete resources are never retried since a call that failed
+// may still have changed the IaaS.
+type retryingCloud struct {
+	Cloud
+	retrier biretry.Retrier
+}
+
+func NewRetryingCloud(cloud Cloud, retrier biretry.Retrier) Cloud {
+	return retryingCloud{Cloud: cloud, retrier: retrier}
+}
+
+func (c retryingCloud) HasVM(vmCID string) (bool, error) {
+	var found bool
+
+	err := c.retrier.Retry("CPI method has_vm", isRetryableReadError, func() error {
+		var err error
+		found, err = c.Cloud.HasVM(vmCID)
+		return err
+	})
+
+	return found, err
+}
+
+func (c retryingCloud) HasDisk(diskCID string) (bool, error) {
+	var found bool
+
+	err := c.retrier.Retry("CPI method has_disk", isRetryableReadError, func() error {
+		var err error
+		found, err = c.Cloud.HasDisk(diskCID)
+		return err
+	})
+
+	return found, err
+}
+
+func (c retryingCloud) Info() (CPIInfo, error) {
+	var info CPIInfo
+
+	err := c.retrier.Retry("CPI method info", isRetryableReadError, func() error {
+		var err error
+		info, err = c.Cloud.Info()
+		return err
+	})
+
+	return info, err
+}
+
+func (c retryingCloud) AttachDisk(vmCID, diskCID string) error {
+	return c.retrier.Retry("CPI method attach_disk", isOkToRetryError, func() error {
+		return c.Cloud.AttachDisk(vmCID, diskCID)
+	})
+}
+
+func (c retryingCloud) DetachDisk(vmCID, diskCID string) error {
+	return c.retrier.Retry("CPI method detach_disk", isOkToRetryError, func() error {
+		return c.Cloud.DetachDisk(vmCID, diskCID)
+	})
+}
+
+// isRetryableReadError retries methods that only read from the IaaS after
+// any error except a CPI not implementing them
+func isRetryableReadError(err error) bool {
+	cpiErr, ok := err.(Error)
+	return !ok || cpiErr.Type() != NotImplementedError
+}
+
+// isOkToRetryError only retries methods that change the IaaS when the CPI
+// says that calling them again is safe
+func isOkToRetryError(err error) bool {
+	cpiErr, ok := err.(Error)
+	return ok && cpiErr.OkToRetry()
+}
@@ -0,0 +1,105 @@
+package cloud_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cloud"
+	mock_cloud "github.com/cloudfoundry/bosh-cli/cloud/mocks"
+	biretry "github.com/cloudfoundry/bosh-cli/retry"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
+)
+
+var _ = Describe("RetryingCloud", func() {
+	var (
+		mockCtrl  *gomock.Controller
+		mockCloud *mock_cloud.MockCloud
+		cloud     Cloud
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockCloud = mock_cloud.NewMockCloud(mockCtrl)
+
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		retrier := biretry.NewRetrier(biretry.Config{Retries: 2}, fakeclock.NewFakeClock(time.Now()), biwarn.NewWarnings(logger), logger)
+
+		cloud = NewRetryingCloud(mockCloud, retrier)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	cpiError := func(method string, errType string, okToRetry bool) error {
+		return NewCPIError(method, CmdError{Type: errType, Message: "fake-cpi-err", OkToRetry: okToRetry})
+	}
+
+	It("retries has_vm after errors", func() {
+		gomock.InOrder(
+			mockCloud.EXPECT().HasVM("fake-vm-cid").Return(false, errors.New("fake-err")),
+			mockCloud.EXPECT().HasVM("fake-vm-cid").Return(true, nil),
+		)
+
+		found, err := cloud.HasVM("fake-vm-cid")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeTrue())
+	})
+
+	It("retries has_disk after errors", func() {
+		gomock.InOrder(
+			mockCloud.EXPECT().HasDisk("fake-disk-cid").Return(false, cpiError("has_disk", "Bosh::Clouds::CloudError", false)),
+			mockCloud.EXPECT().HasDisk("fake-disk-cid").Return(true, nil),
+		)
+
+		found, err := cloud.HasDisk("fake-disk-cid")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeTrue())
+	})
+
+	It("retries info up to the configured number of retries", func() {
+		mockCloud.EXPECT().Info().Return(CPIInfo{}, errors.New("fake-err")).Times(3)
+
+		_, err := cloud.Info()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("fake-err"))
+	})
+
+	It("does not retry methods that the CPI does not implement", func() {
+		mockCloud.EXPECT().HasDisk("fake-disk-cid").Return(false, cpiError("has_disk", NotImplementedError, false)).Times(1)
+
+		_, err := cloud.HasDisk("fake-disk-cid")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("retries attaching a disk when the CPI says that it is ok to retry", func() {
+		gomock.InOrder(
+			mockCloud.EXPECT().AttachDisk("fake-vm-cid", "fake-disk-cid").Return(cpiError("attach_disk", "Bosh::Clouds::CloudError", true)),
+			mockCloud.EXPECT().AttachDisk("fake-vm-cid", "fake-disk-cid").Return(nil),
+		)
+
+		err := cloud.AttachDisk("fake-vm-cid", "fake-disk-cid")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("does not retry detaching a disk when the CPI does not say that it is ok to retry", func() {
+		mockCloud.EXPECT().DetachDisk("fake-vm-cid", "fake-disk-cid").Return(cpiError("detach_disk", "Bosh::Clouds::CloudError", false)).Times(1)
+
+		err := cloud.DetachDisk("fake-vm-cid", "fake-disk-cid")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("does not retry creating VMs", func() {
+		mockCloud.EXPECT().CreateVM("fake-agent-id", "fake-stemcell-cid", nil, nil, nil).
+			Return("", cpiError("create_vm", VMCreationFailedError, true)).Times(1)
+
+		_, err := cloud.CreateVM("fake-agent-id", "fake-stemcell-cid", nil, nil, nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,38 @@
+package cloud
+
+import (
+	"fmt"
+
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+)
+
+// CloudPropertiesWithTags adds tags to any 'tags' the cloud properties already specify,
+// replacing those with the same key; CPIs that do not support tags ignore them
+func CloudPropertiesWithTags(cloudProperties biproperty.Map, tags map[string]string) biproperty.Map {
+	if len(tags) == 0 {
+		return cloudProperties
+	}
+
+	mergedTags := biproperty.Map{}
+	if existingTags, ok := cloudProperties["tags"].(map[interface{}]interface{}); ok {
+		for key, value := range existingTags {
+			mergedTags[fmt.Sprintf("%v", key)] = value
+		}
+	} else if existingTags, ok := cloudProperties["tags"].(biproperty.Map); ok {
+		for key, value := range existingTags {
+			mergedTags[key] = value
+		}
+	}
+
+	for key, value := range tags {
+		mergedTags[key] = value
+	}
+
+	propertiesWithTags := biproperty.Map{}
+	for key, value := range cloudProperties {
+		propertiesWithTags[key] = value
+	}
+	propertiesWithTags["tags"] = mergedTags
+
+	return propertiesWithTags
+}
@@ -0,0 +1,31 @@
+package cloud_test
+
+import (
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cloud"
+)
+
+var _ = Describe("CloudPropertiesWithTags", func() {
+	It("adds the tags to the tags of the cloud properties", func() {
+		cloudProperties := biproperty.Map{
+			"instance_type": "m1.small",
+			"tags":          map[interface{}]interface{}{"existing": "existing-value", "owner": "existing-owner"},
+		}
+
+		Expect(CloudPropertiesWithTags(cloudProperties, map[string]string{"owner": "fake-owner"})).To(Equal(biproperty.Map{
+			"instance_type": "m1.small",
+			"tags":          biproperty.Map{"existing": "existing-value", "owner": "fake-owner"},
+		}))
+
+		Expect(cloudProperties["tags"]).To(Equal(map[interface{}]interface{}{"existing": "existing-value", "owner": "existing-owner"}))
+	})
+
+	It("returns the cloud properties unchanged without tags", func() {
+		cloudProperties := biproperty.Map{"instance_type": "m1.small"}
+
+		Expect(CloudPropertiesWithTags(cloudProperties, nil)).To(Equal(cloudProperties))
+	})
+})
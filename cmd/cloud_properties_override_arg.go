@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+	"gopkg.in/yaml.v2"
+)
+
+type CloudPropertiesOverrideArg struct {
+	ResourcePool    string
+	CloudProperties biproperty.Map
+}
+
+func (a *CloudPropertiesOverrideArg) UnmarshalFlag(data string) error {
+	pieces := strings.SplitN(data, "=", 2)
+	if len(pieces) != 2 {
+		return bosherr.Errorf("Expected cloud properties override '%s' to be in format 'resource-pool=cloud-properties'", data)
+	}
+
+	if len(pieces[0]) == 0 {
+		return bosherr.Errorf("Expected cloud properties override '%s' to specify non-empty resource pool name", data)
+	}
+
+	var rawCloudProperties map[interface{}]interface{}
+
+	err := yaml.Unmarshal([]byte(pieces[1]), &rawCloudProperties)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Deserializing cloud properties override '%s'", data)
+	}
+
+	if len(rawCloudProperties) == 0 {
+		return bosherr.Errorf("Expected cloud properties override '%s' to specify a non-empty hash", data)
+	}
+
+	cloudProperties, err := biproperty.BuildMap(rawCloudProperties)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Parsing cloud properties override '%s'", data)
+	}
+
+	*a = CloudPropertiesOverrideArg{ResourcePool: pieces[0], CloudProperties: cloudProperties}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package cmd_test
+
+import (
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+)
+
+var _ = Describe("CloudPropertiesOverrideArg", func() {
+	Describe("UnmarshalFlag", func() {
+		var (
+			arg *CloudPropertiesOverrideArg
+		)
+
+		BeforeEach(func() {
+			arg = &CloudPropertiesOverrideArg{}
+		})
+
+		It("sets resource pool name and cloud properties", func() {
+			err := arg.UnmarshalFlag("default={instance_type: m4.xlarge, ephemeral_disk: {size: 4096}}")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(arg.ResourcePool).To(Equal("default"))
+			Expect(arg.CloudProperties).To(Equal(biproperty.Map{
+				"instance_type":  "m4.xlarge",
+				"ephemeral_disk": biproperty.Map{"size": 4096},
+			}))
+		})
+
+		It("returns an error if value is not in the name=yaml format", func() {
+			err := arg.UnmarshalFlag("default")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected cloud properties override 'default' to be in format 'resource-pool=cloud-properties'"))
+		})
+
+		It("returns an error if resource pool name is empty", func() {
+			err := arg.UnmarshalFlag("={instance_type: m4.xlarge}")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected cloud properties override '={instance_type: m4.xlarge}' to specify non-empty resource pool name"))
+		})
+
+		It("returns an error if cloud properties are not a hash", func() {
+			err := arg.UnmarshalFlag("default=[1, 2]")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Deserializing cloud properties override 'default=[1, 2]'"))
+		})
+
+		It("returns an error if cloud properties are empty", func() {
+			err := arg.UnmarshalFlag("default=")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected cloud properties override 'default=' to specify a non-empty hash"))
+		})
+	})
+})
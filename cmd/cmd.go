@@ -1,21 +1,32 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"path/filepath"
 
 	"github.com/cppforlife/go-patch/patch"
 
 	cmdconf "github.com/cloudfoundry/bosh-cli/cmd/config"
+	biconfig "github.com/cloudfoundry/bosh-cli/config"
 	"github.com/cloudfoundry/bosh-cli/crypto"
+	bideplmanifest "github.com/cloudfoundry/bosh-cli/deployment/manifest"
+	bidepltpl "github.com/cloudfoundry/bosh-cli/deployment/template"
 	boshdir "github.com/cloudfoundry/bosh-cli/director"
 	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	biinstall "github.com/cloudfoundry/bosh-cli/installation"
+	biinstallmanifest "github.com/cloudfoundry/bosh-cli/installation/manifest"
+	"github.com/cloudfoundry/bosh-cli/offline"
 	boshrel "github.com/cloudfoundry/bosh-cli/release"
+	birelsetmanifest "github.com/cloudfoundry/bosh-cli/release/set/manifest"
 	boshreldir "github.com/cloudfoundry/bosh-cli/releasedir"
 	boshssh "github.com/cloudfoundry/bosh-cli/ssh"
 	bistemcell "github.com/cloudfoundry/bosh-cli/stemcell"
 	boshui "github.com/cloudfoundry/bosh-cli/ui"
 	boshuit "github.com/cloudfoundry/bosh-cli/ui/task"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 
 	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
 	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
@@ -38,6 +49,11 @@ type cmdConveniencePanic struct {
 }
 
 func (c Cmd) Execute() (cmdErr error) {
+	var tmpRootPath, tmpDirPath string
+
+	// Runs after convenience panics were turned into cmdErr
+	defer func() { c.cleanUpTmpDir(tmpDirPath, cmdErr) }()
+
 	// Catch convenience panics from panicIfErr
 	defer func() {
 		if r := recover(); r != nil {
@@ -50,7 +66,7 @@ func (c Cmd) Execute() (cmdErr error) {
 	}()
 
 	c.configureUI()
-	c.configureFS()
+	tmpRootPath, tmpDirPath = c.configureFS()
 
 	if c.BoshOpts.Sha2 {
 		c.deps = c.deps.WithSha2CheckSumming()
@@ -66,20 +82,174 @@ func (c Cmd) Execute() (cmdErr error) {
 		return NewEnvironmentsCmd(c.config(), deps.UI).Run()
 
 	case *CreateEnvOpts:
-		envProvider := func(manifestPath string, statePath string, vars boshtpl.Variables, op patch.Op) DeploymentPreparer {
-			return NewEnvFactory(deps, manifestPath, statePath, vars, op, opts.RecreatePersistentDisks).Preparer()
+		eventLog := newEnvEventLog(deps, "create-env", opts.EventLog, opts.VarFlags.AsVariables(), c.jsonStageEvents())
+		offlineGuard := newOfflineGuard(opts.Offline)
+
+		installationBlobstore, installationIndexStore, err := newInstallationBlobstore(c.config(), deps)
+		if err != nil {
+			return err
+		}
+
+		// Only runs that may change the state file are kept from overlapping
+		if !opts.PrintManifest && !opts.DryRun {
+			unlock, err := c.lockState(opts.Args.Manifest, opts.StatePath, opts.ForceUnlock)
+			if err != nil {
+				return err
+			}
+
+			defer unlock()
 		}
 
-		stage := boshui.NewStage(deps.UI, deps.Time, deps.Logger)
-		return NewCreateEnvCmd(deps.UI, envProvider).Run(stage, *opts)
+		return eventLog.Run(func(stage boshui.Stage) error {
+			createEnv := func(ctx context.Context, opts CreateEnvOpts) error {
+				envProvider := func(manifestPath string, statePath string, vars boshtpl.Variables, op patch.Op) DeploymentPreparer {
+					factoryOpts := NewCreateEnvFactoryOpts(opts, tmpRootPath, tmpDirPath)
+					factoryOpts.InstallationBlobstore = installationBlobstore
+					factoryOpts.InstallationIndexStore = installationIndexStore
+					factoryOpts.OfflineGuard = offlineGuard
+					factoryOpts.AgentOpts.Context = ctx
+
+					envFactory := NewEnvFactory(deps, manifestPath, statePath, vars, op, factoryOpts)
+					eventLog.warnings = envFactory.warnings
+					return envFactory.Preparer(opts.WarningsAsErrors)
+				}
+
+				return offlineErr(offlineGuard, NewCreateEnvCmd(deps.UI, envProvider).Run(stage, opts))
+			}
+
+			if opts.Watch {
+				return NewCreateEnvWatcher(deps.UI, deps.FS, deps.Time, signal.Notify, signal.Stop).Run(*opts, createEnv)
+			}
+
+			ctx, stopInterrupting := NewInterruptContext(deps.UI, signal.Notify, signal.Stop)
+			defer stopInterrupting()
+
+			return createEnv(ctx, *opts)
+		})
 
 	case *DeleteEnvOpts:
-		envProvider := func(manifestPath string, statePath string, vars boshtpl.Variables, op patch.Op) DeploymentDeleter {
-			return NewEnvFactory(deps, manifestPath, statePath, vars, op, false).Deleter()
+		eventLog := newEnvEventLog(deps, "delete-env", opts.EventLog, opts.VarFlags.AsVariables(), c.jsonStageEvents())
+		offlineGuard := newOfflineGuard(opts.Offline)
+
+		installationBlobstore, installationIndexStore, err := newInstallationBlobstore(c.config(), deps)
+		if err != nil {
+			return err
+		}
+
+		envProvider := func(manifestPath string, statePath string, vars boshtpl.Variables, op patch.Op, confirmDestroy DestroyConfirmation) DeploymentDeleter {
+			factoryOpts := NewDeleteEnvFactoryOpts(*opts, tmpRootPath, tmpDirPath)
+			factoryOpts.InstallationBlobstore = installationBlobstore
+			factoryOpts.InstallationIndexStore = installationIndexStore
+			factoryOpts.OfflineGuard = offlineGuard
+
+			envFactory := NewEnvFactory(deps, manifestPath, statePath, vars, op, factoryOpts)
+			eventLog.warnings = envFactory.warnings
+			return envFactory.Deleter(confirmDestroy)
+		}
+
+		unlock, err := c.lockState(opts.Args.Manifest, opts.StatePath, opts.ForceUnlock)
+		if err != nil {
+			return err
+		}
+
+		defer unlock()
+
+		return eventLog.Run(func(stage boshui.Stage) error {
+			return offlineErr(offlineGuard, NewDeleteCmd(deps.UI, envProvider).Run(stage, *opts))
+		})
+
+	case *EnvInstancesOpts:
+		envProvider := func(manifestPath string, statePath string, vars boshtpl.Variables, op patch.Op) EnvInstancesLister {
+			return NewEnvFactory(deps, manifestPath, statePath, vars, op, NewDefaultEnvFactoryOpts(tmpRootPath, tmpDirPath)).InstancesLister()
+		}
+
+		return NewEnvInstancesCmd(deps.UI, envProvider).Run(*opts)
+
+	case *EnvInfoOpts:
+		envProvider := func(manifestPath string, statePath string, vars boshtpl.Variables, op patch.Op) EnvInfoLoader {
+			return NewEnvFactory(deps, manifestPath, statePath, vars, op, NewDefaultEnvFactoryOpts(tmpRootPath, tmpDirPath)).InfoLoader()
+		}
+
+		return NewEnvInfoCmd(deps.UI, envProvider).Run(*opts)
+
+	case *EnvLogsOpts:
+		envProvider := func(manifestPath string, statePath string, vars boshtpl.Variables, op patch.Op) EnvLogsFetcher {
+			return NewEnvFactory(deps, manifestPath, statePath, vars, op, NewDefaultEnvFactoryOpts(tmpRootPath, tmpDirPath)).LogsFetcher()
+		}
+
+		return NewEnvLogsCmd(deps.UI, envProvider).Run(*opts)
+
+	case *EnvDisksOpts:
+		envProvider := func(manifestPath string, statePath string, vars boshtpl.Variables, op patch.Op) EnvDisksManager {
+			return NewEnvFactory(deps, manifestPath, statePath, vars, op, NewDefaultEnvFactoryOpts(tmpRootPath, tmpDirPath)).DisksManager()
+		}
+
+		// Listing disks only reads the state, deleting them changes it
+		if opts.Delete {
+			unlock, err := c.lockState(opts.Args.Manifest, opts.StatePath, opts.ForceUnlock)
+			if err != nil {
+				return err
+			}
+
+			defer unlock()
+		}
+
+		eventLog := newEnvEventLog(deps, "env-disks", "", opts.VarFlags.AsVariables(), c.jsonStageEvents())
+
+		return eventLog.Run(func(stage boshui.Stage) error {
+			return NewEnvDisksCmd(deps.UI, envProvider).Run(stage, *opts)
+		})
+
+	case *EnvCloudCheckOpts:
+		envProvider := func(manifestPath string, statePath string, vars boshtpl.Variables, op patch.Op) EnvCloudChecker {
+			return NewEnvFactory(deps, manifestPath, statePath, vars, op, NewDefaultEnvFactoryOpts(tmpRootPath, tmpDirPath)).CloudChecker()
+		}
+
+		// Reports only read the state, resolving problems changes it
+		if !opts.Report {
+			unlock, err := c.lockState(opts.Args.Manifest, opts.StatePath, opts.ForceUnlock)
+			if err != nil {
+				return err
+			}
+
+			defer unlock()
+		}
+
+		eventLog := newEnvEventLog(deps, "env-cloud-check", "", opts.VarFlags.AsVariables(), c.jsonStageEvents())
+
+		return eventLog.Run(func(stage boshui.Stage) error {
+			return NewEnvCloudCheckCmd(deps.UI, envProvider).Run(stage, *opts)
+		})
+
+	case *ListStagesOpts:
+		return NewListStagesCmd(deps.UI).Run(*opts)
+
+	case *ReplayEventsOpts:
+		return NewReplayEventsCmd(deps.FS, deps.UI, deps.Logger).Run(*opts)
+
+	case *ValidateManifestOpts:
+		manifestParser := ReleaseSetAndInstallationManifestParser{
+			ReleaseSetParser: birelsetmanifest.NewParser(deps.FS, deps.Logger, birelsetmanifest.NewValidator(deps.Logger)),
+			InstallationParser: biinstallmanifest.NewParser(
+				deps.FS, deps.UUIDGen, deps.Logger, biinstallmanifest.NewValidator(deps.Logger), ""),
 		}
 
-		stage := boshui.NewStage(deps.UI, deps.Time, deps.Logger)
-		return NewDeleteCmd(deps.UI, envProvider).Run(stage, *opts)
+		warnings := biwarn.NewWarnings(deps.Logger)
+		releaseProvider, _ := c.releaseProviders()
+		stemcellExtractor := bistemcell.NewExtractor(bistemcell.NewReader(deps.Compressor, deps.FS), deps.FS)
+
+		return NewValidateManifestCmd(
+			deps.UI,
+			manifestParser,
+			bidepltpl.NewDeploymentTemplateFactory(deps.FS),
+			bideplmanifest.NewParser(deps.FS, warnings, deps.Logger),
+			bideplmanifest.NewValidator(warnings, deps.Logger),
+			releaseProvider.NewExtractingArchiveReader(),
+			biinstall.NewReleaseManager(deps.Logger),
+			stemcellExtractor,
+			warnings,
+			deps.Logger,
+		).Run(*opts)
 
 	case *AliasEnvOpts:
 		sessionFactory := func(config cmdconf.Config) Session {
@@ -169,6 +339,12 @@ func (c Cmd) Execute() (cmdErr error) {
 		return NewDeleteReleaseCmd(deps.UI, c.director()).Run(*opts)
 
 	case *StemcellsOpts:
+		if len(opts.StatePath) > 0 {
+			deploymentStateService := biconfig.NewFileSystemDeploymentStateService(deps.FS, deps.UUIDGen, deps.Logger, opts.StatePath)
+			stemcellRepo := biconfig.NewStemcellRepo(deploymentStateService, deps.UUIDGen)
+			return NewStateStemcellsCmd(deps.UI, deploymentStateService, stemcellRepo).Run()
+		}
+
 		return NewStemcellsCmd(deps.UI, c.director()).Run()
 
 	case *UploadStemcellOpts:
@@ -182,7 +358,8 @@ func (c Cmd) Execute() (cmdErr error) {
 		return NewDeleteStemcellCmd(deps.UI, c.director()).Run(*opts)
 
 	case *RepackStemcellOpts:
-		stemcellReader := bistemcell.NewReader(deps.Compressor, deps.FS)
+		compressor := NewCompressionLevelCompressor(deps.Compressor, deps.CmdRunner, deps.FS, opts.CompressionLevel)
+		stemcellReader := bistemcell.NewReader(compressor, deps.FS)
 		stemcellExtractor := bistemcell.NewExtractor(stemcellReader, deps.FS)
 
 		return NewRepackStemcellCmd(deps.UI, deps.FS, stemcellExtractor).Run(*opts)
@@ -266,6 +443,9 @@ func (c Cmd) Execute() (cmdErr error) {
 	case *ManifestOpts:
 		return NewManifestCmd(deps.UI, c.deployment()).Run()
 
+	case *DiffOpts:
+		return NewDiffCmd(deps.UI, c.deployment()).Run(*opts)
+
 	case *EventsOpts:
 		return NewEventsCmd(deps.UI, c.director()).Run(*opts)
 
@@ -370,9 +550,18 @@ func (c Cmd) Execute() (cmdErr error) {
 			return releaseReader, releaseDir
 		}
 
+		archiveWriter := relProv.NewArchiveWriter()
+
+		if !opts.CompressionLevel.IsDefault() {
+			compressor := NewCompressionLevelCompressor(deps.Compressor, deps.CmdRunner, deps.FS, opts.CompressionLevel)
+			archiveWriter = boshrel.NewProvider(
+				deps.CmdRunner, compressor, deps.DigestCalculator, deps.FS, deps.Logger).NewArchiveWriter()
+		}
+
 		_, err := NewCreateReleaseCmd(
 			releaseDirFactory,
-			relProv.NewArchiveWriter(),
+			archiveWriter,
+			crypto.NewDigestCalculator(c.deps.FS, []boshcrypto.Algorithm{boshcrypto.DigestAlgorithmSHA1}),
 			c.deps.FS,
 			c.deps.UI,
 		).Run(*opts)
@@ -402,6 +591,15 @@ func (c Cmd) Execute() (cmdErr error) {
 			c.deps.UI,
 		).Run(opts.Args)
 
+	case *DiffReleasesOpts:
+		relProv, _ := c.releaseProviders()
+
+		return NewDiffReleasesCmd(
+			relProv.NewArchiveReader(),
+			crypto.NewDigestCalculator(c.deps.FS, []boshcrypto.Algorithm{boshcrypto.DigestAlgorithmSHA1}),
+			c.deps.UI,
+		).Run(*opts)
+
 	case *BlobsOpts:
 		return NewBlobsCmd(c.blobsDir(opts.Directory), deps.UI).Run()
 
@@ -424,6 +622,9 @@ func (c Cmd) Execute() (cmdErr error) {
 	case *VariablesOpts:
 		return NewVariablesCmd(deps.UI, c.deployment()).Run()
 
+	case *OutputSchemaOpts:
+		return NewOutputSchemaCmd(deps.UI).Run(*opts)
+
 	default:
 		return fmt.Errorf("Unhandled command: %#v", c.Opts)
 	}
@@ -435,7 +636,8 @@ func (c Cmd) configureUI() {
 		c.deps.UI.EnableColor()
 	}
 
-	if c.BoshOpts.JSONOpt {
+	// The schema is printed as is since it is already JSON
+	if c.BoshOpts.JSONOpt && !c.BoshOpts.SchemaOpt {
 		c.deps.UI.EnableJSON()
 	}
 
@@ -453,12 +655,79 @@ func (c Cmd) configureUI() {
 	}
 }
 
-func (c Cmd) configureFS() {
-	tmpDirPath, err := c.deps.FS.ExpandPath(filepath.Join("~", ".bosh", "tmp"))
+// offlineGuard returns a guard forbidding network access when offline, otherwise nil
+func newOfflineGuard(offlineOpt bool) *offline.Guard {
+	if !offlineOpt {
+		return nil
+	}
+	return offline.NewGuard()
+}
+
+// offlineErr lists what needed network access when a command failed offline
+func offlineErr(guard *offline.Guard, err error) error {
+	if guard == nil {
+		return err
+	}
+	return guard.Err(err)
+}
+
+// configureFS sets the temp root of the file system. With --tmp-dir each run
+// gets its own directory below it, which is returned along with the given
+// root so that it can be removed once the command succeeded while caches
+// kept in the root outlive the run.
+func (c Cmd) configureFS() (string, string) {
+	if len(c.BoshOpts.TmpDirOpt) == 0 {
+		tmpDirPath, err := c.deps.FS.ExpandPath(filepath.Join("~", ".bosh", "tmp"))
+		c.panicIfErr(err)
+
+		err = c.deps.FS.ChangeTempRoot(tmpDirPath)
+		c.panicIfErr(err)
+
+		return "", ""
+	}
+
+	tmpRootPath, err := c.deps.FS.ExpandPath(c.BoshOpts.TmpDirOpt)
+	c.panicIfErr(err)
+
+	err = c.deps.FS.ChangeTempRoot(tmpRootPath)
+	c.panicIfErr(err)
+
+	tmpDirPath, err := c.deps.FS.TempDir("bosh-cli")
 	c.panicIfErr(err)
 
 	err = c.deps.FS.ChangeTempRoot(tmpDirPath)
 	c.panicIfErr(err)
+
+	return tmpRootPath, tmpDirPath
+}
+
+// cleanUpTmpDir removes the temporary files of a successful run and keeps
+// them for debugging when the command failed
+func (c Cmd) cleanUpTmpDir(tmpDirPath string, cmdErr error) {
+	if len(tmpDirPath) == 0 {
+		return
+	}
+
+	if cmdErr != nil {
+		c.deps.Logger.Info("Cmd", "Keeping temporary directory '%s' of failed command", tmpDirPath)
+		c.deps.UI.ErrorLinef("Keeping temporary files in '%s' for debugging", tmpDirPath)
+		return
+	}
+
+	err := c.deps.FS.RemoveAll(tmpDirPath)
+	if err != nil {
+		c.deps.Logger.Warn("Cmd", "Removing temporary directory '%s': %s", tmpDirPath, err.Error())
+	}
+}
+
+// jsonStageEvents streams stages to stderr as lines of JSON with --json,
+// since the JSON document on stdout is only printed once the command finished
+func (c Cmd) jsonStageEvents() boshui.StageEventRecorder {
+	if !c.BoshOpts.JSONOpt {
+		return nil
+	}
+
+	return boshui.NewJSONStageEventWriter(os.Stderr, c.deps.Logger)
 }
 
 func (c Cmd) config() cmdconf.Config {
@@ -527,6 +796,7 @@ func (c Cmd) releaseManager(director boshdir.Director) ReleaseManager {
 	createReleaseCmd := NewCreateReleaseCmd(
 		releaseDirFactory,
 		releaseWriter,
+		crypto.NewDigestCalculator(c.deps.FS, []boshcrypto.Algorithm{boshcrypto.DigestAlgorithmSHA1}),
 		c.deps.FS,
 		c.deps.UI,
 	)
@@ -548,6 +818,17 @@ func (c Cmd) releaseManager(director boshdir.Director) ReleaseManager {
 	return NewReleaseManager(createReleaseCmd, uploadReleaseCmd, c.BoshOpts.Parallel)
 }
 
+func (c Cmd) lockState(manifest EnvManifestArg, statePath string, forceUnlock bool) (func(), error) {
+	// Manifests read from stdin require --state, which the commands report when it is missing
+	if manifest.Stdin && len(statePath) == 0 {
+		return func() {}, nil
+	}
+
+	statePath = biconfig.DeploymentStatePath(manifest.Path, statePath)
+
+	return NewStateLock(c.deps.FS, statePath, forceUnlock, os.Getpid(), processExists, c.deps.Logger).Lock()
+}
+
 func (c Cmd) blobsDir(dir DirOrCWDArg) boshreldir.BlobsDir {
 	_, relDirProv := c.releaseProviders()
 	return relDirProv.NewFSBlobsDir(dir.Path)
@@ -104,6 +104,31 @@ var _ = Describe("Cmd", func() {
 			Expect(err.Error()).To(Equal("fake-err"))
 		})
 
+		Describe("tmp dir", func() {
+			BeforeEach(func() {
+				cmd.BoshOpts = BoshOpts{TmpDirOpt: "/big/tmp"}
+				fs.TempDirDir = "/big/tmp/bosh-cli-run"
+			})
+
+			It("uses a directory of the run below the given tmp dir and removes it on success", func() {
+				cmd.Opts = &MessageOpts{Message: "output"}
+
+				err := cmd.Execute()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fs.TempRootPath).To(Equal("/big/tmp/bosh-cli-run"))
+				Expect(fs.FileExists("/big/tmp/bosh-cli-run")).To(BeFalse())
+			})
+
+			It("keeps the directory of the run when the command fails", func() {
+				err := cmd.Execute()
+				Expect(err).To(HaveOccurred())
+
+				Expect(fs.FileExists("/big/tmp/bosh-cli-run")).To(BeTrue())
+				Expect(ui.Errors).To(ContainElement("Keeping temporary files in '/big/tmp/bosh-cli-run' for debugging"))
+			})
+		})
+
 		It("returns error for unknown commands", func() {
 			err := cmd.Execute()
 			Expect(err).To(HaveOccurred())
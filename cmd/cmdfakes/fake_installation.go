@@ -17,6 +17,18 @@ func (f *FakeInstallation) Job() biinstallation.InstalledJob {
 	return biinstallation.InstalledJob{}
 }
 
+func (f *FakeInstallation) CompiledPackages() []biinstallation.CompiledPackageRef {
+	return nil
+}
+
+func (f *FakeInstallation) ExpectedCPIDigest() string {
+	return ""
+}
+
+func (f *FakeInstallation) CPIAPIVersion() int {
+	return 0
+}
+
 func (f *FakeInstallation) WithRunningRegistry(logger boshlog.Logger, stage biui.Stage, fn func() error) error {
 	return fn()
 }
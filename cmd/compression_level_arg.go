@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"strconv"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// CompressionLevelArg is a gzip level; its zero value keeps the default
+// compression of the tar command
+type CompressionLevelArg int
+
+func (a *CompressionLevelArg) UnmarshalFlag(data string) error {
+	switch data {
+	case "fast":
+		*a = CompressionLevelArg(gzip.BestSpeed)
+		return nil
+	case "best":
+		*a = CompressionLevelArg(gzip.BestCompression)
+		return nil
+	}
+
+	level, err := strconv.Atoi(data)
+	if err != nil || level < gzip.BestSpeed || level > gzip.BestCompression {
+		return bosherr.Errorf("Expected compression level '%s' to be 'fast', 'best' or a number from 1 to 9", data)
+	}
+
+	*a = CompressionLevelArg(level)
+
+	return nil
+}
+
+func (a CompressionLevelArg) IsDefault() bool { return a == 0 }
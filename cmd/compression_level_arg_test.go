@@ -0,0 +1,49 @@
+package cmd_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+)
+
+var _ = Describe("CompressionLevelArg", func() {
+	Describe("UnmarshalFlag", func() {
+		var (
+			arg CompressionLevelArg
+		)
+
+		BeforeEach(func() {
+			arg = CompressionLevelArg(0)
+		})
+
+		It("defaults to the tar default", func() {
+			Expect(arg.IsDefault()).To(BeTrue())
+		})
+
+		It("accepts fast and best", func() {
+			err := (&arg).UnmarshalFlag("fast")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(arg).To(Equal(CompressionLevelArg(1)))
+
+			err = (&arg).UnmarshalFlag("best")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(arg).To(Equal(CompressionLevelArg(9)))
+			Expect(arg.IsDefault()).To(BeFalse())
+		})
+
+		It("accepts numeric levels", func() {
+			err := (&arg).UnmarshalFlag("6")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(arg).To(Equal(CompressionLevelArg(6)))
+		})
+
+		It("returns error for unknown levels", func() {
+			for _, level := range []string{"0", "10", "fastest"} {
+				err := (&arg).UnmarshalFlag(level)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Expected compression level '" + level + "'"))
+			}
+		})
+	})
+})
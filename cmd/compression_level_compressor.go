@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshcmd "github.com/cloudfoundry/bosh-utils/fileutil"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+type compressionLevelCompressor struct {
+	boshcmd.Compressor
+	cmdRunner boshsys.CmdRunner
+	fs        boshsys.FileSystem
+	level     CompressionLevelArg
+}
+
+// NewCompressionLevelCompressor returns a Compressor that gzips tarballs with level.
+// The tar command cannot be given a gzip level portably, so the tarball is
+// created uncompressed and then gzipped in process.
+// With the default level, and for decompression and clean up, compressor is used as is.
+func NewCompressionLevelCompressor(
+	compressor boshcmd.Compressor,
+	cmdRunner boshsys.CmdRunner,
+	fs boshsys.FileSystem,
+	level CompressionLevelArg,
+) boshcmd.Compressor {
+	if level.IsDefault() {
+		return compressor
+	}
+
+	return compressionLevelCompressor{Compressor: compressor, cmdRunner: cmdRunner, fs: fs, level: level}
+}
+
+func (c compressionLevelCompressor) CompressFilesInDir(dir string) (string, error) {
+	return c.CompressSpecificFilesInDir(dir, []string{"."})
+}
+
+func (c compressionLevelCompressor) CompressSpecificFilesInDir(dir string, files []string) (string, error) {
+	tarPath, err := c.tempFilePath("bosh-cli-CompressionLevelCompressor-tar")
+	if err != nil {
+		return "", err
+	}
+
+	defer c.fs.RemoveAll(tarPath)
+
+	args := append([]string{"cf", tarPath, "-C", dir}, files...)
+
+	_, _, _, err = c.cmdRunner.RunCommand("tar", args...)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Shelling out to tar")
+	}
+
+	tarballPath, err := c.tempFilePath("bosh-cli-CompressionLevelCompressor-tgz")
+	if err != nil {
+		return "", err
+	}
+
+	err = c.gzip(tarPath, tarballPath)
+	if err != nil {
+		_ = c.fs.RemoveAll(tarballPath)
+		return "", bosherr.WrapErrorf(err, "Compressing files in '%s'", dir)
+	}
+
+	return tarballPath, nil
+}
+
+func (c compressionLevelCompressor) tempFilePath(prefix string) (string, error) {
+	file, err := c.fs.TempFile(prefix)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Creating temporary file for tarball")
+	}
+
+	err = file.Close()
+	if err != nil {
+		return "", bosherr.WrapErrorf(err, "Closing temporary file '%s'", file.Name())
+	}
+
+	return file.Name(), nil
+}
+
+func (c compressionLevelCompressor) gzip(srcPath, dstPath string) error {
+	src, err := c.fs.OpenFile(srcPath, os.O_RDONLY, 0)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Opening '%s'", srcPath)
+	}
+
+	defer src.Close()
+
+	dst, err := c.fs.OpenFile(dstPath, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Opening '%s'", dstPath)
+	}
+
+	defer dst.Close()
+
+	gzipWriter, err := gzip.NewWriterLevel(dst, int(c.level))
+	if err != nil {
+		return bosherr.WrapError(err, "Creating gzip writer")
+	}
+
+	_, err = io.Copy(gzipWriter, src)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing gzip")
+	}
+
+	err = gzipWriter.Close()
+	if err != nil {
+		return bosherr.WrapError(err, "Closing gzip writer")
+	}
+
+	return dst.Close()
+}
@@ -0,0 +1,77 @@
+package cmd_test
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	boshcmd "github.com/cloudfoundry/bosh-utils/fileutil"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+)
+
+var _ = Describe("CompressionLevelCompressor", func() {
+	var (
+		fs         boshsys.FileSystem
+		cmdRunner  boshsys.CmdRunner
+		compressor boshcmd.Compressor
+		srcDir     string
+		dstDir     string
+	)
+
+	BeforeEach(func() {
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		fs = boshsys.NewOsFileSystem(logger)
+		cmdRunner = boshsys.NewExecCmdRunner(logger)
+		compressor = boshcmd.NewTarballCompressor(cmdRunner, fs)
+
+		var err error
+
+		srcDir, err = ioutil.TempDir("", "compression-level-src")
+		Expect(err).ToNot(HaveOccurred())
+
+		dstDir, err = ioutil.TempDir("", "compression-level-dst")
+		Expect(err).ToNot(HaveOccurred())
+
+		err = ioutil.WriteFile(filepath.Join(srcDir, "file"), []byte("fake-contents"), 0644)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(srcDir)
+		os.RemoveAll(dstDir)
+	})
+
+	It("returns the given compressor for the default level", func() {
+		Expect(NewCompressionLevelCompressor(compressor, cmdRunner, fs, CompressionLevelArg(0))).To(Equal(compressor))
+	})
+
+	It("creates gzipped tarballs that the given compressor can decompress", func() {
+		levelCompressor := NewCompressionLevelCompressor(compressor, cmdRunner, fs, CompressionLevelArg(9))
+
+		tarballPath, err := levelCompressor.CompressFilesInDir(srcDir)
+		Expect(err).ToNot(HaveOccurred())
+
+		defer levelCompressor.CleanUp(tarballPath)
+
+		tarball, err := os.Open(tarballPath)
+		Expect(err).ToNot(HaveOccurred())
+
+		defer tarball.Close()
+
+		_, err = gzip.NewReader(tarball)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = levelCompressor.DecompressFileToDir(tarballPath, dstDir, boshcmd.CompressorOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
+		contents, err := ioutil.ReadFile(filepath.Join(dstDir, "file"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(contents)).To(Equal("fake-contents"))
+	})
+})
@@ -88,6 +88,15 @@ type FakeConfig struct {
 	unsetCredentialsReturnsOnCall map[int]struct {
 		result1 config.Config
 	}
+	InstallationBlobstoreStub        func() config.Blobstore
+	installationBlobstoreMutex       sync.RWMutex
+	installationBlobstoreArgsForCall []struct{}
+	installationBlobstoreReturns     struct {
+		result1 config.Blobstore
+	}
+	installationBlobstoreReturnsOnCall map[int]struct {
+		result1 config.Blobstore
+	}
 	SaveStub        func() error
 	saveMutex       sync.RWMutex
 	saveArgsForCall []struct{}
@@ -435,6 +444,46 @@ func (fake *FakeConfig) UnsetCredentialsReturnsOnCall(i int, result1 config.Conf
 	}{result1}
 }
 
+func (fake *FakeConfig) InstallationBlobstore() config.Blobstore {
+	fake.installationBlobstoreMutex.Lock()
+	ret, specificReturn := fake.installationBlobstoreReturnsOnCall[len(fake.installationBlobstoreArgsForCall)]
+	fake.installationBlobstoreArgsForCall = append(fake.installationBlobstoreArgsForCall, struct{}{})
+	fake.recordInvocation("InstallationBlobstore", []interface{}{})
+	fake.installationBlobstoreMutex.Unlock()
+	if fake.InstallationBlobstoreStub != nil {
+		return fake.InstallationBlobstoreStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.installationBlobstoreReturns.result1
+}
+
+func (fake *FakeConfig) InstallationBlobstoreCallCount() int {
+	fake.installationBlobstoreMutex.RLock()
+	defer fake.installationBlobstoreMutex.RUnlock()
+	return len(fake.installationBlobstoreArgsForCall)
+}
+
+func (fake *FakeConfig) InstallationBlobstoreReturns(result1 config.Blobstore) {
+	fake.InstallationBlobstoreStub = nil
+	fake.installationBlobstoreReturns = struct {
+		result1 config.Blobstore
+	}{result1}
+}
+
+func (fake *FakeConfig) InstallationBlobstoreReturnsOnCall(i int, result1 config.Blobstore) {
+	fake.InstallationBlobstoreStub = nil
+	if fake.installationBlobstoreReturnsOnCall == nil {
+		fake.installationBlobstoreReturnsOnCall = make(map[int]struct {
+			result1 config.Blobstore
+		})
+	}
+	fake.installationBlobstoreReturnsOnCall[i] = struct {
+		result1 config.Blobstore
+	}{result1}
+}
+
 func (fake *FakeConfig) Save() error {
 	fake.saveMutex.Lock()
 	ret, specificReturn := fake.saveReturnsOnCall[len(fake.saveArgsForCall)]
@@ -492,6 +541,8 @@ func (fake *FakeConfig) Invocations() map[string][][]interface{} {
 	defer fake.setCredentialsMutex.RUnlock()
 	fake.unsetCredentialsMutex.RLock()
 	defer fake.unsetCredentialsMutex.RUnlock()
+	fake.installationBlobstoreMutex.RLock()
+	defer fake.installationBlobstoreMutex.RUnlock()
 	fake.saveMutex.RLock()
 	defer fake.saveMutex.RUnlock()
 	return fake.invocations
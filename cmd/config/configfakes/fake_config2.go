@@ -72,6 +72,10 @@ func (f *FakeConfig2) SetDeployment(environment string, nameOrPath string) confi
 	panic("Not implemented")
 }
 
+func (f *FakeConfig2) InstallationBlobstore() config.Blobstore {
+	return config.Blobstore{}
+}
+
 func (f *FakeConfig2) Save() error {
 	f.Saved.EnvironmentURL = f.Existing.EnvironmentURL
 	f.Saved.EnvironmentAlias = f.Existing.EnvironmentAlias
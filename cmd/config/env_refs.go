@@ -0,0 +1,133 @@
+package config
+
+import (
+	"os"
+	"regexp"
+	"sort"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// envRefRegexp matches config values that consist only of a ${NAME} reference to an environment variable
+var envRefRegexp = regexp.MustCompile(`\A\$\{([A-Za-z_][A-Za-z0-9_]*)\}\z`)
+
+func envRefName(value string) (string, bool) {
+	matches := envRefRegexp.FindStringSubmatch(value)
+	if matches == nil {
+		return "", false
+	}
+
+	return matches[1], true
+}
+
+// resolveEnvRef returns the value of the referenced environment variable,
+// or the value itself when it is not a reference
+func resolveEnvRef(value string) string {
+	name, ok := envRefName(value)
+	if !ok {
+		return value
+	}
+
+	return os.Getenv(name)
+}
+
+// keepEnvRef returns the current config value if it refers to an environment variable
+// that holds the new value, otherwise the new value
+func keepEnvRef(current, value string) string {
+	if _, ok := envRefName(current); ok && resolveEnvRef(current) == value {
+		return current
+	}
+
+	return value
+}
+
+func resolveEnvRefs(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case string:
+		return resolveEnvRef(typedValue)
+
+	case map[string]interface{}:
+		resolved := map[string]interface{}{}
+		for k, v := range typedValue {
+			resolved[k] = resolveEnvRefs(v)
+		}
+		return resolved
+
+	case map[interface{}]interface{}:
+		resolved := map[interface{}]interface{}{}
+		for k, v := range typedValue {
+			resolved[k] = resolveEnvRefs(v)
+		}
+		return resolved
+
+	case []interface{}:
+		resolved := []interface{}{}
+		for _, v := range typedValue {
+			resolved = append(resolved, resolveEnvRefs(v))
+		}
+		return resolved
+
+	default:
+		return value
+	}
+}
+
+func collectEnvRefNames(value interface{}, names map[string]struct{}) {
+	switch typedValue := value.(type) {
+	case string:
+		if name, ok := envRefName(typedValue); ok {
+			names[name] = struct{}{}
+		}
+
+	case map[string]interface{}:
+		for _, v := range typedValue {
+			collectEnvRefNames(v, names)
+		}
+
+	case map[interface{}]interface{}:
+		for _, v := range typedValue {
+			collectEnvRefNames(v, names)
+		}
+
+	case []interface{}:
+		for _, v := range typedValue {
+			collectEnvRefNames(v, names)
+		}
+	}
+}
+
+// checkEnvRefs returns an error when credentials or blobstore options
+// refer to environment variables that are not set
+func (s fsConfigSchema) checkEnvRefs() error {
+	names := map[string]struct{}{}
+
+	for _, tg := range s.Environments {
+		for _, value := range []string{tg.CACert, tg.Username, tg.Password, tg.RefreshToken} {
+			collectEnvRefNames(value, names)
+		}
+	}
+
+	collectEnvRefNames(s.InstallationBlobstore.Options, names)
+
+	var unset []string
+
+	for name := range names {
+		if _, found := os.LookupEnv(name); !found {
+			unset = append(unset, name)
+		}
+	}
+
+	sort.Strings(unset)
+
+	var errs []error
+
+	for _, name := range unset {
+		errs = append(errs, bosherr.Errorf("Expected environment variable '%s' to be set", name))
+	}
+
+	if len(errs) > 0 {
+		return bosherr.NewMultiError(errs...)
+	}
+
+	return nil
+}
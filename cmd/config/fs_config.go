@@ -14,8 +14,19 @@ environments:
   ca_cert: |...
   username: admin
   password: admin
+installation_blobstore:
+  provider: s3
+  options:
+    bucket_name: compiled-packages
+    region: us-east-1
+    access_key_id: ...
+    secret_access_key: ${AWS_SECRET_ACCESS_KEY}
 */
 
+// Credentials, CA certificates and blobstore options can be given as ${NAME}
+// to read them from environment variables instead of keeping them in the file.
+// References are resolved when values are read and are saved as they are.
+
 type FSConfig struct {
 	path string
 	fs   boshsys.FileSystem
@@ -25,6 +36,8 @@ type FSConfig struct {
 
 type fsConfigSchema struct {
 	Environments []fsConfigSchema_Environment `yaml:"environments"`
+
+	InstallationBlobstore fsConfigSchema_Blobstore `yaml:"installation_blobstore,omitempty"`
 }
 
 type fsConfigSchema_Environment struct {
@@ -39,6 +52,11 @@ type fsConfigSchema_Environment struct {
 	RefreshToken string `yaml:"refresh_token,omitempty"`
 }
 
+type fsConfigSchema_Blobstore struct {
+	Provider string                 `yaml:"provider,omitempty"`
+	Options  map[string]interface{} `yaml:"options,omitempty"`
+}
+
 func NewFSConfigFromPath(path string, fs boshsys.FileSystem) (FSConfig, error) {
 	var schema fsConfigSchema
 
@@ -57,6 +75,11 @@ func NewFSConfigFromPath(path string, fs boshsys.FileSystem) (FSConfig, error) {
 		if err != nil {
 			return FSConfig{}, bosherr.WrapError(err, "Unmarshalling config")
 		}
+
+		err = schema.checkEnvRefs()
+		if err != nil {
+			return FSConfig{}, bosherr.WrapErrorf(err, "Resolving environment variables in config '%s'", absPath)
+		}
 	}
 
 	return FSConfig{path: absPath, fs: fs, schema: schema}, nil
@@ -101,27 +124,28 @@ func (c FSConfig) AliasEnvironment(url, alias, caCert string) (Config, error) {
 func (c FSConfig) CACert(urlOrAlias string) string {
 	_, tg := c.findOrCreateEnvironment(urlOrAlias)
 
-	return tg.CACert
+	return resolveEnvRef(tg.CACert)
 }
 
 func (c FSConfig) Credentials(urlOrAlias string) Creds {
 	_, tg := c.findOrCreateEnvironment(urlOrAlias)
 
 	return Creds{
-		Client:       tg.Username,
-		ClientSecret: tg.Password,
+		Client:       resolveEnvRef(tg.Username),
+		ClientSecret: resolveEnvRef(tg.Password),
 
-		RefreshToken: tg.RefreshToken,
+		RefreshToken: resolveEnvRef(tg.RefreshToken),
 	}
 }
 
 func (c FSConfig) SetCredentials(urlOrAlias string, creds Creds) Config {
 	config := c.deepCopy()
 
+	// References stay in place when their value is not changed, so that it is not written to the file
 	i, tg := config.findOrCreateEnvironment(urlOrAlias)
-	tg.Username = creds.Client
-	tg.Password = creds.ClientSecret
-	tg.RefreshToken = creds.RefreshToken
+	tg.Username = keepEnvRef(tg.Username, creds.Client)
+	tg.Password = keepEnvRef(tg.Password, creds.ClientSecret)
+	tg.RefreshToken = keepEnvRef(tg.RefreshToken, creds.RefreshToken)
 	config.schema.Environments[i] = tg
 
 	return config
@@ -139,6 +163,19 @@ func (c FSConfig) UnsetCredentials(urlOrAlias string) Config {
 	return config
 }
 
+func (c FSConfig) InstallationBlobstore() Blobstore {
+	var options map[string]interface{}
+
+	if c.schema.InstallationBlobstore.Options != nil {
+		options = resolveEnvRefs(c.schema.InstallationBlobstore.Options).(map[string]interface{})
+	}
+
+	return Blobstore{
+		Provider: c.schema.InstallationBlobstore.Provider,
+		Options:  options,
+	}
+}
+
 func (c FSConfig) Save() error {
 	bytes, err := yaml.Marshal(c.schema)
 	if err != nil {
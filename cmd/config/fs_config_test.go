@@ -389,6 +389,143 @@ var _ = Describe("FSConfig", func() {
 		})
 	})
 
+	Describe("InstallationBlobstore", func() {
+		It("returns an empty blobstore if none is configured", func() {
+			Expect(config.InstallationBlobstore()).To(Equal(Blobstore{}))
+		})
+
+		It("returns the configured blobstore and keeps it when saving", func() {
+			fs.WriteFileString("/dir/sub-dir/config", `
+installation_blobstore:
+  provider: s3
+  options:
+    bucket_name: fake-bucket
+    region: fake-region
+`)
+
+			expectedBlobstore := Blobstore{
+				Provider: "s3",
+				Options: map[string]interface{}{
+					"bucket_name": "fake-bucket",
+					"region":      "fake-region",
+				},
+			}
+
+			config = readConfig()
+			Expect(config.InstallationBlobstore()).To(Equal(expectedBlobstore))
+
+			updatedConfig, err := config.AliasEnvironment("url1", "alias1", "")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = updatedConfig.Save()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(readConfig().InstallationBlobstore()).To(Equal(expectedBlobstore))
+		})
+
+		It("does not save an unconfigured blobstore", func() {
+			err := config.Save()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.ReadFileString("/dir/sub-dir/config")).ToNot(ContainSubstring("installation_blobstore"))
+		})
+	})
+
+	Describe("environment variable references", func() {
+		BeforeEach(func() {
+			os.Setenv("BOSH_CONFIG_TEST_PASSWORD", "fake-password")
+			os.Setenv("BOSH_CONFIG_TEST_SECRET_KEY", "fake-secret-key")
+
+			fs.WriteFileString("/dir/sub-dir/config", `
+environments:
+- url: url
+  alias: alias
+  username: admin
+  password: ${BOSH_CONFIG_TEST_PASSWORD}
+installation_blobstore:
+  provider: s3
+  options:
+    bucket_name: fake-bucket
+    credentials:
+      secret_access_key: ${BOSH_CONFIG_TEST_SECRET_KEY}
+`)
+		})
+
+		AfterEach(func() {
+			os.Unsetenv("BOSH_CONFIG_TEST_PASSWORD")
+			os.Unsetenv("BOSH_CONFIG_TEST_SECRET_KEY")
+		})
+
+		It("resolves credentials and blobstore options from the environment", func() {
+			config = readConfig()
+
+			Expect(config.Credentials("alias")).To(Equal(Creds{Client: "admin", ClientSecret: "fake-password"}))
+			Expect(config.InstallationBlobstore().Options).To(Equal(map[string]interface{}{
+				"bucket_name": "fake-bucket",
+				"credentials": map[interface{}]interface{}{
+					"secret_access_key": "fake-secret-key",
+				},
+			}))
+		})
+
+		It("keeps references when saving", func() {
+			config = readConfig()
+
+			updatedConfig := config.SetCredentials("alias", Creds{
+				Client:       "admin",
+				ClientSecret: "fake-password",
+				RefreshToken: "fake-token",
+			})
+
+			err := updatedConfig.Save()
+			Expect(err).ToNot(HaveOccurred())
+
+			contents, err := fs.ReadFileString("/dir/sub-dir/config")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(ContainSubstring("${BOSH_CONFIG_TEST_PASSWORD}"))
+			Expect(contents).To(ContainSubstring("${BOSH_CONFIG_TEST_SECRET_KEY}"))
+			Expect(contents).ToNot(ContainSubstring("fake-password"))
+			Expect(contents).ToNot(ContainSubstring("fake-secret-key"))
+
+			Expect(readConfig().Credentials("alias")).To(Equal(Creds{
+				Client:       "admin",
+				ClientSecret: "fake-password",
+				RefreshToken: "fake-token",
+			}))
+		})
+
+		It("replaces references when credentials change", func() {
+			config = readConfig()
+
+			updatedConfig := config.SetCredentials("alias", Creds{Client: "admin", ClientSecret: "new-password"})
+
+			err := updatedConfig.Save()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.ReadFileString("/dir/sub-dir/config")).ToNot(ContainSubstring("${BOSH_CONFIG_TEST_PASSWORD}"))
+			Expect(readConfig().Credentials("alias").ClientSecret).To(Equal("new-password"))
+		})
+
+		It("treats values that are not only a reference literally", func() {
+			fs.WriteFileString("/dir/sub-dir/config", `
+environments:
+- url: url
+  password: pa${BOSH_CONFIG_TEST_PASSWORD}ss
+`)
+
+			Expect(readConfig().Credentials("url").ClientSecret).To(Equal("pa${BOSH_CONFIG_TEST_PASSWORD}ss"))
+		})
+
+		It("returns an error when a referenced environment variable is not set", func() {
+			os.Unsetenv("BOSH_CONFIG_TEST_PASSWORD")
+
+			_, err := NewFSConfigFromPath("/dir/sub-dir/config", fs)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Resolving environment variables in config '/dir/sub-dir/config'"))
+			Expect(err.Error()).To(ContainSubstring("Expected environment variable 'BOSH_CONFIG_TEST_PASSWORD' to be set"))
+		})
+	})
+
 	Describe("Save", func() {
 		It("chmods the file to 600", func() {
 			config := readConfig()
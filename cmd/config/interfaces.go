@@ -13,6 +13,8 @@ type Config interface {
 	SetCredentials(url string, creds Creds) Config
 	UnsetCredentials(url string) Config
 
+	InstallationBlobstore() Blobstore
+
 	Save() error
 }
 
@@ -20,3 +22,10 @@ type Environment struct {
 	URL   string
 	Alias string
 }
+
+// Blobstore configures where create-env keeps compiled packages and rendered jobs of installations.
+// An empty Provider keeps them in the local blobstore of each installation.
+type Blobstore struct {
+	Provider string
+	Options  map[string]interface{}
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/cloud"
+)
+
+// CPITimeoutArg overrides how long a CPI method may run, e.g. 'create_vm=2h'.
+// The method 'default' applies to methods without a timeout of their own.
+type CPITimeoutArg struct {
+	Method  string
+	Timeout time.Duration
+}
+
+func (a *CPITimeoutArg) UnmarshalFlag(data string) error {
+	pieces := strings.SplitN(data, "=", 2)
+	if len(pieces) != 2 || len(pieces[0]) == 0 {
+		return bosherr.Errorf("Expected CPI timeout '%s' to be in format 'method=duration'", data)
+	}
+
+	timeout, err := time.ParseDuration(pieces[1])
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Parsing CPI timeout '%s'", data)
+	}
+
+	if timeout < 0 {
+		return bosherr.Errorf("Expected CPI timeout '%s' to not be negative", data)
+	}
+
+	*a = CPITimeoutArg{Method: pieces[0], Timeout: timeout}
+
+	return nil
+}
+
+// NewCPIMethodTimeouts returns the default CPI method timeouts with the given overrides applied
+func NewCPIMethodTimeouts(args []CPITimeoutArg) bicloud.CPIMethodTimeouts {
+	timeouts := bicloud.NewDefaultCPIMethodTimeouts()
+
+	for _, arg := range args {
+		timeouts = timeouts.Override(arg.Method, arg.Timeout)
+	}
+
+	return timeouts
+}
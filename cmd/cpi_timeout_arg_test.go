@@ -0,0 +1,65 @@
+package cmd_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+)
+
+var _ = Describe("CPITimeoutArg", func() {
+	Describe("UnmarshalFlag", func() {
+		var (
+			arg *CPITimeoutArg
+		)
+
+		BeforeEach(func() {
+			arg = &CPITimeoutArg{}
+		})
+
+		It("sets method and timeout", func() {
+			err := arg.UnmarshalFlag("create_vm=2h")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*arg).To(Equal(CPITimeoutArg{Method: "create_vm", Timeout: 2 * time.Hour}))
+		})
+
+		It("accepts 0 to disable the timeout", func() {
+			err := arg.UnmarshalFlag("default=0")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*arg).To(Equal(CPITimeoutArg{Method: "default", Timeout: 0}))
+		})
+
+		It("returns an error if value is not in the method=duration format", func() {
+			err := arg.UnmarshalFlag("=2h")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected CPI timeout '=2h' to be in format 'method=duration'"))
+		})
+
+		It("returns an error if the duration cannot be parsed", func() {
+			err := arg.UnmarshalFlag("create_vm=2 hours")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Parsing CPI timeout 'create_vm=2 hours'"))
+		})
+
+		It("returns an error if the duration is negative", func() {
+			err := arg.UnmarshalFlag("create_vm=-1m")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected CPI timeout 'create_vm=-1m' to not be negative"))
+		})
+	})
+
+	Describe("NewCPIMethodTimeouts", func() {
+		It("overrides the default timeouts", func() {
+			timeouts := NewCPIMethodTimeouts([]CPITimeoutArg{
+				{Method: "create_vm", Timeout: 2 * time.Hour},
+				{Method: "default", Timeout: 0},
+			})
+
+			Expect(timeouts.For("create_vm")).To(Equal(2 * time.Hour))
+			Expect(timeouts.For("delete_vm")).To(Equal(15 * time.Minute))
+			Expect(timeouts.For("quota")).To(Equal(time.Duration(0)))
+		})
+	})
+})
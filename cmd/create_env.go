@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	"github.com/cppforlife/go-patch/patch"
 
 	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
@@ -19,9 +20,74 @@ func NewCreateEnvCmd(ui boshui.UI, envProvider EnvProviderFunction) *CreateEnvCm
 }
 
 func (c *CreateEnvCmd) Run(stage boshui.Stage, opts CreateEnvOpts) error {
-	c.ui.BeginLinef("Deployment manifest: '%s'\n", opts.Args.Manifest.Path)
+	if opts.PrintManifest {
+		return c.printManifest(opts)
+	}
 
-	depPreparer := c.envProvider(opts.Args.Manifest.Path, opts.StatePath, opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp())
+	artifactOpts := DeploymentArtifactOpts{Path: opts.ExportArtifact, Format: opts.ExportArtifactFormat}
 
-	return depPreparer.PrepareDeployment(stage, opts.Recreate, opts.RecreatePersistentDisks)
+	// Validated before deploying so that a typo does not lose the artifact of a successful deploy
+	err := artifactOpts.Validate()
+	if err != nil {
+		return err
+	}
+
+	// Pruning would delete packages that other environments using the cache or index still need
+	if opts.PruneCompiled && len(opts.CompiledPackageCache) > 0 {
+		return bosherr.Error("Expected --prune-compiled not to be given with --compiled-package-cache")
+	}
+
+	if opts.PruneCompiled && len(opts.CompiledPackageIndex) > 0 {
+		return bosherr.Error("Expected --prune-compiled not to be given with --compiled-package-index")
+	}
+
+	if opts.Args.Manifest.Stdin && len(opts.StatePath) == 0 {
+		return bosherr.Error("Expected --state to be given when reading the manifest from stdin")
+	}
+
+	removeManifest, err := opts.Args.Manifest.WriteTempFile()
+	if err != nil {
+		return err
+	}
+
+	defer removeManifest()
+
+	c.ui.BeginLinef("Deployment manifest: '%s'\n", opts.Args.Manifest.Name())
+
+	depPreparer := c.envProvider(opts.Args.Manifest.Path, opts.Args.Manifest.StatePath(opts.StatePath), opts.VarFlags.AsVariables(), withTags(opts.OpsFlags.AsOp(), opts.Tags))
+
+	return depPreparer.PrepareDeployment(stage, PrepareDeploymentOpts{
+		Recreate:                opts.Recreate,
+		RecreatePersistentDisks: opts.RecreatePersistentDisks,
+		PruneCompiled:           opts.PruneCompiled,
+		ProbeAgent:              opts.ProbeAgent,
+		TagStemcell:             opts.TagStemcell,
+		SkipPreflight:           opts.SkipPreflight,
+		AllowDowngrade:          opts.AllowDowngrade,
+		Production:              opts.Production,
+		DryRun:                  opts.DryRun,
+
+		Artifact: artifactOpts,
+	})
+}
+
+func (c *CreateEnvCmd) printManifest(opts CreateEnvOpts) error {
+	vars := opts.VarFlags.AsVariables()
+	if !opts.NoRedact {
+		vars = boshtpl.NewRedactedVars(vars)
+	}
+
+	removeManifest, err := opts.Args.Manifest.WriteTempFile()
+	if err != nil {
+		return err
+	}
+
+	defer removeManifest()
+
+	err = NewInterpolateCmd(c.ui).Print(opts.Args.Manifest.Bytes, vars, withTags(opts.OpsFlags.AsOp(), opts.Tags), boshtpl.EvaluateOpts{})
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Evaluating manifest '%s'", opts.Args.Manifest.Name())
+	}
+
+	return nil
 }
@@ -3,6 +3,7 @@ package cmd_test
 import (
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"regexp"
 
@@ -17,6 +18,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
 
+	biagentclient "github.com/cloudfoundry/bosh-agent/agentclient"
 	mock_httpagent "github.com/cloudfoundry/bosh-agent/agentclient/http/mocks"
 	mock_agentclient "github.com/cloudfoundry/bosh-cli/agentclient/mocks"
 	mock_blobstore "github.com/cloudfoundry/bosh-cli/blobstore/mocks"
@@ -32,6 +34,8 @@ import (
 	fakebideplmanifest "github.com/cloudfoundry/bosh-cli/deployment/manifest/manifestfakes"
 	fakebideplval "github.com/cloudfoundry/bosh-cli/deployment/manifest/manifestfakes"
 	mock_deployment "github.com/cloudfoundry/bosh-cli/deployment/mocks"
+	bisshtunnel "github.com/cloudfoundry/bosh-cli/deployment/sshtunnel"
+	fakebisshtunnel "github.com/cloudfoundry/bosh-cli/deployment/sshtunnel/fakes"
 	bidepltpl "github.com/cloudfoundry/bosh-cli/deployment/template"
 	fakebidepltpl "github.com/cloudfoundry/bosh-cli/deployment/template/templatefakes"
 	fakebivm "github.com/cloudfoundry/bosh-cli/deployment/vm/fakes"
@@ -56,6 +60,7 @@ import (
 	fakebistemcell "github.com/cloudfoundry/bosh-cli/stemcell/stemcellfakes"
 	biui "github.com/cloudfoundry/bosh-cli/ui"
 	fakebiui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 )
 
 var _ = Describe("CreateEnvCmd", func() {
@@ -79,6 +84,8 @@ var _ = Describe("CreateEnvCmd", func() {
 			manifestSHA   string
 
 			mockDeployer              *mock_deployment.MockDeployer
+			fakeSSHTunnelFactory      *fakebisshtunnel.FakeFactory
+			sshTunnelPool             bisshtunnel.PoolingFactory
 			mockInstaller             *mock_install.MockInstaller
 			mockInstallerFactory      *mock_install.MockInstallerFactory
 			releaseReader             *fakerel.FakeReader
@@ -87,7 +94,13 @@ var _ = Describe("CreateEnvCmd", func() {
 			mockRegistryServer        *mock_registry.MockServer
 			mockAgentClient           *mock_agentclient.MockAgentClient
 			mockAgentClientFactory    *mock_httpagent.MockAgentClientFactory
-			mockCloudFactory          *mock_cloud.MockFactory
+			expectGetState            *gomock.Call
+
+			cloudPropertiesOverrides []bicmd.CloudPropertiesOverrideArg
+			mockCloudFactory         *mock_cloud.MockFactory
+
+			warnings         biwarn.Warnings
+			warningsAsErrors bool
 
 			cpiRelease *fakebirel.FakeRelease
 			logger     boshlog.Logger
@@ -130,10 +143,12 @@ var _ = Describe("CreateEnvCmd", func() {
 			boshDeploymentManifest bideplmanifest.Manifest
 			installationManifest   biinstallmanifest.Manifest
 			cloud                  bicloud.Cloud
+			fakeCPICmdRunner       *fakebicloud.FakeCPICmdRunner
 
 			cloudStemcell bistemcell.CloudStemcell
 
 			defaultCreateEnvOpts bicmd.CreateEnvOpts
+			dryRunStateService   bool
 
 			expectLegacyMigrate        *gomock.Call
 			expectStemcellUpload       *gomock.Call
@@ -158,6 +173,8 @@ var _ = Describe("CreateEnvCmd", func() {
 			fs.WriteFileString(deploymentManifestPath, "")
 
 			mockDeployer = mock_deployment.NewMockDeployer(mockCtrl)
+			fakeSSHTunnelFactory = fakebisshtunnel.NewFakeFactory()
+			sshTunnelPool = bisshtunnel.NewPoolingFactory(fakeSSHTunnelFactory, logger)
 			mockInstaller = mock_install.NewMockInstaller(mockCtrl)
 			mockInstallerFactory = mock_install.NewMockInstallerFactory(mockCtrl)
 
@@ -167,9 +184,17 @@ var _ = Describe("CreateEnvCmd", func() {
 			mockRegistryServerManager = mock_registry.NewMockServerManager(mockCtrl)
 			mockRegistryServer = mock_registry.NewMockServer(mockCtrl)
 
+			cloudPropertiesOverrides = nil
+			warnings = biwarn.NewWarnings(logger)
+			warningsAsErrors = false
+
 			mockAgentClientFactory = mock_httpagent.NewMockAgentClientFactory(mockCtrl)
 			mockAgentClient = mock_agentclient.NewMockAgentClient(mockCtrl)
 			mockAgentClientFactory.EXPECT().NewAgentClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(mockAgentClient, nil).AnyTimes()
+			expectGetState = mockAgentClient.EXPECT().GetState().Return(biagentclient.AgentState{
+				JobState:     "running",
+				NetworkSpecs: map[string]biagentclient.NetworkSpec{"fake-network-name": {IP: "10.0.0.5"}},
+			}, nil).AnyTimes()
 
 			mockCloudFactory = mock_cloud.NewMockFactory(mockCtrl)
 
@@ -213,6 +238,7 @@ var _ = Describe("CreateEnvCmd", func() {
 				bistemcell.Manifest{
 					Name:            "fake-stemcell-name",
 					Version:         "fake-stemcell-version",
+					OS:              "fake-stemcell-os",
 					SHA1:            "fake-stemcell-sha1",
 					CloudProperties: biproperty.Map{},
 				},
@@ -235,6 +261,12 @@ var _ = Describe("CreateEnvCmd", func() {
 			fakeDeploymentValidator.SetValidateReleaseJobsBehavior([]fakebideplval.ValidateReleaseJobsOutput{
 				{Err: nil},
 			})
+			fakeDeploymentValidator.SetValidateJobPropertiesBehavior([]fakebideplval.ValidateJobPropertiesOutput{
+				{Err: nil},
+			})
+			fakeDeploymentValidator.SetValidateStemcellCompatibilityBehavior([]fakebideplval.ValidateStemcellCompatibilityOutput{
+				{Err: nil},
+			})
 
 			// stemcell exists
 			fs.WriteFile(stemcellTarballPath, []byte{})
@@ -298,27 +330,32 @@ var _ = Describe("CreateEnvCmd", func() {
 				return cpiRelease, nil
 			}
 
-			cloud = bicloud.NewCloud(fakebicloud.NewFakeCPICmdRunner(), "fake-director-id", logger)
+			fakeCPICmdRunner = fakebicloud.NewFakeCPICmdRunner()
+			cloud = bicloud.NewCloud(fakeCPICmdRunner, "fake-director-id", 1, logger)
 			cloudStemcell = fakebistemcell.NewFakeCloudStemcell(
 				"fake-stemcell-cid", "fake-stemcell-name", "fake-stemcell-version")
 
 			defaultCreateEnvOpts = bicmd.CreateEnvOpts{
 				Args: bicmd.CreateEnvArgs{
-					Manifest: bicmd.FileBytesWithPathArg{Path: deploymentManifestPath},
+					Manifest: bicmd.EnvManifestArg{Path: deploymentManifestPath},
 				},
 			}
+			dryRunStateService = false
 		})
 
 		JustBeforeEach(func() {
 			doGet := func(deploymentManifestPath string, statePath string, deploymentVars boshtpl.Variables, deploymentOp patch.Op) bicmd.DeploymentPreparer {
 				deploymentStateService := biconfig.NewFileSystemDeploymentStateService(fs, configUUIDGenerator, logger, biconfig.DeploymentStatePath(deploymentManifestPath, statePath))
+				if dryRunStateService {
+					deploymentStateService = biconfig.NewDryRunDeploymentStateService(fs, configUUIDGenerator, logger, biconfig.DeploymentStatePath(deploymentManifestPath, statePath))
+				}
 				deploymentRepo := biconfig.NewDeploymentRepo(deploymentStateService)
 				releaseRepo := biconfig.NewReleaseRepo(deploymentStateService, fakeUUIDGenerator)
 				stemcellRepo := biconfig.NewStemcellRepo(deploymentStateService, fakeUUIDGenerator)
 				deploymentRecord := deployment.NewRecord(deploymentRepo, releaseRepo, stemcellRepo)
 
 				tarballCache := bitarball.NewCache("fake-base-path", fs, logger)
-				tarballProvider := bitarball.NewProvider(tarballCache, fs, nil, 1, 0, logger)
+				tarballProvider := bitarball.NewProvider(tarballCache, fs, nil, nil, 1, 0, biui.NewNoopProgressReporter(), logger)
 
 				cpiInstaller := bicpirel.CpiInstaller{
 					ReleaseManager:   releaseManager,
@@ -340,6 +377,8 @@ var _ = Describe("CreateEnvCmd", func() {
 					fakeDeploymentValidator,
 					releaseManager,
 					fakeDeploymentTemplateFactory,
+					cloudPropertiesOverrides,
+					logger,
 				)
 
 				fakeInstallationUUIDGenerator := &fakeuuid.FakeGenerator{}
@@ -348,6 +387,10 @@ var _ = Describe("CreateEnvCmd", func() {
 					deploymentStateService,
 					fakeInstallationUUIDGenerator,
 					filepath.Join("fake-install-dir"),
+					"",
+					"",
+					"",
+					fs,
 				)
 				tempRootConfigurator := bicmd.NewTempRootConfigurator(fs)
 
@@ -355,6 +398,7 @@ var _ = Describe("CreateEnvCmd", func() {
 					userInterface,
 					logger,
 					"deployCmd",
+					fs,
 					deploymentStateService,
 					mockLegacyDeploymentStateMigrator,
 					releaseManager,
@@ -365,6 +409,7 @@ var _ = Describe("CreateEnvCmd", func() {
 					mockVMManagerFactory,
 					mockBlobstoreFactory,
 					mockDeployer,
+					sshTunnelPool,
 					deploymentManifestPath,
 					deploymentVars,
 					deploymentOp,
@@ -373,8 +418,12 @@ var _ = Describe("CreateEnvCmd", func() {
 					stemcellFetcher,
 					releaseSetAndInstallationManifestParser,
 					deploymentManifestParser,
+					fakeDeploymentValidator,
 					tempRootConfigurator,
 					targetProvider,
+					warnings,
+					warningsAsErrors,
+					1,
 				)
 			}
 
@@ -386,7 +435,7 @@ var _ = Describe("CreateEnvCmd", func() {
 
 			fakeStemcellManagerFactory.SetNewManagerBehavior(cloud, mockStemcellManager)
 
-			expectStemcellUpload = mockStemcellManager.EXPECT().Upload(extractedStemcell, fakeStage).Return(cloudStemcell, nil).AnyTimes()
+			expectStemcellUpload = mockStemcellManager.EXPECT().Upload(extractedStemcell, gomock.Any(), fakeStage).Return(cloudStemcell, nil).AnyTimes()
 
 			expectStemcellDeleteUnused = mockStemcellManager.EXPECT().DeleteUnused(fakeStage).AnyTimes()
 
@@ -408,7 +457,7 @@ var _ = Describe("CreateEnvCmd", func() {
 
 			mockInstallerFactory.EXPECT().NewInstaller(target).Return(mockInstaller).AnyTimes()
 
-			installation := biinstall.NewInstallation(target, installedJob, installationManifest, mockRegistryServerManager)
+			installation := biinstall.NewInstallation(target, installedJob, nil, installationManifest, mockRegistryServerManager)
 
 			expectInstall = mockInstaller.EXPECT().Install(installationManifest, gomock.Any()).Do(func(_ interface{}, stage biui.Stage) {
 				Expect(fakeStage.SubStages).To(ContainElement(stage))
@@ -452,7 +501,7 @@ var _ = Describe("CreateEnvCmd", func() {
 					createEnvOptsWithStatePath := bicmd.CreateEnvOpts{
 						StatePath: filepath.Join("/", "specified", "path", "to", "cool-state.json"),
 						Args: bicmd.CreateEnvArgs{
-							Manifest: bicmd.FileBytesWithPathArg{Path: deploymentManifestPath},
+							Manifest: bicmd.EnvManifestArg{Path: deploymentManifestPath},
 						},
 					}
 
@@ -463,6 +512,205 @@ var _ = Describe("CreateEnvCmd", func() {
 			})
 		})
 
+		Context("when the manifest is read from stdin", func() {
+			var stdinOpts bicmd.CreateEnvOpts
+
+			BeforeEach(func() {
+				manifestBytes, err := fs.ReadFile(deploymentManifestPath)
+				Expect(err).ToNot(HaveOccurred())
+
+				stdinOpts = bicmd.CreateEnvOpts{
+					StatePath: filepath.Join("/", "specified", "path", "to", "cool-state.json"),
+					Args: bicmd.CreateEnvArgs{
+						Manifest: bicmd.EnvManifestArg{FS: fs, Bytes: manifestBytes, Stdin: true},
+					},
+				}
+
+				// the manifest is written to the current directory, which legacy state is looked up next to
+				dir, err := filepath.Abs(".")
+				Expect(err).ToNot(HaveOccurred())
+
+				mockLegacyDeploymentStateMigrator.EXPECT().MigrateIfExists(filepath.Join(dir, "bosh-deployments.yml")).AnyTimes()
+			})
+
+			It("deploys the manifest and names stdin as its source", func() {
+				err := command.Run(fakeStage, stdinOpts)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stdOut).To(gbytes.Say("Deployment manifest: 'stdin'"))
+			})
+
+			It("requires the state file path", func() {
+				stdinOpts.StatePath = ""
+
+				expectDeploy.Times(0)
+
+				err := command.Run(fakeStage, stdinOpts)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Expected --state to be given when reading the manifest from stdin"))
+			})
+		})
+
+		Context("when `print-manifest` flag is specified", func() {
+			BeforeEach(func() {
+				defaultCreateEnvOpts.PrintManifest = true
+				defaultCreateEnvOpts.Args.Manifest.Bytes = []byte("name: ((name))\npassword: ((password))\n")
+				defaultCreateEnvOpts.VarFlags = bicmd.VarFlags{
+					VarKVs: []boshtpl.VarKV{
+						{Name: "name", Value: "fake-name"},
+						{Name: "password", Value: "fake-password"},
+					},
+				}
+			})
+
+			It("prints the resolved manifest with redacted variable values without deploying", func() {
+				expectDeploy.Times(0)
+				expectInstall.Times(0)
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(stdOut.Contents())).To(Equal("name: <redacted>\npassword: <redacted>\n"))
+				Expect(fakeStage.PerformCalls).To(BeEmpty())
+			})
+
+			It("prints variable values if `no-redact` flag is specified", func() {
+				defaultCreateEnvOpts.NoRedact = true
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(stdOut.Contents())).To(Equal("name: fake-name\npassword: fake-password\n"))
+			})
+
+			It("returns an error if manifest cannot be evaluated", func() {
+				defaultCreateEnvOpts.Args.Manifest.Bytes = []byte("name: [")
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Evaluating manifest"))
+			})
+
+			It("adds `tag` values to the manifest tags", func() {
+				defaultCreateEnvOpts.NoRedact = true
+				defaultCreateEnvOpts.Args.Manifest.Bytes = []byte("name: ((name))\ntags:\n  env: dev\n  team: core\n")
+				defaultCreateEnvOpts.Tags = []bicmd.TagArg{
+					{Key: "env", Value: "prod"},
+					{Key: "owner", Value: "ops"},
+				}
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(stdOut.Contents())).To(Equal("name: fake-name\ntags:\n  env: prod\n  owner: ops\n  team: core\n"))
+			})
+		})
+
+		Context("when `production` flag is specified", func() {
+			BeforeEach(func() {
+				defaultCreateEnvOpts.Production = true
+			})
+
+			It("marks the deployment state as production", func() {
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).NotTo(HaveOccurred())
+
+				deploymentState, err := biconfig.NewFileSystemDeploymentStateService(fs, configUUIDGenerator, logger, deploymentStatePath).Load()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(deploymentState.Production).To(BeTrue())
+			})
+		})
+
+		It("keeps the deployment state marked as production without the `production` flag", func() {
+			err := fs.WriteFileString(deploymentStatePath, `{"director_id": "generated-director-uuid", "production": true}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = command.Run(fakeStage, defaultCreateEnvOpts)
+			Expect(err).NotTo(HaveOccurred())
+
+			deploymentState, err := biconfig.NewFileSystemDeploymentStateService(fs, configUUIDGenerator, logger, deploymentStatePath).Load()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploymentState.Production).To(BeTrue())
+		})
+
+		Context("when `dry-run` flag is specified", func() {
+			BeforeEach(func() {
+				defaultCreateEnvOpts.DryRun = true
+				dryRunStateService = true
+			})
+
+			It("prints the planned changes without installing the CPI, deploying or writing the state file", func() {
+				expectLegacyMigrate.Times(0)
+				expectInstall.Times(0)
+				expectNewCloud.Times(0)
+				expectStemcellUpload.Times(0)
+				expectDeploy.Times(0)
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stdOut).To(gbytes.Say("Planned changes"))
+				Expect(stdOut).To(gbytes.Say(`Stemcell fake-stemcell-name/fake-stemcell-version\s+upload`))
+				Expect(stdOut).To(gbytes.Say("Dry run: skipping deploy."))
+
+				Expect(fs.FileExists(deploymentStatePath)).To(BeFalse())
+			})
+
+			It("reuses the stemcell recorded in the state file", func() {
+				err := fs.WriteFileString(deploymentStatePath, `{
+					"director_id": "fake-director-id",
+					"stemcells": [{"id": "fake-stemcell-id", "name": "fake-stemcell-name", "version": "fake-stemcell-version", "cid": "fake-stemcell-cid"}]
+				}`)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stdOut).To(gbytes.Say(`reuse\s+Use uploaded stemcell 'fake-stemcell-cid'`))
+			})
+		})
+
+		Context("when a persistent disk changed in the manifest", func() {
+			var fakeUI *fakebiui.FakeUI
+
+			BeforeEach(func() {
+				fakeUI = &fakebiui.FakeUI{}
+				userInterface = fakeUI
+
+				boshDeploymentManifest.Jobs[0].Instances = 1
+				boshDeploymentManifest.Jobs[0].PersistentDisk = 2048
+
+				err := fs.WriteFileString(deploymentStatePath, `{
+					"director_id": "generated-director-uuid",
+					"current_disk_id": "fake-disk-id",
+					"disks": [{"id": "fake-disk-id", "cid": "fake-disk-cid", "size": 1024, "cloud_properties": {}}]
+				}`)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("asks for confirmation before installing the CPI", func() {
+				fakeUI.AskedConfirmationErr = errors.New("stopped")
+
+				expectInstall.Times(0)
+				expectDeploy.Times(0)
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).To(Equal(errors.New("stopped")))
+				Expect(fakeUI.AskedConfirmationCalled).To(BeTrue())
+				Expect(fakeUI.Said).To(ContainElement("  - Disk fake-job-name/0: Copy disk 'fake-disk-cid' (1024 MB) to a new 2048 MB disk and delete it"))
+			})
+
+			It("deploys once confirmed", func() {
+				expectDeploy.Times(1)
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeUI.AskedConfirmationCalled).To(BeTrue())
+			})
+
+			It("does not ask when persistent disks are recreated anyway", func() {
+				defaultCreateEnvOpts.RecreatePersistentDisks = true
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeUI.AskedConfirmationCalled).To(BeFalse())
+			})
+		})
+
 		It("does not migrate the legacy bosh-deployments.yml if manifest-state.json exists", func() {
 			err := fs.WriteFileString(deploymentStatePath, "{}")
 			Expect(err).ToNot(HaveOccurred())
@@ -537,6 +785,19 @@ var _ = Describe("CreateEnvCmd", func() {
 			}))
 		})
 
+		It("validates jobs in manifest support the stemcell", func() {
+			err := command.Run(fakeStage, defaultCreateEnvOpts)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeDeploymentValidator.ValidateStemcellCompatibilityInputs).To(Equal([]fakebideplval.ValidateStemcellCompatibilityInput{
+				{
+					Manifest:        boshDeploymentManifest,
+					ReleaseManager:  releaseManager,
+					StemcellOS:      "fake-stemcell-os",
+					StemcellVersion: "fake-stemcell-version",
+				},
+			}))
+		})
+
 		It("logs validating stages", func() {
 			err := command.Run(fakeStage, defaultCreateEnvOpts)
 			Expect(err).NotTo(HaveOccurred())
@@ -621,16 +882,232 @@ var _ = Describe("CreateEnvCmd", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 
-		It("adds a new 'deploying' event logger stage", func() {
+		It("adds a new 'Validating CPI connectivity' event logger stage", func() {
 			err := command.Run(fakeStage, defaultCreateEnvOpts)
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(fakeStage.PerformCalls[3]).To(Equal(&fakebiui.PerformCall{
+				Name: "Validating CPI connectivity",
+			}))
+			Expect(fakeCPICmdRunner.RunInputs[0].Method).To(Equal("info"))
+		})
+
+		Context("when the CPI cannot reach the IaaS", func() {
+			BeforeEach(func() {
+				fakeCPICmdRunner.RunCmdOutput = bicloud.CmdOutput{
+					Error: &bicloud.CmdError{
+						Type:    "Bosh::Clouds::CloudError",
+						Message: "fake-auth-error",
+					},
+				}
+			})
+
+			It("fails with the CPI error before uploading the stemcell", func() {
+				expectStemcellUpload.Times(0)
+				expectDeploy.Times(0)
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Validating CPI connectivity (use --skip-preflight to deploy anyway)"))
+				Expect(err.Error()).To(ContainSubstring("fake-auth-error"))
+
+				Expect(fakeStage.PerformCalls[3].Name).To(Equal("Validating CPI connectivity"))
+				Expect(fakeStage.PerformCalls[3].Error).To(HaveOccurred())
+			})
+
+			It("does not check connectivity with --skip-preflight", func() {
+				defaultCreateEnvOpts.SkipPreflight = true
+
+				_ = command.Run(fakeStage, defaultCreateEnvOpts)
+
+				for _, call := range fakeStage.PerformCalls {
+					Expect(call.Name).ToNot(Equal("Validating CPI connectivity"))
+				}
+				for _, input := range fakeCPICmdRunner.RunInputs {
+					Expect(input.Method).ToNot(Equal("info"))
+				}
+			})
+		})
+
+		Context("when the CPI does not implement info", func() {
+			BeforeEach(func() {
+				fakeCPICmdRunner.RunCmdOutput = bicloud.CmdOutput{
+					Error: &bicloud.CmdError{
+						Type:    "Bosh::Clouds::CloudError",
+						Message: "Invalid Method: info",
+					},
+				}
+			})
+
+			It("passes the connectivity check", func() {
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeStage.PerformCalls[3]).To(Equal(&fakebiui.PerformCall{
+					Name: "Validating CPI connectivity",
+				}))
+			})
+		})
+
+		Context("when the manifest has tags", func() {
+			var uploadedTags map[string]string
+
+			BeforeEach(func() {
+				boshDeploymentManifest.Tags = map[string]string{"owner": "fake-owner"}
+				uploadedTags = nil
+			})
+
+			JustBeforeEach(func() {
+				expectStemcellUpload.Do(func(_ bistemcell.ExtractedStemcell, tags map[string]string, _ biui.Stage) {
+					uploadedTags = tags
+				})
+			})
+
+			It("does not tag the stemcell by default", func() {
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(uploadedTags).To(BeNil())
+			})
+
+			It("tags the stemcell when `tag-stemcell` flag is specified", func() {
+				defaultCreateEnvOpts.TagStemcell = true
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(uploadedTags).To(Equal(map[string]string{"owner": "fake-owner"}))
+			})
+		})
+
+		Context("when the CPI reports the available quota", func() {
+			BeforeEach(func() {
+				boshDeploymentManifest.Jobs[0].Instances = 1
+				fakeDeploymentParser.ParseReturns(boshDeploymentManifest, nil)
+			})
+
+			It("fails before uploading the stemcell when the deployment does not fit", func() {
+				fakeCPICmdRunner.RunCmdOutput = bicloud.CmdOutput{
+					Result: map[string]interface{}{"vms": float64(0)},
+				}
+				expectStemcellUpload.Times(0)
+				expectDeploy.Times(0)
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Insufficient quota to deploy:\n  - VMs: requested 1, available 0"))
+
+				Expect(fakeCPICmdRunner.RunInputs[0].Method).To(Equal("info"))
+				Expect(fakeCPICmdRunner.RunInputs[1].Method).To(Equal("quota"))
+			})
+
+			It("deploys when the deployment fits", func() {
+				fakeCPICmdRunner.RunCmdOutput = bicloud.CmdOutput{
+					Result: map[string]interface{}{"vms": float64(1)},
+				}
+				expectDeploy.Times(1)
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when exporting an artifact", func() {
+			BeforeEach(func() {
+				defaultCreateEnvOpts.ExportArtifact = "/path/to/artifact.json"
+				defaultCreateEnvOpts.ExportArtifactFormat = "json"
+			})
+
+			It("writes the artifact after deploying", func() {
+				expectDeploy.Times(1)
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fs.FileExists("/path/to/artifact.json")).To(BeTrue())
+				Expect(fs.GetFileTestStat("/path/to/artifact.json").FileMode).To(Equal(os.FileMode(0600)))
+				Expect(fs.ReadFileString("/path/to/artifact.json")).To(ContainSubstring(`"name": "fake-deployment-name"`))
+
+				Expect(stdOut).To(gbytes.Say("Exported deployment artifact: '/path/to/artifact.json'"))
+			})
+
+			It("fails before deploying when the format is unknown", func() {
+				defaultCreateEnvOpts.ExportArtifactFormat = "xml"
+				expectDeploy.Times(0)
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Unknown artifact format 'xml'"))
+			})
+		})
+
+		It("skips the quota check when the CPI does not implement it", func() {
+			fakeCPICmdRunner.RunCmdOutput = bicloud.CmdOutput{
+				Error: &bicloud.CmdError{Type: bicloud.NotImplementedError, Message: "fake-not-implemented"},
+			}
+			expectDeploy.Times(1)
+
+			err := command.Run(fakeStage, defaultCreateEnvOpts)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("adds a new 'deploying' event logger stage", func() {
+			err := command.Run(fakeStage, defaultCreateEnvOpts)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeStage.PerformCalls[4]).To(Equal(&fakebiui.PerformCall{
 				Name:  "deploying",
 				Stage: &fakebiui.FakeStage{}, // mock deployer doesn't add sub-stages
 			}))
 		})
 
+		It("names the event logger stage 'recreating VM' when recreating", func() {
+			defaultCreateEnvOpts.Recreate = true
+
+			err := command.Run(fakeStage, defaultCreateEnvOpts)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeStage.PerformCalls[4]).To(Equal(&fakebiui.PerformCall{
+				Name:  "recreating VM",
+				Stage: &fakebiui.FakeStage{}, // mock deployer doesn't add sub-stages
+			}))
+		})
+
+		It("stops the pooled SSH tunnels after deploying", func() {
+			fakeSSHTunnel := fakebisshtunnel.NewFakeTunnel()
+			fakeSSHTunnel.SetStartBehavior(nil, nil)
+			fakeSSHTunnelFactory.SSHTunnel = fakeSSHTunnel
+
+			readyErrCh := make(chan error, 1)
+			sshTunnelPool.NewSSHTunnel(bisshtunnel.Options{Host: "fake-host"}).Start(readyErrCh, make(chan error, 1))
+			Expect(<-readyErrCh).ToNot(HaveOccurred())
+
+			err := command.Run(fakeStage, defaultCreateEnvOpts)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeSSHTunnel.Stopped).To(BeTrue())
+		})
+
+		It("does not prune compiled packages in a shared compiled package cache", func() {
+			defaultCreateEnvOpts.PruneCompiled = true
+			defaultCreateEnvOpts.CompiledPackageCache = "/shared/cache"
+			expectDeploy.Times(0)
+
+			err := command.Run(fakeStage, defaultCreateEnvOpts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected --prune-compiled not to be given with --compiled-package-cache"))
+		})
+
+		It("does not prune compiled packages in a shared compiled package index", func() {
+			defaultCreateEnvOpts.PruneCompiled = true
+			defaultCreateEnvOpts.CompiledPackageIndex = "/shared/compiled_packages.json"
+			expectDeploy.Times(0)
+
+			err := command.Run(fakeStage, defaultCreateEnvOpts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected --prune-compiled not to be given with --compiled-package-index"))
+		})
+
 		It("deploys", func() {
 			expectDeploy.Times(1)
 
@@ -638,6 +1115,166 @@ var _ = Describe("CreateEnvCmd", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		Context("when resource pool cloud properties are overridden", func() {
+			BeforeEach(func() {
+				boshDeploymentManifest.Jobs[0].ResourcePool = "fake-resource-pool-name"
+				boshDeploymentManifest.ResourcePools[0].Name = "fake-resource-pool-name"
+				boshDeploymentManifest.ResourcePools[0].CloudProperties = biproperty.Map{
+					"instance_type": "m3.medium",
+					"zone":          "z1",
+				}
+				cloudPropertiesOverrides = []bicmd.CloudPropertiesOverrideArg{
+					{
+						ResourcePool:    "fake-resource-pool-name",
+						CloudProperties: biproperty.Map{"instance_type": "m4.xlarge"},
+					},
+				}
+			})
+
+			It("deploys with the merged cloud properties", func() {
+				overriddenManifest, err := boshDeploymentManifest.WithResourcePoolCloudProperties(
+					"fake-resource-pool-name", biproperty.Map{"instance_type": "m4.xlarge"})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(overriddenManifest.ResourcePools[0].CloudProperties).To(Equal(biproperty.Map{
+					"instance_type": "m4.xlarge",
+					"zone":          "z1",
+				}))
+
+				mockDeployer.EXPECT().Deploy(
+					cloud,
+					overriddenManifest,
+					cloudStemcell,
+					installationManifest.Registry,
+					fakeVMManager,
+					mockBlobstore,
+					gomock.Any(),
+				).Return(mock_deployment.NewMockDeployment(mockCtrl), nil).Times(1)
+
+				err = command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("records a manifest SHA that includes the overrides", func() {
+				mockDeployer.EXPECT().Deploy(
+					gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+				).Return(mock_deployment.NewMockDeployment(mockCtrl), nil).AnyTimes()
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).NotTo(HaveOccurred())
+
+				deploymentState, err := setupDeploymentStateService.Load()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deploymentState.CurrentManifestSHA).ToNot(BeEmpty())
+				Expect(deploymentState.CurrentManifestSHA).ToNot(Equal(manifestSHA))
+			})
+
+			It("returns an error when the overridden resource pool does not exist", func() {
+				cloudPropertiesOverrides[0].ResourcePool = "fake-other-resource-pool-name"
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Could not find resource pool 'fake-other-resource-pool-name'"))
+			})
+		})
+
+		It("prints a health summary of the deployed instance", func() {
+			err := command.Run(fakeStage, defaultCreateEnvOpts)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdOut).To(gbytes.Say(`fake-job-name/0\s+running\s+10\.0\.0\.5`))
+		})
+
+		It("does not fail the deploy when the agent cannot be reached for the health summary", func() {
+			expectGetState.Return(biagentclient.AgentState{}, errors.New("fake-get-state-err"))
+
+			err := command.Run(fakeStage, defaultCreateEnvOpts)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdOut).To(gbytes.Say("Warnings"))
+			Expect(stdOut).To(gbytes.Say(`deploy\s+Unable to determine instance health: agent is unreachable`))
+		})
+
+		It("does not print a warnings section when nothing was warned about", func() {
+			err := command.Run(fakeStage, defaultCreateEnvOpts)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdOut).ToNot(gbytes.Say("Warnings"))
+		})
+
+		It("prints warnings raised during validation at the end", func() {
+			warnings.Warn("deployment manifest", "fake-warning")
+
+			err := command.Run(fakeStage, defaultCreateEnvOpts)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdOut).To(gbytes.Say(`fake-job-name/0\s+running`))
+			Expect(stdOut).To(gbytes.Say("Warnings"))
+			Expect(stdOut).To(gbytes.Say(`deployment manifest\s+fake-warning`))
+		})
+
+		Context("when treating warnings as errors", func() {
+			BeforeEach(func() {
+				warningsAsErrors = true
+			})
+
+			It("fails before deploying when validation raised warnings", func() {
+				warnings.Warn("deployment manifest", "fake-warning")
+				expectDeploy.Times(0)
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Treating 1 warning(s) as errors"))
+
+				Expect(stdOut).To(gbytes.Say(`deployment manifest\s+fake-warning`))
+			})
+
+			It("fails after deploying when deploying raised warnings", func() {
+				expectGetState.Return(biagentclient.AgentState{}, errors.New("fake-get-state-err"))
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Treating 1 warning(s) as errors"))
+			})
+
+			It("succeeds when nothing was warned about", func() {
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		It("does not prune compiled packages by default", func() {
+			mockInstaller.EXPECT().PruneCompiledPackages(gomock.Any()).Times(0)
+
+			err := command.Run(fakeStage, defaultCreateEnvOpts)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when `prune-compiled` flag is specified", func() {
+			BeforeEach(func() {
+				defaultCreateEnvOpts.PruneCompiled = true
+			})
+
+			It("prunes compiled packages after deploying and reports reclaimed space", func() {
+				mockInstaller.EXPECT().PruneCompiledPackages(gomock.Any()).Return(int64(2000), nil)
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeStage.PerformCalls).To(ContainElement(&fakebiui.PerformCall{
+					Name: "Pruning compiled packages",
+				}))
+				Expect(stdOut).To(gbytes.Say("Reclaimed 2.0 kB from pruned compiled packages"))
+			})
+
+			It("returns an error when pruning fails", func() {
+				mockInstaller.EXPECT().PruneCompiledPackages(gomock.Any()).Return(int64(0), errors.New("fake-prune-err"))
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-prune-err"))
+			})
+		})
+
 		It("updates the deployment record", func() {
 			err := command.Run(fakeStage, defaultCreateEnvOpts)
 			Expect(err).NotTo(HaveOccurred())
@@ -1032,6 +1669,38 @@ var _ = Describe("CreateEnvCmd", func() {
 			})
 		})
 
+		Context("when validating job properties fails", func() {
+			BeforeEach(func() {
+				fakeDeploymentValidator.SetValidateJobPropertiesBehavior([]fakebideplval.ValidateJobPropertiesOutput{
+					{Err: bosherr.Error("fake-job-properties-validation-error")},
+				})
+			})
+
+			It("logs the failed event log", func() {
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).To(HaveOccurred())
+
+				performCall := fakeStage.PerformCalls[0].Stage.PerformCalls[2]
+				Expect(performCall.Name).To(Equal("Validating deployment manifest"))
+				Expect(performCall.Error.Error()).To(Equal("Validating deployment job properties: fake-job-properties-validation-error"))
+			})
+		})
+
+		Context("when jobs do not support the stemcell", func() {
+			BeforeEach(func() {
+				fakeDeploymentValidator.SetValidateStemcellCompatibilityBehavior([]fakebideplval.ValidateStemcellCompatibilityOutput{
+					{Err: bosherr.Error("fake-stemcell-compatibility-error")},
+				})
+			})
+
+			It("returns err before installing the CPI", func() {
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Validating deployment jobs support the stemcell: fake-stemcell-compatibility-error"))
+				Expect(fakeStage.PerformCalls).To(HaveLen(1))
+			})
+		})
+
 		Context("when uploading stemcell fails", func() {
 			JustBeforeEach(func() {
 				expectStemcellUpload.Return(nil, bosherr.Error("fake-upload-error"))
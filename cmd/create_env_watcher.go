@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+
+	bidepltpl "github.com/cloudfoundry/bosh-cli/deployment/template"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+)
+
+const (
+	createEnvWatchInterval = 1 * time.Second
+
+	// Editors often write a file in several steps, so files must stay the same
+	// for this long before create-env runs again
+	createEnvWatchDebounce = 500 * time.Millisecond
+)
+
+type CreateEnvWatcher struct {
+	ui               boshui.UI
+	fs               boshsys.FileSystem
+	timeService      clock.Clock
+	signalNotifyFunc func(chan<- os.Signal, ...os.Signal)
+	signalStopFunc   func(chan<- os.Signal)
+}
+
+func NewCreateEnvWatcher(
+	ui boshui.UI,
+	fs boshsys.FileSystem,
+	timeService clock.Clock,
+	signalNotifyFunc func(chan<- os.Signal, ...os.Signal),
+	signalStopFunc func(chan<- os.Signal),
+) CreateEnvWatcher {
+	return CreateEnvWatcher{
+		ui:               ui,
+		fs:               fs,
+		timeService:      timeService,
+		signalNotifyFunc: signalNotifyFunc,
+		signalStopFunc:   signalStopFunc,
+	}
+}
+
+// Run runs createEnv and then runs it again every time the manifest, the files it
+// includes, vars files or ops files change, until interrupted. Unchanged deployments
+// are skipped by create-env itself. Failures are reported and watching continues, so
+// that they can be fixed by editing the files. createEnv is given a context that is
+// canceled on interrupt, so that interrupting a running create-env also stops watching.
+func (w CreateEnvWatcher) Run(opts CreateEnvOpts, createEnv func(context.Context, CreateEnvOpts) error) error {
+	if opts.Recreate || opts.RecreatePersistentDisks || opts.PrintManifest {
+		return bosherr.Error("Watching for changes cannot be combined with --recreate, --recreate-persistent-disks or --print-manifest")
+	}
+
+	if opts.Args.Manifest.Stdin {
+		return bosherr.Error("Watching for changes requires a manifest file instead of stdin")
+	}
+
+	ctx, stopInterrupting := NewInterruptContext(w.ui, w.signalNotifyFunc, w.signalStopFunc)
+	defer stopInterrupting()
+
+	paths := w.watchedPaths(opts)
+	digests := w.digests(paths)
+
+	w.createEnv(ctx, opts, createEnv)
+
+	for ctx.Err() == nil {
+		w.ui.PrintLinef("\nWatching %s for changes (press Ctrl-C to stop)...", strings.Join(quotePaths(paths), ", "))
+
+		digests = w.waitForChanges(ctx, paths, digests)
+		if ctx.Err() != nil {
+			break
+		}
+
+		reloadedOpts, err := w.reload(opts)
+		if err != nil {
+			w.ui.ErrorLinef("Reloading changed files: %s", err.Error())
+			continue
+		}
+
+		opts = reloadedOpts
+
+		// Files may have been added to or removed from the includes
+		paths = w.watchedPaths(opts)
+		digests = w.digests(paths)
+
+		w.ui.PrintLinef("Detected changes, running create-env again\n")
+
+		w.createEnv(ctx, opts, createEnv)
+	}
+
+	w.ui.PrintLinef("Stopped watching")
+
+	return nil
+}
+
+func (w CreateEnvWatcher) createEnv(ctx context.Context, opts CreateEnvOpts, createEnv func(context.Context, CreateEnvOpts) error) {
+	err := createEnv(ctx, opts)
+	if err != nil {
+		w.ui.ErrorLinef("Creating environment failed: %s", err.Error())
+	}
+}
+
+// waitForChanges returns the digests of the files once they differ from the given
+// digests and have not changed for the debounce period, or when ctx is canceled
+func (w CreateEnvWatcher) waitForChanges(ctx context.Context, paths []string, digests map[string]string) map[string]string {
+	var changedDigests map[string]string
+
+	for {
+		interval := createEnvWatchInterval
+		if changedDigests != nil {
+			interval = createEnvWatchDebounce
+		}
+
+		select {
+		case <-ctx.Done():
+			return digests
+		case <-w.timeService.After(interval):
+		}
+
+		currentDigests := w.digests(paths)
+
+		if changedDigests == nil {
+			if !reflect.DeepEqual(currentDigests, digests) {
+				changedDigests = currentDigests
+			}
+			continue
+		}
+
+		if reflect.DeepEqual(currentDigests, changedDigests) {
+			return currentDigests
+		}
+
+		changedDigests = currentDigests
+	}
+}
+
+// watchedPaths does not include the vars store since create-env writes to it
+func (w CreateEnvWatcher) watchedPaths(opts CreateEnvOpts) []string {
+	paths := []string{opts.Args.Manifest.Path}
+
+	paths = append(paths, bidepltpl.IncludedPaths(w.fs, opts.Args.Manifest.Path, opts.Args.Manifest.Bytes)...)
+
+	for _, varsFile := range opts.VarsFiles {
+		if varsFile.Path != "" {
+			paths = append(paths, varsFile.Path)
+		}
+	}
+
+	for _, opsFile := range opts.OpsFiles {
+		if opsFile.Path != "" {
+			paths = append(paths, opsFile.Path)
+		}
+	}
+
+	return paths
+}
+
+// digests records unreadable files with an empty digest so that they are
+// considered changed once they can be read again
+func (w CreateEnvWatcher) digests(paths []string) map[string]string {
+	digests := map[string]string{}
+
+	for _, path := range paths {
+		bytes, err := w.fs.ReadFile(path)
+		if err != nil {
+			digests[path] = ""
+			continue
+		}
+
+		digests[path] = fmt.Sprintf("%x", sha1.Sum(bytes))
+	}
+
+	return digests
+}
+
+func (w CreateEnvWatcher) reload(opts CreateEnvOpts) (CreateEnvOpts, error) {
+	manifest := EnvManifestArg{FS: opts.Args.Manifest.FS}
+
+	err := manifest.UnmarshalFlag(opts.Args.Manifest.Path)
+	if err != nil {
+		return opts, bosherr.WrapErrorf(err, "Reading manifest '%s'", opts.Args.Manifest.Path)
+	}
+
+	varsFiles := []boshtpl.VarsFileArg{}
+
+	for _, varsFile := range opts.VarsFiles {
+		reloaded := boshtpl.VarsFileArg{FS: varsFile.FS}
+
+		err := reloaded.UnmarshalFlag(varsFile.Path)
+		if err != nil {
+			return opts, err
+		}
+
+		varsFiles = append(varsFiles, reloaded)
+	}
+
+	opsFiles := []OpsFileArg{}
+
+	for _, opsFile := range opts.OpsFiles {
+		reloaded := OpsFileArg{FS: opsFile.FS}
+
+		err := reloaded.UnmarshalFlag(opsFile.Path)
+		if err != nil {
+			return opts, err
+		}
+
+		opsFiles = append(opsFiles, reloaded)
+	}
+
+	opts.Args.Manifest = manifest
+	opts.VarsFiles = varsFiles
+	opts.OpsFiles = opsFiles
+
+	return opts, nil
+}
+
+func quotePaths(paths []string) []string {
+	var quoted []string
+
+	for _, path := range paths {
+		quoted = append(quoted, fmt.Sprintf("'%s'", path))
+	}
+
+	return quoted
+}
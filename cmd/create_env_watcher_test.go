@@ -0,0 +1,281 @@
+package cmd_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+)
+
+var _ = Describe("CreateEnvWatcher", func() {
+	var (
+		ui          *fakeui.FakeUI
+		fs          *fakesys.FakeFileSystem
+		timeService *fakeclock.FakeClock
+		signalChs   chan chan<- os.Signal
+		stoppedChs  chan chan<- os.Signal
+		watcher     CreateEnvWatcher
+
+		opts CreateEnvOpts
+
+		createEnvLock     sync.Mutex
+		createEnvRuns     []CreateEnvOpts
+		createEnvErr      error
+		createEnvBlocking bool
+		createEnvCtxs     chan context.Context
+
+		signalCh chan<- os.Signal
+	)
+
+	createEnv := func(ctx context.Context, opts CreateEnvOpts) error {
+		createEnvLock.Lock()
+		createEnvRuns = append(createEnvRuns, opts)
+		blocking := createEnvBlocking
+		createEnvLock.Unlock()
+
+		createEnvCtxs <- ctx
+
+		if blocking {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+
+		return createEnvErr
+	}
+
+	runs := func() []CreateEnvOpts {
+		createEnvLock.Lock()
+		defer createEnvLock.Unlock()
+
+		return append([]CreateEnvOpts{}, createEnvRuns...)
+	}
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		fs = fakesys.NewFakeFileSystem()
+		timeService = fakeclock.NewFakeClock(time.Now())
+		signalChs = make(chan chan<- os.Signal, 10)
+		stoppedChs = make(chan chan<- os.Signal, 10)
+
+		signalNotify := func(ch chan<- os.Signal, _ ...os.Signal) { signalChs <- ch }
+		signalStop := func(ch chan<- os.Signal) { stoppedChs <- ch }
+
+		watcher = NewCreateEnvWatcher(ui, fs, timeService, signalNotify, signalStop)
+
+		createEnvRuns = nil
+		createEnvErr = nil
+		createEnvBlocking = false
+		createEnvCtxs = make(chan context.Context, 10)
+
+		fs.WriteFileString("/manifest.yml", "name: original")
+		fs.WriteFileString("/vars.yml", "key: original")
+		fs.WriteFileString("/ops.yml", "[]")
+
+		manifestArg := EnvManifestArg{FS: fs}
+		Expect(manifestArg.UnmarshalFlag("/manifest.yml")).To(Succeed())
+
+		varsFileArg := boshtpl.VarsFileArg{FS: fs}
+		Expect(varsFileArg.UnmarshalFlag("/vars.yml")).To(Succeed())
+
+		opsFileArg := OpsFileArg{FS: fs}
+		Expect(opsFileArg.UnmarshalFlag("/ops.yml")).To(Succeed())
+
+		opts = CreateEnvOpts{
+			Args:     CreateEnvArgs{Manifest: manifestArg},
+			VarFlags: VarFlags{VarsFiles: []boshtpl.VarsFileArg{varsFileArg}},
+			OpsFlags: OpsFlags{OpsFiles: []OpsFileArg{opsFileArg}},
+			Watch:    true,
+		}
+	})
+
+	start := func() chan error {
+		errCh := make(chan error, 1)
+
+		go func() {
+			defer GinkgoRecover()
+			errCh <- watcher.Run(opts, createEnv)
+		}()
+
+		Eventually(signalChs).Should(Receive(&signalCh))
+		Eventually(createEnvCtxs).Should(Receive())
+
+		return errCh
+	}
+
+	// tick waits until the files have been checked again after the time passed
+	tick := func(duration time.Duration) {
+		timeService.WaitForWatcherAndIncrement(duration)
+		Eventually(timeService.WatcherCount).Should(Equal(1))
+	}
+
+	interrupt := func(errCh chan error) {
+		signalCh <- os.Interrupt
+
+		Eventually(errCh).Should(Receive(BeNil()))
+	}
+
+	It("runs create-env, then stops watching when interrupted", func() {
+		errCh := start()
+
+		interrupt(errCh)
+
+		Expect(runs()).To(HaveLen(1))
+		Expect(ui.Said).To(ContainElement("\nWatching '/manifest.yml', '/vars.yml', '/ops.yml' for changes (press Ctrl-C to stop)..."))
+		Expect(ui.Said).To(ContainElement("Stopped watching"))
+		Expect(stoppedChs).To(Receive())
+	})
+
+	It("runs create-env again with the reloaded files once they stop changing", func() {
+		errCh := start()
+
+		fs.WriteFileString("/manifest.yml", "name: first-edit")
+		tick(time.Second)
+
+		fs.WriteFileString("/manifest.yml", "name: second-edit")
+		fs.WriteFileString("/vars.yml", "key: changed")
+		tick(500 * time.Millisecond)
+
+		Consistently(runs).Should(HaveLen(1))
+
+		tick(500 * time.Millisecond)
+
+		Eventually(runs).Should(HaveLen(2))
+
+		reloadedOpts := runs()[1]
+		Expect(string(reloadedOpts.Args.Manifest.Bytes)).To(Equal("name: second-edit"))
+		Expect(reloadedOpts.VarsFiles[0].Vars).To(Equal(boshtpl.StaticVariables{"key": "changed"}))
+		Expect(reloadedOpts.OpsFiles[0].Path).To(Equal("/ops.yml"))
+		Expect(ui.Said).To(ContainElement("Detected changes, running create-env again\n"))
+
+		interrupt(errCh)
+
+		Expect(runs()).To(HaveLen(2))
+	})
+
+	It("does not run create-env again when files do not change", func() {
+		errCh := start()
+
+		tick(time.Second)
+		tick(time.Second)
+
+		Consistently(runs).Should(HaveLen(1))
+		Expect(stoppedChs).ToNot(Receive())
+
+		fs.WriteFileString("/manifest.yml", "name: changed")
+		tick(time.Second)
+		tick(500 * time.Millisecond)
+
+		Eventually(runs).Should(HaveLen(2))
+
+		interrupt(errCh)
+	})
+
+	It("keeps watching when create-env fails", func() {
+		createEnvErr = errors.New("fake-create-env-err")
+
+		errCh := start()
+
+		Expect(ui.Errors).To(ContainElement("Creating environment failed: fake-create-env-err"))
+
+		fs.WriteFileString("/manifest.yml", "name: fixed")
+		tick(time.Second)
+		tick(500 * time.Millisecond)
+
+		Eventually(runs).Should(HaveLen(2))
+
+		interrupt(errCh)
+	})
+
+	It("reports files that cannot be reloaded and keeps watching", func() {
+		errCh := start()
+
+		fs.WriteFileString("/ops.yml", "- type: unknown")
+		tick(time.Second)
+		tick(500 * time.Millisecond)
+
+		Eventually(func() []string { return ui.Errors }).Should(ContainElement(ContainSubstring("Reloading changed files")))
+		Expect(runs()).To(HaveLen(1))
+
+		interrupt(errCh)
+	})
+
+	It("interrupts a running create-env and stops watching", func() {
+		createEnvBlocking = true
+
+		errCh := make(chan error, 1)
+
+		go func() {
+			defer GinkgoRecover()
+			errCh <- watcher.Run(opts, createEnv)
+		}()
+
+		var ctx context.Context
+		Eventually(createEnvCtxs).Should(Receive(&ctx))
+		Expect(ctx.Err()).ToNot(HaveOccurred())
+
+		Eventually(signalChs).Should(Receive(&signalCh))
+		interrupt(errCh)
+
+		Expect(ctx.Err()).To(HaveOccurred())
+		Expect(runs()).To(HaveLen(1))
+		Expect(ui.Errors).To(ContainElement("Creating environment failed: context canceled"))
+		Expect(ui.Said).To(ContainElement("Stopped watching"))
+	})
+
+	It("watches files included by the manifest", func() {
+		fs.WriteFileString("/manifest.yml", "include: [included.yml]\nname: original")
+		fs.WriteFileString("/included.yml", "releases: []")
+		Expect(opts.Args.Manifest.UnmarshalFlag("/manifest.yml")).To(Succeed())
+
+		errCh := start()
+
+		Eventually(func() []string { return ui.Said }).Should(ContainElement(
+			"\nWatching '/manifest.yml', '/included.yml', '/vars.yml', '/ops.yml' for changes (press Ctrl-C to stop)..."))
+
+		fs.WriteFileString("/included.yml", "include: [nested.yml]\nreleases: []")
+		tick(time.Second)
+		tick(500 * time.Millisecond)
+
+		Eventually(runs).Should(HaveLen(2))
+		Eventually(func() []string { return ui.Said }).Should(ContainElement(
+			"\nWatching '/manifest.yml', '/included.yml', '/nested.yml', '/vars.yml', '/ops.yml' for changes (press Ctrl-C to stop)..."))
+
+		fs.WriteFileString("/nested.yml", "releases: []")
+		tick(time.Second)
+		tick(500 * time.Millisecond)
+
+		Eventually(runs).Should(HaveLen(3))
+
+		interrupt(errCh)
+	})
+
+	It("returns an error when combined with recreating", func() {
+		opts.Recreate = true
+
+		err := watcher.Run(opts, createEnv)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("cannot be combined with --recreate"))
+
+		Expect(runs()).To(BeEmpty())
+	})
+
+	It("returns an error when the manifest was read from stdin", func() {
+		opts.Args.Manifest = EnvManifestArg{FS: fs, Bytes: []byte("name: original"), Stdin: true}
+
+		err := watcher.Run(opts, createEnv)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Watching for changes requires a manifest file instead of stdin"))
+
+		Expect(runs()).To(BeEmpty())
+	})
+})
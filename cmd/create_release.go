@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
@@ -8,6 +10,7 @@ import (
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
 	semver "github.com/cppforlife/go-semi-semantic/version"
 
+	bicrypto "github.com/cloudfoundry/bosh-cli/crypto"
 	boshrel "github.com/cloudfoundry/bosh-cli/release"
 	boshreldir "github.com/cloudfoundry/bosh-cli/releasedir"
 	boshui "github.com/cloudfoundry/bosh-cli/ui"
@@ -16,6 +19,7 @@ import (
 type CreateReleaseCmd struct {
 	releaseDirFactory func(DirOrCWDArg) (boshrel.Reader, boshreldir.ReleaseDir)
 	releaseWriter     boshrel.Writer
+	sha1Calculator    bicrypto.DigestCalculator
 	fs                boshsys.FileSystem
 	ui                boshui.UI
 }
@@ -23,13 +27,18 @@ type CreateReleaseCmd struct {
 func NewCreateReleaseCmd(
 	releaseDirFactory func(DirOrCWDArg) (boshrel.Reader, boshreldir.ReleaseDir),
 	releaseWriter boshrel.Writer,
+	sha1Calculator bicrypto.DigestCalculator,
 	fs boshsys.FileSystem,
 	ui boshui.UI,
 ) CreateReleaseCmd {
-	return CreateReleaseCmd{releaseDirFactory, releaseWriter, fs, ui}
+	return CreateReleaseCmd{releaseDirFactory, releaseWriter, sha1Calculator, fs, ui}
 }
 
 func (c CreateReleaseCmd) Run(opts CreateReleaseOpts) (boshrel.Release, error) {
+	if len(opts.OutputDir.Path) > 0 && len(opts.Tarball.ExpandedPath) > 0 {
+		return nil, bosherr.Error("Expected only one of --tarball or --output-dir to be given")
+	}
+
 	releaseManifestReader, releaseDir := c.releaseDirFactory(opts.Directory)
 	manifestGiven := len(opts.Args.Manifest.Path) > 0
 
@@ -55,8 +64,13 @@ func (c CreateReleaseCmd) Run(opts CreateReleaseOpts) (boshrel.Release, error) {
 		}
 	}
 
+	tables := ReleaseTables{Release: release}
 	dstPath := opts.Tarball.ExpandedPath
 
+	if len(opts.OutputDir.Path) > 0 {
+		dstPath = filepath.Join(opts.OutputDir.Path, "((name))-((version)).tgz")
+	}
+
 	if dstPath != "" {
 		path, err := c.releaseWriter.Write(release, nil)
 		if err != nil {
@@ -66,13 +80,31 @@ func (c CreateReleaseCmd) Run(opts CreateReleaseOpts) (boshrel.Release, error) {
 		dstPath = strings.Replace(dstPath, "((name))", release.Name(), -1)
 		dstPath = strings.Replace(dstPath, "((version))", release.Version(), -1)
 
+		err = c.fs.MkdirAll(filepath.Dir(dstPath), os.ModePerm)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Creating release archive directory '%s'", filepath.Dir(dstPath))
+		}
+
 		err = boshfu.NewFileMover(c.fs).Move(path, dstPath)
 		if err != nil {
 			return nil, bosherr.WrapErrorf(err, "Moving release archive to final destination")
 		}
+
+		tables.ArchiveSHA1, err = c.sha1Calculator.Calculate(dstPath)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Calculating SHA1 of release archive '%s'", dstPath)
+		}
+
+		archiveInfo, err := c.fs.Stat(dstPath)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Checking size of release archive '%s'", dstPath)
+		}
+
+		tables.ArchiveSize = uint64(archiveInfo.Size())
 	}
 
-	ReleaseTables{Release: release, ArchivePath: dstPath}.Print(c.ui)
+	tables.ArchivePath = dstPath
+	tables.Print(c.ui)
 
 	return release, nil
 }
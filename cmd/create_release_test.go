@@ -8,12 +8,14 @@ import (
 	. "github.com/onsi/gomega"
 
 	. "github.com/cloudfoundry/bosh-cli/cmd"
+	"github.com/cloudfoundry/bosh-cli/crypto"
 	boshrel "github.com/cloudfoundry/bosh-cli/release"
 	fakerel "github.com/cloudfoundry/bosh-cli/release/releasefakes"
 	boshreldir "github.com/cloudfoundry/bosh-cli/releasedir"
 	fakereldir "github.com/cloudfoundry/bosh-cli/releasedir/releasedirfakes"
 	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
 	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
 	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 )
 
@@ -39,7 +41,8 @@ var _ = Describe("CreateReleaseCmd", func() {
 		fakeWriter = &fakerel.FakeWriter{}
 		fakeFS = fakesys.NewFakeFileSystem()
 		ui = &fakeui.FakeUI{}
-		command = NewCreateReleaseCmd(releaseDirFactory, fakeWriter, fakeFS, ui)
+		sha1Calculator := crypto.NewDigestCalculator(fakeFS, []boshcrypto.Algorithm{boshcrypto.DigestAlgorithmSHA1})
+		command = NewCreateReleaseCmd(releaseDirFactory, fakeWriter, sha1Calculator, fakeFS, ui)
 	})
 
 	Describe("Run", func() {
@@ -130,6 +133,8 @@ var _ = Describe("CreateReleaseCmd", func() {
 							boshtbl.NewHeader("Version"),
 							boshtbl.NewHeader("Commit Hash"),
 							boshtbl.NewHeader("Archive"),
+							boshtbl.NewHeader("SHA1"),
+							boshtbl.NewHeader("Size"),
 						},
 
 						Rows: [][]boshtbl.Value{
@@ -138,6 +143,8 @@ var _ = Describe("CreateReleaseCmd", func() {
 								boshtbl.NewValueString("ver"),
 								boshtbl.NewValueString("commit"),
 								boshtbl.NewValueString("/tarball-destination.tgz"),
+								boshtbl.NewValueString("f1df14fca919f32bdf630f52bfbeae46b66bf41c"),
+								boshtbl.NewValueBytes(20),
 							},
 						},
 						Transpose: true,
@@ -169,6 +176,8 @@ var _ = Describe("CreateReleaseCmd", func() {
 							boshtbl.NewHeader("Version"),
 							boshtbl.NewHeader("Commit Hash"),
 							boshtbl.NewHeader("Archive"),
+							boshtbl.NewHeader("SHA1"),
+							boshtbl.NewHeader("Size"),
 						},
 
 						Rows: [][]boshtbl.Value{
@@ -177,6 +186,8 @@ var _ = Describe("CreateReleaseCmd", func() {
 								boshtbl.NewValueString("ver"),
 								boshtbl.NewValueString("commit"),
 								boshtbl.NewValueString("/tarball-destination-rel-ver.tgz"),
+								boshtbl.NewValueString("f1df14fca919f32bdf630f52bfbeae46b66bf41c"),
+								boshtbl.NewValueBytes(20),
 							},
 						},
 						Transpose: true,
@@ -202,6 +213,37 @@ var _ = Describe("CreateReleaseCmd", func() {
 					Expect(err.Error()).To(ContainSubstring("fake-err"))
 				})
 
+				It("creates the parent directory of the archive destination", func() {
+					opts.Tarball = FileArg{ExpandedPath: "/staging/releases/rel.tgz"}
+
+					fakeWriter.WriteStub = func(rel boshrel.Release, skipPkgs []string) (string, error) {
+						fakeFS.WriteFileString("/temp-tarball.tgz", "release content blah")
+						return "/temp-tarball.tgz", nil
+					}
+
+					err := act()
+					Expect(err).ToNot(HaveOccurred())
+
+					content, err := fakeFS.ReadFileString("/staging/releases/rel.tgz")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(content).To(Equal("release content blah"))
+				})
+
+				It("returns error if creating the parent directory fails", func() {
+					opts.Tarball = FileArg{ExpandedPath: "/staging/rel.tgz"}
+
+					fakeWriter.WriteStub = func(rel boshrel.Release, skipPkgs []string) (string, error) {
+						fakeFS.WriteFileString("/temp-tarball.tgz", "release content blah")
+						return "/temp-tarball.tgz", nil
+					}
+
+					fakeFS.MkdirAllError = errors.New("fake-err")
+
+					err := act()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("Creating release archive directory '/staging': fake-err"))
+				})
+
 				It("returns error moving the archive fails", func() {
 					fakeWriter.WriteStub = func(rel boshrel.Release, skipPkgs []string) (string, error) {
 						fakeFS.WriteFileString("/temp-tarball.tgz", "release content blah")
@@ -215,6 +257,41 @@ var _ = Describe("CreateReleaseCmd", func() {
 					Expect(err.Error()).To(ContainSubstring("fake-err"))
 				})
 			})
+
+			Context("with output directory", func() {
+				BeforeEach(func() {
+					opts.OutputDir = DirOrCWDArg{Path: "/staging/releases"}
+
+					fakeWriter.WriteStub = func(rel boshrel.Release, skipPkgs []string) (string, error) {
+						fakeFS.WriteFileString("/temp-tarball.tgz", "release content blah")
+						return "/temp-tarball.tgz", nil
+					}
+				})
+
+				It("creates the directory and names the archive after the release", func() {
+					err := act()
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(ui.Tables[0].Rows[0][3]).To(Equal(boshtbl.NewValueString("/staging/releases/rel-ver.tgz")))
+
+					Expect(fakeFS.FileExists("/temp-tarball.tgz")).To(BeFalse())
+
+					content, err := fakeFS.ReadFileString("/staging/releases/rel-ver.tgz")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(content).To(Equal("release content blah"))
+				})
+
+				It("returns error without building the release if tarball is also given", func() {
+					opts.Tarball = FileArg{ExpandedPath: "/tarball-destination.tgz"}
+
+					err := act()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("Expected only one of --tarball or --output-dir to be given"))
+
+					Expect(releaseReader.ReadCallCount()).To(Equal(0))
+					Expect(fakeWriter.WriteCallCount()).To(Equal(0))
+				})
+			})
 		})
 
 		Context("when manifest path is not provided", func() {
@@ -437,6 +514,8 @@ var _ = Describe("CreateReleaseCmd", func() {
 						boshtbl.NewHeader("Version"),
 						boshtbl.NewHeader("Commit Hash"),
 						boshtbl.NewHeader("Archive"),
+						boshtbl.NewHeader("SHA1"),
+						boshtbl.NewHeader("Size"),
 					},
 					Rows: [][]boshtbl.Value{
 						{
@@ -444,6 +523,8 @@ var _ = Describe("CreateReleaseCmd", func() {
 							boshtbl.NewValueString("next-final+ver"),
 							boshtbl.NewValueString("commit"),
 							boshtbl.NewValueString("/archive-path"),
+							boshtbl.NewValueString("f1df14fca919f32bdf630f52bfbeae46b66bf41c"),
+							boshtbl.NewValueBytes(20),
 						},
 					},
 					Transpose: true,
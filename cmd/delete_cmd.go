@@ -1,26 +1,88 @@
 package cmd
 
 import (
+	"fmt"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	"github.com/cppforlife/go-patch/patch"
 
 	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
 	boshui "github.com/cloudfoundry/bosh-cli/ui"
 )
 
+// DestroyConfirmation is asked to approve deleting an environment; production
+// is true when the environment is marked as production in its state file.
+type DestroyConfirmation func(deploymentName string, production bool) error
+
 type DeleteCmd struct {
 	ui          boshui.UI
-	envProvider func(string, string, boshtpl.Variables, patch.Op) DeploymentDeleter
+	envProvider func(string, string, boshtpl.Variables, patch.Op, DestroyConfirmation) DeploymentDeleter
 }
 
-func NewDeleteCmd(ui boshui.UI, envProvider func(string, string, boshtpl.Variables, patch.Op) DeploymentDeleter) *DeleteCmd {
+func NewDeleteCmd(ui boshui.UI, envProvider func(string, string, boshtpl.Variables, patch.Op, DestroyConfirmation) DeploymentDeleter) *DeleteCmd {
 	return &DeleteCmd{ui: ui, envProvider: envProvider}
 }
 
 func (c *DeleteCmd) Run(stage boshui.Stage, opts DeleteEnvOpts) error {
-	c.ui.BeginLinef("Deployment manifest: '%s'\n", opts.Args.Manifest.Path)
+	if opts.Args.Manifest.Stdin && len(opts.StatePath) == 0 {
+		return bosherr.Error("Expected --state to be given when reading the manifest from stdin")
+	}
+
+	removeManifest, err := opts.Args.Manifest.WriteTempFile()
+	if err != nil {
+		return err
+	}
+
+	defer removeManifest()
+
+	c.ui.BeginLinef("Deployment manifest: '%s'\n", opts.Args.Manifest.Name())
 
 	depDeleter := c.envProvider(
-		opts.Args.Manifest.Path, opts.StatePath, opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp())
+		opts.Args.Manifest.Path, opts.Args.Manifest.StatePath(opts.StatePath), opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp(), c.confirmDestroy(opts))
+
+	return depDeleter.DeleteDeployment(stage, opts.Force)
+}
+
+// confirmDestroy requires the deployment name to be typed (or given via --confirm-destroy)
+// unless --yes was passed explicitly. Without a terminal to ask, only environments
+// marked as production need the name so that scripts using --non-interactive keep working.
+func (c *DeleteCmd) confirmDestroy(opts DeleteEnvOpts) DestroyConfirmation {
+	return func(deploymentName string, production bool) error {
+		if opts.Yes {
+			if production {
+				c.ui.BeginLinef("Deleting production environment '%s' without confirmation (--yes)\n", deploymentName)
+			}
+			return nil
+		}
+
+		confirmation := opts.ConfirmDestroy
+
+		if len(confirmation) == 0 {
+			if !c.ui.IsInteractive() {
+				if !production {
+					return nil
+				}
+
+				return bosherr.Errorf("Environment '%s' is marked as production; pass '--confirm-destroy=%s' or '--yes' to delete it", deploymentName, deploymentName)
+			}
+
+			label := fmt.Sprintf("Type the name of environment '%s' to confirm deleting its VM, disks and stemcells", deploymentName)
+			if production {
+				label = fmt.Sprintf("Environment '%s' is marked as production. Type its name to confirm deletion", deploymentName)
+			}
+
+			var err error
+
+			confirmation, err = c.ui.AskForText(label)
+			if err != nil {
+				return err
+			}
+		}
+
+		if confirmation != deploymentName {
+			return bosherr.Errorf("Confirmation '%s' does not match environment name '%s'", confirmation, deploymentName)
+		}
 
-	return depDeleter.DeleteDeployment(stage)
+		return nil
+	}
 }
@@ -36,14 +36,16 @@ var _ = Describe("DeleteCmd", func() {
 			fakeStage              *fakebiui.FakeStage
 			deploymentManifestPath = "/deployment-dir/fake-deployment-manifest.yml"
 			statePath              string
+			confirmDestroy         bicmd.DestroyConfirmation
 		)
 
 		var newDeleteCmd = func() *bicmd.DeleteCmd {
-			doGetFunc := func(manifestPath string, statePath_ string, vars boshtpl.Variables, op patch.Op) bicmd.DeploymentDeleter {
+			doGetFunc := func(manifestPath string, statePath_ string, vars boshtpl.Variables, op patch.Op, confirmDestroy_ bicmd.DestroyConfirmation) bicmd.DeploymentDeleter {
 				Expect(manifestPath).To(Equal(deploymentManifestPath))
 				Expect(vars).To(Equal(boshtpl.NewMultiVars([]boshtpl.Variables{boshtpl.StaticVariables{"key": "value"}})))
 				Expect(op).To(Equal(patch.Ops{patch.ErrOp{}}))
 				statePath = statePath_
+				confirmDestroy = confirmDestroy_
 				return mockDeploymentDeleter
 			}
 
@@ -64,10 +66,10 @@ var _ = Describe("DeleteCmd", func() {
 
 		Context("state path is NOT specified", func() {
 			It("sends the manifest on to the deleter", func() {
-				mockDeploymentDeleter.EXPECT().DeleteDeployment(fakeStage).Return(nil)
+				mockDeploymentDeleter.EXPECT().DeleteDeployment(fakeStage, false).Return(nil)
 				newDeleteCmd().Run(fakeStage, bicmd.DeleteEnvOpts{
 					Args: bicmd.DeleteEnvArgs{
-						Manifest: bicmd.FileBytesWithPathArg{Path: deploymentManifestPath},
+						Manifest: bicmd.EnvManifestArg{Path: deploymentManifestPath},
 					},
 					VarFlags: bicmd.VarFlags{
 						VarKVs: []boshtpl.VarKV{{Name: "key", Value: "value"}},
@@ -83,13 +85,25 @@ var _ = Describe("DeleteCmd", func() {
 			})
 		})
 
+		Context("manifest is read from stdin", func() {
+			It("requires the state file path", func() {
+				err := newDeleteCmd().Run(fakeStage, bicmd.DeleteEnvOpts{
+					Args: bicmd.DeleteEnvArgs{
+						Manifest: bicmd.EnvManifestArg{FS: fs, Stdin: true},
+					},
+				})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Expected --state to be given when reading the manifest from stdin"))
+			})
+		})
+
 		Context("state path is specified", func() {
 			It("sends the manifest on to the deleter", func() {
-				mockDeploymentDeleter.EXPECT().DeleteDeployment(fakeStage).Return(nil)
+				mockDeploymentDeleter.EXPECT().DeleteDeployment(fakeStage, false).Return(nil)
 				newDeleteCmd().Run(fakeStage, bicmd.DeleteEnvOpts{
 					StatePath: "/new/state/file/path/state.json",
 					Args: bicmd.DeleteEnvArgs{
-						Manifest: bicmd.FileBytesWithPathArg{Path: deploymentManifestPath},
+						Manifest: bicmd.EnvManifestArg{Path: deploymentManifestPath},
 					},
 					VarFlags: bicmd.VarFlags{
 						VarKVs: []boshtpl.VarKV{{Name: "key", Value: "value"}},
@@ -105,13 +119,34 @@ var _ = Describe("DeleteCmd", func() {
 			})
 		})
 
+		Context("when forced", func() {
+			It("tells the deleter to continue past failures", func() {
+				mockDeploymentDeleter.EXPECT().DeleteDeployment(fakeStage, true).Return(nil)
+				err := newDeleteCmd().Run(fakeStage, bicmd.DeleteEnvOpts{
+					Args: bicmd.DeleteEnvArgs{
+						Manifest: bicmd.EnvManifestArg{Path: deploymentManifestPath},
+					},
+					VarFlags: bicmd.VarFlags{
+						VarKVs: []boshtpl.VarKV{{Name: "key", Value: "value"}},
+					},
+					OpsFlags: bicmd.OpsFlags{
+						OpsFiles: []bicmd.OpsFileArg{
+							{Ops: patch.Ops([]patch.Op{patch.ErrOp{}})},
+						},
+					},
+					Force: true,
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
 		Context("when the deployment deleter returns an error", func() {
 			It("sends the manifest on to the deleter", func() {
 				err := bosherr.Error("boom")
-				mockDeploymentDeleter.EXPECT().DeleteDeployment(fakeStage).Return(err)
+				mockDeploymentDeleter.EXPECT().DeleteDeployment(fakeStage, false).Return(err)
 				returnedErr := newDeleteCmd().Run(fakeStage, bicmd.DeleteEnvOpts{
 					Args: bicmd.DeleteEnvArgs{
-						Manifest: bicmd.FileBytesWithPathArg{Path: deploymentManifestPath},
+						Manifest: bicmd.EnvManifestArg{Path: deploymentManifestPath},
 					},
 					VarFlags: bicmd.VarFlags{
 						VarKVs: []boshtpl.VarKV{{Name: "key", Value: "value"}},
@@ -125,5 +160,157 @@ var _ = Describe("DeleteCmd", func() {
 				Expect(returnedErr).To(Equal(err))
 			})
 		})
+
+		Describe("confirming deletion of a production environment", func() {
+			var opts bicmd.DeleteEnvOpts
+
+			BeforeEach(func() {
+				opts = bicmd.DeleteEnvOpts{
+					Args: bicmd.DeleteEnvArgs{
+						Manifest: bicmd.EnvManifestArg{Path: deploymentManifestPath},
+					},
+					VarFlags: bicmd.VarFlags{
+						VarKVs: []boshtpl.VarKV{{Name: "key", Value: "value"}},
+					},
+					OpsFlags: bicmd.OpsFlags{
+						OpsFiles: []bicmd.OpsFileArg{
+							{Ops: patch.Ops([]patch.Op{patch.ErrOp{}})},
+						},
+					},
+				}
+
+				mockDeploymentDeleter.EXPECT().DeleteDeployment(fakeStage, false).Return(nil)
+			})
+
+			act := func() {
+				err := newDeleteCmd().Run(fakeStage, opts)
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			It("accepts the typed deployment name", func() {
+				fakeUI.Interactive = true
+				fakeUI.AskedText = []fakeui.Answer{{Text: "fake-env"}}
+				act()
+
+				Expect(confirmDestroy("fake-env", true)).To(Succeed())
+				Expect(fakeUI.AskedTextLabels).To(Equal([]string{
+					"Environment 'fake-env' is marked as production. Type its name to confirm deletion",
+				}))
+			})
+
+			It("rejects a typed name that does not match", func() {
+				fakeUI.Interactive = true
+				fakeUI.AskedText = []fakeui.Answer{{Text: "other-env"}}
+				act()
+
+				err := confirmDestroy("fake-env", true)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Confirmation 'other-env' does not match environment name 'fake-env'"))
+			})
+
+			It("accepts the name given via --confirm-destroy without asking", func() {
+				opts.ConfirmDestroy = "fake-env"
+				act()
+
+				Expect(confirmDestroy("fake-env", true)).To(Succeed())
+				Expect(fakeUI.AskedTextLabels).To(BeEmpty())
+			})
+
+			It("rejects a --confirm-destroy name that does not match", func() {
+				opts.ConfirmDestroy = "other-env"
+				act()
+
+				Expect(confirmDestroy("fake-env", true)).ToNot(Succeed())
+			})
+
+			It("requires --confirm-destroy when not interactive", func() {
+				fakeUI.Interactive = false
+				act()
+
+				err := confirmDestroy("fake-env", true)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Environment 'fake-env' is marked as production; pass '--confirm-destroy=fake-env' or '--yes' to delete it"))
+			})
+
+			It("skips confirmation with --yes", func() {
+				opts.Yes = true
+				act()
+
+				Expect(confirmDestroy("fake-env", true)).To(Succeed())
+				Expect(fakeUI.AskedTextLabels).To(BeEmpty())
+			})
+		})
+
+		Describe("confirming deletion of an environment not marked as production", func() {
+			var opts bicmd.DeleteEnvOpts
+
+			BeforeEach(func() {
+				opts = bicmd.DeleteEnvOpts{
+					Args: bicmd.DeleteEnvArgs{
+						Manifest: bicmd.EnvManifestArg{Path: deploymentManifestPath},
+					},
+					VarFlags: bicmd.VarFlags{
+						VarKVs: []boshtpl.VarKV{{Name: "key", Value: "value"}},
+					},
+					OpsFlags: bicmd.OpsFlags{
+						OpsFiles: []bicmd.OpsFileArg{
+							{Ops: patch.Ops([]patch.Op{patch.ErrOp{}})},
+						},
+					},
+				}
+
+				mockDeploymentDeleter.EXPECT().DeleteDeployment(fakeStage, false).Return(nil)
+			})
+
+			act := func() {
+				err := newDeleteCmd().Run(fakeStage, opts)
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			It("asks for the deployment name when interactive", func() {
+				fakeUI.Interactive = true
+				fakeUI.AskedText = []fakeui.Answer{{Text: "fake-env"}}
+				act()
+
+				Expect(confirmDestroy("fake-env", false)).To(Succeed())
+				Expect(fakeUI.AskedTextLabels).To(Equal([]string{
+					"Type the name of environment 'fake-env' to confirm deleting its VM, disks and stemcells",
+				}))
+			})
+
+			It("rejects a typed name that does not match, such as 'y'", func() {
+				fakeUI.Interactive = true
+				fakeUI.AskedText = []fakeui.Answer{{Text: "y"}}
+				act()
+
+				err := confirmDestroy("fake-env", false)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Confirmation 'y' does not match environment name 'fake-env'"))
+			})
+
+			It("does not ask when not interactive", func() {
+				fakeUI.Interactive = false
+				act()
+
+				Expect(confirmDestroy("fake-env", false)).To(Succeed())
+				Expect(fakeUI.AskedTextLabels).To(BeEmpty())
+			})
+
+			It("skips confirmation with --yes", func() {
+				fakeUI.Interactive = true
+				opts.Yes = true
+				act()
+
+				Expect(confirmDestroy("fake-env", false)).To(Succeed())
+				Expect(fakeUI.AskedTextLabels).To(BeEmpty())
+			})
+
+			It("rejects a --confirm-destroy name that does not match", func() {
+				opts.ConfirmDestroy = "other-env"
+				act()
+
+				Expect(confirmDestroy("fake-env", false)).ToNot(Succeed())
+			})
+		})
 	})
 })
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/cppforlife/go-patch/patch"
 
 	boshdir "github.com/cloudfoundry/bosh-cli/director"
 	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
@@ -29,7 +30,10 @@ func NewDeployCmd(
 func (c DeployCmd) Run(opts DeployOpts) error {
 	tpl := boshtpl.NewTemplate(opts.Args.Manifest.Bytes)
 
-	bytes, err := tpl.Evaluate(opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp(), boshtpl.EvaluateOpts{})
+	// Variables missing from the flags are left for the director to resolve
+	evalOpts := boshtpl.EvaluateOpts{ExpectAllVarsUsed: opts.VarErrorsUnused}
+
+	bytes, err := tpl.Evaluate(opts.VarFlags.AsVariables(), c.ops(opts), evalOpts)
 	if err != nil {
 		return bosherr.WrapErrorf(err, "Evaluating manifest")
 	}
@@ -50,6 +54,14 @@ func (c DeployCmd) Run(opts DeployOpts) error {
 	}
 
 	diff := NewDiff(deploymentDiff.Diff)
+
+	if opts.DiffContext {
+		diff, err = c.collapseSecrets(diff, tpl, opts)
+		if err != nil {
+			return err
+		}
+	}
+
 	diff.Print(c.ui)
 
 	err = c.ui.AskForConfirmation()
@@ -71,6 +83,30 @@ func (c DeployCmd) Run(opts DeployOpts) error {
 	return c.deployment.Update(bytes, updateOpts)
 }
 
+// collapseSecrets finds secret paths in the manifest with ops applied but
+// variables left unresolved, so that every value coming from vars or creds
+// is still a variable reference.
+func (c DeployCmd) collapseSecrets(diff Diff, tpl boshtpl.Template, opts DeployOpts) (Diff, error) {
+	bytes, err := tpl.Evaluate(boshtpl.StaticVariables{}, c.ops(opts), boshtpl.EvaluateOpts{})
+	if err != nil {
+		return Diff{}, bosherr.WrapErrorf(err, "Evaluating manifest for secret paths")
+	}
+
+	secretPaths, err := SecretPaths(bytes)
+	if err != nil {
+		return Diff{}, err
+	}
+
+	return diff.CollapseSecrets(secretPaths), nil
+}
+
+// ops adds --tag values to the manifest's top level tags after ops files
+// are applied. The director keeps them with the deployment and sets them
+// as metadata on the VMs and disks it creates through the CPI.
+func (c DeployCmd) ops(opts DeployOpts) patch.Op {
+	return withTags(opts.OpsFlags.AsOp(), opts.Tags)
+}
+
 func (c DeployCmd) checkDeploymentName(bytes []byte) error {
 	manifest, err := boshdir.NewManifestFromBytes(bytes)
 	if err != nil {
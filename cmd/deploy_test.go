@@ -129,6 +129,76 @@ var _ = Describe("DeployCmd", func() {
 			Expect(bytes).To(Equal([]byte("name: dep\nname1: val1-from-kv\nname2: val2-from-file\nxyz: val\n")))
 		})
 
+		It("deploys manifest with tags added after ops files", func() {
+			opts.Args.Manifest = FileBytesArg{
+				Bytes: []byte("name: dep\ntags: {owner: ops, team: a}\n"),
+			}
+
+			opts.OpsFiles = []OpsFileArg{
+				{
+					Ops: patch.Ops([]patch.Op{
+						patch.ReplaceOp{Path: patch.MustNewPointerFromString("/tags/owner"), Value: "from-ops"},
+					}),
+				},
+			}
+
+			opts.Tags = []TagArg{
+				{Key: "owner", Value: "me"},
+				{Key: "cost/center", Value: "42"},
+			}
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(deployment.UpdateCallCount()).To(Equal(1))
+
+			bytes, _ := deployment.UpdateArgsForCall(0)
+			Expect(bytes).To(Equal([]byte("name: dep\ntags:\n  cost/center: \"42\"\n  owner: me\n  team: a\n")))
+		})
+
+		It("adds tags to manifests without tags", func() {
+			opts.Tags = []TagArg{{Key: "owner", Value: "me"}}
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			bytes, _ := deployment.UpdateArgsForCall(0)
+			Expect(bytes).To(Equal([]byte("name: dep\ntags:\n  owner: me\n")))
+		})
+
+		It("returns error listing all unused variables and does not deploy if variables must be used", func() {
+			opts.Args.Manifest = FileBytesArg{Bytes: []byte("name: dep\nname1: ((name1))\nname2: ((name2))\n")}
+			opts.VarErrorsUnused = true
+
+			opts.VarKVs = []boshtpl.VarKV{
+				{Name: "name1", Value: "val1"},
+				{Name: "typo1", Value: "val"},
+				{Name: "typo2", Value: "val"},
+			}
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected to use variables"))
+			Expect(err.Error()).To(ContainSubstring("typo1"))
+			Expect(err.Error()).To(ContainSubstring("typo2"))
+
+			Expect(releaseUploader.UploadReleasesCallCount()).To(Equal(0))
+			Expect(deployment.UpdateCallCount()).To(Equal(0))
+		})
+
+		It("deploys with variables left for the director when variables must be used", func() {
+			opts.Args.Manifest = FileBytesArg{Bytes: []byte("name: dep\nname1: ((name1))\nname2: ((name2))\n")}
+			opts.VarErrorsUnused = true
+
+			opts.VarKVs = []boshtpl.VarKV{{Name: "name1", Value: "val1"}}
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			bytes, _ := deployment.UpdateArgsForCall(0)
+			Expect(bytes).To(Equal([]byte("name: dep\nname1: val1\nname2: ((name2))\n")))
+		})
+
 		It("does not deploy if name specified in the manifest does not match deployment's name", func() {
 			opts.Args.Manifest = FileBytesArg{
 				Bytes: []byte("name: other-name"),
@@ -232,6 +302,51 @@ releases:
 			Expect(ui.Said).To(ContainElement("- some line that was removed\n"))
 		})
 
+		Context("when showing diff context", func() {
+			BeforeEach(func() {
+				opts.Args.Manifest = FileBytesArg{Bytes: []byte(`name: dep
+instance_groups:
+- name: web
+  properties:
+    port: 8080
+    password: ((web_password))
+`)}
+				opts.DiffContext = true
+
+				deployment.DiffReturns(boshdir.NewDeploymentDiff([][]interface{}{
+					[]interface{}{"instance_groups:", nil},
+					[]interface{}{"- name: web", nil},
+					[]interface{}{"  properties:", nil},
+					[]interface{}{"    port: 8080", "removed"},
+					[]interface{}{"    port: 9090", "added"},
+					[]interface{}{"    password: old-password", "removed"},
+					[]interface{}{"    password: new-password", "added"},
+				}, nil), nil)
+			})
+
+			It("collapses changed secret values and shows other changes", func() {
+				err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(ui.Said).To(Equal([]string{
+					"  instance_groups:\n",
+					"  - name: web\n",
+					"    properties:\n",
+					"-     port: 8080\n",
+					"+     port: 9090\n",
+					"      password: [secret changed]\n",
+				}))
+			})
+
+			It("deploys with the diff returned by the director", func() {
+				err := act()
+				Expect(err).ToNot(HaveOccurred())
+
+				_, updateOpts := deployment.UpdateArgsForCall(0)
+				Expect(updateOpts.Diff.Diff).To(HaveLen(7))
+			})
+		})
+
 		It("deploys manifest with diff context", func() {
 			context := map[string]interface{}{
 				"cloud_config_id":   2,
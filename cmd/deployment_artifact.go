@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	"gopkg.in/yaml.v2"
+
+	biconfig "github.com/cloudfoundry/bosh-cli/config"
+	bideplmanifest "github.com/cloudfoundry/bosh-cli/deployment/manifest"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	biinstallmanifest "github.com/cloudfoundry/bosh-cli/installation/manifest"
+)
+
+const (
+	DeploymentArtifactFormatJSON = "json"
+	DeploymentArtifactFormatYAML = "yaml"
+)
+
+// DeploymentArtifactOpts configures the artifact written after a successful create-env.
+// Nothing is written when Path is empty.
+type DeploymentArtifactOpts struct {
+	Path   string
+	Format string
+}
+
+func (o DeploymentArtifactOpts) Validate() error {
+	if o.Path == "" {
+		return nil
+	}
+
+	switch o.Format {
+	case DeploymentArtifactFormatJSON, DeploymentArtifactFormatYAML:
+		return nil
+	default:
+		return bosherr.Errorf("Unknown artifact format '%s', expected '%s' or '%s'", o.Format, DeploymentArtifactFormatJSON, DeploymentArtifactFormatYAML)
+	}
+}
+
+// DeploymentArtifact describes how to connect to a deployed environment
+// so that automation does not need to parse the create-env output
+type DeploymentArtifact struct {
+	Name        string                       `json:"name" yaml:"name"`
+	VMCID       string                       `json:"vm_cid" yaml:"vm_cid"`
+	DiskCID     string                       `json:"disk_cid,omitempty" yaml:"disk_cid,omitempty"`
+	Instances   []DeploymentArtifactInstance `json:"instances" yaml:"instances"`
+	Mbus        DeploymentArtifactMbus       `json:"mbus" yaml:"mbus"`
+	Registry    *DeploymentArtifactRegistry  `json:"registry,omitempty" yaml:"registry,omitempty"`
+	Credentials map[string]interface{}       `json:"credentials" yaml:"credentials"`
+}
+
+type DeploymentArtifactInstance struct {
+	Name string   `json:"name" yaml:"name"`
+	IPs  []string `json:"ips" yaml:"ips"`
+}
+
+type DeploymentArtifactMbus struct {
+	URL    string `json:"url" yaml:"url"`
+	CACert string `json:"ca_cert,omitempty" yaml:"ca_cert,omitempty"`
+}
+
+type DeploymentArtifactRegistry struct {
+	Host     string `json:"host" yaml:"host"`
+	Port     int    `json:"port" yaml:"port"`
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
+}
+
+// NewDeploymentArtifact collects the endpoints of the deployed instance from the manifests,
+// the CIDs created by the CPI from the deployment state and the credentials from the variables
+func NewDeploymentArtifact(
+	deploymentManifest bideplmanifest.Manifest,
+	installationManifest biinstallmanifest.Manifest,
+	deploymentState biconfig.DeploymentState,
+	vars boshtpl.Variables,
+) (DeploymentArtifact, error) {
+	artifact := DeploymentArtifact{
+		Name:        deploymentManifest.Name,
+		VMCID:       deploymentState.CurrentVMCID,
+		Instances:   []DeploymentArtifactInstance{},
+		Credentials: map[string]interface{}{},
+		Mbus: DeploymentArtifactMbus{
+			URL:    installationManifest.Mbus,
+			CACert: installationManifest.Cert.CA,
+		},
+	}
+
+	for _, disk := range deploymentState.Disks {
+		if disk.ID == deploymentState.CurrentDiskID {
+			artifact.DiskCID = disk.CID
+		}
+	}
+
+	for _, job := range deploymentManifest.Jobs {
+		ips := []string{}
+		for _, network := range job.Networks {
+			ips = append(ips, network.StaticIPs...)
+		}
+
+		artifact.Instances = append(artifact.Instances, DeploymentArtifactInstance{Name: job.Name + "/0", IPs: ips})
+	}
+
+	if !installationManifest.Registry.IsEmpty() {
+		artifact.Registry = &DeploymentArtifactRegistry{
+			Host:     installationManifest.Registry.Host,
+			Port:     installationManifest.Registry.Port,
+			Username: installationManifest.Registry.Username,
+			Password: installationManifest.Registry.Password,
+		}
+	}
+
+	defs, err := vars.List()
+	if err != nil {
+		return DeploymentArtifact{}, bosherr.WrapError(err, "Listing variables")
+	}
+
+	for _, def := range defs {
+		val, found, err := vars.Get(boshtpl.VariableDefinition{Name: def.Name})
+		if err != nil {
+			return DeploymentArtifact{}, bosherr.WrapErrorf(err, "Getting variable '%s'", def.Name)
+		}
+
+		if !found {
+			continue
+		}
+
+		// Variables loaded from YAML contain maps that cannot be marshalled as JSON
+		artifact.Credentials[def.Name], err = biproperty.Build(val)
+		if err != nil {
+			return DeploymentArtifact{}, bosherr.WrapErrorf(err, "Converting variable '%s'", def.Name)
+		}
+	}
+
+	return artifact, nil
+}
+
+// Write saves the artifact readable only by the current user since it contains credentials
+func (a DeploymentArtifact) Write(fs boshsys.FileSystem, opts DeploymentArtifactOpts) error {
+	var (
+		bytes []byte
+		err   error
+	)
+
+	if opts.Format == DeploymentArtifactFormatJSON {
+		bytes, err = json.MarshalIndent(a, "", "  ")
+	} else {
+		bytes, err = yaml.Marshal(a)
+	}
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling deployment artifact")
+	}
+
+	file, err := fs.OpenFile(opts.Path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(0600))
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Opening deployment artifact '%s'", opts.Path)
+	}
+
+	defer file.Close()
+
+	// An existing file keeps its permissions when it is opened, so restrict them before writing secrets
+	err = fs.Chmod(opts.Path, os.FileMode(0600))
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Setting deployment artifact '%s' permissions", opts.Path)
+	}
+
+	_, err = file.Write(bytes)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing deployment artifact '%s'", opts.Path)
+	}
+
+	return nil
+}
@@ -18,10 +18,11 @@ import (
 	biinstallmanifest "github.com/cloudfoundry/bosh-cli/installation/manifest"
 	birelsetmanifest "github.com/cloudfoundry/bosh-cli/release/set/manifest"
 	biui "github.com/cloudfoundry/bosh-cli/ui"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 )
 
 type DeploymentDeleter interface {
-	DeleteDeployment(stage biui.Stage) (err error)
+	DeleteDeployment(stage biui.Stage, force bool) (err error)
 }
 
 func NewDeploymentDeleter(
@@ -43,6 +44,8 @@ func NewDeploymentDeleter(
 	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser,
 	tempRootConfigurator TempRootConfigurator,
 	targetProvider biinstall.TargetProvider,
+	warnings biwarn.Warnings,
+	confirmDestroy DestroyConfirmation,
 ) DeploymentDeleter {
 	return &deploymentDeleter{
 		ui:                                      ui,
@@ -63,6 +66,8 @@ func NewDeploymentDeleter(
 		releaseSetAndInstallationManifestParser: releaseSetAndInstallationManifestParser,
 		tempRootConfigurator:                    tempRootConfigurator,
 		targetProvider:                          targetProvider,
+		warnings:                                warnings,
+		confirmDestroy:                          confirmDestroy,
 	}
 }
 
@@ -85,9 +90,13 @@ type deploymentDeleter struct {
 	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser
 	tempRootConfigurator                    TempRootConfigurator
 	targetProvider                          biinstall.TargetProvider
+	warnings                                biwarn.Warnings
+	confirmDestroy                          DestroyConfirmation
 }
 
-func (c *deploymentDeleter) DeleteDeployment(stage biui.Stage) (err error) {
+func (c *deploymentDeleter) DeleteDeployment(stage biui.Stage, force bool) (err error) {
+	defer printWarnings(c.ui, c.warnings)
+
 	c.ui.BeginLinef("Deployment state: '%s'\n", c.deploymentStateService.Path())
 
 	if !c.deploymentStateService.Exists() {
@@ -145,9 +154,14 @@ func (c *deploymentDeleter) DeleteDeployment(stage biui.Stage) (err error) {
 		return err
 	}
 
+	err = c.confirmDestroy(installationManifest.Name, deploymentState.Production)
+	if err != nil {
+		return bosherr.WrapError(err, "Confirming deletion of environment")
+	}
+
 	err = c.cpiInstaller.WithInstalledCpiRelease(installationManifest, target, stage, func(localCpiInstallation biinstall.Installation) error {
 		return localCpiInstallation.WithRunningRegistry(c.logger, stage, func() error {
-			err = c.findAndDeleteDeployment(stage, localCpiInstallation, deploymentState.DirectorID, installationManifest.Mbus, installationManifest.Cert.CA)
+			err = c.findAndDeleteDeployment(stage, localCpiInstallation, deploymentState.DirectorID, installationManifest.Mbus, installationManifest.Cert.CA, force)
 
 			if err != nil {
 				return err
@@ -167,21 +181,31 @@ func (c *deploymentDeleter) DeleteDeployment(stage biui.Stage) (err error) {
 	return err
 }
 
-func (c *deploymentDeleter) findAndDeleteDeployment(stage biui.Stage, installation biinstall.Installation, directorID, installationMbus, caCert string) error {
+func (c *deploymentDeleter) findAndDeleteDeployment(stage biui.Stage, installation biinstall.Installation, directorID, installationMbus, caCert string, force bool) error {
 	deploymentManager, err := c.deploymentManager(installation, directorID, installationMbus, caCert)
 	if err != nil {
 		return err
 	}
 
-	err = c.findCurrentDeploymentAndDelete(stage, deploymentManager)
+	err = c.findCurrentDeploymentAndDelete(stage, deploymentManager, force)
 	if err != nil {
+		if !force {
+			return bosherr.WrapError(err, "Deleting deployment")
+		}
+
+		// Unused disks and stemcells are still cleaned up so that a re-run only has to retry what failed
+		cleanupErr := deploymentManager.Cleanup(stage)
+		if cleanupErr != nil {
+			return bosherr.NewMultiError(bosherr.WrapError(err, "Deleting deployment"), cleanupErr)
+		}
+
 		return bosherr.WrapError(err, "Deleting deployment")
 	}
 
 	return deploymentManager.Cleanup(stage)
 }
 
-func (c *deploymentDeleter) findCurrentDeploymentAndDelete(stage biui.Stage, deploymentManager bidepl.Manager) error {
+func (c *deploymentDeleter) findCurrentDeploymentAndDelete(stage biui.Stage, deploymentManager bidepl.Manager, force bool) error {
 	c.logger.Debug(c.logTag, "Finding current deployment...")
 
 	deployment, found, err := deploymentManager.FindCurrent()
@@ -196,6 +220,10 @@ func (c *deploymentDeleter) findCurrentDeploymentAndDelete(stage biui.Stage, dep
 			return nil
 		}
 
+		if force {
+			return deployment.ForceDelete(deleteStage)
+		}
+
 		return deployment.Delete(deleteStage)
 	})
 }
@@ -210,7 +238,10 @@ func (c *deploymentDeleter) deploymentManager(installation biinstall.Installatio
 
 	c.logger.Debug(c.logTag, "Creating agent client...")
 
-	agentClient, _ := c.agentClientFactory.NewAgentClient(directorID, installationMbus, caCert)
+	agentClient, err := c.agentClientFactory.NewAgentClient(directorID, installationMbus, caCert)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Creating agent client")
+	}
 
 	c.logger.Debug(c.logTag, "Creating blobstore client...")
 
@@ -1,7 +1,14 @@
 package cmd
 
 import (
+	gosha512 "crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"strings"
+
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
 	"github.com/cppforlife/go-patch/patch"
 
 	bideplmanifest "github.com/cloudfoundry/bosh-cli/deployment/manifest"
@@ -17,22 +24,28 @@ type DeploymentManifestParser interface {
 }
 
 type deploymentManifestParser struct {
-	deploymentParser    bideplmanifest.Parser
-	deploymentValidator bideplmanifest.Validator
-	releaseManager      birel.Manager
-	templateFactory     bidepltpl.DeploymentTemplateFactory
+	deploymentParser         bideplmanifest.Parser
+	deploymentValidator      bideplmanifest.Validator
+	releaseManager           birel.Manager
+	templateFactory          bidepltpl.DeploymentTemplateFactory
+	cloudPropertiesOverrides []CloudPropertiesOverrideArg
+	logger                   boshlog.Logger
 }
 
 func NewDeploymentManifestParser(
 	deploymentParser bideplmanifest.Parser,
 	deploymentValidator bideplmanifest.Validator,
 	releaseManager birel.Manager,
-	templateFactory bidepltpl.DeploymentTemplateFactory) DeploymentManifestParser {
+	templateFactory bidepltpl.DeploymentTemplateFactory,
+	cloudPropertiesOverrides []CloudPropertiesOverrideArg,
+	logger boshlog.Logger) DeploymentManifestParser {
 	return deploymentManifestParser{
-		deploymentParser:    deploymentParser,
-		deploymentValidator: deploymentValidator,
-		releaseManager:      releaseManager,
-		templateFactory:     templateFactory,
+		deploymentParser:         deploymentParser,
+		deploymentValidator:      deploymentValidator,
+		releaseManager:           releaseManager,
+		templateFactory:          templateFactory,
+		cloudPropertiesOverrides: cloudPropertiesOverrides,
+		logger:                   logger,
 	}
 }
 
@@ -60,6 +73,11 @@ func (y deploymentManifestParser) GetDeploymentManifest(path string, vars boshtp
 			return bosherr.WrapErrorf(err, "Parsing deployment manifest '%s'", path)
 		}
 
+		deploymentManifest, manifestSHA, err = y.overrideCloudProperties(deploymentManifest, manifestSHA)
+		if err != nil {
+			return err
+		}
+
 		err = y.deploymentValidator.Validate(deploymentManifest, releaseSetManifest)
 		if err != nil {
 			return bosherr.WrapError(err, "Validating deployment manifest")
@@ -70,6 +88,11 @@ func (y deploymentManifestParser) GetDeploymentManifest(path string, vars boshtp
 			return bosherr.WrapError(err, "Validating deployment jobs refer to jobs in release")
 		}
 
+		err = y.deploymentValidator.ValidateJobProperties(deploymentManifest, y.releaseManager)
+		if err != nil {
+			return bosherr.WrapError(err, "Validating deployment job properties")
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -78,3 +101,61 @@ func (y deploymentManifestParser) GetDeploymentManifest(path string, vars boshtp
 
 	return deploymentManifest, manifestSHA, nil
 }
+
+// overrideCloudProperties merges cloud properties given on the command line
+// into the manifest and folds them into the manifest SHA so that changing an
+// override is detected as a deployment change.
+func (y deploymentManifestParser) overrideCloudProperties(deploymentManifest bideplmanifest.Manifest, manifestSHA string) (bideplmanifest.Manifest, string, error) {
+	if len(y.cloudPropertiesOverrides) == 0 {
+		return deploymentManifest, manifestSHA, nil
+	}
+
+	var err error
+
+	for _, override := range y.cloudPropertiesOverrides {
+		deploymentManifest, err = deploymentManifest.WithResourcePoolCloudProperties(override.ResourcePool, override.CloudProperties)
+		if err != nil {
+			return bideplmanifest.Manifest{}, "", bosherr.WrapError(err, "Overriding resource pool cloud properties")
+		}
+	}
+
+	for _, resourcePool := range deploymentManifest.ResourcePools {
+		y.logger.Debug("deploymentManifestParser", "Resource pool '%s' cloud properties after overrides: %#v",
+			resourcePool.Name, redactCloudProperties(resourcePool.CloudProperties))
+	}
+
+	overridesBytes, err := json.Marshal(y.cloudPropertiesOverrides)
+	if err != nil {
+		return bideplmanifest.Manifest{}, "", bosherr.WrapError(err, "Marshalling resource pool cloud properties overrides")
+	}
+
+	sha512 := gosha512.New()
+	sha512.Write([]byte(manifestSHA))
+	sha512.Write(overridesBytes)
+
+	return deploymentManifest, fmt.Sprintf("%x", sha512.Sum(nil)), nil
+}
+
+var sensitiveCloudPropertyNames = []string{"password", "secret", "key", "token", "credential"}
+
+func redactCloudProperties(cloudProperties biproperty.Map) biproperty.Map {
+	redacted := biproperty.Map{}
+
+	for name, value := range cloudProperties {
+		if nestedProperties, ok := value.(biproperty.Map); ok {
+			redacted[name] = redactCloudProperties(nestedProperties)
+			continue
+		}
+
+		redacted[name] = value
+
+		for _, sensitiveName := range sensitiveCloudPropertyNames {
+			if strings.Contains(strings.ToLower(name), sensitiveName) {
+				redacted[name] = boshtpl.RedactedValue
+				break
+			}
+		}
+	}
+
+	return redacted
+}
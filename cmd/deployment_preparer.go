@@ -1,11 +1,17 @@
 package cmd
 
 import (
+	"sort"
+	"strings"
+
+	biagentclient "github.com/cloudfoundry/bosh-agent/agentclient"
 	bihttpagent "github.com/cloudfoundry/bosh-agent/agentclient/http"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	bihttpclient "github.com/cloudfoundry/bosh-utils/httpclient"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
 	"github.com/cppforlife/go-patch/patch"
+	"github.com/dustin/go-humanize"
 
 	biblobstore "github.com/cloudfoundry/bosh-cli/blobstore"
 	bicloud "github.com/cloudfoundry/bosh-cli/cloud"
@@ -13,6 +19,7 @@ import (
 	bicpirel "github.com/cloudfoundry/bosh-cli/cpi/release"
 	bidepl "github.com/cloudfoundry/bosh-cli/deployment"
 	bideplmanifest "github.com/cloudfoundry/bosh-cli/deployment/manifest"
+	bisshtunnel "github.com/cloudfoundry/bosh-cli/deployment/sshtunnel"
 	bivm "github.com/cloudfoundry/bosh-cli/deployment/vm"
 	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
 	biinstall "github.com/cloudfoundry/bosh-cli/installation"
@@ -21,12 +28,15 @@ import (
 	birelsetmanifest "github.com/cloudfoundry/bosh-cli/release/set/manifest"
 	bistemcell "github.com/cloudfoundry/bosh-cli/stemcell"
 	biui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 )
 
 func NewDeploymentPreparer(
 	ui biui.UI,
 	logger boshlog.Logger,
 	logTag string,
+	fs boshsys.FileSystem,
 	deploymentStateService biconfig.DeploymentStateService,
 	legacyDeploymentStateMigrator biconfig.LegacyDeploymentStateMigrator,
 	releaseManager boshinst.ReleaseManager,
@@ -37,6 +47,7 @@ func NewDeploymentPreparer(
 	vmManagerFactory bivm.ManagerFactory,
 	blobstoreFactory biblobstore.Factory,
 	deployer bidepl.Deployer,
+	sshTunnelPool bisshtunnel.PoolingFactory,
 	deploymentManifestPath string,
 	deploymentVars boshtpl.Variables,
 	deploymentOp patch.Op,
@@ -45,13 +56,18 @@ func NewDeploymentPreparer(
 	stemcellFetcher bistemcell.Fetcher,
 	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser,
 	deploymentManifestParser DeploymentManifestParser,
+	deploymentValidator bideplmanifest.Validator,
 	tempRootConfigurator TempRootConfigurator,
 	targetProvider biinstall.TargetProvider,
+	warnings biwarn.Warnings,
+	warningsAsErrors bool,
+	workers int,
 ) DeploymentPreparer {
 	return DeploymentPreparer{
 		ui:                                      ui,
 		logger:                                  logger,
 		logTag:                                  logTag,
+		fs:                                      fs,
 		deploymentStateService:                  deploymentStateService,
 		legacyDeploymentStateMigrator:           legacyDeploymentStateMigrator,
 		releaseManager:                          releaseManager,
@@ -62,6 +78,7 @@ func NewDeploymentPreparer(
 		vmManagerFactory:                        vmManagerFactory,
 		blobstoreFactory:                        blobstoreFactory,
 		deployer:                                deployer,
+		sshTunnelPool:                           sshTunnelPool,
 		deploymentManifestPath:                  deploymentManifestPath,
 		deploymentVars:                          deploymentVars,
 		deploymentOp:                            deploymentOp,
@@ -70,8 +87,12 @@ func NewDeploymentPreparer(
 		stemcellFetcher:                         stemcellFetcher,
 		releaseSetAndInstallationManifestParser: releaseSetAndInstallationManifestParser,
 		deploymentManifestParser:                deploymentManifestParser,
+		deploymentValidator:                     deploymentValidator,
 		tempRootConfigurator:                    tempRootConfigurator,
 		targetProvider:                          targetProvider,
+		warnings:                                warnings,
+		warningsAsErrors:                        warningsAsErrors,
+		workers:                                 workers,
 	}
 }
 
@@ -79,6 +100,7 @@ type DeploymentPreparer struct {
 	ui                                      biui.UI
 	logger                                  boshlog.Logger
 	logTag                                  string
+	fs                                      boshsys.FileSystem
 	deploymentStateService                  biconfig.DeploymentStateService
 	legacyDeploymentStateMigrator           biconfig.LegacyDeploymentStateMigrator
 	releaseManager                          boshinst.ReleaseManager
@@ -89,6 +111,7 @@ type DeploymentPreparer struct {
 	vmManagerFactory                        bivm.ManagerFactory
 	blobstoreFactory                        biblobstore.Factory
 	deployer                                bidepl.Deployer
+	sshTunnelPool                           bisshtunnel.PoolingFactory
 	deploymentManifestPath                  string
 	deploymentVars                          boshtpl.Variables
 	deploymentOp                            patch.Op
@@ -97,14 +120,41 @@ type DeploymentPreparer struct {
 	stemcellFetcher                         bistemcell.Fetcher
 	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser
 	deploymentManifestParser                DeploymentManifestParser
+	deploymentValidator                     bideplmanifest.Validator
 	tempRootConfigurator                    TempRootConfigurator
 	targetProvider                          biinstall.TargetProvider
+	warnings                                biwarn.Warnings
+	warningsAsErrors                        bool
+	workers                                 int
+}
+
+// PrepareDeploymentOpts holds the create-env flags that change how the environment is deployed
+type PrepareDeploymentOpts struct {
+	Recreate                bool
+	RecreatePersistentDisks bool
+	PruneCompiled           bool
+	ProbeAgent              bool
+	TagStemcell             bool
+	SkipPreflight           bool
+	AllowDowngrade          bool
+	Production              bool
+	DryRun                  bool
+
+	Artifact DeploymentArtifactOpts
 }
 
-func (c *DeploymentPreparer) PrepareDeployment(stage biui.Stage, recreate bool, recreatePersistentDisks bool) (err error) {
+func (c *DeploymentPreparer) PrepareDeployment(stage biui.Stage, opts PrepareDeploymentOpts) (err error) {
+	defer func() {
+		printWarnings(c.ui, c.warnings)
+		if err == nil {
+			err = c.checkWarnings()
+		}
+	}()
+
 	c.ui.BeginLinef("Deployment state: '%s'\n", c.deploymentStateService.Path())
 
-	if !c.deploymentStateService.Exists() {
+	// A dry run only reads the deployment state, so a legacy deployments file is not migrated
+	if !opts.DryRun && !c.deploymentStateService.Exists() {
 		migrated, err := c.legacyDeploymentStateMigrator.MigrateIfExists(biconfig.LegacyDeploymentStatePath(c.deploymentManifestPath))
 		if err != nil {
 			return bosherr.WrapError(err, "Migrating legacy deployment state file")
@@ -119,14 +169,29 @@ func (c *DeploymentPreparer) PrepareDeployment(stage biui.Stage, recreate bool,
 		return bosherr.WrapError(err, "Loading deployment state")
 	}
 
-	target, err := c.targetProvider.NewTarget()
-	if err != nil {
-		return bosherr.WrapError(err, "Determining installation target")
+	// An environment stays marked as production, even when later deployed without the flag
+	if opts.Production && !deploymentState.Production && !opts.DryRun {
+		deploymentState.Production = true
+
+		err = c.deploymentStateService.Save(deploymentState)
+		if err != nil {
+			return bosherr.WrapError(err, "Marking deployment state as production")
+		}
 	}
 
-	err = c.tempRootConfigurator.PrepareAndSetTempRoot(target.TmpPath(), c.logger)
-	if err != nil {
-		return bosherr.WrapError(err, "Setting temp root")
+	var target biinstall.Target
+
+	// The installation workspace is only needed to install the CPI, which a dry run does not do
+	if !opts.DryRun {
+		target, err = c.targetProvider.NewTarget()
+		if err != nil {
+			return bosherr.WrapError(err, "Determining installation target")
+		}
+
+		err = c.tempRootConfigurator.PrepareAndSetTempRoot(target.TmpPath(), c.logger)
+		if err != nil {
+			return bosherr.WrapError(err, "Setting temp root")
+		}
 	}
 
 	defer func() {
@@ -149,14 +214,12 @@ func (c *DeploymentPreparer) PrepareDeployment(stage biui.Stage, recreate bool,
 			return err
 		}
 
-		for _, releaseRef := range releaseSetManifest.Releases {
-			err = c.releaseFetcher.DownloadAndExtract(releaseRef, stage)
-			if err != nil {
-				return err
-			}
+		err := c.releaseFetcher.DownloadAndExtractAll(releaseSetManifest.Releases, c.workers, stage)
+		if err != nil {
+			return err
 		}
 
-		err := c.cpiInstaller.ValidateCpiRelease(installationManifest, stage)
+		err = c.cpiInstaller.ValidateCpiRelease(installationManifest, stage)
 		if err != nil {
 			return err
 		}
@@ -172,6 +235,7 @@ func (c *DeploymentPreparer) PrepareDeployment(stage biui.Stage, recreate bool,
 	if err != nil {
 		return err
 	}
+
 	defer func() {
 		deleteErr := extractedStemcell.Cleanup()
 		if deleteErr != nil {
@@ -179,18 +243,54 @@ func (c *DeploymentPreparer) PrepareDeployment(stage biui.Stage, recreate bool,
 		}
 	}()
 
+	stemcellManifest := extractedStemcell.Manifest()
+
+	err = c.deploymentValidator.ValidateStemcellCompatibility(deploymentManifest, c.releaseManager, stemcellManifest.OS, stemcellManifest.Version)
+	if err != nil {
+		return bosherr.WrapError(err, "Validating deployment jobs support the stemcell")
+	}
+
+	// Fail before changing anything when validation raised warnings that must be treated as errors
+	err = c.checkWarnings()
+	if err != nil {
+		return err
+	}
+
+	if !opts.AllowDowngrade {
+		err = c.checkDowngrades()
+		if err != nil {
+			return err
+		}
+	}
+
 	isDeployed, err := c.deploymentRecord.IsDeployed(manifestSHA, c.releaseManager.List(), extractedStemcell)
 	if err != nil {
 		return bosherr.WrapError(err, "Checking if deployment has changed")
 	}
 
-	if isDeployed && !recreate && !recreatePersistentDisks {
+	if isDeployed && !opts.Recreate && !opts.RecreatePersistentDisks {
 		c.ui.BeginLinef("No deployment, stemcell or release changes. Skipping deploy.\n")
-		return nil
+		if opts.DryRun {
+			return nil
+		}
+		return c.exportArtifact(opts.Artifact, installationManifest, deploymentManifest)
+	}
+
+	if opts.DryRun {
+		return c.printPlan(deploymentState, deploymentManifest, stemcellManifest, opts.RecreatePersistentDisks)
+	}
+
+	if !opts.RecreatePersistentDisks {
+		err = c.confirmDiskMigrations(deploymentState, deploymentManifest, stemcellManifest)
+		if err != nil {
+			return err
+		}
 	}
 
 	err = c.cpiInstaller.WithInstalledCpiRelease(installationManifest, target, stage, func(installation biinstall.Installation) error {
-		return installation.WithRunningRegistry(c.logger, stage, func() error {
+		err := installation.WithRunningRegistry(c.logger, stage, func() error {
+			defer c.stopSSHTunnels()
+
 			return c.deploy(
 				installation,
 				deploymentState,
@@ -198,12 +298,96 @@ func (c *DeploymentPreparer) PrepareDeployment(stage biui.Stage, recreate bool,
 				installationManifest,
 				deploymentManifest,
 				manifestSHA,
+				opts,
 				stage)
 		})
+		if err != nil || !opts.PruneCompiled {
+			return err
+		}
+
+		reclaimed, err := c.cpiInstaller.PruneCompiledPackages(installation, target, stage)
+		if err != nil {
+			return err
+		}
+
+		c.ui.BeginLinef("Reclaimed %s from pruned compiled packages\n", humanize.Bytes(uint64(reclaimed)))
+
+		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	return c.exportArtifact(opts.Artifact, installationManifest, deploymentManifest)
+}
+
+// printPlan shows what deploying would change in the IaaS, without installing the CPI
+func (c *DeploymentPreparer) printPlan(deploymentState biconfig.DeploymentState, deploymentManifest bideplmanifest.Manifest, stemcellManifest bistemcell.Manifest, recreatePersistentDisks bool) error {
+	actions, err := bidepl.Plan(deploymentManifest, deploymentState, stemcellManifest, recreatePersistentDisks)
+	if err != nil {
+		return bosherr.WrapError(err, "Planning deployment")
+	}
+
+	table := boshtbl.Table{
+		Title:   "Planned changes",
+		Content: "changes",
+
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Resource"),
+			boshtbl.NewHeader("Action"),
+			boshtbl.NewHeader("Details"),
+		},
+	}
+
+	for _, action := range actions {
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(action.Resource),
+			boshtbl.NewValueString(action.Action),
+			boshtbl.NewValueString(action.Details),
+		})
+	}
 
-	return err
+	c.ui.PrintTable(table)
 
+	c.ui.BeginLinef("Dry run: skipping deploy.\n")
+
+	return nil
+}
+
+// confirmDiskMigrations asks before persistent disks whose size or cloud properties changed are migrated,
+// since migrating copies all data to a new disk and deletes the current one
+func (c *DeploymentPreparer) confirmDiskMigrations(deploymentState biconfig.DeploymentState, deploymentManifest bideplmanifest.Manifest, stemcellManifest bistemcell.Manifest) error {
+	actions, err := bidepl.Plan(deploymentManifest, deploymentState, stemcellManifest, false)
+	if err != nil {
+		return bosherr.WrapError(err, "Planning deployment")
+	}
+
+	var migrations []bidepl.PlannedAction
+
+	for _, action := range actions {
+		if action.Action == bidepl.PlanActionMigrate {
+			migrations = append(migrations, action)
+		}
+	}
+
+	if len(migrations) == 0 {
+		return nil
+	}
+
+	c.ui.PrintLinef("Persistent disks changed in the manifest and will be migrated:")
+
+	for _, migration := range migrations {
+		c.ui.PrintLinef("  - %s: %s", migration.Resource, migration.Details)
+	}
+
+	return c.ui.AskForConfirmation()
+}
+
+// stopSSHTunnels stops the SSH tunnels forwarding to the registry, which were kept open during deploy
+func (c *DeploymentPreparer) stopSSHTunnels() {
+	if err := c.sshTunnelPool.Close(); err != nil {
+		c.logger.Warn(c.logTag, "Failed to stop SSH tunnels: %s", err.Error())
+	}
 }
 
 func (c *DeploymentPreparer) deploy(
@@ -213,6 +397,7 @@ func (c *DeploymentPreparer) deploy(
 	installationManifest biinstallmanifest.Manifest,
 	deploymentManifest bideplmanifest.Manifest,
 	manifestSHA string,
+	opts PrepareDeploymentOpts,
 	stage biui.Stage,
 ) (err error) {
 	cloud, err := c.cloudFactory.NewCloud(installation, deploymentState.DirectorID)
@@ -220,17 +405,57 @@ func (c *DeploymentPreparer) deploy(
 		return bosherr.WrapError(err, "Creating CPI client from CPI installation")
 	}
 
-	stemcellManager := c.stemcellManagerFactory.NewManager(cloud)
+	if !opts.SkipPreflight {
+		err = c.validateCPIConnectivity(cloud, stage)
+		if err != nil {
+			return err
+		}
+	}
 
-	cloudStemcell, err := stemcellManager.Upload(extractedStemcell, stage)
+	err = c.checkQuota(cloud, deploymentState, deploymentManifest)
 	if err != nil {
 		return err
 	}
 
+	// Created before the stemcell is uploaded so that nothing changes in the cloud when the agent cannot be reached at all, e.g. offline
 	agentClient, err := c.agentClientFactory.NewAgentClient(deploymentState.DirectorID, installationManifest.Mbus, installationManifest.Cert.CA)
 	if err != nil {
 		return err
 	}
+
+	stemcellManager := c.stemcellManagerFactory.NewManager(cloud)
+
+	// Stemcells are only tagged when asked to, since their cloud properties come from the stemcell
+	var stemcellTags map[string]string
+	if opts.TagStemcell {
+		stemcellTags = deploymentManifest.Tags
+	}
+
+	cloudStemcell, err := stemcellManager.Upload(extractedStemcell, stemcellTags, stage)
+	if err != nil {
+		return err
+	}
+
+	if opts.ProbeAgent {
+		prober, err := bivm.NewHTTPAgentProber(agentClient)
+		if err != nil {
+			return err
+		}
+
+		stemcellManifest := extractedStemcell.Manifest()
+
+		agentClient = bivm.NewProbingAgentClient(
+			agentClient,
+			prober,
+			bivm.AgentCompatibility{
+				StemcellName: stemcellManifest.Name + "/" + stemcellManifest.Version,
+				BoshProtocol: stemcellManifest.BoshProtocol,
+				APIVersion:   stemcellManifest.APIVersion,
+			},
+			c.logger,
+		)
+	}
+
 	vmManager := c.vmManagerFactory.NewManager(cloud, agentClient)
 
 	blobstore, err := c.blobstoreFactory.Create(installationManifest.Mbus, bihttpclient.CreateDefaultClientInsecureSkipVerify())
@@ -238,7 +463,14 @@ func (c *DeploymentPreparer) deploy(
 		return bosherr.WrapError(err, "Creating blobstore client")
 	}
 
-	err = stage.PerformComplex("deploying", func(deployStage biui.Stage) error {
+	// The deployer always replaces the VM and reattaches existing disks;
+	// the stage name makes it clear that this was forced with --recreate
+	deployStageName := "deploying"
+	if opts.Recreate {
+		deployStageName = "recreating VM"
+	}
+
+	err = stage.PerformComplex(deployStageName, func(deployStage biui.Stage) error {
 		err = c.deploymentRecord.Clear()
 		if err != nil {
 			return bosherr.WrapError(err, "Clearing deployment record")
@@ -268,6 +500,8 @@ func (c *DeploymentPreparer) deploy(
 		return err
 	}
 
+	c.printHealthSummary(agentClient, deploymentManifest)
+
 	// TODO: cleanup unused disks here?
 
 	err = stemcellManager.DeleteUnused(stage)
@@ -277,3 +511,147 @@ func (c *DeploymentPreparer) deploy(
 
 	return nil
 }
+
+// printHealthSummary shows the agent reported state of the deployed instance.
+// It is informational only; failing to reach the agent does not fail the deploy.
+func (c *DeploymentPreparer) printHealthSummary(agentClient biagentclient.AgentClient, deploymentManifest bideplmanifest.Manifest) {
+	agentState, err := agentClient.GetState()
+	if err != nil {
+		c.logger.Warn(c.logTag, "Failed to get agent state for health summary: %s", err.Error())
+		c.warnings.Warn("deploy", "Unable to determine instance health: agent is unreachable")
+		return
+	}
+
+	table := boshtbl.Table{
+		Content: "instances",
+
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Instance"),
+			boshtbl.NewHeader("Process State"),
+			boshtbl.NewHeader("IPs"),
+			boshtbl.NewHeader("Jobs"),
+		},
+	}
+
+	for _, job := range deploymentManifest.Jobs {
+		ips := []string{}
+		for _, networkSpec := range agentState.NetworkSpecs {
+			if networkSpec.IP != "" {
+				ips = append(ips, networkSpec.IP)
+			}
+		}
+		sort.Strings(ips)
+
+		templateNames := []string{}
+		for _, template := range job.Templates {
+			templateNames = append(templateNames, template.Name)
+		}
+
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(job.Name + "/0"),
+			boshtbl.NewValueFmt(boshtbl.NewValueString(agentState.JobState), agentState.JobState != "running"),
+			boshtbl.NewValueString(strings.Join(ips, ", ")),
+			boshtbl.NewValueStrings(templateNames),
+		})
+	}
+
+	c.ui.PrintTable(table)
+}
+
+// exportArtifact writes the endpoints and credentials of the deployed environment for automation to consume
+func (c *DeploymentPreparer) exportArtifact(opts DeploymentArtifactOpts, installationManifest biinstallmanifest.Manifest, deploymentManifest bideplmanifest.Manifest) error {
+	if opts.Path == "" {
+		return nil
+	}
+
+	// Reloaded since deploying changes the VM and disk CIDs
+	deploymentState, err := c.deploymentStateService.Load()
+	if err != nil {
+		return bosherr.WrapError(err, "Loading deployment state")
+	}
+
+	artifact, err := NewDeploymentArtifact(deploymentManifest, installationManifest, deploymentState, c.deploymentVars)
+	if err != nil {
+		return bosherr.WrapError(err, "Exporting deployment artifact")
+	}
+
+	err = artifact.Write(c.fs, opts)
+	if err != nil {
+		return bosherr.WrapError(err, "Exporting deployment artifact")
+	}
+
+	c.ui.BeginLinef("Exported deployment artifact: '%s'\n", opts.Path)
+
+	return nil
+}
+
+// checkDowngrades refuses to replace a deployed release with an older version, e.g. one pinned by a stale manifest
+func (c *DeploymentPreparer) checkDowngrades() error {
+	downgrades, err := c.deploymentRecord.FindDowngrades(c.releaseManager.List())
+	if err != nil {
+		return bosherr.WrapError(err, "Checking for release downgrades")
+	}
+
+	if len(downgrades) == 0 {
+		return nil
+	}
+
+	var errs []error
+	for _, downgrade := range downgrades {
+		errs = append(errs, bosherr.Errorf(
+			"Release '%s' would be downgraded from currently deployed version '%s' to '%s'",
+			downgrade.Name, downgrade.CurrentVersion, downgrade.RequestedVersion,
+		))
+	}
+
+	return bosherr.WrapError(bosherr.NewMultiError(errs...), "Refusing to downgrade releases (use --allow-downgrade to deploy them anyway)")
+}
+
+// validateCPIConnectivity makes a cheap CPI request right after the CPI is installed, so that
+// CPIs that cannot reach or authenticate with the IaaS fail before the stemcell is uploaded
+func (c *DeploymentPreparer) validateCPIConnectivity(cloud bicloud.Cloud, stage biui.Stage) error {
+	return stage.Perform("Validating CPI connectivity", func() error {
+		_, err := cloud.Info()
+		if err != nil {
+			cloudErr, ok := err.(bicloud.Error)
+			if ok && cloudErr.Type() == bicloud.NotImplementedError {
+				c.logger.Debug(c.logTag, "Skipping CPI connectivity check: the CPI does not implement info")
+				return nil
+			}
+
+			return bosherr.WrapError(err, "Validating CPI connectivity (use --skip-preflight to deploy anyway)")
+		}
+
+		return nil
+	})
+}
+
+// checkQuota fails before anything is created in the cloud when the CPI reports that the deployment does not fit within the available quota
+func (c *DeploymentPreparer) checkQuota(cloud bicloud.Cloud, deploymentState biconfig.DeploymentState, deploymentManifest bideplmanifest.Manifest) error {
+	quota, err := cloud.Quota()
+	if err != nil {
+		cloudErr, ok := err.(bicloud.Error)
+		if ok && cloudErr.Type() == bicloud.NotImplementedError {
+			c.logger.Debug(c.logTag, "Skipping quota check: the CPI does not implement quota")
+			return nil
+		}
+
+		return bosherr.WrapError(err, "Checking IaaS quota")
+	}
+
+	required, err := bidepl.RequiredResources(deploymentManifest, deploymentState)
+	if err != nil {
+		return bosherr.WrapError(err, "Checking IaaS quota")
+	}
+
+	return bidepl.CheckQuota(required, quota)
+}
+
+func (c *DeploymentPreparer) checkWarnings() error {
+	count := len(c.warnings.List())
+	if !c.warningsAsErrors || count == 0 {
+		return nil
+	}
+
+	return bosherr.Errorf("Treating %d warning(s) as errors", count)
+}
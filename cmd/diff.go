@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+)
+
+// DiffCmd shows the changes deploying a manifest would make and fails when
+// there are any, so that it can be used to gate changes in CI
+type DiffCmd struct {
+	ui         boshui.UI
+	deployment boshdir.Deployment
+}
+
+func NewDiffCmd(ui boshui.UI, deployment boshdir.Deployment) DiffCmd {
+	return DiffCmd{ui: ui, deployment: deployment}
+}
+
+func (c DiffCmd) Run(opts DiffOpts) error {
+	tpl := boshtpl.NewTemplate(opts.Args.Manifest.Bytes)
+
+	bytes, err := tpl.Evaluate(opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp(), boshtpl.EvaluateOpts{})
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Evaluating manifest")
+	}
+
+	deploymentDiff, err := c.deployment.Diff(bytes, opts.NoRedact)
+	if err != nil {
+		return err
+	}
+
+	diff := NewDiff(deploymentDiff.Diff)
+
+	diff.Print(c.ui)
+
+	if changes := diff.ChangedLines(); changes > 0 {
+		return bosherr.Errorf("%d changed line(s) found", changes)
+	}
+
+	return nil
+}
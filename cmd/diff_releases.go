@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"sort"
+
+	bicrypto "github.com/cloudfoundry/bosh-cli/crypto"
+	boshrel "github.com/cloudfoundry/bosh-cli/release"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+type DiffReleasesCmd struct {
+	reader           boshrel.Reader
+	digestCalculator bicrypto.DigestCalculator
+	ui               boshui.UI
+}
+
+func NewDiffReleasesCmd(
+	reader boshrel.Reader,
+	digestCalculator bicrypto.DigestCalculator,
+	ui boshui.UI,
+) DiffReleasesCmd {
+	return DiffReleasesCmd{
+		reader:           reader,
+		digestCalculator: digestCalculator,
+		ui:               ui,
+	}
+}
+
+// releaseResource is the part of a job or package that identifies its contents
+type releaseResource struct {
+	Fingerprint   string
+	ArchiveDigest string
+}
+
+// releaseResourceKey tells apart source and compiled packages with the same name,
+// which may both be in a release; jobs have no kind
+type releaseResourceKey struct {
+	Name string
+	Kind string
+}
+
+func (c DiffReleasesCmd) Run(opts DiffReleasesOpts) error {
+	fromRelease, err := c.readRelease(opts.Args.FromPath)
+	if err != nil {
+		return err
+	}
+	defer c.cleanUp(fromRelease)
+
+	toRelease, err := c.readRelease(opts.Args.ToPath)
+	if err != nil {
+		return err
+	}
+	defer c.cleanUp(toRelease)
+
+	summaryTable, err := c.summaryTable(opts.Args, fromRelease, toRelease)
+	if err != nil {
+		return err
+	}
+
+	jobsTable := c.changesTable("jobs", "Job", "", c.jobResources(fromRelease), c.jobResources(toRelease))
+	pkgsTable := c.changesTable("packages", "Package", "Type", c.pkgResources(fromRelease), c.pkgResources(toRelease))
+
+	c.ui.PrintTable(summaryTable)
+	c.ui.PrintTable(jobsTable)
+	c.ui.PrintTable(pkgsTable)
+
+	return nil
+}
+
+func (c DiffReleasesCmd) readRelease(path string) (boshrel.Release, error) {
+	release, err := c.reader.Read(path)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Reading release '%s'", path)
+	}
+
+	return release, nil
+}
+
+func (c DiffReleasesCmd) cleanUp(release boshrel.Release) {
+	err := release.CleanUp()
+	if err != nil {
+		c.ui.ErrorLinef("Failed to clean up release '%s': %s", release.Name(), err.Error())
+	}
+}
+
+func (c DiffReleasesCmd) summaryTable(args DiffReleasesArgs, fromRelease, toRelease boshrel.Release) (boshtbl.Table, error) {
+	table := boshtbl.Table{
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Archive"),
+			boshtbl.NewHeader("Name"),
+			boshtbl.NewHeader("Version"),
+			boshtbl.NewHeader("Commit Hash"),
+			boshtbl.NewHeader("Digest"),
+		},
+	}
+
+	for _, archive := range []struct {
+		Path    string
+		Release boshrel.Release
+	}{
+		{args.FromPath, fromRelease},
+		{args.ToPath, toRelease},
+	} {
+		digest, err := c.digestCalculator.Calculate(archive.Path)
+		if err != nil {
+			return boshtbl.Table{}, bosherr.WrapErrorf(err, "Calculating digest of release '%s'", archive.Path)
+		}
+
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(archive.Path),
+			boshtbl.NewValueString(archive.Release.Name()),
+			boshtbl.NewValueString(archive.Release.Version()),
+			boshtbl.NewValueString(archive.Release.CommitHashWithMark("+")),
+			boshtbl.NewValueString(digest),
+		})
+	}
+
+	return table, nil
+}
+
+// changesTable lists resources that were added, removed or changed; unchanged resources are omitted.
+// The kind of resources is shown in a column after their name when kindHeader is not empty.
+func (c DiffReleasesCmd) changesTable(content, nameHeader, kindHeader string, fromResources, toResources map[releaseResourceKey]releaseResource) boshtbl.Table {
+	header := []boshtbl.Header{boshtbl.NewHeader(nameHeader)}
+	sortBy := []boshtbl.ColumnSort{{Column: 0, Asc: true}}
+
+	if len(kindHeader) > 0 {
+		header = append(header, boshtbl.NewHeader(kindHeader))
+		sortBy = append(sortBy, boshtbl.ColumnSort{Column: 1, Asc: true})
+	}
+
+	table := boshtbl.Table{
+		Content: content,
+		Header: append(header,
+			boshtbl.NewHeader("Change"),
+			boshtbl.NewHeader("Old Fingerprint"),
+			boshtbl.NewHeader("New Fingerprint"),
+			boshtbl.NewHeader("Old Digest"),
+			boshtbl.NewHeader("New Digest"),
+		),
+		SortBy: sortBy,
+	}
+
+	var keys []releaseResourceKey
+	for key := range fromResources {
+		keys = append(keys, key)
+	}
+	for key := range toResources {
+		if _, found := fromResources[key]; !found {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Name != keys[j].Name {
+			return keys[i].Name < keys[j].Name
+		}
+		return keys[i].Kind < keys[j].Kind
+	})
+
+	for _, key := range keys {
+		fromResource, inFrom := fromResources[key]
+		toResource, inTo := toResources[key]
+
+		var change string
+		switch {
+		case !inFrom:
+			change = "added"
+		case !inTo:
+			change = "removed"
+		case fromResource != toResource:
+			change = "changed"
+		default:
+			continue
+		}
+
+		row := []boshtbl.Value{boshtbl.NewValueString(key.Name)}
+		if len(kindHeader) > 0 {
+			row = append(row, boshtbl.NewValueString(key.Kind))
+		}
+
+		table.Rows = append(table.Rows, append(row,
+			boshtbl.NewValueString(change),
+			boshtbl.NewValueString(fromResource.Fingerprint),
+			boshtbl.NewValueString(toResource.Fingerprint),
+			boshtbl.NewValueString(fromResource.ArchiveDigest),
+			boshtbl.NewValueString(toResource.ArchiveDigest),
+		))
+	}
+
+	return table
+}
+
+func (c DiffReleasesCmd) jobResources(release boshrel.Release) map[releaseResourceKey]releaseResource {
+	resources := map[releaseResourceKey]releaseResource{}
+	for _, job := range release.Jobs() {
+		resources[releaseResourceKey{Name: job.Name()}] = releaseResource{
+			Fingerprint:   job.Fingerprint(),
+			ArchiveDigest: job.ArchiveDigest(),
+		}
+	}
+	return resources
+}
+
+func (c DiffReleasesCmd) pkgResources(release boshrel.Release) map[releaseResourceKey]releaseResource {
+	resources := map[releaseResourceKey]releaseResource{}
+	for _, pkg := range release.Packages() {
+		resources[releaseResourceKey{Name: pkg.Name(), Kind: "source"}] = releaseResource{
+			Fingerprint:   pkg.Fingerprint(),
+			ArchiveDigest: pkg.ArchiveDigest(),
+		}
+	}
+	for _, compiledPkg := range release.CompiledPackages() {
+		resources[releaseResourceKey{Name: compiledPkg.Name(), Kind: "compiled"}] = releaseResource{
+			Fingerprint:   compiledPkg.Fingerprint(),
+			ArchiveDigest: compiledPkg.ArchiveDigest(),
+		}
+	}
+	return resources
+}
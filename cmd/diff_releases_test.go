@@ -0,0 +1,238 @@
+package cmd_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	fakecrypto "github.com/cloudfoundry/bosh-cli/crypto/fakes"
+	boshrel "github.com/cloudfoundry/bosh-cli/release"
+	boshjob "github.com/cloudfoundry/bosh-cli/release/job"
+	boshpkg "github.com/cloudfoundry/bosh-cli/release/pkg"
+	fakerel "github.com/cloudfoundry/bosh-cli/release/releasefakes"
+	. "github.com/cloudfoundry/bosh-cli/release/resource"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+var _ = Describe("DiffReleasesCmd", func() {
+	var (
+		releaseReader    *fakerel.FakeReader
+		digestCalculator *fakecrypto.FakeDigestCalculator
+		ui               *fakeui.FakeUI
+		command          DiffReleasesCmd
+
+		fromRelease *fakerel.FakeRelease
+		toRelease   *fakerel.FakeRelease
+		opts        DiffReleasesOpts
+	)
+
+	newJob := func(name, fingerprint, digest string) *boshjob.Job {
+		return boshjob.NewJob(NewResourceWithBuiltArchive(name, fingerprint, "/"+name, digest))
+	}
+
+	newPkg := func(name, fingerprint, digest string) *boshpkg.Package {
+		return boshpkg.NewPackage(NewResourceWithBuiltArchive(name, fingerprint, "/"+name, digest), nil)
+	}
+
+	newCompiledPkg := func(name, fingerprint, digest string) *boshpkg.CompiledPackage {
+		return boshpkg.NewCompiledPackageWithArchive(name, fingerprint, "fake-os/1", "/"+name, digest, nil)
+	}
+
+	BeforeEach(func() {
+		releaseReader = &fakerel.FakeReader{}
+		digestCalculator = fakecrypto.NewFakeDigestCalculator()
+		ui = &fakeui.FakeUI{}
+		command = NewDiffReleasesCmd(releaseReader, digestCalculator, ui)
+
+		fromRelease = &fakerel.FakeRelease{}
+		fromRelease.NameReturns("fake-release")
+		fromRelease.VersionReturns("1")
+		fromRelease.CommitHashWithMarkReturns("abc123")
+		fromRelease.JobsReturns([]*boshjob.Job{
+			newJob("unchanged-job", "job-fp", "job-digest"),
+			newJob("changed-job", "old-job-fp", "old-job-digest"),
+			newJob("removed-job", "removed-job-fp", "removed-job-digest"),
+		})
+		fromRelease.PackagesReturns([]*boshpkg.Package{
+			newPkg("unchanged-pkg", "pkg-fp", "pkg-digest"),
+			newPkg("repacked-pkg", "repacked-pkg-fp", "sha1-digest"),
+			newPkg("recompiled-pkg", "recompiled-pkg-fp", "recompiled-pkg-digest"),
+		})
+		fromRelease.CompiledPackagesReturns([]*boshpkg.CompiledPackage{
+			newCompiledPkg("recompiled-pkg", "recompiled-pkg-fp", "old-compiled-digest"),
+		})
+
+		toRelease = &fakerel.FakeRelease{}
+		toRelease.NameReturns("fake-release")
+		toRelease.VersionReturns("2")
+		toRelease.CommitHashWithMarkReturns("def456+")
+		toRelease.JobsReturns([]*boshjob.Job{
+			newJob("unchanged-job", "job-fp", "job-digest"),
+			newJob("changed-job", "new-job-fp", "new-job-digest"),
+		})
+		toRelease.PackagesReturns([]*boshpkg.Package{
+			newPkg("unchanged-pkg", "pkg-fp", "pkg-digest"),
+			newPkg("repacked-pkg", "repacked-pkg-fp", "sha256:sha256-digest"),
+			newPkg("added-pkg", "added-pkg-fp", "added-pkg-digest"),
+			newPkg("recompiled-pkg", "recompiled-pkg-fp", "recompiled-pkg-digest"),
+		})
+		toRelease.CompiledPackagesReturns([]*boshpkg.CompiledPackage{
+			newCompiledPkg("recompiled-pkg", "recompiled-pkg-fp", "new-compiled-digest"),
+			newCompiledPkg("added-pkg", "added-pkg-fp", "added-compiled-digest"),
+		})
+
+		releaseReader.ReadStub = func(path string) (boshrel.Release, error) {
+			switch path {
+			case "/from.tgz":
+				return fromRelease, nil
+			case "/to.tgz":
+				return toRelease, nil
+			}
+			return nil, errors.New("fake-read-err")
+		}
+
+		digestCalculator.SetCalculateBehavior(map[string]fakecrypto.CalculateInput{
+			"/from.tgz": {DigestStr: "from-tarball-sha1"},
+			"/to.tgz":   {DigestStr: "to-tarball-sha1"},
+		})
+
+		opts = DiffReleasesOpts{Args: DiffReleasesArgs{FromPath: "/from.tgz", ToPath: "/to.tgz"}}
+	})
+
+	act := func() error { return command.Run(opts) }
+
+	It("prints both releases and the jobs and packages that differ", func() {
+		err := act()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ui.Tables).To(HaveLen(3))
+
+		Expect(ui.Tables[0].Rows).To(Equal([][]boshtbl.Value{
+			{
+				boshtbl.NewValueString("/from.tgz"),
+				boshtbl.NewValueString("fake-release"),
+				boshtbl.NewValueString("1"),
+				boshtbl.NewValueString("abc123"),
+				boshtbl.NewValueString("from-tarball-sha1"),
+			},
+			{
+				boshtbl.NewValueString("/to.tgz"),
+				boshtbl.NewValueString("fake-release"),
+				boshtbl.NewValueString("2"),
+				boshtbl.NewValueString("def456+"),
+				boshtbl.NewValueString("to-tarball-sha1"),
+			},
+		}))
+
+		Expect(ui.Tables[1]).To(Equal(boshtbl.Table{
+			Content: "jobs",
+			Header: []boshtbl.Header{
+				boshtbl.NewHeader("Job"),
+				boshtbl.NewHeader("Change"),
+				boshtbl.NewHeader("Old Fingerprint"),
+				boshtbl.NewHeader("New Fingerprint"),
+				boshtbl.NewHeader("Old Digest"),
+				boshtbl.NewHeader("New Digest"),
+			},
+			SortBy: []boshtbl.ColumnSort{{Column: 0, Asc: true}},
+			Rows: [][]boshtbl.Value{
+				{
+					boshtbl.NewValueString("changed-job"),
+					boshtbl.NewValueString("changed"),
+					boshtbl.NewValueString("old-job-fp"),
+					boshtbl.NewValueString("new-job-fp"),
+					boshtbl.NewValueString("old-job-digest"),
+					boshtbl.NewValueString("new-job-digest"),
+				},
+				{
+					boshtbl.NewValueString("removed-job"),
+					boshtbl.NewValueString("removed"),
+					boshtbl.NewValueString("removed-job-fp"),
+					boshtbl.NewValueString(""),
+					boshtbl.NewValueString("removed-job-digest"),
+					boshtbl.NewValueString(""),
+				},
+			},
+		}))
+
+		Expect(ui.Tables[2].Content).To(Equal("packages"))
+		Expect(ui.Tables[2].Header).To(Equal([]boshtbl.Header{
+			boshtbl.NewHeader("Package"),
+			boshtbl.NewHeader("Type"),
+			boshtbl.NewHeader("Change"),
+			boshtbl.NewHeader("Old Fingerprint"),
+			boshtbl.NewHeader("New Fingerprint"),
+			boshtbl.NewHeader("Old Digest"),
+			boshtbl.NewHeader("New Digest"),
+		}))
+		Expect(ui.Tables[2].Rows).To(Equal([][]boshtbl.Value{
+			{
+				boshtbl.NewValueString("added-pkg"),
+				boshtbl.NewValueString("compiled"),
+				boshtbl.NewValueString("added"),
+				boshtbl.NewValueString(""),
+				boshtbl.NewValueString("added-pkg-fp"),
+				boshtbl.NewValueString(""),
+				boshtbl.NewValueString("added-compiled-digest"),
+			},
+			{
+				boshtbl.NewValueString("added-pkg"),
+				boshtbl.NewValueString("source"),
+				boshtbl.NewValueString("added"),
+				boshtbl.NewValueString(""),
+				boshtbl.NewValueString("added-pkg-fp"),
+				boshtbl.NewValueString(""),
+				boshtbl.NewValueString("added-pkg-digest"),
+			},
+			{
+				boshtbl.NewValueString("recompiled-pkg"),
+				boshtbl.NewValueString("compiled"),
+				boshtbl.NewValueString("changed"),
+				boshtbl.NewValueString("recompiled-pkg-fp"),
+				boshtbl.NewValueString("recompiled-pkg-fp"),
+				boshtbl.NewValueString("old-compiled-digest"),
+				boshtbl.NewValueString("new-compiled-digest"),
+			},
+			{
+				boshtbl.NewValueString("repacked-pkg"),
+				boshtbl.NewValueString("source"),
+				boshtbl.NewValueString("changed"),
+				boshtbl.NewValueString("repacked-pkg-fp"),
+				boshtbl.NewValueString("repacked-pkg-fp"),
+				boshtbl.NewValueString("sha1-digest"),
+				boshtbl.NewValueString("sha256:sha256-digest"),
+			},
+		}))
+	})
+
+	It("cleans up both releases", func() {
+		err := act()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fromRelease.CleanUpCallCount()).To(Equal(1))
+		Expect(toRelease.CleanUpCallCount()).To(Equal(1))
+	})
+
+	It("returns an error if a release cannot be read", func() {
+		opts.Args.ToPath = "/missing.tgz"
+
+		err := act()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Reading release '/missing.tgz'"))
+		Expect(fromRelease.CleanUpCallCount()).To(Equal(1))
+	})
+
+	It("returns an error if a tarball digest cannot be calculated", func() {
+		digestCalculator.SetCalculateBehavior(map[string]fakecrypto.CalculateInput{
+			"/from.tgz": {Err: errors.New("fake-digest-err")},
+		})
+
+		err := act()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-digest-err"))
+		Expect(ui.Tables).To(BeEmpty())
+	})
+})
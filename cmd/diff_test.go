@@ -0,0 +1,113 @@
+package cmd_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	fakedir "github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+)
+
+var _ = Describe("DiffCmd", func() {
+	var (
+		ui         *fakeui.FakeUI
+		deployment *fakedir.FakeDeployment
+		command    DiffCmd
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		deployment = &fakedir.FakeDeployment{}
+		command = NewDiffCmd(ui, deployment)
+	})
+
+	Describe("Run", func() {
+		var (
+			opts DiffOpts
+		)
+
+		BeforeEach(func() {
+			opts = DiffOpts{
+				Args: DiffArgs{
+					Manifest: FileBytesArg{Bytes: []byte("name: dep\nreleases:\n- name: rel\n  version: ((version))\n")},
+				},
+				VarFlags: VarFlags{
+					VarKVs: []boshtpl.VarKV{{Name: "version", Value: 2}},
+				},
+			}
+		})
+
+		It("asks the director for a redacted diff of the interpolated manifest", func() {
+			err := command.Run(opts)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(deployment.DiffCallCount()).To(Equal(1))
+			bytes, noRedact := deployment.DiffArgsForCall(0)
+			Expect(string(bytes)).To(Equal("name: dep\nreleases:\n- name: rel\n  version: 2\n"))
+			Expect(noRedact).To(BeFalse())
+		})
+
+		It("asks the director for a diff without redaction when requested", func() {
+			opts.NoRedact = true
+
+			err := command.Run(opts)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, noRedact := deployment.DiffArgsForCall(0)
+			Expect(noRedact).To(BeTrue())
+		})
+
+		It("succeeds when the diff has no changes", func() {
+			deployment.DiffReturns(boshdir.NewDeploymentDiff([][]interface{}{
+				{"releases:", nil},
+				{"- name: rel", nil},
+			}, nil), nil)
+
+			err := command.Run(opts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ui.Said).To(Equal([]string{"  releases:\n", "  - name: rel\n"}))
+		})
+
+		It("prints the diff and returns an error when there are changes", func() {
+			deployment.DiffReturns(boshdir.NewDeploymentDiff([][]interface{}{
+				{"releases:", nil},
+				{"- name: rel", nil},
+				{"  version: 1", "removed"},
+				{"  version: 2", "added"},
+			}, nil), nil)
+
+			err := command.Run(opts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("2 changed line(s) found"))
+
+			Expect(ui.Said).To(Equal([]string{
+				"  releases:\n",
+				"  - name: rel\n",
+				"-   version: 1\n",
+				"+   version: 2\n",
+			}))
+		})
+
+		It("returns an error if diffing fails", func() {
+			deployment.DiffReturns(boshdir.DeploymentDiff{}, errors.New("fake-err"))
+
+			err := command.Run(opts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-err"))
+		})
+
+		It("returns an error if the new manifest cannot be evaluated", func() {
+			opts.Args.Manifest.Bytes = []byte("{")
+
+			err := command.Run(opts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Evaluating manifest"))
+			Expect(deployment.DiffCallCount()).To(Equal(0))
+		})
+	})
+})
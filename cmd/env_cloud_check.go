@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/cppforlife/go-patch/patch"
+
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+// envResolutionAuto picks the default resolution of each problem
+const envResolutionAuto = "auto"
+
+// EnvCloudCheckCmd is the create-env counterpart of cloud-check: it compares the
+// VM and disks recorded in the state file with the IaaS and resolves differences
+type EnvCloudCheckCmd struct {
+	ui          boshui.UI
+	envProvider func(string, string, boshtpl.Variables, patch.Op) EnvCloudChecker
+}
+
+func NewEnvCloudCheckCmd(ui boshui.UI, envProvider func(string, string, boshtpl.Variables, patch.Op) EnvCloudChecker) EnvCloudCheckCmd {
+	return EnvCloudCheckCmd{ui: ui, envProvider: envProvider}
+}
+
+func (c EnvCloudCheckCmd) Run(stage boshui.Stage, opts EnvCloudCheckOpts) error {
+	if opts.Args.Manifest.Stdin && len(opts.StatePath) == 0 {
+		return bosherr.Error("Expected --state to be given when reading the manifest from stdin")
+	}
+
+	removeManifest, err := opts.Args.Manifest.WriteTempFile()
+	if err != nil {
+		return err
+	}
+
+	defer removeManifest()
+
+	checker := c.envProvider(
+		opts.Args.Manifest.Path, opts.Args.Manifest.StatePath(opts.StatePath), opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp())
+
+	return checker.CheckAndResolve(stage, func(problems []EnvProblem) ([]EnvProblemAnswer, error) {
+		return c.resolve(problems, opts)
+	})
+}
+
+func (c EnvCloudCheckCmd) resolve(problems []EnvProblem, opts EnvCloudCheckOpts) ([]EnvProblemAnswer, error) {
+	table := boshtbl.Table{
+		Content: "problems",
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("#"),
+			boshtbl.NewHeader("Type"),
+			boshtbl.NewHeader("Description"),
+		},
+		SortBy: []boshtbl.ColumnSort{{Column: 0, Asc: true}},
+	}
+
+	for i, problem := range problems {
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueInt(i + 1),
+			boshtbl.NewValueString(problem.Type),
+			boshtbl.NewValueString(problem.Description),
+		})
+	}
+
+	c.ui.PrintTable(table)
+
+	if len(problems) == 0 {
+		return nil, nil
+	} else if opts.Report {
+		return nil, bosherr.Errorf("%d problem(s) found", len(problems))
+	}
+
+	var answers []EnvProblemAnswer
+
+	for _, problem := range problems {
+		var resolution EnvProblemResolution
+
+		if len(opts.Resolutions) > 0 {
+			resolution = c.findResolution(opts.Resolutions, problem)
+		} else {
+			var plans []string
+
+			for _, res := range problem.Resolutions {
+				plans = append(plans, res.Plan)
+			}
+
+			chosenIndex, err := c.ui.AskForChoice(problem.Description, plans)
+			if err != nil {
+				return nil, err
+			}
+
+			resolution = problem.Resolutions[chosenIndex]
+		}
+
+		answers = append(answers, EnvProblemAnswer{Problem: problem, Resolution: resolution})
+	}
+
+	err := c.ui.AskForConfirmation()
+	if err != nil {
+		return nil, err
+	}
+
+	return answers, nil
+}
+
+// findResolution prefers a resolution named explicitly over the default one picked by 'auto'
+func (c EnvCloudCheckCmd) findResolution(names []string, problem EnvProblem) EnvProblemResolution {
+	auto := false
+
+	for _, name := range names {
+		if name == envResolutionAuto {
+			auto = true
+			continue
+		}
+
+		for _, res := range problem.Resolutions {
+			if res.Name == name {
+				return res
+			}
+		}
+	}
+
+	if auto {
+		return problem.Resolutions[0]
+	}
+
+	return envResolutionIgnore
+}
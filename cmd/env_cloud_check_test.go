@@ -0,0 +1,222 @@
+package cmd_test
+
+import (
+	"errors"
+
+	"github.com/cppforlife/go-patch/patch"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+type fakeEnvCloudChecker struct {
+	problems []EnvProblem
+	checkErr error
+
+	resolverCalled bool
+	answers        []EnvProblemAnswer
+	resolverErr    error
+	stage          boshui.Stage
+}
+
+func (c *fakeEnvCloudChecker) CheckAndResolve(stage boshui.Stage, resolver EnvProblemResolver) error {
+	c.stage = stage
+
+	if c.checkErr != nil {
+		return c.checkErr
+	}
+
+	c.resolverCalled = true
+	c.answers, c.resolverErr = resolver(c.problems)
+	return c.resolverErr
+}
+
+var _ = Describe("EnvCloudCheckCmd", func() {
+	var (
+		ui           *fakeui.FakeUI
+		stage        *fakeui.FakeStage
+		checker      *fakeEnvCloudChecker
+		manifestPath string
+		statePath    string
+		command      EnvCloudCheckCmd
+	)
+
+	missingVM := EnvProblem{
+		Type:        "missing_vm",
+		CID:         "fake-vm-cid",
+		Description: "fake-vm-description",
+		Resolutions: []EnvProblemResolution{
+			{Name: "delete_vm_reference", Plan: "fake-delete-plan"},
+			{Name: "ignore", Plan: "Skip for now"},
+		},
+	}
+
+	unattachedDisk := EnvProblem{
+		Type:        "unattached_disk",
+		CID:         "fake-disk-cid",
+		Description: "fake-disk-description",
+		Resolutions: []EnvProblemResolution{
+			{Name: "reattach_disk", Plan: "fake-reattach-plan"},
+			{Name: "ignore", Plan: "Skip for now"},
+		},
+	}
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		stage = fakeui.NewFakeStage()
+		checker = &fakeEnvCloudChecker{problems: []EnvProblem{missingVM, unattachedDisk}}
+
+		envProvider := func(path string, state string, _ boshtpl.Variables, _ patch.Op) EnvCloudChecker {
+			manifestPath = path
+			statePath = state
+			return checker
+		}
+
+		command = NewEnvCloudCheckCmd(ui, envProvider)
+	})
+
+	opts := func() EnvCloudCheckOpts {
+		return EnvCloudCheckOpts{
+			Args:      EnvCloudCheckArgs{Manifest: EnvManifestArg{Path: "/fake-manifest.yml"}},
+			StatePath: "/fake-state.json",
+		}
+	}
+
+	It("prints problems and asks for a resolution of each", func() {
+		ui.AskedChoiceChosens = []int{1, 0}
+		ui.AskedChoiceErrs = []error{nil, nil}
+
+		err := command.Run(stage, opts())
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(manifestPath).To(Equal("/fake-manifest.yml"))
+		Expect(statePath).To(Equal("/fake-state.json"))
+		Expect(checker.stage).To(Equal(stage))
+
+		Expect(ui.Table).To(Equal(boshtbl.Table{
+			Content: "problems",
+			Header: []boshtbl.Header{
+				boshtbl.NewHeader("#"),
+				boshtbl.NewHeader("Type"),
+				boshtbl.NewHeader("Description"),
+			},
+			SortBy: []boshtbl.ColumnSort{{Column: 0, Asc: true}},
+			Rows: [][]boshtbl.Value{
+				{
+					boshtbl.NewValueInt(1),
+					boshtbl.NewValueString("missing_vm"),
+					boshtbl.NewValueString("fake-vm-description"),
+				},
+				{
+					boshtbl.NewValueInt(2),
+					boshtbl.NewValueString("unattached_disk"),
+					boshtbl.NewValueString("fake-disk-description"),
+				},
+			},
+		}))
+
+		Expect(ui.AskedChoiceLabel).To(Equal("fake-disk-description"))
+		Expect(ui.AskedChoiceOptions).To(Equal([]string{"fake-reattach-plan", "Skip for now"}))
+		Expect(ui.AskedConfirmationCalled).To(BeTrue())
+
+		Expect(checker.answers).To(Equal([]EnvProblemAnswer{
+			{Problem: missingVM, Resolution: missingVM.Resolutions[1]},
+			{Problem: unattachedDisk, Resolution: unattachedDisk.Resolutions[0]},
+		}))
+	})
+
+	It("applies the default resolution of each problem with --resolution auto", func() {
+		o := opts()
+		o.Resolutions = []string{"auto"}
+
+		err := command.Run(stage, o)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ui.AskedChoiceCalled).To(BeFalse())
+		Expect(checker.answers).To(Equal([]EnvProblemAnswer{
+			{Problem: missingVM, Resolution: missingVM.Resolutions[0]},
+			{Problem: unattachedDisk, Resolution: unattachedDisk.Resolutions[0]},
+		}))
+	})
+
+	It("skips problems that have none of the given resolutions", func() {
+		o := opts()
+		o.Resolutions = []string{"reattach_disk"}
+
+		err := command.Run(stage, o)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(checker.answers).To(Equal([]EnvProblemAnswer{
+			{Problem: missingVM, Resolution: EnvProblemResolution{Name: "ignore", Plan: "Skip for now"}},
+			{Problem: unattachedDisk, Resolution: unattachedDisk.Resolutions[0]},
+		}))
+	})
+
+	It("prefers given resolutions over the default ones picked by auto", func() {
+		o := opts()
+		o.Resolutions = []string{"auto", "ignore"}
+
+		err := command.Run(stage, o)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(checker.answers).To(Equal([]EnvProblemAnswer{
+			{Problem: missingVM, Resolution: missingVM.Resolutions[1]},
+			{Problem: unattachedDisk, Resolution: unattachedDisk.Resolutions[1]},
+		}))
+	})
+
+	It("only reports problems with --report", func() {
+		o := opts()
+		o.Report = true
+
+		err := command.Run(stage, o)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("2 problem(s) found"))
+
+		Expect(ui.Table.Rows).To(HaveLen(2))
+		Expect(ui.AskedChoiceCalled).To(BeFalse())
+		Expect(ui.AskedConfirmationCalled).To(BeFalse())
+	})
+
+	It("does not ask anything when there are no problems", func() {
+		checker.problems = nil
+
+		err := command.Run(stage, opts())
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ui.Table.Rows).To(BeEmpty())
+		Expect(ui.AskedConfirmationCalled).To(BeFalse())
+		Expect(checker.answers).To(BeEmpty())
+	})
+
+	It("does not resolve problems when confirmation is declined", func() {
+		o := opts()
+		o.Resolutions = []string{"auto"}
+		ui.AskedConfirmationErr = errors.New("stop")
+
+		err := command.Run(stage, o)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("stop"))
+		Expect(checker.answers).To(BeNil())
+	})
+
+	It("returns an error if checking fails", func() {
+		checker.checkErr = errors.New("fake-err")
+
+		err := command.Run(stage, opts())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("fake-err"))
+		Expect(checker.resolverCalled).To(BeFalse())
+	})
+
+	It("requires --state when reading the manifest from stdin", func() {
+		err := command.Run(stage, EnvCloudCheckOpts{Args: EnvCloudCheckArgs{Manifest: EnvManifestArg{Path: "-", Stdin: true}}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Expected --state"))
+	})
+})
@@ -0,0 +1,389 @@
+package cmd
+
+import (
+	"fmt"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/cppforlife/go-patch/patch"
+
+	bihttpagent "github.com/cloudfoundry/bosh-agent/agentclient/http"
+	bicloud "github.com/cloudfoundry/bosh-cli/cloud"
+	biconfig "github.com/cloudfoundry/bosh-cli/config"
+	bicpirel "github.com/cloudfoundry/bosh-cli/cpi/release"
+	bidisk "github.com/cloudfoundry/bosh-cli/deployment/disk"
+	bivm "github.com/cloudfoundry/bosh-cli/deployment/vm"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	biinstall "github.com/cloudfoundry/bosh-cli/installation"
+	biinstallmanifest "github.com/cloudfoundry/bosh-cli/installation/manifest"
+	birelsetmanifest "github.com/cloudfoundry/bosh-cli/release/set/manifest"
+	biui "github.com/cloudfoundry/bosh-cli/ui"
+)
+
+const (
+	EnvProblemMissingVM      = "missing_vm"
+	EnvProblemMissingDisk    = "missing_disk"
+	EnvProblemUnattachedDisk = "unattached_disk"
+
+	EnvResolutionIgnore              = "ignore"
+	EnvResolutionDeleteVMReference   = "delete_vm_reference"
+	EnvResolutionDeleteDiskReference = "delete_disk_reference"
+	EnvResolutionReattachDisk        = "reattach_disk"
+)
+
+// EnvProblem is a difference between the state file of an environment created
+// with create-env and what the IaaS reports
+type EnvProblem struct {
+	Type        string // e.g. "missing_vm"
+	CID         string // CID of the VM or disk the problem is about
+	Description string
+
+	// Resolutions start with the default one, which is safe to apply without asking
+	Resolutions []EnvProblemResolution
+}
+
+type EnvProblemResolution struct {
+	Name string // e.g. "delete_vm_reference"
+	Plan string // e.g. "Delete VM reference"
+}
+
+type EnvProblemAnswer struct {
+	Problem    EnvProblem
+	Resolution EnvProblemResolution
+}
+
+var envResolutionIgnore = EnvProblemResolution{Name: EnvResolutionIgnore, Plan: "Skip for now"}
+
+// EnvProblemResolver picks a resolution for each problem found, or returns
+// an error to stop before anything is changed
+type EnvProblemResolver func([]EnvProblem) ([]EnvProblemAnswer, error)
+
+type EnvCloudChecker interface {
+	// CheckAndResolve installs the CPI to find problems and applies the resolutions picked by resolver
+	CheckAndResolve(stage biui.Stage, resolver EnvProblemResolver) error
+}
+
+// EnvProblemScanner finds and resolves problems using an installed CPI
+type EnvProblemScanner struct {
+	cloud     bicloud.Cloud
+	vmManager bivm.Manager
+	vmRepo    biconfig.VMRepo
+	diskRepo  biconfig.DiskRepo
+	logTag    string
+	logger    boshlog.Logger
+}
+
+func NewEnvProblemScanner(
+	cloud bicloud.Cloud,
+	vmManager bivm.Manager,
+	vmRepo biconfig.VMRepo,
+	diskRepo biconfig.DiskRepo,
+	logger boshlog.Logger,
+) EnvProblemScanner {
+	return EnvProblemScanner{
+		cloud:     cloud,
+		vmManager: vmManager,
+		vmRepo:    vmRepo,
+		diskRepo:  diskRepo,
+		logTag:    "envProblemScanner",
+		logger:    logger,
+	}
+}
+
+func (s EnvProblemScanner) Scan() ([]EnvProblem, error) {
+	var problems []EnvProblem
+
+	vm, vmFound, err := s.vmManager.FindCurrent()
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Finding current VM")
+	}
+
+	if vmFound {
+		exists, err := vm.Exists()
+		if err != nil {
+			return nil, err
+		}
+
+		if !exists {
+			vmFound = false
+			problems = append(problems, EnvProblem{
+				Type:        EnvProblemMissingVM,
+				CID:         vm.CID(),
+				Description: fmt.Sprintf("VM '%s' is recorded in the state file but missing from the IaaS", vm.CID()),
+				Resolutions: []EnvProblemResolution{
+					{Name: EnvResolutionDeleteVMReference, Plan: "Delete VM reference, the next create-env recreates the VM"},
+					envResolutionIgnore,
+				},
+			})
+		}
+	}
+
+	diskRecords, err := s.diskRepo.All()
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Getting all disk records")
+	}
+
+	currentDiskRecord, currentDiskFound, err := s.diskRepo.FindCurrent()
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Finding current disk record")
+	}
+
+	for _, diskRecord := range diskRecords {
+		exists, err := s.cloud.HasDisk(diskRecord.CID)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Checking existence of disk '%s'", diskRecord.CID)
+		}
+
+		if !exists {
+			if currentDiskFound && diskRecord.ID == currentDiskRecord.ID {
+				currentDiskFound = false
+			}
+
+			problems = append(problems, EnvProblem{
+				Type:        EnvProblemMissingDisk,
+				CID:         diskRecord.CID,
+				Description: fmt.Sprintf("Disk '%s' is recorded in the state file but missing from the IaaS", diskRecord.CID),
+				// Forgetting a disk loses its data if the IaaS only failed to find it for now
+				Resolutions: []EnvProblemResolution{
+					envResolutionIgnore,
+					{Name: EnvResolutionDeleteDiskReference, Plan: "Delete disk reference"},
+				},
+			})
+		}
+	}
+
+	if !vmFound || !currentDiskFound {
+		return problems, nil
+	}
+
+	attachedDisks, err := vm.Disks()
+	if err != nil {
+		// An unresponsive agent should not hide the problems found so far
+		s.logger.Warn(s.logTag, "Skipping disk attachment check of VM '%s': %s", vm.CID(), err.Error())
+		return problems, nil
+	}
+
+	for _, attachedDisk := range attachedDisks {
+		if attachedDisk.CID() == currentDiskRecord.CID {
+			return problems, nil
+		}
+	}
+
+	problems = append(problems, EnvProblem{
+		Type:        EnvProblemUnattachedDisk,
+		CID:         currentDiskRecord.CID,
+		Description: fmt.Sprintf("Disk '%s' is not attached to VM '%s'", currentDiskRecord.CID, vm.CID()),
+		Resolutions: []EnvProblemResolution{
+			{Name: EnvResolutionReattachDisk, Plan: "Reattach disk to VM"},
+			envResolutionIgnore,
+		},
+	})
+
+	return problems, nil
+}
+
+func (s EnvProblemScanner) Resolve(answers []EnvProblemAnswer, stage biui.Stage) error {
+	for _, answer := range answers {
+		if answer.Resolution.Name == EnvResolutionIgnore {
+			continue
+		}
+
+		stepName := fmt.Sprintf("Resolving %s '%s' with %s", answer.Problem.Type, answer.Problem.CID, answer.Resolution.Name)
+
+		err := stage.Perform(stepName, func() error {
+			return s.resolve(answer)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s EnvProblemScanner) resolve(answer EnvProblemAnswer) error {
+	switch answer.Resolution.Name {
+	case EnvResolutionDeleteVMReference:
+		return s.vmRepo.ClearCurrent()
+
+	case EnvResolutionDeleteDiskReference:
+		diskRecord, found, err := s.diskRepo.Find(answer.Problem.CID)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Finding disk record '%s'", answer.Problem.CID)
+		}
+
+		if !found {
+			return nil
+		}
+
+		return s.diskRepo.Delete(diskRecord)
+
+	case EnvResolutionReattachDisk:
+		vm, found, err := s.vmManager.FindCurrent()
+		if err != nil {
+			return bosherr.WrapError(err, "Finding current VM")
+		}
+
+		if !found {
+			return bosherr.Error("No VM is deployed in the environment")
+		}
+
+		diskRecord, found, err := s.diskRepo.Find(answer.Problem.CID)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Finding disk record '%s'", answer.Problem.CID)
+		}
+
+		if !found {
+			return bosherr.Errorf("Disk '%s' is no longer recorded in the state file", answer.Problem.CID)
+		}
+
+		return vm.AttachDisk(bidisk.NewDisk(diskRecord, s.cloud, s.diskRepo))
+	}
+
+	return bosherr.Errorf("Unknown resolution '%s'", answer.Resolution.Name)
+}
+
+type envCloudChecker struct {
+	deploymentStateService                  biconfig.DeploymentStateService
+	vmRepo                                  biconfig.VMRepo
+	diskRepo                                biconfig.DiskRepo
+	vmManagerFactory                        bivm.ManagerFactory
+	agentClientFactory                      bihttpagent.AgentClientFactory
+	releaseManager                          biinstall.ReleaseManager
+	releaseFetcher                          biinstall.ReleaseFetcher
+	cpiInstaller                            bicpirel.CpiInstaller
+	cloudFactory                            bicloud.Factory
+	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser
+	tempRootConfigurator                    TempRootConfigurator
+	targetProvider                          biinstall.TargetProvider
+	deploymentManifestPath                  string
+	deploymentVars                          boshtpl.Variables
+	deploymentOp                            patch.Op
+	logTag                                  string
+	logger                                  boshlog.Logger
+}
+
+func NewEnvCloudChecker(
+	deploymentStateService biconfig.DeploymentStateService,
+	vmRepo biconfig.VMRepo,
+	diskRepo biconfig.DiskRepo,
+	vmManagerFactory bivm.ManagerFactory,
+	agentClientFactory bihttpagent.AgentClientFactory,
+	releaseManager biinstall.ReleaseManager,
+	releaseFetcher biinstall.ReleaseFetcher,
+	cpiInstaller bicpirel.CpiInstaller,
+	cloudFactory bicloud.Factory,
+	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser,
+	tempRootConfigurator TempRootConfigurator,
+	targetProvider biinstall.TargetProvider,
+	deploymentManifestPath string,
+	deploymentVars boshtpl.Variables,
+	deploymentOp patch.Op,
+	logger boshlog.Logger,
+) EnvCloudChecker {
+	return envCloudChecker{
+		deploymentStateService:                  deploymentStateService,
+		vmRepo:                                  vmRepo,
+		diskRepo:                                diskRepo,
+		vmManagerFactory:                        vmManagerFactory,
+		agentClientFactory:                      agentClientFactory,
+		releaseManager:                          releaseManager,
+		releaseFetcher:                          releaseFetcher,
+		cpiInstaller:                            cpiInstaller,
+		cloudFactory:                            cloudFactory,
+		releaseSetAndInstallationManifestParser: releaseSetAndInstallationManifestParser,
+		tempRootConfigurator:                    tempRootConfigurator,
+		targetProvider:                          targetProvider,
+		deploymentManifestPath:                  deploymentManifestPath,
+		deploymentVars:                          deploymentVars,
+		deploymentOp:                            deploymentOp,
+		logTag:                                  "envCloudChecker",
+		logger:                                  logger,
+	}
+}
+
+func (c envCloudChecker) CheckAndResolve(stage biui.Stage, resolver EnvProblemResolver) error {
+	deploymentState, err := loadExistingDeploymentState(c.deploymentStateService)
+	if err != nil {
+		return err
+	}
+
+	target, err := c.targetProvider.NewTarget()
+	if err != nil {
+		return bosherr.WrapError(err, "Determining installation target")
+	}
+
+	err = c.tempRootConfigurator.PrepareAndSetTempRoot(target.TmpPath(), c.logger)
+	if err != nil {
+		return bosherr.WrapError(err, "Setting temp root")
+	}
+
+	defer func() {
+		err := c.releaseManager.DeleteAll()
+		if err != nil {
+			c.logger.Warn(c.logTag, "Deleting all extracted releases: %s", err.Error())
+		}
+	}()
+
+	var installationManifest biinstallmanifest.Manifest
+
+	err = stage.PerformComplex("validating", func(stage biui.Stage) error {
+		var releaseSetManifest birelsetmanifest.Manifest
+		releaseSetManifest, installationManifest, err = c.releaseSetAndInstallationManifestParser.ReleaseSetAndInstallationManifest(
+			c.deploymentManifestPath, c.deploymentVars, c.deploymentOp)
+		if err != nil {
+			return err
+		}
+
+		cpiReleaseName := installationManifest.Template.Release
+		cpiReleaseRef, found := releaseSetManifest.FindByName(cpiReleaseName)
+		if !found {
+			return bosherr.Errorf("installation release '%s' must refer to a release in releases", cpiReleaseName)
+		}
+
+		err = c.releaseFetcher.DownloadAndExtract(cpiReleaseRef, stage)
+		if err != nil {
+			return err
+		}
+
+		return c.cpiInstaller.ValidateCpiRelease(installationManifest, stage)
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.cpiInstaller.WithInstalledCpiRelease(installationManifest, target, stage, func(installation biinstall.Installation) error {
+		return installation.WithRunningRegistry(c.logger, stage, func() error {
+			cloud, err := c.cloudFactory.NewCloud(installation, deploymentState.DirectorID)
+			if err != nil {
+				return bosherr.WrapError(err, "Creating CPI client from CPI installation")
+			}
+
+			agentClient, err := c.agentClientFactory.NewAgentClient(
+				deploymentState.DirectorID, installationManifest.Mbus, installationManifest.Cert.CA)
+			if err != nil {
+				return err
+			}
+
+			scanner := NewEnvProblemScanner(
+				cloud, c.vmManagerFactory.NewManager(cloud, agentClient), c.vmRepo, c.diskRepo, c.logger)
+
+			var problems []EnvProblem
+
+			err = stage.Perform("Scanning for problems", func() error {
+				problems, err = scanner.Scan()
+				return err
+			})
+			if err != nil {
+				return err
+			}
+
+			answers, err := resolver(problems)
+			if err != nil {
+				return err
+			}
+
+			return scanner.Resolve(answers, stage)
+		})
+	})
+}
@@ -0,0 +1,190 @@
+package cmd_test
+
+import (
+	"errors"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	fakebicloud "github.com/cloudfoundry/bosh-cli/cloud/fakes"
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	biconfig "github.com/cloudfoundry/bosh-cli/config"
+	bidisk "github.com/cloudfoundry/bosh-cli/deployment/disk"
+	fakebivm "github.com/cloudfoundry/bosh-cli/deployment/vm/fakes"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+)
+
+var _ = Describe("EnvProblemScanner", func() {
+	var (
+		fs        *fakesys.FakeFileSystem
+		cloud     *fakebicloud.FakeCloud
+		vmManager *fakebivm.FakeManager
+		vm        *fakebivm.FakeVM
+		vmRepo    biconfig.VMRepo
+		diskRepo  biconfig.DiskRepo
+		stage     *fakeui.FakeStage
+		scanner   EnvProblemScanner
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		uuidGenerator := &fakeuuid.FakeGenerator{}
+
+		fs.WriteFileString("/deployment-state.json", `{
+	"director_id": "fake-director-id",
+	"current_vm_cid": "fake-vm-cid",
+	"current_disk_id": "fake-disk-id",
+	"disks": [{"id": "fake-disk-id", "cid": "fake-disk-cid", "size": 1024}]
+}`)
+
+		deploymentStateService := biconfig.NewFileSystemDeploymentStateService(fs, uuidGenerator, logger, "/deployment-state.json")
+		vmRepo = biconfig.NewVMRepo(deploymentStateService)
+		diskRepo = biconfig.NewDiskRepo(deploymentStateService, uuidGenerator)
+
+		cloud = fakebicloud.NewFakeCloud()
+		cloud.HasDiskFound = true
+
+		vm = fakebivm.NewFakeVM("fake-vm-cid")
+		vm.ExistsFound = true
+		vm.ListDisksDisks = []bidisk.Disk{bidisk.NewDisk(biconfig.DiskRecord{CID: "fake-disk-cid"}, nil, nil)}
+
+		vmManager = fakebivm.NewFakeManager()
+		vmManager.SetFindCurrentBehavior(vm, true, nil)
+
+		stage = fakeui.NewFakeStage()
+
+		scanner = NewEnvProblemScanner(cloud, vmManager, vmRepo, diskRepo, logger)
+	})
+
+	Describe("Scan", func() {
+		It("finds no problems when the VM and disk exist and the disk is attached", func() {
+			problems, err := scanner.Scan()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(problems).To(BeEmpty())
+
+			Expect(cloud.HasDiskInput).To(Equal(fakebicloud.HasDiskInput{DiskCID: "fake-disk-cid"}))
+		})
+
+		It("finds a missing VM", func() {
+			vm.ExistsFound = false
+
+			problems, err := scanner.Scan()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(problems).To(HaveLen(1))
+			Expect(problems[0].Type).To(Equal("missing_vm"))
+			Expect(problems[0].CID).To(Equal("fake-vm-cid"))
+			Expect(problems[0].Resolutions[0].Name).To(Equal("delete_vm_reference"))
+		})
+
+		It("finds a missing disk without checking whether it is attached", func() {
+			cloud.HasDiskFound = false
+			vm.ListDisksDisks = nil
+
+			problems, err := scanner.Scan()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(problems).To(HaveLen(1))
+			Expect(problems[0].Type).To(Equal("missing_disk"))
+			Expect(problems[0].CID).To(Equal("fake-disk-cid"))
+			Expect(problems[0].Resolutions[0].Name).To(Equal("ignore"))
+			Expect(problems[0].Resolutions[1].Name).To(Equal("delete_disk_reference"))
+		})
+
+		It("finds a current disk that is not attached to the VM", func() {
+			vm.ListDisksDisks = nil
+
+			problems, err := scanner.Scan()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(problems).To(HaveLen(1))
+			Expect(problems[0].Type).To(Equal("unattached_disk"))
+			Expect(problems[0].CID).To(Equal("fake-disk-cid"))
+			Expect(problems[0].Resolutions[0].Name).To(Equal("reattach_disk"))
+		})
+
+		It("skips the attachment check when the agent does not respond", func() {
+			vm.ListDisksErr = errors.New("fake-agent-err")
+
+			problems, err := scanner.Scan()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(problems).To(BeEmpty())
+		})
+
+		It("returns an error if checking the VM fails", func() {
+			vm.ExistsErr = errors.New("fake-has-vm-err")
+
+			_, err := scanner.Scan()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-has-vm-err"))
+		})
+
+		It("returns an error if checking a disk fails", func() {
+			cloud.HasDiskErr = errors.New("fake-has-disk-err")
+
+			_, err := scanner.Scan()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-has-disk-err"))
+		})
+	})
+
+	Describe("Resolve", func() {
+		answer := func(problemType, cid, resolution string) EnvProblemAnswer {
+			return EnvProblemAnswer{
+				Problem:    EnvProblem{Type: problemType, CID: cid},
+				Resolution: EnvProblemResolution{Name: resolution},
+			}
+		}
+
+		It("deletes the VM reference", func() {
+			err := scanner.Resolve([]EnvProblemAnswer{answer("missing_vm", "fake-vm-cid", "delete_vm_reference")}, stage)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, found, err := vmRepo.FindCurrent()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeFalse())
+
+			Expect(stage.PerformCalls).To(HaveLen(1))
+			Expect(stage.PerformCalls[0].Name).To(Equal("Resolving missing_vm 'fake-vm-cid' with delete_vm_reference"))
+		})
+
+		It("deletes the disk reference", func() {
+			err := scanner.Resolve([]EnvProblemAnswer{answer("missing_disk", "fake-disk-cid", "delete_disk_reference")}, stage)
+			Expect(err).ToNot(HaveOccurred())
+
+			records, err := diskRepo.All()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(records).To(BeEmpty())
+
+			_, found, err := diskRepo.FindCurrent()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+
+		It("reattaches the disk to the VM", func() {
+			err := scanner.Resolve([]EnvProblemAnswer{answer("unattached_disk", "fake-disk-cid", "reattach_disk")}, stage)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(vm.AttachDiskInputs).To(HaveLen(1))
+			Expect(vm.AttachDiskInputs[0].Disk.CID()).To(Equal("fake-disk-cid"))
+		})
+
+		It("skips ignored problems", func() {
+			err := scanner.Resolve([]EnvProblemAnswer{answer("missing_vm", "fake-vm-cid", "ignore")}, stage)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, found, err := vmRepo.FindCurrent()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+
+			Expect(stage.PerformCalls).To(BeEmpty())
+		})
+
+		It("returns an error for an unknown resolution", func() {
+			err := scanner.Resolve([]EnvProblemAnswer{answer("missing_vm", "fake-vm-cid", "fake-resolution")}, stage)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unknown resolution 'fake-resolution'"))
+		})
+	})
+})
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/cppforlife/go-patch/patch"
+
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+// EnvDisksCmd lists the persistent disks recorded in the state file of an environment
+// created with create-env and deletes the ones no longer in use
+type EnvDisksCmd struct {
+	ui          boshui.UI
+	envProvider func(string, string, boshtpl.Variables, patch.Op) EnvDisksManager
+}
+
+func NewEnvDisksCmd(ui boshui.UI, envProvider func(string, string, boshtpl.Variables, patch.Op) EnvDisksManager) EnvDisksCmd {
+	return EnvDisksCmd{ui: ui, envProvider: envProvider}
+}
+
+func (c EnvDisksCmd) Run(stage boshui.Stage, opts EnvDisksOpts) error {
+	if opts.Args.Manifest.Stdin && len(opts.StatePath) == 0 {
+		return bosherr.Error("Expected --state to be given when reading the manifest from stdin")
+	}
+
+	if opts.Delete && !opts.Orphaned {
+		return bosherr.Error("Expected --orphaned to be given with --delete")
+	}
+
+	removeManifest, err := opts.Args.Manifest.WriteTempFile()
+	if err != nil {
+		return err
+	}
+
+	defer removeManifest()
+
+	manager := c.envProvider(
+		opts.Args.Manifest.Path, opts.Args.Manifest.StatePath(opts.StatePath), opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp())
+
+	disks, err := manager.ListDisks()
+	if err != nil {
+		return err
+	}
+
+	table := boshtbl.Table{
+		Content: "disks",
+
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Disk CID"),
+			boshtbl.NewHeader("Size"),
+			boshtbl.NewHeader("Tags"),
+			boshtbl.NewHeader("Orphaned"),
+		},
+
+		SortBy: []boshtbl.ColumnSort{{Column: 0, Asc: true}},
+	}
+
+	for _, disk := range disks {
+		if opts.Orphaned && !disk.Orphaned {
+			continue
+		}
+
+		var orphaned boshtbl.Value = boshtbl.NewValueString("no")
+		if disk.Orphaned {
+			orphaned = boshtbl.NewValueFmt(boshtbl.NewValueString("yes"), true)
+		}
+
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(disk.CID),
+			boshtbl.NewValueMegaBytes(uint64(disk.Size)),
+			boshtbl.NewValueStrings(disk.TagStrings()),
+			orphaned,
+		})
+	}
+
+	c.ui.PrintTable(table)
+
+	if !opts.Delete || len(table.Rows) == 0 {
+		return nil
+	}
+
+	err = c.ui.AskForConfirmation()
+	if err != nil {
+		return err
+	}
+
+	return manager.DeleteOrphanedDisks(stage)
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"sort"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/cppforlife/go-patch/patch"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/cloud"
+	biconfig "github.com/cloudfoundry/bosh-cli/config"
+	bicpirel "github.com/cloudfoundry/bosh-cli/cpi/release"
+	bidisk "github.com/cloudfoundry/bosh-cli/deployment/disk"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	biinstall "github.com/cloudfoundry/bosh-cli/installation"
+	biinstallmanifest "github.com/cloudfoundry/bosh-cli/installation/manifest"
+	birelsetmanifest "github.com/cloudfoundry/bosh-cli/release/set/manifest"
+	biui "github.com/cloudfoundry/bosh-cli/ui"
+)
+
+// EnvDisk is a persistent disk recorded in the state file of an environment created with create-env
+type EnvDisk struct {
+	CID  string
+	Size int
+	Tags map[string]string
+
+	// Orphaned is true when the disk is not the current persistent disk,
+	// e.g. because a deploy failed before it could be deleted
+	Orphaned bool
+}
+
+// TagStrings returns the disk's tags as sorted key=value strings
+func (d EnvDisk) TagStrings() []string {
+	var tags []string
+
+	for key, value := range d.Tags {
+		tags = append(tags, key+"="+value)
+	}
+
+	sort.Strings(tags)
+
+	return tags
+}
+
+type EnvDisksManager interface {
+	ListDisks() ([]EnvDisk, error)
+
+	// DeleteOrphanedDisks installs the CPI to delete orphaned disks and their records
+	DeleteOrphanedDisks(stage biui.Stage) error
+}
+
+type envDisksManager struct {
+	deploymentStateService                  biconfig.DeploymentStateService
+	diskRepo                                biconfig.DiskRepo
+	diskManagerFactory                      bidisk.ManagerFactory
+	releaseManager                          biinstall.ReleaseManager
+	releaseFetcher                          biinstall.ReleaseFetcher
+	cpiInstaller                            bicpirel.CpiInstaller
+	cloudFactory                            bicloud.Factory
+	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser
+	tempRootConfigurator                    TempRootConfigurator
+	targetProvider                          biinstall.TargetProvider
+	deploymentManifestPath                  string
+	deploymentVars                          boshtpl.Variables
+	deploymentOp                            patch.Op
+	logTag                                  string
+	logger                                  boshlog.Logger
+}
+
+func NewEnvDisksManager(
+	deploymentStateService biconfig.DeploymentStateService,
+	diskRepo biconfig.DiskRepo,
+	diskManagerFactory bidisk.ManagerFactory,
+	releaseManager biinstall.ReleaseManager,
+	releaseFetcher biinstall.ReleaseFetcher,
+	cpiInstaller bicpirel.CpiInstaller,
+	cloudFactory bicloud.Factory,
+	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser,
+	tempRootConfigurator TempRootConfigurator,
+	targetProvider biinstall.TargetProvider,
+	deploymentManifestPath string,
+	deploymentVars boshtpl.Variables,
+	deploymentOp patch.Op,
+	logger boshlog.Logger,
+) EnvDisksManager {
+	return envDisksManager{
+		deploymentStateService:                  deploymentStateService,
+		diskRepo:                                diskRepo,
+		diskManagerFactory:                      diskManagerFactory,
+		releaseManager:                          releaseManager,
+		releaseFetcher:                          releaseFetcher,
+		cpiInstaller:                            cpiInstaller,
+		cloudFactory:                            cloudFactory,
+		releaseSetAndInstallationManifestParser: releaseSetAndInstallationManifestParser,
+		tempRootConfigurator:                    tempRootConfigurator,
+		targetProvider:                          targetProvider,
+		deploymentManifestPath:                  deploymentManifestPath,
+		deploymentVars:                          deploymentVars,
+		deploymentOp:                            deploymentOp,
+		logTag:                                  "envDisksManager",
+		logger:                                  logger,
+	}
+}
+
+func (m envDisksManager) ListDisks() ([]EnvDisk, error) {
+	_, err := loadExistingDeploymentState(m.deploymentStateService)
+	if err != nil {
+		return nil, err
+	}
+
+	diskRecords, err := m.diskRepo.All()
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Getting all disk records")
+	}
+
+	currentDiskRecord, found, err := m.diskRepo.FindCurrent()
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Finding current disk record")
+	}
+
+	var disks []EnvDisk
+
+	for _, diskRecord := range diskRecords {
+		disks = append(disks, EnvDisk{
+			CID:      diskRecord.CID,
+			Size:     diskRecord.Size,
+			Tags:     diskRecord.Tags,
+			Orphaned: !found || diskRecord.ID != currentDiskRecord.ID,
+		})
+	}
+
+	return disks, nil
+}
+
+func (m envDisksManager) DeleteOrphanedDisks(stage biui.Stage) error {
+	deploymentState, err := m.deploymentStateService.Load()
+	if err != nil {
+		return bosherr.WrapError(err, "Loading deployment state")
+	}
+
+	target, err := m.targetProvider.NewTarget()
+	if err != nil {
+		return bosherr.WrapError(err, "Determining installation target")
+	}
+
+	err = m.tempRootConfigurator.PrepareAndSetTempRoot(target.TmpPath(), m.logger)
+	if err != nil {
+		return bosherr.WrapError(err, "Setting temp root")
+	}
+
+	defer func() {
+		err := m.releaseManager.DeleteAll()
+		if err != nil {
+			m.logger.Warn(m.logTag, "Deleting all extracted releases: %s", err.Error())
+		}
+	}()
+
+	var installationManifest biinstallmanifest.Manifest
+
+	err = stage.PerformComplex("validating", func(stage biui.Stage) error {
+		var releaseSetManifest birelsetmanifest.Manifest
+		releaseSetManifest, installationManifest, err = m.releaseSetAndInstallationManifestParser.ReleaseSetAndInstallationManifest(
+			m.deploymentManifestPath, m.deploymentVars, m.deploymentOp)
+		if err != nil {
+			return err
+		}
+
+		cpiReleaseName := installationManifest.Template.Release
+		cpiReleaseRef, found := releaseSetManifest.FindByName(cpiReleaseName)
+		if !found {
+			return bosherr.Errorf("installation release '%s' must refer to a release in releases", cpiReleaseName)
+		}
+
+		err = m.releaseFetcher.DownloadAndExtract(cpiReleaseRef, stage)
+		if err != nil {
+			return err
+		}
+
+		return m.cpiInstaller.ValidateCpiRelease(installationManifest, stage)
+	})
+	if err != nil {
+		return err
+	}
+
+	return m.cpiInstaller.WithInstalledCpiRelease(installationManifest, target, stage, func(installation biinstall.Installation) error {
+		return installation.WithRunningRegistry(m.logger, stage, func() error {
+			cloud, err := m.cloudFactory.NewCloud(installation, deploymentState.DirectorID)
+			if err != nil {
+				return bosherr.WrapError(err, "Creating CPI client from CPI installation")
+			}
+
+			return m.diskManagerFactory.NewManager(cloud).DeleteUnused(stage)
+		})
+	})
+}
@@ -0,0 +1,88 @@
+package cmd_test
+
+import (
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	"github.com/cppforlife/go-patch/patch"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	biconfig "github.com/cloudfoundry/bosh-cli/config"
+	bicpirel "github.com/cloudfoundry/bosh-cli/cpi/release"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	biinstall "github.com/cloudfoundry/bosh-cli/installation"
+)
+
+var _ = Describe("EnvDisksManager", func() {
+	var (
+		fs      *fakesys.FakeFileSystem
+		manager EnvDisksManager
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		uuidGenerator := &fakeuuid.FakeGenerator{}
+
+		deploymentStateService := biconfig.NewFileSystemDeploymentStateService(fs, uuidGenerator, logger, "/deployment-state.json")
+
+		manager = NewEnvDisksManager(
+			deploymentStateService,
+			biconfig.NewDiskRepo(deploymentStateService, uuidGenerator),
+			nil,
+			nil,
+			biinstall.ReleaseFetcher{},
+			bicpirel.CpiInstaller{},
+			nil,
+			ReleaseSetAndInstallationManifestParser{},
+			nil,
+			nil,
+			"/deployment.yml",
+			boshtpl.StaticVariables{},
+			patch.Ops{},
+			logger,
+		)
+	})
+
+	Describe("ListDisks", func() {
+		It("returns an error without creating a state file when there is none", func() {
+			_, err := manager.ListDisks()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("No deployment state file found at '/deployment-state.json'"))
+			Expect(fs.FileExists("/deployment-state.json")).To(BeFalse())
+		})
+
+		It("lists disks other than the current disk as orphaned", func() {
+			fs.WriteFileString("/deployment-state.json", `{
+	"director_id": "fake-director-id",
+	"current_disk_id": "fake-current-disk-id",
+	"disks": [
+		{"id": "fake-current-disk-id", "cid": "fake-current-disk-cid", "size": 1024, "tags": {"team": "core"}},
+		{"id": "fake-orphaned-disk-id", "cid": "fake-orphaned-disk-cid", "size": 2048}
+	]
+}`)
+
+			disks, err := manager.ListDisks()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(disks).To(Equal([]EnvDisk{
+				{CID: "fake-current-disk-cid", Size: 1024, Tags: map[string]string{"team": "core"}},
+				{CID: "fake-orphaned-disk-cid", Size: 2048, Orphaned: true},
+			}))
+		})
+
+		It("lists all disks as orphaned when there is no current disk", func() {
+			fs.WriteFileString("/deployment-state.json", `{
+	"director_id": "fake-director-id",
+	"disks": [{"id": "fake-disk-id", "cid": "fake-disk-cid", "size": 1024}]
+}`)
+
+			disks, err := manager.ListDisks()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(disks).To(Equal([]EnvDisk{
+				{CID: "fake-disk-cid", Size: 1024, Orphaned: true},
+			}))
+		})
+	})
+})
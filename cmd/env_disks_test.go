@@ -0,0 +1,194 @@
+package cmd_test
+
+import (
+	"errors"
+
+	"github.com/cppforlife/go-patch/patch"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+type fakeEnvDisksManager struct {
+	disks   []EnvDisk
+	listErr error
+
+	deleteCalled bool
+	deleteStage  boshui.Stage
+	deleteErr    error
+}
+
+func (m *fakeEnvDisksManager) ListDisks() ([]EnvDisk, error) {
+	return m.disks, m.listErr
+}
+
+func (m *fakeEnvDisksManager) DeleteOrphanedDisks(stage boshui.Stage) error {
+	m.deleteCalled = true
+	m.deleteStage = stage
+	return m.deleteErr
+}
+
+var _ = Describe("EnvDisksCmd", func() {
+	var (
+		ui           *fakeui.FakeUI
+		stage        *fakeui.FakeStage
+		manager      *fakeEnvDisksManager
+		manifestPath string
+		statePath    string
+		command      EnvDisksCmd
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		stage = fakeui.NewFakeStage()
+		manager = &fakeEnvDisksManager{
+			disks: []EnvDisk{
+				{CID: "fake-current-disk-cid", Size: 1024, Tags: map[string]string{"team": "core", "env": "prod"}},
+				{CID: "fake-orphaned-disk-cid", Size: 2048, Orphaned: true},
+			},
+		}
+
+		envProvider := func(path string, state string, _ boshtpl.Variables, _ patch.Op) EnvDisksManager {
+			manifestPath = path
+			statePath = state
+			return manager
+		}
+
+		command = NewEnvDisksCmd(ui, envProvider)
+	})
+
+	opts := func() EnvDisksOpts {
+		return EnvDisksOpts{
+			Args:      EnvDisksArgs{Manifest: EnvManifestArg{Path: "/fake-manifest.yml"}},
+			StatePath: "/fake-state.json",
+		}
+	}
+
+	header := []boshtbl.Header{
+		boshtbl.NewHeader("Disk CID"),
+		boshtbl.NewHeader("Size"),
+		boshtbl.NewHeader("Tags"),
+		boshtbl.NewHeader("Orphaned"),
+	}
+
+	orphanedRow := []boshtbl.Value{
+		boshtbl.NewValueString("fake-orphaned-disk-cid"),
+		boshtbl.NewValueMegaBytes(2048),
+		boshtbl.NewValueStrings(nil),
+		boshtbl.NewValueFmt(boshtbl.NewValueString("yes"), true),
+	}
+
+	It("prints current and orphaned disks", func() {
+		err := command.Run(stage, opts())
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(manifestPath).To(Equal("/fake-manifest.yml"))
+		Expect(statePath).To(Equal("/fake-state.json"))
+
+		Expect(ui.Table).To(Equal(boshtbl.Table{
+			Content: "disks",
+			Header:  header,
+			SortBy:  []boshtbl.ColumnSort{{Column: 0, Asc: true}},
+			Rows: [][]boshtbl.Value{
+				{
+					boshtbl.NewValueString("fake-current-disk-cid"),
+					boshtbl.NewValueMegaBytes(1024),
+					boshtbl.NewValueStrings([]string{"env=prod", "team=core"}),
+					boshtbl.NewValueString("no"),
+				},
+				orphanedRow,
+			},
+		}))
+
+		Expect(ui.AskedConfirmationCalled).To(BeFalse())
+		Expect(manager.deleteCalled).To(BeFalse())
+	})
+
+	It("prints only orphaned disks with --orphaned", func() {
+		o := opts()
+		o.Orphaned = true
+
+		err := command.Run(stage, o)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ui.Table.Rows).To(Equal([][]boshtbl.Value{orphanedRow}))
+		Expect(manager.deleteCalled).To(BeFalse())
+	})
+
+	Context("with --orphaned --delete", func() {
+		var deleteOpts EnvDisksOpts
+
+		BeforeEach(func() {
+			deleteOpts = opts()
+			deleteOpts.Orphaned = true
+			deleteOpts.Delete = true
+		})
+
+		It("deletes orphaned disks after confirming", func() {
+			err := command.Run(stage, deleteOpts)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Table.Rows).To(Equal([][]boshtbl.Value{orphanedRow}))
+			Expect(ui.AskedConfirmationCalled).To(BeTrue())
+			Expect(manager.deleteCalled).To(BeTrue())
+			Expect(manager.deleteStage).To(Equal(stage))
+		})
+
+		It("does not delete disks when confirmation is declined", func() {
+			ui.AskedConfirmationErr = errors.New("stop")
+
+			err := command.Run(stage, deleteOpts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("stop"))
+
+			Expect(manager.deleteCalled).To(BeFalse())
+		})
+
+		It("does not ask for confirmation when no disk is orphaned", func() {
+			manager.disks = []EnvDisk{{CID: "fake-current-disk-cid", Size: 1024}}
+
+			err := command.Run(stage, deleteOpts)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.AskedConfirmationCalled).To(BeFalse())
+			Expect(manager.deleteCalled).To(BeFalse())
+		})
+
+		It("returns an error if deleting fails", func() {
+			manager.deleteErr = errors.New("fake-err")
+
+			err := command.Run(stage, deleteOpts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("fake-err"))
+		})
+	})
+
+	It("requires --orphaned with --delete", func() {
+		o := opts()
+		o.Delete = true
+
+		err := command.Run(stage, o)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Expected --orphaned"))
+		Expect(manager.deleteCalled).To(BeFalse())
+	})
+
+	It("returns an error if listing disks fails", func() {
+		manager.listErr = errors.New("fake-err")
+
+		err := command.Run(stage, opts())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("fake-err"))
+	})
+
+	It("requires --state when reading the manifest from stdin", func() {
+		err := command.Run(stage, EnvDisksOpts{Args: EnvDisksArgs{Manifest: EnvManifestArg{Path: "-", Stdin: true}}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Expected --state"))
+	})
+})
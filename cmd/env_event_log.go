@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	"github.com/cloudfoundry/bosh-cli/eventlog"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// envEventLog optionally records the stages of create-env or delete-env
+// and writes them to an event log once the command finishes, even if it fails
+type envEventLog struct {
+	deps    BasicDeps
+	command string
+	path    string
+	vars    boshtpl.Variables
+
+	// stageEvents, if set, is told about stages as they happen, e.g. to stream them with --json
+	stageEvents boshui.StageEventRecorder
+
+	// warnings are set by the env provider once the command has created them
+	warnings biwarn.Warnings
+
+	logTag string
+}
+
+func newEnvEventLog(deps BasicDeps, command string, path string, vars boshtpl.Variables, stageEvents boshui.StageEventRecorder) *envEventLog {
+	return &envEventLog{
+		deps:        deps,
+		command:     command,
+		path:        path,
+		vars:        vars,
+		stageEvents: stageEvents,
+		logTag:      "envEventLog",
+	}
+}
+
+func (l *envEventLog) Run(run func(boshui.Stage) error) error {
+	if l.path == "" {
+		if l.stageEvents != nil {
+			return run(boshui.NewRecordingStage(l.deps.UI, l.deps.Time, l.deps.Logger, l.stageEvents))
+		}
+
+		return run(boshui.NewStage(l.deps.UI, l.deps.Time, l.deps.Logger))
+	}
+
+	correlationID, err := l.deps.UUIDGen.Generate()
+	if err != nil {
+		return bosherr.WrapError(err, "Generating event log correlation ID")
+	}
+
+	l.deps.Logger.Info(l.logTag, "Recording %s events with correlation ID '%s'", l.command, correlationID)
+
+	recorder := eventlog.NewRecorder(l.command, VersionLabel, correlationID, l.vars, l.deps.FS, l.deps.Logger)
+
+	var stageRecorder boshui.StageEventRecorder = recorder
+	if l.stageEvents != nil {
+		stageRecorder = boshui.NewMultiStageEventRecorder(recorder, l.stageEvents)
+	}
+
+	runErr := run(boshui.NewRecordingStage(l.deps.UI, l.deps.Time, l.deps.Logger, stageRecorder))
+
+	var warnings []biwarn.Warning
+	if l.warnings != nil {
+		warnings = l.warnings.List()
+	}
+
+	err = recorder.Write(l.path, warnings, runErr)
+	if err != nil {
+		if runErr != nil {
+			l.deps.UI.ErrorLinef("Failed to write event log: %s", err.Error())
+			return runErr
+		}
+		return err
+	}
+
+	l.deps.UI.PrintLinef("Event log: '%s' (correlation ID '%s')", l.path, correlationID)
+
+	return runErr
+}
@@ -1,17 +1,21 @@
 package cmd
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"runtime"
 	"time"
 
 	"github.com/cppforlife/go-patch/patch"
 
 	bihttpagent "github.com/cloudfoundry/bosh-agent/agentclient/http"
+	boshagentclient "github.com/cloudfoundry/bosh-cli/agentclient"
 	biblobstore "github.com/cloudfoundry/bosh-cli/blobstore"
 	bicloud "github.com/cloudfoundry/bosh-cli/cloud"
 	biconfig "github.com/cloudfoundry/bosh-cli/config"
 	bicpirel "github.com/cloudfoundry/bosh-cli/cpi/release"
+	bicrypto "github.com/cloudfoundry/bosh-cli/crypto"
 	bidepl "github.com/cloudfoundry/bosh-cli/deployment"
 	bidisk "github.com/cloudfoundry/bosh-cli/deployment/disk"
 	biinstance "github.com/cloudfoundry/bosh-cli/deployment/instance"
@@ -26,13 +30,19 @@ import (
 	boshinst "github.com/cloudfoundry/bosh-cli/installation"
 	boshinstmanifest "github.com/cloudfoundry/bosh-cli/installation/manifest"
 	bitarball "github.com/cloudfoundry/bosh-cli/installation/tarball"
+	"github.com/cloudfoundry/bosh-cli/offline"
 	biregistry "github.com/cloudfoundry/bosh-cli/registry"
 	boshrel "github.com/cloudfoundry/bosh-cli/release"
 	birelsetmanifest "github.com/cloudfoundry/bosh-cli/release/set/manifest"
+	biretry "github.com/cloudfoundry/bosh-cli/retry"
 	bistatepkg "github.com/cloudfoundry/bosh-cli/state/pkg"
 	bistemcell "github.com/cloudfoundry/bosh-cli/stemcell"
 	bitemplate "github.com/cloudfoundry/bosh-cli/templatescompiler"
 	bitemplateerb "github.com/cloudfoundry/bosh-cli/templatescompiler/erbrenderer"
+	biui "github.com/cloudfoundry/bosh-cli/ui"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
+	boshblob "github.com/cloudfoundry/bosh-utils/blobstore"
+	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
 	"github.com/cloudfoundry/bosh-utils/httpclient"
 )
 
@@ -42,6 +52,13 @@ type envFactory struct {
 	manifestVars boshtpl.Variables
 	manifestOp   patch.Op
 
+	cloudPropertiesOverrides []CloudPropertiesOverrideArg
+
+	warnings biwarn.Warnings
+
+	// retrier retries CPI calls and agent blobstore transfers alike
+	retrier biretry.Retrier
+
 	deploymentStateService     biconfig.DeploymentStateService
 	installationManifestParser ReleaseSetAndInstallationManifestParser
 
@@ -53,17 +70,136 @@ type envFactory struct {
 	targetProvider boshinst.TargetProvider
 	cloudFactory   bicloud.Factory
 
+	vmRepo                 biconfig.VMRepo
+	diskRepo               biconfig.DiskRepo
 	diskManagerFactory     bidisk.ManagerFactory
 	vmManagerFactory       bivm.ManagerFactory
 	stemcellManagerFactory bistemcell.ManagerFactory
 
 	instanceManagerFactory   biinstance.ManagerFactory
+	sshTunnelPool            bisshtunnel.PoolingFactory
 	deploymentManagerFactory bidepl.ManagerFactory
 
 	agentClientFactory bihttpagent.AgentClientFactory
 	blobstoreFactory   biblobstore.Factory
 	deploymentFactory  bidepl.Factory
 	deploymentRecord   bidepl.Record
+
+	// workers is the number of releases that are fetched and packages that are compiled at a time
+	workers int
+}
+
+// AgentOpts configures how often agent tasks are polled and how long
+// to wait for the agent of a new instance to become ready
+type AgentOpts struct {
+	PollInterval time.Duration
+	ReadyTimeout time.Duration
+
+	// Context interrupts running agent calls once it is canceled
+	Context      context.Context
+	CallTimeouts boshagentclient.CallTimeouts
+
+	// BlobstorePartSize uploads blobs larger than it to the agent in parts; 0 uploads them at once
+	BlobstorePartSize int64
+}
+
+func NewDefaultAgentOpts() AgentOpts {
+	return AgentOpts{
+		PollInterval: 1 * time.Second,
+		ReadyTimeout: 10 * time.Minute,
+		Context:      context.Background(),
+		CallTimeouts: boshagentclient.NewDefaultCallTimeouts(),
+	}
+}
+
+// EnvFactoryOpts configures the environment that an envFactory builds dependencies for.
+// Commands that only read the environment use NewDefaultEnvFactoryOpts.
+type EnvFactoryOpts struct {
+	RecreatePersistentDisks bool
+	ReextractStemcell       bool
+	RerenderTemplates       bool
+	DryRun                  bool
+
+	CompiledPackageIndexPath string
+	CompiledPackageCachePath string
+
+	// TmpRootPath is given with --tmp-dir and TmpDirPath is the directory of the run below it
+	TmpRootPath string
+	TmpDirPath  string
+
+	InstallationBlobstore  boshblob.Blobstore
+	InstallationIndexStore biindex.FileStore
+
+	CloudPropertiesOverrides   []CloudPropertiesOverrideArg
+	CPIRecording               bicloud.CPIRecordingOpts
+	RetryConfig                biretry.Config
+	CPITimeouts                bicloud.CPIMethodTimeouts
+	CPIAPIVersion              int
+	AdvertisedRegistryEndpoint string
+
+	StreamCompileLogs             bool
+	DeterministicCompiledPackages bool
+
+	// Workers below 1 fetch and compile with one worker per CPU
+	Workers int
+
+	TarballMirrors []bitarball.Mirror
+	OfflineGuard   *offline.Guard
+	AgentOpts      AgentOpts
+}
+
+func NewDefaultEnvFactoryOpts(tmpRootPath, tmpDirPath string) EnvFactoryOpts {
+	return EnvFactoryOpts{
+		TmpRootPath: tmpRootPath,
+		TmpDirPath:  tmpDirPath,
+		RetryConfig: biretry.NewDefaultConfig(),
+		CPITimeouts: bicloud.NewDefaultCPIMethodTimeouts(),
+		Workers:     1,
+		AgentOpts:   NewDefaultAgentOpts(),
+	}
+}
+
+func NewCreateEnvFactoryOpts(opts CreateEnvOpts, tmpRootPath, tmpDirPath string) EnvFactoryOpts {
+	factoryOpts := NewDefaultEnvFactoryOpts(tmpRootPath, tmpDirPath)
+
+	factoryOpts.RecreatePersistentDisks = opts.RecreatePersistentDisks
+	factoryOpts.ReextractStemcell = opts.Reextract
+	factoryOpts.RerenderTemplates = opts.Rerender
+	factoryOpts.DryRun = opts.DryRun
+	factoryOpts.CompiledPackageIndexPath = opts.CompiledPackageIndex
+	factoryOpts.CompiledPackageCachePath = opts.CompiledPackageCache
+	factoryOpts.CloudPropertiesOverrides = opts.CloudPropertiesOverrides
+	factoryOpts.CPIRecording = bicloud.CPIRecordingOpts{RecordPath: opts.RecordCPI, ReplayPath: opts.ReplayCPI}
+	factoryOpts.RetryConfig.Retries = opts.Retries
+	factoryOpts.RetryConfig.Delay = opts.RetryDelay
+	factoryOpts.CPITimeouts = NewCPIMethodTimeouts(opts.CPITimeouts)
+	factoryOpts.CPIAPIVersion = opts.CPIAPIVersion
+	factoryOpts.AdvertisedRegistryEndpoint = opts.AdvertisedRegistryEndpoint
+	factoryOpts.StreamCompileLogs = opts.StreamCompileLogs
+	factoryOpts.DeterministicCompiledPackages = opts.DeterministicCompiledPackages
+	factoryOpts.Workers = opts.Workers
+	factoryOpts.TarballMirrors = NewTarballMirrors(opts.Mirrors)
+	factoryOpts.AgentOpts.PollInterval = time.Duration(opts.AgentPollInterval)
+	factoryOpts.AgentOpts.ReadyTimeout = time.Duration(opts.AgentReadyTimeout)
+	factoryOpts.AgentOpts.BlobstorePartSize = opts.BlobstorePartSize
+
+	return factoryOpts
+}
+
+func NewDeleteEnvFactoryOpts(opts DeleteEnvOpts, tmpRootPath, tmpDirPath string) EnvFactoryOpts {
+	factoryOpts := NewDefaultEnvFactoryOpts(tmpRootPath, tmpDirPath)
+
+	factoryOpts.CompiledPackageIndexPath = opts.CompiledPackageIndex
+	factoryOpts.CompiledPackageCachePath = opts.CompiledPackageCache
+	factoryOpts.CPIRecording = bicloud.CPIRecordingOpts{RecordPath: opts.RecordCPI, ReplayPath: opts.ReplayCPI}
+	factoryOpts.RetryConfig.Retries = opts.Retries
+	factoryOpts.RetryConfig.Delay = opts.RetryDelay
+	factoryOpts.CPITimeouts = NewCPIMethodTimeouts(opts.CPITimeouts)
+	factoryOpts.CPIAPIVersion = opts.CPIAPIVersion
+	factoryOpts.Workers = 0
+	factoryOpts.TarballMirrors = NewTarballMirrors(opts.Mirrors)
+
+	return factoryOpts
 }
 
 func NewEnvFactory(
@@ -72,27 +208,65 @@ func NewEnvFactory(
 	statePath string,
 	manifestVars boshtpl.Variables,
 	manifestOp patch.Op,
-	recreatePersistentDisks bool,
+	opts EnvFactoryOpts,
 ) *envFactory {
+	if opts.Workers < 1 {
+		opts.Workers = runtime.NumCPU()
+	}
+
 	f := envFactory{
 		deps:         deps,
 		manifestPath: manifestPath,
 		manifestVars: manifestVars,
 		manifestOp:   manifestOp,
+
+		cloudPropertiesOverrides: opts.CloudPropertiesOverrides,
+
+		warnings: biwarn.NewWarnings(deps.Logger),
+
+		workers: opts.Workers,
 	}
 
+	f.retrier = biretry.NewRetrier(opts.RetryConfig, deps.Time, f.warnings, deps.Logger)
+
 	f.releaseManager = boshinst.NewReleaseManager(deps.Logger)
 	releaseJobResolver := bideplrel.NewJobResolver(f.releaseManager)
 
 	// todo expand path?
 	workspaceRootPath := filepath.Join(os.Getenv("HOME"), ".bosh")
 
+	// Stemcells are extracted below --tmp-dir when given, outside the
+	// directory of the run so that they stay cached once it is removed
+	stemcellsPath := filepath.Join(workspaceRootPath, "stemcells")
+	if opts.TmpRootPath != "" {
+		stemcellsPath = filepath.Join(opts.TmpRootPath, "stemcells")
+	}
+
+	// The CPI is compiled in the directory of the run
+	installationsTmpPath := ""
+	if opts.TmpDirPath != "" {
+		installationsTmpPath = filepath.Join(opts.TmpDirPath, "installations")
+	}
+
 	{
 		tarballCacheBasePath := filepath.Join(workspaceRootPath, "downloads")
 		tarballCache := bitarball.NewCache(tarballCacheBasePath, deps.FS, deps.Logger)
 		httpClient := httpclient.NewHTTPClient(httpclient.CreateDefaultClient(nil), deps.Logger)
+		downloadAttempts := 3
+
+		if opts.OfflineGuard != nil {
+			httpClient = httpclient.NewHTTPClient(opts.OfflineGuard.HTTPClient(), deps.Logger)
+			downloadAttempts = 1
+		}
+
+		// Progress of concurrent downloads would be drawn over each other
+		progress := deps.UI.ProgressReporter(deps.Time)
+		if opts.Workers > 1 {
+			progress = biui.NewNoopProgressReporter()
+		}
+
 		tarballProvider := bitarball.NewProvider(
-			tarballCache, deps.FS, httpClient, 3, 500*time.Millisecond, deps.Logger)
+			tarballCache, deps.FS, httpClient, opts.TarballMirrors, downloadAttempts, 500*time.Millisecond, progress, deps.Logger)
 
 		releaseProvider := boshrel.NewProvider(
 			deps.CmdRunner, deps.Compressor, deps.DigestCalculator, deps.FS, deps.Logger)
@@ -104,7 +278,16 @@ func NewEnvFactory(
 		)
 
 		stemcellReader := bistemcell.NewReader(deps.Compressor, deps.FS)
-		stemcellExtractor := bistemcell.NewExtractor(stemcellReader, deps.FS)
+		stemcellExtractor := bistemcell.NewCachingExtractor(
+			stemcellReader,
+			deps.Compressor,
+			bicrypto.NewDigestCalculator(deps.FS, []boshcrypto.Algorithm{boshcrypto.DigestAlgorithmSHA1}),
+			deps.FS,
+			stemcellsPath,
+			bistemcell.DefaultCachedStemcells,
+			opts.ReextractStemcell,
+			deps.Logger,
+		)
 
 		f.stemcellFetcher = bistemcell.Fetcher{
 			TarballProvider:   tarballProvider,
@@ -112,14 +295,24 @@ func NewEnvFactory(
 		}
 	}
 
-	f.deploymentStateService = biconfig.NewFileSystemDeploymentStateService(
-		deps.FS, deps.UUIDGen, deps.Logger, biconfig.DeploymentStatePath(manifestPath, statePath))
+	if opts.DryRun {
+		f.deploymentStateService = biconfig.NewDryRunDeploymentStateService(
+			deps.FS, deps.UUIDGen, deps.Logger, biconfig.DeploymentStatePath(manifestPath, statePath))
+	} else {
+		f.deploymentStateService = biconfig.NewFileSystemDeploymentStateService(
+			deps.FS, deps.UUIDGen, deps.Logger, biconfig.DeploymentStatePath(manifestPath, statePath))
+	}
 
 	{
+		if opts.OfflineGuard != nil && opts.InstallationBlobstore != nil {
+			opts.InstallationBlobstore = offlineInstallationBlobstore{blobstore: opts.InstallationBlobstore, guard: opts.OfflineGuard}
+			opts.InstallationIndexStore = offlineIndexStore{guard: opts.OfflineGuard}
+		}
+
 		registryServer := biregistry.NewServerManager(deps.Logger)
 		installerFactory := boshinst.NewInstallerFactory(
 			deps.UI, deps.CmdRunner, deps.Compressor, releaseJobResolver,
-			deps.UUIDGen, registryServer, deps.Logger, deps.FS, deps.DigestCreationAlgorithms)
+			deps.UUIDGen, registryServer, deps.Logger, deps.FS, deps.DigestCreationAlgorithms, opts.StreamCompileLogs, opts.DeterministicCompiledPackages, opts.Workers, opts.InstallationBlobstore, opts.InstallationIndexStore)
 
 		f.cpiInstaller = bicpirel.CpiInstaller{
 			ReleaseManager:   f.releaseManager,
@@ -129,19 +322,20 @@ func NewEnvFactory(
 	}
 
 	f.targetProvider = boshinst.NewTargetProvider(
-		f.deploymentStateService, deps.UUIDGen, filepath.Join(workspaceRootPath, "installations"))
+		f.deploymentStateService, deps.UUIDGen, filepath.Join(workspaceRootPath, "installations"),
+		opts.CompiledPackageIndexPath, opts.CompiledPackageCachePath, installationsTmpPath, deps.FS)
 
 	{
-		diskRepo := biconfig.NewDiskRepo(f.deploymentStateService, deps.UUIDGen)
+		f.diskRepo = biconfig.NewDiskRepo(f.deploymentStateService, deps.UUIDGen)
 		stemcellRepo := biconfig.NewStemcellRepo(f.deploymentStateService, deps.UUIDGen)
-		vmRepo := biconfig.NewVMRepo(f.deploymentStateService)
+		f.vmRepo = biconfig.NewVMRepo(f.deploymentStateService)
 
-		f.diskManagerFactory = bidisk.NewManagerFactory(diskRepo, deps.Logger)
-		diskDeployer := bivm.NewDiskDeployer(f.diskManagerFactory, diskRepo, deps.Logger, recreatePersistentDisks)
+		f.diskManagerFactory = bidisk.NewManagerFactory(f.diskRepo, deps.Logger)
+		diskDeployer := bivm.NewDiskDeployer(f.diskManagerFactory, f.diskRepo, deps.Logger, opts.RecreatePersistentDisks)
 
 		f.stemcellManagerFactory = bistemcell.NewManagerFactory(stemcellRepo)
 		f.vmManagerFactory = bivm.NewManagerFactory(
-			vmRepo, stemcellRepo, diskDeployer, deps.UUIDGen, deps.FS, deps.Logger)
+			f.vmRepo, stemcellRepo, diskDeployer, deps.UUIDGen, deps.FS, f.warnings, deps.Logger)
 
 		deploymentRepo := biconfig.NewDeploymentRepo(f.deploymentStateService)
 		releaseRepo := biconfig.NewReleaseRepo(f.deploymentStateService, deps.UUIDGen)
@@ -149,14 +343,24 @@ func NewEnvFactory(
 	}
 
 	{
-		f.blobstoreFactory = biblobstore.NewBlobstoreFactory(deps.UUIDGen, deps.FS, deps.Logger)
-		f.deploymentFactory = bidepl.NewFactory(10*time.Second, 500*time.Millisecond)
-		f.agentClientFactory = bihttpagent.NewAgentClientFactory(1*time.Second, deps.Logger)
-		f.cloudFactory = bicloud.NewFactory(deps.FS, deps.CmdRunner, deps.Logger)
+		f.blobstoreFactory = biblobstore.NewBlobstoreFactory(deps.UUIDGen, deps.FS, f.retrier, opts.AgentOpts.BlobstorePartSize, deps.UI.ProgressReporter(deps.Time), deps.Logger)
+		f.deploymentFactory = bidepl.NewFactory(10*time.Second, 500*time.Millisecond, deps.Time, f.warnings, deps.Logger)
+		f.agentClientFactory = boshagentclient.NewCancelableAgentClientFactory(
+			bihttpagent.NewAgentClientFactory(opts.AgentOpts.PollInterval, deps.Logger), opts.AgentOpts.Context, opts.AgentOpts.CallTimeouts)
+		cpiDigestCalculator := bicrypto.NewDigestCalculator(deps.FS, []boshcrypto.Algorithm{boshcrypto.DigestAlgorithmSHA256})
+		f.cloudFactory = bicloud.NewFactory(deps.FS, deps.CmdRunner, opts.CPITimeouts, deps.Time, cpiDigestCalculator, opts.CPIRecording, f.retrier, opts.CPIAPIVersion, deps.Logger)
 	}
 
 	{
-		erbRenderer := bitemplateerb.NewERBRenderer(deps.FS, deps.CmdRunner, deps.Logger)
+		erbRenderer := bitemplateerb.NewCachingERBRenderer(
+			bitemplateerb.NewERBRenderer(deps.FS, deps.CmdRunner, deps.Logger),
+			deps.FS,
+			deps.Time,
+			filepath.Join(workspaceRootPath, "templates"),
+			bitemplateerb.DefaultTemplateCacheMaxAge,
+			opts.RerenderTemplates,
+			deps.Logger,
+		)
 		jobRenderer := bitemplate.NewJobRenderer(erbRenderer, deps.FS, deps.UUIDGen, deps.Logger)
 
 		builderFactory := biinstancestate.NewBuilderFactory(
@@ -167,11 +371,11 @@ func NewEnvFactory(
 			deps.Logger,
 		)
 
-		sshTunnelFactory := bisshtunnel.NewFactory(deps.Logger)
-		instanceFactory := biinstance.NewFactory(builderFactory)
+		f.sshTunnelPool = bisshtunnel.NewPoolingFactory(bisshtunnel.NewFactory(deps.Logger), deps.Logger)
+		instanceFactory := biinstance.NewFactory(builderFactory, opts.AgentOpts.ReadyTimeout)
 
 		f.instanceManagerFactory = biinstance.NewManagerFactory(
-			sshTunnelFactory, instanceFactory, deps.Logger)
+			f.sshTunnelPool, instanceFactory, deps.Logger)
 	}
 
 	{
@@ -179,7 +383,7 @@ func NewEnvFactory(
 		releaseSetParser := birelsetmanifest.NewParser(deps.FS, deps.Logger, releaseSetValidator)
 
 		installValidator := boshinstmanifest.NewValidator(deps.Logger)
-		installParser := boshinstmanifest.NewParser(deps.FS, deps.UUIDGen, deps.Logger, installValidator)
+		installParser := boshinstmanifest.NewParser(deps.FS, deps.UUIDGen, deps.Logger, installValidator, opts.AdvertisedRegistryEndpoint)
 
 		f.installationManifestParser = ReleaseSetAndInstallationManifestParser{
 			ReleaseSetParser:   releaseSetParser,
@@ -190,11 +394,14 @@ func NewEnvFactory(
 	return &f
 }
 
-func (f *envFactory) Preparer() DeploymentPreparer {
+func (f *envFactory) Preparer(warningsAsErrors bool) DeploymentPreparer {
+	deploymentValidator := bideplmanifest.NewValidator(f.warnings, f.deps.Logger)
+
 	return NewDeploymentPreparer(
 		f.deps.UI,
 		f.deps.Logger,
 		"DeploymentPreparer",
+		f.deps.FS,
 		f.deploymentStateService,
 		biconfig.NewLegacyDeploymentStateMigrator(
 			f.deploymentStateService,
@@ -215,6 +422,7 @@ func (f *envFactory) Preparer() DeploymentPreparer {
 			f.deploymentFactory,
 			f.deps.Logger,
 		),
+		f.sshTunnelPool,
 		f.manifestPath,
 		f.manifestVars,
 		f.manifestOp,
@@ -223,17 +431,105 @@ func (f *envFactory) Preparer() DeploymentPreparer {
 		f.stemcellFetcher,
 		f.installationManifestParser,
 		NewDeploymentManifestParser(
-			bideplmanifest.NewParser(f.deps.FS, f.deps.Logger),
-			bideplmanifest.NewValidator(f.deps.Logger),
+			bideplmanifest.NewParser(f.deps.FS, f.warnings, f.deps.Logger),
+			deploymentValidator,
 			f.releaseManager,
 			bidepltpl.NewDeploymentTemplateFactory(f.deps.FS),
+			f.cloudPropertiesOverrides,
+			f.deps.Logger,
 		),
+		deploymentValidator,
+		NewTempRootConfigurator(f.deps.FS),
+		f.targetProvider,
+		f.warnings,
+		warningsAsErrors,
+		f.workers,
+	)
+}
+
+func (f *envFactory) InstancesLister() EnvInstancesLister {
+	return NewEnvInstancesLister(
+		f.deploymentStateService,
+		biconfig.NewVMRepo(f.deploymentStateService),
+		f.agentClientFactory,
+		f.installationManifestParser,
+		bidepltpl.NewDeploymentTemplateFactory(f.deps.FS),
+		bideplmanifest.NewParser(f.deps.FS, f.warnings, f.deps.Logger),
+		f.manifestPath,
+		f.manifestVars,
+		f.manifestOp,
+		f.deps.Logger,
+	)
+}
+
+func (f *envFactory) InfoLoader() EnvInfoLoader {
+	return NewEnvInfoLoader(
+		f.deploymentStateService,
+		f.agentClientFactory,
+		f.installationManifestParser,
+		f.manifestPath,
+		f.manifestVars,
+		f.manifestOp,
+		f.deps.Logger,
+	)
+}
+
+func (f *envFactory) LogsFetcher() EnvLogsFetcher {
+	return NewEnvLogsFetcher(
+		f.deploymentStateService,
+		f.agentClientFactory,
+		f.blobstoreFactory,
+		f.installationManifestParser,
+		f.deps.FS,
+		f.deps.Time,
+		f.manifestPath,
+		f.manifestVars,
+		f.manifestOp,
+		f.deps.Logger,
+	)
+}
+
+func (f *envFactory) DisksManager() EnvDisksManager {
+	return NewEnvDisksManager(
+		f.deploymentStateService,
+		f.diskRepo,
+		f.diskManagerFactory,
+		f.releaseManager,
+		f.releaseFetcher,
+		f.cpiInstaller,
+		f.cloudFactory,
+		f.installationManifestParser,
 		NewTempRootConfigurator(f.deps.FS),
 		f.targetProvider,
+		f.manifestPath,
+		f.manifestVars,
+		f.manifestOp,
+		f.deps.Logger,
+	)
+}
+
+func (f *envFactory) CloudChecker() EnvCloudChecker {
+	return NewEnvCloudChecker(
+		f.deploymentStateService,
+		f.vmRepo,
+		f.diskRepo,
+		f.vmManagerFactory,
+		f.agentClientFactory,
+		f.releaseManager,
+		f.releaseFetcher,
+		f.cpiInstaller,
+		f.cloudFactory,
+		f.installationManifestParser,
+		NewTempRootConfigurator(f.deps.FS),
+		f.targetProvider,
+		f.manifestPath,
+		f.manifestVars,
+		f.manifestOp,
+		f.deps.Logger,
 	)
 }
 
-func (f *envFactory) Deleter() DeploymentDeleter {
+func (f *envFactory) Deleter(confirmDestroy DestroyConfirmation) DeploymentDeleter {
 	return NewDeploymentDeleter(
 		f.deps.UI,
 		"DeploymentDeleter",
@@ -259,5 +555,7 @@ func (f *envFactory) Deleter() DeploymentDeleter {
 		f.installationManifestParser,
 		NewTempRootConfigurator(f.deps.FS),
 		f.targetProvider,
+		f.warnings,
+		confirmDestroy,
 	)
 }
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/cppforlife/go-patch/patch"
+
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+// EnvInfoCmd shows what is deployed in an environment created with create-env,
+// using its state file and pinging the agent instead of asking a director
+type EnvInfoCmd struct {
+	ui          boshui.UI
+	envProvider func(string, string, boshtpl.Variables, patch.Op) EnvInfoLoader
+}
+
+func NewEnvInfoCmd(ui boshui.UI, envProvider func(string, string, boshtpl.Variables, patch.Op) EnvInfoLoader) EnvInfoCmd {
+	return EnvInfoCmd{ui: ui, envProvider: envProvider}
+}
+
+func (c EnvInfoCmd) Run(opts EnvInfoOpts) error {
+	if opts.Args.Manifest.Stdin && len(opts.StatePath) == 0 {
+		return bosherr.Error("Expected --state to be given when reading the manifest from stdin")
+	}
+
+	removeManifest, err := opts.Args.Manifest.WriteTempFile()
+	if err != nil {
+		return err
+	}
+
+	defer removeManifest()
+
+	loader := c.envProvider(
+		opts.Args.Manifest.Path, opts.Args.Manifest.StatePath(opts.StatePath), opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp())
+
+	info, err := loader.LoadInfo()
+	if err != nil {
+		return err
+	}
+
+	var agent boshtbl.Value = boshtbl.NewValueFmt(boshtbl.NewValueString("unknown"), true)
+	if info.AgentResponding {
+		agent = boshtbl.NewValueString("responding")
+	}
+
+	table := boshtbl.Table{
+		Content: "environment",
+
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Name"),
+			boshtbl.NewHeader("UUID"),
+			boshtbl.NewHeader("Installation ID"),
+			boshtbl.NewHeader("Stemcell"),
+			boshtbl.NewHeader("Stemcell CID"),
+			boshtbl.NewHeader("CPI Release"),
+			boshtbl.NewHeader("VM CID"),
+			boshtbl.NewHeader("Address"),
+			boshtbl.NewHeader("Agent"),
+		},
+
+		Rows: [][]boshtbl.Value{
+			{
+				boshtbl.NewValueString(info.Name),
+				boshtbl.NewValueString(info.DirectorID),
+				boshtbl.NewValueString(info.InstallationID),
+				boshtbl.NewValueString(info.Stemcell),
+				boshtbl.NewValueString(info.StemcellCID),
+				boshtbl.NewValueString(info.CPIRelease),
+				boshtbl.NewValueString(info.VMCID),
+				boshtbl.NewValueString(info.Address),
+				agent,
+			},
+		},
+
+		Transpose: true,
+	}
+
+	c.ui.PrintTable(table)
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+
+	bihttpagent "github.com/cloudfoundry/bosh-agent/agentclient/http"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/cppforlife/go-patch/patch"
+
+	biconfig "github.com/cloudfoundry/bosh-cli/config"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+)
+
+// EnvInfo describes an environment created with create-env as tracked by its state file
+type EnvInfo struct {
+	Name           string
+	DirectorID     string
+	InstallationID string
+
+	// Stemcell and CPIRelease are 'name/version', or empty when they were not deployed
+	Stemcell    string
+	StemcellCID string
+	CPIRelease  string
+
+	VMCID   string
+	Address string
+
+	// AgentResponding is true when the agent of the current VM answered a ping;
+	// otherwise it is unknown whether the environment is up
+	AgentResponding bool
+}
+
+type EnvInfoLoader interface {
+	LoadInfo() (EnvInfo, error)
+}
+
+type envInfoLoader struct {
+	deploymentStateService                  biconfig.DeploymentStateService
+	agentClientFactory                      bihttpagent.AgentClientFactory
+	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser
+	deploymentManifestPath                  string
+	deploymentVars                          boshtpl.Variables
+	deploymentOp                            patch.Op
+	logTag                                  string
+	logger                                  boshlog.Logger
+}
+
+func NewEnvInfoLoader(
+	deploymentStateService biconfig.DeploymentStateService,
+	agentClientFactory bihttpagent.AgentClientFactory,
+	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser,
+	deploymentManifestPath string,
+	deploymentVars boshtpl.Variables,
+	deploymentOp patch.Op,
+	logger boshlog.Logger,
+) EnvInfoLoader {
+	return envInfoLoader{
+		deploymentStateService:                  deploymentStateService,
+		agentClientFactory:                      agentClientFactory,
+		releaseSetAndInstallationManifestParser: releaseSetAndInstallationManifestParser,
+		deploymentManifestPath:                  deploymentManifestPath,
+		deploymentVars:                          deploymentVars,
+		deploymentOp:                            deploymentOp,
+		logTag:                                  "envInfoLoader",
+		logger:                                  logger,
+	}
+}
+
+func (l envInfoLoader) LoadInfo() (EnvInfo, error) {
+	deploymentState, err := loadExistingDeploymentState(l.deploymentStateService)
+	if err != nil {
+		return EnvInfo{}, err
+	}
+
+	_, installationManifest, err := l.releaseSetAndInstallationManifestParser.ReleaseSetAndInstallationManifest(
+		l.deploymentManifestPath, l.deploymentVars, l.deploymentOp)
+	if err != nil {
+		return EnvInfo{}, err
+	}
+
+	info := EnvInfo{
+		Name:           installationManifest.Name,
+		DirectorID:     deploymentState.DirectorID,
+		InstallationID: deploymentState.InstallationID,
+		VMCID:          deploymentState.CurrentVMCID,
+		Address:        mbusHost(installationManifest.Mbus),
+	}
+
+	for _, stemcell := range deploymentState.Stemcells {
+		if stemcell.ID == deploymentState.CurrentStemcellID {
+			info.Stemcell = fmt.Sprintf("%s/%s", stemcell.Name, stemcell.Version)
+			info.StemcellCID = stemcell.CID
+		}
+	}
+
+	for _, release := range deploymentState.Releases {
+		if release.Name == installationManifest.Template.Release {
+			info.CPIRelease = fmt.Sprintf("%s/%s", release.Name, release.Version)
+		}
+	}
+
+	if len(info.VMCID) > 0 {
+		info.AgentResponding = pingAgent(l.agentClientFactory, deploymentState.DirectorID, installationManifest.Mbus, installationManifest.Cert.CA, l.logTag, l.logger)
+	}
+
+	return info, nil
+}
+
+// mbusHost returns the address the CLI reaches the VM at, without credentials or port
+func mbusHost(mbusURL string) string {
+	parsedURL, err := url.Parse(mbusURL)
+	if err != nil {
+		return ""
+	}
+
+	return parsedURL.Hostname()
+}
@@ -0,0 +1,133 @@
+package cmd_test
+
+import (
+	"errors"
+
+	"github.com/cppforlife/go-patch/patch"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+type fakeEnvInfoLoader struct {
+	info EnvInfo
+	err  error
+}
+
+func (l fakeEnvInfoLoader) LoadInfo() (EnvInfo, error) {
+	return l.info, l.err
+}
+
+var _ = Describe("EnvInfoCmd", func() {
+	var (
+		ui           *fakeui.FakeUI
+		loader       fakeEnvInfoLoader
+		manifestPath string
+		statePath    string
+		command      EnvInfoCmd
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		loader = fakeEnvInfoLoader{}
+
+		envProvider := func(path string, state string, _ boshtpl.Variables, _ patch.Op) EnvInfoLoader {
+			manifestPath = path
+			statePath = state
+			return loader
+		}
+
+		command = NewEnvInfoCmd(ui, envProvider)
+	})
+
+	opts := func() EnvInfoOpts {
+		return EnvInfoOpts{
+			Args:      EnvInfoArgs{Manifest: EnvManifestArg{Path: "/fake-manifest.yml"}},
+			StatePath: "/fake-state.json",
+		}
+	}
+
+	expectedTable := func(agent boshtbl.Value) boshtbl.Table {
+		return boshtbl.Table{
+			Content: "environment",
+
+			Header: []boshtbl.Header{
+				boshtbl.NewHeader("Name"),
+				boshtbl.NewHeader("UUID"),
+				boshtbl.NewHeader("Installation ID"),
+				boshtbl.NewHeader("Stemcell"),
+				boshtbl.NewHeader("Stemcell CID"),
+				boshtbl.NewHeader("CPI Release"),
+				boshtbl.NewHeader("VM CID"),
+				boshtbl.NewHeader("Address"),
+				boshtbl.NewHeader("Agent"),
+			},
+
+			Rows: [][]boshtbl.Value{
+				{
+					boshtbl.NewValueString("fake-name"),
+					boshtbl.NewValueString("fake-director-id"),
+					boshtbl.NewValueString("fake-installation-id"),
+					boshtbl.NewValueString("fake-stemcell/1"),
+					boshtbl.NewValueString("fake-stemcell-cid"),
+					boshtbl.NewValueString("fake-cpi/2"),
+					boshtbl.NewValueString("fake-vm-cid"),
+					boshtbl.NewValueString("10.0.0.6"),
+					agent,
+				},
+			},
+
+			Transpose: true,
+		}
+	}
+
+	BeforeEach(func() {
+		loader.info = EnvInfo{
+			Name:           "fake-name",
+			DirectorID:     "fake-director-id",
+			InstallationID: "fake-installation-id",
+			Stemcell:       "fake-stemcell/1",
+			StemcellCID:    "fake-stemcell-cid",
+			CPIRelease:     "fake-cpi/2",
+			VMCID:          "fake-vm-cid",
+			Address:        "10.0.0.6",
+		}
+	})
+
+	It("prints the environment with a responding agent", func() {
+		loader.info.AgentResponding = true
+
+		err := command.Run(opts())
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(manifestPath).To(Equal("/fake-manifest.yml"))
+		Expect(statePath).To(Equal("/fake-state.json"))
+
+		Expect(ui.Table).To(Equal(expectedTable(boshtbl.NewValueString("responding"))))
+	})
+
+	It("prints the environment with an unknown agent state when the agent does not respond", func() {
+		err := command.Run(opts())
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ui.Table).To(Equal(expectedTable(boshtbl.NewValueFmt(boshtbl.NewValueString("unknown"), true))))
+	})
+
+	It("returns an error if loading info fails", func() {
+		loader.err = errors.New("fake-err")
+
+		err := command.Run(opts())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("fake-err"))
+	})
+
+	It("requires --state when reading the manifest from stdin", func() {
+		err := command.Run(EnvInfoOpts{Args: EnvInfoArgs{Manifest: EnvManifestArg{Path: "-", Stdin: true}}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Expected --state"))
+	})
+})
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/cppforlife/go-patch/patch"
+
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+// EnvInstancesCmd lists the instances of an environment created with create-env,
+// using its state file and pinging the agent instead of asking a director
+type EnvInstancesCmd struct {
+	ui          boshui.UI
+	envProvider func(string, string, boshtpl.Variables, patch.Op) EnvInstancesLister
+}
+
+func NewEnvInstancesCmd(ui boshui.UI, envProvider func(string, string, boshtpl.Variables, patch.Op) EnvInstancesLister) EnvInstancesCmd {
+	return EnvInstancesCmd{ui: ui, envProvider: envProvider}
+}
+
+func (c EnvInstancesCmd) Run(opts EnvInstancesOpts) error {
+	if opts.Args.Manifest.Stdin && len(opts.StatePath) == 0 {
+		return bosherr.Error("Expected --state to be given when reading the manifest from stdin")
+	}
+
+	removeManifest, err := opts.Args.Manifest.WriteTempFile()
+	if err != nil {
+		return err
+	}
+
+	defer removeManifest()
+
+	lister := c.envProvider(
+		opts.Args.Manifest.Path, opts.Args.Manifest.StatePath(opts.StatePath), opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp())
+
+	instances, err := lister.ListInstances()
+	if err != nil {
+		return err
+	}
+
+	table := boshtbl.Table{
+		Content: "instances",
+
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Instance"),
+			boshtbl.NewHeader("VM CID"),
+			boshtbl.NewHeader("Reachable"),
+		},
+
+		SortBy: []boshtbl.ColumnSort{{Column: 0, Asc: true}},
+	}
+
+	for _, instance := range instances {
+		var reachable boshtbl.Value = boshtbl.NewValueFmt(boshtbl.NewValueString("no"), true)
+		if instance.Reachable {
+			reachable = boshtbl.NewValueString("yes")
+		}
+
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(instance.Name),
+			boshtbl.NewValueString(instance.VMCID),
+			reachable,
+		})
+	}
+
+	c.ui.PrintTable(table)
+
+	return nil
+}
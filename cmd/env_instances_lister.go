@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+
+	bihttpagent "github.com/cloudfoundry/bosh-agent/agentclient/http"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/cppforlife/go-patch/patch"
+
+	biconfig "github.com/cloudfoundry/bosh-cli/config"
+	bideplmanifest "github.com/cloudfoundry/bosh-cli/deployment/manifest"
+	bidepltpl "github.com/cloudfoundry/bosh-cli/deployment/template"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+)
+
+// EnvInstance is an instance of an environment created with create-env
+type EnvInstance struct {
+	Name  string
+	VMCID string
+
+	// Reachable is true when the agent of the VM answered a ping
+	Reachable bool
+}
+
+type EnvInstancesLister interface {
+	ListInstances() ([]EnvInstance, error)
+}
+
+type envInstancesLister struct {
+	deploymentStateService                  biconfig.DeploymentStateService
+	vmRepo                                  biconfig.VMRepo
+	agentClientFactory                      bihttpagent.AgentClientFactory
+	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser
+	templateFactory                         bidepltpl.DeploymentTemplateFactory
+	deploymentParser                        bideplmanifest.Parser
+	deploymentManifestPath                  string
+	deploymentVars                          boshtpl.Variables
+	deploymentOp                            patch.Op
+	logTag                                  string
+	logger                                  boshlog.Logger
+}
+
+func NewEnvInstancesLister(
+	deploymentStateService biconfig.DeploymentStateService,
+	vmRepo biconfig.VMRepo,
+	agentClientFactory bihttpagent.AgentClientFactory,
+	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser,
+	templateFactory bidepltpl.DeploymentTemplateFactory,
+	deploymentParser bideplmanifest.Parser,
+	deploymentManifestPath string,
+	deploymentVars boshtpl.Variables,
+	deploymentOp patch.Op,
+	logger boshlog.Logger,
+) EnvInstancesLister {
+	return envInstancesLister{
+		deploymentStateService:                  deploymentStateService,
+		vmRepo:                                  vmRepo,
+		agentClientFactory:                      agentClientFactory,
+		releaseSetAndInstallationManifestParser: releaseSetAndInstallationManifestParser,
+		templateFactory:                         templateFactory,
+		deploymentParser:                        deploymentParser,
+		deploymentManifestPath:                  deploymentManifestPath,
+		deploymentVars:                          deploymentVars,
+		deploymentOp:                            deploymentOp,
+		logTag:                                  "envInstancesLister",
+		logger:                                  logger,
+	}
+}
+
+func (l envInstancesLister) ListInstances() ([]EnvInstance, error) {
+	deploymentState, err := loadExistingDeploymentState(l.deploymentStateService)
+	if err != nil {
+		return nil, err
+	}
+
+	_, installationManifest, err := l.releaseSetAndInstallationManifestParser.ReleaseSetAndInstallationManifest(
+		l.deploymentManifestPath, l.deploymentVars, l.deploymentOp)
+	if err != nil {
+		return nil, err
+	}
+
+	deploymentManifest, err := l.parseDeploymentManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	// create-env deploys a single VM, so the manifest is held to the single instance the deployer accepts
+	if len(deploymentManifest.Jobs) != 1 {
+		return nil, bosherr.Errorf("There must only be one job, found %d", len(deploymentManifest.Jobs))
+	}
+
+	job := deploymentManifest.Jobs[0]
+
+	if job.Instances != 1 {
+		return nil, bosherr.Errorf("Job '%s' must have only one instance, found %d", job.Name, job.Instances)
+	}
+
+	vmCID, found, err := l.vmRepo.FindCurrent()
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Finding current VM")
+	}
+
+	var reachable bool
+
+	if found {
+		reachable = pingAgent(l.agentClientFactory, deploymentState.DirectorID, installationManifest.Mbus, installationManifest.Cert.CA, l.logTag, l.logger)
+	}
+
+	instance := EnvInstance{
+		Name:      fmt.Sprintf("%s/0", job.Name),
+		VMCID:     vmCID,
+		Reachable: reachable,
+	}
+
+	return []EnvInstance{instance}, nil
+}
+
+func (l envInstancesLister) parseDeploymentManifest() (bideplmanifest.Manifest, error) {
+	template, err := l.templateFactory.NewDeploymentTemplateFromPath(l.deploymentManifestPath)
+	if err != nil {
+		return bideplmanifest.Manifest{}, bosherr.WrapErrorf(err, "Evaluating manifest")
+	}
+
+	interpolatedTemplate, err := template.Evaluate(l.deploymentVars, l.deploymentOp)
+	if err != nil {
+		return bideplmanifest.Manifest{}, bosherr.WrapErrorf(err, "Evaluating manifest '%s'", l.deploymentManifestPath)
+	}
+
+	deploymentManifest, err := l.deploymentParser.Parse(interpolatedTemplate, l.deploymentManifestPath)
+	if err != nil {
+		return bideplmanifest.Manifest{}, bosherr.WrapErrorf(err, "Parsing deployment manifest '%s'", l.deploymentManifestPath)
+	}
+
+	return deploymentManifest, nil
+}
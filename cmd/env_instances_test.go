@@ -0,0 +1,105 @@
+package cmd_test
+
+import (
+	"errors"
+
+	"github.com/cppforlife/go-patch/patch"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+type fakeEnvInstancesLister struct {
+	instances []EnvInstance
+	err       error
+}
+
+func (l fakeEnvInstancesLister) ListInstances() ([]EnvInstance, error) {
+	return l.instances, l.err
+}
+
+var _ = Describe("EnvInstancesCmd", func() {
+	var (
+		ui           *fakeui.FakeUI
+		lister       fakeEnvInstancesLister
+		manifestPath string
+		statePath    string
+		command      EnvInstancesCmd
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		lister = fakeEnvInstancesLister{}
+
+		envProvider := func(path string, state string, _ boshtpl.Variables, _ patch.Op) EnvInstancesLister {
+			manifestPath = path
+			statePath = state
+			return lister
+		}
+
+		command = NewEnvInstancesCmd(ui, envProvider)
+	})
+
+	opts := func() EnvInstancesOpts {
+		return EnvInstancesOpts{
+			Args:      EnvInstancesArgs{Manifest: EnvManifestArg{Path: "/fake-manifest.yml"}},
+			StatePath: "/fake-state.json",
+		}
+	}
+
+	It("prints reachable and unreachable instances", func() {
+		lister.instances = []EnvInstance{
+			{Name: "bosh/0", VMCID: "fake-vm-cid", Reachable: true},
+			{Name: "bosh/1", VMCID: "fake-other-vm-cid"},
+		}
+
+		err := command.Run(opts())
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(manifestPath).To(Equal("/fake-manifest.yml"))
+		Expect(statePath).To(Equal("/fake-state.json"))
+
+		Expect(ui.Table).To(Equal(boshtbl.Table{
+			Content: "instances",
+
+			Header: []boshtbl.Header{
+				boshtbl.NewHeader("Instance"),
+				boshtbl.NewHeader("VM CID"),
+				boshtbl.NewHeader("Reachable"),
+			},
+
+			SortBy: []boshtbl.ColumnSort{{Column: 0, Asc: true}},
+
+			Rows: [][]boshtbl.Value{
+				{
+					boshtbl.NewValueString("bosh/0"),
+					boshtbl.NewValueString("fake-vm-cid"),
+					boshtbl.NewValueString("yes"),
+				},
+				{
+					boshtbl.NewValueString("bosh/1"),
+					boshtbl.NewValueString("fake-other-vm-cid"),
+					boshtbl.NewValueFmt(boshtbl.NewValueString("no"), true),
+				},
+			},
+		}))
+	})
+
+	It("returns an error if listing instances fails", func() {
+		lister.err = errors.New("fake-err")
+
+		err := command.Run(opts())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("fake-err"))
+	})
+
+	It("requires --state when reading the manifest from stdin", func() {
+		err := command.Run(EnvInstancesOpts{Args: EnvInstancesArgs{Manifest: EnvManifestArg{Path: "-", Stdin: true}}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Expected --state"))
+	})
+})
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/cppforlife/go-patch/patch"
+
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+)
+
+// EnvLogsCmd fetches logs from the VM of an environment created with create-env,
+// asking its agent over mbus instead of going through a director
+type EnvLogsCmd struct {
+	ui          boshui.UI
+	envProvider func(string, string, boshtpl.Variables, patch.Op) EnvLogsFetcher
+}
+
+func NewEnvLogsCmd(ui boshui.UI, envProvider func(string, string, boshtpl.Variables, patch.Op) EnvLogsFetcher) EnvLogsCmd {
+	return EnvLogsCmd{ui: ui, envProvider: envProvider}
+}
+
+func (c EnvLogsCmd) Run(opts EnvLogsOpts) error {
+	if opts.Args.Manifest.Stdin && len(opts.StatePath) == 0 {
+		return bosherr.Error("Expected --state to be given when reading the manifest from stdin")
+	}
+
+	if opts.Agent && len(opts.Jobs) > 0 {
+		return bosherr.Error("Expected only one of --agent or --job to be given")
+	}
+
+	removeManifest, err := opts.Args.Manifest.WriteTempFile()
+	if err != nil {
+		return err
+	}
+
+	defer removeManifest()
+
+	fetcher := c.envProvider(
+		opts.Args.Manifest.Path, opts.Args.Manifest.StatePath(opts.StatePath), opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp())
+
+	path, err := fetcher.FetchLogs(opts.Jobs, opts.Agent, opts.Directory.Path)
+	if err != nil {
+		return err
+	}
+
+	c.ui.PrintLinef("Saved logs to '%s'", path)
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"code.cloudfoundry.org/clock"
+	bihttpagent "github.com/cloudfoundry/bosh-agent/agentclient/http"
+	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshfu "github.com/cloudfoundry/bosh-utils/fileutil"
+	bihttpclient "github.com/cloudfoundry/bosh-utils/httpclient"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	"github.com/cppforlife/go-patch/patch"
+
+	boshagentclient "github.com/cloudfoundry/bosh-cli/agentclient"
+	biblobstore "github.com/cloudfoundry/bosh-cli/blobstore"
+	biconfig "github.com/cloudfoundry/bosh-cli/config"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+)
+
+type EnvLogsFetcher interface {
+	// FetchLogs saves a tarball of the job logs, or of the agent logs, of the current VM
+	// into dstDirPath and returns its path. Without jobs the logs of all jobs are fetched.
+	FetchLogs(jobs []string, agent bool, dstDirPath string) (string, error)
+}
+
+type envLogsFetcher struct {
+	deploymentStateService                  biconfig.DeploymentStateService
+	agentClientFactory                      bihttpagent.AgentClientFactory
+	blobstoreFactory                        biblobstore.Factory
+	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser
+	fs                                      boshsys.FileSystem
+	timeService                             clock.Clock
+	deploymentManifestPath                  string
+	deploymentVars                          boshtpl.Variables
+	deploymentOp                            patch.Op
+	logTag                                  string
+	logger                                  boshlog.Logger
+}
+
+func NewEnvLogsFetcher(
+	deploymentStateService biconfig.DeploymentStateService,
+	agentClientFactory bihttpagent.AgentClientFactory,
+	blobstoreFactory biblobstore.Factory,
+	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser,
+	fs boshsys.FileSystem,
+	timeService clock.Clock,
+	deploymentManifestPath string,
+	deploymentVars boshtpl.Variables,
+	deploymentOp patch.Op,
+	logger boshlog.Logger,
+) EnvLogsFetcher {
+	return envLogsFetcher{
+		deploymentStateService:                  deploymentStateService,
+		agentClientFactory:                      agentClientFactory,
+		blobstoreFactory:                        blobstoreFactory,
+		releaseSetAndInstallationManifestParser: releaseSetAndInstallationManifestParser,
+		fs:                                      fs,
+		timeService:                             timeService,
+		deploymentManifestPath:                  deploymentManifestPath,
+		deploymentVars:                          deploymentVars,
+		deploymentOp:                            deploymentOp,
+		logTag:                                  "envLogsFetcher",
+		logger:                                  logger,
+	}
+}
+
+func (f envLogsFetcher) FetchLogs(jobs []string, agent bool, dstDirPath string) (string, error) {
+	deploymentState, err := loadExistingDeploymentState(f.deploymentStateService)
+	if err != nil {
+		return "", err
+	}
+
+	if len(deploymentState.CurrentVMCID) == 0 {
+		return "", bosherr.Error("No VM is deployed in the environment")
+	}
+
+	_, installationManifest, err := f.releaseSetAndInstallationManifestParser.ReleaseSetAndInstallationManifest(
+		f.deploymentManifestPath, f.deploymentVars, f.deploymentOp)
+	if err != nil {
+		return "", err
+	}
+
+	agentClient, err := f.agentClientFactory.NewAgentClient(deploymentState.DirectorID, installationManifest.Mbus, installationManifest.Cert.CA)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Creating agent client")
+	}
+
+	logsFetcher, ok := agentClient.(boshagentclient.LogsFetcher)
+	if !ok {
+		return "", bosherr.Error("Fetching logs is not supported by the agent client")
+	}
+
+	logType := "job"
+	var filters []string
+
+	if agent {
+		logType = "agent"
+	} else {
+		// Job logs are kept in a directory per job under /var/vcap/sys/log
+		for _, job := range jobs {
+			filters = append(filters, job+"/**/*")
+		}
+	}
+
+	blobstoreID, sha1, err := logsFetcher.FetchLogs(logType, filters)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Fetching logs from the agent")
+	}
+
+	// The agent puts the logs into the blobstore it serves over mbus, the same one used to deploy
+	blobstore, err := f.blobstoreFactory.Create(installationManifest.Mbus, bihttpclient.CreateDefaultClientInsecureSkipVerify())
+	if err != nil {
+		return "", bosherr.WrapError(err, "Creating blobstore client")
+	}
+
+	localBlob, err := blobstore.Get(blobstoreID)
+	if err != nil {
+		return "", bosherr.WrapErrorf(err, "Downloading logs '%s'", blobstoreID)
+	}
+
+	defer localBlob.DeleteSilently()
+
+	if len(sha1) > 0 {
+		digest, err := boshcrypto.ParseMultipleDigest(sha1)
+		if err != nil {
+			return "", bosherr.WrapError(err, "Parsing logs digest")
+		}
+
+		err = digest.VerifyFilePath(localBlob.Path(), f.fs)
+		if err != nil {
+			return "", bosherr.WrapError(err, "Verifying logs")
+		}
+	}
+
+	tsSuffix := strings.Replace(f.timeService.Now().Format("20060102-150405.999999999"), ".", "-", -1)
+	dstFilePath := filepath.Join(dstDirPath, fmt.Sprintf("%s-%s.tgz", installationManifest.Name, tsSuffix))
+
+	err = boshfu.NewFileMover(f.fs).Move(localBlob.Path(), dstFilePath)
+	if err != nil {
+		return "", bosherr.WrapErrorf(err, "Moving logs to '%s'", dstFilePath)
+	}
+
+	return dstFilePath, nil
+}
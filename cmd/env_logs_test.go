@@ -0,0 +1,102 @@
+package cmd_test
+
+import (
+	"errors"
+
+	"github.com/cppforlife/go-patch/patch"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+)
+
+type fakeEnvLogsFetcher struct {
+	jobs       []string
+	agent      bool
+	dstDirPath string
+
+	path string
+	err  error
+}
+
+func (f *fakeEnvLogsFetcher) FetchLogs(jobs []string, agent bool, dstDirPath string) (string, error) {
+	f.jobs = jobs
+	f.agent = agent
+	f.dstDirPath = dstDirPath
+	return f.path, f.err
+}
+
+var _ = Describe("EnvLogsCmd", func() {
+	var (
+		ui           *fakeui.FakeUI
+		fetcher      *fakeEnvLogsFetcher
+		manifestPath string
+		statePath    string
+		command      EnvLogsCmd
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		fetcher = &fakeEnvLogsFetcher{path: "/logs/fake-logs.tgz"}
+
+		envProvider := func(path string, state string, _ boshtpl.Variables, _ patch.Op) EnvLogsFetcher {
+			manifestPath = path
+			statePath = state
+			return fetcher
+		}
+
+		command = NewEnvLogsCmd(ui, envProvider)
+	})
+
+	opts := func() EnvLogsOpts {
+		return EnvLogsOpts{
+			Args:      EnvLogsArgs{Manifest: EnvManifestArg{Path: "/fake-manifest.yml"}},
+			StatePath: "/fake-state.json",
+			Directory: DirOrCWDArg{Path: "/logs"},
+			Jobs:      []string{"fake-job"},
+		}
+	}
+
+	It("fetches logs into the destination directory and prints where they were saved", func() {
+		err := command.Run(opts())
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(manifestPath).To(Equal("/fake-manifest.yml"))
+		Expect(statePath).To(Equal("/fake-state.json"))
+
+		Expect(fetcher.jobs).To(Equal([]string{"fake-job"}))
+		Expect(fetcher.agent).To(BeFalse())
+		Expect(fetcher.dstDirPath).To(Equal("/logs"))
+
+		Expect(ui.Said).To(Equal([]string{"Saved logs to '/logs/fake-logs.tgz'"}))
+	})
+
+	It("returns an error when both --agent and --job are given", func() {
+		o := opts()
+		o.Agent = true
+
+		err := command.Run(o)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected only one of --agent or --job to be given"))
+	})
+
+	It("requires --state when reading the manifest from stdin", func() {
+		o := opts()
+		o.Args.Manifest.Stdin = true
+		o.StatePath = ""
+
+		err := command.Run(o)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected --state to be given when reading the manifest from stdin"))
+	})
+
+	It("returns an error when fetching logs fails", func() {
+		fetcher.err = errors.New("fake-err")
+
+		err := command.Run(opts())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("fake-err"))
+	})
+})
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	biconfig "github.com/cloudfoundry/bosh-cli/config"
+	bidepltpl "github.com/cloudfoundry/bosh-cli/deployment/template"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// EnvManifestArg is the deployment manifest given to create-env and delete-env.
+// Passing '-' reads the manifest from stdin.
+type EnvManifestArg struct {
+	FS boshsys.FileSystem
+
+	Bytes []byte
+	Path  string
+	Stdin bool
+
+	// sourcePath is the manifest file given when Path is a temporary file
+	sourcePath string
+}
+
+func (a *EnvManifestArg) UnmarshalFlag(data string) error {
+	if data == "-" {
+		bs, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Reading manifest from stdin")
+		}
+
+		(*a).Bytes = bs
+		(*a).Stdin = true
+
+		return nil
+	}
+
+	arg := FileBytesWithPathArg{FS: a.FS}
+
+	err := arg.UnmarshalFlag(data)
+	if err != nil {
+		return err
+	}
+
+	(*a).Bytes = arg.Bytes
+	(*a).Path = arg.Path
+
+	return nil
+}
+
+// Name describes where the manifest was read from in messages
+func (a EnvManifestArg) Name() string {
+	if a.Stdin {
+		return "stdin"
+	}
+
+	if len(a.sourcePath) > 0 {
+		return a.sourcePath
+	}
+
+	return a.Path
+}
+
+// StatePath returns the given state path. When the manifest file was replaced by a
+// temporary file and no state path was given, it returns the default state path of
+// the manifest file instead of the one derived from the temporary file.
+func (a EnvManifestArg) StatePath(statePath string) string {
+	if len(statePath) > 0 || len(a.sourcePath) == 0 {
+		return statePath
+	}
+
+	return biconfig.DeploymentStatePath(a.sourcePath, statePath)
+}
+
+// WriteTempFile resolves the includes of the manifest once, before any of the
+// manifest parsers read it, and saves the result to a hidden file that replaces
+// Path and Bytes. The file is written next to the manifest, or to the current
+// directory for a manifest read from stdin, since the parsers resolve relative
+// paths in it, e.g. release URLs and private keys, against its directory.
+// Manifests from a file without includes are used as they are.
+// The returned function removes the file.
+func (a *EnvManifestArg) WriteTempFile() (func(), error) {
+	noop := func() {}
+
+	dir := filepath.Dir(a.Path)
+
+	if a.Stdin {
+		var err error
+
+		dir, err = filepath.Abs(".")
+		if err != nil {
+			return noop, bosherr.WrapError(err, "Getting current directory")
+		}
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf(".bosh-cli-manifest-%d.yml", os.Getpid()))
+
+	includesPath := a.Path
+	if a.Stdin {
+		includesPath = path
+	}
+
+	bytes, err := bidepltpl.ResolveIncludes(a.FS, includesPath, a.Bytes)
+	if err != nil {
+		return noop, err
+	}
+
+	if !a.Stdin && string(bytes) == string(a.Bytes) {
+		return noop, nil
+	}
+
+	file, err := a.FS.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return noop, bosherr.WrapErrorf(err, "Creating temporary manifest file in '%s'", dir)
+	}
+
+	defer file.Close()
+
+	_, err = file.Write(bytes)
+	if err != nil {
+		a.FS.RemoveAll(path)
+		return noop, bosherr.WrapError(err, "Writing temporary manifest file")
+	}
+
+	if !a.Stdin {
+		(*a).sourcePath = a.Path
+	}
+
+	(*a).Path = path
+	(*a).Bytes = bytes
+
+	return func() { a.FS.RemoveAll(path) }, nil
+}
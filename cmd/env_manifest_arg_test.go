@@ -0,0 +1,174 @@
+package cmd_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+)
+
+var _ = Describe("EnvManifestArg", func() {
+	var (
+		fs  *fakesys.FakeFileSystem
+		arg EnvManifestArg
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		arg = EnvManifestArg{FS: fs}
+	})
+
+	Describe("UnmarshalFlag", func() {
+		Context("when dash is given as path", func() {
+			var stdin *os.File
+
+			BeforeEach(func() {
+				stdin = os.Stdin
+			})
+
+			AfterEach(func() {
+				os.Stdin = stdin
+			})
+
+			It("reads the manifest from stdin", func() {
+				r, w, err := os.Pipe()
+				Expect(err).ToNot(HaveOccurred())
+
+				os.Stdin = r
+
+				_, err = w.Write([]byte("name: fake-deployment"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(w.Close()).To(Succeed())
+
+				err = (&arg).UnmarshalFlag("-")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(arg.Bytes).To(Equal([]byte("name: fake-deployment")))
+				Expect(arg.Stdin).To(BeTrue())
+				Expect(arg.Path).To(BeEmpty())
+				Expect(arg.Name()).To(Equal("stdin"))
+			})
+
+			It("returns an error if reading from stdin fails", func() {
+				os.Stdin = nil
+
+				err := (&arg).UnmarshalFlag("-")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Reading manifest from stdin"))
+			})
+		})
+
+		Context("when path is not a dash", func() {
+			It("sets path and bytes", func() {
+				fs.WriteFileString("/some/path", "content")
+
+				err := (&arg).UnmarshalFlag("/some/path")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(arg.Path).To(Equal("/some/path"))
+				Expect(arg.Bytes).To(Equal([]byte("content")))
+				Expect(arg.Stdin).To(BeFalse())
+				Expect(arg.Name()).To(Equal("/some/path"))
+			})
+
+			It("returns an error if reading file fails", func() {
+				fs.WriteFileString("/some/path", "content")
+				fs.ReadFileError = errors.New("fake-err")
+
+				err := (&arg).UnmarshalFlag("/some/path")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-err"))
+			})
+		})
+	})
+
+	Describe("WriteTempFile", func() {
+		It("does nothing for a manifest file without includes", func() {
+			arg.Path = "/some/path"
+			arg.Bytes = []byte("name: fake-deployment\n")
+
+			removeManifest, err := (&arg).WriteTempFile()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(arg.Path).To(Equal("/some/path"))
+			Expect(arg.StatePath("")).To(BeEmpty())
+
+			removeManifest()
+		})
+
+		Context("when the manifest file has includes", func() {
+			var manifestPath string
+
+			BeforeEach(func() {
+				arg.Path = "/some/manifest.yml"
+				arg.Bytes = []byte("include: [common/env.yml]\nname: fake-deployment\n")
+				fs.WriteFileString("/some/common/env.yml", "releases: [{name: fake-release, url: release.tgz}]\n")
+
+				manifestPath = filepath.Join("/some", fmt.Sprintf(".bosh-cli-manifest-%d.yml", os.Getpid()))
+			})
+
+			It("writes the manifest with resolved includes next to the manifest file, and removes it afterwards", func() {
+				removeManifest, err := (&arg).WriteTempFile()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(arg.Path).To(Equal(manifestPath))
+				Expect(arg.Name()).To(Equal("/some/manifest.yml"))
+				Expect(fs.ReadFileString(manifestPath)).To(Equal(string(arg.Bytes)))
+				Expect(string(arg.Bytes)).To(ContainSubstring("url: /some/common/release.tgz"))
+				Expect(string(arg.Bytes)).ToNot(ContainSubstring("include"))
+
+				removeManifest()
+				Expect(fs.FileExists(manifestPath)).To(BeFalse())
+			})
+
+			It("keeps the default state path of the manifest file", func() {
+				_, err := (&arg).WriteTempFile()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(arg.StatePath("")).To(Equal(filepath.Join("/some", "manifest-state.json")))
+				Expect(arg.StatePath("/other/state.json")).To(Equal("/other/state.json"))
+			})
+
+			It("returns an error when an include is missing", func() {
+				fs.RemoveAll("/some/common/env.yml")
+
+				_, err := (&arg).WriteTempFile()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("includes '/some/common/env.yml' which does not exist"))
+			})
+		})
+
+		Context("when the manifest was read from stdin", func() {
+			var manifestPath string
+
+			BeforeEach(func() {
+				arg.Stdin = true
+				arg.Bytes = []byte("name: fake-deployment\n")
+
+				dir, err := filepath.Abs(".")
+				Expect(err).ToNot(HaveOccurred())
+
+				manifestPath = filepath.Join(dir, fmt.Sprintf(".bosh-cli-manifest-%d.yml", os.Getpid()))
+			})
+
+			It("writes the manifest to the current directory so that relative paths resolve there, and removes it afterwards", func() {
+				removeManifest, err := (&arg).WriteTempFile()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(arg.Path).To(Equal(manifestPath))
+				Expect(fs.ReadFileString(manifestPath)).To(Equal("name: fake-deployment\n"))
+
+				removeManifest()
+				Expect(fs.FileExists(manifestPath)).To(BeFalse())
+			})
+
+			It("returns an error when the file cannot be created", func() {
+				fs.OpenFileErr = errors.New("fake-open-file-err")
+
+				_, err := (&arg).WriteTempFile()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-open-file-err"))
+			})
+		})
+	})
+})
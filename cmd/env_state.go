@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	bihttpagent "github.com/cloudfoundry/bosh-agent/agentclient/http"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+
+	biconfig "github.com/cloudfoundry/bosh-cli/config"
+)
+
+// loadExistingDeploymentState loads the state of an environment created before;
+// a missing state file is an error since loading it would create it
+func loadExistingDeploymentState(deploymentStateService biconfig.DeploymentStateService) (biconfig.DeploymentState, error) {
+	if !deploymentStateService.Exists() {
+		return biconfig.DeploymentState{}, bosherr.Errorf("No deployment state file found at '%s'", deploymentStateService.Path())
+	}
+
+	deploymentState, err := deploymentStateService.Load()
+	if err != nil {
+		return biconfig.DeploymentState{}, bosherr.WrapError(err, "Loading deployment state")
+	}
+
+	return deploymentState, nil
+}
+
+// pingAgent reports whether the agent answers; an unreachable agent is only logged
+// since commands inspecting an environment are most useful when it is half up
+func pingAgent(agentClientFactory bihttpagent.AgentClientFactory, directorID, mbusURL, caCert string, logTag string, logger boshlog.Logger) bool {
+	agentClient, err := agentClientFactory.NewAgentClient(directorID, mbusURL, caCert)
+	if err != nil {
+		logger.Debug(logTag, "Creating agent client: %s", err.Error())
+		return false
+	}
+
+	_, err = agentClient.Ping()
+	if err != nil {
+		logger.Debug(logTag, "Pinging agent: %s", err.Error())
+		return false
+	}
+
+	return true
+}
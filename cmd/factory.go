@@ -79,6 +79,11 @@ func (f Factory) New(args []string) (Cmd, error) {
 			opts.Deployment = boshOpts.DeploymentOpt
 		}
 
+		// Printing the schema does not run the command
+		if boshOpts.ReadOnlyOpt && !boshOpts.SchemaOpt && !isReadOnlyCommand(command) {
+			return fmt.Errorf("Command '%s' is not allowed in read-only mode", parser.Active.Name)
+		}
+
 		if len(extraArgs) > 0 {
 			errMsg := "Command '%T' does not support extra arguments: %s"
 			return fmt.Errorf(errMsg, command, strings.Join(extraArgs, ", "))
@@ -86,6 +91,10 @@ func (f Factory) New(args []string) (Cmd, error) {
 
 		cmdOpts = command
 
+		if boshOpts.SchemaOpt {
+			cmdOpts = &OutputSchemaOpts{Command: parser.Active.Name}
+		}
+
 		return nil
 	}
 
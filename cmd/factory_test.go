@@ -355,6 +355,71 @@ var _ = Describe("Factory", func() {
 		})
 	})
 
+	Describe("schema option", func() {
+		It("prints the output schema of the command instead of running it", func() {
+			cmd, err := factory.New([]string{"--schema", "stemcells"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cmd.Opts).To(Equal(&OutputSchemaOpts{Command: "stemcells"}))
+		})
+
+		It("is allowed in read-only mode for commands that change state", func() {
+			cmd, err := factory.New([]string{"--read-only", "--schema", "delete-disk", "cid"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cmd.Opts).To(Equal(&OutputSchemaOpts{Command: "delete-disk"}))
+		})
+	})
+
+	Describe("read-only option", func() {
+		BeforeEach(func() {
+			err := fs.WriteFileString(fakeFilePath, "")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("allows commands that only inspect state", func() {
+			for _, args := range [][]string{
+				{"vms"},
+				{"disks"},
+				{"stemcells"},
+				{"instances"},
+				{"manifest"},
+				{"interpolate", fakeFilePath},
+				{"diff", fakeFilePath},
+				{"create-env", "--print-manifest", fakeFilePath},
+				{"env-info", fakeFilePath},
+				{"env-instances", fakeFilePath},
+				{"env-logs", fakeFilePath},
+				{"env-disks", "--orphaned", fakeFilePath},
+				{"env-cloud-check", "--report", fakeFilePath},
+				{"validate-manifest", fakeFilePath},
+			} {
+				_, err := factory.New(append([]string{"--read-only"}, args...))
+				Expect(err).ToNot(HaveOccurred(), "command %v", args)
+			}
+		})
+
+		It("refuses commands that change state", func() {
+			for _, args := range [][]string{
+				{"deploy", fakeFilePath},
+				{"create-env", fakeFilePath},
+				{"delete-env", fakeFilePath},
+				{"env-disks", "--orphaned", "--delete", fakeFilePath},
+				{"env-cloud-check", fakeFilePath},
+				{"delete-disk", "cid"},
+				{"log-in"},
+				{"upload-stemcell", fakeFilePath},
+			} {
+				_, err := factory.New(append([]string{"--read-only"}, args...))
+				Expect(err).To(HaveOccurred(), "command %v", args)
+				Expect(err.Error()).To(Equal("Command '" + args[0] + "' is not allowed in read-only mode"))
+			}
+		})
+
+		It("allows mutating commands when not set", func() {
+			_, err := factory.New([]string{"delete-disk", "cid"})
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
 	Describe("global options", func() {
 		clearNonGlobalOpts := func(boshOpts BoshOpts) BoshOpts {
 			boshOpts.VersionOpt = nil   // can't compare functions
@@ -363,6 +428,7 @@ var _ = Describe("Factory", func() {
 			boshOpts.ExportRelease = ExportReleaseOpts{}
 			boshOpts.RunErrand = RunErrandOpts{}
 			boshOpts.Logs = LogsOpts{}
+			boshOpts.EnvLogs = EnvLogsOpts{}
 			boshOpts.Interpolate = InterpolateOpts{}
 			boshOpts.InitRelease = InitReleaseOpts{}
 			boshOpts.ResetRelease = ResetReleaseOpts{}
@@ -378,6 +444,8 @@ var _ = Describe("Factory", func() {
 			boshOpts.UploadBlobs = UploadBlobsOpts{}
 			boshOpts.SSH = SSHOpts{}
 			boshOpts.SCP = SCPOpts{}
+			boshOpts.CreateEnv = CreateEnvOpts{}
+			boshOpts.DeleteEnv = DeleteEnvOpts{}
 			boshOpts.Deploy = DeployOpts{}
 			boshOpts.UpdateRuntimeConfig = UpdateRuntimeConfigOpts{}
 			boshOpts.VMs = VMsOpts{}
@@ -413,6 +481,7 @@ var _ = Describe("Factory", func() {
 				"--no-color",
 				"--non-interactive",
 				"--parallel", "123",
+				"--read-only",
 				"locks",
 			}
 
@@ -431,6 +500,7 @@ var _ = Describe("Factory", func() {
 				NoColorOpt:        true,
 				NonInteractiveOpt: true,
 				Parallel:          123,
+				ReadOnlyOpt:       true,
 			}))
 		})
 
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	boshblob "github.com/cloudfoundry/bosh-utils/blobstore"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+
+	cmdconf "github.com/cloudfoundry/bosh-cli/cmd/config"
+	biindex "github.com/cloudfoundry/bosh-cli/index"
+	boshreldir "github.com/cloudfoundry/bosh-cli/releasedir"
+)
+
+// newInstallationBlobstore returns the blobstore configured for installations in the CLI config,
+// and the store that keeps the compiled package index next to its blobs, or nils when
+// installations keep compiled packages in their own local blobstore
+func newInstallationBlobstore(config cmdconf.Config, deps BasicDeps) (boshblob.Blobstore, biindex.FileStore, error) {
+	blobstoreConfig := config.InstallationBlobstore()
+
+	var blobstore boshblob.Blobstore
+	var indexStore biindex.FileStore
+
+	switch blobstoreConfig.Provider {
+	case "":
+		return nil, nil, nil
+	case "local":
+		blobstore = boshblob.NewLocalBlobstore(deps.FS, deps.UUIDGen, blobstoreConfig.Options)
+		blobstorePath, _ := blobstoreConfig.Options["blobstore_path"].(string)
+		indexStore = localFileStore{fs: deps.FS, dir: blobstorePath}
+	case "s3":
+		s3Blobstore := boshreldir.NewS3Blobstore(deps.FS, deps.UUIDGen, blobstoreConfig.Options)
+		blobstore, indexStore = s3Blobstore, s3Blobstore
+	default:
+		return nil, nil, bosherr.Errorf("Expected installation blobstore provider to be 'local' or 's3' but was '%s'", blobstoreConfig.Provider)
+	}
+
+	err := blobstore.Validate()
+	if err != nil {
+		return nil, nil, bosherr.WrapErrorf(err, "Validating '%s' installation blobstore", blobstoreConfig.Provider)
+	}
+
+	return blobstore, indexStore, nil
+}
+
+// localFileStore keeps files in the directory of a local blobstore
+type localFileStore struct {
+	fs  boshsys.FileSystem
+	dir string
+}
+
+func (s localFileStore) Download(name string, path string) (bool, error) {
+	storedPath := filepath.Join(s.dir, name)
+
+	if !s.fs.FileExists(storedPath) {
+		return false, nil
+	}
+
+	err := s.fs.CopyFile(storedPath, path)
+	if err != nil {
+		return false, bosherr.WrapErrorf(err, "Copying '%s'", storedPath)
+	}
+
+	return true, nil
+}
+
+func (s localFileStore) Upload(name string, path string) error {
+	err := s.fs.MkdirAll(s.dir, os.ModePerm)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Creating directory '%s'", s.dir)
+	}
+
+	storedPath := filepath.Join(s.dir, name)
+
+	err = s.fs.CopyFile(path, storedPath)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Copying to '%s'", storedPath)
+	}
+
+	return nil
+}
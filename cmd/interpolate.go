@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	"github.com/cppforlife/go-patch/patch"
 
 	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
@@ -16,12 +17,13 @@ func NewInterpolateCmd(ui boshui.UI) InterpolateCmd {
 }
 
 func (c InterpolateCmd) Run(opts InterpolateOpts) error {
-	tpl := boshtpl.NewTemplate(opts.Args.Manifest.Bytes)
+	if opts.VarErrors && opts.NoVarErrors {
+		return bosherr.Error("Expected only one of --var-errs and --no-var-errs")
+	}
 
-	vars := opts.VarFlags.AsVariables()
-	op := opts.OpsFlags.AsOp()
+	// Printing a template with unresolved variables usually hides a missing --var or --vars-file
 	evalOpts := boshtpl.EvaluateOpts{
-		ExpectAllKeys:     opts.VarErrors,
+		ExpectAllKeys:     !opts.NoVarErrors,
 		ExpectAllVarsUsed: opts.VarErrorsUnused,
 	}
 
@@ -32,7 +34,13 @@ func (c InterpolateCmd) Run(opts InterpolateOpts) error {
 		evalOpts.UnescapedMultiline = true
 	}
 
-	bytes, err := tpl.Evaluate(vars, op, evalOpts)
+	return c.Print(opts.Args.Manifest.Bytes, opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp(), evalOpts)
+}
+
+// Print evaluates the template and prints the result, so that commands
+// showing a manifest print it the same way as interpolate
+func (c InterpolateCmd) Print(template []byte, vars boshtpl.Variables, op patch.Op, evalOpts boshtpl.EvaluateOpts) error {
+	bytes, err := boshtpl.NewTemplate(template).Evaluate(vars, op, evalOpts)
 	if err != nil {
 		return err
 	}
@@ -117,7 +117,7 @@ var _ = Describe("InterpolateCmd", func() {
 			Expect(ui.Blocks).To(Equal([]string{"subkey:\n  subsubkey: key\n"}))
 		})
 
-		It("returns error if variables are not found in templated manifest if var-errs flag is set", func() {
+		It("returns error if variables are not found in templated manifest", func() {
 			opts.Args.Manifest = FileBytesArg{
 				Bytes: []byte("name1: ((name1))\nname2: ((name2))"),
 			}
@@ -126,11 +126,35 @@ var _ = Describe("InterpolateCmd", func() {
 				{Name: "name1", Value: "val1-from-kv"},
 			}
 
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected to find variables: name2"))
+			Expect(ui.Blocks).To(BeEmpty())
+		})
+
+		It("leaves variables that are not found in templated manifest if no-var-errs flag is set", func() {
+			opts.Args.Manifest = FileBytesArg{
+				Bytes: []byte("name1: ((name1))\nname2: ((name2))"),
+			}
+
+			opts.VarKVs = []boshtpl.VarKV{
+				{Name: "name1", Value: "val1-from-kv"},
+			}
+
+			opts.NoVarErrors = true
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ui.Blocks).To(Equal([]string{"name1: val1-from-kv\nname2: ((name2))\n"}))
+		})
+
+		It("returns error if both var-errs and no-var-errs flags are set", func() {
 			opts.VarErrors = true
+			opts.NoVarErrors = true
 
 			err := act()
 			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(ContainSubstring("Expected to find variables: name2"))
+			Expect(err.Error()).To(Equal("Expected only one of --var-errs and --no-var-errs"))
 		})
 
 		It("returns error if variables are not used in templated manifest if var-errs-unused flag is set", func() {
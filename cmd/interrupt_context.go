@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"syscall"
+
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+)
+
+// NewInterruptContext returns a context that is canceled on the first Ctrl-C or SIGTERM
+// until stop is called. Later signals are no longer caught so that pressing Ctrl-C again
+// exits right away, e.g. while waiting for the CPI instead of the agent.
+func NewInterruptContext(
+	ui boshui.UI,
+	signalNotifyFunc func(chan<- os.Signal, ...os.Signal),
+	signalStopFunc func(chan<- os.Signal),
+) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	stopped := make(chan struct{})
+
+	signalNotifyFunc(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-signals:
+			signalStopFunc(signals)
+			ui.ErrorLinef("Interrupting the running agent call, press Ctrl-C again to exit immediately")
+			cancel()
+		case <-stopped:
+		}
+	}()
+
+	stop := func() {
+		close(stopped)
+		signalStopFunc(signals)
+		cancel()
+	}
+
+	return ctx, stop
+}
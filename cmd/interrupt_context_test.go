@@ -0,0 +1,64 @@
+package cmd_test
+
+import (
+	"os"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+)
+
+var _ = Describe("NewInterruptContext", func() {
+	var (
+		ui             *fakeui.FakeUI
+		notifiedCh     chan chan<- os.Signal
+		notifiedSigs   []os.Signal
+		stoppedCh      chan chan<- os.Signal
+		signalNotify   func(chan<- os.Signal, ...os.Signal)
+		signalStop     func(chan<- os.Signal)
+		registeredChan chan<- os.Signal
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		notifiedCh = make(chan chan<- os.Signal, 1)
+		stoppedCh = make(chan chan<- os.Signal, 2)
+
+		signalNotify = func(ch chan<- os.Signal, sigs ...os.Signal) {
+			notifiedSigs = sigs
+			notifiedCh <- ch
+		}
+		signalStop = func(ch chan<- os.Signal) { stoppedCh <- ch }
+	})
+
+	It("cancels the context on Ctrl-C and stops catching signals", func() {
+		ctx, stop := NewInterruptContext(ui, signalNotify, signalStop)
+		defer stop()
+
+		registeredChan = <-notifiedCh
+		Expect(notifiedSigs).To(Equal([]os.Signal{os.Interrupt, syscall.SIGTERM}))
+
+		Expect(ctx.Err()).ToNot(HaveOccurred())
+
+		registeredChan <- os.Interrupt
+
+		Eventually(ctx.Done()).Should(BeClosed())
+		Expect(<-stoppedCh).To(Equal(registeredChan))
+		Expect(ui.Errors).To(ContainElement(ContainSubstring("press Ctrl-C again to exit immediately")))
+	})
+
+	It("stops catching signals once stopped", func() {
+		ctx, stop := NewInterruptContext(ui, signalNotify, signalStop)
+
+		registeredChan = <-notifiedCh
+
+		stop()
+
+		Expect(<-stoppedCh).To(Equal(registeredChan))
+		Expect(ctx.Done()).To(BeClosed())
+		Expect(ui.Errors).To(BeEmpty())
+	})
+})
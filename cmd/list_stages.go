@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"encoding/json"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+)
+
+// EnvStage describes a stage emitted through boshui.Stage by create-env or delete-env.
+// Complex stages contain sub-stages; Repeated stages may be emitted zero or more times
+// (e.g. once per release or unused stemcell); Optional stages are only emitted when
+// requested by a flag.
+type EnvStage struct {
+	Name     string     `json:"name"`
+	Complex  bool       `json:"complex,omitempty"`
+	Repeated bool       `json:"repeated,omitempty"`
+	Optional bool       `json:"optional,omitempty"`
+	Stages   []EnvStage `json:"stages,omitempty"`
+}
+
+var (
+	validatingEnvStages = []EnvStage{
+		{Name: "Downloading <release-source>", Repeated: true},
+		{Name: "Validating release '<release-name>'", Repeated: true},
+		{Name: "Validating cpi release"},
+	}
+
+	installingCPIEnvStage = EnvStage{
+		Name:    "installing CPI",
+		Complex: true,
+		Stages: []EnvStage{
+			{Name: "Compiling package '<package-name>/<fingerprint>'", Repeated: true},
+			{Name: "Installing packages"},
+			{Name: "Rendering job templates"},
+			{Name: "Installing job '<job-name>'", Repeated: true},
+		},
+	}
+
+	deployingEnvStages = []EnvStage{
+		{Name: "Waiting for the agent on VM '<vm-cid>'", Repeated: true},
+		{Name: "Stopping jobs on instance '<instance>'", Repeated: true},
+		{Name: "Unmounting disk '<disk-cid>'", Repeated: true},
+		{Name: "Deleting VM '<vm-cid>'", Repeated: true},
+		{Name: "Creating VM for instance '<instance>' from stemcell '<stemcell-cid>'"},
+		{Name: "Waiting for the agent on VM '<vm-cid>' to be ready"},
+		{Name: "Creating disk", Repeated: true},
+		{Name: "Attaching disk '<disk-cid>' to VM '<vm-cid>'", Repeated: true},
+		{Name: "Migrating disk content from '<disk-cid>' to '<disk-cid>'", Repeated: true},
+		{Name: "Detaching disk '<disk-cid>'", Repeated: true},
+		{Name: "Deleting disk '<disk-cid>'", Repeated: true},
+		{Name: "Deleting disk '<disk-cid>' of interrupted migration", Repeated: true},
+		{Name: "Rendering job templates"},
+		{Name: "Compiling package '<package-name>/<fingerprint>'", Repeated: true},
+		{Name: "Updating instance '<instance>'"},
+		{Name: "Running the pre-start scripts '<instance>'"},
+		{Name: "Starting jobs on instance '<instance>'"},
+		{Name: "Waiting for instance '<instance>' to be running"},
+		{Name: "Running the post-start scripts '<instance>'"},
+	}
+
+	CreateEnvStages = []EnvStage{
+		{
+			Name:    "validating",
+			Complex: true,
+			Stages: append(validatingEnvStages,
+				EnvStage{Name: "Validating deployment manifest"},
+				EnvStage{Name: "Validating stemcell"},
+			),
+		},
+		installingCPIEnvStage,
+		{Name: "Starting registry"},
+		// skipped with --skip-preflight
+		{Name: "Validating CPI connectivity"},
+		{Name: "Uploading stemcell '<stemcell-name>/<stemcell-version>'"},
+		{Name: "deploying", Complex: true, Stages: deployingEnvStages},
+		// emitted instead of "deploying" when --recreate is passed
+		{Name: "recreating VM", Complex: true, Optional: true, Stages: deployingEnvStages},
+		{Name: "Stopping registry"},
+		{Name: "Pruning compiled packages", Optional: true},
+		{Name: "Cleaning up rendered CPI jobs"},
+		{Name: "Deleting unused stemcell '<stemcell-cid>'", Repeated: true},
+	}
+
+	DeleteEnvStages = []EnvStage{
+		{
+			Name:    "validating",
+			Complex: true,
+			Stages:  validatingEnvStages,
+		},
+		installingCPIEnvStage,
+		{Name: "Starting registry"},
+		{
+			Name:    "deleting deployment",
+			Complex: true,
+			Stages: []EnvStage{
+				{Name: "Waiting for the agent on VM '<vm-cid>'"},
+				{Name: "Stopping jobs on instance '<instance>'"},
+				{Name: "Unmounting disk '<disk-cid>'", Repeated: true},
+				{Name: "Deleting VM '<vm-cid>'"},
+				{Name: "Deleting disk '<disk-cid>'", Repeated: true},
+				{Name: "Deleting stemcell '<stemcell-cid>'", Repeated: true},
+				{Name: "Retrying stemcell delete '<stemcell-cid>' (attempt <attempt>/<attempts>)", Repeated: true},
+			},
+		},
+		{Name: "Deleting unused disk '<disk-cid>'", Repeated: true},
+		{Name: "Deleting unused stemcell '<stemcell-cid>'", Repeated: true},
+		{Name: "Uninstalling local artifacts for CPI and deployment"},
+		{Name: "Stopping registry"},
+		{Name: "Cleaning up rendered CPI jobs"},
+	}
+)
+
+type ListStagesCmd struct {
+	ui boshui.UI
+}
+
+func NewListStagesCmd(ui boshui.UI) ListStagesCmd {
+	return ListStagesCmd{ui: ui}
+}
+
+func (c ListStagesCmd) Run(opts ListStagesOpts) error {
+	var stages []EnvStage
+
+	switch opts.Args.Command {
+	case "create-env":
+		stages = CreateEnvStages
+	case "delete-env":
+		stages = DeleteEnvStages
+	default:
+		return bosherr.Errorf("Expected command to be 'create-env' or 'delete-env' but was '%s'", opts.Args.Command)
+	}
+
+	bytes, err := json.MarshalIndent(stages, "", "    ")
+	if err != nil {
+		return bosherr.WrapError(err, "Marshaling stages")
+	}
+
+	c.ui.PrintBlock(append(bytes, '\n'))
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+)
+
+var _ = Describe("ListStagesCmd", func() {
+	var (
+		ui      *fakeui.FakeUI
+		command ListStagesCmd
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		command = NewListStagesCmd(ui)
+	})
+
+	Describe("Run", func() {
+		var (
+			opts ListStagesOpts
+		)
+
+		BeforeEach(func() {
+			opts = ListStagesOpts{}
+		})
+
+		act := func() error { return command.Run(opts) }
+
+		topLevelNames := func() []string {
+			Expect(ui.Blocks).To(HaveLen(1))
+
+			var stages []EnvStage
+			Expect(json.Unmarshal([]byte(ui.Blocks[0]), &stages)).To(Succeed())
+
+			var names []string
+			for _, stage := range stages {
+				names = append(names, stage.Name)
+			}
+			return names
+		}
+
+		It("prints create-env stages in order as JSON", func() {
+			opts.Args.Command = "create-env"
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(topLevelNames()).To(Equal([]string{
+				"validating",
+				"installing CPI",
+				"Starting registry",
+				"Validating CPI connectivity",
+				"Uploading stemcell '<stemcell-name>/<stemcell-version>'",
+				"deploying",
+				"recreating VM",
+				"Stopping registry",
+				"Pruning compiled packages",
+				"Cleaning up rendered CPI jobs",
+				"Deleting unused stemcell '<stemcell-cid>'",
+			}))
+		})
+
+		It("prints delete-env stages in order as JSON", func() {
+			opts.Args.Command = "delete-env"
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(topLevelNames()).To(Equal([]string{
+				"validating",
+				"installing CPI",
+				"Starting registry",
+				"deleting deployment",
+				"Deleting unused disk '<disk-cid>'",
+				"Deleting unused stemcell '<stemcell-cid>'",
+				"Uninstalling local artifacts for CPI and deployment",
+				"Stopping registry",
+				"Cleaning up rendered CPI jobs",
+			}))
+		})
+
+		It("includes sub-stages of complex stages", func() {
+			opts.Args.Command = "create-env"
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Blocks[0]).To(ContainSubstring(`"name": "Validating deployment manifest"`))
+			Expect(ui.Blocks[0]).To(ContainSubstring(`"complex": true`))
+		})
+
+		It("lists every stage performed by create-env and delete-env", func() {
+			var listedNames []string
+
+			var collectNames func([]EnvStage)
+			collectNames = func(stages []EnvStage) {
+				for _, stage := range stages {
+					listedNames = append(listedNames, stage.Name)
+					collectNames(stage.Stages)
+				}
+			}
+			collectNames(CreateEnvStages)
+			collectNames(DeleteEnvStages)
+
+			stageNameRegexps := []*regexp.Regexp{
+				regexp.MustCompile(`\.Perform(?:Complex)?\((?:fmt\.Sprintf\()?"([^"]+)"`),
+				regexp.MustCompile(`(?:stepName|stageName|deployStageName)\s*:?=\s*(?:fmt\.Sprintf\()?"([^"]+)"`),
+			}
+
+			// Stages of other commands
+			ignoredPaths := map[string]bool{
+				filepath.Join("..", "cmd", "env_cloud_checker.go"): true,
+			}
+
+			var performedNames []string
+
+			err := filepath.Walk("..", func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+
+				if info.IsDir() {
+					if info.Name() == "vendor" || info.Name() == "integration" || info.Name() == "acceptance" {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+
+				if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") || ignoredPaths[path] {
+					return nil
+				}
+
+				contents, err := ioutil.ReadFile(path)
+				if err != nil {
+					return err
+				}
+
+				for _, re := range stageNameRegexps {
+					for _, match := range re.FindAllStringSubmatch(string(contents), -1) {
+						performedNames = append(performedNames, match[1])
+					}
+				}
+
+				return nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(performedNames).ToNot(BeEmpty())
+
+			// Format verbs stand for the <placeholders> of listed names, e.g. '%s/%d' for '<instance>'
+			verbs := regexp.MustCompile(`%[sdvq](?:/%[sdvq])*`)
+
+			for _, name := range performedNames {
+				pattern := "^" + strings.Join(strings.Split(regexp.QuoteMeta(verbs.ReplaceAllString(name, "\x00")), "\x00"), ".+") + "$"
+				Expect(listedNames).To(ContainElement(MatchRegexp(pattern)), "stage '%s' is not listed", name)
+			}
+		})
+
+		It("returns an error for other commands", func() {
+			opts.Args.Command = "deploy"
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected command to be 'create-env' or 'delete-env' but was 'deploy'"))
+			Expect(ui.Blocks).To(BeEmpty())
+		})
+	})
+})
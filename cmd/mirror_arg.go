@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	bitarball "github.com/cloudfoundry/bosh-cli/installation/tarball"
+)
+
+// MirrorArg makes tarballs whose URL starts with URL also be downloaded from MirrorURL,
+// e.g. 'https://bosh.io/d/=https://mirror.example.com/bosh.io/'.
+// Mirrors are tried in the order given after the original URL.
+type MirrorArg struct {
+	URL       string
+	MirrorURL string
+}
+
+func (a *MirrorArg) UnmarshalFlag(data string) error {
+	pieces := strings.SplitN(data, "=", 2)
+	if len(pieces) != 2 || len(pieces[0]) == 0 || len(pieces[1]) == 0 {
+		return bosherr.Errorf("Expected mirror '%s' to be in format 'url=mirror_url'", data)
+	}
+
+	for _, piece := range pieces {
+		if !strings.HasPrefix(piece, "http://") && !strings.HasPrefix(piece, "https://") {
+			return bosherr.Errorf("Expected mirror '%s' to only have http(s) URLs", data)
+		}
+	}
+
+	*a = MirrorArg{URL: pieces[0], MirrorURL: pieces[1]}
+
+	return nil
+}
+
+// NewTarballMirrors returns the mirrors tried when downloading release and stemcell tarballs
+func NewTarballMirrors(args []MirrorArg) []bitarball.Mirror {
+	var mirrors []bitarball.Mirror
+
+	for _, arg := range args {
+		mirrors = append(mirrors, bitarball.Mirror{URL: arg.URL, MirrorURL: arg.MirrorURL})
+	}
+
+	return mirrors
+}
@@ -0,0 +1,53 @@
+package cmd_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	bitarball "github.com/cloudfoundry/bosh-cli/installation/tarball"
+)
+
+var _ = Describe("MirrorArg", func() {
+	Describe("UnmarshalFlag", func() {
+		var (
+			arg *MirrorArg
+		)
+
+		BeforeEach(func() {
+			arg = &MirrorArg{}
+		})
+
+		It("sets URL and mirror URL", func() {
+			err := arg.UnmarshalFlag("https://bosh.io/d/=https://mirror.example.com/bosh.io/?a=1,2")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*arg).To(Equal(MirrorArg{URL: "https://bosh.io/d/", MirrorURL: "https://mirror.example.com/bosh.io/?a=1,2"}))
+		})
+
+		It("returns an error if value is not in the url=mirror_url format", func() {
+			err := arg.UnmarshalFlag("https://bosh.io/d/")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected mirror 'https://bosh.io/d/' to be in format 'url=mirror_url'"))
+		})
+
+		It("returns an error if either URL is not an http(s) URL", func() {
+			err := arg.UnmarshalFlag("https://bosh.io/d/=file:///mirror/")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected mirror 'https://bosh.io/d/=file:///mirror/' to only have http(s) URLs"))
+		})
+	})
+
+	Describe("NewTarballMirrors", func() {
+		It("keeps the order of the mirrors", func() {
+			mirrors := NewTarballMirrors([]MirrorArg{
+				{URL: "https://bosh.io/d/", MirrorURL: "https://mirror1.example.com/"},
+				{URL: "https://bosh.io/d/", MirrorURL: "https://mirror2.example.com/"},
+			})
+
+			Expect(mirrors).To(Equal([]bitarball.Mirror{
+				{URL: "https://bosh.io/d/", MirrorURL: "https://mirror1.example.com/"},
+				{URL: "https://bosh.io/d/", MirrorURL: "https://mirror2.example.com/"},
+			}))
+		})
+	})
+})
@@ -29,12 +29,12 @@ func (_m *MockDeploymentDeleter) EXPECT() *_MockDeploymentDeleterRecorder {
 	return _m.recorder
 }
 
-func (_m *MockDeploymentDeleter) DeleteDeployment(_param0 ui.Stage) error {
-	ret := _m.ctrl.Call(_m, "DeleteDeployment", _param0)
+func (_m *MockDeploymentDeleter) DeleteDeployment(_param0 ui.Stage, _param1 bool) error {
+	ret := _m.ctrl.Call(_m, "DeleteDeployment", _param0, _param1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-func (_mr *_MockDeploymentDeleterRecorder) DeleteDeployment(arg0 interface{}) *gomock.Call {
-	return _mr.mock.ctrl.RecordCall(_mr.mock, "DeleteDeployment", arg0)
+func (_mr *_MockDeploymentDeleterRecorder) DeleteDeployment(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "DeleteDeployment", arg0, arg1)
 }
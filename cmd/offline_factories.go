@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+
+	boshblob "github.com/cloudfoundry/bosh-utils/blobstore"
+
+	biindex "github.com/cloudfoundry/bosh-cli/index"
+	"github.com/cloudfoundry/bosh-cli/offline"
+)
+
+// offlineInstallationBlobstore refuses to transfer blobs to or from a remote installation blobstore
+type offlineInstallationBlobstore struct {
+	blobstore boshblob.Blobstore
+	guard     *offline.Guard
+}
+
+func (b offlineInstallationBlobstore) Get(blobID string) (string, error) {
+	return "", b.guard.Forbid(fmt.Sprintf("installation blobstore to download blob '%s'", blobID))
+}
+
+func (b offlineInstallationBlobstore) Create(_ string) (string, error) {
+	return "", b.guard.Forbid("installation blobstore to upload a blob")
+}
+
+func (b offlineInstallationBlobstore) Delete(blobID string) error {
+	return b.guard.Forbid(fmt.Sprintf("installation blobstore to delete blob '%s'", blobID))
+}
+
+// CleanUp only removes a local file
+func (b offlineInstallationBlobstore) CleanUp(path string) error {
+	return b.blobstore.CleanUp(path)
+}
+
+func (b offlineInstallationBlobstore) Validate() error {
+	return nil
+}
+
+var _ boshblob.Blobstore = offlineInstallationBlobstore{}
+
+// offlineIndexStore refuses to transfer the compiled package index kept next to the blobs of a remote installation blobstore
+type offlineIndexStore struct {
+	guard *offline.Guard
+}
+
+func (s offlineIndexStore) Download(name string, _ string) (bool, error) {
+	return false, s.guard.Forbid(fmt.Sprintf("installation blobstore to download '%s'", name))
+}
+
+func (s offlineIndexStore) Upload(name string, _ string) error {
+	return s.guard.Forbid(fmt.Sprintf("installation blobstore to upload '%s'", name))
+}
+
+var _ biindex.FileStore = offlineIndexStore{}
@@ -40,6 +40,14 @@ var _ = Describe("OpsFileArg", func() {
 			}))
 		})
 
+		It("remembers the path of the file", func() {
+			fs.WriteFileString("/some/path", "[]")
+
+			err := (&arg).UnmarshalFlag("/some/path")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(arg.Path).To(Equal("/some/path"))
+		})
+
 		It("returns an error if operations are not valid", func() {
 			fs.WriteFileString("/some/path", "- type: unknown")
 
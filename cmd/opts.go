@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"time"
+
 	boshuuid "github.com/cloudfoundry/bosh-utils/uuid"
 	"github.com/cppforlife/go-patch/patch"
 
@@ -19,6 +21,8 @@ type BoshOpts struct {
 	CACertOpt      CACertArg `long:"ca-cert"               description:"Director CA certificate path or value" env:"BOSH_CA_CERT"`
 	Sha2           bool      `long:"sha2"                  description:"Use SHA256 checksums" env:"BOSH_SHA2"`
 	Parallel       int       `long:"parallel" description:"The max number of parallel operations" default:"5"`
+	ReadOnlyOpt    bool      `long:"read-only" description:"Refuse commands that change director, cloud or local state" env:"BOSH_READ_ONLY"`
+	TmpDirOpt      string    `long:"tmp-dir"   description:"Directory for temporary files such as extracted stemcells and compiled packages (default: ~/.bosh/tmp)" env:"BOSH_TMP_DIR"`
 
 	// Hidden
 	UsernameOpt string `long:"user" hidden:"true" env:"BOSH_USER"`
@@ -32,6 +36,7 @@ type BoshOpts struct {
 	// Output formatting
 	ColumnOpt         []ColumnOpt `long:"column"                    description:"Filter to show only given column(s)"`
 	JSONOpt           bool        `long:"json"                      description:"Output as JSON"`
+	SchemaOpt         bool        `long:"schema"                    description:"Show the JSON schema of the command's output instead of running it"`
 	TTYOpt            bool        `long:"tty"                       description:"Force TTY-like output"`
 	NoColorOpt        bool        `long:"no-color"                  description:"Toggle colorized output"`
 	NonInteractiveOpt bool        `long:"non-interactive" short:"n" description:"Don't ask for user input" env:"BOSH_NON_INTERACTIVE"`
@@ -41,11 +46,19 @@ type BoshOpts struct {
 	// -----> Director management
 
 	// Environments
-	Environment  EnvironmentOpts  `command:"environment"  alias:"env"  description:"Show environment"`
-	Environments EnvironmentsOpts `command:"environments" alias:"envs" description:"List environments"`
-	CreateEnv    CreateEnvOpts    `command:"create-env"                description:"Create or update BOSH environment"`
-	DeleteEnv    DeleteEnvOpts    `command:"delete-env"                description:"Delete BOSH environment"`
-	AliasEnv     AliasEnvOpts     `command:"alias-env"                 description:"Alias environment to save URL and CA certificate"`
+	Environment      EnvironmentOpts      `command:"environment"  alias:"env"  description:"Show environment"`
+	Environments     EnvironmentsOpts     `command:"environments" alias:"envs" description:"List environments"`
+	CreateEnv        CreateEnvOpts        `command:"create-env"                description:"Create or update BOSH environment"`
+	DeleteEnv        DeleteEnvOpts        `command:"delete-env"                description:"Delete BOSH environment"`
+	EnvInstances     EnvInstancesOpts     `command:"env-instances"             description:"List instances of an environment created with create-env and whether their agents respond"`
+	EnvDisks         EnvDisksOpts         `command:"env-disks"                 description:"List persistent disks of an environment created with create-env and delete orphaned ones"`
+	EnvInfo          EnvInfoOpts          `command:"env-info"                  description:"Show the deployment, stemcell, CPI release and address of an environment created with create-env"`
+	EnvLogs          EnvLogsOpts          `command:"env-logs"                  description:"Fetch job or agent logs from the VM of an environment created with create-env"`
+	EnvCloudCheck    EnvCloudCheckOpts    `command:"env-cloud-check" alias:"env-cck" description:"Find and resolve differences between the state file of an environment created with create-env and the IaaS"`
+	AliasEnv         AliasEnvOpts         `command:"alias-env"                 description:"Alias environment to save URL and CA certificate"`
+	ListStages       ListStagesOpts       `command:"list-stages"               description:"List stages emitted by create-env or delete-env"`
+	ReplayEvents     ReplayEventsOpts     `command:"replay-events"             description:"Show the stages and warnings saved in an event log"`
+	ValidateManifest ValidateManifestOpts `command:"validate-manifest"         description:"Validate a create-env manifest without deploying it"`
 
 	// Authentication
 	LogIn  LogInOpts  `command:"log-in"  alias:"l" alias:"login"  description:"Log in"`
@@ -86,6 +99,7 @@ type BoshOpts struct {
 
 	Deploy   DeployOpts   `command:"deploy"   alias:"d"   description:"Update deployment"`
 	Manifest ManifestOpts `command:"manifest" alias:"man" description:"Show deployment manifest"`
+	Diff     DiffOpts     `command:"diff"                 description:"Compare deployment manifest with a new one"`
 
 	Interpolate InterpolateOpts `command:"interpolate" alias:"int" description:"Interpolates variables into a manifest"`
 
@@ -152,6 +166,7 @@ type BoshOpts struct {
 	GeneratePackage GeneratePackageOpts `command:"generate-package"            description:"Generate package"`
 	CreateRelease   CreateReleaseOpts   `command:"create-release"   alias:"cr" description:"Create release"`
 	VendorPackage   VendorPackageOpts   `command:"vendor-package"              description:"Vendor package"`
+	DiffReleases    DiffReleasesOpts    `command:"diff-releases"               description:"Compare jobs and packages of two release tarballs"`
 
 	// Hidden
 	Sha1ifyRelease  Sha1ifyReleaseOpts  `command:"sha1ify-release"  hidden:"true" description:"Convert release tarball to use SHA1"`
@@ -178,26 +193,178 @@ type CreateEnvOpts struct {
 	Args CreateEnvArgs `positional-args:"true" required:"true"`
 	VarFlags
 	OpsFlags
-	StatePath               string `long:"state" value-name:"PATH" description:"State file path"`
-	Recreate                bool   `long:"recreate" description:"Recreate VM in deployment"`
-	RecreatePersistentDisks bool   `long:"recreate-persistent-disks" description:"Recreate persistent disks in the deployment"`
+	StatePath                     string                       `long:"state" value-name:"PATH" description:"State file path"`
+	Recreate                      bool                         `long:"recreate" description:"Recreate VM in deployment"`
+	RecreatePersistentDisks       bool                         `long:"recreate-persistent-disks" description:"Recreate persistent disks in the deployment"`
+	Reextract                     bool                         `long:"reextract" description:"Extract the stemcell again instead of reusing the extraction cached by a previous run"`
+	Rerender                      bool                         `long:"rerender" description:"Render job templates again instead of reusing renderings cached by a previous run"`
+	PruneCompiled                 bool                         `long:"prune-compiled" description:"Prune compiled packages no longer used by the deployment"`
+	PrintManifest                 bool                         `long:"print-manifest" description:"Print fully resolved manifest and exit without deploying"`
+	DryRun                        bool                         `long:"dry-run" description:"Validate the manifest and print the planned stemcell, VM and disk changes without installing the CPI or writing the state file"`
+	NoRedact                      bool                         `long:"no-redact" description:"Show non-redacted variable values when printing manifest"`
+	CompiledPackageIndex          string                       `long:"compiled-package-index" value-name:"PATH" description:"Compiled package index path, with compiled packages kept in a blobs directory next to it (default: inside the installation workspace)"`
+	CompiledPackageCache          string                       `long:"compiled-package-cache" value-name:"DIR" description:"Directory keeping compiled packages so that they are reused by other environments using the same cache"`
+	CloudPropertiesOverrides      []CloudPropertiesOverrideArg `long:"resource-pool-cloud-properties" value-name:"NAME=HASH" description:"Override cloud properties of a resource pool (can be specified multiple times)"`
+	RecordCPI                     string                       `long:"record-cpi" value-name:"PATH" description:"Record CPI requests and responses to a file, with secrets redacted"`
+	ReplayCPI                     string                       `long:"replay-cpi" value-name:"PATH" description:"Replay CPI responses from a recording instead of running the CPI"`
+	CPIAPIVersion                 int                          `long:"cpi-api-version" value-name:"VERSION" description:"CPI API version to make requests with, overriding cloud_provider.cpi_api_version (default: 1)"`
+	Retries                       int                          `long:"retries" value-name:"N" description:"Retry CPI calls that are safe to repeat, such as has_vm, and agent blobstore downloads and uploads up to N times after transient errors" default:"3"`
+	RetryDelay                    time.Duration                `long:"retry-delay" value-name:"DURATION" description:"Delay before the first retry of a CPI call or blobstore transfer, doubled after each retry up to 30s" default:"1s"`
+	CPITimeouts                   []CPITimeoutArg              `long:"cpi-timeout" value-name:"METHOD=DURATION" description:"Override the timeout of a CPI method, or of methods without their own timeout with 'default'; 0 disables it (can be specified multiple times)"`
+	Mirrors                       []MirrorArg                  `long:"mirror" value-name:"URL=MIRROR_URL" description:"Download release and stemcell tarballs whose URL starts with URL from MIRROR_URL when the original URL fails, in the given order (can be specified multiple times)"`
+	WarningsAsErrors              bool                         `long:"warnings-as-errors" description:"Fail when validating or deploying raises warnings"`
+	AdvertisedRegistryEndpoint    string                       `long:"advertised-registry-endpoint" value-name:"URL" description:"Registry URL the agent is told to connect to (default: the registry bind address)"`
+	ProbeAgent                    bool                         `long:"probe-agent" description:"Check that the agent is compatible with the stemcell before applying jobs"`
+	Tags                          []TagArg                     `long:"tag" value-name:"KEY=VALUE" description:"Tag the VM and disks, overriding manifest tags with the same key; tags are added to their cloud properties and recorded in the state file; multiple allowed"`
+	TagStemcell                   bool                         `long:"tag-stemcell" description:"Add the manifest tags to the 'tags' cloud property of the uploaded stemcell, for CPIs that tag stemcells from it"`
+	SkipPreflight                 bool                         `long:"skip-preflight" description:"Do not ask the CPI to validate its IaaS configuration before uploading the stemcell"`
+	EventLog                      string                       `long:"event-log" value-name:"PATH" description:"Write stages, timings and warnings to a compressed event log, with secrets redacted"`
+	AllowDowngrade                bool                         `long:"allow-downgrade" description:"Allow deploying releases older than the currently deployed versions"`
+	Production                    bool                         `long:"production" description:"Mark the environment as production in the state file, so that delete-env requires typing its name to confirm"`
+	StreamCompileLogs             bool                         `long:"stream-compile-logs" description:"Show the output of packaging scripts while compiling packages, prefixed with the package name"`
+	DeterministicCompiledPackages bool                         `long:"deterministic-compiled-packages" description:"Create compiled package archives with normalized timestamps, modes and ownership so that the same package always has the same SHA"`
+	Offline                       bool                         `long:"offline" description:"Fail instead of downloading releases, stemcells or blobs over the network, listing what needed it; the CPI and the agent are still reached"`
+	Watch                         bool                         `long:"watch" description:"Run again whenever the manifest, files it includes, vars files or ops files change, until interrupted"`
+	Workers                       int                          `long:"workers" value-name:"N" description:"Download and extract up to N releases and compile up to N packages at a time; progress of single downloads is only shown with one (default: number of CPUs)"`
+	ExportArtifact                string                       `long:"export-artifact" value-name:"PATH" description:"Write endpoints, CIDs and credentials of the deployed environment to a file readable only by the current user"`
+	ExportArtifactFormat          string                       `long:"export-artifact-format" value-name:"FORMAT" description:"Format of the exported artifact: 'json' or 'yaml'" default:"yaml"`
+	AgentPollInterval             PositiveDurationArg          `long:"agent-poll-interval" value-name:"DURATION" description:"Interval between checks of long running agent tasks" default:"1s"`
+	BlobstorePartSize             int64                        `long:"blobstore-part-size" value-name:"BYTES" description:"Upload blobs to the agent blobstore in parts of this size, resending only parts that failed; the blobstore must accept ranged PUT requests (default: upload at once)"`
+	AgentReadyTimeout             PositiveDurationArg          `long:"agent-ready-timeout" value-name:"DURATION" description:"Abort when the agent of an instance does not become ready within this duration" default:"10m"`
+	ForceUnlock                   bool                         `long:"force-unlock" description:"Take over the lock of the state file even if the process holding it is still running"`
 	cmd
 }
 
 type CreateEnvArgs struct {
-	Manifest FileBytesWithPathArg `positional-arg-name:"PATH" description:"Path to a manifest file"`
+	Manifest EnvManifestArg `positional-arg-name:"PATH" description:"Path to a manifest file, or '-' to read it from stdin"`
 }
 
 type DeleteEnvOpts struct {
 	Args DeleteEnvArgs `positional-args:"true" required:"true"`
 	VarFlags
 	OpsFlags
-	StatePath string `long:"state" value-name:"PATH" description:"State file path"`
+	StatePath            string          `long:"state" value-name:"PATH" description:"State file path"`
+	CompiledPackageIndex string          `long:"compiled-package-index" value-name:"PATH" description:"Compiled package index path, with compiled packages kept in a blobs directory next to it (default: inside the installation workspace)"`
+	CompiledPackageCache string          `long:"compiled-package-cache" value-name:"DIR" description:"Directory keeping compiled packages so that they are reused by other environments using the same cache"`
+	ConfirmDestroy       string          `long:"confirm-destroy" value-name:"NAME" description:"Deployment name confirming deletion instead of typing it, required for an environment marked as production when not interactive"`
+	Yes                  bool            `long:"yes" description:"Skip the deletion confirmation, including for an environment marked as production"`
+	Force                bool            `long:"force" description:"Continue deleting the remaining resources when one cannot be deleted, failing at the end"`
+	RecordCPI            string          `long:"record-cpi" value-name:"PATH" description:"Record CPI requests and responses to a file, with secrets redacted"`
+	ReplayCPI            string          `long:"replay-cpi" value-name:"PATH" description:"Replay CPI responses from a recording instead of running the CPI"`
+	CPIAPIVersion        int             `long:"cpi-api-version" value-name:"VERSION" description:"CPI API version to make requests with, overriding cloud_provider.cpi_api_version (default: 1)"`
+	Retries              int             `long:"retries" value-name:"N" description:"Retry CPI calls that are safe to repeat, such as has_vm, and agent blobstore downloads and uploads up to N times after transient errors" default:"3"`
+	RetryDelay           time.Duration   `long:"retry-delay" value-name:"DURATION" description:"Delay before the first retry of a CPI call or blobstore transfer, doubled after each retry up to 30s" default:"1s"`
+	CPITimeouts          []CPITimeoutArg `long:"cpi-timeout" value-name:"METHOD=DURATION" description:"Override the timeout of a CPI method, or of methods without their own timeout with 'default'; 0 disables it (can be specified multiple times)"`
+	Mirrors              []MirrorArg     `long:"mirror" value-name:"URL=MIRROR_URL" description:"Download release and stemcell tarballs whose URL starts with URL from MIRROR_URL when the original URL fails, in the given order (can be specified multiple times)"`
+	EventLog             string          `long:"event-log" value-name:"PATH" description:"Write stages, timings and warnings to a compressed event log, with secrets redacted"`
+	Offline              bool            `long:"offline" description:"Fail instead of downloading releases, stemcells or blobs over the network, listing what needed it; the CPI and the agent are still reached"`
+	ForceUnlock          bool            `long:"force-unlock" description:"Take over the lock of the state file even if the process holding it is still running"`
 	cmd
 }
 
 type DeleteEnvArgs struct {
-	Manifest FileBytesWithPathArg `positional-arg-name:"PATH" description:"Path to a manifest file"`
+	Manifest EnvManifestArg `positional-arg-name:"PATH" description:"Path to a manifest file, or '-' to read it from stdin"`
+}
+
+type EnvInstancesOpts struct {
+	Args EnvInstancesArgs `positional-args:"true" required:"true"`
+	VarFlags
+	OpsFlags
+	StatePath string `long:"state" value-name:"PATH" description:"State file path"`
+	cmd
+}
+
+type EnvInstancesArgs struct {
+	Manifest EnvManifestArg `positional-arg-name:"PATH" description:"Path to a manifest file, or '-' to read it from stdin"`
+}
+
+type EnvDisksOpts struct {
+	Args EnvDisksArgs `positional-args:"true" required:"true"`
+	VarFlags
+	OpsFlags
+	StatePath   string `long:"state"        value-name:"PATH" description:"State file path"`
+	Orphaned    bool   `long:"orphaned"                       description:"List only disks that are not the current persistent disk"`
+	Delete      bool   `long:"delete"                         description:"Delete orphaned disks after confirming, requires --orphaned"`
+	ForceUnlock bool   `long:"force-unlock"                   description:"Take over the lock of the state file even if the process holding it is still running"`
+	cmd
+}
+
+type EnvDisksArgs struct {
+	Manifest EnvManifestArg `positional-arg-name:"PATH" description:"Path to a manifest file, or '-' to read it from stdin"`
+}
+
+type EnvCloudCheckOpts struct {
+	Args EnvCloudCheckArgs `positional-args:"true" required:"true"`
+	VarFlags
+	OpsFlags
+	StatePath   string   `long:"state"      value-name:"PATH" description:"State file path"`
+	Resolutions []string `long:"resolution"                   description:"Apply resolution of given type, or 'auto' to apply the default resolution of each problem"`
+	Report      bool     `long:"report"     short:"r"         description:"Only generate report; don't attempt to resolve problems"`
+	ForceUnlock bool     `long:"force-unlock"                 description:"Take over the lock of the state file even if the process holding it is still running"`
+	cmd
+}
+
+type EnvCloudCheckArgs struct {
+	Manifest EnvManifestArg `positional-arg-name:"PATH" description:"Path to a manifest file, or '-' to read it from stdin"`
+}
+
+type EnvInfoOpts struct {
+	Args EnvInfoArgs `positional-args:"true" required:"true"`
+	VarFlags
+	OpsFlags
+	StatePath string `long:"state" value-name:"PATH" description:"State file path"`
+	cmd
+}
+
+type EnvInfoArgs struct {
+	Manifest EnvManifestArg `positional-arg-name:"PATH" description:"Path to a manifest file, or '-' to read it from stdin"`
+}
+
+type EnvLogsOpts struct {
+	Args EnvLogsArgs `positional-args:"true" required:"true"`
+	VarFlags
+	OpsFlags
+	StatePath string      `long:"state" value-name:"PATH" description:"State file path"`
+	Directory DirOrCWDArg `long:"dir" description:"Destination directory" default:"."`
+
+	Jobs  []string `long:"job"   description:"Limit to only specific jobs"`
+	Agent bool     `long:"agent" description:"Include only agent logs"`
+	cmd
+}
+
+type EnvLogsArgs struct {
+	Manifest EnvManifestArg `positional-arg-name:"PATH" description:"Path to a manifest file, or '-' to read it from stdin"`
+}
+
+type ListStagesOpts struct {
+	Args ListStagesArgs `positional-args:"true" required:"true"`
+	cmd
+}
+
+type ListStagesArgs struct {
+	Command string `positional-arg-name:"COMMAND" description:"Command name (create-env or delete-env)"`
+}
+
+type ReplayEventsOpts struct {
+	Args ReplayEventsArgs `positional-args:"true" required:"true"`
+	cmd
+}
+
+type ReplayEventsArgs struct {
+	Path string `positional-arg-name:"PATH" description:"Path to an event log written with --event-log"`
+}
+
+type ValidateManifestOpts struct {
+	Args ValidateManifestArgs `positional-args:"true" required:"true"`
+	VarFlags
+	OpsFlags
+	Releases []FileArg `long:"release"  value-name:"PATH" description:"Path to a release tarball to check jobs against (can be specified multiple times)"`
+	Stemcell FileArg   `long:"stemcell" value-name:"PATH" description:"Path to a stemcell tarball to check jobs against, requires --release"`
+	cmd
+}
+
+type ValidateManifestArgs struct {
+	Manifest EnvManifestArg `positional-arg-name:"PATH" description:"Path to a manifest file, or '-' to read it from stdin"`
 }
 
 // Environment
@@ -294,7 +461,8 @@ type InterpolateOpts struct {
 	OpsFlags
 
 	Path            patch.Pointer `long:"path" value-name:"OP-PATH" description:"Extract value out of template (e.g.: /private_key)"`
-	VarErrors       bool          `long:"var-errs"                  description:"Expect all variables to be found, otherwise error"`
+	VarErrors       bool          `long:"var-errs"                  description:"Expect all variables to be found, otherwise error (default)"`
+	NoVarErrors     bool          `long:"no-var-errs"               description:"Leave variables that are not found in the template instead of failing, e.g. to interpolate in several passes"`
 	VarErrorsUnused bool          `long:"var-errs-unused"           description:"Expect all variables to be used, otherwise error"`
 
 	cmd
@@ -433,7 +601,8 @@ type DeployOpts struct {
 	VarFlags
 	OpsFlags
 
-	NoRedact bool `long:"no-redact" description:"Show non-redacted manifest diff"`
+	NoRedact    bool `long:"no-redact" description:"Show non-redacted manifest diff"`
+	DiffContext bool `long:"diff-context" description:"Show changed secret values in the manifest diff as '[secret changed]'"`
 
 	Recreate                bool                `long:"recreate"                                description:"Recreate all VMs in deployment"`
 	RecreatePersistentDisks bool                `long:"recreate-persistent-disks"               description:"Recreate all persistent disks in deployment"`
@@ -445,6 +614,10 @@ type DeployOpts struct {
 
 	DryRun bool `long:"dry-run" description:"Renders job templates without altering deployment"`
 
+	VarErrorsUnused bool `long:"var-errs-unused" description:"Expect all variables to be used, otherwise error"`
+
+	Tags []TagArg `long:"tag" value-name:"KEY=VALUE" description:"Tag VMs and disks of the deployment, overriding manifest tags with the same key; multiple allowed"`
+
 	cmd
 }
 
@@ -452,6 +625,21 @@ type DeployArgs struct {
 	Manifest FileBytesArg `positional-arg-name:"PATH" description:"Path to a manifest file"`
 }
 
+type DiffOpts struct {
+	Args DiffArgs `positional-args:"true" required:"true"`
+
+	VarFlags
+	OpsFlags
+
+	NoRedact bool `long:"no-redact" description:"Show non-redacted manifest diff"`
+
+	cmd
+}
+
+type DiffArgs struct {
+	Manifest FileBytesArg `positional-arg-name:"PATH" description:"Path to a manifest file"`
+}
+
 type ManifestOpts struct {
 	cmd
 }
@@ -491,6 +679,8 @@ type EventArgs struct {
 // Stemcells
 
 type StemcellsOpts struct {
+	StatePath string `long:"state" value-name:"PATH" description:"List stemcells recorded in a create-env state file instead of the director"`
+
 	cmd
 }
 
@@ -531,6 +721,8 @@ type RepackStemcellOpts struct {
 	Format          []string           `long:"format" description:"Repacked stemcell formats. Can be used multiple times. Overrides existing formats."`
 	Version         string             `long:"version" description:"Repacked stemcell version"`
 
+	CompressionLevel CompressionLevelArg `long:"compression-level" value-name:"LEVEL" description:"Gzip level of the repacked stemcell: fast, best or 1-9 (default: tar default)"`
+
 	cmd
 }
 
@@ -933,6 +1125,17 @@ type RedigestReleaseArgs struct {
 	Destination FileArg `positional-arg-name:"DESTINATION"`
 }
 
+type DiffReleasesOpts struct {
+	Args DiffReleasesArgs `positional-args:"true" required:"true"`
+
+	cmd
+}
+
+type DiffReleasesArgs struct {
+	FromPath string `positional-arg-name:"FROM-PATH" description:"Path to the older release tarball"`
+	ToPath   string `positional-arg-name:"TO-PATH" description:"Path to the newer release tarball"`
+}
+
 type CreateReleaseOpts struct {
 	Args CreateReleaseArgs `positional-args:"true"`
 
@@ -942,9 +1145,12 @@ type CreateReleaseOpts struct {
 	Version          VersionArg `long:"version"            description:"Custom release version (e.g.: 1.0.0, 1.0-beta.2+dev.10)"`
 	TimestampVersion bool       `long:"timestamp-version"  description:"Create release with the timestamp as the dev version (e.g.: 1+dev.TIMESTAMP)"`
 
-	Final   bool    `long:"final"   description:"Make it a final release"`
-	Tarball FileArg `long:"tarball" description:"Create release tarball at path (e.g. /tmp/release.tgz)"`
-	Force   bool    `long:"force"   description:"Ignore Git dirty state check"`
+	Final     bool        `long:"final"      description:"Make it a final release"`
+	Tarball   FileArg     `long:"tarball"    description:"Create release tarball at path (e.g. /tmp/release.tgz)"`
+	OutputDir DirOrCWDArg `long:"output-dir" description:"Create release tarball named NAME-VERSION.tgz in directory, creating it if needed"`
+	Force     bool        `long:"force"      description:"Ignore Git dirty state check"`
+
+	CompressionLevel CompressionLevelArg `long:"compression-level" value-name:"LEVEL" description:"Gzip level of the release tarball: fast, best or 1-9 (default: tar default)"`
 
 	cmd
 }
@@ -1017,6 +1223,11 @@ type MessageOpts struct {
 	Message string
 }
 
+// OutputSchemaOpts is used for the schema flag
+type OutputSchemaOpts struct {
+	Command string
+}
+
 type VariablesOpts struct {
 	Deployment string
 	cmd
@@ -136,6 +136,22 @@ var _ = Describe("Opts", func() {
 			})
 		})
 
+		Describe("ReadOnlyOpt", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("ReadOnlyOpt", opts)).To(Equal(
+					`long:"read-only" description:"Refuse commands that change director, cloud or local state" env:"BOSH_READ_ONLY"`,
+				))
+			})
+		})
+
+		Describe("TmpDirOpt", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("TmpDirOpt", opts)).To(Equal(
+					`long:"tmp-dir" description:"Directory for temporary files such as extracted stemcells and compiled packages (default: ~/.bosh/tmp)" env:"BOSH_TMP_DIR"`,
+				))
+			})
+		})
+
 		Describe("CACertOpt", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("CACertOpt", opts)).To(Equal(
@@ -184,6 +200,14 @@ var _ = Describe("Opts", func() {
 			})
 		})
 
+		Describe("SchemaOpt", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("SchemaOpt", opts)).To(Equal(
+					`long:"schema" description:"Show the JSON schema of the command's output instead of running it"`,
+				))
+			})
+		})
+
 		Describe("TTYOpt", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("TTYOpt", opts)).To(Equal(
@@ -224,6 +248,70 @@ var _ = Describe("Opts", func() {
 			})
 		})
 
+		Describe("EnvInstances", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("EnvInstances", opts)).To(Equal(
+					`command:"env-instances" description:"List instances of an environment created with create-env and whether their agents respond"`,
+				))
+			})
+		})
+
+		Describe("EnvInfo", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("EnvInfo", opts)).To(Equal(
+					`command:"env-info" description:"Show the deployment, stemcell, CPI release and address of an environment created with create-env"`,
+				))
+			})
+		})
+
+		Describe("EnvLogs", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("EnvLogs", opts)).To(Equal(
+					`command:"env-logs" description:"Fetch job or agent logs from the VM of an environment created with create-env"`,
+				))
+			})
+		})
+
+		Describe("EnvDisks", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("EnvDisks", opts)).To(Equal(
+					`command:"env-disks" description:"List persistent disks of an environment created with create-env and delete orphaned ones"`,
+				))
+			})
+		})
+
+		Describe("EnvCloudCheck", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("EnvCloudCheck", opts)).To(Equal(
+					`command:"env-cloud-check" alias:"env-cck" description:"Find and resolve differences between the state file of an environment created with create-env and the IaaS"`,
+				))
+			})
+		})
+
+		Describe("ListStages", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("ListStages", opts)).To(Equal(
+					`command:"list-stages" description:"List stages emitted by create-env or delete-env"`,
+				))
+			})
+		})
+
+		Describe("ReplayEvents", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("ReplayEvents", opts)).To(Equal(
+					`command:"replay-events" description:"Show the stages and warnings saved in an event log"`,
+				))
+			})
+		})
+
+		Describe("ValidateManifest", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("ValidateManifest", opts)).To(Equal(
+					`command:"validate-manifest" description:"Validate a create-env manifest without deploying it"`,
+				))
+			})
+		})
+
 		Describe("Environment", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("Environment", opts)).To(Equal(
@@ -432,6 +520,14 @@ var _ = Describe("Opts", func() {
 			})
 		})
 
+		Describe("Diff", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Diff", opts)).To(Equal(
+					`command:"diff" description:"Compare deployment manifest with a new one"`,
+				))
+			})
+		})
+
 		Describe("Stemcells", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("Stemcells", opts)).To(Equal(
@@ -720,6 +816,14 @@ var _ = Describe("Opts", func() {
 			})
 		})
 
+		Describe("DiffReleases", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("DiffReleases", opts)).To(Equal(
+					`command:"diff-releases" description:"Compare jobs and packages of two release tarballs"`,
+				))
+			})
+		})
+
 		Describe("Sha2ifyRelease", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("Sha2ifyRelease", opts)).To(Equal(
@@ -810,11 +914,227 @@ var _ = Describe("Opts", func() {
 			))
 		})
 
+		It("has --reextract", func() {
+			Expect(getStructTagForName("Reextract", opts)).To(Equal(
+				`long:"reextract" description:"Extract the stemcell again instead of reusing the extraction cached by a previous run"`,
+			))
+		})
+
+		It("has --rerender", func() {
+			Expect(getStructTagForName("Rerender", opts)).To(Equal(
+				`long:"rerender" description:"Render job templates again instead of reusing renderings cached by a previous run"`,
+			))
+		})
+
 		It("has --recreate-persistent-disks", func() {
 			Expect(getStructTagForName("RecreatePersistentDisks", opts)).To(Equal(
 				`long:"recreate-persistent-disks" description:"Recreate persistent disks in the deployment"`,
 			))
 		})
+
+		It("has --prune-compiled", func() {
+			Expect(getStructTagForName("PruneCompiled", opts)).To(Equal(
+				`long:"prune-compiled" description:"Prune compiled packages no longer used by the deployment"`,
+			))
+		})
+
+		It("has --print-manifest", func() {
+			Expect(getStructTagForName("PrintManifest", opts)).To(Equal(
+				`long:"print-manifest" description:"Print fully resolved manifest and exit without deploying"`,
+			))
+		})
+
+		It("has --dry-run", func() {
+			Expect(getStructTagForName("DryRun", opts)).To(Equal(
+				`long:"dry-run" description:"Validate the manifest and print the planned stemcell, VM and disk changes without installing the CPI or writing the state file"`,
+			))
+		})
+
+		It("has --no-redact", func() {
+			Expect(getStructTagForName("NoRedact", opts)).To(Equal(
+				`long:"no-redact" description:"Show non-redacted variable values when printing manifest"`,
+			))
+		})
+
+		It("has --compiled-package-index", func() {
+			Expect(getStructTagForName("CompiledPackageIndex", opts)).To(Equal(
+				`long:"compiled-package-index" value-name:"PATH" description:"Compiled package index path, with compiled packages kept in a blobs directory next to it (default: inside the installation workspace)"`,
+			))
+		})
+
+		It("has --compiled-package-cache", func() {
+			Expect(getStructTagForName("CompiledPackageCache", opts)).To(Equal(
+				`long:"compiled-package-cache" value-name:"DIR" description:"Directory keeping compiled packages so that they are reused by other environments using the same cache"`,
+			))
+		})
+
+		It("has --resource-pool-cloud-properties", func() {
+			Expect(getStructTagForName("CloudPropertiesOverrides", opts)).To(Equal(
+				`long:"resource-pool-cloud-properties" value-name:"NAME=HASH" description:"Override cloud properties of a resource pool (can be specified multiple times)"`,
+			))
+		})
+
+		It("has --record-cpi", func() {
+			Expect(getStructTagForName("RecordCPI", opts)).To(Equal(
+				`long:"record-cpi" value-name:"PATH" description:"Record CPI requests and responses to a file, with secrets redacted"`,
+			))
+		})
+
+		It("has --replay-cpi", func() {
+			Expect(getStructTagForName("ReplayCPI", opts)).To(Equal(
+				`long:"replay-cpi" value-name:"PATH" description:"Replay CPI responses from a recording instead of running the CPI"`,
+			))
+		})
+
+		It("has --cpi-api-version", func() {
+			Expect(getStructTagForName("CPIAPIVersion", opts)).To(Equal(
+				`long:"cpi-api-version" value-name:"VERSION" description:"CPI API version to make requests with, overriding cloud_provider.cpi_api_version (default: 1)"`,
+			))
+		})
+
+		It("has --warnings-as-errors", func() {
+			Expect(getStructTagForName("WarningsAsErrors", opts)).To(Equal(
+				`long:"warnings-as-errors" description:"Fail when validating or deploying raises warnings"`,
+			))
+		})
+
+		It("has --advertised-registry-endpoint", func() {
+			Expect(getStructTagForName("AdvertisedRegistryEndpoint", opts)).To(Equal(
+				`long:"advertised-registry-endpoint" value-name:"URL" description:"Registry URL the agent is told to connect to (default: the registry bind address)"`,
+			))
+		})
+
+		It("has --skip-preflight", func() {
+			Expect(getStructTagForName("SkipPreflight", opts)).To(Equal(
+				`long:"skip-preflight" description:"Do not ask the CPI to validate its IaaS configuration before uploading the stemcell"`,
+			))
+		})
+
+		It("has --probe-agent", func() {
+			Expect(getStructTagForName("ProbeAgent", opts)).To(Equal(
+				`long:"probe-agent" description:"Check that the agent is compatible with the stemcell before applying jobs"`,
+			))
+		})
+
+		It("has --event-log", func() {
+			Expect(getStructTagForName("EventLog", opts)).To(Equal(
+				`long:"event-log" value-name:"PATH" description:"Write stages, timings and warnings to a compressed event log, with secrets redacted"`,
+			))
+		})
+
+		It("has --allow-downgrade", func() {
+			Expect(getStructTagForName("AllowDowngrade", opts)).To(Equal(
+				`long:"allow-downgrade" description:"Allow deploying releases older than the currently deployed versions"`,
+			))
+		})
+
+		It("has --production", func() {
+			Expect(getStructTagForName("Production", opts)).To(Equal(
+				`long:"production" description:"Mark the environment as production in the state file, so that delete-env requires typing its name to confirm"`,
+			))
+		})
+
+		It("has --tag", func() {
+			Expect(getStructTagForName("Tags", opts)).To(Equal(
+				`long:"tag" value-name:"KEY=VALUE" description:"Tag the VM and disks, overriding manifest tags with the same key; tags are added to their cloud properties and recorded in the state file; multiple allowed"`,
+			))
+		})
+
+		It("has --tag-stemcell", func() {
+			Expect(getStructTagForName("TagStemcell", opts)).To(Equal(
+				`long:"tag-stemcell" description:"Add the manifest tags to the 'tags' cloud property of the uploaded stemcell, for CPIs that tag stemcells from it"`,
+			))
+		})
+
+		It("has --stream-compile-logs", func() {
+			Expect(getStructTagForName("StreamCompileLogs", opts)).To(Equal(
+				`long:"stream-compile-logs" description:"Show the output of packaging scripts while compiling packages, prefixed with the package name"`,
+			))
+		})
+
+		It("has --deterministic-compiled-packages", func() {
+			Expect(getStructTagForName("DeterministicCompiledPackages", opts)).To(Equal(
+				`long:"deterministic-compiled-packages" description:"Create compiled package archives with normalized timestamps, modes and ownership so that the same package always has the same SHA"`,
+			))
+		})
+
+		It("has --offline", func() {
+			Expect(getStructTagForName("Offline", opts)).To(Equal(
+				`long:"offline" description:"Fail instead of downloading releases, stemcells or blobs over the network, listing what needed it; the CPI and the agent are still reached"`,
+			))
+		})
+
+		It("has --force-unlock", func() {
+			Expect(getStructTagForName("ForceUnlock", opts)).To(Equal(
+				`long:"force-unlock" description:"Take over the lock of the state file even if the process holding it is still running"`,
+			))
+		})
+
+		It("has --watch", func() {
+			Expect(getStructTagForName("Watch", opts)).To(Equal(
+				`long:"watch" description:"Run again whenever the manifest, files it includes, vars files or ops files change, until interrupted"`,
+			))
+		})
+
+		It("has --workers", func() {
+			Expect(getStructTagForName("Workers", opts)).To(Equal(
+				`long:"workers" value-name:"N" description:"Download and extract up to N releases and compile up to N packages at a time; progress of single downloads is only shown with one (default: number of CPUs)"`,
+			))
+		})
+
+		It("has --export-artifact", func() {
+			Expect(getStructTagForName("ExportArtifact", opts)).To(Equal(
+				`long:"export-artifact" value-name:"PATH" description:"Write endpoints, CIDs and credentials of the deployed environment to a file readable only by the current user"`,
+			))
+		})
+
+		It("has --export-artifact-format", func() {
+			Expect(getStructTagForName("ExportArtifactFormat", opts)).To(Equal(
+				`long:"export-artifact-format" value-name:"FORMAT" description:"Format of the exported artifact: 'json' or 'yaml'" default:"yaml"`,
+			))
+		})
+
+		It("has --agent-poll-interval", func() {
+			Expect(getStructTagForName("AgentPollInterval", opts)).To(Equal(
+				`long:"agent-poll-interval" value-name:"DURATION" description:"Interval between checks of long running agent tasks" default:"1s"`,
+			))
+		})
+
+		It("has --blobstore-part-size", func() {
+			Expect(getStructTagForName("BlobstorePartSize", opts)).To(Equal(
+				`long:"blobstore-part-size" value-name:"BYTES" description:"Upload blobs to the agent blobstore in parts of this size, resending only parts that failed; the blobstore must accept ranged PUT requests (default: upload at once)"`,
+			))
+		})
+
+		It("has --agent-ready-timeout", func() {
+			Expect(getStructTagForName("AgentReadyTimeout", opts)).To(Equal(
+				`long:"agent-ready-timeout" value-name:"DURATION" description:"Abort when the agent of an instance does not become ready within this duration" default:"10m"`,
+			))
+		})
+
+		It("has --retries", func() {
+			Expect(getStructTagForName("Retries", opts)).To(Equal(
+				`long:"retries" value-name:"N" description:"Retry CPI calls that are safe to repeat, such as has_vm, and agent blobstore downloads and uploads up to N times after transient errors" default:"3"`,
+			))
+		})
+
+		It("has --retry-delay", func() {
+			Expect(getStructTagForName("RetryDelay", opts)).To(Equal(
+				`long:"retry-delay" value-name:"DURATION" description:"Delay before the first retry of a CPI call or blobstore transfer, doubled after each retry up to 30s" default:"1s"`,
+			))
+		})
+
+		It("has --cpi-timeout", func() {
+			Expect(getStructTagForName("CPITimeouts", opts)).To(Equal(
+				`long:"cpi-timeout" value-name:"METHOD=DURATION" description:"Override the timeout of a CPI method, or of methods without their own timeout with 'default'; 0 disables it (can be specified multiple times)"`,
+			))
+		})
+
+		It("has --mirror", func() {
+			Expect(getStructTagForName("Mirrors", opts)).To(Equal(
+				`long:"mirror" value-name:"URL=MIRROR_URL" description:"Download release and stemcell tarballs whose URL starts with URL from MIRROR_URL when the original URL fails, in the given order (can be specified multiple times)"`,
+			))
+		})
 	})
 
 	Describe("CreateEnvArgs", func() {
@@ -827,7 +1147,7 @@ var _ = Describe("Opts", func() {
 		Describe("Manifest", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("Manifest", args)).To(Equal(
-					`positional-arg-name:"PATH" description:"Path to a manifest file"`,
+					`positional-arg-name:"PATH" description:"Path to a manifest file, or '-' to read it from stdin"`,
 				))
 			})
 		})
@@ -851,6 +1171,292 @@ var _ = Describe("Opts", func() {
 				`long:"state" value-name:"PATH" description:"State file path"`,
 			))
 		})
+
+		It("has --compiled-package-index", func() {
+			Expect(getStructTagForName("CompiledPackageIndex", opts)).To(Equal(
+				`long:"compiled-package-index" value-name:"PATH" description:"Compiled package index path, with compiled packages kept in a blobs directory next to it (default: inside the installation workspace)"`,
+			))
+		})
+
+		It("has --compiled-package-cache", func() {
+			Expect(getStructTagForName("CompiledPackageCache", opts)).To(Equal(
+				`long:"compiled-package-cache" value-name:"DIR" description:"Directory keeping compiled packages so that they are reused by other environments using the same cache"`,
+			))
+		})
+
+		It("has --confirm-destroy", func() {
+			Expect(getStructTagForName("ConfirmDestroy", opts)).To(Equal(
+				`long:"confirm-destroy" value-name:"NAME" description:"Deployment name confirming deletion instead of typing it, required for an environment marked as production when not interactive"`,
+			))
+		})
+
+		It("has --yes", func() {
+			Expect(getStructTagForName("Yes", opts)).To(Equal(
+				`long:"yes" description:"Skip the deletion confirmation, including for an environment marked as production"`,
+			))
+		})
+
+		It("has --force", func() {
+			Expect(getStructTagForName("Force", opts)).To(Equal(
+				`long:"force" description:"Continue deleting the remaining resources when one cannot be deleted, failing at the end"`,
+			))
+		})
+
+		It("has --record-cpi", func() {
+			Expect(getStructTagForName("RecordCPI", opts)).To(Equal(
+				`long:"record-cpi" value-name:"PATH" description:"Record CPI requests and responses to a file, with secrets redacted"`,
+			))
+		})
+
+		It("has --replay-cpi", func() {
+			Expect(getStructTagForName("ReplayCPI", opts)).To(Equal(
+				`long:"replay-cpi" value-name:"PATH" description:"Replay CPI responses from a recording instead of running the CPI"`,
+			))
+		})
+
+		It("has --cpi-api-version", func() {
+			Expect(getStructTagForName("CPIAPIVersion", opts)).To(Equal(
+				`long:"cpi-api-version" value-name:"VERSION" description:"CPI API version to make requests with, overriding cloud_provider.cpi_api_version (default: 1)"`,
+			))
+		})
+
+		It("has --retries", func() {
+			Expect(getStructTagForName("Retries", opts)).To(Equal(
+				`long:"retries" value-name:"N" description:"Retry CPI calls that are safe to repeat, such as has_vm, and agent blobstore downloads and uploads up to N times after transient errors" default:"3"`,
+			))
+		})
+
+		It("has --retry-delay", func() {
+			Expect(getStructTagForName("RetryDelay", opts)).To(Equal(
+				`long:"retry-delay" value-name:"DURATION" description:"Delay before the first retry of a CPI call or blobstore transfer, doubled after each retry up to 30s" default:"1s"`,
+			))
+		})
+
+		It("has --cpi-timeout", func() {
+			Expect(getStructTagForName("CPITimeouts", opts)).To(Equal(
+				`long:"cpi-timeout" value-name:"METHOD=DURATION" description:"Override the timeout of a CPI method, or of methods without their own timeout with 'default'; 0 disables it (can be specified multiple times)"`,
+			))
+		})
+
+		It("has --mirror", func() {
+			Expect(getStructTagForName("Mirrors", opts)).To(Equal(
+				`long:"mirror" value-name:"URL=MIRROR_URL" description:"Download release and stemcell tarballs whose URL starts with URL from MIRROR_URL when the original URL fails, in the given order (can be specified multiple times)"`,
+			))
+		})
+
+		It("has --event-log", func() {
+			Expect(getStructTagForName("EventLog", opts)).To(Equal(
+				`long:"event-log" value-name:"PATH" description:"Write stages, timings and warnings to a compressed event log, with secrets redacted"`,
+			))
+		})
+
+		It("has --offline", func() {
+			Expect(getStructTagForName("Offline", opts)).To(Equal(
+				`long:"offline" description:"Fail instead of downloading releases, stemcells or blobs over the network, listing what needed it; the CPI and the agent are still reached"`,
+			))
+		})
+
+		It("has --force-unlock", func() {
+			Expect(getStructTagForName("ForceUnlock", opts)).To(Equal(
+				`long:"force-unlock" description:"Take over the lock of the state file even if the process holding it is still running"`,
+			))
+		})
+	})
+
+	Describe("EnvInstancesOpts", func() {
+		var opts *EnvInstancesOpts
+
+		BeforeEach(func() {
+			opts = &EnvInstancesOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		It("has --state", func() {
+			Expect(getStructTagForName("StatePath", opts)).To(Equal(
+				`long:"state" value-name:"PATH" description:"State file path"`,
+			))
+		})
+	})
+
+	Describe("EnvInstancesArgs", func() {
+		var args *EnvInstancesArgs
+
+		BeforeEach(func() {
+			args = &EnvInstancesArgs{}
+		})
+
+		Describe("Manifest", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Manifest", args)).To(Equal(
+					`positional-arg-name:"PATH" description:"Path to a manifest file, or '-' to read it from stdin"`,
+				))
+			})
+		})
+	})
+
+	Describe("EnvDisksOpts", func() {
+		var opts *EnvDisksOpts
+
+		BeforeEach(func() {
+			opts = &EnvDisksOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		It("has --state", func() {
+			Expect(getStructTagForName("StatePath", opts)).To(Equal(
+				`long:"state" value-name:"PATH" description:"State file path"`,
+			))
+		})
+
+		It("has --orphaned", func() {
+			Expect(getStructTagForName("Orphaned", opts)).To(Equal(
+				`long:"orphaned" description:"List only disks that are not the current persistent disk"`,
+			))
+		})
+
+		It("has --delete", func() {
+			Expect(getStructTagForName("Delete", opts)).To(Equal(
+				`long:"delete" description:"Delete orphaned disks after confirming, requires --orphaned"`,
+			))
+		})
+
+		It("has --force-unlock", func() {
+			Expect(getStructTagForName("ForceUnlock", opts)).To(Equal(
+				`long:"force-unlock" description:"Take over the lock of the state file even if the process holding it is still running"`,
+			))
+		})
+	})
+
+	Describe("EnvCloudCheckOpts", func() {
+		var opts *EnvCloudCheckOpts
+
+		BeforeEach(func() {
+			opts = &EnvCloudCheckOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		It("has --state", func() {
+			Expect(getStructTagForName("StatePath", opts)).To(Equal(
+				`long:"state" value-name:"PATH" description:"State file path"`,
+			))
+		})
+
+		It("has --resolution", func() {
+			Expect(getStructTagForName("Resolutions", opts)).To(Equal(
+				`long:"resolution" description:"Apply resolution of given type, or 'auto' to apply the default resolution of each problem"`,
+			))
+		})
+
+		It("has --report", func() {
+			Expect(getStructTagForName("Report", opts)).To(Equal(
+				`long:"report" short:"r" description:"Only generate report; don't attempt to resolve problems"`,
+			))
+		})
+
+		It("has --force-unlock", func() {
+			Expect(getStructTagForName("ForceUnlock", opts)).To(Equal(
+				`long:"force-unlock" description:"Take over the lock of the state file even if the process holding it is still running"`,
+			))
+		})
+	})
+
+	Describe("EnvInfoOpts", func() {
+		var opts *EnvInfoOpts
+
+		BeforeEach(func() {
+			opts = &EnvInfoOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		It("has --state", func() {
+			Expect(getStructTagForName("StatePath", opts)).To(Equal(
+				`long:"state" value-name:"PATH" description:"State file path"`,
+			))
+		})
+	})
+
+	Describe("EnvLogsOpts", func() {
+		var opts *EnvLogsOpts
+
+		BeforeEach(func() {
+			opts = &EnvLogsOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		It("has --state", func() {
+			Expect(getStructTagForName("StatePath", opts)).To(Equal(
+				`long:"state" value-name:"PATH" description:"State file path"`,
+			))
+		})
+
+		It("has --dir", func() {
+			Expect(getStructTagForName("Directory", opts)).To(Equal(
+				`long:"dir" description:"Destination directory" default:"."`,
+			))
+		})
+
+		It("has --job", func() {
+			Expect(getStructTagForName("Jobs", opts)).To(Equal(
+				`long:"job" description:"Limit to only specific jobs"`,
+			))
+		})
+
+		It("has --agent", func() {
+			Expect(getStructTagForName("Agent", opts)).To(Equal(
+				`long:"agent" description:"Include only agent logs"`,
+			))
+		})
+	})
+
+	Describe("ValidateManifestOpts", func() {
+		var opts *ValidateManifestOpts
+
+		BeforeEach(func() {
+			opts = &ValidateManifestOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		It("has --release", func() {
+			Expect(getStructTagForName("Releases", opts)).To(Equal(
+				`long:"release" value-name:"PATH" description:"Path to a release tarball to check jobs against (can be specified multiple times)"`,
+			))
+		})
+
+		It("has --stemcell", func() {
+			Expect(getStructTagForName("Stemcell", opts)).To(Equal(
+				`long:"stemcell" value-name:"PATH" description:"Path to a stemcell tarball to check jobs against, requires --release"`,
+			))
+		})
 	})
 
 	Describe("DeleteEnvArgs", func() {
@@ -863,7 +1469,67 @@ var _ = Describe("Opts", func() {
 		Describe("Manifest", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("Manifest", args)).To(Equal(
-					`positional-arg-name:"PATH" description:"Path to a manifest file"`,
+					`positional-arg-name:"PATH" description:"Path to a manifest file, or '-' to read it from stdin"`,
+				))
+			})
+		})
+	})
+
+	Describe("ListStagesOpts", func() {
+		var opts *ListStagesOpts
+
+		BeforeEach(func() {
+			opts = &ListStagesOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+	})
+
+	Describe("ListStagesArgs", func() {
+		var args *ListStagesArgs
+
+		BeforeEach(func() {
+			args = &ListStagesArgs{}
+		})
+
+		Describe("Command", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Command", args)).To(Equal(
+					`positional-arg-name:"COMMAND" description:"Command name (create-env or delete-env)"`,
+				))
+			})
+		})
+	})
+
+	Describe("ReplayEventsOpts", func() {
+		var opts *ReplayEventsOpts
+
+		BeforeEach(func() {
+			opts = &ReplayEventsOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+	})
+
+	Describe("ReplayEventsArgs", func() {
+		var args *ReplayEventsArgs
+
+		BeforeEach(func() {
+			args = &ReplayEventsArgs{}
+		})
+
+		Describe("Path", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Path", args)).To(Equal(
+					`positional-arg-name:"PATH" description:"Path to an event log written with --event-log"`,
 				))
 			})
 		})
@@ -1240,7 +1906,13 @@ var _ = Describe("Opts", func() {
 
 		It("has VarErrors", func() {
 			Expect(getStructTagForName("VarErrors", &opts)).To(Equal(
-				`long:"var-errs" description:"Expect all variables to be found, otherwise error"`,
+				`long:"var-errs" description:"Expect all variables to be found, otherwise error (default)"`,
+			))
+		})
+
+		It("has NoVarErrors", func() {
+			Expect(getStructTagForName("NoVarErrors", &opts)).To(Equal(
+				`long:"no-var-errs" description:"Leave variables that are not found in the template instead of failing, e.g. to interpolate in several passes"`,
 			))
 		})
 
@@ -1353,6 +2025,44 @@ var _ = Describe("Opts", func() {
 		})
 	})
 
+	Describe("DiffOpts", func() {
+		var opts *DiffOpts
+
+		BeforeEach(func() {
+			opts = &DiffOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		Describe("NoRedact", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("NoRedact", opts)).To(Equal(
+					`long:"no-redact" description:"Show non-redacted manifest diff"`,
+				))
+			})
+		})
+	})
+
+	Describe("DiffArgs", func() {
+		var opts *DiffArgs
+
+		BeforeEach(func() {
+			opts = &DiffArgs{}
+		})
+
+		Describe("Manifest", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Manifest", opts)).To(Equal(
+					`positional-arg-name:"PATH" description:"Path to a manifest file"`,
+				))
+			})
+		})
+	})
+
 	Describe("DeployOpts", func() {
 		var opts *DeployOpts
 
@@ -1390,6 +2100,14 @@ var _ = Describe("Opts", func() {
 			})
 		})
 
+		Describe("DiffContext", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("DiffContext", opts)).To(Equal(
+					`long:"diff-context" description:"Show changed secret values in the manifest diff as '[secret changed]'"`,
+				))
+			})
+		})
+
 		Describe("SkipDrain", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("SkipDrain", opts)).To(Equal(
@@ -1421,6 +2139,22 @@ var _ = Describe("Opts", func() {
 				))
 			})
 		})
+
+		Describe("VarErrorsUnused", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("VarErrorsUnused", opts)).To(Equal(
+					`long:"var-errs-unused" description:"Expect all variables to be used, otherwise error"`,
+				))
+			})
+		})
+
+		Describe("Tags", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Tags", opts)).To(Equal(
+					`long:"tag" value-name:"KEY=VALUE" description:"Tag VMs and disks of the deployment, overriding manifest tags with the same key; multiple allowed"`,
+				))
+			})
+		})
 	})
 
 	Describe("DeployArgs", func() {
@@ -1455,6 +2189,22 @@ var _ = Describe("Opts", func() {
 		})
 	})
 
+	Describe("StemcellsOpts", func() {
+		var opts *StemcellsOpts
+
+		BeforeEach(func() {
+			opts = &StemcellsOpts{}
+		})
+
+		Describe("StatePath", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("StatePath", opts)).To(Equal(
+					`long:"state" value-name:"PATH" description:"List stemcells recorded in a create-env state file instead of the director"`,
+				))
+			})
+		})
+	})
+
 	Describe("UploadStemcellOpts", func() {
 		var opts *UploadStemcellOpts
 
@@ -1562,6 +2312,14 @@ var _ = Describe("Opts", func() {
 			opts = &RepackStemcellOpts{}
 		})
 
+		Describe("CompressionLevel", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("CompressionLevel", opts)).To(Equal(
+					`long:"compression-level" value-name:"LEVEL" description:"Gzip level of the repacked stemcell: fast, best or 1-9 (default: tar default)"`,
+				))
+			})
+		})
+
 		Describe("Args", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
@@ -2825,6 +3583,14 @@ var _ = Describe("Opts", func() {
 			opts = &CreateReleaseOpts{}
 		})
 
+		Describe("CompressionLevel", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("CompressionLevel", opts)).To(Equal(
+					`long:"compression-level" value-name:"LEVEL" description:"Gzip level of the release tarball: fast, best or 1-9 (default: tar default)"`,
+				))
+			})
+		})
+
 		Describe("Args", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true"`))
@@ -2885,6 +3651,14 @@ var _ = Describe("Opts", func() {
 			})
 		})
 
+		Describe("OutputDir", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("OutputDir", opts)).To(Equal(
+					`long:"output-dir" description:"Create release tarball named NAME-VERSION.tgz in directory, creating it if needed"`,
+				))
+			})
+		})
+
 		Describe("Force", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("Force", opts)).To(Equal(
@@ -2929,6 +3703,35 @@ var _ = Describe("Opts", func() {
 		})
 	})
 
+	Describe("DiffReleasesOpts", func() {
+		var opts *DiffReleasesOpts
+
+		BeforeEach(func() {
+			opts = &DiffReleasesOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+	})
+
+	Describe("DiffReleasesArgs", func() {
+		var opts *DiffReleasesArgs
+
+		BeforeEach(func() {
+			opts = &DiffReleasesArgs{}
+		})
+
+		Describe("Positional args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("FromPath", opts)).To(Equal(`positional-arg-name:"FROM-PATH" description:"Path to the older release tarball"`))
+				Expect(getStructTagForName("ToPath", opts)).To(Equal(`positional-arg-name:"TO-PATH" description:"Path to the newer release tarball"`))
+			})
+		})
+	})
+
 	Describe("CreateReleaseArgs", func() {
 		var opts *CreateReleaseArgs
 
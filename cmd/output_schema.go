@@ -0,0 +1,361 @@
+package cmd
+
+import (
+	"encoding/json"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+var warningsTableSchema = boshui.TableSchema{
+	Content: "warnings",
+	Columns: []boshtbl.Header{
+		boshtbl.NewHeader("Source"),
+		boshtbl.NewHeader("Warning"),
+	},
+}
+
+var problemsTableSchema = boshui.TableSchema{
+	Content: "problems",
+	Columns: []boshtbl.Header{
+		boshtbl.NewHeader("#"),
+		boshtbl.NewHeader("Type"),
+		boshtbl.NewHeader("Description"),
+	},
+}
+
+var configTableSchema = boshui.TableSchema{
+	Content: "config",
+	Columns: []boshtbl.Header{
+		boshtbl.NewHeader("ID"),
+		boshtbl.NewHeader("Type"),
+		boshtbl.NewHeader("Name"),
+		boshtbl.NewHeader("Created At"),
+		boshtbl.NewHeader("Content"),
+	},
+}
+
+var releaseTableSchemas = []boshui.TableSchema{
+	{
+		Content: "jobs",
+		Columns: []boshtbl.Header{
+			boshtbl.NewHeader("Job"),
+			boshtbl.NewHeader("Digest"),
+			boshtbl.NewHeader("Packages"),
+		},
+	},
+	{
+		Content: "packages",
+		Columns: []boshtbl.Header{
+			boshtbl.NewHeader("Package"),
+			boshtbl.NewHeader("Digest"),
+			boshtbl.NewHeader("Dependencies"),
+		},
+	},
+}
+
+// outputTableSchemas lists the tables printed by commands with a fixed set of columns.
+// Other commands print the same JSON document but their tables are described generically.
+// A test checks that every table built with a fixed set of columns is described here.
+var outputTableSchemas = map[string][]boshui.TableSchema{
+	"create-env": {
+		{
+			Content: "changes",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("Resource"),
+				boshtbl.NewHeader("Action"),
+				boshtbl.NewHeader("Details"),
+			},
+		},
+		{
+			Content: "instances",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("Instance"),
+				boshtbl.NewHeader("Process State"),
+				boshtbl.NewHeader("IPs"),
+				boshtbl.NewHeader("Jobs"),
+			},
+		},
+		warningsTableSchema,
+	},
+	"env-instances": {
+		{
+			Content: "instances",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("Instance"),
+				boshtbl.NewHeader("VM CID"),
+				boshtbl.NewHeader("Reachable"),
+			},
+		},
+	},
+	"env-disks": {
+		{
+			Content: "disks",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("Disk CID"),
+				boshtbl.NewHeader("Size"),
+				boshtbl.NewHeader("Tags"),
+				boshtbl.NewHeader("Orphaned"),
+			},
+		},
+	},
+	"env-info": {
+		{
+			Content: "environment",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("Name"),
+				boshtbl.NewHeader("UUID"),
+				boshtbl.NewHeader("Installation ID"),
+				boshtbl.NewHeader("Stemcell"),
+				boshtbl.NewHeader("Stemcell CID"),
+				boshtbl.NewHeader("CPI Release"),
+				boshtbl.NewHeader("VM CID"),
+				boshtbl.NewHeader("Address"),
+				boshtbl.NewHeader("Agent"),
+			},
+		},
+	},
+	"env-cloud-check": {problemsTableSchema},
+	"validate-manifest": {
+		{
+			Content: "errors",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("Path"),
+				boshtbl.NewHeader("Error"),
+			},
+		},
+		warningsTableSchema,
+	},
+	"replay-events": {warningsTableSchema},
+	"environments": {
+		{
+			Content: "environments",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("URL"),
+				boshtbl.NewHeader("Alias"),
+			},
+		},
+	},
+	"deployments": {
+		{
+			Content: "deployments",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("Name"),
+				boshtbl.NewHeader("Release(s)"),
+				boshtbl.NewHeader("Stemcell(s)"),
+				boshtbl.NewHeader("Team(s)"),
+			},
+		},
+	},
+	"releases": {
+		{
+			Content: "releases",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("Name"),
+				boshtbl.NewHeader("Version"),
+				boshtbl.NewHeader("Commit Hash"),
+			},
+		},
+	},
+	"stemcells": {
+		{
+			Content: "stemcells",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("Name"),
+				boshtbl.NewHeader("Version"),
+				boshtbl.NewHeader("OS"),
+				boshtbl.NewHeader("CPI"),
+				boshtbl.NewHeader("CID"),
+			},
+		},
+		// printed instead when listing the stemcells of a state file with --state
+		{
+			Content: "stemcells",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("Name"),
+				boshtbl.NewHeader("Version"),
+				boshtbl.NewHeader("CID"),
+			},
+		},
+	},
+	"inspect-release": {
+		{
+			Content: "jobs",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("Job"),
+				boshtbl.NewHeader("Blobstore ID"),
+				boshtbl.NewHeader("Digest"),
+				boshtbl.NewHeader("Links Consumed"),
+				boshtbl.NewHeader("Links Provided"),
+			},
+		},
+		{
+			Content: "packages",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("Package"),
+				boshtbl.NewHeader("Compiled for"),
+				boshtbl.NewHeader("Blobstore ID"),
+				boshtbl.NewHeader("Digest"),
+			},
+		},
+	},
+	"create-release":   releaseTableSchemas,
+	"finalize-release": releaseTableSchemas,
+	"redigest-release": releaseTableSchemas,
+	"tasks": {
+		{
+			Content: "tasks",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("ID"),
+				boshtbl.NewHeader("State"),
+				boshtbl.NewHeader("Started At"),
+				boshtbl.NewHeader("Last Activity At"),
+				boshtbl.NewHeader("User"),
+				boshtbl.NewHeader("Deployment"),
+				boshtbl.NewHeader("Description"),
+				boshtbl.NewHeader("Result"),
+			},
+		},
+	},
+	"locks": {
+		{
+			Content: "locks",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("Type"),
+				boshtbl.NewHeader("Resource"),
+				boshtbl.NewHeader("Task ID"),
+				boshtbl.NewHeader("Expires at"),
+			},
+		},
+	},
+	"configs": {
+		{
+			Content: "configs",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("ID"),
+				boshtbl.NewHeader("Type"),
+				boshtbl.NewHeader("Name"),
+				boshtbl.NewHeader("Team"),
+				boshtbl.NewHeader("Created At"),
+			},
+		},
+	},
+	"disks": {
+		{
+			Content: "disks",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("Disk CID"),
+				boshtbl.NewHeader("Size"),
+				boshtbl.NewHeader("Deployment"),
+				boshtbl.NewHeader("Instance"),
+				boshtbl.NewHeader("AZ"),
+				boshtbl.NewHeader("Orphaned At"),
+			},
+		},
+	},
+	"config":        {configTableSchema},
+	"update-config": {configTableSchema},
+	"cloud-check":   {problemsTableSchema},
+	"run-errand": {
+		{
+			Content: "errand(s)",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("Instance"),
+				boshtbl.NewHeader("Exit Code"),
+				boshtbl.NewHeader("Stdout"),
+				boshtbl.NewHeader("Stderr"),
+			},
+		},
+	},
+	"errands": {
+		{
+			Content: "errands",
+			Columns: []boshtbl.Header{boshtbl.NewHeader("Name")},
+		},
+	},
+	"events": {
+		{
+			Content: "events",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("ID"),
+				boshtbl.NewHeader("Time"),
+				boshtbl.NewHeader("User"),
+				boshtbl.NewHeader("Action"),
+				boshtbl.NewHeader("Object Type"),
+				boshtbl.NewHeader("Object Name"),
+				boshtbl.NewHeader("Task ID"),
+				boshtbl.NewHeader("Deployment"),
+				boshtbl.NewHeader("Instance"),
+				boshtbl.NewHeader("Context"),
+				boshtbl.NewHeader("Error"),
+			},
+		},
+	},
+	"orphaned-vms": {
+		{
+			Content: "orphaned_vms",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("VM CID"),
+				boshtbl.NewHeader("Deployment"),
+				boshtbl.NewHeader("Instance"),
+				boshtbl.NewHeader("AZ"),
+				boshtbl.NewHeader("IPs"),
+				boshtbl.NewHeader("Orphaned At"),
+			},
+		},
+	},
+	"snapshots": {
+		{
+			Content: "snapshots",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("Instance"),
+				boshtbl.NewHeader("CID"),
+				boshtbl.NewHeader("Created At"),
+				boshtbl.NewHeader("Clean"),
+			},
+		},
+	},
+	"blobs": {
+		{
+			Content: "blobs",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("Path"),
+				boshtbl.NewHeader("Size"),
+				boshtbl.NewHeader("Blobstore ID"),
+				boshtbl.NewHeader("Digest"),
+			},
+		},
+	},
+	"variables": {
+		{
+			Content: "variables",
+			Columns: []boshtbl.Header{
+				boshtbl.NewHeader("ID"),
+				boshtbl.NewHeader("Name"),
+			},
+		},
+	},
+}
+
+type OutputSchemaCmd struct {
+	ui boshui.UI
+}
+
+func NewOutputSchemaCmd(ui boshui.UI) OutputSchemaCmd {
+	return OutputSchemaCmd{ui: ui}
+}
+
+func (c OutputSchemaCmd) Run(opts OutputSchemaOpts) error {
+	schema := boshui.JSONSchema(opts.Command, outputTableSchemas[opts.Command])
+
+	bytes, err := json.MarshalIndent(schema, "", "    ")
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling output schema")
+	}
+
+	c.ui.PrintBlock(append(bytes, '\n'))
+
+	return nil
+}
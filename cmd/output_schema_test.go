@@ -0,0 +1,202 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+)
+
+var _ = Describe("OutputSchemaCmd", func() {
+	var (
+		ui      *fakeui.FakeUI
+		command OutputSchemaCmd
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		command = NewOutputSchemaCmd(ui)
+	})
+
+	Describe("Run", func() {
+		printedSchema := func() map[string]interface{} {
+			Expect(ui.Blocks).To(HaveLen(1))
+
+			var schema map[string]interface{}
+			Expect(json.Unmarshal([]byte(ui.Blocks[0]), &schema)).To(Succeed())
+
+			return schema
+		}
+
+		tableSchemas := func(schema map[string]interface{}) []interface{} {
+			tables := schema["properties"].(map[string]interface{})["Tables"].(map[string]interface{})
+			return tables["items"].(map[string]interface{})["anyOf"].([]interface{})
+		}
+
+		It("prints the schema of the tables printed by the command", func() {
+			err := command.Run(OutputSchemaOpts{Command: "stemcells"})
+			Expect(err).ToNot(HaveOccurred())
+
+			schema := printedSchema()
+			Expect(schema["title"]).To(Equal("bosh stemcells --json"))
+
+			tables := tableSchemas(schema)
+			Expect(tables).To(HaveLen(3))
+
+			properties := tables[0].(map[string]interface{})["properties"].(map[string]interface{})
+			Expect(properties["Content"]).To(Equal(map[string]interface{}{"const": "stemcells"}))
+
+			rows := properties["Rows"].(map[string]interface{})["items"].(map[string]interface{})
+			Expect(rows["properties"]).To(HaveKey("name"))
+			Expect(rows["properties"]).To(HaveKey("cid"))
+		})
+
+		It("describes every table printed with a fixed set of columns", func() {
+			describedTables := map[string]bool{}
+
+			boshOpts := reflect.TypeOf(BoshOpts{})
+			for i := 0; i < boshOpts.NumField(); i++ {
+				commandName := boshOpts.Field(i).Tag.Get("command")
+				if commandName == "" {
+					continue
+				}
+
+				ui.Blocks = nil
+
+				err := command.Run(OutputSchemaOpts{Command: commandName})
+				Expect(err).ToNot(HaveOccurred())
+
+				for _, table := range tableSchemas(printedSchema()) {
+					properties := table.(map[string]interface{})["properties"].(map[string]interface{})
+
+					content, found := properties["Content"].(map[string]interface{})["const"]
+					if !found {
+						continue
+					}
+
+					var titles []string
+					for _, header := range properties["Header"].(map[string]interface{})["properties"].(map[string]interface{}) {
+						titles = append(titles, header.(map[string]interface{})["const"].(string))
+					}
+
+					describedTables[tableDescription(content.(string), titles)] = true
+				}
+			}
+
+			fileSet := token.NewFileSet()
+			packages, err := parser.ParseDir(fileSet, ".", func(info os.FileInfo) bool {
+				return !strings.HasSuffix(info.Name(), "_test.go")
+			}, 0)
+			Expect(err).ToNot(HaveOccurred())
+
+			var printedTables []string
+
+			for _, pkg := range packages {
+				ast.Inspect(pkg, func(node ast.Node) bool {
+					if content, titles, fixed := fixedTable(node); fixed {
+						printedTables = append(printedTables, tableDescription(content, titles))
+					}
+					return true
+				})
+			}
+
+			Expect(printedTables).ToNot(BeEmpty())
+
+			var undescribedTables []string
+			for _, table := range printedTables {
+				if !describedTables[table] {
+					undescribedTables = append(undescribedTables, table)
+				}
+			}
+
+			Expect(undescribedTables).To(BeEmpty(), "tables are not described in outputTableSchemas")
+		})
+
+		It("prints a generic schema for commands without fixed tables", func() {
+			err := command.Run(OutputSchemaOpts{Command: "vms"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(tableSchemas(printedSchema())).To(HaveLen(1))
+		})
+	})
+})
+
+func tableDescription(content string, titles []string) string {
+	sort.Strings(titles)
+	return content + ": " + strings.Join(titles, ", ")
+}
+
+// fixedTable returns the content and column titles of a table literal
+// whose header only consists of literal titles
+func fixedTable(node ast.Node) (string, []string, bool) {
+	lit, ok := node.(*ast.CompositeLit)
+	if !ok {
+		return "", nil, false
+	}
+
+	tableType, ok := lit.Type.(*ast.SelectorExpr)
+	if !ok || tableType.Sel.Name != "Table" {
+		return "", nil, false
+	}
+
+	var content string
+	var titles []string
+
+	for _, elt := range lit.Elts {
+		field, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return "", nil, false
+		}
+
+		switch field.Key.(*ast.Ident).Name {
+		case "Content":
+			value, ok := stringLiteral(field.Value)
+			if !ok {
+				return "", nil, false
+			}
+			content = value
+
+		case "Header":
+			headers, ok := field.Value.(*ast.CompositeLit)
+			if !ok {
+				return "", nil, false
+			}
+
+			for _, header := range headers.Elts {
+				call, ok := header.(*ast.CallExpr)
+				if !ok || len(call.Args) != 1 {
+					return "", nil, false
+				}
+
+				title, ok := stringLiteral(call.Args[0])
+				if !ok {
+					return "", nil, false
+				}
+				titles = append(titles, title)
+			}
+		}
+	}
+
+	return content, titles, content != "" && len(titles) > 0
+}
+
+func stringLiteral(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
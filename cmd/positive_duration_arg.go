@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// PositiveDurationArg is a duration such as '10m' that must be greater than zero
+type PositiveDurationArg time.Duration
+
+func (a *PositiveDurationArg) UnmarshalFlag(data string) error {
+	duration, err := time.ParseDuration(data)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Parsing duration '%s'", data)
+	}
+
+	if duration <= 0 {
+		return bosherr.Errorf("Expected duration '%s' to be greater than zero", data)
+	}
+
+	*a = PositiveDurationArg(duration)
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package cmd_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+)
+
+var _ = Describe("PositiveDurationArg", func() {
+	Describe("UnmarshalFlag", func() {
+		var (
+			arg PositiveDurationArg
+		)
+
+		BeforeEach(func() {
+			arg = PositiveDurationArg(0)
+		})
+
+		It("parses durations", func() {
+			err := (&arg).UnmarshalFlag("10m")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(arg).To(Equal(PositiveDurationArg(10 * time.Minute)))
+		})
+
+		It("returns an error for durations that cannot be parsed", func() {
+			err := (&arg).UnmarshalFlag("ten minutes")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Parsing duration 'ten minutes'"))
+		})
+
+		It("returns an error for durations that are not greater than zero", func() {
+			err := (&arg).UnmarshalFlag("0s")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected duration '0s' to be greater than zero"))
+
+			err = (&arg).UnmarshalFlag("-1m")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected duration '-1m' to be greater than zero"))
+		})
+	})
+})
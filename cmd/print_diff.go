@@ -2,9 +2,20 @@ package cmd
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"gopkg.in/yaml.v2"
+
 	boshui "github.com/cloudfoundry/bosh-cli/ui"
 )
 
+const secretChangedValue = "[secret changed]"
+
+var secretValueRegex = regexp.MustCompile(`\A\(\((!?[-/\.\w\pL]+)\)\)\z`)
+
 type Diff struct {
 	lines [][]interface{}
 }
@@ -15,6 +26,184 @@ func NewDiff(lines [][]interface{}) Diff {
 	}
 }
 
+// SecretPaths returns the slash separated key paths whose values in the manifest
+// are entirely a variable reference, i.e. values that are resolved from vars or creds.
+// Array elements are identified like in ops files: by 'name=<name>' when they are
+// hashes with a name and by their index otherwise.
+func SecretPaths(manifest []byte) (map[string]struct{}, error) {
+	var obj interface{}
+
+	err := yaml.Unmarshal(manifest, &obj)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Parsing manifest for secret paths")
+	}
+
+	paths := map[string]struct{}{}
+	collectSecretPaths(obj, nil, paths)
+
+	return paths, nil
+}
+
+func collectSecretPaths(obj interface{}, keys []string, paths map[string]struct{}) {
+	switch typedObj := obj.(type) {
+	case map[interface{}]interface{}:
+		for key, value := range typedObj {
+			collectSecretPaths(value, append(keys[:len(keys):len(keys)], fmt.Sprintf("%v", key)), paths)
+		}
+	case []interface{}:
+		for i, value := range typedObj {
+			collectSecretPaths(value, append(keys[:len(keys):len(keys)], arrayElementKey(value, i)), paths)
+		}
+	case string:
+		if len(keys) > 0 && secretValueRegex.MatchString(typedObj) {
+			paths[strings.Join(keys, "/")] = struct{}{}
+		}
+	}
+}
+
+func arrayElementKey(value interface{}, index int) string {
+	if hash, ok := value.(map[interface{}]interface{}); ok {
+		if name, found := hash["name"]; found {
+			return fmt.Sprintf("name=%v", name)
+		}
+	}
+
+	return strconv.Itoa(index)
+}
+
+// CollapseSecrets replaces a removed value at one of secretPaths that is directly
+// followed by an added value at the same path, including any multi-line
+// continuation of either, with a single line showing '[secret changed]'.
+func (d Diff) CollapseSecrets(secretPaths map[string]struct{}) Diff {
+	lines := [][]interface{}{}
+	paths := diffLinePaths(d.lines)
+
+	for i := 0; i < len(d.lines); i++ {
+		line := d.lines[i]
+
+		if _, found := secretPaths[paths[i]]; found && d.lineMod(i) == "removed" {
+			addedStart := d.endOfValue(i, "removed")
+			if addedStart < len(d.lines) && d.lineMod(addedStart) == "added" && paths[addedStart] == paths[i] {
+				lineText, _ := line[0].(string)
+				key := lineText[:strings.Index(lineText, ":")+1]
+				lines = append(lines, []interface{}{key + " " + secretChangedValue, nil})
+				i = d.endOfValue(addedStart, "added") - 1
+				continue
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return NewDiff(lines)
+}
+
+func (d Diff) lineMod(i int) string {
+	lineMod, _ := d.lines[i][1].(string)
+	return lineMod
+}
+
+// endOfValue returns the index after the key line at i and the more indented
+// lines with the same modification that continue its value.
+func (d Diff) endOfValue(i int, lineMod string) int {
+	keyIndent := diffLineIndent(d.lines[i])
+
+	j := i + 1
+	for j < len(d.lines) && d.lineMod(j) == lineMod && diffLineIndent(d.lines[j]) > keyIndent {
+		j++
+	}
+
+	return j
+}
+
+func diffLineIndent(line []interface{}) int {
+	lineText, _ := line[0].(string)
+	return len(lineText) - len(strings.TrimLeft(lineText, " "))
+}
+
+// diffLinePaths reconstructs the key path of each line from its indentation.
+// Array elements are identified like in SecretPaths; elements that start with
+// their name are identified by it and others by their position in the diff.
+// Lines that do not hold a key get an empty path.
+func diffLinePaths(lines [][]interface{}) []string {
+	type pathKey struct {
+		indent int
+		key    string
+		items  int
+	}
+
+	paths := make([]string, len(lines))
+	stack := []*pathKey{}
+
+	push := func(indent int, key string) {
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, &pathKey{indent: indent, key: key})
+	}
+
+	for i, line := range lines {
+		lineText, _ := line[0].(string)
+		lineMod, _ := line[1].(string)
+
+		content := strings.TrimLeft(lineText, " ")
+		indent := len(lineText) - len(content)
+		for strings.HasPrefix(content, "- ") {
+			content = strings.TrimPrefix(content, "- ")
+
+			// Elements sit between the key of their array and their own keys,
+			// which are indented further by the dash
+			for len(stack) > 0 && stack[len(stack)-1].indent > indent {
+				stack = stack[:len(stack)-1]
+			}
+
+			// Removed elements get the position of the element that replaces them
+			key := ""
+			if len(stack) > 0 {
+				key = strconv.Itoa(stack[len(stack)-1].items)
+				if lineMod != "removed" {
+					stack[len(stack)-1].items++
+				}
+			}
+
+			if strings.HasPrefix(content, "name: ") {
+				key = "name=" + strings.TrimSpace(strings.TrimPrefix(content, "name: "))
+			}
+
+			push(indent+1, key)
+			indent += 2
+		}
+
+		keyEnd := strings.Index(content, ":")
+		if keyEnd <= 0 || strings.ContainsAny(content[:keyEnd], " \"'") {
+			continue
+		}
+
+		push(indent, content[:keyEnd])
+
+		keys := []string{}
+		for _, k := range stack {
+			keys = append(keys, k.key)
+		}
+		paths[i] = strings.Join(keys, "/")
+	}
+
+	return paths
+}
+
+// ChangedLines returns the number of added and removed lines
+func (d Diff) ChangedLines() int {
+	var changed int
+
+	for i := range d.lines {
+		if lineMod := d.lineMod(i); lineMod == "added" || lineMod == "removed" {
+			changed++
+		}
+	}
+
+	return changed
+}
+
 func (d Diff) Print(ui boshui.UI) {
 	for _, line := range d.lines {
 		lineMod, _ := line[1].(string)
@@ -0,0 +1,129 @@
+package cmd_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+)
+
+var _ = Describe("Diff", func() {
+	Describe("SecretPaths", func() {
+		It("returns paths whose values are variable references, identifying array elements by name or index", func() {
+			paths, err := SecretPaths([]byte(`
+name: dep
+instance_groups:
+- name: web
+  properties:
+    password: ((web_password))
+    url: https://((host))/
+    tls:
+      certificate: ((web_tls.certificate))
+    users:
+    - password: ((admin_password))
+    - password: plain
+      keys: [((key_1)), fixed]
+- name: worker
+  properties:
+    password: plain
+`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(paths).To(Equal(map[string]struct{}{
+				"instance_groups/name=web/properties/password":         {},
+				"instance_groups/name=web/properties/tls/certificate":  {},
+				"instance_groups/name=web/properties/users/0/password": {},
+				"instance_groups/name=web/properties/users/1/keys/0":   {},
+			}))
+		})
+
+		It("returns an error when the manifest cannot be parsed", func() {
+			_, err := SecretPaths([]byte("{"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Parsing manifest for secret paths"))
+		})
+	})
+
+	Describe("CollapseSecrets", func() {
+		secretPaths := map[string]struct{}{
+			"instance_groups/name=web/properties/password":         {},
+			"instance_groups/name=web/properties/tls/certificate":  {},
+			"instance_groups/name=web/properties/users/1/password": {},
+		}
+
+		It("collapses changed multi-line secret values", func() {
+			diff := NewDiff([][]interface{}{
+				{"instance_groups:", nil},
+				{"- name: web", nil},
+				{"  properties:", nil},
+				{"    tls:", nil},
+				{"      certificate: |-", "removed"},
+				{"        old-line-1", "removed"},
+				{"        old-line-2", "removed"},
+				{"      certificate: |-", "added"},
+				{"        new-line-1", "added"},
+				{"    other: value", nil},
+			}).CollapseSecrets(secretPaths)
+
+			Expect(diff.String()).To(Equal(`  instance_groups:
+  - name: web
+    properties:
+      tls:
+        certificate: [secret changed]
+      other: value
+`))
+		})
+
+		It("keeps added or removed secrets and changes at other paths", func() {
+			diff := NewDiff([][]interface{}{
+				{"instance_groups:", nil},
+				{"- name: web", nil},
+				{"  properties:", nil},
+				{"    password: <redacted>", "added"},
+				{"- name: worker", "removed"},
+				{"  password: <redacted>", "removed"},
+				{"  password: <redacted>", "added"},
+			}).CollapseSecrets(secretPaths)
+
+			Expect(diff.String()).To(Equal(`  instance_groups:
+  - name: web
+    properties:
++     password: <redacted>
+- - name: worker
+-   password: <redacted>
++   password: <redacted>
+`))
+		})
+
+		It("only collapses secrets in the same array element as in the manifest", func() {
+			diff := NewDiff([][]interface{}{
+				{"instance_groups:", nil},
+				{"- name: worker", nil},
+				{"  properties:", nil},
+				{"    password: <redacted>", "removed"},
+				{"    password: <redacted>", "added"},
+				{"- name: web", nil},
+				{"  properties:", nil},
+				{"    users:", nil},
+				{"    - password: <redacted>", nil},
+				{"    - password: <redacted>", "removed"},
+				{"    - password: <redacted>", "added"},
+				{"    - password: <redacted>", "removed"},
+				{"    - password: <redacted>", "added"},
+			}).CollapseSecrets(secretPaths)
+
+			Expect(diff.String()).To(Equal(`  instance_groups:
+  - name: worker
+    properties:
+-     password: <redacted>
++     password: <redacted>
+  - name: web
+    properties:
+      users:
+      - password: <redacted>
+      - password: [secret changed]
+-     - password: <redacted>
++     - password: <redacted>
+`))
+		})
+	})
+})
@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package cmd
+
+import (
+	"syscall"
+)
+
+// processExists reports whether a process with pid is running, including ones owned by other users
+func processExists(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
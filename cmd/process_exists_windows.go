@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"os"
+)
+
+// processExists reports whether a process with pid is running; FindProcess fails on Windows otherwise
+func processExists(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	process.Release()
+
+	return true
+}
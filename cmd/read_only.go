@@ -0,0 +1,32 @@
+package cmd
+
+// isReadOnlyCommand reports whether opts selects a command that only inspects
+// director, cloud or local state. Commands are mutating unless listed here,
+// so that new commands are refused in read-only mode until reviewed.
+func isReadOnlyCommand(opts interface{}) bool {
+	switch typedOpts := opts.(type) {
+	case *CreateEnvOpts:
+		return typedOpts.PrintManifest
+
+	case *EnvDisksOpts:
+		return !typedOpts.Delete
+
+	case *EnvCloudCheckOpts:
+		return typedOpts.Report
+
+	case *HelpOpts,
+		*EnvironmentOpts, *EnvironmentsOpts, *ListStagesOpts, *ReplayEventsOpts, *ValidateManifestOpts,
+		*EnvInfoOpts, *EnvInstancesOpts, *EnvLogsOpts,
+		*TaskOpts, *TasksOpts, *LocksOpts,
+		*ConfigOpts, *ConfigsOpts, *DiffConfigOpts,
+		*CloudConfigOpts, *CPIConfigOpts, *RuntimeConfigOpts,
+		*DeploymentOpts, *DeploymentsOpts, *ManifestOpts, *InterpolateOpts,
+		*EventsOpts, *EventOpts,
+		*StemcellsOpts, *ReleasesOpts, *InspectReleaseOpts, *ErrandsOpts,
+		*DisksOpts, *SnapshotsOpts, *InstancesOpts, *VMsOpts, *OrphanedVMsOpts,
+		*DiffOpts, *DiffReleasesOpts, *BlobsOpts, *VariablesOpts:
+		return true
+	}
+
+	return false
+}
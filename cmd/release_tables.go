@@ -12,6 +12,10 @@ import (
 type ReleaseTables struct {
 	Release     boshrel.Release
 	ArchivePath string
+
+	// ArchiveSHA1 and ArchiveSize are shown only when ArchiveSHA1 is set
+	ArchiveSHA1 string
+	ArchiveSize uint64
 }
 
 func (t ReleaseTables) Print(ui boshui.UI) {
@@ -37,6 +41,16 @@ func (t ReleaseTables) Print(ui boshui.UI) {
 		})
 	}
 
+	if len(t.ArchiveSHA1) > 0 {
+		summaryTable = summaryTable.AddColumn("SHA1", []boshtbl.Value{
+			boshtbl.NewValueString(t.ArchiveSHA1),
+		})
+
+		summaryTable = summaryTable.AddColumn("Size", []boshtbl.Value{
+			boshtbl.NewValueBytes(t.ArchiveSize),
+		})
+	}
+
 	jobsTable := boshtbl.Table{
 		Content: "jobs",
 		Header: []boshtbl.Header{
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"github.com/cloudfoundry/bosh-cli/eventlog"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+type ReplayEventsCmd struct {
+	fs     boshsys.FileSystem
+	ui     boshui.UI
+	logger boshlog.Logger
+}
+
+func NewReplayEventsCmd(fs boshsys.FileSystem, ui boshui.UI, logger boshlog.Logger) ReplayEventsCmd {
+	return ReplayEventsCmd{fs: fs, ui: ui, logger: logger}
+}
+
+func (c ReplayEventsCmd) Run(opts ReplayEventsOpts) error {
+	eventLog, err := eventlog.Read(c.fs, opts.Args.Path)
+	if err != nil {
+		return err
+	}
+
+	c.ui.PrintLinef("Command: %s", eventLog.Command)
+	c.ui.PrintLinef("CLI version: %s", eventLog.CLIVersion)
+	c.ui.PrintLinef("Correlation ID: %s", eventLog.CorrelationID)
+
+	boshui.ReplayStageEvents(c.ui, eventLog.Events, c.logger)
+
+	if len(eventLog.Warnings) > 0 {
+		table := boshtbl.Table{
+			Title:   "Warnings",
+			Content: "warnings",
+
+			Header: []boshtbl.Header{
+				boshtbl.NewHeader("Source"),
+				boshtbl.NewHeader("Warning"),
+			},
+		}
+
+		for _, warning := range eventLog.Warnings {
+			table.Rows = append(table.Rows, []boshtbl.Value{
+				boshtbl.NewValueString(warning.Source),
+				boshtbl.NewValueString(warning.Message),
+			})
+		}
+
+		c.ui.PrintTable(table)
+	}
+
+	if eventLog.Error != "" {
+		c.ui.ErrorLinef("\n%s failed: %s", eventLog.Command, eventLog.Error)
+	}
+
+	return nil
+}
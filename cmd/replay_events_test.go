@@ -0,0 +1,77 @@
+package cmd_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	"github.com/cloudfoundry/bosh-cli/eventlog"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+)
+
+var _ = Describe("ReplayEventsCmd", func() {
+	var (
+		fs      *fakesys.FakeFileSystem
+		ui      *fakeui.FakeUI
+		logger  boshlog.Logger
+		command ReplayEventsCmd
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		ui = &fakeui.FakeUI{}
+		logger = boshlog.NewLogger(boshlog.LevelNone)
+		command = NewReplayEventsCmd(fs, ui, logger)
+	})
+
+	act := func() error {
+		return command.Run(ReplayEventsOpts{Args: ReplayEventsArgs{Path: "/fake/events.json.gz"}})
+	}
+
+	Context("when the event log exists", func() {
+		BeforeEach(func() {
+			startTime := time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC)
+
+			recorder := eventlog.NewRecorder("create-env", "1.2.3", "fake-correlation-id", nil, fs, logger)
+			recorder.RecordStageEvent(boshui.StageEvent{Time: startTime, Type: boshui.StageStarted, Stages: []string{"Creating VM"}})
+			recorder.RecordStageEvent(boshui.StageEvent{Time: startTime.Add(time.Minute), Type: boshui.StageFinished, Stages: []string{"Creating VM"}, Duration: time.Minute})
+
+			err := recorder.Write("/fake/events.json.gz", []biwarn.Warning{{Source: "blobstore", Message: "fake-warning"}}, errors.New("fake-run-err"))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("prints the recorded command, stages, warnings and error", func() {
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Said).To(Equal([]string{
+				"Command: create-env",
+				"CLI version: 1.2.3",
+				"Correlation ID: fake-correlation-id",
+				"Creating VM...",
+				" Finished (00:01:00)",
+			}))
+
+			Expect(ui.Tables).To(HaveLen(1))
+			Expect(ui.Tables[0].Rows).To(Equal([][]boshtbl.Value{
+				{boshtbl.NewValueString("blobstore"), boshtbl.NewValueString("fake-warning")},
+			}))
+
+			Expect(ui.Errors).To(Equal([]string{"\ncreate-env failed: fake-run-err"}))
+		})
+	})
+
+	It("returns an error when the event log cannot be read", func() {
+		err := act()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Reading event log '/fake/events.json.gz'"))
+	})
+})
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// StateLock keeps commands such as create-env and delete-env from changing the same deployment state at once.
+// The lock is a file next to the state file holding the PID of its owner, so that a lock left
+// behind by a process that is gone, e.g. after being killed, is taken over.
+type StateLock struct {
+	fs            boshsys.FileSystem
+	statePath     string
+	forceUnlock   bool
+	pid           int
+	processExists func(int) bool
+	logger        boshlog.Logger
+	logTag        string
+}
+
+func NewStateLock(
+	fs boshsys.FileSystem,
+	statePath string,
+	forceUnlock bool,
+	pid int,
+	processExists func(int) bool,
+	logger boshlog.Logger,
+) StateLock {
+	return StateLock{
+		fs:            fs,
+		statePath:     statePath,
+		forceUnlock:   forceUnlock,
+		pid:           pid,
+		processExists: processExists,
+		logger:        logger,
+		logTag:        "stateLock",
+	}
+}
+
+// Lock returns a function that releases the lock
+func (l StateLock) Lock() (func(), error) {
+	lockPath := l.statePath + ".lock"
+
+	if l.fs.FileExists(lockPath) {
+		err := l.takeOver(lockPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := l.create(lockPath, l.pid)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		if err := l.fs.RemoveAll(lockPath); err != nil {
+			l.logger.Warn(l.logTag, "Failed to remove state lock '%s': %s", lockPath, err.Error())
+		}
+	}, nil
+}
+
+// takeOver removes a lock left behind by a process that is gone. A lock without a PID
+// is being created by another run. The lock is moved aside before it is removed and
+// checked again, since another run may have taken it over in the meantime; such a
+// lock is put back unless a third run already holds the lock.
+func (l StateLock) takeOver(lockPath string) error {
+	pid, found := l.holderPID(lockPath)
+	if !l.forceUnlock && (!found || l.processExists(pid)) {
+		return l.lockedErr(pid, found)
+	}
+
+	stalePath := lockPath + ".stale-" + strconv.Itoa(l.pid)
+
+	err := l.fs.Rename(lockPath, stalePath)
+	if err != nil {
+		if l.fs.FileExists(lockPath) {
+			return bosherr.WrapErrorf(err, "Moving stale state lock '%s'", lockPath)
+		}
+
+		// Another run took the lock over first, creating the lock tells whether it still holds it
+		return nil
+	}
+
+	defer l.fs.RemoveAll(stalePath)
+
+	movedPID, movedFound := l.holderPID(stalePath)
+	if movedFound != found || movedPID != pid {
+		if err := l.create(lockPath, movedPID); err != nil {
+			l.logger.Warn(l.logTag, "Failed to put back state lock '%s' of PID %d: %s", lockPath, movedPID, err.Error())
+		}
+
+		return l.lockedErr(movedPID, movedFound)
+	}
+
+	l.logger.Warn(l.logTag, "Taking over lock '%s' of PID %d", lockPath, pid)
+
+	return nil
+}
+
+// create fails when the lock exists, so that only one of several runs gets it
+func (l StateLock) create(lockPath string, pid int) error {
+	file, err := l.fs.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			holderPID, found := l.holderPID(lockPath)
+			return l.lockedErr(holderPID, found)
+		}
+
+		return bosherr.WrapErrorf(err, "Creating state lock '%s'", lockPath)
+	}
+
+	_, err = file.Write([]byte(strconv.Itoa(pid)))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		l.fs.RemoveAll(lockPath)
+		return bosherr.WrapErrorf(err, "Writing state lock '%s'", lockPath)
+	}
+
+	return nil
+}
+
+func (l StateLock) holderPID(lockPath string) (int, bool) {
+	contents, err := l.fs.ReadFileString(lockPath)
+	if err != nil {
+		return 0, false
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(contents))
+	if err != nil {
+		return 0, false
+	}
+
+	return pid, true
+}
+
+func (l StateLock) lockedErr(pid int, found bool) error {
+	if !found {
+		return bosherr.Errorf(
+			"Deployment state '%s' is being locked by another process, use --force-unlock if no process is changing it", l.statePath)
+	}
+
+	return bosherr.Errorf(
+		"Deployment state '%s' is locked by PID %d, use --force-unlock if that process is no longer changing it", l.statePath, pid)
+}
@@ -0,0 +1,128 @@
+package cmd_test
+
+import (
+	"errors"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+)
+
+var _ = Describe("StateLock", func() {
+	var (
+		fs            *fakesys.FakeFileSystem
+		runningPIDs   map[int]bool
+		forceUnlock   bool
+		processExists func(int) bool
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		runningPIDs = map[int]bool{}
+		forceUnlock = false
+		processExists = func(pid int) bool { return runningPIDs[pid] }
+	})
+
+	lock := func() (func(), error) {
+		return NewStateLock(fs, "/state.json", forceUnlock, 123, processExists, boshlog.NewLogger(boshlog.LevelNone)).Lock()
+	}
+
+	It("writes its PID to a lock file next to the state file until unlocked", func() {
+		unlock, err := lock()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fs.ReadFileString("/state.json.lock")).To(Equal("123"))
+
+		unlock()
+		Expect(fs.FileExists("/state.json.lock")).To(BeFalse())
+	})
+
+	It("returns an error naming the PID holding the lock when it is still running", func() {
+		fs.WriteFileString("/state.json.lock", "456")
+		runningPIDs[456] = true
+
+		_, err := lock()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Deployment state '/state.json' is locked by PID 456, use --force-unlock if that process is no longer changing it"))
+		Expect(fs.ReadFileString("/state.json.lock")).To(Equal("456"))
+	})
+
+	It("takes over a lock whose process is gone", func() {
+		fs.WriteFileString("/state.json.lock", "456")
+
+		_, err := lock()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fs.ReadFileString("/state.json.lock")).To(Equal("123"))
+	})
+
+	It("returns an error for a lock that does not hold a PID yet since another run is creating it", func() {
+		fs.WriteFileString("/state.json.lock", "")
+
+		_, err := lock()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Deployment state '/state.json' is being locked by another process, use --force-unlock if no process is changing it"))
+	})
+
+	It("takes over a lock that does not hold a PID when forced", func() {
+		fs.WriteFileString("/state.json.lock", "")
+		forceUnlock = true
+
+		_, err := lock()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fs.ReadFileString("/state.json.lock")).To(Equal("123"))
+	})
+
+	It("moves a stale lock aside before removing it", func() {
+		fs.WriteFileString("/state.json.lock", "456")
+
+		_, err := lock()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fs.RenameOldPaths).To(Equal([]string{"/state.json.lock"}))
+		Expect(fs.RenameNewPaths).To(Equal([]string{"/state.json.lock.stale-123"}))
+		Expect(fs.FileExists("/state.json.lock.stale-123")).To(BeFalse())
+	})
+
+	It("puts back a lock that another run took over after the stale lock was checked", func() {
+		fs.WriteFileString("/state.json.lock", "456")
+		processExists = func(pid int) bool {
+			// another run replaces the stale lock with its own before it is moved aside
+			fs.WriteFileString("/state.json.lock", "789")
+			return false
+		}
+
+		_, err := lock()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("is locked by PID 789"))
+		Expect(fs.ReadFileString("/state.json.lock")).To(Equal("789"))
+		Expect(fs.FileExists("/state.json.lock.stale-123")).To(BeFalse())
+	})
+
+	It("returns an error when a stale lock cannot be moved aside", func() {
+		fs.WriteFileString("/state.json.lock", "456")
+		fs.RenameError = errors.New("fake-rename-err")
+
+		_, err := lock()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Moving stale state lock '/state.json.lock': fake-rename-err"))
+	})
+
+	It("takes over a lock of a running process when forced", func() {
+		fs.WriteFileString("/state.json.lock", "456")
+		runningPIDs[456] = true
+		forceUnlock = true
+
+		_, err := lock()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fs.ReadFileString("/state.json.lock")).To(Equal("123"))
+	})
+
+	It("returns an error when the lock file cannot be created", func() {
+		fs.OpenFileErr = errors.New("fake-err")
+
+		_, err := lock()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Creating state lock '/state.json.lock': fake-err"))
+	})
+})
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	biconfig "github.com/cloudfoundry/bosh-cli/config"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+// StateStemcellsCmd lists the stemcells that create-env uploaded to the CPI,
+// as recorded in the deployment state file, without contacting a director
+type StateStemcellsCmd struct {
+	ui                     boshui.UI
+	deploymentStateService biconfig.DeploymentStateService
+	stemcellRepo           biconfig.StemcellRepo
+}
+
+func NewStateStemcellsCmd(
+	ui boshui.UI,
+	deploymentStateService biconfig.DeploymentStateService,
+	stemcellRepo biconfig.StemcellRepo,
+) StateStemcellsCmd {
+	return StateStemcellsCmd{
+		ui:                     ui,
+		deploymentStateService: deploymentStateService,
+		stemcellRepo:           stemcellRepo,
+	}
+}
+
+func (c StateStemcellsCmd) Run() error {
+	_, err := loadExistingDeploymentState(c.deploymentStateService)
+	if err != nil {
+		return err
+	}
+
+	stemcells, err := c.stemcellRepo.All()
+	if err != nil {
+		return bosherr.WrapError(err, "Loading stemcells from deployment state")
+	}
+
+	current, found, err := c.stemcellRepo.FindCurrent()
+	if err != nil {
+		return bosherr.WrapError(err, "Finding current stemcell in deployment state")
+	}
+
+	table := boshtbl.Table{
+		Content: "stemcells",
+
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Name"),
+			boshtbl.NewHeader("Version"),
+			boshtbl.NewHeader("CID"),
+		},
+
+		SortBy: []boshtbl.ColumnSort{
+			{Column: 0, Asc: true},
+			{Column: 1, Asc: false},
+		},
+
+		Notes: []string{"(*) Currently deployed"},
+	}
+
+	for _, stemcell := range stemcells {
+		var mark string
+		if found && stemcell.ID == current.ID {
+			mark = "*"
+		}
+
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(stemcell.Name),
+			boshtbl.NewValueSuffix(boshtbl.NewValueString(stemcell.Version), mark),
+			boshtbl.NewValueString(stemcell.CID),
+		})
+	}
+
+	c.ui.PrintTable(table)
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package cmd_test
+
+import (
+	"errors"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+	biconfig "github.com/cloudfoundry/bosh-cli/config"
+	fakebiconfig "github.com/cloudfoundry/bosh-cli/config/fakes"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+var _ = Describe("StateStemcellsCmd", func() {
+	var (
+		ui                     *fakeui.FakeUI
+		fs                     *fakesys.FakeFileSystem
+		deploymentStateService biconfig.DeploymentStateService
+		stemcellRepo           *fakebiconfig.FakeStemcellRepo
+		command                StateStemcellsCmd
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		fs = fakesys.NewFakeFileSystem()
+		deploymentStateService = biconfig.NewFileSystemDeploymentStateService(
+			fs, &fakeuuid.FakeGenerator{}, boshlog.NewLogger(boshlog.LevelNone), "/fake-state.json")
+		stemcellRepo = fakebiconfig.NewFakeStemcellRepo()
+		command = NewStateStemcellsCmd(ui, deploymentStateService, stemcellRepo)
+	})
+
+	Describe("Run", func() {
+		act := func() error { return command.Run() }
+
+		Context("when the state file exists", func() {
+			BeforeEach(func() {
+				fs.WriteFileString("/fake-state.json", "{}")
+			})
+
+			It("lists stemcells recorded in the state file", func() {
+				stemcellRepo.AllStemcellRecords = []biconfig.StemcellRecord{
+					{ID: "fake-stemcell-id-1", Name: "stem1", Version: "1", CID: "stem1-cid"},
+					{ID: "fake-stemcell-id-2", Name: "stem2", Version: "2", CID: "stem2-cid"},
+				}
+
+				err := stemcellRepo.SetFindCurrentBehavior(stemcellRepo.AllStemcellRecords[1], true, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = act()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(ui.Table).To(Equal(boshtbl.Table{
+					Content: "stemcells",
+
+					Header: []boshtbl.Header{
+						boshtbl.NewHeader("Name"),
+						boshtbl.NewHeader("Version"),
+						boshtbl.NewHeader("CID"),
+					},
+
+					SortBy: []boshtbl.ColumnSort{
+						{Column: 0, Asc: true},
+						{Column: 1, Asc: false},
+					},
+
+					Notes: []string{"(*) Currently deployed"},
+
+					Rows: [][]boshtbl.Value{
+						{
+							boshtbl.NewValueString("stem1"),
+							boshtbl.NewValueSuffix(boshtbl.NewValueString("1"), ""),
+							boshtbl.NewValueString("stem1-cid"),
+						},
+						{
+							boshtbl.NewValueString("stem2"),
+							boshtbl.NewValueSuffix(boshtbl.NewValueString("2"), "*"),
+							boshtbl.NewValueString("stem2-cid"),
+						},
+					},
+				}))
+			})
+
+			It("returns an error if stemcells cannot be loaded", func() {
+				stemcellRepo.AllErr = errors.New("fake-err")
+
+				err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-err"))
+			})
+		})
+
+		It("returns an error without creating the state file when it does not exist", func() {
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("No deployment state file found at '/fake-state.json'"))
+
+			Expect(fs.FileExists("/fake-state.json")).To(BeFalse())
+			Expect(ui.Table).To(Equal(boshtbl.Table{}))
+		})
+	})
+})
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/cppforlife/go-patch/patch"
+)
+
+type TagArg struct {
+	Key   string
+	Value string
+}
+
+func (a *TagArg) UnmarshalFlag(data string) error {
+	pieces := strings.SplitN(data, "=", 2)
+	if len(pieces) != 2 {
+		return bosherr.Errorf("Expected tag '%s' to be in format 'key=value'", data)
+	}
+
+	if len(pieces[0]) == 0 {
+		return bosherr.Errorf("Expected tag '%s' to specify non-empty key", data)
+	}
+
+	*a = TagArg{Key: pieces[0], Value: pieces[1]}
+
+	return nil
+}
+
+// withTags sets tags in the manifest's top level tags after op is applied,
+// replacing manifest tags with the same key
+func withTags(op patch.Op, tags []TagArg) patch.Op {
+	ops := patch.Ops{op}
+
+	for _, tag := range tags {
+		ops = append(ops, patch.ReplaceOp{
+			Path: patch.NewPointer([]patch.Token{
+				patch.RootToken{},
+				patch.KeyToken{Key: "tags", Optional: true},
+				patch.KeyToken{Key: tag.Key, Optional: true},
+			}),
+			Value: tag.Value,
+		})
+	}
+
+	return ops
+}
@@ -0,0 +1,44 @@
+package cmd_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/cmd"
+)
+
+var _ = Describe("TagArg", func() {
+	Describe("UnmarshalFlag", func() {
+		var (
+			arg *TagArg
+		)
+
+		BeforeEach(func() {
+			arg = &TagArg{}
+		})
+
+		It("sets key and value", func() {
+			err := arg.UnmarshalFlag("cost-center=team=a")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*arg).To(Equal(TagArg{Key: "cost-center", Value: "team=a"}))
+		})
+
+		It("allows empty value", func() {
+			err := arg.UnmarshalFlag("owner=")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*arg).To(Equal(TagArg{Key: "owner"}))
+		})
+
+		It("returns an error if value is not in the key=value format", func() {
+			err := arg.UnmarshalFlag("owner")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected tag 'owner' to be in format 'key=value'"))
+		})
+
+		It("returns an error if key is empty", func() {
+			err := arg.UnmarshalFlag("=me")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected tag '=me' to specify non-empty key"))
+		})
+	})
+})
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"regexp"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+
+	bideplmanifest "github.com/cloudfoundry/bosh-cli/deployment/manifest"
+	bidepltpl "github.com/cloudfoundry/bosh-cli/deployment/template"
+	boshinst "github.com/cloudfoundry/bosh-cli/installation"
+	boshrel "github.com/cloudfoundry/bosh-cli/release"
+	bistemcell "github.com/cloudfoundry/bosh-cli/stemcell"
+	boshui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
+)
+
+// ValidateManifestCmd runs the validations of create-env against a manifest
+// without downloading anything, installing the CPI or touching the IaaS.
+// Jobs are only checked against releases and stemcells when their tarballs are given.
+type ValidateManifestCmd struct {
+	ui boshui.UI
+
+	manifestParser      ReleaseSetAndInstallationManifestParser
+	templateFactory     bidepltpl.DeploymentTemplateFactory
+	deploymentParser    bideplmanifest.Parser
+	deploymentValidator bideplmanifest.Validator
+	releaseReader       boshrel.Reader
+	releaseManager      boshinst.ReleaseManager
+	stemcellExtractor   bistemcell.Extractor
+	warnings            biwarn.Warnings
+
+	logTag string
+	logger boshlog.Logger
+}
+
+func NewValidateManifestCmd(
+	ui boshui.UI,
+	manifestParser ReleaseSetAndInstallationManifestParser,
+	templateFactory bidepltpl.DeploymentTemplateFactory,
+	deploymentParser bideplmanifest.Parser,
+	deploymentValidator bideplmanifest.Validator,
+	releaseReader boshrel.Reader,
+	releaseManager boshinst.ReleaseManager,
+	stemcellExtractor bistemcell.Extractor,
+	warnings biwarn.Warnings,
+	logger boshlog.Logger,
+) ValidateManifestCmd {
+	return ValidateManifestCmd{
+		ui: ui,
+
+		manifestParser:      manifestParser,
+		templateFactory:     templateFactory,
+		deploymentParser:    deploymentParser,
+		deploymentValidator: deploymentValidator,
+		releaseReader:       releaseReader,
+		releaseManager:      releaseManager,
+		stemcellExtractor:   stemcellExtractor,
+		warnings:            warnings,
+
+		logTag: "validateManifestCmd",
+		logger: logger,
+	}
+}
+
+func (c ValidateManifestCmd) Run(opts ValidateManifestOpts) error {
+	if len(opts.Stemcell.ExpandedPath) > 0 && len(opts.Releases) == 0 {
+		return bosherr.Error("Expected --release to be given with --stemcell")
+	}
+
+	removeManifest, err := opts.Args.Manifest.WriteTempFile()
+	if err != nil {
+		return err
+	}
+
+	defer removeManifest()
+
+	defer func() {
+		err := c.releaseManager.DeleteAll()
+		if err != nil {
+			c.logger.Warn(c.logTag, "Deleting all extracted releases: %s", err.Error())
+		}
+	}()
+
+	errs := c.validate(opts)
+
+	printWarnings(c.ui, c.warnings)
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	table := boshtbl.Table{
+		Title:   "Validation errors",
+		Content: "errors",
+
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Path"),
+			boshtbl.NewHeader("Error"),
+		},
+	}
+
+	for _, err := range errs {
+		path, msg := splitManifestPath(err.Error())
+
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(path),
+			boshtbl.NewValueString(msg),
+		})
+	}
+
+	c.ui.PrintTable(table)
+
+	return bosherr.Errorf("Manifest '%s' is invalid: found %d error(s)", opts.Args.Manifest.Name(), len(errs))
+}
+
+// validate keeps going after a failed validation as long as later validations
+// do not depend on its result so that all errors are reported at once
+func (c ValidateManifestCmd) validate(opts ValidateManifestOpts) []error {
+	path := opts.Args.Manifest.Path
+	vars := opts.VarFlags.AsVariables()
+	op := opts.OpsFlags.AsOp()
+
+	releaseSetManifest, err := c.manifestParser.ReleaseSetParser.Parse(path, vars, op)
+	if err != nil {
+		// Other validations refer to releases, so they would only add noise
+		return validationErrors(err)
+	}
+
+	_, err = c.manifestParser.InstallationParser.Parse(path, vars, op, releaseSetManifest)
+	errs := validationErrors(err)
+
+	template, err := c.templateFactory.NewDeploymentTemplateFromPath(path)
+	if err != nil {
+		return append(errs, bosherr.WrapErrorf(err, "Evaluating manifest"))
+	}
+
+	interpolatedTemplate, err := template.Evaluate(vars, op)
+	if err != nil {
+		return append(errs, bosherr.WrapErrorf(err, "Evaluating manifest '%s'", path))
+	}
+
+	deploymentManifest, err := c.deploymentParser.Parse(interpolatedTemplate, path)
+	if err != nil {
+		return append(errs, bosherr.WrapErrorf(err, "Parsing deployment manifest '%s'", path))
+	}
+
+	errs = append(errs, validationErrors(c.deploymentValidator.Validate(deploymentManifest, releaseSetManifest))...)
+
+	if len(opts.Releases) == 0 {
+		return errs
+	}
+
+	for _, releaseArg := range opts.Releases {
+		release, err := c.releaseReader.Read(releaseArg.ExpandedPath)
+		if err != nil {
+			return append(errs, bosherr.WrapErrorf(err, "Extracting release '%s'", releaseArg.ExpandedPath))
+		}
+
+		c.releaseManager.Add(release)
+
+		if _, found := releaseSetManifest.FindByName(release.Name()); !found {
+			errs = append(errs, bosherr.Errorf("Release '%s' from tarball '%s' must be in releases", release.Name(), releaseArg.ExpandedPath))
+		}
+	}
+
+	errs = append(errs, validationErrors(c.deploymentValidator.ValidateReleaseJobs(deploymentManifest, c.releaseManager))...)
+	errs = append(errs, validationErrors(c.deploymentValidator.ValidateJobProperties(deploymentManifest, c.releaseManager))...)
+
+	if len(opts.Stemcell.ExpandedPath) == 0 {
+		return errs
+	}
+
+	extractedStemcell, err := c.stemcellExtractor.Extract(opts.Stemcell.ExpandedPath)
+	if err != nil {
+		return append(errs, bosherr.WrapErrorf(err, "Extracting stemcell '%s'", opts.Stemcell.ExpandedPath))
+	}
+
+	defer func() {
+		err := extractedStemcell.Cleanup()
+		if err != nil {
+			c.logger.Warn(c.logTag, "Failed to delete extracted stemcell: %s", err.Error())
+		}
+	}()
+
+	stemcellManifest := extractedStemcell.Manifest()
+
+	return append(errs, validationErrors(c.deploymentValidator.ValidateStemcellCompatibility(
+		deploymentManifest, c.releaseManager, stemcellManifest.OS, stemcellManifest.Version))...)
+}
+
+// validationErrors returns the individual errors collected by a manifest validator,
+// which parsers return wrapped in their own errors
+func validationErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	cause := err
+
+	for {
+		switch typedErr := cause.(type) {
+		case bosherr.MultiError:
+			return typedErr.Errors
+		case bosherr.ComplexError:
+			cause = typedErr.Cause
+		default:
+			return []error{err}
+		}
+	}
+}
+
+var manifestPathPrefix = regexp.MustCompile(`^([a-z_]+(?:\[\d+\])?(?:\.[a-z0-9_]+(?:\[\d+\])?)*) (.+)$`)
+
+// splitManifestPath separates the manifest path that validation errors start with,
+// e.g. 'jobs[0].networks' of 'jobs[0].networks must be a non-empty array'
+func splitManifestPath(msg string) (string, string) {
+	matches := manifestPathPrefix.FindStringSubmatch(msg)
+	if matches == nil {
+		return "", msg
+	}
+
+	return matches[1], matches[2]
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	biui "github.com/cloudfoundry/bosh-cli/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/ui/table"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
+)
+
+// printWarnings shows all warnings raised while a command ran in one table
+// so that they are not lost in the output of a long deploy or delete
+func printWarnings(ui biui.UI, warnings biwarn.Warnings) {
+	list := warnings.List()
+	if len(list) == 0 {
+		return
+	}
+
+	table := boshtbl.Table{
+		Title:   "Warnings",
+		Content: "warnings",
+
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Source"),
+			boshtbl.NewHeader("Warning"),
+		},
+	}
+
+	for _, warning := range list {
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(warning.Source),
+			boshtbl.NewValueString(warning.Message),
+		})
+	}
+
+	ui.PrintTable(table)
+}
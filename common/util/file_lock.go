@@ -0,0 +1,35 @@
+//go:build !windows
+// +build !windows
+
+package util
+
+import (
+	"syscall"
+
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// TryLockFile tries to lock file without waiting, exclusively or shared with other readers.
+// Taking a shared lock while holding the exclusive lock downgrades it.
+// The lock is released when the file is closed.
+// Files that are not backed by a file descriptor (e.g. fakes) are not locked.
+func TryLockFile(file boshsys.File, exclusive bool) (bool, error) {
+	fdFile, ok := file.(interface {
+		Fd() uintptr
+	})
+	if !ok {
+		return true, nil
+	}
+
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+
+	err := syscall.Flock(int(fdFile.Fd()), how|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return false, nil
+	}
+
+	return err == nil, err
+}
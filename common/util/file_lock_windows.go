@@ -0,0 +1,10 @@
+package util
+
+import (
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// TryLockFile does not lock on Windows, so other processes are not kept out there
+func TryLockFile(_ boshsys.File, _ bool) (bool, error) {
+	return true, nil
+}
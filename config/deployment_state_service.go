@@ -15,6 +15,13 @@ type DeploymentState struct {
 	Disks              []DiskRecord     `json:"disks"`
 	Stemcells          []StemcellRecord `json:"stemcells"`
 	Releases           []ReleaseRecord  `json:"releases"`
+
+	// MigratingDiskID is the disk that data is being migrated to;
+	// it is only set while a persistent disk migration is in progress
+	MigratingDiskID string `json:"migrating_disk_id,omitempty"`
+
+	// Production requires delete-env to be confirmed by typing the deployment name
+	Production bool `json:"production,omitempty"`
 }
 
 type StemcellRecord struct {
@@ -29,6 +36,9 @@ type DiskRecord struct {
 	CID             string         `json:"cid"`
 	Size            int            `json:"size"`
 	CloudProperties biproperty.Map `json:"cloud_properties"`
+
+	// Tags were added to the cloud properties when the disk was created
+	Tags map[string]string `json:"tags,omitempty"`
 }
 
 type ReleaseRecord struct {
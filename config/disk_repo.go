@@ -10,7 +10,10 @@ type DiskRepo interface {
 	UpdateCurrent(diskID string) error
 	FindCurrent() (DiskRecord, bool, error)
 	ClearCurrent() error
-	Save(cid string, size int, cloudProperties biproperty.Map) (DiskRecord, error)
+	UpdateMigrating(diskID string) error
+	FindMigrating() (DiskRecord, bool, error)
+	ClearMigrating() error
+	Save(cid string, size int, cloudProperties biproperty.Map, tags map[string]string) (DiskRecord, error)
 	Find(cid string) (DiskRecord, bool, error)
 	All() ([]DiskRecord, error)
 	Delete(DiskRecord) error
@@ -28,7 +31,7 @@ func NewDiskRepo(deploymentStateService DeploymentStateService, uuidGenerator bo
 	}
 }
 
-func (r diskRepo) Save(cid string, size int, cloudProperties biproperty.Map) (DiskRecord, error) {
+func (r diskRepo) Save(cid string, size int, cloudProperties biproperty.Map, tags map[string]string) (DiskRecord, error) {
 	config, records, err := r.load()
 	if err != nil {
 		return DiskRecord{}, err
@@ -43,6 +46,7 @@ func (r diskRepo) Save(cid string, size int, cloudProperties biproperty.Map) (Di
 		CID:             cid,
 		Size:            size,
 		CloudProperties: cloudProperties,
+		Tags:            tags,
 	}
 	newRecord.ID, err = r.uuidGenerator.Generate()
 	if err != nil {
@@ -142,6 +146,10 @@ func (r diskRepo) Delete(diskRecord DiskRecord) error {
 		config.CurrentDiskID = ""
 	}
 
+	if config.MigratingDiskID == diskRecord.ID {
+		config.MigratingDiskID = ""
+	}
+
 	err = r.deploymentStateService.Save(config)
 	if err != nil {
 		return bosherr.WrapError(err, "Saving new config")
@@ -165,6 +173,68 @@ func (r diskRepo) ClearCurrent() error {
 	return nil
 }
 
+// UpdateMigrating records the disk that data is being migrated to
+// so that an interrupted migration can be rolled back
+func (r diskRepo) UpdateMigrating(diskID string) error {
+	deploymentState, err := r.deploymentStateService.Load()
+	if err != nil {
+		return bosherr.WrapError(err, "Loading existing config")
+	}
+
+	found := false
+	for _, oldRecord := range deploymentState.Disks {
+		if oldRecord.ID == diskID {
+			found = true
+		}
+	}
+	if !found {
+		return bosherr.Errorf("Verifying disk record exists with id '%s'", diskID)
+	}
+
+	deploymentState.MigratingDiskID = diskID
+
+	err = r.deploymentStateService.Save(deploymentState)
+	if err != nil {
+		return bosherr.WrapError(err, "Saving new config")
+	}
+	return nil
+}
+
+func (r diskRepo) FindMigrating() (DiskRecord, bool, error) {
+	deploymentState, err := r.deploymentStateService.Load()
+	if err != nil {
+		return DiskRecord{}, false, bosherr.WrapError(err, "Loading existing config")
+	}
+
+	migratingDiskID := deploymentState.MigratingDiskID
+	if migratingDiskID == "" {
+		return DiskRecord{}, false, nil
+	}
+
+	for _, oldRecord := range deploymentState.Disks {
+		if oldRecord.ID == migratingDiskID {
+			return oldRecord, true, nil
+		}
+	}
+
+	return DiskRecord{}, false, nil
+}
+
+func (r diskRepo) ClearMigrating() error {
+	deploymentState, err := r.deploymentStateService.Load()
+	if err != nil {
+		return bosherr.WrapError(err, "Loading existing config")
+	}
+
+	deploymentState.MigratingDiskID = ""
+
+	err = r.deploymentStateService.Save(deploymentState)
+	if err != nil {
+		return bosherr.WrapError(err, "Saving new config")
+	}
+	return nil
+}
+
 func (r diskRepo) load() (DeploymentState, []DiskRecord, error) {
 	deploymentState, err := r.deploymentStateService.Load()
 	if err != nil {
@@ -33,7 +33,7 @@ var _ = Describe("DiskRepo", func() {
 
 	Describe("Save", func() {
 		It("saves the disk record using the config service", func() {
-			record, err := repo.Save("fake-cid", 1024, cloudProperties)
+			record, err := repo.Save("fake-cid", 1024, cloudProperties, nil)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(record).To(Equal(DiskRecord{
 				ID:              "fake-uuid-1",
@@ -62,7 +62,7 @@ var _ = Describe("DiskRepo", func() {
 
 	Describe("Find", func() {
 		It("finds existing disk records", func() {
-			savedRecord, err := repo.Save("fake-cid", 1024, cloudProperties)
+			savedRecord, err := repo.Save("fake-cid", 1024, cloudProperties, nil)
 			Expect(err).ToNot(HaveOccurred())
 
 			foundRecord, found, err := repo.Find("fake-cid")
@@ -72,7 +72,7 @@ var _ = Describe("DiskRepo", func() {
 		})
 
 		It("when the disk is not in the records, returns not found", func() {
-			_, err := repo.Save("other-cid", 1024, cloudProperties)
+			_, err := repo.Save("other-cid", 1024, cloudProperties, nil)
 			Expect(err).ToNot(HaveOccurred())
 
 			_, found, err := repo.Find("fake-cid")
@@ -88,7 +88,7 @@ var _ = Describe("DiskRepo", func() {
 			)
 
 			BeforeEach(func() {
-				record, err := repo.Save("fake-cid", 1024, cloudProperties)
+				record, err := repo.Save("fake-cid", 1024, cloudProperties, nil)
 				Expect(err).ToNot(HaveOccurred())
 				recordID = record.ID
 			})
@@ -106,7 +106,7 @@ var _ = Describe("DiskRepo", func() {
 
 		Context("when a disk record does not exists with the same ID", func() {
 			BeforeEach(func() {
-				_, err := repo.Save("fake-cid", 1024, cloudProperties)
+				_, err := repo.Save("fake-cid", 1024, cloudProperties, nil)
 				Expect(err).ToNot(HaveOccurred())
 			})
 
@@ -124,10 +124,10 @@ var _ = Describe("DiskRepo", func() {
 				diskID2 string
 			)
 			BeforeEach(func() {
-				_, err := repo.Save("fake-cid-1", 1024, cloudProperties)
+				_, err := repo.Save("fake-cid-1", 1024, cloudProperties, nil)
 				Expect(err).ToNot(HaveOccurred())
 
-				record, err := repo.Save("fake-cid-2", 1024, cloudProperties)
+				record, err := repo.Save("fake-cid-2", 1024, cloudProperties, nil)
 				Expect(err).ToNot(HaveOccurred())
 				diskID2 = record.ID
 
@@ -149,7 +149,7 @@ var _ = Describe("DiskRepo", func() {
 
 		Context("when current disk does not exist", func() {
 			BeforeEach(func() {
-				_, err := repo.Save("fake-cid", 1024, cloudProperties)
+				_, err := repo.Save("fake-cid", 1024, cloudProperties, nil)
 				Expect(err).ToNot(HaveOccurred())
 			})
 
@@ -177,10 +177,10 @@ var _ = Describe("DiskRepo", func() {
 
 		BeforeEach(func() {
 			var err error
-			firstDisk, err = repo.Save("fake-cid-1", 1024, cloudProperties)
+			firstDisk, err = repo.Save("fake-cid-1", 1024, cloudProperties, nil)
 			Expect(err).ToNot(HaveOccurred())
 
-			secondDisk, err = repo.Save("fake-cid-2", 2048, cloudProperties)
+			secondDisk, err = repo.Save("fake-cid-2", 2048, cloudProperties, nil)
 			Expect(err).ToNot(HaveOccurred())
 		})
 
@@ -203,10 +203,10 @@ var _ = Describe("DiskRepo", func() {
 		BeforeEach(func() {
 			var err error
 
-			firstDisk, err = repo.Save("fake-cid-1", 1024, cloudProperties)
+			firstDisk, err = repo.Save("fake-cid-1", 1024, cloudProperties, nil)
 			Expect(err).ToNot(HaveOccurred())
 
-			secondDisk, err = repo.Save("fake-cid-2", 2048, cloudProperties)
+			secondDisk, err = repo.Save("fake-cid-2", 2048, cloudProperties, nil)
 			Expect(err).ToNot(HaveOccurred())
 		})
 
@@ -263,4 +263,54 @@ var _ = Describe("DiskRepo", func() {
 			Expect(found).To(BeFalse())
 		})
 	})
+
+	Describe("migration tracking", func() {
+		var record DiskRecord
+
+		BeforeEach(func() {
+			var err error
+			record, err = repo.Save("fake-cid", 1024, cloudProperties, nil)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("finds the disk recorded as migrating", func() {
+			err := repo.UpdateMigrating(record.ID)
+			Expect(err).ToNot(HaveOccurred())
+
+			migratingRecord, found, err := repo.FindMigrating()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(migratingRecord).To(Equal(record))
+		})
+
+		It("returns an error when the disk does not exist", func() {
+			err := repo.UpdateMigrating("fake-unknown-id")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Verifying disk record exists with id 'fake-unknown-id'"))
+		})
+
+		It("clears the migrating disk", func() {
+			err := repo.UpdateMigrating(record.ID)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = repo.ClearMigrating()
+			Expect(err).ToNot(HaveOccurred())
+
+			_, found, err := repo.FindMigrating()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+
+		It("clears the migrating disk when it is deleted", func() {
+			err := repo.UpdateMigrating(record.ID)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = repo.Delete(record)
+			Expect(err).ToNot(HaveOccurred())
+
+			deploymentState, err := deploymentStateService.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deploymentState.MigratingDiskID).To(BeEmpty())
+		})
+	})
 })
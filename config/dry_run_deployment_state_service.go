@@ -0,0 +1,64 @@
+package config
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	boshuuid "github.com/cloudfoundry/bosh-utils/uuid"
+)
+
+// dryRunDeploymentStateService reads the deployment state file
+// but keeps all changes in memory so that a dry run never writes it
+type dryRunDeploymentStateService struct {
+	*fileSystemDeploymentStateService
+
+	deploymentState *DeploymentState
+}
+
+func NewDryRunDeploymentStateService(fs boshsys.FileSystem, uuidGenerator boshuuid.Generator, logger boshlog.Logger, deploymentStatePath string) DeploymentStateService {
+	return &dryRunDeploymentStateService{
+		fileSystemDeploymentStateService: &fileSystemDeploymentStateService{
+			configPath:    deploymentStatePath,
+			fs:            fs,
+			uuidGenerator: uuidGenerator,
+			logger:        logger,
+			logTag:        "dryRunConfig",
+		},
+	}
+}
+
+func (s *dryRunDeploymentStateService) Load() (DeploymentState, error) {
+	if s.deploymentState != nil {
+		return *s.deploymentState, nil
+	}
+
+	deploymentState, err := s.read()
+	if err != nil {
+		return DeploymentState{}, err
+	}
+
+	if deploymentState.DirectorID == "" {
+		deploymentState.DirectorID, err = s.uuidGenerator.Generate()
+		if err != nil {
+			return DeploymentState{}, bosherr.WrapError(err, "Generating DirectorID")
+		}
+	}
+
+	s.deploymentState = &deploymentState
+
+	return deploymentState, nil
+}
+
+func (s *dryRunDeploymentStateService) Save(deploymentState DeploymentState) error {
+	s.logger.Debug(s.logTag, "Keeping deployment state in memory %#v", deploymentState)
+
+	s.deploymentState = &deploymentState
+
+	return nil
+}
+
+func (s *dryRunDeploymentStateService) Cleanup() error {
+	s.deploymentState = &DeploymentState{}
+
+	return nil
+}
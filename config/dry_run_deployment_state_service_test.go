@@ -0,0 +1,77 @@
+package config_test
+
+import (
+	. "github.com/cloudfoundry/bosh-cli/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+)
+
+var _ = Describe("dryRunDeploymentStateService", func() {
+	var (
+		service             DeploymentStateService
+		deploymentStatePath string
+		fakeFs              *fakesys.FakeFileSystem
+		fakeUUIDGenerator   *fakeuuid.FakeGenerator
+	)
+
+	BeforeEach(func() {
+		fakeFs = fakesys.NewFakeFileSystem()
+		deploymentStatePath = "/some/deployment.json"
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		fakeUUIDGenerator = fakeuuid.NewFakeGenerator()
+		fakeUUIDGenerator.GeneratedUUID = "fake-uuid-1"
+		service = NewDryRunDeploymentStateService(fakeFs, fakeUUIDGenerator, logger, deploymentStatePath)
+	})
+
+	Describe("Load", func() {
+		It("reads the existing state file", func() {
+			fakeFs.WriteFileString(deploymentStatePath, `{"director_id":"fake-director-id","current_vm_cid":"fake-vm-cid"}`)
+
+			deploymentState, err := service.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deploymentState.DirectorID).To(Equal("fake-director-id"))
+			Expect(deploymentState.CurrentVMCID).To(Equal("fake-vm-cid"))
+		})
+
+		It("generates a director id without creating the state file", func() {
+			deploymentState, err := service.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deploymentState.DirectorID).To(Equal("fake-uuid-1"))
+			Expect(fakeFs.FileExists(deploymentStatePath)).To(BeFalse())
+
+			fakeUUIDGenerator.GeneratedUUID = "fake-uuid-2"
+
+			deploymentState, err = service.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deploymentState.DirectorID).To(Equal("fake-uuid-1"))
+		})
+	})
+
+	Describe("Save", func() {
+		It("keeps the state in memory without writing the state file", func() {
+			fakeFs.WriteFileString(deploymentStatePath, `{"director_id":"fake-director-id"}`)
+
+			err := service.Save(DeploymentState{DirectorID: "fake-director-id", InstallationID: "fake-installation-id"})
+			Expect(err).ToNot(HaveOccurred())
+
+			deploymentState, err := service.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deploymentState.InstallationID).To(Equal("fake-installation-id"))
+
+			Expect(fakeFs.ReadFileString(deploymentStatePath)).To(Equal(`{"director_id":"fake-director-id"}`))
+		})
+	})
+
+	Describe("Cleanup", func() {
+		It("keeps the state file", func() {
+			fakeFs.WriteFileString(deploymentStatePath, `{"director_id":"fake-director-id"}`)
+
+			Expect(service.Cleanup()).To(Succeed())
+			Expect(fakeFs.FileExists(deploymentStatePath)).To(BeTrue())
+		})
+	})
+})
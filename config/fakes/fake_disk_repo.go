@@ -11,6 +11,10 @@ type FakeDiskRepo struct {
 
 	findCurrentOutput diskRepoFindCurrentOutput
 
+	UpdateMigratingInputs []DiskRepoUpdateCurrentInput
+	findMigratingOutput   diskRepoFindCurrentOutput
+	ClearMigratingCalled  bool
+
 	SaveInputs []DiskRepoSaveInput
 	saveOutput diskRepoSaveOutput
 
@@ -36,6 +40,7 @@ type DiskRepoSaveInput struct {
 	CID             string
 	Size            int
 	CloudProperties biproperty.Map
+	Tags            map[string]string
 }
 
 type diskRepoSaveOutput struct {
@@ -82,11 +87,28 @@ func (r *FakeDiskRepo) ClearCurrent() error {
 	return nil
 }
 
-func (r *FakeDiskRepo) Save(cid string, size int, cloudProperties biproperty.Map) (biconfig.DiskRecord, error) {
+func (r *FakeDiskRepo) UpdateMigrating(diskID string) error {
+	r.UpdateMigratingInputs = append(r.UpdateMigratingInputs, DiskRepoUpdateCurrentInput{
+		DiskID: diskID,
+	})
+	return r.updateErr
+}
+
+func (r *FakeDiskRepo) FindMigrating() (biconfig.DiskRecord, bool, error) {
+	return r.findMigratingOutput.diskRecord, r.findMigratingOutput.found, r.findMigratingOutput.err
+}
+
+func (r *FakeDiskRepo) ClearMigrating() error {
+	r.ClearMigratingCalled = true
+	return nil
+}
+
+func (r *FakeDiskRepo) Save(cid string, size int, cloudProperties biproperty.Map, tags map[string]string) (biconfig.DiskRecord, error) {
 	r.SaveInputs = append(r.SaveInputs, DiskRepoSaveInput{
 		CID:             cid,
 		Size:            size,
 		CloudProperties: cloudProperties,
+		Tags:            tags,
 	})
 
 	return r.saveOutput.diskRecord, r.saveOutput.err
@@ -120,6 +142,14 @@ func (r *FakeDiskRepo) SetFindCurrentBehavior(diskRecord biconfig.DiskRecord, fo
 	}
 }
 
+func (r *FakeDiskRepo) SetFindMigratingBehavior(diskRecord biconfig.DiskRecord, found bool, err error) {
+	r.findMigratingOutput = diskRepoFindCurrentOutput{
+		diskRecord: diskRecord,
+		found:      found,
+		err:        err,
+	}
+}
+
 func (r *FakeDiskRepo) SetSaveBehavior(diskRecord biconfig.DiskRecord, found bool, err error) {
 	r.saveOutput = diskRepoSaveOutput{
 		diskRecord: diskRecord,
@@ -52,9 +52,23 @@ func (s *fileSystemDeploymentStateService) Load() (DeploymentState, error) {
 		panic("configPath not yet set!")
 	}
 
+	deploymentState, err := s.read()
+	if err != nil {
+		return DeploymentState{}, err
+	}
+
+	err = s.initDefaults(&deploymentState)
+	if err != nil {
+		return DeploymentState{}, bosherr.WrapErrorf(err, "Initializing deployment state defaults")
+	}
+
+	return deploymentState, nil
+}
+
+func (s *fileSystemDeploymentStateService) read() (DeploymentState, error) {
 	s.logger.Debug(s.logTag, "Loading deployment state: %s", s.configPath)
 
-	deploymentState := &DeploymentState{}
+	deploymentState := DeploymentState{}
 
 	if s.fs.FileExists(s.configPath) {
 		deploymentStateFileContents, err := s.fs.ReadFile(s.configPath)
@@ -63,18 +77,13 @@ func (s *fileSystemDeploymentStateService) Load() (DeploymentState, error) {
 		}
 		s.logger.Debug(s.logTag, "Deployment File Contents %#s", deploymentStateFileContents)
 
-		err = json.Unmarshal(deploymentStateFileContents, deploymentState)
+		err = json.Unmarshal(deploymentStateFileContents, &deploymentState)
 		if err != nil {
 			return DeploymentState{}, bosherr.WrapErrorf(err, "Unmarshalling deployment state file '%s'", s.configPath)
 		}
 	}
 
-	err := s.initDefaults(deploymentState)
-	if err != nil {
-		return DeploymentState{}, bosherr.WrapErrorf(err, "Initializing deployment state defaults")
-	}
-
-	return *deploymentState, nil
+	return deploymentState, nil
 }
 
 func (s *fileSystemDeploymentStateService) Save(deploymentState DeploymentState) error {
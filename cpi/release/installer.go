@@ -64,6 +64,21 @@ func (i CpiInstaller) WithInstalledCpiRelease(installationManifest biinstallmani
 	return
 }
 
+func (i CpiInstaller) PruneCompiledPackages(installation biinstall.Installation, target biinstall.Target, stage biui.Stage) (int64, error) {
+	var reclaimed int64
+
+	err := stage.Perform("Pruning compiled packages", func() error {
+		var err error
+		reclaimed, err = i.InstallerFactory.NewInstaller(target).PruneCompiledPackages(installation)
+		return err
+	})
+	if err != nil {
+		return reclaimed, bosherr.WrapError(err, "Pruning compiled packages")
+	}
+
+	return reclaimed, nil
+}
+
 func (i CpiInstaller) cleanupInstall(installation biinstall.Installation, installer biinstall.Installer, stage biui.Stage) error {
 	return stage.Perform("Cleaning up rendered CPI jobs", func() error {
 		return installer.Cleanup(installation)
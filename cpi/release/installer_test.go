@@ -212,4 +212,57 @@ var _ = Describe("Installer", func() {
 			})
 		})
 	})
+
+	Describe("PruneCompiledPackages", func() {
+		var (
+			mockCtrl             *gomock.Controller
+			mockInstaller        *mocks.MockInstaller
+			mockInstallerFactory *mock_install.MockInstallerFactory
+			stage                *fakeui.FakeStage
+			installation         *mocks.MockInstallation
+			target               biinstallation.Target
+			cpiInstaller         release.CpiInstaller
+		)
+
+		BeforeEach(func() {
+			mockCtrl = gomock.NewController(GinkgoT())
+
+			mockInstaller = mocks.NewMockInstaller(mockCtrl)
+			mockInstallerFactory = mock_install.NewMockInstallerFactory(mockCtrl)
+			stage = fakeui.NewFakeStage()
+			installation = mocks.NewMockInstallation(mockCtrl)
+
+			target = biinstallation.NewTarget("fake-installation-path")
+			mockInstallerFactory.EXPECT().NewInstaller(target).Return(mockInstaller).AnyTimes()
+
+			cpiInstaller = release.CpiInstaller{
+				InstallerFactory: mockInstallerFactory,
+			}
+		})
+
+		AfterEach(func() {
+			mockCtrl.Finish()
+		})
+
+		It("prunes compiled packages of the installation in a stage and returns reclaimed bytes", func() {
+			mockInstaller.EXPECT().PruneCompiledPackages(installation).Return(int64(1024), nil)
+
+			reclaimed, err := cpiInstaller.PruneCompiledPackages(installation, target, stage)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reclaimed).To(Equal(int64(1024)))
+
+			Expect(stage.PerformCalls).To(Equal([]*fakeui.PerformCall{
+				{Name: "Pruning compiled packages"},
+			}))
+		})
+
+		It("returns the error", func() {
+			mockInstaller.EXPECT().PruneCompiledPackages(installation).Return(int64(0), errors.New("fake-prune-err"))
+
+			_, err := cpiInstaller.PruneCompiledPackages(installation, target, stage)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-prune-err"))
+		})
+	})
+
 })
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"time"
 
+	"code.cloudfoundry.org/clock"
+
 	. "github.com/cloudfoundry/bosh-cli/deployment"
 
 	mock_httpagent "github.com/cloudfoundry/bosh-agent/agentclient/http/mocks"
@@ -22,6 +24,7 @@ import (
 	bisshtunnel "github.com/cloudfoundry/bosh-cli/deployment/sshtunnel"
 	biinstallmanifest "github.com/cloudfoundry/bosh-cli/installation/manifest"
 	bistemcell "github.com/cloudfoundry/bosh-cli/stemcell"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	biproperty "github.com/cloudfoundry/bosh-utils/property"
@@ -146,14 +149,14 @@ var _ = Describe("Deployer", func() {
 		mockStateBuilder = mock_instance_state.NewMockBuilder(mockCtrl)
 		mockState = mock_instance_state.NewMockState(mockCtrl)
 
-		instanceFactory := biinstance.NewFactory(mockStateBuilderFactory)
+		instanceFactory := biinstance.NewFactory(mockStateBuilderFactory, 10*time.Minute)
 		instanceManagerFactory := biinstance.NewManagerFactory(fakeSSHTunnelFactory, instanceFactory, logger)
 
 		mockBlobstore = mock_blobstore.NewMockBlobstore(mockCtrl)
 
 		pingTimeout := 10 * time.Second
 		pingDelay := 500 * time.Millisecond
-		deploymentFactory := NewFactory(pingTimeout, pingDelay)
+		deploymentFactory := NewFactory(pingTimeout, pingDelay, clock.NewClock(), biwarn.NewWarnings(logger), logger)
 
 		deployer = NewDeployer(
 			mockVMManagerFactory,
@@ -293,7 +296,7 @@ var _ = Describe("Deployer", func() {
 
 			Expect(fakeStage.PerformCalls[1]).To(Equal(&fakebiui.PerformCall{
 				Name:  "Waiting for the agent on VM 'fake-vm-cid' to be ready",
-				Error: waitError,
+				Error: bosherr.WrapError(waitError, "Agent of instance 'fake-job-name/0' did not become ready within 10m0s"),
 			}))
 		})
 	})
@@ -340,9 +343,12 @@ var _ = Describe("Deployer", func() {
 		_, err := deployer.Deploy(cloud, deploymentManifest, cloudStemcell, registryConfig, fakeVMManager, mockBlobstore, fakeStage)
 		Expect(err).NotTo(HaveOccurred())
 
-		Expect(fakeStage.PerformCalls[2:4]).To(Equal([]*fakebiui.PerformCall{
+		Expect(fakeStage.PerformCalls[2:7]).To(Equal([]*fakebiui.PerformCall{
 			{Name: "Updating instance 'fake-job-name/0'"},
+			{Name: "Running the pre-start scripts 'fake-job-name/0'"},
+			{Name: "Starting jobs on instance 'fake-job-name/0'"},
 			{Name: "Waiting for instance 'fake-job-name/0' to be running"},
+			{Name: "Running the post-start scripts 'fake-job-name/0'"},
 		}))
 	})
 
@@ -368,9 +374,9 @@ var _ = Describe("Deployer", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("fake-start-error"))
 
-			Expect(fakeStage.PerformCalls[2].Name).To(Equal("Updating instance 'fake-job-name/0'"))
-			Expect(fakeStage.PerformCalls[2].Error).To(HaveOccurred())
-			Expect(fakeStage.PerformCalls[2].Error.Error()).To(Equal("Starting the agent: fake-start-error"))
+			Expect(fakeStage.PerformCalls[4].Name).To(Equal("Starting jobs on instance 'fake-job-name/0'"))
+			Expect(fakeStage.PerformCalls[4].Error).To(HaveOccurred())
+			Expect(fakeStage.PerformCalls[4].Error.Error()).To(Equal("Starting the agent: fake-start-error"))
 		})
 	})
 
@@ -388,7 +394,7 @@ var _ = Describe("Deployer", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("fake-wait-running-error"))
 
-			Expect(fakeStage.PerformCalls[3]).To(Equal(&fakebiui.PerformCall{
+			Expect(fakeStage.PerformCalls[5]).To(Equal(&fakebiui.PerformCall{
 				Name:  "Waiting for instance 'fake-job-name/0' to be running",
 				Error: waitError,
 			}))
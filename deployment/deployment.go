@@ -4,23 +4,39 @@ import (
 	"fmt"
 	"time"
 
+	"code.cloudfoundry.org/clock"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+
 	bicloud "github.com/cloudfoundry/bosh-cli/cloud"
 	bidisk "github.com/cloudfoundry/bosh-cli/deployment/disk"
 	biinstance "github.com/cloudfoundry/bosh-cli/deployment/instance"
+	biretry "github.com/cloudfoundry/bosh-cli/retry"
 	bistemcell "github.com/cloudfoundry/bosh-cli/stemcell"
 	biui "github.com/cloudfoundry/bosh-cli/ui"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 )
 
 type Deployment interface {
 	Delete(biui.Stage) error
+
+	// ForceDelete deletes like Delete but continues with the remaining resources when one cannot be deleted,
+	// raising a warning for each failure and returning all of them at the end
+	ForceDelete(biui.Stage) error
 }
 
+// stemcellDeleteRetryConfig gives the IaaS time to notice that the VM using a stemcell was deleted
+var stemcellDeleteRetryConfig = biretry.Config{Retries: 4, Delay: 10 * time.Second, MaxDelay: 10 * time.Second}
+
 type deployment struct {
 	instances   []biinstance.Instance
 	disks       []bidisk.Disk
 	stemcells   []bistemcell.CloudStemcell
 	pingTimeout time.Duration
 	pingDelay   time.Duration
+	timeService clock.Clock
+	warnings    biwarn.Warnings
+	logger      boshlog.Logger
 }
 
 func NewDeployment(
@@ -29,6 +45,9 @@ func NewDeployment(
 	stemcells []bistemcell.CloudStemcell,
 	pingTimeout time.Duration,
 	pingDelay time.Duration,
+	timeService clock.Clock,
+	warnings biwarn.Warnings,
+	logger boshlog.Logger,
 ) Deployment {
 	return &deployment{
 		instances:   instances,
@@ -36,17 +55,47 @@ func NewDeployment(
 		stemcells:   stemcells,
 		pingTimeout: pingTimeout,
 		pingDelay:   pingDelay,
+		timeService: timeService,
+		warnings:    warnings,
+		logger:      logger,
 	}
 }
 
 func (d *deployment) Delete(deleteStage biui.Stage) error {
+	return d.delete(deleteStage, func(err error) error { return err })
+}
+
+func (d *deployment) ForceDelete(deleteStage biui.Stage) error {
+	var errs []error
+
+	err := d.delete(deleteStage, func(err error) error {
+		d.warnings.Warn("delete", "Continuing after failure: %s", err.Error())
+		errs = append(errs, err)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return bosherr.NewMultiError(errs...)
+	}
+
+	return nil
+}
+
+// delete removes instances, then disks, then stemcells. A resource that fails to be deleted
+// stays in the deployment state; onFailure decides whether to stop by returning an error.
+func (d *deployment) delete(deleteStage biui.Stage, onFailure func(error) error) error {
 	// le sigh... consuming from an array sucks without generics
 	for len(d.instances) > 0 {
 		lastIdx := len(d.instances) - 1
 		instance := d.instances[lastIdx]
 
 		if err := instance.Delete(d.pingTimeout, d.pingDelay, deleteStage); err != nil {
-			return err
+			if err = onFailure(err); err != nil {
+				return err
+			}
 		}
 
 		d.instances = d.instances[:lastIdx]
@@ -57,7 +106,9 @@ func (d *deployment) Delete(deleteStage biui.Stage) error {
 		disk := d.disks[lastIdx]
 
 		if err := d.deleteDisk(deleteStage, disk); err != nil {
-			return err
+			if err = onFailure(err); err != nil {
+				return err
+			}
 		}
 
 		d.disks = d.disks[:lastIdx]
@@ -68,7 +119,9 @@ func (d *deployment) Delete(deleteStage biui.Stage) error {
 		stemcell := d.stemcells[lastIdx]
 
 		if err := d.deleteStemcell(deleteStage, stemcell); err != nil {
-			return err
+			if err = onFailure(err); err != nil {
+				return err
+			}
 		}
 
 		d.stemcells = d.stemcells[:lastIdx]
@@ -89,14 +142,29 @@ func (d *deployment) deleteDisk(deleteStage biui.Stage, disk bidisk.Disk) error
 	})
 }
 
+// deleteStemcell retries deletion while the CPI reports that it may succeed later, e.g. while
+// the IaaS considers the stemcell in use since it lags behind the deletion of the VM using it.
+// Retries are shown as stage steps.
 func (d *deployment) deleteStemcell(deleteStage biui.Stage, stemcell bistemcell.CloudStemcell) error {
-	stepName := fmt.Sprintf("Deleting stemcell '%s'", stemcell.CID())
-	return deleteStage.Perform(stepName, func() error {
-		err := stemcell.Delete()
-		cloudErr, ok := err.(bicloud.Error)
-		if ok && cloudErr.Type() == bicloud.StemcellNotFoundError {
-			return biui.NewSkipStageError(cloudErr, "Stemcell not found")
+	retrier := biretry.NewRetrier(stemcellDeleteRetryConfig, d.timeService, nil, d.logger)
+	attempts := stemcellDeleteRetryConfig.Retries + 1
+	attempt := 0
+
+	return retrier.Retry(fmt.Sprintf("delete of stemcell '%s'", stemcell.CID()), bicloud.IsRetryable, func() error {
+		attempt++
+
+		stepName := fmt.Sprintf("Deleting stemcell '%s'", stemcell.CID())
+		if attempt > 1 {
+			stepName = fmt.Sprintf("Retrying stemcell delete '%s' (attempt %d/%d)", stemcell.CID(), attempt, attempts)
 		}
-		return err
+
+		return deleteStage.Perform(stepName, func() error {
+			err := stemcell.Delete()
+			cloudErr, ok := err.(bicloud.Error)
+			if ok && cloudErr.Type() == bicloud.StemcellNotFoundError {
+				return biui.NewSkipStageError(cloudErr, "Stemcell not found")
+			}
+			return err
+		})
 	})
 }
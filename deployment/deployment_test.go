@@ -5,6 +5,7 @@ import (
 
 	"time"
 
+	"code.cloudfoundry.org/clock/fakeclock"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -22,6 +23,7 @@ import (
 	bisshtunnel "github.com/cloudfoundry/bosh-cli/deployment/sshtunnel"
 	bivm "github.com/cloudfoundry/bosh-cli/deployment/vm"
 	bistemcell "github.com/cloudfoundry/bosh-cli/stemcell"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
@@ -64,6 +66,8 @@ var _ = Describe("Deployment", func() {
 			mockBlobstore *mock_blobstore.MockBlobstore
 
 			fakeStage *fakebiui.FakeStage
+			fakeClock *fakeclock.FakeClock
+			warnings  biwarn.Warnings
 
 			deploymentFactory Factory
 
@@ -126,10 +130,12 @@ var _ = Describe("Deployment", func() {
 			mockAgentClient = mock_agentclient.NewMockAgentClient(mockCtrl)
 
 			fakeStage = fakebiui.NewFakeStage()
+			fakeClock = fakeclock.NewFakeClock(time.Now())
+			warnings = biwarn.NewWarnings(logger)
 
 			pingTimeout := 10 * time.Second
 			pingDelay := 500 * time.Millisecond
-			deploymentFactory = NewFactory(pingTimeout, pingDelay)
+			deploymentFactory = NewFactory(pingTimeout, pingDelay, fakeClock, warnings, logger)
 		})
 
 		JustBeforeEach(func() {
@@ -137,14 +143,14 @@ var _ = Describe("Deployment", func() {
 			diskManagerFactory := bidisk.NewManagerFactory(diskRepo, logger)
 			diskDeployer := bivm.NewDiskDeployer(diskManagerFactory, diskRepo, logger, false)
 
-			vmManagerFactory := bivm.NewManagerFactory(vmRepo, stemcellRepo, diskDeployer, fakeUUIDGenerator, fs, logger)
+			vmManagerFactory := bivm.NewManagerFactory(vmRepo, stemcellRepo, diskDeployer, fakeUUIDGenerator, fs, biwarn.NewWarnings(logger), logger)
 			sshTunnelFactory := bisshtunnel.NewFactory(logger)
 
 			mockStateBuilderFactory = mock_instance_state.NewMockBuilderFactory(mockCtrl)
 			mockStateBuilder = mock_instance_state.NewMockBuilder(mockCtrl)
 			mockState = mock_instance_state.NewMockState(mockCtrl)
 
-			instanceFactory := biinstance.NewFactory(mockStateBuilderFactory)
+			instanceFactory := biinstance.NewFactory(mockStateBuilderFactory, 10*time.Minute)
 			instanceManagerFactory := biinstance.NewManagerFactory(sshTunnelFactory, instanceFactory, logger)
 			stemcellManagerFactory := bistemcell.NewManagerFactory(stemcellRepo)
 
@@ -239,7 +245,7 @@ var _ = Describe("Deployment", func() {
 					// reduce timout & delay to reduce test duration
 					pingTimeout := 1 * time.Second
 					pingDelay := 100 * time.Millisecond
-					deploymentFactory = NewFactory(pingTimeout, pingDelay)
+					deploymentFactory = NewFactory(pingTimeout, pingDelay, fakeClock, warnings, logger)
 				})
 
 				It("times out pinging agent, deletes vm, deletes disk, deletes stemcell", func() {
@@ -256,6 +262,55 @@ var _ = Describe("Deployment", func() {
 				})
 			})
 
+			Context("when forced", func() {
+				It("continues deleting disks and stemcells when the VM cannot be deleted", func() {
+					gomock.InOrder(
+						mockCloud.EXPECT().HasVM("fake-vm-cid").Return(false, bosherr.Error("fake-has-vm-error")),
+						mockCloud.EXPECT().DeleteDisk("fake-disk-cid"),
+						mockCloud.EXPECT().DeleteStemcell("fake-stemcell-cid"),
+					)
+
+					err := deployment.ForceDelete(fakeStage)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("fake-has-vm-error"))
+
+					Expect(warnings.List()).To(HaveLen(1))
+					Expect(warnings.List()[0].Source).To(Equal("delete"))
+					Expect(warnings.List()[0].Message).To(ContainSubstring("Continuing after failure: Checking existence of vm for instance 'unknown/0'"))
+				})
+
+				It("keeps what could not be deleted in the deployment state", func() {
+					gomock.InOrder(
+						mockCloud.EXPECT().HasVM("fake-vm-cid").Return(false, bosherr.Error("fake-has-vm-error")),
+						mockCloud.EXPECT().DeleteDisk("fake-disk-cid"),
+						mockCloud.EXPECT().DeleteStemcell("fake-stemcell-cid"),
+					)
+
+					err := deployment.ForceDelete(fakeStage)
+					Expect(err).To(HaveOccurred())
+
+					_, found, err := vmRepo.FindCurrent()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(found).To(BeTrue(), "should still be a current VM")
+
+					diskRecords, err := diskRepo.All()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(diskRecords).To(BeEmpty(), "expected no disk records")
+
+					stemcellRecords, err := stemcellRepo.All()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(stemcellRecords).To(BeEmpty(), "expected no stemcell records")
+				})
+
+				It("does not warn when everything is deleted", func() {
+					expectNormalFlow()
+
+					err := deployment.ForceDelete(fakeStage)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(warnings.List()).To(BeEmpty())
+				})
+			})
+
 			Context("and delete previously suceeded", func() {
 				JustBeforeEach(func() {
 					expectNormalFlow()
@@ -347,7 +402,7 @@ var _ = Describe("Deployment", func() {
 		Context("when a current disk exists", func() {
 			BeforeEach(func() {
 				deploymentStateService.Save(biconfig.DeploymentState{})
-				diskRecord, err := diskRepo.Save("fake-disk-cid", 100, nil)
+				diskRecord, err := diskRepo.Save("fake-disk-cid", 100, nil, nil)
 				Expect(err).ToNot(HaveOccurred())
 				diskRepo.UpdateCurrent(diskRecord.ID)
 			})
@@ -412,6 +467,80 @@ var _ = Describe("Deployment", func() {
 					Expect(err).ToNot(HaveOccurred())
 				})
 			})
+
+			Context("when the stemcell is still in use", func() {
+				var inUseErr = bicloud.NewCPIError("delete_stemcell", bicloud.CmdError{
+					Type:      "Bosh::Clouds::CloudError",
+					Message:   "Image is still in use by an instance",
+					OkToRetry: true,
+				})
+
+				It("retries deleting the stemcell and reports the retries", func() {
+					gomock.InOrder(
+						mockCloud.EXPECT().DeleteStemcell("fake-stemcell-cid").Return(inUseErr),
+						mockCloud.EXPECT().DeleteStemcell("fake-stemcell-cid").Return(inUseErr),
+						mockCloud.EXPECT().DeleteStemcell("fake-stemcell-cid").Return(nil),
+					)
+
+					errCh := make(chan error)
+					go func() { errCh <- deployment.Delete(fakeStage) }()
+
+					fakeClock.WaitForWatcherAndIncrement(10 * time.Second)
+					fakeClock.WaitForWatcherAndIncrement(10 * time.Second)
+					Eventually(errCh).Should(Receive(BeNil()))
+
+					Expect(fakeStage.PerformCalls).To(HaveLen(3))
+					Expect(fakeStage.PerformCalls[0].Name).To(Equal("Deleting stemcell 'fake-stemcell-cid'"))
+					Expect(fakeStage.PerformCalls[0].Error).To(HaveOccurred())
+					Expect(fakeStage.PerformCalls[1].Name).To(Equal("Retrying stemcell delete 'fake-stemcell-cid' (attempt 2/5)"))
+					Expect(fakeStage.PerformCalls[1].Error).To(HaveOccurred())
+					Expect(fakeStage.PerformCalls[2].Name).To(Equal("Retrying stemcell delete 'fake-stemcell-cid' (attempt 3/5)"))
+					Expect(fakeStage.PerformCalls[2].Error).ToNot(HaveOccurred())
+				})
+
+				It("gives up after the maximum number of attempts", func() {
+					mockCloud.EXPECT().DeleteStemcell("fake-stemcell-cid").Return(inUseErr).Times(5)
+
+					errCh := make(chan error)
+					go func() { errCh <- deployment.Delete(fakeStage) }()
+
+					for i := 0; i < 4; i++ {
+						fakeClock.WaitForWatcherAndIncrement(10 * time.Second)
+					}
+					Eventually(errCh).Should(Receive(MatchError(ContainSubstring("Image is still in use by an instance"))))
+				})
+			})
+
+			It("retries when the CPI reports the error as ok to retry", func() {
+				retryableErr := bicloud.NewCPIError("delete_stemcell", bicloud.CmdError{
+					Type:      "Bosh::Clouds::CloudError",
+					Message:   "fake-transient-error",
+					OkToRetry: true,
+				})
+				gomock.InOrder(
+					mockCloud.EXPECT().DeleteStemcell("fake-stemcell-cid").Return(retryableErr),
+					mockCloud.EXPECT().DeleteStemcell("fake-stemcell-cid").Return(nil),
+				)
+
+				errCh := make(chan error)
+				go func() { errCh <- deployment.Delete(fakeStage) }()
+
+				fakeClock.WaitForWatcherAndIncrement(10 * time.Second)
+				Eventually(errCh).Should(Receive(BeNil()))
+			})
+
+			It("fails immediately on errors that are not retryable", func() {
+				fatalErr := bicloud.NewCPIError("delete_stemcell", bicloud.CmdError{
+					Type:    "Bosh::Clouds::CloudError",
+					Message: "fake-fatal-error",
+				})
+				mockCloud.EXPECT().DeleteStemcell("fake-stemcell-cid").Return(fatalErr)
+
+				err := deployment.Delete(fakeStage)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-fatal-error"))
+				Expect(fakeStage.PerformCalls).To(HaveLen(1))
+			})
 		})
 	})
 })
@@ -94,7 +94,7 @@ var _ = Describe("Disk", func() {
 		})
 
 		It("deletes disk from repo", func() {
-			_, err := diskRepo.Save("fake-disk-cid", 1024, diskCloudProperties)
+			_, err := diskRepo.Save("fake-disk-cid", 1024, diskCloudProperties, nil)
 			Expect(err).ToNot(HaveOccurred())
 
 			err = disk.Delete()
@@ -105,7 +105,7 @@ var _ = Describe("Disk", func() {
 
 		Context("when deleted disk is the current disk", func() {
 			BeforeEach(func() {
-				diskRecord, err := diskRepo.Save("fake-disk-cid", 1024, diskCloudProperties)
+				diskRecord, err := diskRepo.Save("fake-disk-cid", 1024, diskCloudProperties, nil)
 				Expect(err).ToNot(HaveOccurred())
 
 				err = diskRepo.UpdateCurrent(diskRecord.ID)
@@ -141,7 +141,7 @@ var _ = Describe("Disk", func() {
 			})
 
 			BeforeEach(func() {
-				diskRecord, err := diskRepo.Save("fake-disk-cid", 1024, diskCloudProperties)
+				diskRecord, err := diskRepo.Save("fake-disk-cid", 1024, diskCloudProperties, nil)
 				Expect(err).ToNot(HaveOccurred())
 
 				err = diskRepo.UpdateCurrent(diskRecord.ID)
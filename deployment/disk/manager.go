@@ -55,7 +55,8 @@ func (m *manager) FindCurrent() ([]Disk, error) {
 }
 
 func (m *manager) Create(diskPool bideplmanifest.DiskPool, vmCID string) (Disk, error) {
-	diskCloudProperties := diskPool.CloudProperties
+	// Tags are recorded apart from the cloud properties so that changing them does not migrate the disk
+	diskCloudProperties := bicloud.CloudPropertiesWithTags(diskPool.CloudProperties, diskPool.Tags)
 
 	m.logger.Debug(m.logTag, "Creating disk")
 	cid, err := m.cloud.CreateDisk(diskPool.DiskSize, diskCloudProperties, vmCID)
@@ -67,7 +68,7 @@ func (m *manager) Create(diskPool bideplmanifest.DiskPool, vmCID string) (Disk,
 			)
 	}
 
-	diskRecord, err := m.diskRepo.Save(cid, diskPool.DiskSize, diskCloudProperties)
+	diskRecord, err := m.diskRepo.Save(cid, diskPool.DiskSize, diskPool.CloudProperties, diskPool.Tags)
 	if err != nil {
 		return nil, bosherr.WrapError(err, "Saving deployment disk record")
 	}
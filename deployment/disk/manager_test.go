@@ -82,6 +82,34 @@ var _ = Describe("Manager", func() {
 					},
 				}))
 			})
+
+			Context("when the disk pool has tags", func() {
+				BeforeEach(func() {
+					diskPool.Tags = map[string]string{"owner": "fake-owner"}
+				})
+
+				It("creates the disk with the tags in its cloud properties", func() {
+					_, err := manager.Create(diskPool, "fake-vm-cid")
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(fakeCloud.CreateDiskInput.CloudProperties).To(Equal(biproperty.Map{
+						"fake-cloud-property-key": "fake-cloud-property-value",
+						"tags":                    biproperty.Map{"owner": "fake-owner"},
+					}))
+				})
+
+				It("records the tags apart from the cloud properties", func() {
+					_, err := manager.Create(diskPool, "fake-vm-cid")
+					Expect(err).ToNot(HaveOccurred())
+
+					diskRecord, _, err := diskRepo.Find("fake-disk-cid")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(diskRecord.CloudProperties).To(Equal(biproperty.Map{
+						"fake-cloud-property-key": "fake-cloud-property-value",
+					}))
+					Expect(diskRecord.Tags).To(Equal(map[string]string{"owner": "fake-owner"}))
+				})
+			})
 		})
 
 		Context("when creating disk fails", func() {
@@ -112,7 +140,7 @@ var _ = Describe("Manager", func() {
 	Describe("FindCurrent", func() {
 		Context("when disk already exists in disk repo", func() {
 			BeforeEach(func() {
-				diskRecord, err := diskRepo.Save("fake-existing-disk-cid", 1024, biproperty.Map{})
+				diskRecord, err := diskRepo.Save("fake-existing-disk-cid", 1024, biproperty.Map{}, nil)
 				Expect(err).ToNot(HaveOccurred())
 
 				err = diskRepo.UpdateCurrent(diskRecord.ID)
@@ -157,18 +185,18 @@ var _ = Describe("Manager", func() {
 
 		BeforeEach(func() {
 			fakeUUIDGenerator.GeneratedUUID = "fake-guid-1"
-			firstDiskRecord, err := diskRepo.Save("fake-disk-cid-1", 1024, biproperty.Map{})
+			firstDiskRecord, err := diskRepo.Save("fake-disk-cid-1", 1024, biproperty.Map{}, nil)
 			Expect(err).ToNot(HaveOccurred())
 			firstDisk = NewDisk(firstDiskRecord, fakeCloud, diskRepo)
 
 			fakeUUIDGenerator.GeneratedUUID = "fake-guid-2"
-			_, err = diskRepo.Save("fake-disk-cid-2", 1024, biproperty.Map{})
+			_, err = diskRepo.Save("fake-disk-cid-2", 1024, biproperty.Map{}, nil)
 			Expect(err).ToNot(HaveOccurred())
 			err = diskRepo.UpdateCurrent("fake-guid-2")
 			Expect(err).ToNot(HaveOccurred())
 
 			fakeUUIDGenerator.GeneratedUUID = "fake-guid-3"
-			thirdDiskRecord, err := diskRepo.Save("fake-disk-cid-3", 1024, biproperty.Map{})
+			thirdDiskRecord, err := diskRepo.Save("fake-disk-cid-3", 1024, biproperty.Map{}, nil)
 			Expect(err).ToNot(HaveOccurred())
 			thirdDisk = NewDisk(thirdDiskRecord, fakeCloud, diskRepo)
 		})
@@ -193,17 +221,17 @@ var _ = Describe("Manager", func() {
 			fakeStage = fakebiui.NewFakeStage()
 
 			fakeUUIDGenerator.GeneratedUUID = "fake-disk-id-1"
-			_, err := diskRepo.Save("fake-disk-cid-1", 100, nil)
+			_, err := diskRepo.Save("fake-disk-cid-1", 100, nil, nil)
 			Expect(err).ToNot(HaveOccurred())
 
 			fakeUUIDGenerator.GeneratedUUID = "fake-disk-id-2"
-			secondDiskRecord, err = diskRepo.Save("fake-disk-cid-2", 100, nil)
+			secondDiskRecord, err = diskRepo.Save("fake-disk-cid-2", 100, nil, nil)
 			Expect(err).ToNot(HaveOccurred())
 			err = diskRepo.UpdateCurrent(secondDiskRecord.ID)
 			Expect(err).ToNot(HaveOccurred())
 
 			fakeUUIDGenerator.GeneratedUUID = "fake-disk-id-3"
-			_, err = diskRepo.Save("fake-disk-cid-3", 100, nil)
+			_, err = diskRepo.Save("fake-disk-cid-3", 100, nil, nil)
 			Expect(err).ToNot(HaveOccurred())
 		})
 
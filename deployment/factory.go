@@ -3,9 +3,13 @@ package deployment
 import (
 	"time"
 
+	"code.cloudfoundry.org/clock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+
 	bidisk "github.com/cloudfoundry/bosh-cli/deployment/disk"
 	biinstance "github.com/cloudfoundry/bosh-cli/deployment/instance"
 	bistemcell "github.com/cloudfoundry/bosh-cli/stemcell"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 )
 
 type Factory interface {
@@ -19,15 +23,24 @@ type Factory interface {
 type factory struct {
 	pingTimeout time.Duration
 	pingDelay   time.Duration
+	timeService clock.Clock
+	warnings    biwarn.Warnings
+	logger      boshlog.Logger
 }
 
 func NewFactory(
 	pingTimeout time.Duration,
 	pingDelay time.Duration,
+	timeService clock.Clock,
+	warnings biwarn.Warnings,
+	logger boshlog.Logger,
 ) Factory {
 	return &factory{
 		pingTimeout: pingTimeout,
 		pingDelay:   pingDelay,
+		timeService: timeService,
+		warnings:    warnings,
+		logger:      logger,
 	}
 }
 
@@ -42,5 +55,8 @@ func (f *factory) NewDeployment(
 		stemcells,
 		f.pingTimeout,
 		f.pingDelay,
+		f.timeService,
+		f.warnings,
+		f.logger,
 	)
 }
@@ -1,6 +1,8 @@
 package instance
 
 import (
+	"time"
+
 	biblobstore "github.com/cloudfoundry/bosh-cli/blobstore"
 	biinstancestate "github.com/cloudfoundry/bosh-cli/deployment/instance/state"
 	bisshtunnel "github.com/cloudfoundry/bosh-cli/deployment/sshtunnel"
@@ -22,13 +24,17 @@ type Factory interface {
 
 type factory struct {
 	stateBuilderFactory biinstancestate.BuilderFactory
+	readyTimeout        time.Duration
 }
 
+// NewFactory creates instances that wait up to readyTimeout for their agent to be ready
 func NewFactory(
 	stateBuilderFactory biinstancestate.BuilderFactory,
+	readyTimeout time.Duration,
 ) Factory {
 	return &factory{
 		stateBuilderFactory: stateBuilderFactory,
+		readyTimeout:        readyTimeout,
 	}
 }
 
@@ -50,6 +56,7 @@ func (f *factory) NewInstance(
 		vmManager,
 		sshTunnelFactory,
 		stateBuilder,
+		f.readyTimeout,
 		logger,
 	)
 }
@@ -37,6 +37,7 @@ type instance struct {
 	vmManager        bivm.Manager
 	sshTunnelFactory bisshtunnel.Factory
 	stateBuilder     biinstancestate.Builder
+	readyTimeout     time.Duration
 	logger           boshlog.Logger
 	logTag           string
 }
@@ -48,6 +49,7 @@ func NewInstance(
 	vmManager bivm.Manager,
 	sshTunnelFactory bisshtunnel.Factory,
 	stateBuilder biinstancestate.Builder,
+	readyTimeout time.Duration,
 	logger boshlog.Logger,
 ) Instance {
 	return &instance{
@@ -57,6 +59,7 @@ func NewInstance(
 		vmManager:        vmManager,
 		sshTunnelFactory: sshTunnelFactory,
 		stateBuilder:     stateBuilder,
+		readyTimeout:     readyTimeout,
 		logger:           logger,
 		logTag:           "instance",
 	}
@@ -84,7 +87,7 @@ func (i *instance) WaitUntilReady(
 ) error {
 	stepName := fmt.Sprintf("Waiting for the agent on VM '%s' to be ready", i.vm.CID())
 	err := stage.Perform(stepName, func() error {
-		if !registryConfig.IsEmpty() {
+		if registryConfig.HasSSHTunnel() {
 			sshReadyErrCh := make(chan error)
 			sshErrCh := make(chan error)
 
@@ -95,7 +98,13 @@ func (i *instance) WaitUntilReady(
 				Password:          registryConfig.SSHTunnel.Password,
 				PrivateKey:        registryConfig.SSHTunnel.PrivateKey,
 				LocalForwardPort:  registryConfig.Port,
-				RemoteForwardPort: registryConfig.Port,
+				RemoteForwardPort: registryConfig.AgentPort(),
+				Bastion: bisshtunnel.BastionOptions{
+					Host:       registryConfig.SSHTunnel.Bastion.Host,
+					Port:       registryConfig.SSHTunnel.Bastion.Port,
+					User:       registryConfig.SSHTunnel.Bastion.User,
+					PrivateKey: registryConfig.SSHTunnel.Bastion.PrivateKey,
+				},
 			}
 			sshTunnel := i.sshTunnelFactory.NewSSHTunnel(sshTunnelOptions)
 			go sshTunnel.Start(sshReadyErrCh, sshErrCh)
@@ -112,7 +121,12 @@ func (i *instance) WaitUntilReady(
 			}
 		}
 
-		return i.vm.WaitUntilReady(10*time.Minute, 500*time.Millisecond)
+		err := i.vm.WaitUntilReady(i.readyTimeout, 500*time.Millisecond)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Agent of instance '%s/%d' did not become ready within %s", i.jobName, i.id, i.readyTimeout)
+		}
+
+		return nil
 	})
 
 	return err
@@ -169,11 +183,20 @@ func (i *instance) UpdateJobs(
 			return bosherr.WrapError(err, "Applying the agent state")
 		}
 
-		err = i.vm.RunScript("pre-start", map[string]interface{}{})
-		if err != nil {
-			return bosherr.WrapError(err, "Running the pre-start script")
-		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
+	// Jobs must not be started before their pre-start scripts have succeeded
+	err = i.runScript("pre-start", stage)
+	if err != nil {
+		return err
+	}
+
+	stepName = fmt.Sprintf("Starting jobs on instance '%s/%d'", i.jobName, i.id)
+	err = stage.Perform(stepName, func() error {
 		err = i.vm.Start()
 		if err != nil {
 			return bosherr.WrapError(err, "Starting the agent")
@@ -190,16 +213,21 @@ func (i *instance) UpdateJobs(
 		return err
 	}
 
-	stepName = fmt.Sprintf("Running the post-start scripts '%s/%d'", i.jobName, i.id)
-	err = stage.Perform(stepName, func() error {
-		err = i.vm.RunScript("post-start", map[string]interface{}{})
+	return i.runScript("post-start", stage)
+}
+
+// runScript waits for the agent to run the script of every job that has one.
+// The agent reports which jobs failed and keeps the script output in
+// /var/vcap/sys/log/<job>/<script>.std{out,err}.log on the VM.
+func (i *instance) runScript(script string, stage biui.Stage) error {
+	stepName := fmt.Sprintf("Running the %s scripts '%s/%d'", script, i.jobName, i.id)
+	return stage.Perform(stepName, func() error {
+		err := i.vm.RunScript(script, map[string]interface{}{})
 		if err != nil {
-			return bosherr.WrapError(err, "Running the post-start script")
+			return bosherr.WrapErrorf(err, "Running the %s script", script)
 		}
 		return nil
 	})
-
-	return err
 }
 
 func (i *instance) Delete(
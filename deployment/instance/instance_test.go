@@ -80,6 +80,7 @@ var _ = Describe("Instance", func() {
 			fakeVMManager,
 			fakeSSHTunnelFactory,
 			mockStateBuilder,
+			10*time.Minute,
 			logger,
 		)
 
@@ -342,6 +343,8 @@ var _ = Describe("Instance", func() {
 
 			Expect(fakeStage.PerformCalls).To(Equal([]*fakebiui.PerformCall{
 				{Name: "Updating instance 'fake-job-name/0'"},
+				{Name: "Running the pre-start scripts 'fake-job-name/0'"},
+				{Name: "Starting jobs on instance 'fake-job-name/0'"},
 				{Name: "Waiting for instance 'fake-job-name/0' to be running"},
 				{Name: "Running the post-start scripts 'fake-job-name/0'"},
 			}))
@@ -392,10 +395,15 @@ var _ = Describe("Instance", func() {
 				fakeVM.RunScriptErrors["pre-start"] = bosherr.Error("fake-run-script-error")
 			})
 
-			It("returns the error", func() {
+			It("returns the error without starting jobs", func() {
 				err := instance.UpdateJobs(deploymentManifest, fakeStage)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("fake-run-script-error"))
+
+				Expect(fakeStage.PerformCalls[1].Name).To(Equal("Running the pre-start scripts 'fake-job-name/0'"))
+				Expect(fakeStage.PerformCalls[1].Error.Error()).To(Equal("Running the pre-start script: fake-run-script-error"))
+				Expect(fakeStage.PerformCalls).To(HaveLen(2))
+				Expect(fakeVM.StartCalled).To(Equal(0))
 			})
 		})
 
@@ -408,6 +416,9 @@ var _ = Describe("Instance", func() {
 				err := instance.UpdateJobs(deploymentManifest, fakeStage)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("fake-run-script-error-poststart"))
+
+				Expect(fakeStage.PerformCalls[4].Name).To(Equal("Running the post-start scripts 'fake-job-name/0'"))
+				Expect(fakeStage.PerformCalls[4].Error.Error()).To(Equal("Running the post-start script: fake-run-script-error-poststart"))
 			})
 		})
 
@@ -421,9 +432,9 @@ var _ = Describe("Instance", func() {
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("fake-start-error"))
 
-				Expect(fakeStage.PerformCalls[0].Name).To(Equal("Updating instance 'fake-job-name/0'"))
-				Expect(fakeStage.PerformCalls[0].Error).To(HaveOccurred())
-				Expect(fakeStage.PerformCalls[0].Error.Error()).To(Equal("Starting the agent: fake-start-error"))
+				Expect(fakeStage.PerformCalls[2].Name).To(Equal("Starting jobs on instance 'fake-job-name/0'"))
+				Expect(fakeStage.PerformCalls[2].Error).To(HaveOccurred())
+				Expect(fakeStage.PerformCalls[2].Error.Error()).To(Equal("Starting the agent: fake-start-error"))
 			})
 		})
 
@@ -443,6 +454,8 @@ var _ = Describe("Instance", func() {
 
 				Expect(fakeStage.PerformCalls).To(Equal([]*fakebiui.PerformCall{
 					{Name: "Updating instance 'fake-job-name/0'"},
+					{Name: "Running the pre-start scripts 'fake-job-name/0'"},
+					{Name: "Starting jobs on instance 'fake-job-name/0'"},
 					{
 						Name:  "Waiting for instance 'fake-job-name/0' to be running",
 						Error: waitError,
@@ -495,6 +508,14 @@ var _ = Describe("Instance", func() {
 				}))
 			})
 
+			It("names the instance when the agent does not become ready in time", func() {
+				fakeVM.WaitUntilReadyErr = bosherr.Error("fake-wait-error")
+
+				err := instance.WaitUntilReady(registryConfig, fakeStage)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Agent of instance 'fake-job-name/0' did not become ready within 10m0s: fake-wait-error"))
+			})
+
 			It("logs start and stop events to the eventLogger", func() {
 				err := instance.WaitUntilReady(registryConfig, fakeStage)
 				Expect(err).NotTo(HaveOccurred())
@@ -528,6 +549,60 @@ var _ = Describe("Instance", func() {
 				})
 			})
 
+			Context("when the registry is advertised at a different port", func() {
+				BeforeEach(func() {
+					registryConfig.AdvertisedHost = "127.0.0.1"
+					registryConfig.AdvertisedPort = 126
+				})
+
+				It("forwards the advertised port on the VM to the registry", func() {
+					err := instance.WaitUntilReady(registryConfig, fakeStage)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fakeSSHTunnelFactory.NewSSHTunnelOptions.LocalForwardPort).To(Equal(125))
+					Expect(fakeSSHTunnelFactory.NewSSHTunnelOptions.RemoteForwardPort).To(Equal(126))
+				})
+			})
+
+			Context("when the SSH tunnel goes through a bastion", func() {
+				BeforeEach(func() {
+					registryConfig.SSHTunnel.Bastion = biinstallmanifest.SSHBastion{
+						Host:       "fake-bastion-host",
+						Port:       2222,
+						User:       "fake-bastion-username",
+						PrivateKey: "fake-bastion-private-key",
+					}
+				})
+
+				It("starts the SSH tunnel through the bastion", func() {
+					err := instance.WaitUntilReady(registryConfig, fakeStage)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fakeSSHTunnelFactory.NewSSHTunnelOptions.Bastion).To(Equal(bisshtunnel.BastionOptions{
+						Host:       "fake-bastion-host",
+						Port:       2222,
+						User:       "fake-bastion-username",
+						PrivateKey: "fake-bastion-private-key",
+					}))
+					Expect(fakeSSHTunnel.Started).To(BeTrue())
+				})
+			})
+
+			Context("when the registry is reached without an SSH tunnel", func() {
+				BeforeEach(func() {
+					registryConfig = biinstallmanifest.Registry{
+						Host:           "0.0.0.0",
+						Port:           125,
+						AdvertisedHost: "203.0.113.7",
+						AdvertisedPort: 125,
+					}
+				})
+
+				It("does not start ssh tunnel", func() {
+					err := instance.WaitUntilReady(registryConfig, fakeStage)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(fakeSSHTunnel.Started).To(BeFalse())
+				})
+			})
+
 			Context("when starting SSH tunnel fails", func() {
 				BeforeEach(func() {
 					fakeSSHTunnel.SetStartBehavior(bosherr.Error("fake-ssh-tunnel-start-error"), nil)
@@ -556,7 +631,7 @@ var _ = Describe("Instance", func() {
 					Expect(fakeStage.PerformCalls).To(Equal([]*fakebiui.PerformCall{
 						{
 							Name:  "Waiting for the agent on VM 'fake-vm-cid' to be ready",
-							Error: waitError,
+							Error: bosherr.WrapError(waitError, "Agent of instance 'fake-job-name/0' did not become ready within 10m0s"),
 						},
 					}))
 				})
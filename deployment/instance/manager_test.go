@@ -75,7 +75,7 @@ var _ = Describe("Manager", func() {
 		mockStateBuilder = mock_instance_state.NewMockBuilder(mockCtrl)
 		mockState = mock_instance_state.NewMockState(mockCtrl)
 
-		instanceFactory = NewFactory(mockStateBuilderFactory)
+		instanceFactory = NewFactory(mockStateBuilderFactory, 10*time.Minute)
 
 		mockBlobstore = mock_blobstore.NewMockBlobstore(mockCtrl)
 
@@ -181,6 +181,7 @@ var _ = Describe("Manager", func() {
 				fakeVMManager,
 				fakeSSHTunnelFactory,
 				mockStateBuilder,
+				10*time.Minute,
 				logger,
 			)
 
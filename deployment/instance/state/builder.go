@@ -83,7 +83,15 @@ func (b *builder) Build(jobName string, instanceID int, deploymentManifest bidep
 		return nil, err
 	}
 
-	renderedJobTemplates, err := b.renderJobTemplates(releaseJobs, releaseJobProperties, deploymentJob.Properties, deploymentManifest.Properties, deploymentManifest.Name, defaultAddress, stage)
+	instance := bitemplate.InstanceSpec{
+		Name:      jobName,
+		Index:     instanceID,
+		ID:        nodeID(instanceID),
+		AZ:        defaultAvailabilityZone,
+		Bootstrap: instanceID == 0,
+	}
+
+	renderedJobTemplates, err := b.renderJobTemplates(releaseJobs, releaseJobProperties, deploymentJob.Properties, deploymentManifest.Properties, deploymentManifest.Name, defaultAddress, instance, stage)
 	if err != nil {
 		return nil, bosherr.WrapErrorf(err, "Rendering job templates for instance '%s/%d'", jobName, instanceID)
 	}
@@ -187,6 +195,7 @@ func (b *builder) renderJobTemplates(
 	globalProperties biproperty.Map,
 	deploymentName string,
 	address string,
+	instance bitemplate.InstanceSpec,
 	stage biui.Stage,
 ) (renderedJobs, error) {
 	var (
@@ -194,7 +203,7 @@ func (b *builder) renderJobTemplates(
 		blobID                 string
 	)
 	err := stage.Perform("Rendering job templates", func() error {
-		renderedJobList, err := b.jobListRenderer.Render(releaseJobs, releaseJobProperties, jobProperties, globalProperties, deploymentName, address)
+		renderedJobList, err := b.jobListRenderer.Render(releaseJobs, releaseJobProperties, jobProperties, globalProperties, deploymentName, address, instance)
 		if err != nil {
 			return err
 		}
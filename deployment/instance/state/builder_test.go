@@ -18,6 +18,7 @@ import (
 	. "github.com/cloudfoundry/bosh-cli/release/resource"
 	bistatejob "github.com/cloudfoundry/bosh-cli/state/job"
 	mock_state_job "github.com/cloudfoundry/bosh-cli/state/job/mocks"
+	bitemplate "github.com/cloudfoundry/bosh-cli/templatescompiler"
 	mock_template "github.com/cloudfoundry/bosh-cli/templatescompiler/mocks"
 	fakebiui "github.com/cloudfoundry/bosh-cli/ui/fakes"
 )
@@ -284,7 +285,13 @@ var _ = Describe("Builder", func() {
 				"fake-job-property": "fake-global-property-value",
 			}
 
-			mockJobListRenderer.EXPECT().Render(releaseJobs, releaseJobProperties, jobProperties, globalProperties, "fake-deployment-name", expectedIP).Return(mockRenderedJobList, nil)
+			mockJobListRenderer.EXPECT().Render(releaseJobs, releaseJobProperties, jobProperties, globalProperties, "fake-deployment-name", expectedIP, bitemplate.InstanceSpec{
+				Name:      "fake-deployment-job-name",
+				Index:     0,
+				ID:        "0",
+				AZ:        "unknown",
+				Bootstrap: true,
+			}).Return(mockRenderedJobList, nil)
 
 			mockRenderedJobList.EXPECT().DeleteSilently()
 
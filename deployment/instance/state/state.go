@@ -5,6 +5,9 @@ import (
 	"strconv"
 )
 
+// defaultAvailabilityZone is reported for instances created without a director to assign an AZ.
+const defaultAvailabilityZone = "unknown"
+
 type State interface {
 	NetworkInterfaces() []NetworkRef
 	RenderedJobs() []JobRef
@@ -105,8 +108,8 @@ func (s *state) ToApplySpec() bias.ApplySpec {
 
 	return bias.ApplySpec{
 		Deployment:       s.deploymentName,
-		NodeID:           strconv.Itoa(s.id),
-		AvailabilityZone: "unknown",
+		NodeID:           nodeID(s.id),
+		AvailabilityZone: defaultAvailabilityZone,
 		Name:             s.name,
 		Index:            s.id,
 		Networks:         networkMap,
@@ -122,3 +125,6 @@ func (s *state) ToApplySpec() bias.ApplySpec {
 		ConfigurationHash: "unused-configuration-hash",
 	}
 }
+
+// nodeID is derived from the instance index so that the apply spec and rendered templates agree on spec.id.
+func nodeID(id int) string { return strconv.Itoa(id) }
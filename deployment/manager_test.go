@@ -1,6 +1,8 @@
 package deployment_test
 
 import (
+	"time"
+
 	. "github.com/cloudfoundry/bosh-cli/deployment"
 
 	mock_agentclient "github.com/cloudfoundry/bosh-cli/agentclient/mocks"
@@ -22,6 +24,7 @@ import (
 	bisshtunnel "github.com/cloudfoundry/bosh-cli/deployment/sshtunnel"
 	bivm "github.com/cloudfoundry/bosh-cli/deployment/vm"
 	bistemcell "github.com/cloudfoundry/bosh-cli/stemcell"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
 	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
@@ -187,12 +190,12 @@ var _ = Describe("Manager", func() {
 			diskManagerFactory := bidisk.NewManagerFactory(diskRepo, logger)
 			diskDeployer := bivm.NewDiskDeployer(diskManagerFactory, diskRepo, logger, false)
 
-			vmManagerFactory := bivm.NewManagerFactory(vmRepo, stemcellRepo, diskDeployer, fakeUUIDGenerator, fs, logger)
+			vmManagerFactory := bivm.NewManagerFactory(vmRepo, stemcellRepo, diskDeployer, fakeUUIDGenerator, fs, biwarn.NewWarnings(logger), logger)
 			sshTunnelFactory := bisshtunnel.NewFactory(logger)
 
 			mockStateBuilderFactory = mock_instance_state.NewMockBuilderFactory(mockCtrl)
 
-			instanceFactory := biinstance.NewFactory(mockStateBuilderFactory)
+			instanceFactory := biinstance.NewFactory(mockStateBuilderFactory, 10*time.Minute)
 			instanceManagerFactory := biinstance.NewManagerFactory(sshTunnelFactory, instanceFactory, logger)
 			stemcellManagerFactory := bistemcell.NewManagerFactory(stemcellRepo)
 
@@ -210,7 +213,7 @@ var _ = Describe("Manager", func() {
 
 			BeforeEach(func() {
 				var err error
-				currentDiskRecord, err = diskRepo.Save("fake-disk-cid", 100, nil)
+				currentDiskRecord, err = diskRepo.Save("fake-disk-cid", 100, nil, nil)
 				Expect(err).ToNot(HaveOccurred())
 				err = diskRepo.UpdateCurrent(currentDiskRecord.ID)
 				Expect(err).ToNot(HaveOccurred())
@@ -246,7 +249,7 @@ var _ = Describe("Manager", func() {
 
 		Context("orphan disk records exist", func() {
 			BeforeEach(func() {
-				_, err := diskRepo.Save("orphan-disk-cid", 100, nil)
+				_, err := diskRepo.Save("orphan-disk-cid", 100, nil, nil)
 				Expect(err).ToNot(HaveOccurred())
 			})
 
@@ -8,4 +8,7 @@ type DiskPool struct {
 	Name            string
 	DiskSize        int
 	CloudProperties biproperty.Map
+
+	// Tags are the tags of the deployment, added to the cloud properties of created disks
+	Tags map[string]string
 }
@@ -75,6 +75,44 @@ func (d Manifest) ResourcePool(jobName string) (ResourcePool, error) {
 	return ResourcePool{}, err
 }
 
+// WithResourcePoolCloudProperties returns a copy of the manifest with the given
+// cloud properties deep merged over those of the named resource pool.
+func (d Manifest) WithResourcePoolCloudProperties(resourcePoolName string, cloudProperties biproperty.Map) (Manifest, error) {
+	resourcePools := make([]ResourcePool, len(d.ResourcePools))
+	copy(resourcePools, d.ResourcePools)
+
+	for i, resourcePool := range resourcePools {
+		if resourcePool.Name == resourcePoolName {
+			resourcePools[i].CloudProperties = mergeProperties(resourcePool.CloudProperties, cloudProperties)
+			d.ResourcePools = resourcePools
+			return d, nil
+		}
+	}
+
+	return Manifest{}, bosherr.Errorf("Could not find resource pool '%s'", resourcePoolName)
+}
+
+func mergeProperties(base, overrides biproperty.Map) biproperty.Map {
+	merged := biproperty.Map{}
+
+	for key, value := range base {
+		merged[key] = value
+	}
+
+	for key, value := range overrides {
+		baseMap, baseIsMap := merged[key].(biproperty.Map)
+		overrideMap, overrideIsMap := value.(biproperty.Map)
+
+		if baseIsMap && overrideIsMap {
+			merged[key] = mergeProperties(baseMap, overrideMap)
+		} else {
+			merged[key] = value
+		}
+	}
+
+	return merged
+}
+
 func (d Manifest) DiskPool(jobName string) (DiskPool, error) {
 	job, found := d.FindJobByName(jobName)
 	if !found {
@@ -84,6 +122,7 @@ func (d Manifest) DiskPool(jobName string) (DiskPool, error) {
 	if job.PersistentDiskPool != "" {
 		for _, diskPool := range d.DiskPools {
 			if diskPool.Name == job.PersistentDiskPool {
+				diskPool.Tags = d.Tags
 				return diskPool, nil
 			}
 		}
@@ -95,6 +134,7 @@ func (d Manifest) DiskPool(jobName string) (DiskPool, error) {
 		diskPool := DiskPool{
 			DiskSize:        job.PersistentDisk,
 			CloudProperties: biproperty.Map{},
+			Tags:            d.Tags,
 		}
 		return diskPool, nil
 	}
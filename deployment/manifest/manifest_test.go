@@ -228,6 +228,54 @@ var _ = Describe("Manifest", func() {
 		})
 	})
 
+	Describe("WithResourcePoolCloudProperties", func() {
+		BeforeEach(func() {
+			deploymentManifest = Manifest{
+				ResourcePools: []ResourcePool{
+					{
+						Name: "fake-resource-pool-name",
+						CloudProperties: biproperty.Map{
+							"instance_type": "m3.medium",
+							"ephemeral_disk": biproperty.Map{
+								"size": 1024,
+								"type": "gp2",
+							},
+						},
+					},
+				},
+			}
+		})
+
+		It("deep merges the cloud properties over the resource pool's cloud properties", func() {
+			overriddenManifest, err := deploymentManifest.WithResourcePoolCloudProperties("fake-resource-pool-name", biproperty.Map{
+				"instance_type":  "m4.xlarge",
+				"ephemeral_disk": biproperty.Map{"size": 4096},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(overriddenManifest.ResourcePools[0].CloudProperties).To(Equal(biproperty.Map{
+				"instance_type": "m4.xlarge",
+				"ephemeral_disk": biproperty.Map{
+					"size": 4096,
+					"type": "gp2",
+				},
+			}))
+		})
+
+		It("does not modify the original manifest", func() {
+			_, err := deploymentManifest.WithResourcePoolCloudProperties("fake-resource-pool-name", biproperty.Map{"instance_type": "m4.xlarge"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(deploymentManifest.ResourcePools[0].CloudProperties["instance_type"]).To(Equal("m3.medium"))
+		})
+
+		It("returns an error when the resource pool is not defined", func() {
+			_, err := deploymentManifest.WithResourcePoolCloudProperties("fake-other-resource-pool-name", biproperty.Map{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Could not find resource pool 'fake-other-resource-pool-name'"))
+		})
+	})
+
 	Describe("DiskPool", func() {
 		Context("when the deployment has disk_pools", func() {
 			BeforeEach(func() {
@@ -269,6 +317,14 @@ var _ = Describe("Manifest", func() {
 					},
 				}))
 			})
+
+			It("has the tags of the deployment", func() {
+				deploymentManifest.Tags = map[string]string{"owner": "fake-owner"}
+
+				diskPool, err := deploymentManifest.DiskPool("fake-job-name")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(diskPool.Tags).To(Equal(map[string]string{"owner": "fake-owner"}))
+			})
 		})
 
 		Context("when job has persistent_disk and there are no disk_pools", func() {
@@ -9,10 +9,14 @@ import (
 )
 
 type FakeValidator struct {
-	ValidateInputs             []ValidateInput
-	ValidateReleaseJobsInputs  []ValidateReleaseJobsInput
-	validateOutputs            []ValidateOutput
-	validateReleaseJobsOutputs []ValidateReleaseJobsOutput
+	ValidateInputs                       []ValidateInput
+	ValidateReleaseJobsInputs            []ValidateReleaseJobsInput
+	ValidateStemcellCompatibilityInputs  []ValidateStemcellCompatibilityInput
+	validateOutputs                      []ValidateOutput
+	validateReleaseJobsOutputs           []ValidateReleaseJobsOutput
+	validateStemcellCompatibilityOutputs []ValidateStemcellCompatibilityOutput
+	ValidateJobPropertiesInputs          []ValidateJobPropertiesInput
+	validateJobPropertiesOutputs         []ValidateJobPropertiesOutput
 }
 
 func NewFakeValidator() *FakeValidator {
@@ -21,6 +25,12 @@ func NewFakeValidator() *FakeValidator {
 		ValidateReleaseJobsInputs:  []ValidateReleaseJobsInput{},
 		validateOutputs:            []ValidateOutput{},
 		validateReleaseJobsOutputs: []ValidateReleaseJobsOutput{},
+
+		ValidateStemcellCompatibilityInputs:  []ValidateStemcellCompatibilityInput{},
+		validateStemcellCompatibilityOutputs: []ValidateStemcellCompatibilityOutput{},
+
+		ValidateJobPropertiesInputs:  []ValidateJobPropertiesInput{},
+		validateJobPropertiesOutputs: []ValidateJobPropertiesOutput{},
 	}
 }
 
@@ -34,6 +44,18 @@ type ValidateReleaseJobsInput struct {
 	ReleaseManager biinstall.ReleaseManager
 }
 
+type ValidateStemcellCompatibilityInput struct {
+	Manifest        bideplmanifest.Manifest
+	ReleaseManager  biinstall.ReleaseManager
+	StemcellOS      string
+	StemcellVersion string
+}
+
+type ValidateJobPropertiesInput struct {
+	Manifest       bideplmanifest.Manifest
+	ReleaseManager biinstall.ReleaseManager
+}
+
 type ValidateOutput struct {
 	Err error
 }
@@ -42,6 +64,14 @@ type ValidateReleaseJobsOutput struct {
 	Err error
 }
 
+type ValidateStemcellCompatibilityOutput struct {
+	Err error
+}
+
+type ValidateJobPropertiesOutput struct {
+	Err error
+}
+
 func (v *FakeValidator) Validate(manifest bideplmanifest.Manifest, releaseSetManifest birelsetmanifest.Manifest) error {
 	v.ValidateInputs = append(v.ValidateInputs, ValidateInput{
 		Manifest:           manifest,
@@ -70,6 +100,36 @@ func (v *FakeValidator) ValidateReleaseJobs(manifest bideplmanifest.Manifest, re
 	return validateReleaseJobsOutput.Err
 }
 
+func (v *FakeValidator) ValidateStemcellCompatibility(manifest bideplmanifest.Manifest, releaseManager biinstall.ReleaseManager, stemcellOS string, stemcellVersion string) error {
+	v.ValidateStemcellCompatibilityInputs = append(v.ValidateStemcellCompatibilityInputs, ValidateStemcellCompatibilityInput{
+		Manifest:        manifest,
+		ReleaseManager:  releaseManager,
+		StemcellOS:      stemcellOS,
+		StemcellVersion: stemcellVersion,
+	})
+
+	if len(v.validateStemcellCompatibilityOutputs) == 0 {
+		return bosherr.Errorf("Unexpected FakeValidator.ValidateStemcellCompatibility(manifest, releaseManager, stemcellOS, stemcellVersion) called with manifest: %#v", manifest)
+	}
+	validateStemcellCompatibilityOutput := v.validateStemcellCompatibilityOutputs[0]
+	v.validateStemcellCompatibilityOutputs = v.validateStemcellCompatibilityOutputs[1:]
+	return validateStemcellCompatibilityOutput.Err
+}
+
+func (v *FakeValidator) ValidateJobProperties(manifest bideplmanifest.Manifest, releaseManager biinstall.ReleaseManager) error {
+	v.ValidateJobPropertiesInputs = append(v.ValidateJobPropertiesInputs, ValidateJobPropertiesInput{
+		Manifest:       manifest,
+		ReleaseManager: releaseManager,
+	})
+
+	if len(v.validateJobPropertiesOutputs) == 0 {
+		return bosherr.Errorf("Unexpected FakeValidator.ValidateJobProperties(manifest, releaseManager) called with manifest: %#v", manifest)
+	}
+	validateJobPropertiesOutput := v.validateJobPropertiesOutputs[0]
+	v.validateJobPropertiesOutputs = v.validateJobPropertiesOutputs[1:]
+	return validateJobPropertiesOutput.Err
+}
+
 func (v *FakeValidator) SetValidateBehavior(outputs []ValidateOutput) {
 	v.validateOutputs = outputs
 }
@@ -77,3 +137,11 @@ func (v *FakeValidator) SetValidateBehavior(outputs []ValidateOutput) {
 func (v *FakeValidator) SetValidateReleaseJobsBehavior(outputs []ValidateReleaseJobsOutput) {
 	v.validateReleaseJobsOutputs = outputs
 }
+
+func (v *FakeValidator) SetValidateStemcellCompatibilityBehavior(outputs []ValidateStemcellCompatibilityOutput) {
+	v.validateStemcellCompatibilityOutputs = outputs
+}
+
+func (v *FakeValidator) SetValidateJobPropertiesBehavior(outputs []ValidateJobPropertiesOutput) {
+	v.validateJobPropertiesOutputs = outputs
+}
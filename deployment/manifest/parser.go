@@ -3,6 +3,7 @@ package manifest
 import (
 	biutil "github.com/cloudfoundry/bosh-cli/common/util"
 	bidepltpl "github.com/cloudfoundry/bosh-cli/deployment/template"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	biproperty "github.com/cloudfoundry/bosh-utils/property"
@@ -15,9 +16,10 @@ type Parser interface {
 }
 
 type parser struct {
-	fs     boshsys.FileSystem
-	logger boshlog.Logger
-	logTag string
+	fs       boshsys.FileSystem
+	warnings biwarn.Warnings
+	logger   boshlog.Logger
+	logTag   string
 }
 
 type manifest struct {
@@ -110,11 +112,12 @@ var boshDeploymentDefaults = Manifest{
 	},
 }
 
-func NewParser(fs boshsys.FileSystem, logger boshlog.Logger) Parser {
+func NewParser(fs boshsys.FileSystem, warnings biwarn.Warnings, logger boshlog.Logger) Parser {
 	return &parser{
-		fs:     fs,
-		logger: logger,
-		logTag: "deploymentParser",
+		fs:       fs,
+		warnings: warnings,
+		logger:   logger,
+		logTag:   "deploymentParser",
 	}
 }
 
@@ -166,6 +169,10 @@ func (p *parser) parseDeploymentManifest(depManifest manifest, path string) (Man
 		return Manifest{}, bosherr.Error("Deployment specifies both jobs and instance_groups keys, only one is allowed")
 	}
 
+	if len(depManifest.Jobs) > 0 {
+		p.warnings.Warn("deployment manifest", "Key 'jobs' is deprecated, use 'instance_groups' instead")
+	}
+
 	rawJobs := depManifest.Jobs
 	if len(depManifest.InstanceGroups) > 0 {
 		rawJobs = depManifest.InstanceGroups
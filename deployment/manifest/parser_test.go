@@ -6,6 +6,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	bidepltpl "github.com/cloudfoundry/bosh-cli/deployment/template"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	biproperty "github.com/cloudfoundry/bosh-utils/property"
 	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
@@ -16,13 +17,15 @@ var _ = Describe("Parser", func() {
 		manifestPath string
 		fakeFs       *fakesys.FakeFileSystem
 		parser       Parser
+		warnings     biwarn.Warnings
 	)
 
 	BeforeEach(func() {
 		manifestPath = "fake-deployment-path"
 		fakeFs = fakesys.NewFakeFileSystem()
 		logger := boshlog.NewLogger(boshlog.LevelNone)
-		parser = NewParser(fakeFs, logger)
+		warnings = biwarn.NewWarnings(logger)
+		parser = NewParser(fakeFs, warnings, logger)
 	})
 
 	Context("ParseInterpolatedTemplate", func() {
@@ -88,6 +91,15 @@ properties:
 			interpolatedTemplate = bidepltpl.NewInterpolatedTemplate([]byte(contents), "fake-sha")
 		})
 
+		It("warns that the jobs key is deprecated", func() {
+			_, err := parser.Parse(interpolatedTemplate, manifestPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(warnings.List()).To(Equal([]biwarn.Warning{
+				{Source: "deployment manifest", Message: "Key 'jobs' is deprecated, use 'instance_groups' instead"},
+			}))
+		})
+
 		It("parses deployment manifest from the interpolatedTemplate", func() {
 			deploymentManifest, err := parser.Parse(interpolatedTemplate, manifestPath)
 			Expect(err).ToNot(HaveOccurred())
@@ -468,6 +480,12 @@ instance_groups:
 				Expect(err).ToNot(HaveOccurred())
 				Expect(deploymentManifest.Jobs[0].Name).To(Equal("jobby"))
 			})
+
+			It("does not warn about deprecated keys", func() {
+				_, err := parser.Parse(interpolatedTemplate, manifestPath)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(warnings.List()).To(BeEmpty())
+			})
 		})
 		Context("when jobs is defined inside an instance_group, treats it as templates", func() {
 			BeforeEach(func() {
@@ -1,30 +1,41 @@
 package manifest
 
 import (
+	"fmt"
 	"net"
+	"path"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
 
 	binet "github.com/cloudfoundry/bosh-cli/common/net"
 	boshinst "github.com/cloudfoundry/bosh-cli/installation"
+	boshjob "github.com/cloudfoundry/bosh-cli/release/job"
 	birelsetmanifest "github.com/cloudfoundry/bosh-cli/release/set/manifest"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 )
 
 type Validator interface {
 	Validate(Manifest, birelsetmanifest.Manifest) error
 	ValidateReleaseJobs(Manifest, boshinst.ReleaseManager) error
+	ValidateStemcellCompatibility(deploymentManifest Manifest, releaseManager boshinst.ReleaseManager, stemcellOS string, stemcellVersion string) error
+	ValidateJobProperties(Manifest, boshinst.ReleaseManager) error
 }
 
 type validator struct {
-	logger boshlog.Logger
+	warnings biwarn.Warnings
+	logger   boshlog.Logger
 }
 
-func NewValidator(logger boshlog.Logger) Validator {
+func NewValidator(warnings biwarn.Warnings, logger boshlog.Logger) Validator {
 	return &validator{
-		logger: logger,
+		warnings: warnings,
+		logger:   logger,
 	}
 }
 
@@ -66,7 +77,7 @@ func (v *validator) Validate(deploymentManifest Manifest, releaseSetManifest bir
 			errs = append(errs, bosherr.Errorf("disk_pools[%d].name must be provided", idx))
 		}
 		if diskPool.DiskSize <= 0 {
-			errs = append(errs, bosherr.Errorf("disk_pools[%d].disk_size must be > 0", idx))
+			errs = append(errs, bosherr.Errorf("disk_pools[%d].disk_size must be > 0 (disk pool '%s' has %d)", idx, diskPool.Name, diskPool.DiskSize))
 		}
 	}
 
@@ -83,7 +94,7 @@ func (v *validator) Validate(deploymentManifest Manifest, releaseSetManifest bir
 		}
 		if job.PersistentDiskPool != "" {
 			if _, ok := v.diskPoolNames(deploymentManifest)[job.PersistentDiskPool]; !ok {
-				errs = append(errs, bosherr.Errorf("jobs[%d].persistent_disk_pool must be the name of a disk pool", idx))
+				errs = append(errs, bosherr.Errorf("jobs[%d].persistent_disk_pool must be the name of a disk pool ('%s' not defined)", idx, job.PersistentDiskPool))
 			}
 		}
 		if job.Instances < 0 {
@@ -138,17 +149,157 @@ func (v *validator) ValidateReleaseJobs(deploymentManifest Manifest, releaseMana
 	errs := []error{}
 
 	for idx, job := range deploymentManifest.Jobs {
+		colocatedJobs := []colocatedJob{}
+
 		for templateIdx, template := range job.Templates {
 			release, found := releaseManager.Find(template.Release)
 			if !found {
 				errs = append(errs, bosherr.Errorf("jobs[%d].templates[%d].release '%s' must refer to release in releases", idx, templateIdx, template.Release))
 			} else {
-				_, found := release.FindJobByName(template.Name)
+				releaseJob, found := release.FindJobByName(template.Name)
 				if !found {
 					errs = append(errs, bosherr.Errorf("jobs[%d].templates[%d] must refer to a job in '%s', but there is no job named '%s'", idx, templateIdx, release.Name(), template.Name))
+				} else {
+					colocatedJobs = append(colocatedJobs, colocatedJob{
+						path:       fmt.Sprintf("jobs[%d].templates[%d]", idx, templateIdx),
+						template:   template,
+						releaseJob: releaseJob,
+					})
+				}
+			}
+		}
+
+		errs = append(errs, v.validateColocatedPackages(colocatedJobs)...)
+		errs = append(errs, v.validateColocatedTemplateTargets(colocatedJobs)...)
+		errs = append(errs, v.validateColocatedPorts(deploymentManifest, job, colocatedJobs)...)
+	}
+
+	if len(errs) > 0 {
+		return bosherr.NewMultiError(errs...)
+	}
+
+	return nil
+}
+
+// ValidateStemcellCompatibility reports jobs whose spec declares the stemcells
+// they support when the deployment's stemcell is not one of them.
+// Jobs that declare no stemcells support any stemcell.
+func (v *validator) ValidateStemcellCompatibility(deploymentManifest Manifest, releaseManager boshinst.ReleaseManager, stemcellOS string, stemcellVersion string) error {
+	errs := []error{}
+
+	for idx, job := range deploymentManifest.Jobs {
+		for templateIdx, template := range job.Templates {
+			release, found := releaseManager.Find(template.Release)
+			if !found {
+				continue
+			}
+
+			releaseJob, found := release.FindJobByName(template.Name)
+			if !found || len(releaseJob.Stemcells) == 0 {
+				continue
+			}
+
+			supported, err := v.supportsStemcell(releaseJob, stemcellOS, stemcellVersion)
+			if err != nil {
+				errs = append(errs, bosherr.WrapErrorf(err, "jobs[%d].templates[%d] '%s' from release '%s'", idx, templateIdx, template.Name, template.Release))
+				continue
+			}
+
+			if !supported {
+				descriptions := []string{}
+				for _, constraint := range releaseJob.Stemcells {
+					descriptions = append(descriptions, fmt.Sprintf("'%s'", constraint))
+				}
+
+				errs = append(errs, bosherr.Errorf(
+					"jobs[%d].templates[%d] '%s' from release '%s' supports stemcells %s, but the deployment uses stemcell '%s/%s'",
+					idx, templateIdx, template.Name, template.Release, strings.Join(descriptions, ", "), stemcellOS, stemcellVersion,
+				))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return bosherr.NewMultiError(errs...)
+	}
+
+	return nil
+}
+
+// ValidateJobProperties cross-references manifest properties with the properties
+// declared in job specs. Properties no job declares are reported as warnings,
+// since they are ignored when rendering. Properties that a template requires,
+// and that have neither a value nor a spec default, are errors.
+func (v *validator) ValidateJobProperties(deploymentManifest Manifest, releaseManager boshinst.ReleaseManager) error {
+	errs := []error{}
+	allDeclared := map[string]struct{}{}
+
+	for idx, job := range deploymentManifest.Jobs {
+		jobDeclared := map[string]struct{}{}
+		jobPropertiesUsed := false
+
+		for templateIdx, template := range job.Templates {
+			release, found := releaseManager.Find(template.Release)
+			if !found {
+				continue
+			}
+
+			releaseJob, found := release.FindJobByName(template.Name)
+			if !found {
+				continue
+			}
+
+			path := fmt.Sprintf("jobs[%d].templates[%d]", idx, templateIdx)
+			templateDeclared := map[string]struct{}{}
+
+			for propertyName := range releaseJob.Properties {
+				templateDeclared[propertyName] = struct{}{}
+				allDeclared[propertyName] = struct{}{}
+
+				if template.Properties == nil {
+					jobDeclared[propertyName] = struct{}{}
+				}
+			}
+
+			if template.Properties != nil {
+				for _, propertyName := range v.undeclaredProperties(*template.Properties, templateDeclared) {
+					v.warnings.Warn("deployment manifest", "%s '%s' does not declare property '%s' in its spec", path, template.Name, propertyName)
+				}
+			} else {
+				jobPropertiesUsed = true
+			}
+
+			requiredNames, err := releaseJob.PropertiesUsedWithoutDefault()
+			if err != nil {
+				errs = append(errs, bosherr.WrapErrorf(err, "%s '%s' from release '%s'", path, template.Name, template.Release))
+				continue
+			}
+
+			for _, propertyName := range requiredNames {
+				definition, declared := releaseJob.Properties[propertyName]
+				if !declared || definition.Default != nil {
+					continue
+				}
+
+				// Templates may only read the property behind a condition, so a missing value is not an error
+				if !v.hasPropertyValue(deploymentManifest, job, template, propertyName) {
+					v.warnings.Warn("deployment manifest", "%s '%s' from release '%s' reads property '%s', which has no default and is not set",
+						path, template.Name, template.Release, propertyName)
 				}
 			}
 		}
+
+		if jobPropertiesUsed {
+			for _, propertyName := range v.undeclaredProperties(job.Properties, jobDeclared) {
+				v.warnings.Warn("deployment manifest", "jobs[%d].properties '%s' is not declared by any of its templates", idx, propertyName)
+			}
+		}
+	}
+
+	if len(allDeclared) > 0 {
+		for _, propertyName := range v.undeclaredProperties(deploymentManifest.Properties, allDeclared) {
+			v.warnings.Warn("deployment manifest", "properties '%s' is not declared by any job", propertyName)
+		}
 	}
 
 	if len(errs) > 0 {
@@ -158,6 +309,283 @@ func (v *validator) ValidateReleaseJobs(deploymentManifest Manifest, releaseMana
 	return nil
 }
 
+// undeclaredProperties returns the dotted paths of manifest property values
+// that are neither a declared property nor nested within one
+func (v *validator) undeclaredProperties(properties biproperty.Map, declared map[string]struct{}) []string {
+	undeclared := []string{}
+
+	for _, path := range v.propertyPaths("", properties) {
+		known := false
+		for name := range declared {
+			if path == name || strings.HasPrefix(path, name+".") || strings.HasPrefix(name, path+".") {
+				known = true
+				break
+			}
+		}
+		if !known {
+			undeclared = append(undeclared, path)
+		}
+	}
+
+	sort.Strings(undeclared)
+
+	return undeclared
+}
+
+func (v *validator) propertyPaths(prefix string, properties biproperty.Map) []string {
+	paths := []string{}
+
+	for key, value := range properties {
+		path := key
+		if len(prefix) > 0 {
+			path = prefix + "." + key
+		}
+
+		if nested, ok := value.(biproperty.Map); ok && len(nested) > 0 {
+			paths = append(paths, v.propertyPaths(path, nested)...)
+		} else {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+func (v *validator) hasPropertyValue(deploymentManifest Manifest, job Job, template ReleaseJobRef, propertyName string) bool {
+	if template.Properties != nil {
+		_, found := v.lookupProperty(*template.Properties, propertyName)
+		return found
+	}
+
+	if _, found := v.lookupProperty(job.Properties, propertyName); found {
+		return true
+	}
+
+	_, found := v.lookupProperty(deploymentManifest.Properties, propertyName)
+	return found
+}
+
+func (v *validator) supportsStemcell(releaseJob boshjob.Job, stemcellOS string, stemcellVersion string) (bool, error) {
+	for _, constraint := range releaseJob.Stemcells {
+		matched, err := constraint.Matches(stemcellOS, stemcellVersion)
+		if err != nil || matched {
+			return matched, err
+		}
+	}
+
+	return false, nil
+}
+
+type colocatedJob struct {
+	path       string
+	template   ReleaseJobRef
+	releaseJob boshjob.Job
+}
+
+type reservedPort struct {
+	job      colocatedJob
+	property string
+}
+
+// validateColocatedPackages reports packages, including dependencies of packages,
+// that share a name but differ in fingerprint between jobs colocated on the same VM,
+// since only one of them can be installed under /var/vcap/packages.
+func (v *validator) validateColocatedPackages(colocatedJobs []colocatedJob) []error {
+	errs := []error{}
+
+	releaseJobs := []boshjob.Job{}
+	for _, colocated := range colocatedJobs {
+		releaseJobs = append(releaseJobs, colocated.releaseJob)
+	}
+
+	for _, conflict := range boshjob.FindPackageConflicts(releaseJobs) {
+		colocated, owner := colocatedJobs[conflict.JobIndex], colocatedJobs[conflict.OtherJobIndex]
+
+		errs = append(errs, bosherr.Errorf(
+			"%s '%s' from release '%s' requires package '%s' with fingerprint '%s', which conflicts with %s '%s' from release '%s' requiring fingerprint '%s'",
+			colocated.path, colocated.template.Name, colocated.template.Release, conflict.Name, conflict.Fingerprint,
+			owner.path, owner.template.Name, owner.template.Release, conflict.OtherFingerprint,
+		))
+	}
+
+	return errs
+}
+
+// validateColocatedTemplateTargets reports templates that would be rendered to the
+// same path on the VM, or outside of the directory of their job. Each job is
+// rendered into a directory named after it, next to its monit file.
+func (v *validator) validateColocatedTemplateTargets(colocatedJobs []colocatedJob) []error {
+	errs := []error{}
+
+	type templateOwner struct {
+		job      colocatedJob
+		template string
+	}
+	owners := map[string]templateOwner{}
+
+	for _, colocated := range colocatedJobs {
+		jobName := colocated.template.Name
+		owners[path.Join(jobName, "monit")] = templateOwner{job: colocated, template: "monit"}
+	}
+
+	for _, colocated := range colocatedJobs {
+		jobName := colocated.template.Name
+
+		templates := []string{}
+		for template := range colocated.releaseJob.Templates {
+			templates = append(templates, template)
+		}
+		sort.Strings(templates)
+
+		for _, template := range templates {
+			target := path.Join(jobName, colocated.releaseJob.Templates[template])
+
+			if !strings.HasPrefix(target, jobName+"/") {
+				errs = append(errs, bosherr.Errorf(
+					"%s '%s' from release '%s' renders template '%s' to '%s', which is outside of the job directory",
+					colocated.path, colocated.template.Name, colocated.template.Release, template, colocated.releaseJob.Templates[template],
+				))
+				continue
+			}
+
+			owner, found := owners[target]
+			if !found {
+				owners[target] = templateOwner{job: colocated, template: template}
+				continue
+			}
+
+			errs = append(errs, bosherr.Errorf(
+				"%s '%s' from release '%s' renders template '%s' to '%s', which conflicts with %s '%s' from release '%s' rendering '%s' there",
+				colocated.path, colocated.template.Name, colocated.template.Release, template, target,
+				owner.job.path, owner.job.template.Name, owner.job.template.Release, owner.template,
+			))
+		}
+	}
+
+	return errs
+}
+
+// validateColocatedPorts reports ports reserved by more than one colocated job.
+// Ports are discovered from job spec properties named 'port' or '*_port'.
+// Properties without value or default reserve no port and are only warned about;
+// values that are not port numbers are errors.
+func (v *validator) validateColocatedPorts(deploymentManifest Manifest, job Job, colocatedJobs []colocatedJob) []error {
+	errs := []error{}
+	reserved := map[int]reservedPort{}
+
+	for _, colocated := range colocatedJobs {
+		propertyNames := []string{}
+		for propertyName := range colocated.releaseJob.Properties {
+			if v.isPortProperty(propertyName) {
+				propertyNames = append(propertyNames, propertyName)
+			}
+		}
+		sort.Strings(propertyNames)
+
+		for _, propertyName := range propertyNames {
+			port, found, err := v.resolvePort(deploymentManifest, job, colocated, propertyName)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			if !found {
+				continue
+			}
+
+			owner, found := reserved[port]
+			if !found {
+				reserved[port] = reservedPort{job: colocated, property: propertyName}
+				continue
+			}
+
+			if owner.job.path != colocated.path {
+				errs = append(errs, bosherr.Errorf(
+					"%s '%s' reserves port %d via property '%s', which overlaps with %s '%s' via property '%s'",
+					colocated.path, colocated.template.Name, port, propertyName,
+					owner.job.path, owner.job.template.Name, owner.property,
+				))
+			}
+		}
+	}
+
+	return errs
+}
+
+func (v *validator) isPortProperty(propertyName string) bool {
+	segments := strings.Split(propertyName, ".")
+	last := segments[len(segments)-1]
+	return last == "port" || strings.HasSuffix(last, "_port")
+}
+
+func (v *validator) resolvePort(deploymentManifest Manifest, job Job, colocated colocatedJob, propertyName string) (int, bool, error) {
+	var value biproperty.Property
+	var found bool
+
+	if colocated.template.Properties != nil {
+		value, found = v.lookupProperty(*colocated.template.Properties, propertyName)
+	} else {
+		value, found = v.lookupProperty(job.Properties, propertyName)
+		if !found {
+			value, found = v.lookupProperty(deploymentManifest.Properties, propertyName)
+		}
+	}
+
+	if !found {
+		value = colocated.releaseJob.Properties[propertyName].Default
+		found = value != nil
+	}
+
+	if !found {
+		v.warnings.Warn("deployment manifest", "Unable to determine port for %s '%s' property '%s': no value or default provided", colocated.path, colocated.template.Name, propertyName)
+		return 0, false, nil
+	}
+
+	port := -1
+
+	switch typedValue := value.(type) {
+	case int:
+		port = typedValue
+	case int64:
+		port = int(typedValue)
+	case uint64:
+		port = int(typedValue)
+	case float64:
+		if typedValue == float64(int(typedValue)) {
+			port = int(typedValue)
+		}
+	case string:
+		parsedPort, err := strconv.Atoi(typedValue)
+		if err == nil {
+			port = parsedPort
+		}
+	}
+
+	if port < 1 || port > 65535 {
+		return 0, false, bosherr.Errorf("%s '%s' property '%s' must be a port number between 1 and 65535, but is %#v", colocated.path, colocated.template.Name, propertyName, value)
+	}
+
+	return port, true, nil
+}
+
+func (v *validator) lookupProperty(properties biproperty.Map, propertyName string) (biproperty.Property, bool) {
+	var current biproperty.Property = properties
+
+	for _, segment := range strings.Split(propertyName, ".") {
+		currentMap, ok := current.(biproperty.Map)
+		if !ok {
+			return nil, false
+		}
+
+		current, ok = currentMap[segment]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
 func (v *validator) isBlank(str string) bool {
 	return str == "" || strings.TrimSpace(str) == ""
 }
@@ -3,6 +3,7 @@ package manifest_test
 import (
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	biproperty "github.com/cloudfoundry/bosh-utils/property"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -10,14 +11,17 @@ import (
 	boshinst "github.com/cloudfoundry/bosh-cli/installation"
 	boshjob "github.com/cloudfoundry/bosh-cli/release/job"
 	birelmanifest "github.com/cloudfoundry/bosh-cli/release/manifest"
+	boshpkg "github.com/cloudfoundry/bosh-cli/release/pkg"
 	fakerel "github.com/cloudfoundry/bosh-cli/release/releasefakes"
 	. "github.com/cloudfoundry/bosh-cli/release/resource"
 	birelsetmanifest "github.com/cloudfoundry/bosh-cli/release/set/manifest"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 )
 
 var _ = Describe("Validator", func() {
 	var (
 		logger         boshlog.Logger
+		warnings       biwarn.Warnings
 		releaseManager boshinst.ReleaseManager
 		validator      Validator
 
@@ -114,7 +118,8 @@ var _ = Describe("Validator", func() {
 			boshjob.NewJob(NewResource("fake-job-name", "", nil)),
 		})
 		releaseManager.Add(release)
-		validator = NewValidator(logger)
+		warnings = biwarn.NewWarnings(logger)
+		validator = NewValidator(warnings, logger)
 	})
 
 	Describe("Validate", func() {
@@ -287,7 +292,22 @@ var _ = Describe("Validator", func() {
 
 			err := validator.Validate(deploymentManifest, validReleaseSetManifest)
 			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(ContainSubstring("disk_pools[0].disk_size must be > 0"))
+			Expect(err.Error()).To(ContainSubstring("disk_pools[0].disk_size must be > 0 (disk pool 'fake-disk' has 0)"))
+		})
+
+		It("rejects negative disk pool sizes", func() {
+			deploymentManifest := Manifest{
+				DiskPools: []DiskPool{
+					{
+						Name:     "fake-disk",
+						DiskSize: -1024,
+					},
+				},
+			}
+
+			err := validator.Validate(deploymentManifest, validReleaseSetManifest)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("disk_pools[0].disk_size must be > 0 (disk pool 'fake-disk' has -1024)"))
 		})
 
 		Describe("networks", func() {
@@ -644,7 +664,7 @@ var _ = Describe("Validator", func() {
 
 			err := validator.Validate(deploymentManifest, validReleaseSetManifest)
 			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(ContainSubstring("jobs[0].persistent_disk_pool must be the name of a disk pool"))
+			Expect(err.Error()).To(ContainSubstring("jobs[0].persistent_disk_pool must be the name of a disk pool ('non-existent-disk-pool' not defined)"))
 		})
 
 		It("validates job resource pool is provided", func() {
@@ -1034,5 +1054,371 @@ var _ = Describe("Validator", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("jobs[0].templates[0] must refer to a job in 'fake-release-name', but there is no job named 'fake-other-job-name'"))
 		})
+
+		Context("when jobs are colocated", func() {
+			var (
+				deploymentManifest Manifest
+				firstJob           *boshjob.Job
+				secondJob          *boshjob.Job
+			)
+
+			addRelease := func(name string, job *boshjob.Job) {
+				otherRelease := &fakerel.FakeRelease{
+					NameStub:    func() string { return name },
+					VersionStub: func() string { return "1.0" },
+				}
+				otherRelease.FindJobByNameStub = func(string) (boshjob.Job, bool) { return *job, true }
+				releaseManager.Add(otherRelease)
+			}
+
+			BeforeEach(func() {
+				deploymentManifest = validManifest
+				deploymentManifest.Jobs = []Job{validManifest.Jobs[0]}
+				deploymentManifest.Jobs[0].Templates = []ReleaseJobRef{
+					{Name: "fake-first-job", Release: "fake-first-release"},
+					{Name: "fake-second-job", Release: "fake-second-release"},
+				}
+
+				firstJob = boshjob.NewJob(NewResource("fake-first-job", "", nil))
+				secondJob = boshjob.NewJob(NewResource("fake-second-job", "", nil))
+				addRelease("fake-first-release", firstJob)
+				addRelease("fake-second-release", secondJob)
+			})
+
+			It("allows packages with the same name and fingerprint", func() {
+				firstJob.Packages = []boshpkg.Compilable{boshpkg.NewPackage(NewResource("fake-pkg", "fake-fp", nil), nil)}
+				secondJob.Packages = []boshpkg.Compilable{boshpkg.NewPackage(NewResource("fake-pkg", "fake-fp", nil), nil)}
+
+				err := validator.ValidateReleaseJobs(deploymentManifest, releaseManager)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("reports packages with the same name but different fingerprints", func() {
+				firstJob.Packages = []boshpkg.Compilable{boshpkg.NewPackage(NewResource("fake-pkg", "fake-fp-1", nil), nil)}
+				secondJob.Packages = []boshpkg.Compilable{boshpkg.NewPackage(NewResource("fake-pkg", "fake-fp-2", nil), nil)}
+
+				err := validator.ValidateReleaseJobs(deploymentManifest, releaseManager)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("jobs[0].templates[1] 'fake-second-job' from release 'fake-second-release' requires package 'fake-pkg' with fingerprint 'fake-fp-2', which conflicts with jobs[0].templates[0] 'fake-first-job' from release 'fake-first-release' requiring fingerprint 'fake-fp-1'"))
+			})
+
+			It("allows jobs to render templates to the same relative path in their own directories", func() {
+				firstJob.Templates = map[string]string{"config.yml.erb": "config/config.yml"}
+				secondJob.Templates = map[string]string{"config.yml.erb": "config/config.yml"}
+
+				err := validator.ValidateReleaseJobs(deploymentManifest, releaseManager)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("reports templates of a job rendered to the same path", func() {
+				firstJob.Templates = map[string]string{
+					"a.yml.erb": "config/app.yml",
+					"b.yml.erb": "config/./app.yml",
+				}
+
+				err := validator.ValidateReleaseJobs(deploymentManifest, releaseManager)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("jobs[0].templates[0] 'fake-first-job' from release 'fake-first-release' renders template 'b.yml.erb' to 'fake-first-job/config/app.yml', which conflicts with jobs[0].templates[0] 'fake-first-job' from release 'fake-first-release' rendering 'a.yml.erb' there"))
+			})
+
+			It("reports templates rendered over the monit file", func() {
+				secondJob.Templates = map[string]string{"monit.erb": "monit"}
+
+				err := validator.ValidateReleaseJobs(deploymentManifest, releaseManager)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("jobs[0].templates[1] 'fake-second-job' from release 'fake-second-release' renders template 'monit.erb' to 'fake-second-job/monit', which conflicts with jobs[0].templates[1] 'fake-second-job' from release 'fake-second-release' rendering 'monit' there"))
+			})
+
+			It("reports templates rendered outside of the job directory, e.g. into another job", func() {
+				secondJob.Templates = map[string]string{"config.yml.erb": "../fake-first-job/config/config.yml"}
+
+				err := validator.ValidateReleaseJobs(deploymentManifest, releaseManager)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("jobs[0].templates[1] 'fake-second-job' from release 'fake-second-release' renders template 'config.yml.erb' to '../fake-first-job/config/config.yml', which is outside of the job directory"))
+			})
+
+			It("reports ports reserved by more than one job, using spec defaults and manifest properties", func() {
+				firstJob.Properties = map[string]boshjob.PropertyDefinition{
+					"first.port":       {Default: 8080},
+					"first.admin_port": {Default: 9000},
+				}
+				secondJob.Properties = map[string]boshjob.PropertyDefinition{
+					"second.port":       {Default: 8081},
+					"second.admin_port": {},
+				}
+				deploymentManifest.Properties = biproperty.Map{
+					"second": biproperty.Map{"port": 8080, "admin_port": "9000"},
+				}
+
+				err := validator.ValidateReleaseJobs(deploymentManifest, releaseManager)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("jobs[0].templates[1] 'fake-second-job' reserves port 9000 via property 'second.admin_port', which overlaps with jobs[0].templates[0] 'fake-first-job' via property 'first.admin_port'"))
+				Expect(err.Error()).To(ContainSubstring("jobs[0].templates[1] 'fake-second-job' reserves port 8080 via property 'second.port', which overlaps with jobs[0].templates[0] 'fake-first-job' via property 'first.port'"))
+			})
+
+			It("uses template properties instead of job and global properties when provided", func() {
+				firstJob.Properties = map[string]boshjob.PropertyDefinition{"port": {Default: 8080}}
+				secondJob.Properties = map[string]boshjob.PropertyDefinition{"port": {Default: 8080}}
+				deploymentManifest.Jobs[0].Templates[1].Properties = &biproperty.Map{"port": 8081}
+
+				err := validator.ValidateReleaseJobs(deploymentManifest, releaseManager)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("skips and warns about ports without value or default", func() {
+				firstJob.Properties = map[string]boshjob.PropertyDefinition{"port": {}}
+				secondJob.Properties = map[string]boshjob.PropertyDefinition{"port": {}}
+
+				err := validator.ValidateReleaseJobs(deploymentManifest, releaseManager)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(warnings.List()).To(HaveLen(2))
+				Expect(warnings.List()[0].Source).To(Equal("deployment manifest"))
+				Expect(warnings.List()[0].Message).To(ContainSubstring("Unable to determine port for jobs[0].templates[0] 'fake-first-job' property 'port': no value or default provided"))
+			})
+
+			It("reports port values that are not port numbers", func() {
+				firstJob.Properties = map[string]boshjob.PropertyDefinition{"port": {Default: "not-a-port"}}
+				secondJob.Properties = map[string]boshjob.PropertyDefinition{"port": {Default: 70000}}
+
+				err := validator.ValidateReleaseJobs(deploymentManifest, releaseManager)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("jobs[0].templates[0] 'fake-first-job' property 'port' must be a port number between 1 and 65535, but is \"not-a-port\""))
+				Expect(err.Error()).To(ContainSubstring("jobs[0].templates[1] 'fake-second-job' property 'port' must be a port number between 1 and 65535, but is 70000"))
+			})
+		})
+	})
+
+	Describe("ValidateStemcellCompatibility", func() {
+		var (
+			deploymentManifest Manifest
+			firstJob           *boshjob.Job
+			secondJob          *boshjob.Job
+		)
+
+		addRelease := func(name string, job *boshjob.Job) {
+			otherRelease := &fakerel.FakeRelease{
+				NameStub:    func() string { return name },
+				VersionStub: func() string { return "1.0" },
+			}
+			otherRelease.FindJobByNameStub = func(string) (boshjob.Job, bool) { return *job, true }
+			releaseManager.Add(otherRelease)
+		}
+
+		BeforeEach(func() {
+			deploymentManifest = validManifest
+			deploymentManifest.Jobs = []Job{validManifest.Jobs[0]}
+			deploymentManifest.Jobs[0].Templates = []ReleaseJobRef{
+				{Name: "fake-first-job", Release: "fake-first-release"},
+				{Name: "fake-second-job", Release: "fake-second-release"},
+			}
+
+			firstJob = boshjob.NewJob(NewResource("fake-first-job", "", nil))
+			secondJob = boshjob.NewJob(NewResource("fake-second-job", "", nil))
+			addRelease("fake-first-release", firstJob)
+			addRelease("fake-second-release", secondJob)
+		})
+
+		It("skips jobs that do not declare stemcells", func() {
+			err := validator.ValidateStemcellCompatibility(deploymentManifest, releaseManager, "ubuntu-trusty", "3468.1")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("allows stemcells matching any declared stemcell", func() {
+			firstJob.Stemcells = []boshjob.StemcellConstraint{
+				{OS: "ubuntu-xenial", Version: ">= 97"},
+				{OS: "ubuntu-trusty", Version: ">= 3468, < 3500"},
+			}
+
+			err := validator.ValidateStemcellCompatibility(deploymentManifest, releaseManager, "ubuntu-trusty", "3468.1")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("reports all jobs that do not support the stemcell", func() {
+			firstJob.Stemcells = []boshjob.StemcellConstraint{{OS: "ubuntu-xenial"}}
+			secondJob.Stemcells = []boshjob.StemcellConstraint{{OS: "ubuntu-trusty", Version: ">= 3500"}}
+
+			err := validator.ValidateStemcellCompatibility(deploymentManifest, releaseManager, "ubuntu-trusty", "3468.1")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(
+				"jobs[0].templates[0] 'fake-first-job' from release 'fake-first-release' supports stemcells 'ubuntu-xenial', but the deployment uses stemcell 'ubuntu-trusty/3468.1'"))
+			Expect(err.Error()).To(ContainSubstring(
+				"jobs[0].templates[1] 'fake-second-job' from release 'fake-second-release' supports stemcells 'ubuntu-trusty >= 3500', but the deployment uses stemcell 'ubuntu-trusty/3468.1'"))
+		})
+
+		It("reports invalid version constraints", func() {
+			firstJob.Stemcells = []boshjob.StemcellConstraint{{OS: "ubuntu-trusty", Version: ">= not a version!"}}
+
+			err := validator.ValidateStemcellCompatibility(deploymentManifest, releaseManager, "ubuntu-trusty", "3468.1")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("jobs[0].templates[0] 'fake-first-job' from release 'fake-first-release': Parsing stemcell version constraint"))
+		})
+	})
+
+	Describe("ValidateJobProperties", func() {
+		var (
+			deploymentManifest Manifest
+			fs                 *fakesys.FakeFileSystem
+			firstJob           *boshjob.Job
+			secondJob          *boshjob.Job
+		)
+
+		addRelease := func(name string, job *boshjob.Job) {
+			otherRelease := &fakerel.FakeRelease{
+				NameStub:    func() string { return name },
+				VersionStub: func() string { return "1.0" },
+			}
+			otherRelease.FindJobByNameStub = func(string) (boshjob.Job, bool) { return *job, true }
+			releaseManager.Add(otherRelease)
+		}
+
+		warningMessages := func() []string {
+			messages := []string{}
+			for _, warning := range warnings.List() {
+				Expect(warning.Source).To(Equal("deployment manifest"))
+				messages = append(messages, warning.Message)
+			}
+			return messages
+		}
+
+		BeforeEach(func() {
+			fs = fakesys.NewFakeFileSystem()
+
+			deploymentManifest = validManifest
+			deploymentManifest.Properties = biproperty.Map{}
+			deploymentManifest.Jobs = []Job{validManifest.Jobs[0]}
+			deploymentManifest.Jobs[0].Properties = biproperty.Map{}
+			deploymentManifest.Jobs[0].Templates = []ReleaseJobRef{
+				{Name: "fake-first-job", Release: "fake-first-release"},
+				{Name: "fake-second-job", Release: "fake-second-release"},
+			}
+
+			firstJob = boshjob.NewExtractedJob(NewResource("fake-first-job", "", nil), "/first-job", fs)
+			firstJob.Properties = map[string]boshjob.PropertyDefinition{
+				"first.certificate": {},
+				"first.config":      {Default: biproperty.Map{}},
+				"first.port":        {Default: 8080},
+			}
+			secondJob = boshjob.NewExtractedJob(NewResource("fake-second-job", "", nil), "/second-job", fs)
+			secondJob.Properties = map[string]boshjob.PropertyDefinition{
+				"second.password": {},
+			}
+			addRelease("fake-first-release", firstJob)
+			addRelease("fake-second-release", secondJob)
+		})
+
+		It("allows properties declared by the jobs, including values nested within declared properties", func() {
+			deploymentManifest.Properties = biproperty.Map{
+				"first": biproperty.Map{
+					"certificate": "fake-cert",
+					"config":      biproperty.Map{"any": biproperty.Map{"key": "value"}},
+				},
+			}
+			deploymentManifest.Jobs[0].Properties = biproperty.Map{
+				"second": biproperty.Map{"password": "fake-password"},
+			}
+
+			err := validator.ValidateJobProperties(deploymentManifest, releaseManager)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings.List()).To(BeEmpty())
+		})
+
+		It("warns about global and job properties that no job declares", func() {
+			deploymentManifest.Properties = biproperty.Map{
+				"first": biproperty.Map{"certifcate": "fake-cert", "port": 8443},
+			}
+			deploymentManifest.Jobs[0].Properties = biproperty.Map{
+				"second": biproperty.Map{"pasword": "fake-password"},
+			}
+
+			err := validator.ValidateJobProperties(deploymentManifest, releaseManager)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warningMessages()).To(Equal([]string{
+				"jobs[0].properties 'second.pasword' is not declared by any of its templates",
+				"properties 'first.certifcate' is not declared by any job",
+			}))
+		})
+
+		It("warns about template properties that the template's job does not declare", func() {
+			deploymentManifest.Jobs[0].Templates[1].Properties = &biproperty.Map{
+				"second": biproperty.Map{"password": "fake-password"},
+				"first":  biproperty.Map{"port": 8443},
+			}
+
+			err := validator.ValidateJobProperties(deploymentManifest, releaseManager)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warningMessages()).To(Equal([]string{
+				"jobs[0].templates[1] 'fake-second-job' does not declare property 'first.port' in its spec",
+			}))
+		})
+
+		Context("when templates read properties without a fallback value", func() {
+			BeforeEach(func() {
+				firstJob.Templates = map[string]string{"config.erb": "config/config"}
+				fs.WriteFileString("/first-job/templates/config.erb", `
+cert: <%= p("first.certificate") %>
+port: <%= p("first.port") %>
+`)
+				secondJob.Templates = map[string]string{"config.erb": "config/config"}
+				fs.WriteFileString("/second-job/templates/config.erb", `
+<% if_p("second.password") do |password| %>password: <%= password %><% end %>
+`)
+			})
+
+			It("warns about properties without a default that are read but not set", func() {
+				err := validator.ValidateJobProperties(deploymentManifest, releaseManager)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(warningMessages()).To(Equal([]string{
+					"jobs[0].templates[0] 'fake-first-job' from release 'fake-first-release' reads property 'first.certificate', which has no default and is not set",
+				}))
+			})
+
+			It("does not fail for properties that templates read behind a condition", func() {
+				fs.WriteFileString("/first-job/templates/config.erb", `
+<% if p("first.port", false) %>cert: <%= p("first.certificate") %><% end %>
+`)
+
+				err := validator.ValidateJobProperties(deploymentManifest, releaseManager)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("allows required properties set globally or on the job", func() {
+				deploymentManifest.Properties = biproperty.Map{
+					"first": biproperty.Map{"certificate": "fake-cert"},
+				}
+
+				err := validator.ValidateJobProperties(deploymentManifest, releaseManager)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(warningMessages()).To(BeEmpty())
+
+				deploymentManifest.Properties = biproperty.Map{}
+				deploymentManifest.Jobs[0].Properties = biproperty.Map{
+					"first": biproperty.Map{"certificate": "fake-cert"},
+				}
+
+				err = validator.ValidateJobProperties(deploymentManifest, releaseManager)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(warningMessages()).To(BeEmpty())
+			})
+
+			It("only looks at template properties when the template has them", func() {
+				deploymentManifest.Properties = biproperty.Map{
+					"first": biproperty.Map{"certificate": "fake-cert"},
+				}
+				deploymentManifest.Jobs[0].Templates[0].Properties = &biproperty.Map{}
+
+				err := validator.ValidateJobProperties(deploymentManifest, releaseManager)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(warningMessages()).To(ContainElement(ContainSubstring("reads property 'first.certificate'")))
+			})
+
+			It("returns error if templates cannot be read", func() {
+				Expect(fs.RemoveAll("/first-job/templates/config.erb")).To(Succeed())
+
+				err := validator.ValidateJobProperties(deploymentManifest, releaseManager)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("jobs[0].templates[0] 'fake-first-job' from release 'fake-first-release': Reading template 'config.erb'"))
+			})
+		})
 	})
 })
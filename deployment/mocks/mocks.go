@@ -49,6 +49,16 @@ func (_mr *_MockDeploymentRecorder) Delete(arg0 interface{}) *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Delete", arg0)
 }
 
+func (_m *MockDeployment) ForceDelete(_param0 ui.Stage) error {
+	ret := _m.ctrl.Call(_m, "ForceDelete", _param0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockDeploymentRecorder) ForceDelete(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ForceDelete", arg0)
+}
+
 // Mock of Factory interface
 type MockFactory struct {
 	ctrl     *gomock.Controller
@@ -0,0 +1,108 @@
+package deployment
+
+import (
+	"fmt"
+
+	biconfig "github.com/cloudfoundry/bosh-cli/config"
+	bideplmanifest "github.com/cloudfoundry/bosh-cli/deployment/manifest"
+	bistemcell "github.com/cloudfoundry/bosh-cli/stemcell"
+)
+
+// Actions of a PlannedAction
+const (
+	PlanActionUpload   = "upload"
+	PlanActionReuse    = "reuse"
+	PlanActionCreate   = "create"
+	PlanActionRecreate = "recreate"
+	PlanActionAttach   = "attach"
+	PlanActionMigrate  = "migrate"
+)
+
+// PlannedAction is a change that deploying the manifest makes in the IaaS
+type PlannedAction struct {
+	Resource string
+	Action   string
+	Details  string
+}
+
+// Plan returns the actions that deploying the manifest takes, deciding like the stemcell manager,
+// the deployer and the disk deployer do: a stemcell is only uploaded when no stemcell with its name
+// and version is recorded, the current VM is always replaced, and the current persistent disk is
+// attached again unless it changed or is recreated, in which case it is migrated to a new disk.
+func Plan(manifest bideplmanifest.Manifest, state biconfig.DeploymentState, stemcell bistemcell.Manifest, recreatePersistentDisks bool) ([]PlannedAction, error) {
+	actions := []PlannedAction{planStemcell(state, stemcell)}
+
+	currentVMCID := state.CurrentVMCID
+	currentDisk, hasDisk := findCurrentDisk(state)
+
+	for _, job := range manifest.Jobs {
+		diskPool, err := manifest.DiskPool(job.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := 0; i < job.Instances; i++ {
+			instance := fmt.Sprintf("%s/%d", job.Name, i)
+
+			if currentVMCID != "" {
+				actions = append(actions, PlannedAction{
+					Resource: "VM " + instance,
+					Action:   PlanActionRecreate,
+					Details:  fmt.Sprintf("Delete VM '%s' and create a new one", currentVMCID),
+				})
+				currentVMCID = ""
+			} else {
+				actions = append(actions, PlannedAction{Resource: "VM " + instance, Action: PlanActionCreate})
+			}
+
+			if diskPool.DiskSize == 0 {
+				continue
+			}
+
+			diskResource := "Disk " + instance
+
+			switch {
+			case !hasDisk:
+				actions = append(actions, PlannedAction{
+					Resource: diskResource,
+					Action:   PlanActionCreate,
+					Details:  fmt.Sprintf("Create a %d MB disk", diskPool.DiskSize),
+				})
+
+			case recreatePersistentDisks || !diskMatches(currentDisk, diskPool):
+				actions = append(actions, PlannedAction{
+					Resource: diskResource,
+					Action:   PlanActionMigrate,
+					Details:  fmt.Sprintf("Copy disk '%s' (%d MB) to a new %d MB disk and delete it", currentDisk.CID, currentDisk.Size, diskPool.DiskSize),
+				})
+
+			default:
+				actions = append(actions, PlannedAction{
+					Resource: diskResource,
+					Action:   PlanActionAttach,
+					Details:  fmt.Sprintf("Attach disk '%s' (%d MB)", currentDisk.CID, currentDisk.Size),
+				})
+			}
+
+			hasDisk = false
+		}
+	}
+
+	return actions, nil
+}
+
+func planStemcell(state biconfig.DeploymentState, stemcell bistemcell.Manifest) PlannedAction {
+	resource := fmt.Sprintf("Stemcell %s/%s", stemcell.Name, stemcell.Version)
+
+	for _, record := range state.Stemcells {
+		if record.Name == stemcell.Name && record.Version == stemcell.Version {
+			return PlannedAction{
+				Resource: resource,
+				Action:   PlanActionReuse,
+				Details:  fmt.Sprintf("Use uploaded stemcell '%s'", record.CID),
+			}
+		}
+	}
+
+	return PlannedAction{Resource: resource, Action: PlanActionUpload}
+}
@@ -0,0 +1,110 @@
+package deployment_test
+
+import (
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	biconfig "github.com/cloudfoundry/bosh-cli/config"
+	. "github.com/cloudfoundry/bosh-cli/deployment"
+	bideplmanifest "github.com/cloudfoundry/bosh-cli/deployment/manifest"
+	bistemcell "github.com/cloudfoundry/bosh-cli/stemcell"
+)
+
+var _ = Describe("Plan", func() {
+	var (
+		manifest bideplmanifest.Manifest
+		state    biconfig.DeploymentState
+		stemcell bistemcell.Manifest
+	)
+
+	BeforeEach(func() {
+		manifest = bideplmanifest.Manifest{
+			Jobs: []bideplmanifest.Job{
+				{
+					Name:           "fake-job-name",
+					Instances:      1,
+					PersistentDisk: 1024,
+				},
+			},
+		}
+
+		state = biconfig.DeploymentState{}
+
+		stemcell = bistemcell.Manifest{Name: "fake-stemcell-name", Version: "fake-stemcell-version"}
+	})
+
+	It("uploads the stemcell and creates the VM and disk of a new deployment", func() {
+		actions, err := Plan(manifest, state, stemcell, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actions).To(Equal([]PlannedAction{
+			{Resource: "Stemcell fake-stemcell-name/fake-stemcell-version", Action: "upload"},
+			{Resource: "VM fake-job-name/0", Action: "create"},
+			{Resource: "Disk fake-job-name/0", Action: "create", Details: "Create a 1024 MB disk"},
+		}))
+	})
+
+	Context("when the deployment exists", func() {
+		BeforeEach(func() {
+			state.CurrentVMCID = "fake-vm-cid"
+			state.CurrentDiskID = "fake-disk-id"
+			state.Disks = []biconfig.DiskRecord{
+				{ID: "fake-disk-id", CID: "fake-disk-cid", Size: 1024, CloudProperties: biproperty.Map{}},
+			}
+			state.Stemcells = []biconfig.StemcellRecord{
+				{Name: "fake-stemcell-name", Version: "fake-stemcell-version", CID: "fake-stemcell-cid"},
+			}
+		})
+
+		It("reuses the stemcell, recreates the VM and attaches the current disk", func() {
+			actions, err := Plan(manifest, state, stemcell, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actions).To(Equal([]PlannedAction{
+				{Resource: "Stemcell fake-stemcell-name/fake-stemcell-version", Action: "reuse", Details: "Use uploaded stemcell 'fake-stemcell-cid'"},
+				{Resource: "VM fake-job-name/0", Action: "recreate", Details: "Delete VM 'fake-vm-cid' and create a new one"},
+				{Resource: "Disk fake-job-name/0", Action: "attach", Details: "Attach disk 'fake-disk-cid' (1024 MB)"},
+			}))
+		})
+
+		It("uploads a stemcell with another version", func() {
+			stemcell.Version = "fake-new-stemcell-version"
+
+			actions, err := Plan(manifest, state, stemcell, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actions[0]).To(Equal(PlannedAction{Resource: "Stemcell fake-stemcell-name/fake-new-stemcell-version", Action: "upload"}))
+		})
+
+		It("migrates the current disk when its size changes", func() {
+			manifest.Jobs[0].PersistentDisk = 2048
+
+			actions, err := Plan(manifest, state, stemcell, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actions[2]).To(Equal(PlannedAction{
+				Resource: "Disk fake-job-name/0",
+				Action:   "migrate",
+				Details:  "Copy disk 'fake-disk-cid' (1024 MB) to a new 2048 MB disk and delete it",
+			}))
+		})
+
+		It("migrates the current disk when persistent disks are recreated", func() {
+			actions, err := Plan(manifest, state, stemcell, true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actions[2].Action).To(Equal("migrate"))
+		})
+	})
+
+	It("plans no disk for jobs without persistent disk", func() {
+		manifest.Jobs[0].PersistentDisk = 0
+
+		actions, err := Plan(manifest, state, stemcell, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actions).To(HaveLen(2))
+	})
+
+	It("returns an error when the disk pool of a job is missing", func() {
+		manifest.Jobs[0].PersistentDiskPool = "fake-missing-pool"
+
+		_, err := Plan(manifest, state, stemcell, false)
+		Expect(err).To(HaveOccurred())
+	})
+})
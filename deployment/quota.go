@@ -0,0 +1,87 @@
+package deployment
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/cloud"
+	biconfig "github.com/cloudfoundry/bosh-cli/config"
+	bideplmanifest "github.com/cloudfoundry/bosh-cli/deployment/manifest"
+)
+
+// Resources are VMs and persistent disk space, in MB, created in the IaaS
+type Resources struct {
+	VMs      int
+	DiskSize int
+}
+
+// RequiredResources returns the resources that deploying the manifest creates in addition to
+// the ones in the deployment state. The current VM is deleted before its replacement is created,
+// so it does not need additional quota, but a persistent disk that changes is migrated to a new
+// disk before the current one is deleted.
+func RequiredResources(manifest bideplmanifest.Manifest, state biconfig.DeploymentState) (Resources, error) {
+	var required Resources
+
+	for _, job := range manifest.Jobs {
+		required.VMs += job.Instances
+
+		diskPool, err := manifest.DiskPool(job.Name)
+		if err != nil {
+			return Resources{}, err
+		}
+
+		if diskPool.DiskSize > 0 && !hasCurrentDisk(state, diskPool) {
+			required.DiskSize += diskPool.DiskSize * job.Instances
+		}
+	}
+
+	if state.CurrentVMCID != "" && required.VMs > 0 {
+		required.VMs--
+	}
+
+	return required, nil
+}
+
+func hasCurrentDisk(state biconfig.DeploymentState, diskPool bideplmanifest.DiskPool) bool {
+	disk, found := findCurrentDisk(state)
+
+	return found && diskMatches(disk, diskPool)
+}
+
+func findCurrentDisk(state biconfig.DeploymentState) (biconfig.DiskRecord, bool) {
+	for _, disk := range state.Disks {
+		if disk.ID == state.CurrentDiskID {
+			return disk, true
+		}
+	}
+
+	return biconfig.DiskRecord{}, false
+}
+
+// diskMatches compares disks like the disk deployer does to decide whether to migrate them
+func diskMatches(disk biconfig.DiskRecord, diskPool bideplmanifest.DiskPool) bool {
+	return disk.Size == diskPool.DiskSize && reflect.DeepEqual(disk.CloudProperties, diskPool.CloudProperties)
+}
+
+// CheckQuota returns an error listing the requested and available resources
+// when the required resources do not fit within the quota
+func CheckQuota(required Resources, quota bicloud.Quota) error {
+	var shortages []string
+
+	if quota.VMs != nil && required.VMs > *quota.VMs {
+		shortages = append(shortages, fmt.Sprintf("VMs: requested %d, available %d", required.VMs, *quota.VMs))
+	}
+
+	if quota.DiskSize != nil && required.DiskSize > *quota.DiskSize {
+		shortages = append(shortages, fmt.Sprintf("Persistent disk: requested %d MB, available %d MB", required.DiskSize, *quota.DiskSize))
+	}
+
+	if len(shortages) == 0 {
+		return nil
+	}
+
+	return bosherr.Errorf("Insufficient quota to deploy:\n  - %s", strings.Join(shortages, "\n  - "))
+}
@@ -0,0 +1,107 @@
+package deployment_test
+
+import (
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/cloud"
+	biconfig "github.com/cloudfoundry/bosh-cli/config"
+	. "github.com/cloudfoundry/bosh-cli/deployment"
+	bideplmanifest "github.com/cloudfoundry/bosh-cli/deployment/manifest"
+)
+
+var _ = Describe("Quota", func() {
+	Describe("RequiredResources", func() {
+		var (
+			manifest bideplmanifest.Manifest
+			state    biconfig.DeploymentState
+		)
+
+		BeforeEach(func() {
+			manifest = bideplmanifest.Manifest{
+				Jobs: []bideplmanifest.Job{
+					{
+						Name:           "fake-job-name",
+						Instances:      1,
+						PersistentDisk: 1024,
+					},
+				},
+			}
+
+			state = biconfig.DeploymentState{}
+		})
+
+		It("requires a VM and a disk for each instance of a new deployment", func() {
+			required, err := RequiredResources(manifest, state)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(required).To(Equal(Resources{VMs: 1, DiskSize: 1024}))
+		})
+
+		It("does not require a VM to replace the current VM", func() {
+			state.CurrentVMCID = "fake-vm-cid"
+
+			required, err := RequiredResources(manifest, state)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(required.VMs).To(Equal(0))
+		})
+
+		It("does not require a disk when the current disk is kept", func() {
+			state.CurrentDiskID = "fake-disk-id"
+			state.Disks = []biconfig.DiskRecord{
+				{ID: "fake-disk-id", Size: 1024, CloudProperties: biproperty.Map{}},
+			}
+
+			required, err := RequiredResources(manifest, state)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(required.DiskSize).To(Equal(0))
+		})
+
+		It("requires a new disk when the current disk is migrated", func() {
+			state.CurrentDiskID = "fake-disk-id"
+			state.Disks = []biconfig.DiskRecord{
+				{ID: "fake-disk-id", Size: 512, CloudProperties: biproperty.Map{}},
+			}
+
+			required, err := RequiredResources(manifest, state)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(required.DiskSize).To(Equal(1024))
+		})
+
+		It("returns an error when the disk pool of a job cannot be found", func() {
+			manifest.Jobs[0].PersistentDiskPool = "fake-missing-disk-pool"
+
+			_, err := RequiredResources(manifest, state)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Could not find persistent disk pool 'fake-missing-disk-pool'"))
+		})
+	})
+
+	Describe("CheckQuota", func() {
+		var (
+			vms      int
+			diskSize int
+		)
+
+		BeforeEach(func() {
+			vms = 1
+			diskSize = 1024
+		})
+
+		It("succeeds when the resources fit within the quota", func() {
+			err := CheckQuota(Resources{VMs: 1, DiskSize: 1024}, bicloud.Quota{VMs: &vms, DiskSize: &diskSize})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("succeeds when the quota is not limited", func() {
+			err := CheckQuota(Resources{VMs: 5, DiskSize: 10240}, bicloud.Quota{})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("reports the requested and available resources that do not fit", func() {
+			err := CheckQuota(Resources{VMs: 2, DiskSize: 2048}, bicloud.Quota{VMs: &vms, DiskSize: &diskSize})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Insufficient quota to deploy:\n  - VMs: requested 2, available 1\n  - Persistent disk: requested 2048 MB, available 1024 MB"))
+		})
+	})
+})
@@ -5,14 +5,23 @@ import (
 	birel "github.com/cloudfoundry/bosh-cli/release"
 	bistemcell "github.com/cloudfoundry/bosh-cli/stemcell"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	semver "github.com/cppforlife/go-semi-semantic/version"
 )
 
 type Record interface {
 	IsDeployed(manifestSHA string, releases []birel.Release, stemcell bistemcell.ExtractedStemcell) (bool, error)
+	FindDowngrades(releases []birel.Release) ([]ReleaseDowngrade, error)
 	Clear() error
 	Update(manifestSHA string, releases []birel.Release) error
 }
 
+// ReleaseDowngrade is a release that would replace a newer version of itself
+type ReleaseDowngrade struct {
+	Name             string
+	CurrentVersion   string
+	RequestedVersion string
+}
+
 type deploymentRecord struct {
 	deploymentRepo biconfig.DeploymentRepo
 	releaseRepo    biconfig.ReleaseRepo
@@ -87,6 +96,45 @@ func (v *deploymentRecord) IsDeployed(newManifestSHA string, releases []birel.Re
 	return true, nil
 }
 
+// FindDowngrades returns the releases that are older than the recorded version of the same release.
+// Releases whose versions cannot be parsed are not compared.
+func (v *deploymentRecord) FindDowngrades(releases []birel.Release) ([]ReleaseDowngrade, error) {
+	currentReleaseRecords, err := v.releaseRepo.List()
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Finding currently deployed releases")
+	}
+
+	var downgrades []ReleaseDowngrade
+
+	for _, release := range releases {
+		for _, releaseRecord := range currentReleaseRecords {
+			if releaseRecord.Name != release.Name() {
+				continue
+			}
+
+			currentVersion, err := semver.NewVersionFromString(releaseRecord.Version)
+			if err != nil {
+				continue
+			}
+
+			requestedVersion, err := semver.NewVersionFromString(release.Version())
+			if err != nil {
+				continue
+			}
+
+			if requestedVersion.IsLt(currentVersion) {
+				downgrades = append(downgrades, ReleaseDowngrade{
+					Name:             release.Name(),
+					CurrentVersion:   releaseRecord.Version,
+					RequestedVersion: release.Version(),
+				})
+			}
+		}
+	}
+
+	return downgrades, nil
+}
+
 func (v *deploymentRecord) Clear() error {
 	err := v.deploymentRepo.UpdateCurrent("")
 	if err != nil {
@@ -277,6 +277,60 @@ var _ = Describe("Record", func() {
 		})
 	})
 
+	Describe("FindDowngrades", func() {
+		var (
+			cpiRelease *fakerel.FakeRelease
+		)
+
+		BeforeEach(func() {
+			release.VersionStub = func() string { return "2.1" }
+
+			cpiRelease = &fakerel.FakeRelease{}
+			cpiRelease.NameReturns("fake-cpi-release")
+			cpiRelease.VersionReturns("58")
+
+			releases = []boshrel.Release{release, cpiRelease}
+
+			releaseRepo.ListReturns([]biconfig.ReleaseRecord{
+				{ID: "fake-release-id", Name: "fake-release-name", Version: "2.0"},
+				{ID: "fake-cpi-release-id", Name: "fake-cpi-release", Version: "60+dev.1"},
+			}, nil)
+		})
+
+		It("returns releases older than the recorded versions", func() {
+			downgrades, err := deploymentRecord.FindDowngrades(releases)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(downgrades).To(Equal([]ReleaseDowngrade{
+				{Name: "fake-cpi-release", CurrentVersion: "60+dev.1", RequestedVersion: "58"},
+			}))
+		})
+
+		It("compares versions semantically", func() {
+			cpiRelease.VersionReturns("60+dev.10")
+
+			downgrades, err := deploymentRecord.FindDowngrades(releases)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(downgrades).To(BeEmpty())
+		})
+
+		It("ignores releases that are not recorded or have unparseable versions", func() {
+			cpiRelease.NameReturns("fake-other-release")
+			release.VersionStub = func() string { return "not a version!" }
+
+			downgrades, err := deploymentRecord.FindDowngrades(releases)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(downgrades).To(BeEmpty())
+		})
+
+		It("returns an error when the release records cannot be listed", func() {
+			releaseRepo.ListReturns(nil, errors.New("fake-list-error"))
+
+			_, err := deploymentRecord.FindDowngrades(releases)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-list-error"))
+		})
+	})
+
 	Describe("Update", func() {
 		It("calculates and updates sha1 of currently deployed manifest", func() {
 			err := deploymentRecord.Update("fake-manifest-sha1", releases)
@@ -3,6 +3,8 @@ package fakes
 type FakeTunnel struct {
 	startOutput *startOutput
 	Started     bool
+	Stopped     bool
+	StopErr     error
 }
 
 type startOutput struct {
@@ -23,6 +25,12 @@ func (s *FakeTunnel) Start(readyErrCh chan<- error, errCh chan<- error) {
 	}
 }
 
+func (s *FakeTunnel) Stop() error {
+	s.Stopped = true
+
+	return s.StopErr
+}
+
 func (s *FakeTunnel) SetStartBehavior(readyErrChOutput error, errChOutput error) {
 	s.startOutput = &startOutput{
 		ReadyErrChOutput: readyErrChOutput,
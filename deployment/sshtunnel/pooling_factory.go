@@ -0,0 +1,164 @@
+package sshtunnel
+
+import (
+	"sync"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+// PoolingFactory is a Factory that keeps started tunnels running so that
+// tunnels with the same options share one SSH connection instead of dialing again.
+// A pooled tunnel that stopped, e.g. because its SSH connection dropped,
+// is dialed again the next time a tunnel with its options is started.
+type PoolingFactory interface {
+	Factory
+
+	// Close stops all tunnels in the pool
+	Close() error
+}
+
+type poolingFactory struct {
+	factory Factory
+
+	tunnels map[Options]*pooledTunnel
+	lock    sync.Mutex
+
+	logTag string
+	logger boshlog.Logger
+}
+
+type pooledTunnel struct {
+	tunnel SSHTunnel
+
+	// stopped is closed once Start of the underlying tunnel returns
+	stopped chan struct{}
+
+	// errCh receives the errors of the underlying tunnel;
+	// it belongs to the caller that started the tunnel most recently
+	errCh chan<- error
+	lock  sync.Mutex
+}
+
+// NewPoolingFactory returns a PoolingFactory creating tunnels with factory
+func NewPoolingFactory(factory Factory, logger boshlog.Logger) PoolingFactory {
+	return &poolingFactory{
+		factory: factory,
+		tunnels: map[Options]*pooledTunnel{},
+
+		logTag: "sshTunnelPool",
+		logger: logger,
+	}
+}
+
+func (f *poolingFactory) NewSSHTunnel(opts Options) SSHTunnel {
+	return &poolingSSHTunnel{opts: opts, pool: f}
+}
+
+func (f *poolingFactory) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var errs []error
+
+	for opts, pooled := range f.tunnels {
+		f.logger.Debug(f.logTag, "Stopping SSH tunnel to %s:%d", opts.Host, opts.Port)
+
+		if err := pooled.tunnel.Stop(); err != nil {
+			errs = append(errs, bosherr.WrapErrorf(err, "Stopping SSH tunnel to %s:%d", opts.Host, opts.Port))
+		}
+	}
+
+	f.tunnels = map[Options]*pooledTunnel{}
+
+	if len(errs) > 0 {
+		return bosherr.NewMultiError(errs...)
+	}
+
+	return nil
+}
+
+func (f *poolingFactory) start(opts Options, errCh chan<- error) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if pooled, found := f.tunnels[opts]; found {
+		if pooled.isRunning() {
+			f.logger.Debug(f.logTag, "Reusing SSH tunnel to %s:%d", opts.Host, opts.Port)
+			pooled.setErrCh(errCh)
+			return nil
+		}
+
+		f.logger.Debug(f.logTag, "SSH tunnel to %s:%d is no longer running, dialing again", opts.Host, opts.Port)
+		delete(f.tunnels, opts)
+
+		if err := pooled.tunnel.Stop(); err != nil {
+			f.logger.Warn(f.logTag, "Failed to stop SSH tunnel to %s:%d: %s", opts.Host, opts.Port, err.Error())
+		}
+	}
+
+	pooled := &pooledTunnel{
+		tunnel:  f.factory.NewSSHTunnel(opts),
+		stopped: make(chan struct{}),
+		errCh:   errCh,
+	}
+
+	readyErrCh := make(chan error, 1)
+	tunnelErrCh := make(chan error)
+
+	go func() {
+		pooled.tunnel.Start(readyErrCh, tunnelErrCh)
+		close(pooled.stopped)
+	}()
+
+	go pooled.forwardErrors(tunnelErrCh)
+
+	if err := <-readyErrCh; err != nil {
+		return err
+	}
+
+	f.tunnels[opts] = pooled
+
+	return nil
+}
+
+func (t *pooledTunnel) isRunning() bool {
+	select {
+	case <-t.stopped:
+		return false
+	default:
+		return true
+	}
+}
+
+func (t *pooledTunnel) setErrCh(errCh chan<- error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.errCh = errCh
+}
+
+func (t *pooledTunnel) forwardErrors(tunnelErrCh <-chan error) {
+	for err := range tunnelErrCh {
+		t.lock.Lock()
+		errCh := t.errCh
+		t.lock.Unlock()
+
+		errCh <- err
+	}
+}
+
+type poolingSSHTunnel struct {
+	opts Options
+	pool *poolingFactory
+}
+
+// Start reports the tunnel as ready right away when a running tunnel with the same options is pooled
+func (t *poolingSSHTunnel) Start(readyErrCh chan<- error, errCh chan<- error) {
+	readyErrCh <- t.pool.start(t.opts, errCh)
+}
+
+// Stop does nothing since pooled tunnels are shared; they are stopped by Close of the PoolingFactory
+func (t *poolingSSHTunnel) Stop() error {
+	return nil
+}
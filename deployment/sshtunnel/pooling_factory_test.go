@@ -0,0 +1,131 @@
+package sshtunnel_test
+
+import (
+	"errors"
+	"sync"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/deployment/sshtunnel"
+)
+
+// runningTunnel is ready once started and keeps running until it is stopped or dropped
+type runningTunnel struct {
+	readyErr error
+	done     chan struct{}
+	doneOnce sync.Once
+	stopped  bool
+}
+
+func (t *runningTunnel) Start(readyErrCh chan<- error, errCh chan<- error) {
+	readyErrCh <- t.readyErr
+	if t.readyErr != nil {
+		return
+	}
+
+	<-t.done
+}
+
+func (t *runningTunnel) Stop() error {
+	t.stopped = true
+	t.drop()
+	return nil
+}
+
+func (t *runningTunnel) drop() { t.doneOnce.Do(func() { close(t.done) }) }
+
+type runningTunnelFactory struct {
+	readyErr error
+	tunnels  []*runningTunnel
+}
+
+func (f *runningTunnelFactory) NewSSHTunnel(Options) SSHTunnel {
+	tunnel := &runningTunnel{readyErr: f.readyErr, done: make(chan struct{})}
+	f.tunnels = append(f.tunnels, tunnel)
+	return tunnel
+}
+
+var _ = Describe("PoolingFactory", func() {
+	var (
+		factory *runningTunnelFactory
+		pool    PoolingFactory
+		opts    Options
+	)
+
+	BeforeEach(func() {
+		factory = &runningTunnelFactory{}
+		pool = NewPoolingFactory(factory, boshlog.NewLogger(boshlog.LevelNone))
+		opts = Options{Host: "fake-host", Port: 22, User: "fake-user", LocalForwardPort: 6901, RemoteForwardPort: 6901}
+	})
+
+	start := func(opts Options) error {
+		readyErrCh := make(chan error, 1)
+		pool.NewSSHTunnel(opts).Start(readyErrCh, make(chan error))
+		return <-readyErrCh
+	}
+
+	It("reuses a running tunnel with the same options", func() {
+		Expect(start(opts)).To(Succeed())
+		Expect(start(opts)).To(Succeed())
+
+		Expect(factory.tunnels).To(HaveLen(1))
+	})
+
+	It("starts separate tunnels for different options", func() {
+		otherOpts := opts
+		otherOpts.Host = "other-fake-host"
+
+		Expect(start(opts)).To(Succeed())
+		Expect(start(otherOpts)).To(Succeed())
+
+		Expect(factory.tunnels).To(HaveLen(2))
+	})
+
+	It("dials again when the pooled tunnel is no longer running", func() {
+		Expect(start(opts)).To(Succeed())
+		factory.tunnels[0].drop()
+
+		Eventually(func() int {
+			Expect(start(opts)).To(Succeed())
+			return len(factory.tunnels)
+		}).Should(Equal(2))
+	})
+
+	It("does not pool tunnels that failed to start", func() {
+		factory.readyErr = errors.New("fake-ready-err")
+		Expect(start(opts)).To(MatchError("fake-ready-err"))
+
+		factory.readyErr = nil
+		Expect(start(opts)).To(Succeed())
+
+		Expect(factory.tunnels).To(HaveLen(2))
+	})
+
+	It("stops all pooled tunnels when closed", func() {
+		otherOpts := opts
+		otherOpts.Host = "other-fake-host"
+
+		Expect(start(opts)).To(Succeed())
+		Expect(start(otherOpts)).To(Succeed())
+
+		Expect(pool.Close()).To(Succeed())
+
+		Expect(factory.tunnels[0].stopped).To(BeTrue())
+		Expect(factory.tunnels[1].stopped).To(BeTrue())
+
+		Expect(start(opts)).To(Succeed())
+		Expect(factory.tunnels).To(HaveLen(3))
+	})
+
+	It("does not stop pooled tunnels when a pooled tunnel is stopped", func() {
+		tunnel := pool.NewSSHTunnel(opts)
+		readyErrCh := make(chan error, 1)
+		tunnel.Start(readyErrCh, make(chan error))
+		Expect(<-readyErrCh).To(Succeed())
+
+		Expect(tunnel.Stop()).To(Succeed())
+		Expect(factory.tunnels[0].stopped).To(BeFalse())
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"sync"
 
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
@@ -13,6 +14,7 @@ import (
 
 type SSHTunnel interface {
 	Start(chan<- error, chan<- error)
+	Stop() error
 }
 
 type sshTunnel struct {
@@ -22,6 +24,8 @@ type sshTunnel struct {
 	remoteForwardPort int
 
 	remoteListener net.Listener
+	stopped        bool
+	lock           sync.Mutex
 
 	logTag string
 	logger boshlog.Logger
@@ -36,18 +40,25 @@ func (s *sshTunnel) Start(readyErrCh chan<- error, errCh chan<- error) {
 
 	remoteListenAddr := fmt.Sprintf("127.0.0.1:%d", s.remoteForwardPort)
 	s.logger.Debug(s.logTag, "Listening on remote server %s", remoteListenAddr)
-	s.remoteListener, err = s.client.Listen("tcp", remoteListenAddr)
+	remoteListener, err := s.client.Listen("tcp", remoteListenAddr)
 	if err != nil {
 		readyErrCh <- bosherr.WrapError(err, "Listening on remote server")
 		return
 	}
 
+	s.lock.Lock()
+	s.remoteListener = remoteListener
+	s.lock.Unlock()
+
 	readyErrCh <- nil
 
 	for {
-		remoteConn, err := s.remoteListener.Accept()
+		remoteConn, err := remoteListener.Accept()
 		s.logger.Debug(s.logTag, "Received connection")
 		if err != nil {
+			if s.isStopped() {
+				return
+			}
 			errCh <- bosherr.WrapError(err, "Accepting connection on remote server")
 			return
 		}
@@ -99,3 +110,26 @@ func (s *sshTunnel) Start(readyErrCh chan<- error, errCh chan<- error) {
 		}(localConn, remoteConn)
 	}
 }
+
+// Stop closes the remote listener and the SSH connection, which makes Start return
+func (s *sshTunnel) Stop() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.stopped = true
+
+	if s.remoteListener != nil {
+		if err := s.remoteListener.Close(); err != nil {
+			s.logger.Warn(s.logTag, "Failed to close remote listener: %s", err.Error())
+		}
+	}
+
+	return s.client.Stop()
+}
+
+func (s *sshTunnel) isStopped() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.stopped
+}
@@ -15,6 +15,17 @@ type Options struct {
 
 	LocalForwardPort  int
 	RemoteForwardPort int
+
+	// Bastion is used to reach Host when it is not directly reachable
+	Bastion BastionOptions
+}
+
+type BastionOptions struct {
+	Host string
+	Port int
+
+	User       string
+	PrivateKey string
 }
 
 func (o Options) IsEmpty() bool {
@@ -43,6 +54,13 @@ func (f *factory) NewSSHTunnel(opts Options) SSHTunnel {
 		User:       opts.User,
 		Password:   opts.Password,
 		PrivateKey: opts.PrivateKey,
+
+		Bastion: boshssh.BastionOpts{
+			Host:       opts.Bastion.Host,
+			Port:       opts.Bastion.Port,
+			User:       opts.Bastion.User,
+			PrivateKey: opts.Bastion.PrivateKey,
+		},
 	}
 
 	tunnel := &sshTunnel{
@@ -18,7 +18,7 @@ func NewDeploymentTemplate(content []byte) DeploymentTemplate {
 }
 
 func (t DeploymentTemplate) Evaluate(vars boshtpl.Variables, op patch.Op) (InterpolatedTemplate, error) {
-	bytes, err := t.template.Evaluate(vars, op, boshtpl.EvaluateOpts{ExpectAllKeys: true})
+	bytes, err := t.template.Evaluate(vars, op, boshtpl.EvaluateOpts{ExpectAllKeys: true, ReportMissingLocations: true})
 	if err != nil {
 		return InterpolatedTemplate{}, err
 	}
@@ -32,6 +32,14 @@ var _ = Describe("DeploymentTemplate", func() {
 		Expect(err.Error()).To(ContainSubstring("Expected to find variables: key"))
 	})
 
+	It("returns an error naming the manifest path referencing a missing variable", func() {
+		deploymentTemplate := NewDeploymentTemplate([]byte("jobs:\n- name: bosh\n  properties:\n    password: ((password))\n"))
+
+		_, err := deploymentTemplate.Evaluate(boshtpl.StaticVariables{}, patch.Ops{})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Expected to find variables: password (referenced at /jobs/name=bosh/properties/password)"))
+	})
+
 	It("returns a struct that can return the SHA2 512 of the struct", func() {
 		deploymentTemplate := NewDeploymentTemplate([]byte(""))
 		vars := boshtpl.StaticVariables{"key": "foo"}
@@ -0,0 +1,319 @@
+package template
+
+import (
+	"path/filepath"
+	"strings"
+
+	biutil "github.com/cloudfoundry/bosh-cli/common/util"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	"gopkg.in/yaml.v2"
+)
+
+const manifestIncludeKey = "include"
+
+// ResolveIncludes returns the manifest at path with the files listed under its top level
+// 'include' key merged in, before any interpolation takes place. Included paths are relative
+// to the including file and may include further files.
+//
+// Relative paths in included files, i.e. release and stemcell URLs, the CPI digest pin file
+// and SSH tunnel private keys, are made absolute against the directory of the included file,
+// so that the result resolves the same way from the directory of the manifest at path.
+//
+// Included files are merged in the order they are listed, followed by the including file itself:
+// hashes are merged key by key, arrays are concatenated and any other value replaces the
+// earlier one. A file that is included more than once is only merged the first time.
+// Manifests without an 'include' key are returned unchanged.
+func ResolveIncludes(fs boshsys.FileSystem, path string, contents []byte) ([]byte, error) {
+	var manifest interface{}
+
+	err := yaml.Unmarshal(contents, &manifest)
+	if err != nil {
+		// Leave reporting malformed manifests to the parsers
+		return contents, nil
+	}
+
+	manifestHash, ok := manifest.(map[interface{}]interface{})
+	if !ok {
+		return contents, nil
+	}
+
+	if _, found := manifestHash[manifestIncludeKey]; !found {
+		return contents, nil
+	}
+
+	resolver := &includeResolver{fs: fs, merged: map[string]bool{path: true}}
+
+	resolved, err := resolver.resolve(path, manifestHash, []string{path})
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Resolving includes of manifest '%s'", path)
+	}
+
+	if len(resolver.missing) > 0 {
+		return nil, bosherr.WrapErrorf(bosherr.NewMultiError(resolver.missing...), "Resolving includes of manifest '%s'", path)
+	}
+
+	resolvedBytes, err := yaml.Marshal(resolved)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Marshalling manifest '%s' with includes", path)
+	}
+
+	return resolvedBytes, nil
+}
+
+// IncludedPaths returns the paths of the files that the manifest at path includes, directly
+// or through other included files. Missing files are listed as well so that they can be
+// watched for, while files that cannot be read are not followed.
+func IncludedPaths(fs boshsys.FileSystem, path string, contents []byte) []string {
+	var manifest map[interface{}]interface{}
+
+	err := yaml.Unmarshal(contents, &manifest)
+	if err != nil {
+		return nil
+	}
+
+	resolver := &includeResolver{fs: fs, merged: map[string]bool{path: true}}
+
+	return resolver.includedPaths(path, manifest)
+}
+
+type includeResolver struct {
+	fs      boshsys.FileSystem
+	merged  map[string]bool
+	missing []error
+}
+
+func (r *includeResolver) resolve(path string, manifest map[interface{}]interface{}, chain []string) (map[interface{}]interface{}, error) {
+	includePaths, err := r.includePaths(path, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := map[interface{}]interface{}{}
+
+	for _, includePath := range includePaths {
+		for _, chainPath := range chain {
+			if chainPath == includePath {
+				return nil, bosherr.Errorf("Manifest include cycle: %s", strings.Join(append(chain, includePath), " -> "))
+			}
+		}
+
+		if r.merged[includePath] {
+			continue
+		}
+		r.merged[includePath] = true
+
+		if !r.fs.FileExists(includePath) {
+			r.missing = append(r.missing, bosherr.Errorf("Manifest '%s' includes '%s' which does not exist", path, includePath))
+			continue
+		}
+
+		included, err := r.read(includePath)
+		if err != nil {
+			return nil, err
+		}
+
+		err = r.absolutifyPaths(includePath, included)
+		if err != nil {
+			return nil, err
+		}
+
+		includedChain := append(append([]string{}, chain...), includePath)
+
+		included, err = r.resolve(includePath, included, includedChain)
+		if err != nil {
+			return nil, err
+		}
+
+		resolved = mergeManifestValues(resolved, included).(map[interface{}]interface{})
+	}
+
+	withoutIncludes := map[interface{}]interface{}{}
+	for key, value := range manifest {
+		if key != manifestIncludeKey {
+			withoutIncludes[key] = value
+		}
+	}
+
+	return mergeManifestValues(resolved, withoutIncludes).(map[interface{}]interface{}), nil
+}
+
+func (r *includeResolver) includedPaths(path string, manifest map[interface{}]interface{}) []string {
+	includePaths, err := r.includePaths(path, manifest)
+	if err != nil {
+		return nil
+	}
+
+	var paths []string
+
+	for _, includePath := range includePaths {
+		if r.merged[includePath] {
+			continue
+		}
+		r.merged[includePath] = true
+
+		paths = append(paths, includePath)
+
+		included, err := r.read(includePath)
+		if err != nil {
+			continue
+		}
+
+		paths = append(paths, r.includedPaths(includePath, included)...)
+	}
+
+	return paths
+}
+
+func (r *includeResolver) includePaths(path string, manifest map[interface{}]interface{}) ([]string, error) {
+	includes, found := manifest[manifestIncludeKey]
+	if !found || includes == nil {
+		return nil, nil
+	}
+
+	includeList, ok := includes.([]interface{})
+	if !ok {
+		return nil, bosherr.Errorf("Expected '%s' in manifest '%s' to be a list of paths", manifestIncludeKey, path)
+	}
+
+	var paths []string
+
+	for _, include := range includeList {
+		includePath, ok := include.(string)
+		if !ok || includePath == "" {
+			return nil, bosherr.Errorf("Expected '%s' in manifest '%s' to be a list of paths", manifestIncludeKey, path)
+		}
+
+		if !filepath.IsAbs(includePath) {
+			includePath = filepath.Join(filepath.Dir(path), includePath)
+		}
+
+		paths = append(paths, includePath)
+	}
+
+	return paths, nil
+}
+
+func (r *includeResolver) read(path string) (map[interface{}]interface{}, error) {
+	contents, err := r.fs.ReadFile(path)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Reading included manifest '%s'", path)
+	}
+
+	var manifest interface{}
+
+	err = yaml.Unmarshal(contents, &manifest)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Unmarshalling included manifest '%s'", path)
+	}
+
+	if manifest == nil {
+		return map[interface{}]interface{}{}, nil
+	}
+
+	manifestHash, ok := manifest.(map[interface{}]interface{})
+	if !ok {
+		return nil, bosherr.Errorf("Expected included manifest '%s' to be a hash", path)
+	}
+
+	return manifestHash, nil
+}
+
+// absolutifyPaths rewrites the relative paths that the manifest parsers would
+// otherwise resolve against the top level manifest
+func (r *includeResolver) absolutifyPaths(path string, manifest map[interface{}]interface{}) error {
+	var pathHolders []pathHolder
+
+	for _, release := range hashesAt(manifest["releases"]) {
+		pathHolders = append(pathHolders, pathHolder{release, "url"})
+	}
+
+	for _, resourcePool := range hashesAt(manifest["resource_pools"]) {
+		for _, stemcell := range hashesAt([]interface{}{resourcePool["stemcell"]}) {
+			pathHolders = append(pathHolders, pathHolder{stemcell, "url"})
+		}
+	}
+
+	for _, cloudProvider := range hashesAt([]interface{}{manifest["cloud_provider"]}) {
+		pathHolders = append(pathHolders, pathHolder{cloudProvider, "cpi_sha256_pin_file"})
+
+		for _, sshTunnel := range hashesAt([]interface{}{cloudProvider["ssh_tunnel"]}) {
+			pathHolders = append(pathHolders, pathHolder{sshTunnel, "private_key"})
+
+			for _, bastion := range hashesAt([]interface{}{sshTunnel["bastion"]}) {
+				pathHolders = append(pathHolders, pathHolder{bastion, "private_key"})
+			}
+		}
+	}
+
+	for _, holder := range pathHolders {
+		value, ok := holder.hash[holder.key].(string)
+		if !ok || value == "" || strings.Contains(value, "((") || strings.HasPrefix(value, "-----BEGIN") {
+			continue
+		}
+
+		absolutePath, err := biutil.AbsolutifyPath(path, value, r.fs)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Resolving path '%s' in included manifest '%s'", value, path)
+		}
+
+		holder.hash[holder.key] = absolutePath
+	}
+
+	return nil
+}
+
+type pathHolder struct {
+	hash map[interface{}]interface{}
+	key  string
+}
+
+func hashesAt(value interface{}) []map[interface{}]interface{} {
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var hashes []map[interface{}]interface{}
+
+	for _, item := range list {
+		if hash, ok := item.(map[interface{}]interface{}); ok {
+			hashes = append(hashes, hash)
+		}
+	}
+
+	return hashes
+}
+
+func mergeManifestValues(base, override interface{}) interface{} {
+	switch typedOverride := override.(type) {
+	case map[interface{}]interface{}:
+		typedBase, ok := base.(map[interface{}]interface{})
+		if !ok {
+			return typedOverride
+		}
+
+		merged := map[interface{}]interface{}{}
+		for key, value := range typedBase {
+			merged[key] = value
+		}
+		for key, value := range typedOverride {
+			if baseValue, found := merged[key]; found {
+				merged[key] = mergeManifestValues(baseValue, value)
+			} else {
+				merged[key] = value
+			}
+		}
+		return merged
+
+	case []interface{}:
+		typedBase, ok := base.([]interface{})
+		if !ok {
+			return typedOverride
+		}
+
+		return append(append([]interface{}{}, typedBase...), typedOverride...)
+	}
+
+	return override
+}
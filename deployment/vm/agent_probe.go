@@ -0,0 +1,204 @@
+package vm
+
+import (
+	"encoding/json"
+	"fmt"
+
+	biagentclient "github.com/cloudfoundry/bosh-agent/agentclient"
+	"github.com/cloudfoundry/bosh-agent/agentclient/applyspec"
+	bihttpagent "github.com/cloudfoundry/bosh-agent/agentclient/http"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+// AgentInfo is what the agent reports about itself.
+// APIVersion is 0 for agents that do not answer the 'info' message.
+type AgentInfo struct {
+	AgentID      string
+	BoshProtocol string
+	APIVersion   int
+}
+
+// AgentRequestSender sends raw messages to the agent, e.g. the AgentRequest of an http agent client
+type AgentRequestSender interface {
+	Send(method string, arguments []interface{}, response bihttpagent.Response) error
+}
+
+type AgentProber interface {
+	Probe() (AgentInfo, error)
+}
+
+type agentProber struct {
+	sender AgentRequestSender
+}
+
+func NewAgentProber(sender AgentRequestSender) AgentProber {
+	return agentProber{sender: sender}
+}
+
+// NewHTTPAgentProber probes the agent behind an http agent client
+func NewHTTPAgentProber(agentClient biagentclient.AgentClient) (AgentProber, error) {
+	httpAgentClient, ok := agentClient.(*bihttpagent.AgentClient)
+	if !ok {
+		return nil, bosherr.Errorf("Probing the agent requires an http agent client, got '%T'", agentClient)
+	}
+
+	return NewAgentProber(httpAgentClient.AgentRequest), nil
+}
+
+func (p agentProber) Probe() (AgentInfo, error) {
+	var stateResponse agentProbeStateResponse
+
+	err := p.sender.Send("get_state", []interface{}{}, &stateResponse)
+	if err != nil {
+		return AgentInfo{}, bosherr.WrapError(err, "Getting agent state")
+	}
+
+	var infoResponse agentProbeInfoResponse
+
+	err = p.sender.Send("info", []interface{}{}, &infoResponse)
+	if err != nil {
+		return AgentInfo{}, bosherr.WrapError(err, "Getting agent info")
+	}
+
+	return AgentInfo{
+		AgentID:      stateResponse.Value.AgentID,
+		BoshProtocol: stateResponse.Value.boshProtocol(),
+		APIVersion:   infoResponse.Value.APIVersion,
+	}, nil
+}
+
+type agentProbeException struct {
+	Message string
+}
+
+type agentProbeStateResponse struct {
+	Value     agentProbeStateValue
+	Exception *agentProbeException
+}
+
+type agentProbeStateValue struct {
+	AgentID      string      `json:"agent_id"`
+	BoshProtocol interface{} `json:"bosh_protocol"`
+}
+
+// boshProtocol is reported as a string by current agents and as a number by older ones
+func (s agentProbeStateValue) boshProtocol() string {
+	if s.BoshProtocol == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", s.BoshProtocol)
+}
+
+func (r *agentProbeStateResponse) Unmarshal(message []byte) error {
+	return json.Unmarshal(message, r)
+}
+
+func (r *agentProbeStateResponse) ServerError() error {
+	if r.Exception != nil {
+		return bosherr.Errorf("Agent responded with error: %s", r.Exception.Message)
+	}
+	return nil
+}
+
+type agentProbeInfoResponse struct {
+	Value struct {
+		APIVersion int `json:"api_version"`
+	}
+	Exception *agentProbeException
+}
+
+func (r *agentProbeInfoResponse) Unmarshal(message []byte) error {
+	return json.Unmarshal(message, r)
+}
+
+// ServerError ignores exceptions since agents that predate the 'info' message reject it as unknown
+func (r *agentProbeInfoResponse) ServerError() error {
+	return nil
+}
+
+// AgentCompatibility is what the stemcell being deployed expects from its agent.
+// APIVersion is the lowest agent API version the stemcell requires, 0 for none.
+type AgentCompatibility struct {
+	StemcellName string
+	BoshProtocol string
+	APIVersion   int
+}
+
+func (c AgentCompatibility) Validate(info AgentInfo) error {
+	if c.BoshProtocol != "" && info.BoshProtocol != c.BoshProtocol {
+		return bosherr.Errorf(
+			"Agent speaks BOSH protocol '%s' but stemcell '%s' requires protocol '%s'; the agent is not compatible with the stemcell",
+			info.BoshProtocol, c.StemcellName, c.BoshProtocol,
+		)
+	}
+
+	if info.APIVersion < c.APIVersion {
+		return bosherr.Errorf(
+			"Agent supports API version %d but stemcell '%s' requires API version %d; the agent is too old for the stemcell",
+			info.APIVersion, c.StemcellName, c.APIVersion,
+		)
+	}
+
+	return nil
+}
+
+// probingAgentClient probes the agent and checks its compatibility before the first apply spec is sent
+type probingAgentClient struct {
+	biagentclient.AgentClient
+
+	prober        AgentProber
+	compatibility AgentCompatibility
+	probed        bool
+
+	logger boshlog.Logger
+	logTag string
+}
+
+func NewProbingAgentClient(
+	agentClient biagentclient.AgentClient,
+	prober AgentProber,
+	compatibility AgentCompatibility,
+	logger boshlog.Logger,
+) biagentclient.AgentClient {
+	return &probingAgentClient{
+		AgentClient:   agentClient,
+		prober:        prober,
+		compatibility: compatibility,
+		logger:        logger,
+		logTag:        "probingAgentClient",
+	}
+}
+
+func (c *probingAgentClient) Apply(spec applyspec.ApplySpec) error {
+	if !c.probed {
+		err := c.probe()
+		if err != nil {
+			return err
+		}
+		c.probed = true
+	}
+
+	return c.AgentClient.Apply(spec)
+}
+
+func (c *probingAgentClient) probe() error {
+	_, err := c.AgentClient.Ping()
+	if err != nil {
+		return bosherr.WrapError(err, "Probing agent: pinging agent")
+	}
+
+	info, err := c.prober.Probe()
+	if err != nil {
+		return bosherr.WrapError(err, "Probing agent")
+	}
+
+	c.logger.Info(c.logTag, "Agent '%s' reported BOSH protocol '%s' and API version %d", info.AgentID, info.BoshProtocol, info.APIVersion)
+
+	err = c.compatibility.Validate(info)
+	if err != nil {
+		return bosherr.WrapError(err, "Probing agent")
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package vm_test
+
+import (
+	"errors"
+
+	. "github.com/cloudfoundry/bosh-cli/deployment/vm"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	biagentclient "github.com/cloudfoundry/bosh-agent/agentclient"
+	bias "github.com/cloudfoundry/bosh-agent/agentclient/applyspec"
+	bihttpagent "github.com/cloudfoundry/bosh-agent/agentclient/http"
+	"github.com/cloudfoundry/bosh-utils/logger/loggerfakes"
+
+	fakebiagentclient "github.com/cloudfoundry/bosh-agent/agentclient/fakes"
+)
+
+type fakeAgentRequestSender struct {
+	methods   []string
+	responses map[string]string
+	errs      map[string]error
+}
+
+func (s *fakeAgentRequestSender) Send(method string, arguments []interface{}, response bihttpagent.Response) error {
+	s.methods = append(s.methods, method)
+
+	if err := s.errs[method]; err != nil {
+		return err
+	}
+
+	err := response.Unmarshal([]byte(s.responses[method]))
+	if err != nil {
+		return err
+	}
+
+	return response.ServerError()
+}
+
+type fakeAgentProber struct {
+	info       AgentInfo
+	err        error
+	probeCount int
+}
+
+func (p *fakeAgentProber) Probe() (AgentInfo, error) {
+	p.probeCount++
+	return p.info, p.err
+}
+
+var _ = Describe("AgentProber", func() {
+	var (
+		sender *fakeAgentRequestSender
+		prober AgentProber
+	)
+
+	BeforeEach(func() {
+		sender = &fakeAgentRequestSender{
+			responses: map[string]string{
+				"get_state": `{"value":{"agent_id":"fake-agent-id","bosh_protocol":"1","job_state":"running"}}`,
+				"info":      `{"value":{"api_version":1}}`,
+			},
+			errs: map[string]error{},
+		}
+		prober = NewAgentProber(sender)
+	})
+
+	It("returns the agent id, protocol and api version reported by the agent", func() {
+		info, err := prober.Probe()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info).To(Equal(AgentInfo{AgentID: "fake-agent-id", BoshProtocol: "1", APIVersion: 1}))
+		Expect(sender.methods).To(Equal([]string{"get_state", "info"}))
+	})
+
+	It("accepts a numeric protocol", func() {
+		sender.responses["get_state"] = `{"value":{"agent_id":"fake-agent-id","bosh_protocol":1}}`
+
+		info, err := prober.Probe()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.BoshProtocol).To(Equal("1"))
+	})
+
+	It("reports api version 0 for agents that do not know the info message", func() {
+		sender.responses["info"] = `{"exception":{"message":"unknown message info"}}`
+
+		info, err := prober.Probe()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.APIVersion).To(Equal(0))
+	})
+
+	It("returns an error when the agent state cannot be fetched", func() {
+		sender.responses["get_state"] = `{"exception":{"message":"fake-state-err"}}`
+
+		_, err := prober.Probe()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Getting agent state"))
+		Expect(err.Error()).To(ContainSubstring("fake-state-err"))
+	})
+
+	It("returns an error when the agent cannot be reached", func() {
+		sender.errs["info"] = errors.New("fake-send-err")
+
+		_, err := prober.Probe()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Getting agent info: fake-send-err"))
+	})
+
+	Describe("NewHTTPAgentProber", func() {
+		It("requires an http agent client", func() {
+			_, err := NewHTTPAgentProber(&fakebiagentclient.FakeAgentClient{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Probing the agent requires an http agent client"))
+		})
+	})
+})
+
+var _ = Describe("ProbingAgentClient", func() {
+	var (
+		fakeAgentClient *fakebiagentclient.FakeAgentClient
+		prober          *fakeAgentProber
+		logger          *loggerfakes.FakeLogger
+		agentClient     biagentclient.AgentClient
+	)
+
+	BeforeEach(func() {
+		fakeAgentClient = &fakebiagentclient.FakeAgentClient{}
+		prober = &fakeAgentProber{info: AgentInfo{AgentID: "fake-agent-id", BoshProtocol: "1", APIVersion: 1}}
+		logger = &loggerfakes.FakeLogger{}
+
+		agentClient = NewProbingAgentClient(
+			fakeAgentClient,
+			prober,
+			AgentCompatibility{StemcellName: "fake-stemcell/1", BoshProtocol: "1"},
+			logger,
+		)
+	})
+
+	It("pings and probes the agent once before the first apply", func() {
+		Expect(agentClient.Apply(bias.ApplySpec{})).To(Succeed())
+		Expect(agentClient.Apply(bias.ApplySpec{})).To(Succeed())
+
+		Expect(fakeAgentClient.PingCallCount()).To(Equal(1))
+		Expect(prober.probeCount).To(Equal(1))
+		Expect(fakeAgentClient.ApplyCallCount()).To(Equal(2))
+	})
+
+	It("logs the reported versions", func() {
+		Expect(agentClient.Apply(bias.ApplySpec{})).To(Succeed())
+
+		Expect(logger.InfoCallCount()).To(Equal(1))
+		_, msg, args := logger.InfoArgsForCall(0)
+		Expect(msg).To(Equal("Agent '%s' reported BOSH protocol '%s' and API version %d"))
+		Expect(args).To(Equal([]interface{}{"fake-agent-id", "1", 1}))
+	})
+
+	It("does not apply when the agent speaks a different protocol than the stemcell", func() {
+		prober.info.BoshProtocol = "0"
+
+		err := agentClient.Apply(bias.ApplySpec{})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Agent speaks BOSH protocol '0' but stemcell 'fake-stemcell/1' requires protocol '1'"))
+		Expect(fakeAgentClient.ApplyCallCount()).To(Equal(0))
+	})
+
+	Context("when the stemcell requires an agent API version", func() {
+		BeforeEach(func() {
+			agentClient = NewProbingAgentClient(
+				fakeAgentClient,
+				prober,
+				AgentCompatibility{StemcellName: "fake-stemcell/1", BoshProtocol: "1", APIVersion: 2},
+				logger,
+			)
+		})
+
+		It("applies when the agent supports the version", func() {
+			prober.info.APIVersion = 2
+
+			Expect(agentClient.Apply(bias.ApplySpec{})).To(Succeed())
+			Expect(fakeAgentClient.ApplyCallCount()).To(Equal(1))
+		})
+
+		It("does not apply when the agent only supports an older version", func() {
+			err := agentClient.Apply(bias.ApplySpec{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Agent supports API version 1 but stemcell 'fake-stemcell/1' requires API version 2"))
+			Expect(fakeAgentClient.ApplyCallCount()).To(Equal(0))
+		})
+
+		It("does not apply when the agent does not report a version", func() {
+			prober.info.APIVersion = 0
+
+			err := agentClient.Apply(bias.ApplySpec{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Agent supports API version 0"))
+		})
+	})
+
+	It("does not apply when the agent does not respond to ping", func() {
+		fakeAgentClient.PingReturns("", errors.New("fake-ping-err"))
+
+		err := agentClient.Apply(bias.ApplySpec{})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-ping-err"))
+		Expect(prober.probeCount).To(Equal(0))
+		Expect(fakeAgentClient.ApplyCallCount()).To(Equal(0))
+	})
+
+	It("does not apply when probing fails", func() {
+		prober.err = errors.New("fake-probe-err")
+
+		err := agentClient.Apply(bias.ApplySpec{})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Probing agent: fake-probe-err"))
+		Expect(fakeAgentClient.ApplyCallCount()).To(Equal(0))
+	})
+})
@@ -51,6 +51,11 @@ func (d *diskDeployer) Deploy(diskPool bideplmanifest.DiskPool, cloud bicloud.Cl
 		return disks, bosherr.WrapError(err, "Multiple current disks not supported")
 
 	} else if len(disks) == 1 {
+		err = d.rollBackInterruptedMigration(disks[0], cloud, stage)
+		if err != nil {
+			return disks, err
+		}
+
 		disks, err = d.deployExistingDisk(disks[0], diskPool, vm, stage)
 		if err != nil {
 			return disks, err
@@ -136,6 +141,16 @@ func (d *diskDeployer) migrateDisk(
 		return newDisk, err
 	}
 
+	newDiskRecord, err := d.findDiskRecord(newDisk)
+	if err != nil {
+		return newDisk, err
+	}
+
+	err = d.diskRepo.UpdateMigrating(newDiskRecord.ID)
+	if err != nil {
+		return newDisk, bosherr.WrapError(err, "Recording disk migration")
+	}
+
 	stageName := fmt.Sprintf("Attaching disk '%s' to VM '%s'", newDisk.CID(), vm.CID())
 	err = stage.Perform(stageName, func() error {
 		return vm.AttachDisk(newDisk)
@@ -157,6 +172,11 @@ func (d *diskDeployer) migrateDisk(
 		return newDisk, err
 	}
 
+	err = d.diskRepo.ClearMigrating()
+	if err != nil {
+		return newDisk, bosherr.WrapError(err, "Clearing disk migration record")
+	}
+
 	stageName = fmt.Sprintf("Detaching disk '%s'", originalDisk.CID())
 	err = stage.Perform(stageName, func() error {
 		return vm.DetachDisk(originalDisk)
@@ -176,14 +196,55 @@ func (d *diskDeployer) migrateDisk(
 	return newDisk, nil
 }
 
-func (d *diskDeployer) updateCurrentDiskRecord(disk bidisk.Disk) error {
+// rollBackInterruptedMigration deletes the disk that an interrupted deploy was migrating data to,
+// so that data is migrated again from the current disk, which still has all of it
+func (d *diskDeployer) rollBackInterruptedMigration(currentDisk bidisk.Disk, cloud bicloud.Cloud, stage biui.Stage) error {
+	migratingDiskRecord, found, err := d.diskRepo.FindMigrating()
+	if err != nil {
+		return bosherr.WrapError(err, "Finding disk of interrupted migration")
+	}
+
+	if !found {
+		return nil
+	}
+
+	// The migration was interrupted after it completed
+	if migratingDiskRecord.CID == currentDisk.CID() {
+		return d.diskRepo.ClearMigrating()
+	}
+
+	d.logger.Debug(d.logTag, "Rolling back interrupted migration from disk '%s' to disk '%s'", currentDisk.CID(), migratingDiskRecord.CID)
+
+	migratingDisk := bidisk.NewDisk(migratingDiskRecord, cloud, d.diskRepo)
+
+	stageName := fmt.Sprintf("Deleting disk '%s' of interrupted migration", migratingDisk.CID())
+	err = stage.Perform(stageName, func() error {
+		return migratingDisk.Delete()
+	})
+	if err != nil {
+		return err
+	}
+
+	return d.diskRepo.ClearMigrating()
+}
+
+func (d *diskDeployer) findDiskRecord(disk bidisk.Disk) (biconfig.DiskRecord, error) {
 	savedDiskRecord, found, err := d.diskRepo.Find(disk.CID())
 	if err != nil {
-		return bosherr.WrapError(err, "Finding disk record")
+		return biconfig.DiskRecord{}, bosherr.WrapError(err, "Finding disk record")
 	}
 
 	if !found {
-		return bosherr.Error("Failed to find disk record for new disk")
+		return biconfig.DiskRecord{}, bosherr.Error("Failed to find disk record for new disk")
+	}
+
+	return savedDiskRecord, nil
+}
+
+func (d *diskDeployer) updateCurrentDiskRecord(disk bidisk.Disk) error {
+	savedDiskRecord, err := d.findDiskRecord(disk)
+	if err != nil {
+		return err
 	}
 
 	err = d.diskRepo.UpdateCurrent(savedDiskRecord.ID)
@@ -365,6 +365,16 @@ var _ = Describe("DiskDeployer", func() {
 					}))
 				})
 
+				It("records the migration until the secondary disk is promoted", func() {
+					_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeDiskRepo.UpdateMigratingInputs).To(Equal([]fakebiconfig.DiskRepoUpdateCurrentInput{
+						{DiskID: "fake-secondary-disk-id"},
+					}))
+					Expect(fakeDiskRepo.ClearMigratingCalled).To(BeTrue())
+				})
+
 				Context("when disk creation fails", func() {
 					BeforeEach(func() {
 						fakeDiskManager.CreateErr = bosherr.Error("fake-create-disk-error")
@@ -456,6 +466,62 @@ var _ = Describe("DiskDeployer", func() {
 							},
 						}))
 					})
+
+					It("keeps the migration recorded so that the next deploy rolls it back", func() {
+						_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+						Expect(err).To(HaveOccurred())
+
+						Expect(fakeDiskRepo.UpdateMigratingInputs).To(HaveLen(1))
+						Expect(fakeDiskRepo.ClearMigratingCalled).To(BeFalse())
+					})
+				})
+			})
+
+			Context("when a previous migration was interrupted", func() {
+				var interruptedDiskRecord biconfig.DiskRecord
+
+				BeforeEach(func() {
+					existingDisk.SetNeedsMigrationBehavior(false)
+
+					interruptedDiskRecord = biconfig.DiskRecord{
+						ID:  "fake-interrupted-disk-id",
+						CID: "fake-interrupted-disk-cid",
+					}
+					fakeDiskRepo.SetFindMigratingBehavior(interruptedDiskRecord, true, nil)
+					fakeDiskRepo.SetFindBehavior("fake-interrupted-disk-cid", interruptedDiskRecord, true, nil)
+				})
+
+				It("deletes the disk that data was being migrated to before attaching the current disk", func() {
+					_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(cloud.DeleteDiskInputs).To(Equal([]fakebicloud.DeleteDiskInput{
+						{DiskCID: "fake-interrupted-disk-cid"},
+					}))
+					Expect(fakeDiskRepo.DeleteInputs).To(ContainElement(fakebiconfig.DiskRepoDeleteInput{DiskRecord: interruptedDiskRecord}))
+					Expect(fakeDiskRepo.ClearMigratingCalled).To(BeTrue())
+
+					Expect(fakeStage.PerformCalls[0]).To(Equal(&fakebiui.PerformCall{
+						Name: "Deleting disk 'fake-interrupted-disk-cid' of interrupted migration",
+					}))
+					Expect(fakeStage.PerformCalls[1]).To(Equal(&fakebiui.PerformCall{
+						Name: "Attaching disk 'fake-existing-disk-cid' to VM 'fake-vm-cid'",
+					}))
+				})
+
+				Context("when the migration completed before the interruption", func() {
+					BeforeEach(func() {
+						interruptedDiskRecord.CID = "fake-existing-disk-cid"
+						fakeDiskRepo.SetFindMigratingBehavior(interruptedDiskRecord, true, nil)
+					})
+
+					It("keeps the current disk", func() {
+						_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(cloud.DeleteDiskInputs).To(BeEmpty())
+						Expect(fakeDiskRepo.ClearMigratingCalled).To(BeTrue())
+					})
 				})
 			})
 		})
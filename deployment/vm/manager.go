@@ -8,6 +8,7 @@ import (
 	biconfig "github.com/cloudfoundry/bosh-cli/config"
 	bideplmanifest "github.com/cloudfoundry/bosh-cli/deployment/manifest"
 	bistemcell "github.com/cloudfoundry/bosh-cli/stemcell"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	biproperty "github.com/cloudfoundry/bosh-utils/property"
@@ -30,6 +31,7 @@ type manager struct {
 	cloud              bicloud.Cloud
 	uuidGenerator      boshuuid.Generator
 	fs                 boshsys.FileSystem
+	warnings           biwarn.Warnings
 	logger             boshlog.Logger
 	logTag             string
 	timeService        Clock
@@ -43,6 +45,7 @@ func NewManager(
 	cloud bicloud.Cloud,
 	uuidGenerator boshuuid.Generator,
 	fs boshsys.FileSystem,
+	warnings biwarn.Warnings,
 	logger boshlog.Logger,
 	timeService Clock,
 ) Manager {
@@ -54,6 +57,7 @@ func NewManager(
 		diskDeployer:  diskDeployer,
 		uuidGenerator: uuidGenerator,
 		fs:            fs,
+		warnings:      warnings,
 		logger:        logger,
 		logTag:        "vmManager",
 		timeService:   timeService,
@@ -79,6 +83,7 @@ func (m *manager) FindCurrent() (VM, bool, error) {
 		m.cloud,
 		clock.NewClock(),
 		m.fs,
+		m.warnings,
 		m.logger,
 	)
 
@@ -103,7 +108,7 @@ func (m *manager) Create(stemcell bistemcell.CloudStemcell, deploymentManifest b
 		return nil, bosherr.WrapError(err, "Generating agent ID")
 	}
 
-	cid, err := m.createAndRecordVM(agentID, stemcell, resourcePool, networkInterfaces)
+	cid, err := m.createAndRecordVM(agentID, stemcell, resourcePool, networkInterfaces, deploymentManifest.Tags)
 	if err != nil {
 		return nil, err
 	}
@@ -125,7 +130,9 @@ func (m *manager) Create(stemcell bistemcell.CloudStemcell, deploymentManifest b
 	if err != nil {
 		cloudErr, ok := err.(bicloud.Error)
 		if ok && cloudErr.Type() == bicloud.NotImplementedError {
-			//ignore it
+			if len(deploymentManifest.Tags) > 0 {
+				m.warnings.Warn("cpi", "VM '%s' was not tagged: the CPI does not implement set_vm_metadata", cid)
+			}
 		} else {
 			return nil, bosherr.WrapErrorf(err, "Setting VM metadata to %s", metadata)
 		}
@@ -140,15 +147,17 @@ func (m *manager) Create(stemcell bistemcell.CloudStemcell, deploymentManifest b
 		m.cloud,
 		clock.NewClock(),
 		m.fs,
+		m.warnings,
 		m.logger,
 		metadata,
+		deploymentManifest.Tags,
 	)
 
 	return vm, nil
 }
 
-func (m *manager) createAndRecordVM(agentID string, stemcell bistemcell.CloudStemcell, resourcePool bideplmanifest.ResourcePool, networkInterfaces map[string]biproperty.Map) (string, error) {
-	cid, err := m.cloud.CreateVM(agentID, stemcell.CID(), resourcePool.CloudProperties, networkInterfaces, resourcePool.Env)
+func (m *manager) createAndRecordVM(agentID string, stemcell bistemcell.CloudStemcell, resourcePool bideplmanifest.ResourcePool, networkInterfaces map[string]biproperty.Map, tags map[string]string) (string, error) {
+	cid, err := m.cloud.CreateVM(agentID, stemcell.CID(), bicloud.CloudPropertiesWithTags(resourcePool.CloudProperties, tags), networkInterfaces, resourcePool.Env)
 	if err != nil {
 		return "", bosherr.WrapErrorf(err, "Creating vm with stemcell cid '%s'", stemcell.CID())
 	}
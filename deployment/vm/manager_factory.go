@@ -5,6 +5,7 @@ import (
 	biagentclient "github.com/cloudfoundry/bosh-agent/agentclient"
 	bicloud "github.com/cloudfoundry/bosh-cli/cloud"
 	biconfig "github.com/cloudfoundry/bosh-cli/config"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
 	boshuuid "github.com/cloudfoundry/bosh-utils/uuid"
@@ -20,6 +21,7 @@ type managerFactory struct {
 	diskDeployer  DiskDeployer
 	uuidGenerator boshuuid.Generator
 	fs            boshsys.FileSystem
+	warnings      biwarn.Warnings
 	logger        boshlog.Logger
 }
 
@@ -29,6 +31,7 @@ func NewManagerFactory(
 	diskDeployer DiskDeployer,
 	uuidGenerator boshuuid.Generator,
 	fs boshsys.FileSystem,
+	warnings biwarn.Warnings,
 	logger boshlog.Logger,
 ) ManagerFactory {
 	return &managerFactory{
@@ -37,6 +40,7 @@ func NewManagerFactory(
 		diskDeployer:  diskDeployer,
 		uuidGenerator: uuidGenerator,
 		fs:            fs,
+		warnings:      warnings,
 		logger:        logger,
 	}
 }
@@ -50,6 +54,7 @@ func (f *managerFactory) NewManager(cloud bicloud.Cloud, agentClient biagentclie
 		cloud,
 		f.uuidGenerator,
 		f.fs,
+		f.warnings,
 		f.logger,
 		clock.NewClock(),
 	)
@@ -14,6 +14,7 @@ import (
 	. "github.com/cloudfoundry/bosh-cli/deployment/vm"
 	fakebivm "github.com/cloudfoundry/bosh-cli/deployment/vm/fakes"
 	bistemcell "github.com/cloudfoundry/bosh-cli/stemcell"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	biproperty "github.com/cloudfoundry/bosh-utils/property"
 	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
@@ -38,6 +39,7 @@ var _ = Describe("Manager", func() {
 		fakeAgentClient           *fakebiagentclient.FakeAgentClient
 		stemcell                  bistemcell.CloudStemcell
 		fs                        *fakesys.FakeFileSystem
+		warnings                  biwarn.Warnings
 		fakeTimeService           Clock
 	)
 
@@ -53,6 +55,7 @@ var _ = Describe("Manager", func() {
 		stemcellRepo = biconfig.NewStemcellRepo(deploymentStateService, fakeUUIDGenerator)
 
 		fakeDiskDeployer = fakebivm.NewFakeDiskDeployer()
+		warnings = biwarn.NewWarnings(logger)
 		fakeTime := time.Date(2016, time.November, 10, 23, 0, 0, 0, time.UTC)
 		fakeTimeService = &FakeClock{Times: []time.Time{fakeTime, time.Now().Add(10 * time.Minute)}}
 
@@ -64,6 +67,7 @@ var _ = Describe("Manager", func() {
 			fakeCloud,
 			fakeUUIDGenerator,
 			fs,
+			warnings,
 			logger,
 			fakeTimeService,
 		)
@@ -134,6 +138,7 @@ var _ = Describe("Manager", func() {
 				fakeCloud,
 				clock.NewClock(),
 				fs,
+				warnings,
 				logger,
 				bicloud.VMMetadata{
 					"deployment":     "fake-deployment",
@@ -143,6 +148,7 @@ var _ = Describe("Manager", func() {
 					"director":       "bosh-init",
 					"created_at":     "2016-11-10T23:00:00Z",
 				},
+				nil,
 			)
 			Expect(vm).To(Equal(expectedVM))
 
@@ -194,6 +200,18 @@ var _ = Describe("Manager", func() {
 				}))
 			})
 
+			It("passes the tags to the infrastructure in the vm cloud properties", func() {
+				deploymentManifest.Tags = map[string]string{"key1": "value1"}
+
+				_, err := manager.Create(stemcell, deploymentManifest)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeCloud.CreateVMInput.CloudProperties).To(Equal(biproperty.Map{
+					"fake-cloud-property-key": "fake-cloud-property-value",
+					"tags":                    biproperty.Map{"key1": "value1"},
+				}))
+			})
+
 			Context("overriding built-in metadata", func() {
 				It("gives precedence to deployment tags", func() {
 					deploymentManifest.Tags = map[string]string{
@@ -253,6 +271,20 @@ var _ = Describe("Manager", func() {
 
 				_, err := manager.Create(stemcell, deploymentManifest)
 				Expect(err).ToNot(HaveOccurred())
+				Expect(warnings.List()).To(BeEmpty())
+			})
+
+			It("warns that the vm was not tagged when the manifest has tags", func() {
+				fakeCloud.SetVMMetadataError = cloud.NewCPIError("set_vm_metadata", cloud.CmdError{
+					Type: "Bosh::Clouds::NotImplemented",
+				})
+				deploymentManifest.Tags = map[string]string{"owner": "fake-owner"}
+
+				_, err := manager.Create(stemcell, deploymentManifest)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(warnings.List()).To(Equal([]biwarn.Warning{
+					{Source: "cpi", Message: "VM 'fake-vm-cid' was not tagged: the CPI does not implement set_vm_metadata"},
+				}))
 			})
 		})
 
@@ -9,7 +9,9 @@ import (
 	biconfig "github.com/cloudfoundry/bosh-cli/config"
 	bidisk "github.com/cloudfoundry/bosh-cli/deployment/disk"
 	bideplmanifest "github.com/cloudfoundry/bosh-cli/deployment/manifest"
+	biretry "github.com/cloudfoundry/bosh-cli/retry"
 	biui "github.com/cloudfoundry/bosh-cli/ui"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	boshretry "github.com/cloudfoundry/bosh-utils/retrystrategy"
@@ -50,9 +52,11 @@ type vm struct {
 	cloud        bicloud.Cloud
 	timeService  Clock
 	fs           boshsys.FileSystem
+	warnings     biwarn.Warnings
 	logger       boshlog.Logger
 	logTag       string
 	metadata     bicloud.VMMetadata
+	tags         map[string]string
 }
 
 func NewVM(
@@ -64,6 +68,7 @@ func NewVM(
 	cloud bicloud.Cloud,
 	timeService Clock,
 	fs boshsys.FileSystem,
+	warnings biwarn.Warnings,
 	logger boshlog.Logger,
 ) VM {
 	return &vm{
@@ -75,6 +80,7 @@ func NewVM(
 		cloud:        cloud,
 		timeService:  timeService,
 		fs:           fs,
+		warnings:     warnings,
 		logger:       logger,
 		logTag:       "vm",
 	}
@@ -89,8 +95,10 @@ func NewVMWithMetadata(
 	cloud bicloud.Cloud,
 	timeService Clock,
 	fs boshsys.FileSystem,
+	warnings biwarn.Warnings,
 	logger boshlog.Logger,
 	metadata bicloud.VMMetadata,
+	tags map[string]string,
 ) VM {
 	return &vm{
 		cid:          cid,
@@ -101,9 +109,11 @@ func NewVMWithMetadata(
 		cloud:        cloud,
 		timeService:  timeService,
 		fs:           fs,
+		warnings:     warnings,
 		logger:       logger,
 		logTag:       "vm",
 		metadata:     metadata,
+		tags:         tags,
 	}
 }
 
@@ -123,10 +133,25 @@ func (vm *vm) AgentClient() biagentclient.AgentClient {
 	return vm.agentClient
 }
 
+// maxAgentPingDelay caps the backoff between pings so that an agent that
+// comes up late is still noticed soon after
+const maxAgentPingDelay = 5 * time.Second
+
+// WaitUntilReady pings the agent until it responds or the timeout passes,
+// starting with the given delay between pings and backing off from there
 func (vm *vm) WaitUntilReady(timeout time.Duration, delay time.Duration) error {
 	agentPingRetryable := biagentclient.NewPingRetryable(vm.agentClient)
-	agentPingRetryStrategy := boshretry.NewTimeoutRetryStrategy(timeout, delay, agentPingRetryable, vm.timeService, vm.logger)
-	return agentPingRetryStrategy.Try()
+
+	// Pings are expected to fail until the agent starts, so they are only logged
+	retrier := biretry.NewRetrier(biretry.Config{Delay: delay, MaxDelay: maxAgentPingDelay, Timeout: timeout}, vm.timeService, nil, vm.logger)
+
+	var shouldRetry bool
+
+	return retrier.Retry("ping of agent", func(error) bool { return shouldRetry }, func() error {
+		var err error
+		shouldRetry, err = agentPingRetryable.Attempt()
+		return err
+	})
 }
 
 func (vm *vm) Start() error {
@@ -184,6 +209,9 @@ func (vm *vm) AttachDisk(disk bidisk.Disk) error {
 		cloudErr, ok := err.(bicloud.Error)
 		if ok && cloudErr.Type() == bicloud.NotImplementedError {
 			vm.logger.Warn(vm.logTag, "'SetDiskMetadata' not implemented by CPI")
+			if len(vm.tags) > 0 {
+				vm.warnings.Warn("cpi", "Disk '%s' was not tagged: the CPI does not implement set_disk_metadata", disk.CID())
+			}
 		} else {
 			return bosherr.WrapErrorf(err, "Setting disk metadata for %s", disk.CID())
 		}
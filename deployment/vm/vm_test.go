@@ -14,6 +14,7 @@ import (
 	biconfig "github.com/cloudfoundry/bosh-cli/config"
 	bidisk "github.com/cloudfoundry/bosh-cli/deployment/disk"
 	bideplmanifest "github.com/cloudfoundry/bosh-cli/deployment/manifest"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	"github.com/cloudfoundry/bosh-utils/logger/loggerfakes"
 	biproperty "github.com/cloudfoundry/bosh-utils/property"
 	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
@@ -24,6 +25,7 @@ import (
 	fakebidisk "github.com/cloudfoundry/bosh-cli/deployment/disk/fakes"
 	fakebivm "github.com/cloudfoundry/bosh-cli/deployment/vm/fakes"
 	fakebiui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 )
 
 var _ = Describe("VM", func() {
@@ -38,6 +40,7 @@ var _ = Describe("VM", func() {
 		diskPool         bideplmanifest.DiskPool
 		timeService      *FakeClock
 		fs               *fakesys.FakeFileSystem
+		warnings         biwarn.Warnings
 		logger           *loggerfakes.FakeLogger
 	)
 
@@ -64,6 +67,7 @@ var _ = Describe("VM", func() {
 		fakeVMRepo = fakebiconfig.NewFakeVMRepo()
 		fakeStemcellRepo = fakebiconfig.NewFakeStemcellRepo()
 		fakeDiskDeployer = fakebivm.NewFakeDiskDeployer()
+		warnings = biwarn.NewWarnings(boshlog.NewLogger(boshlog.LevelNone))
 		vm = NewVM(
 			"fake-vm-cid",
 			fakeVMRepo,
@@ -73,6 +77,7 @@ var _ = Describe("VM", func() {
 			fakeCloud,
 			timeService,
 			fs,
+			warnings,
 			logger,
 		)
 	})
@@ -241,8 +246,13 @@ var _ = Describe("VM", func() {
 				fakeCloud,
 				timeService,
 				fs,
+				warnings,
 				logger,
 				metadata,
+				map[string]string{
+					"custom_tag1": "custom_value1",
+					"custom_tag2": "custom_value2",
+				},
 			)
 		})
 
@@ -298,6 +308,15 @@ var _ = Describe("VM", func() {
 					Expect(tag).To(Equal("vm"))
 					Expect(msg).To(Equal("'SetDiskMetadata' not implemented by CPI"))
 				})
+
+				It("warns that the disk was not tagged", func() {
+					err := vm.AttachDisk(disk)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(warnings.List()).To(Equal([]biwarn.Warning{
+						{Source: "cpi", Message: "Disk 'fake-disk-cid' was not tagged: the CPI does not implement set_disk_metadata"},
+					}))
+				})
 			})
 
 			Context("when setting metadata fails", func() {
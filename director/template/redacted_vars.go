@@ -0,0 +1,56 @@
+package template
+
+const RedactedValue = "<redacted>"
+
+// RedactedVars replaces values found by wrapped variables with RedactedValue,
+// keeping structure of map and array values so that nested references still resolve.
+type RedactedVars struct {
+	vars Variables
+}
+
+func NewRedactedVars(vars Variables) RedactedVars {
+	return RedactedVars{vars}
+}
+
+var _ Variables = RedactedVars{}
+
+func (v RedactedVars) Get(varDef VariableDefinition) (interface{}, bool, error) {
+	val, found, err := v.vars.Get(varDef)
+	if !found || err != nil {
+		return val, found, err
+	}
+
+	return v.redact(val), true, nil
+}
+
+func (v RedactedVars) List() ([]VariableDefinition, error) {
+	return v.vars.List()
+}
+
+func (v RedactedVars) redact(val interface{}) interface{} {
+	switch typedVal := val.(type) {
+	case map[interface{}]interface{}:
+		redacted := map[interface{}]interface{}{}
+		for k, v2 := range typedVal {
+			redacted[k] = v.redact(v2)
+		}
+		return redacted
+
+	case map[string]interface{}:
+		redacted := map[string]interface{}{}
+		for k, v2 := range typedVal {
+			redacted[k] = v.redact(v2)
+		}
+		return redacted
+
+	case []interface{}:
+		redacted := []interface{}{}
+		for _, v2 := range typedVal {
+			redacted = append(redacted, v.redact(v2))
+		}
+		return redacted
+
+	default:
+		return RedactedValue
+	}
+}
@@ -0,0 +1,66 @@
+package template_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/director/template"
+)
+
+var _ = Describe("RedactedVars", func() {
+	Describe("Get", func() {
+		It("returns redacted value if variable is found", func() {
+			vars := NewRedactedVars(StaticVariables{"key1": "secret"})
+
+			val, found, err := vars.Get(VariableDefinition{Name: "key1"})
+			Expect(val).To(Equal("<redacted>"))
+			Expect(found).To(BeTrue())
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("keeps structure of map and array values", func() {
+			vars := NewRedactedVars(StaticVariables{
+				"cert": map[interface{}]interface{}{
+					"ca":  "secret-ca",
+					"ips": []interface{}{"10.0.0.1"},
+				},
+			})
+
+			val, found, err := vars.Get(VariableDefinition{Name: "cert"})
+			Expect(val).To(Equal(map[interface{}]interface{}{
+				"ca":  "<redacted>",
+				"ips": []interface{}{"<redacted>"},
+			}))
+			Expect(found).To(BeTrue())
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns not found if variable is not found", func() {
+			vars := NewRedactedVars(StaticVariables{})
+
+			val, found, err := vars.Get(VariableDefinition{Name: "key1"})
+			Expect(val).To(BeNil())
+			Expect(found).To(BeFalse())
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns error from wrapped variables", func() {
+			vars := NewRedactedVars(&FakeVariables{GetErr: errors.New("fake-err")})
+
+			_, _, err := vars.Get(VariableDefinition{Name: "key1"})
+			Expect(err).To(Equal(errors.New("fake-err")))
+		})
+	})
+
+	Describe("List", func() {
+		It("returns definitions of wrapped variables", func() {
+			vars := NewRedactedVars(StaticVariables{"key1": "secret"})
+
+			defs, err := vars.List()
+			Expect(defs).To(Equal([]VariableDefinition{{Name: "key1"}}))
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+})
@@ -20,6 +20,10 @@ type EvaluateOpts struct {
 	ExpectAllVarsUsed     bool
 	PostVarSubstitutionOp patch.Op
 	UnescapedMultiline    bool
+
+	// ReportMissingLocations includes the paths referencing each missing variable
+	// in the error returned when ExpectAllKeys is set
+	ReportMissingLocations bool
 }
 
 func NewTemplate(bytes []byte) Template {
@@ -41,7 +45,10 @@ func (t Template) Evaluate(vars Variables, op patch.Op, opts EvaluateOpts) ([]by
 		}
 	}
 
-	obj, err = t.interpolateRoot(obj, newVarsTracker(vars, opts.ExpectAllKeys, opts.ExpectAllVarsUsed))
+	tracker := newVarsTracker(vars, opts.ExpectAllKeys, opts.ExpectAllVarsUsed)
+	tracker.reportMissingLocations = opts.ReportMissingLocations
+
+	obj, err = t.interpolateRoot(obj, tracker)
 	if err != nil {
 		return []byte{}, err
 	}
@@ -71,7 +78,7 @@ func (t Template) interpolateRoot(obj interface{}, tracker varsTracker) (interfa
 		return nil, err
 	}
 
-	obj, err = interpolator{}.Interpolate(obj, varsLookup{tracker})
+	obj, err = interpolator{}.Interpolate(obj, []patch.Token{patch.RootToken{}}, varsLookup{tracker})
 	if err != nil {
 		return nil, err
 	}
@@ -91,16 +98,20 @@ var (
 	interpolationAnchoredRegex = regexp.MustCompile("\\A" + interpolationRegex.String() + "\\z")
 )
 
-func (i interpolator) Interpolate(node interface{}, varsLookup varsLookup) (interface{}, error) {
+// Interpolate replaces variables in node; path are the tokens leading to node
+// and are used to report where missing variables are referenced
+func (i interpolator) Interpolate(node interface{}, path []patch.Token, varsLookup varsLookup) (interface{}, error) {
 	switch typedNode := node.(type) {
 	case map[interface{}]interface{}:
 		for k, v := range typedNode {
-			evaluatedValue, err := i.Interpolate(v, varsLookup)
+			valuePath := i.appendToken(path, patch.KeyToken{Key: fmt.Sprintf("%v", k)})
+
+			evaluatedValue, err := i.Interpolate(v, valuePath, varsLookup)
 			if err != nil {
 				return nil, err
 			}
 
-			evaluatedKey, err := i.Interpolate(k, varsLookup)
+			evaluatedKey, err := i.Interpolate(k, valuePath, varsLookup)
 			if err != nil {
 				return nil, err
 			}
@@ -112,7 +123,7 @@ func (i interpolator) Interpolate(node interface{}, varsLookup varsLookup) (inte
 	case []interface{}:
 		for idx, x := range typedNode {
 			var err error
-			typedNode[idx], err = i.Interpolate(x, varsLookup)
+			typedNode[idx], err = i.Interpolate(x, i.appendToken(path, i.indexToken(idx, x)), varsLookup)
 			if err != nil {
 				return nil, err
 			}
@@ -125,6 +136,10 @@ func (i interpolator) Interpolate(node interface{}, varsLookup varsLookup) (inte
 				return nil, bosherr.WrapErrorf(err, "Finding variable '%s'", name)
 			}
 
+			if !found {
+				varsLookup.missingAt(name, path)
+			}
+
 			if found {
 				// ensure that value type is preserved when replacing the entire field
 				if interpolationAnchoredRegex.MatchString(typedNode) {
@@ -149,6 +164,22 @@ func (i interpolator) Interpolate(node interface{}, varsLookup varsLookup) (inte
 	return node, nil
 }
 
+func (i interpolator) appendToken(path []patch.Token, token patch.Token) []patch.Token {
+	return append(append([]patch.Token{}, path...), token)
+}
+
+// indexToken refers to array items by name when they have one, e.g. /instance_groups/name=db,
+// since indexes are harder to relate to the manifest
+func (i interpolator) indexToken(idx int, item interface{}) patch.Token {
+	if typedItem, ok := item.(map[interface{}]interface{}); ok {
+		if name, ok := typedItem["name"].(string); ok && !interpolationRegex.MatchString(name) {
+			return patch.MatchingIndexToken{Key: "name", Value: name}
+		}
+	}
+
+	return patch.IndexToken{Index: idx}
+}
+
 func (i interpolator) extractVarNames(value string) []string {
 	var names []string
 
@@ -201,6 +232,9 @@ type varsTracker struct {
 	missing    map[string]struct{} // track missing var names
 	visited    map[string]struct{}
 	visitedAll map[string]struct{} // track all var names that were accessed
+
+	reportMissingLocations bool
+	missingLocations       map[string][]string // track paths referencing missing var names
 }
 
 func newVarsTracker(vars Variables, expectAllFound, expectAllUsed bool) varsTracker {
@@ -211,7 +245,25 @@ func newVarsTracker(vars Variables, expectAllFound, expectAllUsed bool) varsTrac
 		missing:        map[string]struct{}{},
 		visited:        map[string]struct{}{},
 		visitedAll:     map[string]struct{}{},
+
+		missingLocations: map[string][]string{},
+	}
+}
+
+func (t varsTracker) missingAt(name string, path []patch.Token) {
+	name = strings.Split(name, ".")[0]
+	location := patch.NewPointer(path).String()
+	if location == "" {
+		location = "/"
+	}
+
+	for _, existing := range t.missingLocations[name] {
+		if existing == location {
+			return
+		}
 	}
+
+	t.missingLocations[name] = append(t.missingLocations[name], location)
 }
 
 func (t varsTracker) Get(name string) (interface{}, bool, error) {
@@ -224,7 +276,7 @@ func (t varsTracker) Get(name string) (interface{}, bool, error) {
 
 	def := t.defs.Find(name)
 
-	def.Options, err = interpolator{}.Interpolate(def.Options, varsLookup{defVarTracker})
+	def.Options, err = interpolator{}.Interpolate(def.Options, []patch.Token{patch.RootToken{}}, varsLookup{defVarTracker})
 	if err != nil {
 		return nil, false, bosherr.WrapErrorf(err, "Interpolating variable '%s' definition options", name)
 	}
@@ -345,6 +397,10 @@ func (t varsTracker) MissingError() error {
 		return nil
 	}
 
+	if t.reportMissingLocations {
+		return bosherr.WrapError(t.locatedMultiErr(t.missing), "Expected to find variables")
+	}
+
 	return bosherr.WrapError(t.multiErr(t.missing), "Expected to find variables")
 }
 
@@ -387,6 +443,27 @@ func (t varsTracker) multiErr(mapWithNames map[string]struct{}) error {
 	return bosherr.NewMultiError(errs...)
 }
 
+func (t varsTracker) locatedMultiErr(mapWithNames map[string]struct{}) error {
+	var names []string
+	for name := range mapWithNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		locations := t.missingLocations[name]
+		if len(locations) == 0 {
+			errs = append(errs, bosherr.Error(name))
+			continue
+		}
+
+		sort.Strings(locations)
+		errs = append(errs, bosherr.Errorf("%s (referenced at %s)", name, strings.Join(locations, ", ")))
+	}
+	return bosherr.NewMultiError(errs...)
+}
+
 type varDefinitions struct {
 	Definitions []VariableDefinition `yaml:"variables"`
 }
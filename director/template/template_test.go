@@ -103,6 +103,34 @@ dup-key: ((key3))
 		Expect(err.Error()).To(Equal("Expected to find variables: key\nkey2\nkey4\nkey_in_array"))
 	})
 
+	It("returns the paths referencing missing variables if ReportMissingLocations is true", func() {
+		template := NewTemplate([]byte(`
+root: ((key))
+instance_groups:
+- name: db
+  properties:
+    password: ((key))
+    cert: ((cert.certificate))
+- properties:
+    url: http://((host)):8080
+`))
+		vars := StaticVariables{}
+
+		_, err := template.Evaluate(vars, nil, EvaluateOpts{ExpectAllKeys: true, ReportMissingLocations: true})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find variables: cert (referenced at /instance_groups/name=db/properties/cert)\n" +
+			"host (referenced at /instance_groups/1/properties/url)\n" +
+			"key (referenced at /instance_groups/name=db/properties/password, /root)"))
+	})
+
+	It("reports the root of the template as the location of a missing variable replacing the whole template", func() {
+		template := NewTemplate([]byte("((key))"))
+
+		_, err := template.Evaluate(StaticVariables{}, nil, EvaluateOpts{ExpectAllKeys: true, ReportMissingLocations: true})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected to find variables: key (referenced at /)"))
+	})
+
 	It("does not return error if there are missing variable keys and ExpectAllKeys is false", func() {
 		template := NewTemplate([]byte("((key)): ((key2))\n((key3)): 2"))
 		vars := StaticVariables{"key3": "foo"}
@@ -10,6 +10,7 @@ type VarsFileArg struct {
 	FS boshsys.FileSystem
 
 	Vars StaticVariables
+	Path string
 }
 
 func (a *VarsFileArg) UnmarshalFlag(filePath string) error {
@@ -30,6 +31,7 @@ func (a *VarsFileArg) UnmarshalFlag(filePath string) error {
 	}
 
 	(*a).Vars = vars
+	(*a).Path = filePath
 
 	return nil
 }
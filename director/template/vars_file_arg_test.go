@@ -33,6 +33,14 @@ var _ = Describe("VarsFileArg", func() {
 			}))
 		})
 
+		It("remembers the path of the file", func() {
+			fs.WriteFileString("/some/path", "name1: var1")
+
+			err := (&arg).UnmarshalFlag("/some/path")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(arg.Path).To(Equal("/some/path"))
+		})
+
 		It("returns objects", func() {
 			fs.WriteFileString("/some/path", "name1: \n  key: value")
 
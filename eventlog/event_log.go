@@ -0,0 +1,244 @@
+package eventlog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	boshtpl "github.com/cloudfoundry/bosh-cli/director/template"
+	biui "github.com/cloudfoundry/bosh-cli/ui"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+const redactedValue = "<redacted>"
+
+// urlCredentialsRegexp matches the user info of URLs, e.g. in mbus or blobstore endpoints
+var urlCredentialsRegexp = regexp.MustCompile(`://[^/\s@]+@`)
+
+// EventLog is everything needed to replay what a command showed while it ran
+type EventLog struct {
+	CLIVersion    string            `json:"cli_version"`
+	CorrelationID string            `json:"correlation_id"`
+	Command       string            `json:"command"`
+	Events        []biui.StageEvent `json:"events"`
+	Warnings      []biwarn.Warning  `json:"warnings"`
+	Error         string            `json:"error,omitempty"`
+}
+
+// Recorder collects stage events while a command runs and writes them,
+// together with the warnings and error of the command, to a gzipped JSON file.
+type Recorder interface {
+	biui.StageEventRecorder
+	Write(path string, warnings []biwarn.Warning, runErr error) error
+}
+
+type recorder struct {
+	command       string
+	cliVersion    string
+	correlationID string
+
+	vars   boshtpl.Variables
+	fs     boshsys.FileSystem
+	logger boshlog.Logger
+	logTag string
+
+	events []biui.StageEvent
+	lock   sync.Mutex
+}
+
+// NewRecorder returns a Recorder that redacts the values of vars,
+// and credentials in URLs, from everything it writes
+func NewRecorder(
+	command string,
+	cliVersion string,
+	correlationID string,
+	vars boshtpl.Variables,
+	fs boshsys.FileSystem,
+	logger boshlog.Logger,
+) Recorder {
+	return &recorder{
+		command:       command,
+		cliVersion:    cliVersion,
+		correlationID: correlationID,
+
+		vars:   vars,
+		fs:     fs,
+		logger: logger,
+		logTag: "eventLogRecorder",
+	}
+}
+
+func (r *recorder) RecordStageEvent(event biui.StageEvent) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) Write(path string, warnings []biwarn.Warning, runErr error) error {
+	r.lock.Lock()
+	events := append([]biui.StageEvent{}, r.events...)
+	r.lock.Unlock()
+
+	// Variables may have been generated while running, so secrets are collected only now
+	secrets, err := r.secrets()
+	if err != nil {
+		return bosherr.WrapError(err, "Collecting secrets to redact from event log")
+	}
+
+	redact := func(s string) string {
+		s = urlCredentialsRegexp.ReplaceAllString(s, "://"+redactedValue+"@")
+		for _, secret := range secrets {
+			s = strings.Replace(s, secret, redactedValue, -1)
+		}
+		return s
+	}
+
+	eventLog := EventLog{
+		CLIVersion:    r.cliVersion,
+		CorrelationID: r.correlationID,
+		Command:       r.command,
+		Events:        []biui.StageEvent{},
+		Warnings:      []biwarn.Warning{},
+	}
+
+	for _, event := range events {
+		stages := make([]string, len(event.Stages))
+		for i, name := range event.Stages {
+			stages[i] = redact(name)
+		}
+		event.Stages = stages
+		event.Message = redact(event.Message)
+
+		eventLog.Events = append(eventLog.Events, event)
+	}
+
+	for _, warning := range warnings {
+		eventLog.Warnings = append(eventLog.Warnings, biwarn.Warning{
+			Source:  warning.Source,
+			Message: redact(warning.Message),
+		})
+	}
+
+	if runErr != nil {
+		eventLog.Error = redact(runErr.Error())
+	}
+
+	eventLogBytes, err := json.Marshal(eventLog)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling event log")
+	}
+
+	var compressed bytes.Buffer
+
+	gzipWriter := gzip.NewWriter(&compressed)
+
+	_, err = gzipWriter.Write(eventLogBytes)
+	if err != nil {
+		return bosherr.WrapError(err, "Compressing event log")
+	}
+
+	err = gzipWriter.Close()
+	if err != nil {
+		return bosherr.WrapError(err, "Compressing event log")
+	}
+
+	err = r.fs.WriteFile(path, compressed.Bytes())
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing event log '%s'", path)
+	}
+
+	r.logger.Debug(r.logTag, "Wrote %d events to event log '%s'", len(eventLog.Events), path)
+
+	return nil
+}
+
+// secrets returns the string values of all variables, longest first so that
+// a secret containing another secret is redacted as a whole
+func (r *recorder) secrets() ([]string, error) {
+	if r.vars == nil {
+		return nil, nil
+	}
+
+	defs, err := r.vars.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var secrets []string
+
+	for _, def := range defs {
+		value, found, err := r.vars.Get(boshtpl.VariableDefinition{Name: def.Name})
+		if err != nil {
+			return nil, err
+		}
+
+		if found {
+			secrets = append(secrets, stringValues(value)...)
+		}
+	}
+
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+
+	return secrets, nil
+}
+
+func stringValues(value interface{}) []string {
+	var values []string
+
+	switch typedValue := value.(type) {
+	case string:
+		if typedValue != "" {
+			values = append(values, typedValue)
+		}
+	case map[interface{}]interface{}:
+		for _, nestedValue := range typedValue {
+			values = append(values, stringValues(nestedValue)...)
+		}
+	case map[string]interface{}:
+		for _, nestedValue := range typedValue {
+			values = append(values, stringValues(nestedValue)...)
+		}
+	case []interface{}:
+		for _, nestedValue := range typedValue {
+			values = append(values, stringValues(nestedValue)...)
+		}
+	}
+
+	return values
+}
+
+// Read returns the event log written to path by a Recorder
+func Read(fs boshsys.FileSystem, path string) (EventLog, error) {
+	compressed, err := fs.ReadFile(path)
+	if err != nil {
+		return EventLog{}, bosherr.WrapErrorf(err, "Reading event log '%s'", path)
+	}
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return EventLog{}, bosherr.WrapErrorf(err, "Decompressing event log '%s'", path)
+	}
+
+	eventLogBytes, err := ioutil.ReadAll(gzipReader)
+	if err != nil {
+		return EventLog{}, bosherr.WrapErrorf(err, "Decompressing event log '%s'", path)
+	}
+
+	var eventLog EventLog
+
+	err = json.Unmarshal(eventLogBytes, &eventLog)
+	if err != nil {
+		return EventLog{}, bosherr.WrapErrorf(err, "Unmarshalling event log '%s'", path)
+	}
+
+	return eventLog, nil
+}
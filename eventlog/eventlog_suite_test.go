@@ -0,0 +1,12 @@
+package eventlog_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestEventLog(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Event Log Suite")
+}
@@ -2,15 +2,25 @@ package index
 
 import (
 	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
+	"sync"
+	"time"
 
+	"code.cloudfoundry.org/clock"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
+
+	biutil "github.com/cloudfoundry/bosh-cli/common/util"
 )
 
 type FileIndex struct {
-	path string
-	fs   boshsys.FileSystem
+	path        string
+	fs          boshsys.FileSystem
+	lockTimeout time.Duration
+	timeService clock.Clock
 }
 
 type indexEntry struct {
@@ -18,11 +28,121 @@ type indexEntry struct {
 	Value json.RawMessage
 }
 
+// DefaultLockTimeout is how long index operations wait for other users of the index
+const DefaultLockTimeout = 1 * time.Minute
+
+const lockPollInterval = 50 * time.Millisecond
+
+// BusyError is returned when an index stays locked by another process,
+// or another index with the same path, for longer than the lock timeout
+type BusyError struct {
+	Path    string
+	Timeout time.Duration
+}
+
+func (e BusyError) Error() string {
+	return fmt.Sprintf("Index '%s' is busy: it was not released by other bosh commands within %s", e.Path, e.Timeout)
+}
+
+// fileIndexLocks serializes access to each index file so that indexes
+// sharing a path (e.g. a shared compiled package index) do not lose updates.
+// Other processes are kept out by locking a file next to the index.
+var fileIndexLocks = struct {
+	sync.Mutex
+	byPath map[string]*sync.RWMutex
+}{byPath: map[string]*sync.RWMutex{}}
+
 func NewFileIndex(path string, fs boshsys.FileSystem) FileIndex {
-	return FileIndex{path: path, fs: fs}
+	return FileIndex{path: path, fs: fs, lockTimeout: DefaultLockTimeout, timeService: clock.NewClock()}
+}
+
+// WithLockTimeout returns a copy of the index that gives up waiting for
+// other users of the index after timeout
+func (ri FileIndex) WithLockTimeout(timeout time.Duration) FileIndex {
+	ri.lockTimeout = timeout
+	return ri
+}
+
+// WithClock returns a copy of the index that waits for other users of the index on timeService
+func (ri FileIndex) WithClock(timeService clock.Clock) FileIndex {
+	ri.timeService = timeService
+	return ri
+}
+
+// lock takes a shared lock for reading when exclusive is false
+// so that commands that only read the index do not wait for each other
+func (ri FileIndex) lock(exclusive bool) (func(), error) {
+	fileIndexLocks.Lock()
+	pathLock, found := fileIndexLocks.byPath[ri.path]
+	if !found {
+		pathLock = &sync.RWMutex{}
+		fileIndexLocks.byPath[ri.path] = pathLock
+	}
+	fileIndexLocks.Unlock()
+
+	deadline := ri.timeService.Now().Add(ri.lockTimeout)
+
+	tryLock, unlock := pathLock.TryRLock, pathLock.RUnlock
+	if exclusive {
+		tryLock, unlock = pathLock.TryLock, pathLock.Unlock
+	}
+
+	for !tryLock() {
+		if ri.timeService.Now().After(deadline) {
+			return nil, BusyError{Path: ri.path, Timeout: ri.lockTimeout}
+		}
+
+		ri.timeService.Sleep(lockPollInterval)
+	}
+
+	lockPath := ri.path + ".lock"
+
+	err := ri.fs.MkdirAll(filepath.Dir(lockPath), os.ModePerm)
+	if err != nil {
+		unlock()
+		return nil, bosherr.WrapErrorf(err, "Creating index directory %s", filepath.Dir(lockPath))
+	}
+
+	processLock, err := ri.fs.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		unlock()
+		return nil, bosherr.WrapErrorf(err, "Opening index lock file %s", lockPath)
+	}
+
+	for {
+		locked, err := biutil.TryLockFile(processLock, exclusive)
+		if err != nil {
+			_ = processLock.Close()
+			unlock()
+			return nil, bosherr.WrapErrorf(err, "Locking index file %s", ri.path)
+		}
+
+		if locked {
+			break
+		}
+
+		if ri.timeService.Now().After(deadline) {
+			_ = processLock.Close()
+			unlock()
+			return nil, BusyError{Path: ri.path, Timeout: ri.lockTimeout}
+		}
+
+		ri.timeService.Sleep(lockPollInterval)
+	}
+
+	return func() {
+		_ = processLock.Close()
+		unlock()
+	}, nil
 }
 
 func (ri FileIndex) Find(key interface{}, value interface{}) error {
+	unlock, err := ri.lock(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	rawEntries, err := ri.readRawEntries()
 	if err != nil {
 		return err
@@ -48,6 +168,12 @@ func (ri FileIndex) Find(key interface{}, value interface{}) error {
 }
 
 func (ri FileIndex) Save(key interface{}, value interface{}) error {
+	unlock, err := ri.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	rawEntries, err := ri.readRawEntries()
 	if err != nil {
 		return err
@@ -89,6 +215,64 @@ func (ri FileIndex) Save(key interface{}, value interface{}) error {
 	return nil
 }
 
+func (ri FileIndex) Delete(key interface{}) error {
+	unlock, err := ri.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	rawEntries, err := ri.readRawEntries()
+	if err != nil {
+		return err
+	}
+
+	rawKey, err := ri.structToMap(key)
+	if err != nil {
+		return err
+	}
+
+	for i, rawEntry := range rawEntries {
+		if reflect.DeepEqual(rawEntry.Key, rawKey) {
+			rawEntries = append(rawEntries[:i], rawEntries[i+1:]...)
+			return ri.writeRawEntries(rawEntries)
+		}
+	}
+
+	return ErrNotFound
+}
+
+func (ri FileIndex) Keys(keysPtr interface{}) error {
+	unlock, err := ri.lock(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	rawEntries, err := ri.readRawEntries()
+	if err != nil {
+		return err
+	}
+
+	rawKeys := []map[string]interface{}{}
+
+	for _, rawEntry := range rawEntries {
+		rawKeys = append(rawKeys, rawEntry.Key)
+	}
+
+	bytes, err := json.Marshal(rawKeys)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling index keys")
+	}
+
+	err = json.Unmarshal(bytes, keysPtr)
+	if err != nil {
+		return bosherr.WrapError(err, "Unmarshalling index keys")
+	}
+
+	return nil
+}
+
 func (ri FileIndex) readRawEntries() ([]indexEntry, error) {
 	var entries []indexEntry
 
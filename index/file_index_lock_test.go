@@ -0,0 +1,163 @@
+//go:build !windows
+// +build !windows
+
+package index_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/index"
+)
+
+var _ = Describe("FileIndex locking", func() {
+	var (
+		fs            boshsys.FileSystem
+		dir           string
+		indexFilePath string
+	)
+
+	BeforeEach(func() {
+		fs = boshsys.NewOsFileSystem(boshlog.NewLogger(boshlog.LevelNone))
+
+		var err error
+		dir, err = fs.TempDir("file-index-lock")
+		Expect(err).ToNot(HaveOccurred())
+
+		indexFilePath = filepath.Join(dir, "index.json")
+	})
+
+	AfterEach(func() {
+		Expect(fs.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("waits while another process holds the lock file next to the index", func() {
+		otherProcessLock, err := os.OpenFile(indexFilePath+".lock", os.O_RDWR|os.O_CREATE, 0644)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(syscall.Flock(int(otherProcessLock.Fd()), syscall.LOCK_EX)).To(Succeed())
+
+		saved := make(chan error, 1)
+		go func() {
+			saved <- NewFileIndex(indexFilePath, fs).Save(Key{Key: "key-1"}, Value{Name: "value-1"})
+		}()
+
+		Consistently(saved).ShouldNot(Receive())
+
+		Expect(otherProcessLock.Close()).To(Succeed())
+		Eventually(saved).Should(Receive(BeNil()))
+
+		var value Value
+		Expect(NewFileIndex(indexFilePath, fs).Find(Key{Key: "key-1"}, &value)).To(Succeed())
+		Expect(value.Name).To(Equal("value-1"))
+	})
+
+	Context("when another process holds the lock longer than the lock timeout", func() {
+		var otherProcessLock *os.File
+
+		lockIndex := func(how int) {
+			var err error
+			otherProcessLock, err = os.OpenFile(indexFilePath+".lock", os.O_RDWR|os.O_CREATE, 0644)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(syscall.Flock(int(otherProcessLock.Fd()), how)).To(Succeed())
+		}
+
+		AfterEach(func() {
+			Expect(otherProcessLock.Close()).To(Succeed())
+		})
+
+		It("returns a busy error instead of waiting forever", func() {
+			lockIndex(syscall.LOCK_EX)
+
+			index := NewFileIndex(indexFilePath, fs).WithLockTimeout(100 * time.Millisecond)
+
+			err := index.Save(Key{Key: "key-1"}, Value{Name: "value-1"})
+			Expect(err).To(Equal(BusyError{Path: indexFilePath, Timeout: 100 * time.Millisecond}))
+			Expect(err.Error()).To(ContainSubstring("is busy"))
+
+			var value Value
+			Expect(index.Find(Key{Key: "key-1"}, &value)).To(BeAssignableToTypeOf(BusyError{}))
+		})
+
+		It("waits for the lock timeout on the given clock", func() {
+			lockIndex(syscall.LOCK_EX)
+
+			fakeClock := fakeclock.NewFakeClock(time.Now())
+			index := NewFileIndex(indexFilePath, fs).WithClock(fakeClock)
+
+			saved := make(chan error, 1)
+			go func() {
+				saved <- index.Save(Key{Key: "key-1"}, Value{Name: "value-1"})
+			}()
+
+			Consistently(saved).ShouldNot(Receive())
+
+			fakeClock.WaitForWatcherAndIncrement(DefaultLockTimeout + time.Second)
+			Eventually(saved).Should(Receive(Equal(BusyError{Path: indexFilePath, Timeout: DefaultLockTimeout})))
+		})
+
+		It("reads while the other process only reads but does not write", func() {
+			Expect(NewFileIndex(indexFilePath, fs).Save(Key{Key: "key-1"}, Value{Name: "value-1"})).To(Succeed())
+
+			lockIndex(syscall.LOCK_SH)
+
+			index := NewFileIndex(indexFilePath, fs).WithLockTimeout(100 * time.Millisecond)
+
+			var value Value
+			Expect(index.Find(Key{Key: "key-1"}, &value)).To(Succeed())
+			Expect(value.Name).To(Equal("value-1"))
+
+			var keys []Key
+			Expect(index.Keys(&keys)).To(Succeed())
+			Expect(keys).To(Equal([]Key{{Key: "key-1"}}))
+
+			Expect(index.Save(Key{Key: "key-2"}, Value{Name: "value-2"})).To(BeAssignableToTypeOf(BusyError{}))
+			Expect(index.Delete(Key{Key: "key-1"})).To(BeAssignableToTypeOf(BusyError{}))
+		})
+	})
+
+	It("does not lose updates of concurrent writers", func() {
+		writers := 20
+
+		var wg sync.WaitGroup
+		errs := make(chan error, writers)
+
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				errs <- NewFileIndex(indexFilePath, fs).Save(
+					Key{Key: fmt.Sprintf("key-%d", i)}, Value{Name: fmt.Sprintf("value-%d", i)})
+			}(i)
+		}
+
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		var keys []Key
+		Expect(NewFileIndex(indexFilePath, fs).Keys(&keys)).To(Succeed())
+		Expect(keys).To(HaveLen(writers))
+
+		for i := 0; i < writers; i++ {
+			var value Value
+			Expect(NewFileIndex(indexFilePath, fs).Find(Key{Key: fmt.Sprintf("key-%d", i)}, &value)).To(Succeed())
+			Expect(value.Name).To(Equal(fmt.Sprintf("value-%d", i)))
+		}
+	})
+})
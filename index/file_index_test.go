@@ -1,6 +1,9 @@
 package index_test
 
 import (
+	"strconv"
+	"sync"
+
 	. "github.com/cloudfoundry/bosh-cli/index"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
@@ -36,6 +39,9 @@ var _ = Describe("FileIndex", func() {
 	AfterEach(func() {
 		err := fs.RemoveAll(indexFilePath)
 		Expect(err).ToNot(HaveOccurred())
+
+		err = fs.RemoveAll(indexFilePath + ".lock")
+		Expect(err).ToNot(HaveOccurred())
 	})
 
 	Describe("Save/Find", func() {
@@ -171,4 +177,78 @@ var _ = Describe("FileIndex", func() {
 			})
 		})
 	})
+
+	Describe("Delete", func() {
+		It("removes the item saved with the key", func() {
+			err := index.Save(Key{Key: "key-1"}, Value{Name: "value-1", Count: 1})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = index.Save(Key{Key: "key-2"}, Value{Name: "value-2", Count: 2})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = index.Delete(Key{Key: "key-1"})
+			Expect(err).ToNot(HaveOccurred())
+
+			var value Value
+
+			err = index.Find(Key{Key: "key-1"}, &value)
+			Expect(err).To(Equal(ErrNotFound))
+
+			err = index.Find(Key{Key: "key-2"}, &value)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(Value{Name: "value-2", Count: 2}))
+		})
+
+		It("returns ErrNotFound if item is not found by key", func() {
+			err := index.Delete(Key{Key: "key-1"})
+			Expect(err).To(Equal(ErrNotFound))
+		})
+	})
+
+	Describe("Keys", func() {
+		It("returns keys of all saved items", func() {
+			err := index.Save(Key{Key: "key-1"}, Value{Name: "value-1", Count: 1})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = index.Save(Key{Key: "key-2"}, Value{Name: "value-2", Count: 2})
+			Expect(err).ToNot(HaveOccurred())
+
+			var keys []Key
+
+			err = index.Keys(&keys)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(keys).To(ConsistOf(Key{Key: "key-1"}, Key{Key: "key-2"}))
+		})
+
+		It("returns no keys if nothing was saved", func() {
+			var keys []Key
+
+			err := index.Keys(&keys)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(keys).To(BeEmpty())
+		})
+	})
+
+	It("does not lose updates when indexes sharing a path are saved concurrently", func() {
+		const count = 20
+		wg := sync.WaitGroup{}
+
+		for i := 0; i < count; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				err := NewFileIndex(indexFilePath, fs).Save(Key{Key: strconv.Itoa(i)}, Value{Count: float64(i)})
+				Expect(err).ToNot(HaveOccurred())
+			}(i)
+		}
+
+		wg.Wait()
+
+		var keys []Key
+		err := index.Keys(&keys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keys).To(HaveLen(count))
+	})
 })
@@ -2,6 +2,8 @@ package index
 
 import (
 	"encoding/json"
+	"sort"
+	"strings"
 
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 )
@@ -50,3 +52,35 @@ func (ri *inMemoryIndex) Save(key interface{}, value interface{}) error {
 
 	return nil
 }
+
+func (ri *inMemoryIndex) Delete(key interface{}) error {
+	keyBytes, err := json.Marshal(key)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Marshalling key %#v", key)
+	}
+
+	if _, exists := ri.entryMap[string(keyBytes)]; !exists {
+		return ErrNotFound
+	}
+
+	delete(ri.entryMap, string(keyBytes))
+
+	return nil
+}
+
+func (ri *inMemoryIndex) Keys(keysPtr interface{}) error {
+	keys := []string{}
+
+	for key := range ri.entryMap {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	err := json.Unmarshal([]byte("["+strings.Join(keys, ",")+"]"), keysPtr)
+	if err != nil {
+		return bosherr.WrapError(err, "Unmarshaling keys")
+	}
+
+	return nil
+}
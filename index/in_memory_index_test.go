@@ -152,4 +152,55 @@ var _ = Describe("InMemoryIndex", func() {
 			})
 		})
 	})
+
+	Describe("Delete", func() {
+		It("removes the item saved with the key", func() {
+			err := index.Save(Key{Key: "key-1"}, Value{Name: "value-1", Count: 1})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = index.Save(Key{Key: "key-2"}, Value{Name: "value-2", Count: 2})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = index.Delete(Key{Key: "key-1"})
+			Expect(err).ToNot(HaveOccurred())
+
+			var value Value
+
+			err = index.Find(Key{Key: "key-1"}, &value)
+			Expect(err).To(Equal(ErrNotFound))
+
+			err = index.Find(Key{Key: "key-2"}, &value)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(Value{Name: "value-2", Count: 2}))
+		})
+
+		It("returns ErrNotFound if item is not found by key", func() {
+			err := index.Delete(Key{Key: "key-1"})
+			Expect(err).To(Equal(ErrNotFound))
+		})
+	})
+
+	Describe("Keys", func() {
+		It("returns keys of all saved items", func() {
+			err := index.Save(Key{Key: "key-1"}, Value{Name: "value-1", Count: 1})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = index.Save(Key{Key: "key-2"}, Value{Name: "value-2", Count: 2})
+			Expect(err).ToNot(HaveOccurred())
+
+			var keys []Key
+
+			err = index.Keys(&keys)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(keys).To(ConsistOf(Key{Key: "key-1"}, Key{Key: "key-2"}))
+		})
+
+		It("returns no keys if nothing was saved", func() {
+			var keys []Key
+
+			err := index.Keys(&keys)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(keys).To(BeEmpty())
+		})
+	})
 })
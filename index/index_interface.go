@@ -11,4 +11,7 @@ var (
 type Index interface {
 	Find(interface{}, interface{}) error
 	Save(interface{}, interface{}) error
+	Delete(interface{}) error
+	// Keys unmarshals keys of all saved records into the given slice pointer
+	Keys(interface{}) error
 }
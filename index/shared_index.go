@@ -0,0 +1,104 @@
+package index
+
+import (
+	"path/filepath"
+	"sync"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// FileStore keeps files under names chosen by the caller, e.g. next to the blobs of a remote blobstore
+type FileStore interface {
+	// Download copies the file stored under name to path and returns false when there is none
+	Download(name string, path string) (bool, error)
+	Upload(name string, path string) error
+}
+
+type sharedIndex struct {
+	local Index
+	store FileStore
+	name  string
+	fs    boshsys.FileSystem
+
+	// saveLock keeps concurrent saves of this process from overwriting each other in the store
+	saveLock *sync.Mutex
+}
+
+// NewSharedIndex returns an index that keeps a copy of local in store under name, so that
+// other machines using the same store find the records saved on this one. Records missing
+// from local are looked up in the store and saved to local when found. Saves are written
+// to both by reading, updating and writing back the stored copy, so saves that race with
+// another machine may be lost from the store; they are still kept in local.
+// Delete and Keys only use local, so pruning leaves records in the store in place.
+func NewSharedIndex(local Index, store FileStore, name string, fs boshsys.FileSystem) Index {
+	return sharedIndex{local: local, store: store, name: name, fs: fs, saveLock: &sync.Mutex{}}
+}
+
+func (i sharedIndex) Find(key interface{}, value interface{}) error {
+	err := i.local.Find(key, value)
+	if err != ErrNotFound {
+		return err
+	}
+
+	err = i.withStoredIndex(false, func(stored Index) error {
+		return stored.Find(key, value)
+	})
+	if err != nil {
+		return err
+	}
+
+	return i.local.Save(key, value)
+}
+
+func (i sharedIndex) Save(key interface{}, value interface{}) error {
+	err := i.local.Save(key, value)
+	if err != nil {
+		return err
+	}
+
+	i.saveLock.Lock()
+	defer i.saveLock.Unlock()
+
+	return i.withStoredIndex(true, func(stored Index) error {
+		return stored.Save(key, value)
+	})
+}
+
+func (i sharedIndex) Delete(key interface{}) error {
+	return i.local.Delete(key)
+}
+
+func (i sharedIndex) Keys(keysPtr interface{}) error {
+	return i.local.Keys(keysPtr)
+}
+
+// withStoredIndex downloads the stored index to a temporary file index for use,
+// and uploads it afterwards when upload is true and use succeeded
+func (i sharedIndex) withStoredIndex(upload bool, use func(Index) error) error {
+	dir, err := i.fs.TempDir("bosh-shared-index")
+	if err != nil {
+		return bosherr.WrapError(err, "Creating temporary directory for shared index")
+	}
+
+	defer i.fs.RemoveAll(dir)
+
+	path := filepath.Join(dir, "index.json")
+
+	_, err = i.store.Download(i.name, path)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Downloading shared index '%s'", i.name)
+	}
+
+	err = use(NewFileIndex(path, i.fs))
+	if err != nil || !upload {
+		return err
+	}
+
+	err = i.store.Upload(i.name, path)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Uploading shared index '%s'", i.name)
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package index_test
+
+import (
+	"errors"
+
+	. "github.com/cloudfoundry/bosh-cli/index"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type memoryFileStore struct {
+	fs          boshsys.FileSystem
+	files       map[string][]byte
+	uploadError error
+}
+
+func (s *memoryFileStore) Download(name string, path string) (bool, error) {
+	contents, found := s.files[name]
+	if !found {
+		return false, nil
+	}
+
+	return true, s.fs.WriteFile(path, contents)
+}
+
+func (s *memoryFileStore) Upload(name string, path string) error {
+	if s.uploadError != nil {
+		return s.uploadError
+	}
+
+	contents, err := s.fs.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	s.files[name] = contents
+
+	return nil
+}
+
+var _ = Describe("SharedIndex", func() {
+	var (
+		fs    boshsys.FileSystem
+		store *memoryFileStore
+		local Index
+		index Index
+	)
+
+	BeforeEach(func() {
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		fs = boshsys.NewOsFileSystem(logger)
+
+		store = &memoryFileStore{fs: fs, files: map[string][]byte{}}
+		local = NewInMemoryIndex()
+		index = NewSharedIndex(local, store, "fake-index.json", fs)
+	})
+
+	otherMachineIndex := func() Index {
+		return NewSharedIndex(NewInMemoryIndex(), store, "fake-index.json", fs)
+	}
+
+	It("finds records saved on other machines and keeps them locally", func() {
+		err := otherMachineIndex().Save(Key{Key: "key"}, Value{Name: "value"})
+		Expect(err).ToNot(HaveOccurred())
+
+		var value Value
+		err = index.Find(Key{Key: "key"}, &value)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal(Value{Name: "value"}))
+
+		delete(store.files, "fake-index.json")
+
+		value = Value{}
+		err = local.Find(Key{Key: "key"}, &value)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal(Value{Name: "value"}))
+	})
+
+	It("keeps the records saved on every machine in the store", func() {
+		err := otherMachineIndex().Save(Key{Key: "other-key"}, Value{Name: "other-value"})
+		Expect(err).ToNot(HaveOccurred())
+
+		err = index.Save(Key{Key: "key"}, Value{Name: "value"})
+		Expect(err).ToNot(HaveOccurred())
+
+		var value Value
+		err = otherMachineIndex().Find(Key{Key: "key"}, &value)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal(Value{Name: "value"}))
+
+		err = otherMachineIndex().Find(Key{Key: "other-key"}, &value)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal(Value{Name: "other-value"}))
+	})
+
+	It("returns not found when neither the local index nor the store have the record", func() {
+		var value Value
+		err := index.Find(Key{Key: "key"}, &value)
+		Expect(err).To(Equal(ErrNotFound))
+	})
+
+	It("returns an error when the store cannot be updated, keeping the record locally", func() {
+		store.uploadError = errors.New("fake-upload-err")
+
+		err := index.Save(Key{Key: "key"}, Value{Name: "value"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Uploading shared index 'fake-index.json': fake-upload-err"))
+
+		var value Value
+		err = local.Find(Key{Key: "key"}, &value)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("only deletes records locally", func() {
+		err := index.Save(Key{Key: "key"}, Value{Name: "value"})
+		Expect(err).ToNot(HaveOccurred())
+
+		err = index.Delete(Key{Key: "key"})
+		Expect(err).ToNot(HaveOccurred())
+
+		var keys []Key
+		err = index.Keys(&keys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keys).To(BeEmpty())
+
+		var value Value
+		err = otherMachineIndex().Find(Key{Key: "key"}, &value)
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
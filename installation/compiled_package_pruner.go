@@ -0,0 +1,79 @@
+package installation
+
+import (
+	"path/filepath"
+
+	bistatepkg "github.com/cloudfoundry/bosh-cli/state/pkg"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+type CompiledPackagePruner interface {
+	// Prune deletes compiled packages that are not referenced by inUse
+	// and returns number of reclaimed bytes
+	Prune(inUse []CompiledPackageRef) (int64, error)
+}
+
+type compiledPackagePruner struct {
+	compiledPackageRepo bistatepkg.CompiledPackageRepo
+	blobstorePath       string
+	fs                  boshsys.FileSystem
+	logger              boshlog.Logger
+	logTag              string
+}
+
+func NewCompiledPackagePruner(
+	compiledPackageRepo bistatepkg.CompiledPackageRepo,
+	blobstorePath string,
+	fs boshsys.FileSystem,
+	logger boshlog.Logger,
+) CompiledPackagePruner {
+	return &compiledPackagePruner{
+		compiledPackageRepo: compiledPackageRepo,
+		blobstorePath:       blobstorePath,
+		fs:                  fs,
+		logger:              logger,
+		logTag:              "compiledPackagePruner",
+	}
+}
+
+func (p *compiledPackagePruner) Prune(inUse []CompiledPackageRef) (int64, error) {
+	var blobIDs []string
+
+	for _, ref := range inUse {
+		blobIDs = append(blobIDs, ref.BlobstoreID)
+	}
+
+	prunedRecords, err := p.compiledPackageRepo.Prune(blobIDs)
+	if err != nil {
+		return 0, bosherr.WrapError(err, "Pruning compiled packages")
+	}
+
+	var reclaimed int64
+
+	for _, record := range prunedRecords {
+		blobPath := filepath.Join(p.blobstorePath, record.BlobID)
+
+		if !p.fs.FileExists(blobPath) {
+			p.logger.Warn(p.logTag, "Skipping missing compiled package blob '%s'", record.BlobID)
+			continue
+		}
+
+		fileInfo, err := p.fs.Stat(blobPath)
+		if err != nil {
+			return reclaimed, bosherr.WrapErrorf(err, "Checking compiled package blob '%s'", record.BlobID)
+		}
+
+		p.logger.Debug(p.logTag, "Deleting compiled package blob '%s'", record.BlobID)
+
+		err = p.fs.RemoveAll(blobPath)
+		if err != nil {
+			return reclaimed, bosherr.WrapErrorf(err, "Deleting compiled package blob '%s'", record.BlobID)
+		}
+
+		reclaimed += fileInfo.Size()
+	}
+
+	return reclaimed, nil
+}
@@ -0,0 +1,90 @@
+package installation_test
+
+import (
+	"errors"
+
+	. "github.com/cloudfoundry/bosh-cli/installation"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	mock_state_pkg "github.com/cloudfoundry/bosh-cli/state/pkg/mocks"
+	"github.com/golang/mock/gomock"
+
+	bistatepkg "github.com/cloudfoundry/bosh-cli/state/pkg"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+)
+
+var _ = Describe("CompiledPackagePruner", func() {
+	var (
+		mockCtrl                *gomock.Controller
+		mockCompiledPackageRepo *mock_state_pkg.MockCompiledPackageRepo
+		fs                      *fakesys.FakeFileSystem
+		pruner                  CompiledPackagePruner
+		inUse                   []CompiledPackageRef
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockCompiledPackageRepo = mock_state_pkg.NewMockCompiledPackageRepo(mockCtrl)
+		fs = fakesys.NewFakeFileSystem()
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+
+		pruner = NewCompiledPackagePruner(mockCompiledPackageRepo, "/blobs", fs, logger)
+
+		inUse = []CompiledPackageRef{
+			{Name: "kept-pkg", Version: "kept-fp", BlobstoreID: "kept-blob-id", SHA1: "kept-sha1"},
+		}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("keeps blobs of packages in use and deletes blobs of pruned packages", func() {
+		Expect(fs.WriteFileString("/blobs/kept-blob-id", "kept-contents")).To(Succeed())
+		Expect(fs.WriteFileString("/blobs/pruned-blob-id", "pruned-contents")).To(Succeed())
+
+		mockCompiledPackageRepo.EXPECT().Prune([]string{"kept-blob-id"}).Return([]bistatepkg.CompiledPackageRecord{
+			{BlobID: "pruned-blob-id", BlobSHA1: "pruned-sha1"},
+		}, nil)
+
+		reclaimed, err := pruner.Prune(inUse)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reclaimed).To(Equal(int64(len("pruned-contents"))))
+
+		Expect(fs.FileExists("/blobs/kept-blob-id")).To(BeTrue())
+		Expect(fs.FileExists("/blobs/pruned-blob-id")).To(BeFalse())
+	})
+
+	It("skips pruned packages whose blobs are already missing", func() {
+		mockCompiledPackageRepo.EXPECT().Prune([]string{"kept-blob-id"}).Return([]bistatepkg.CompiledPackageRecord{
+			{BlobID: "missing-blob-id", BlobSHA1: "missing-sha1"},
+		}, nil)
+
+		reclaimed, err := pruner.Prune(inUse)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reclaimed).To(Equal(int64(0)))
+	})
+
+	It("returns an error when pruning the compiled package repo fails", func() {
+		mockCompiledPackageRepo.EXPECT().Prune([]string{"kept-blob-id"}).Return(nil, errors.New("fake-prune-err"))
+
+		_, err := pruner.Prune(inUse)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Pruning compiled packages: fake-prune-err"))
+	})
+
+	It("returns an error when deleting a blob fails", func() {
+		Expect(fs.WriteFileString("/blobs/pruned-blob-id", "pruned-contents")).To(Succeed())
+		fs.RemoveAllStub = func(string) error { return errors.New("fake-remove-err") }
+
+		mockCompiledPackageRepo.EXPECT().Prune([]string{"kept-blob-id"}).Return([]bistatepkg.CompiledPackageRecord{
+			{BlobID: "pruned-blob-id", BlobSHA1: "pruned-sha1"},
+		}, nil)
+
+		_, err := pruner.Prune(inUse)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Deleting compiled package blob 'pruned-blob-id'"))
+	})
+})
@@ -11,6 +11,9 @@ import (
 type Installation interface {
 	Target() Target
 	Job() InstalledJob
+	CompiledPackages() []CompiledPackageRef
+	ExpectedCPIDigest() string
+	CPIAPIVersion() int
 	WithRunningRegistry(boshlog.Logger, biui.Stage, func() error) error
 	StartRegistry() error
 	StopRegistry() error
@@ -19,6 +22,7 @@ type Installation interface {
 type installation struct {
 	target                Target
 	job                   InstalledJob
+	compiledPackages      []CompiledPackageRef
 	manifest              biinstallmanifest.Manifest
 	registryServerManager biregistry.ServerManager
 
@@ -28,12 +32,14 @@ type installation struct {
 func NewInstallation(
 	target Target,
 	job InstalledJob,
+	compiledPackages []CompiledPackageRef,
 	manifest biinstallmanifest.Manifest,
 	registryServerManager biregistry.ServerManager,
 ) Installation {
 	return &installation{
 		target:                target,
 		job:                   job,
+		compiledPackages:      compiledPackages,
 		manifest:              manifest,
 		registryServerManager: registryServerManager,
 	}
@@ -47,6 +53,20 @@ func (i *installation) Job() InstalledJob {
 	return i.job
 }
 
+func (i *installation) CompiledPackages() []CompiledPackageRef {
+	return i.compiledPackages
+}
+
+// ExpectedCPIDigest returns the digest the CPI executable must match, or "" when verification is disabled.
+func (i *installation) ExpectedCPIDigest() string {
+	return i.manifest.CPIDigest
+}
+
+// CPIAPIVersion returns the CPI API version pinned by the manifest, or 0 to use version 1.
+func (i *installation) CPIAPIVersion() int {
+	return i.manifest.CPIAPIVersion
+}
+
 func (i *installation) WithRunningRegistry(logger boshlog.Logger, stage biui.Stage, fn func() error) error {
 	err := stage.Perform("Starting registry", func() error {
 		return i.StartRegistry()
@@ -73,7 +93,18 @@ func (i *installation) StartRegistry() error {
 			return bosherr.Error("Registry already started")
 		}
 		config := i.manifest.Registry
-		registryServer, err := i.registryServerManager.Start(config.Username, config.Password, config.Host, config.Port)
+
+		var registryServer biregistry.Server
+		var err error
+		if config.TLS.IsEnabled() {
+			registryServer, err = i.registryServerManager.StartTLS(config.Username, config.Password, config.Host, config.Port, biregistry.RegistryTLSConfig{
+				Certificate: []byte(config.TLS.Certificate),
+				PrivateKey:  []byte(config.TLS.PrivateKey),
+				CA:          []byte(config.TLS.CA),
+			})
+		} else {
+			registryServer, err = i.registryServerManager.Start(config.Username, config.Password, config.Host, config.Port)
+		}
 		if err != nil {
 			return bosherr.WrapError(err, "Starting registry")
 		}
@@ -7,6 +7,7 @@ import (
 	. "github.com/cloudfoundry/bosh-cli/installation"
 
 	biinstallmanifest "github.com/cloudfoundry/bosh-cli/installation/manifest"
+	biregistry "github.com/cloudfoundry/bosh-cli/registry"
 	mock_registry "github.com/cloudfoundry/bosh-cli/registry/mocks"
 	test_support_mocks "github.com/cloudfoundry/bosh-cli/test_support/mocks"
 	fakebiui "github.com/cloudfoundry/bosh-cli/ui/fakes"
@@ -38,7 +39,7 @@ var _ = Describe("Installation", func() {
 	)
 
 	var newInstalation = func() Installation {
-		return NewInstallation(target, installedJob, manifest, mockRegistryServerManager)
+		return NewInstallation(target, installedJob, nil, manifest, mockRegistryServerManager)
 	}
 
 	BeforeEach(func() {
@@ -190,6 +191,23 @@ var _ = Describe("Installation", func() {
 				Expect(err).NotTo(HaveOccurred())
 			})
 
+			It("starts the registry over TLS when it has a certificate", func() {
+				manifest.Registry.TLS = biinstallmanifest.RegistryTLS{
+					Certificate: "fake-certificate",
+					PrivateKey:  "fake-private-key",
+					CA:          "fake-ca",
+				}
+
+				mockRegistryServerManager.EXPECT().StartTLS("fake-username", "fake-password", "fake-host", 123, biregistry.RegistryTLSConfig{
+					Certificate: []byte("fake-certificate"),
+					PrivateKey:  []byte("fake-private-key"),
+					CA:          []byte("fake-ca"),
+				}).Return(mockRegistryServer, nil)
+
+				err := newInstalation().StartRegistry()
+				Expect(err).NotTo(HaveOccurred())
+			})
+
 			Context("when starting registry fails", func() {
 				BeforeEach(func() {
 					mockRegistryServerManager.EXPECT().Start("fake-username", "fake-password", "fake-host", 123).Return(nil, errors.New("fake-registry-start-error"))
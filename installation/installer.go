@@ -24,6 +24,7 @@ func NewInstalledJob(ref RenderedJobRef, path string) InstalledJob {
 type Installer interface {
 	Install(biinstallmanifest.Manifest, biui.Stage) (Installation, error)
 	Cleanup(Installation) error
+	PruneCompiledPackages(Installation) (int64, error)
 }
 
 type installer struct {
@@ -31,6 +32,7 @@ type installer struct {
 	jobRenderer           JobRenderer
 	jobResolver           JobResolver
 	packageCompiler       PackageCompiler
+	compiledPackagePruner CompiledPackagePruner
 	blobExtractor         blobextract.Extractor
 	registryServerManager biregistry.ServerManager
 	logger                boshlog.Logger
@@ -42,6 +44,7 @@ func NewInstaller(
 	jobRenderer JobRenderer,
 	jobResolver JobResolver,
 	packageCompiler PackageCompiler,
+	compiledPackagePruner CompiledPackagePruner,
 	blobExtractor blobextract.Extractor,
 	registryServerManager biregistry.ServerManager,
 	logger boshlog.Logger,
@@ -51,6 +54,7 @@ func NewInstaller(
 		jobRenderer:           jobRenderer,
 		jobResolver:           jobResolver,
 		packageCompiler:       packageCompiler,
+		compiledPackagePruner: compiledPackagePruner,
 		blobExtractor:         blobExtractor,
 		registryServerManager: registryServerManager,
 		logger:                logger,
@@ -93,6 +97,7 @@ func (i *installer) Install(manifest biinstallmanifest.Manifest, stage biui.Stag
 	return NewInstallation(
 		i.target,
 		installedJob,
+		compiledPackages,
 		manifest,
 		i.registryServerManager,
 	), nil
@@ -103,6 +108,10 @@ func (i *installer) Cleanup(installation Installation) error {
 	return i.blobExtractor.Cleanup(job.BlobstoreID, job.Path)
 }
 
+func (i *installer) PruneCompiledPackages(installation Installation) (int64, error) {
+	return i.compiledPackagePruner.Prune(installation.CompiledPackages())
+}
+
 func (i *installer) installPackages(compiledPackages []CompiledPackageRef) error {
 	for _, pkg := range compiledPackages {
 		err := i.blobExtractor.Extract(pkg.BlobstoreID, pkg.SHA1, filepath.Join(i.target.PackagesPath(), pkg.Name))
@@ -34,8 +34,18 @@ type installerFactory struct {
 	logTag                 string
 	fs                     boshsys.FileSystem
 	digestCreateAlgorithms []boshcrypto.Algorithm
+	streamCompileLogs      bool
+
+	deterministicCompiledPackages bool
+	compileParallel               int
+	blobstore                     boshblob.Blobstore
+	compiledPackageIndexStore     biindex.FileStore
 }
 
+// NewInstallerFactory returns an InstallerFactory whose installers keep compiled packages
+// and rendered jobs in blobstore, or in the local blobstore of their target when it is nil.
+// When compiledPackageIndexStore is not nil, the compiled package index is shared through it
+// so that other machines using the same blobstore find the packages compiled here.
 func NewInstallerFactory(
 	ui biui.UI,
 	runner boshsys.CmdRunner,
@@ -46,31 +56,55 @@ func NewInstallerFactory(
 	logger boshlog.Logger,
 	fs boshsys.FileSystem,
 	digestCreateAlgorithms []boshcrypto.Algorithm,
+	streamCompileLogs bool,
+	deterministicCompiledPackages bool,
+	compileParallel int,
+	blobstore boshblob.Blobstore,
+	compiledPackageIndexStore biindex.FileStore,
 ) InstallerFactory {
 	return &installerFactory{
-		ui:                    ui,
-		runner:                runner,
-		extractor:             extractor,
-		releaseJobResolver:    releaseJobResolver,
-		uuidGenerator:         uuidGenerator,
-		registryServerManager: registryServerManager,
-		logger:                logger,
-		logTag:                "installer",
-		fs:                    fs,
+		ui:                     ui,
+		runner:                 runner,
+		extractor:              extractor,
+		releaseJobResolver:     releaseJobResolver,
+		uuidGenerator:          uuidGenerator,
+		registryServerManager:  registryServerManager,
+		logger:                 logger,
+		logTag:                 "installer",
+		fs:                     fs,
 		digestCreateAlgorithms: digestCreateAlgorithms,
+		streamCompileLogs:      streamCompileLogs,
+
+		deterministicCompiledPackages: deterministicCompiledPackages,
+		compileParallel:               compileParallel,
+		blobstore:                     blobstore,
+		compiledPackageIndexStore:     compiledPackageIndexStore,
 	}
 }
 
 func (f *installerFactory) NewInstaller(target Target) Installer {
 	context := &installerFactoryContext{
-		target:             target,
-		runner:             f.runner,
-		logger:             f.logger,
-		extractor:          f.extractor,
-		uuidGenerator:      f.uuidGenerator,
-		releaseJobResolver: f.releaseJobResolver,
-		fs:                 f.fs,
+		target:                 target,
+		runner:                 f.runner,
+		logger:                 f.logger,
+		extractor:              f.extractor,
+		uuidGenerator:          f.uuidGenerator,
+		releaseJobResolver:     f.releaseJobResolver,
+		fs:                     f.fs,
 		digestCreateAlgorithms: f.digestCreateAlgorithms,
+
+		compiledPackageCompressor: f.extractor,
+		compileParallel:           f.compileParallel,
+		baseBlobstore:             f.blobstore,
+		indexStore:                f.compiledPackageIndexStore,
+	}
+
+	if f.streamCompileLogs {
+		context.compileLogUI = f.ui
+	}
+
+	if f.deterministicCompiledPackages {
+		context.compiledPackageCompressor = biinstallpkg.NewDeterministicCompressor(f.extractor, f.fs)
 	}
 
 	return NewInstaller(
@@ -78,12 +112,16 @@ func (f *installerFactory) NewInstaller(target Target) Installer {
 		context.JobRenderer(),
 		context.JobResolver(),
 		context.PackageCompiler(),
+		context.CompiledPackagePruner(),
 		context.BlobExtractor(),
 		f.registryServerManager,
 		f.logger,
 	)
 }
 
+// SharedCompiledPackageIndexName is the name of the compiled package index next to the blobs of a shared blobstore
+const SharedCompiledPackageIndexName = "compiled-package-index.json"
+
 type installerFactoryContext struct {
 	target             Target
 	fs                 boshsys.FileSystem
@@ -92,6 +130,12 @@ type installerFactoryContext struct {
 	extractor          boshcmd.Compressor
 	uuidGenerator      boshuuid.Generator
 	releaseJobResolver bideplrel.JobResolver
+	compileLogUI       biui.UI
+
+	compiledPackageCompressor boshcmd.Compressor
+	compileParallel           int
+	baseBlobstore             boshblob.Blobstore
+	indexStore                biindex.FileStore
 
 	jobDependencyCompiler  bistatejob.DependencyCompiler
 	packageCompiler        bistatepkg.Compiler
@@ -130,8 +174,9 @@ func (c *installerFactoryContext) JobDependencyCompiler() bistatejob.DependencyC
 		return c.jobDependencyCompiler
 	}
 
-	c.jobDependencyCompiler = bistatejob.NewDependencyCompiler(
+	c.jobDependencyCompiler = bistatejob.NewParallelDependencyCompiler(
 		c.InstallationStatePackageCompiler(),
+		c.compileParallel,
 		c.logger,
 	)
 
@@ -147,10 +192,11 @@ func (c *installerFactoryContext) InstallationStatePackageCompiler() bistatepkg.
 		c.runner,
 		c.target.PackagesPath(),
 		c.fs,
-		c.extractor,
+		c.compiledPackageCompressor,
 		c.Blobstore(),
 		c.CompiledPackageRepo(),
 		c.BlobExtractor(),
+		c.compileLogUI,
 		c.logger,
 	)
 
@@ -162,9 +208,13 @@ func (c *installerFactoryContext) Blobstore() boshblob.DigestBlobstore {
 		return c.blobstore
 	}
 
-	options := map[string]interface{}{"blobstore_path": c.target.BlobstorePath()}
-	localBlobstore := boshblob.NewLocalBlobstore(c.fs, c.uuidGenerator, options)
-	c.blobstore = boshblob.NewDigestVerifiableBlobstore(localBlobstore, c.fs, c.digestCreateAlgorithms)
+	baseBlobstore := c.baseBlobstore
+	if baseBlobstore == nil {
+		options := map[string]interface{}{"blobstore_path": c.target.BlobstorePath()}
+		baseBlobstore = boshblob.NewLocalBlobstore(c.fs, c.uuidGenerator, options)
+	}
+
+	c.blobstore = boshblob.NewDigestVerifiableBlobstore(baseBlobstore, c.fs, c.digestCreateAlgorithms)
 
 	return c.blobstore
 }
@@ -184,8 +234,21 @@ func (c *installerFactoryContext) CompiledPackageRepo() bistatepkg.CompiledPacka
 		return c.compiledPackageRepo
 	}
 
-	compiledPackageIndex := biindex.NewFileIndex(c.target.CompiledPackagedIndexPath(), c.fs)
+	var compiledPackageIndex biindex.Index = biindex.NewFileIndex(c.target.CompiledPackagedIndexPath(), c.fs)
+	if c.indexStore != nil {
+		compiledPackageIndex = biindex.NewSharedIndex(compiledPackageIndex, c.indexStore, SharedCompiledPackageIndexName, c.fs)
+	}
+
 	c.compiledPackageRepo = bistatepkg.NewCompiledPackageRepo(compiledPackageIndex)
 
 	return c.compiledPackageRepo
 }
+
+func (c *installerFactoryContext) CompiledPackagePruner() CompiledPackagePruner {
+	return NewCompiledPackagePruner(
+		c.CompiledPackageRepo(),
+		c.target.BlobstorePath(),
+		c.fs,
+		c.logger,
+	)
+}
@@ -36,6 +36,7 @@ var _ = Describe("Installer", func() {
 		mockJobRenderer           *mock_install.MockJobRenderer
 		mockJobResolver           *mock_install.MockJobResolver
 		mockPackageCompiler       *mock_install.MockPackageCompiler
+		mockPruner                *mock_install.MockCompiledPackagePruner
 		fakeExtractor             *fakeblobextract.FakeExtractor
 		mockRegistryServerManager *mock_registry.MockServerManager
 
@@ -52,6 +53,7 @@ var _ = Describe("Installer", func() {
 		mockJobRenderer = mock_install.NewMockJobRenderer(mockCtrl)
 		mockJobResolver = mock_install.NewMockJobResolver(mockCtrl)
 		mockPackageCompiler = mock_install.NewMockPackageCompiler(mockCtrl)
+		mockPruner = mock_install.NewMockCompiledPackagePruner(mockCtrl)
 		fakeExtractor = &fakeblobextract.FakeExtractor{}
 		mockRegistryServerManager = mock_registry.NewMockServerManager(mockCtrl)
 
@@ -70,6 +72,7 @@ var _ = Describe("Installer", func() {
 			mockJobRenderer,
 			mockJobResolver,
 			mockPackageCompiler,
+			mockPruner,
 			fakeExtractor,
 			mockRegistryServerManager,
 			logger,
@@ -144,6 +147,7 @@ var _ = Describe("Installer", func() {
 			installation = NewInstallation(
 				target,
 				installedJob,
+				nil,
 				installationManifest,
 				mockRegistryServerManager,
 			)
@@ -168,4 +172,41 @@ var _ = Describe("Installer", func() {
 			Expect(err.Error()).To(ContainSubstring("nope"))
 		})
 	})
+
+	Describe("PruneCompiledPackages", func() {
+		var (
+			installation     Installation
+			compiledPackages []CompiledPackageRef
+		)
+
+		BeforeEach(func() {
+			compiledPackages = []CompiledPackageRef{
+				{Name: "fake-pkg-name", Version: "fake-pkg-fp", BlobstoreID: "fake-blob-id", SHA1: "fake-sha1"},
+			}
+
+			installation = NewInstallation(
+				target,
+				installedJob,
+				compiledPackages,
+				installationManifest,
+				mockRegistryServerManager,
+			)
+		})
+
+		It("prunes compiled packages not used by the installation", func() {
+			mockPruner.EXPECT().Prune(compiledPackages).Return(int64(1024), nil)
+
+			reclaimed, err := installer.PruneCompiledPackages(installation)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reclaimed).To(Equal(int64(1024)))
+		})
+
+		It("returns errors when pruning fails", func() {
+			mockPruner.EXPECT().Prune(compiledPackages).Return(int64(0), errors.New("fake-prune-err"))
+
+			_, err := installer.PruneCompiledPackages(installation)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-prune-err"))
+		})
+	})
 })
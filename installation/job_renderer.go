@@ -67,6 +67,10 @@ func (b *jobRenderer) RenderAndUploadFrom(installationManifest biinstallmanifest
 	return renderedJobRefs, nil
 }
 
+// installationInstance has a fixed ID like deployed instances so that
+// rendering the same installation jobs again renders the same templates
+var installationInstance = bitemplate.InstanceSpec{ID: "0", Bootstrap: true}
+
 // renderJobTemplates renders all the release job templates for multiple release jobs specified
 // by a deployment job and randomly uploads them to blobstore
 func (b *jobRenderer) renderJobTemplates(
@@ -79,7 +83,7 @@ func (b *jobRenderer) renderJobTemplates(
 ) ([]RenderedJobRef, error) {
 	renderedJobRefs := make([]RenderedJobRef, 0, len(releaseJobs))
 	err := stage.Perform("Rendering job templates", func() error {
-		renderedJobList, err := b.jobListRenderer.Render(releaseJobs, releaseJobProperties, jobProperties, globalProperties, deploymentName, "")
+		renderedJobList, err := b.jobListRenderer.Render(releaseJobs, releaseJobProperties, jobProperties, globalProperties, deploymentName, "", installationInstance)
 		if err != nil {
 			return err
 		}
@@ -1,6 +1,11 @@
 package manifest
 
 import (
+	"net"
+	"net/url"
+	"strconv"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	biproperty "github.com/cloudfoundry/bosh-utils/property"
 )
 
@@ -11,6 +16,14 @@ type Manifest struct {
 	Mbus       string
 	Cert       Certificate
 	Registry   Registry
+
+	// CPIDigest is the expected SHA256 digest of the CPI executable.
+	// Verification is skipped when it is empty.
+	CPIDigest string
+
+	// CPIAPIVersion pins the version of the CPI API requests are made with.
+	// Version 1 is used when it is 0.
+	CPIAPIVersion int
 }
 
 type Certificate struct {
@@ -28,18 +41,72 @@ type Registry struct {
 	Host      string
 	Port      int
 	SSHTunnel SSHTunnel
+
+	// AdvertisedHost and AdvertisedPort are what the agent is told to connect to
+	// when the registry is reachable at a different address than it binds to, e.g. behind NAT.
+	AdvertisedHost string
+	AdvertisedPort int
+
+	// TLS makes the registry serve HTTPS when it has a certificate
+	TLS RegistryTLS
+}
+
+// RegistryTLS holds the PEM encoded credentials the registry serves HTTPS with.
+// Agents must present a certificate signed by CA when it is set.
+type RegistryTLS struct {
+	Certificate string
+	PrivateKey  string `yaml:"private_key"`
+	CA          string
+}
+
+func (t RegistryTLS) IsEnabled() bool {
+	return t.Certificate != ""
 }
 
 func (r Registry) IsEmpty() bool {
 	return r == Registry{}
 }
 
+func (r Registry) HasSSHTunnel() bool {
+	return r.SSHTunnel != SSHTunnel{}
+}
+
+// Scheme returns the URL scheme the registry is served with
+func (r Registry) Scheme() string {
+	if r.TLS.IsEnabled() {
+		return "https"
+	}
+	return "http"
+}
+
+// AgentPort returns the port the agent connects to, which defaults to the bind port
+func (r Registry) AgentPort() int {
+	if r.AdvertisedPort != 0 {
+		return r.AdvertisedPort
+	}
+	return r.Port
+}
+
 type SSHTunnel struct {
 	User       string
 	Host       string
 	Port       int
 	Password   string
 	PrivateKey string `yaml:"private_key"`
+
+	// Bastion is connected to first and used to reach Host when the VM is on a private network
+	Bastion SSHBastion
+}
+
+type SSHBastion struct {
+	User       string
+	Host       string
+	Port       int
+	PrivateKey string `yaml:"private_key"`
+}
+
+func (t SSHTunnel) HasBastion() bool {
+	return t.Bastion != SSHBastion{}
 }
 
 func (m *Manifest) PopulateRegistry(username string, password string, host string, port int, sshTunnel SSHTunnel) {
@@ -57,3 +124,54 @@ func (m *Manifest) PopulateRegistry(username string, password string, host strin
 		SSHTunnel: sshTunnel,
 	}
 }
+
+// ServeRegistryOverTLS makes the registry serve HTTPS and tells the agent to connect with it.
+// It must be called after PopulateRegistry and before AdvertiseRegistry.
+func (m *Manifest) ServeRegistryOverTLS(tls RegistryTLS) {
+	m.Registry.TLS = tls
+
+	registryProperties := m.Properties["registry"].(biproperty.Map)
+	registryProperties["endpoint"] = m.Registry.Scheme() + "://" + net.JoinHostPort(m.Registry.Host, strconv.Itoa(m.Registry.Port))
+}
+
+// AdvertiseRegistry makes the agent connect to the registry at endpoint instead of its bind address.
+// It must be called after PopulateRegistry.
+func (m *Manifest) AdvertiseRegistry(endpoint string) error {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Parsing advertised registry endpoint '%s'", endpoint)
+	}
+
+	if endpointURL.Scheme != m.Registry.Scheme() || endpointURL.Hostname() == "" {
+		return bosherr.Errorf("Advertised registry endpoint '%s' must be an %s URL with a host", endpoint, m.Registry.Scheme())
+	}
+
+	if endpointURL.User != nil || (endpointURL.Path != "" && endpointURL.Path != "/") || endpointURL.RawQuery != "" {
+		return bosherr.Errorf("Advertised registry endpoint '%s' must not contain credentials, a path or a query", endpoint)
+	}
+
+	port := 80
+	if m.Registry.TLS.IsEnabled() {
+		port = 443
+	}
+
+	if endpointURL.Port() != "" {
+		port, err = strconv.Atoi(endpointURL.Port())
+		if err != nil || port < 1 || port > 65535 {
+			return bosherr.Errorf("Advertised registry endpoint '%s' must have a port between 1 and 65535", endpoint)
+		}
+	}
+
+	m.Registry.AdvertisedHost = endpointURL.Hostname()
+	m.Registry.AdvertisedPort = port
+
+	m.Properties["registry"] = biproperty.Map{
+		"host":     m.Registry.AdvertisedHost,
+		"port":     m.Registry.AdvertisedPort,
+		"username": m.Registry.Username,
+		"password": m.Registry.Password,
+		"endpoint": m.Registry.Scheme() + "://" + net.JoinHostPort(m.Registry.AdvertisedHost, strconv.Itoa(m.Registry.AdvertisedPort)),
+	}
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package manifest
 
 import (
+	"net"
+	"regexp"
 	"strings"
 
 	birelsetmanifest "github.com/cloudfoundry/bosh-cli/release/set/manifest"
@@ -8,6 +10,8 @@ import (
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 )
 
+var sha256DigestRegexp = regexp.MustCompile(`^(sha256:)?[0-9a-f]{64}$`)
+
 type Validator interface {
 	Validate(Manifest, birelsetmanifest.Manifest) error
 }
@@ -40,6 +44,41 @@ func (v *validator) Validate(manifest Manifest, releaseSetManifest birelsetmanif
 		errs = append(errs, bosherr.Errorf("cloud_provider.template.release '%s' must refer to a release in releases", cpiReleaseName))
 	}
 
+	if manifest.CPIDigest != "" && !sha256DigestRegexp.MatchString(manifest.CPIDigest) {
+		errs = append(errs, bosherr.Errorf("cloud_provider.cpi_sha256 '%s' must be a SHA256 hex digest", manifest.CPIDigest))
+	}
+
+	if manifest.CPIAPIVersion < 0 {
+		errs = append(errs, bosherr.Error("cloud_provider.cpi_api_version must be a positive integer"))
+	}
+
+	if advertisedHost := manifest.Registry.AdvertisedHost; advertisedHost != "" {
+		advertisedIP := net.ParseIP(advertisedHost)
+		if advertisedIP != nil && advertisedIP.IsUnspecified() {
+			errs = append(errs, bosherr.Errorf("Advertised registry endpoint host '%s' must be an address the agent can connect to", advertisedHost))
+		}
+
+		// Loopback on the VM only reaches the registry through the SSH tunnel
+		isLoopback := advertisedHost == "localhost" || (advertisedIP != nil && advertisedIP.IsLoopback())
+		if isLoopback && !manifest.Registry.HasSSHTunnel() {
+			errs = append(errs, bosherr.Errorf("Advertised registry endpoint host '%s' is only reachable from the VM through cloud_provider.ssh_tunnel", advertisedHost))
+		}
+	}
+
+	if sshTunnel := manifest.Registry.SSHTunnel; sshTunnel.HasBastion() {
+		if v.isBlank(sshTunnel.Bastion.Host) {
+			errs = append(errs, bosherr.Error("cloud_provider.ssh_tunnel.bastion.host must be provided"))
+		}
+
+		if v.isBlank(sshTunnel.Bastion.User) {
+			errs = append(errs, bosherr.Error("cloud_provider.ssh_tunnel.bastion.user must be provided"))
+		}
+
+		if v.isBlank(sshTunnel.Bastion.PrivateKey) {
+			errs = append(errs, bosherr.Error("cloud_provider.ssh_tunnel.bastion.private_key must be provided"))
+		}
+	}
+
 	if len(errs) > 0 {
 		return bosherr.NewMultiError(errs...)
 	}
@@ -101,5 +101,94 @@ var _ = Describe("Validator", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("cloud_provider.template.release 'not-provided-valid-release-name' must refer to a release in releases"))
 		})
+
+		It("allows a SHA256 CPI digest with or without the algorithm prefix", func() {
+			manifest := validManifest
+
+			manifest.CPIDigest = "3a6eb0790f39ac87c94f3856b2dd2c5d110e6811602261a9a923d3bb23adc8b7"
+			Expect(validator.Validate(manifest, releaseSetManifest)).To(Succeed())
+
+			manifest.CPIDigest = "sha256:3a6eb0790f39ac87c94f3856b2dd2c5d110e6811602261a9a923d3bb23adc8b7"
+			Expect(validator.Validate(manifest, releaseSetManifest)).To(Succeed())
+		})
+
+		It("validates the CPI digest is a SHA256 digest", func() {
+			manifest := validManifest
+			manifest.CPIDigest = "sha1:fake-digest"
+
+			err := validator.Validate(manifest, releaseSetManifest)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("cloud_provider.cpi_sha256 'sha1:fake-digest' must be a SHA256 hex digest"))
+		})
+
+		It("validates the CPI API version is positive", func() {
+			manifest := validManifest
+			manifest.CPIAPIVersion = -1
+
+			err := validator.Validate(manifest, releaseSetManifest)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("cloud_provider.cpi_api_version must be a positive integer"))
+		})
+
+		It("allows a routable advertised registry host", func() {
+			manifest := validManifest
+			manifest.Registry = Registry{Host: "0.0.0.0", Port: 6901, AdvertisedHost: "203.0.113.7", AdvertisedPort: 6901}
+
+			Expect(validator.Validate(manifest, releaseSetManifest)).To(Succeed())
+		})
+
+		It("validates the advertised registry host is not unspecified", func() {
+			manifest := validManifest
+			manifest.Registry = Registry{Host: "0.0.0.0", Port: 6901, AdvertisedHost: "0.0.0.0", AdvertisedPort: 6901}
+
+			err := validator.Validate(manifest, releaseSetManifest)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Advertised registry endpoint host '0.0.0.0' must be an address the agent can connect to"))
+		})
+
+		It("validates a loopback advertised registry host is only used with an SSH tunnel", func() {
+			manifest := validManifest
+			manifest.Registry = Registry{Host: "0.0.0.0", Port: 6901, AdvertisedHost: "localhost", AdvertisedPort: 6901}
+
+			err := validator.Validate(manifest, releaseSetManifest)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Advertised registry endpoint host 'localhost' is only reachable from the VM through cloud_provider.ssh_tunnel"))
+
+			manifest.Registry.SSHTunnel = SSHTunnel{Host: "54.34.56.8", Port: 22, User: "fake-ssh-user"}
+			Expect(validator.Validate(manifest, releaseSetManifest)).To(Succeed())
+		})
+
+		It("allows a fully specified SSH tunnel bastion", func() {
+			manifest := validManifest
+			manifest.Registry.SSHTunnel = SSHTunnel{
+				Host: "10.0.0.5",
+				Port: 22,
+				User: "fake-ssh-user",
+				Bastion: SSHBastion{
+					Host:       "jumpbox.example.com",
+					Port:       22,
+					User:       "fake-bastion-user",
+					PrivateKey: "fake-bastion-key",
+				},
+			}
+
+			Expect(validator.Validate(manifest, releaseSetManifest)).To(Succeed())
+		})
+
+		It("validates the SSH tunnel bastion host, user and private key are provided", func() {
+			manifest := validManifest
+			manifest.Registry.SSHTunnel = SSHTunnel{
+				Host:    "10.0.0.5",
+				Port:    22,
+				User:    "fake-ssh-user",
+				Bastion: SSHBastion{Port: 2222},
+			}
+
+			err := validator.Validate(manifest, releaseSetManifest)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("cloud_provider.ssh_tunnel.bastion.host must be provided"))
+			Expect(err.Error()).To(ContainSubstring("cloud_provider.ssh_tunnel.bastion.user must be provided"))
+			Expect(err.Error()).To(ContainSubstring("cloud_provider.ssh_tunnel.bastion.private_key must be provided"))
+		})
 	})
 })
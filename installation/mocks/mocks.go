@@ -1,5 +1,5 @@
 // Automatically generated by MockGen. DO NOT EDIT!
-// Source: github.com/cloudfoundry/bosh-cli/installation (interfaces: Installation,Installer,InstallerFactory,Uninstaller,JobResolver,PackageCompiler,JobRenderer)
+// Source: github.com/cloudfoundry/bosh-cli/installation (interfaces: Installation,Installer,InstallerFactory,Uninstaller,JobResolver,PackageCompiler,JobRenderer,CompiledPackagePruner)
 
 package mocks
 
@@ -33,6 +33,36 @@ func (_m *MockInstallation) EXPECT() *_MockInstallationRecorder {
 	return _m.recorder
 }
 
+func (_m *MockInstallation) CPIAPIVersion() int {
+	ret := _m.ctrl.Call(_m, "CPIAPIVersion")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+func (_mr *_MockInstallationRecorder) CPIAPIVersion() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "CPIAPIVersion")
+}
+
+func (_m *MockInstallation) CompiledPackages() []installation.CompiledPackageRef {
+	ret := _m.ctrl.Call(_m, "CompiledPackages")
+	ret0, _ := ret[0].([]installation.CompiledPackageRef)
+	return ret0
+}
+
+func (_mr *_MockInstallationRecorder) CompiledPackages() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "CompiledPackages")
+}
+
+func (_m *MockInstallation) ExpectedCPIDigest() string {
+	ret := _m.ctrl.Call(_m, "ExpectedCPIDigest")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+func (_mr *_MockInstallationRecorder) ExpectedCPIDigest() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ExpectedCPIDigest")
+}
+
 func (_m *MockInstallation) Job() installation.InstalledJob {
 	ret := _m.ctrl.Call(_m, "Job")
 	ret0, _ := ret[0].(installation.InstalledJob)
@@ -125,6 +155,17 @@ func (_mr *_MockInstallerRecorder) Install(arg0, arg1 interface{}) *gomock.Call
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Install", arg0, arg1)
 }
 
+func (_m *MockInstaller) PruneCompiledPackages(_param0 installation.Installation) (int64, error) {
+	ret := _m.ctrl.Call(_m, "PruneCompiledPackages", _param0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockInstallerRecorder) PruneCompiledPackages(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "PruneCompiledPackages", arg0)
+}
+
 // Mock of InstallerFactory interface
 type MockInstallerFactory struct {
 	ctrl     *gomock.Controller
@@ -282,3 +323,35 @@ func (_m *MockJobRenderer) RenderAndUploadFrom(_param0 manifest.Manifest, _param
 func (_mr *_MockJobRendererRecorder) RenderAndUploadFrom(arg0, arg1, arg2 interface{}) *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "RenderAndUploadFrom", arg0, arg1, arg2)
 }
+
+// Mock of CompiledPackagePruner interface
+type MockCompiledPackagePruner struct {
+	ctrl     *gomock.Controller
+	recorder *_MockCompiledPackagePrunerRecorder
+}
+
+// Recorder for MockCompiledPackagePruner (not exported)
+type _MockCompiledPackagePrunerRecorder struct {
+	mock *MockCompiledPackagePruner
+}
+
+func NewMockCompiledPackagePruner(ctrl *gomock.Controller) *MockCompiledPackagePruner {
+	mock := &MockCompiledPackagePruner{ctrl: ctrl}
+	mock.recorder = &_MockCompiledPackagePrunerRecorder{mock}
+	return mock
+}
+
+func (_m *MockCompiledPackagePruner) EXPECT() *_MockCompiledPackagePrunerRecorder {
+	return _m.recorder
+}
+
+func (_m *MockCompiledPackagePruner) Prune(_param0 []installation.CompiledPackageRef) (int64, error) {
+	ret := _m.ctrl.Call(_m, "Prune", _param0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockCompiledPackagePrunerRecorder) Prune(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Prune", arg0)
+}
@@ -0,0 +1,79 @@
+package pkg
+
+import (
+	"bytes"
+	"sync"
+
+	biui "github.com/cloudfoundry/bosh-cli/ui"
+)
+
+// compileLog prints the output of a packaging script line by line as it is
+// written, prefixed with the package name so that lines stay attributable
+// when several packages are compiled at the same time.
+type compileLog struct {
+	ui      biui.UI
+	pkgName string
+
+	started bool
+	lock    sync.Mutex
+}
+
+func newCompileLog(ui biui.UI, pkgName string) *compileLog {
+	return &compileLog{ui: ui, pkgName: pkgName}
+}
+
+// Writer returns a writer for one output stream; stdout and stderr need
+// separate writers so that partial lines of one are not joined with the other
+func (l *compileLog) Writer() *compileLogWriter {
+	return &compileLogWriter{log: l}
+}
+
+func (l *compileLog) printLine(line string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if !l.started {
+		// Move off the line of the stage compiling the package
+		l.ui.BeginLinef("\n")
+		l.started = true
+	}
+
+	l.ui.PrintLinef("%s | %s", l.pkgName, line)
+}
+
+type compileLogWriter struct {
+	log *compileLog
+
+	buf  bytes.Buffer
+	lock sync.Mutex
+}
+
+func (w *compileLogWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.buf.Write(p)
+
+	for {
+		idx := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if idx < 0 {
+			break
+		}
+
+		line := string(w.buf.Next(idx + 1))
+		w.log.printLine(line[:len(line)-1])
+	}
+
+	return len(p), nil
+}
+
+// Flush prints output that did not end with a newline
+func (w *compileLogWriter) Flush() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.buf.Len() > 0 {
+		w.log.printLine(w.buf.String())
+		w.buf.Reset()
+	}
+}
@@ -3,10 +3,12 @@ package pkg
 import (
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/cloudfoundry/bosh-cli/installation/blobextract"
 	birelpkg "github.com/cloudfoundry/bosh-cli/release/pkg"
 	bistatepkg "github.com/cloudfoundry/bosh-cli/state/pkg"
+	biui "github.com/cloudfoundry/bosh-cli/ui"
 	boshblob "github.com/cloudfoundry/bosh-utils/blobstore"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshcmd "github.com/cloudfoundry/bosh-utils/fileutil"
@@ -22,10 +24,19 @@ type compiler struct {
 	blobstore           boshblob.DigestBlobstore
 	compiledPackageRepo bistatepkg.CompiledPackageRepo
 	blobExtractor       blobextract.Extractor
+	compileLogUI        biui.UI
 	logger              boshlog.Logger
 	logTag              string
+
+	// installed counts the compilations using each package installed into packagesDir,
+	// so that concurrent compilations share installed dependencies
+	installedLock sync.Mutex
+	installed     map[string]int
 }
 
+// NewPackageCompiler returns a Compiler that runs packaging scripts locally.
+// When compileLogUI is not nil, script output is printed to it while packages compile.
+// Independent packages may be compiled concurrently.
 func NewPackageCompiler(
 	runner boshsys.CmdRunner,
 	packagesDir string,
@@ -34,6 +45,7 @@ func NewPackageCompiler(
 	blobstore boshblob.DigestBlobstore,
 	compiledPackageRepo bistatepkg.CompiledPackageRepo,
 	blobExtractor blobextract.Extractor,
+	compileLogUI biui.UI,
 	logger boshlog.Logger,
 ) bistatepkg.Compiler {
 	return &compiler{
@@ -44,16 +56,17 @@ func NewPackageCompiler(
 		blobstore:           blobstore,
 		compiledPackageRepo: compiledPackageRepo,
 		blobExtractor:       blobExtractor,
+		compileLogUI:        compileLogUI,
 		logger:              logger,
 		logTag:              "packageCompiler",
+		installed:           map[string]int{},
 	}
 }
 
 func (c *compiler) Compile(pkg birelpkg.Compilable) (bistatepkg.CompiledPackageRecord, bool, error) {
-	// This is a variable being used now to fulfill the requirement of the compiler_interface compile method
-	// to indicate whether the package is already compiled. Compiled CPI releases are not currently allowed.
-	// No other packages, but CPI ones, are currently being compiled locally.
-	isCompiledPackage := false
+	// Packages of compiled CPI releases are only added to the blobstore. Whether they were compiled
+	// for the right stemcell is validated before installing.
+	isCompiledPackage := pkg.IsCompiled()
 
 	c.logger.Debug(c.logTag, "Checking for compiled package '%s/%s'", pkg.Name(), pkg.Fingerprint())
 
@@ -64,23 +77,24 @@ func (c *compiler) Compile(pkg birelpkg.Compilable) (bistatepkg.CompiledPackageR
 		return record, isCompiledPackage, nil
 	}
 
+	if isCompiledPackage {
+		return c.addCompiledPackage(pkg)
+	}
+
 	c.logger.Debug(c.logTag, "Installing dependencies of package '%s/%s'", pkg.Name(), pkg.Fingerprint())
 
-	err = c.installPackages(pkg.Deps())
+	installedDeps, err := c.installPackages(pkg.Deps())
+	defer c.uninstallPackages(installedDeps)
 	if err != nil {
 		return record, isCompiledPackage, bosherr.WrapErrorf(err, "Installing dependencies of package '%s'", pkg.Name())
 	}
 
-	defer func() {
-		if err = c.fileSystem.RemoveAll(c.packagesDir); err != nil {
-			c.logger.Warn(c.logTag, "Failed to remove packages dir: %s", err.Error())
-		}
-	}()
-
 	c.logger.Debug(c.logTag, "Compiling package '%s/%s'", pkg.Name(), pkg.Fingerprint())
 
 	installDir := filepath.Join(c.packagesDir, pkg.Name())
 
+	defer c.removeDir(installDir)
+
 	err = c.fileSystem.MkdirAll(installDir, os.ModePerm)
 	if err != nil {
 		return record, isCompiledPackage, bosherr.WrapError(err, "Creating package install dir")
@@ -106,6 +120,17 @@ func (c *compiler) Compile(pkg birelpkg.Compilable) (bistatepkg.CompiledPackageR
 		WorkingDir:     packageSrcDir,
 	}
 
+	if c.compileLogUI != nil {
+		compileLog := newCompileLog(c.compileLogUI, pkg.Name())
+		stdout, stderr := compileLog.Writer(), compileLog.Writer()
+
+		defer stdout.Flush()
+		defer stderr.Flush()
+
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+	}
+
 	_, _, _, err = c.runner.RunComplexCommand(cmd)
 	if err != nil {
 		return record, isCompiledPackage, bosherr.WrapError(err, "Compiling package")
@@ -140,24 +165,84 @@ func (c *compiler) Compile(pkg birelpkg.Compilable) (bistatepkg.CompiledPackageR
 	return record, isCompiledPackage, nil
 }
 
-func (c *compiler) installPackages(packages []birelpkg.Compilable) error {
+func (c *compiler) addCompiledPackage(pkg birelpkg.Compilable) (bistatepkg.CompiledPackageRecord, bool, error) {
+	c.logger.Debug(c.logTag, "Adding precompiled package '%s/%s'", pkg.Name(), pkg.Fingerprint())
+
+	blobID, digest, err := c.blobstore.Create(pkg.ArchivePath())
+	if err != nil {
+		return bistatepkg.CompiledPackageRecord{}, true, bosherr.WrapError(err, "Creating blob")
+	}
+
+	record := bistatepkg.CompiledPackageRecord{
+		BlobID:   blobID,
+		BlobSHA1: digest.String(),
+	}
+
+	err = c.compiledPackageRepo.Save(pkg, record)
+	if err != nil {
+		return record, true, bosherr.WrapError(err, "Saving compiled package")
+	}
+
+	return record, true, nil
+}
+
+// installPackages installs the compiled packages into packagesDir unless they are already installed
+// for another compilation, and returns the packages that have to be uninstalled afterwards,
+// including those installed before an error occurred
+func (c *compiler) installPackages(packages []birelpkg.Compilable) ([]birelpkg.Compilable, error) {
+	c.installedLock.Lock()
+	defer c.installedLock.Unlock()
+
+	installed := []birelpkg.Compilable{}
+
 	for _, pkg := range packages {
+		if c.installed[pkg.Name()] > 0 {
+			c.installed[pkg.Name()]++
+			installed = append(installed, pkg)
+			continue
+		}
+
 		c.logger.Debug(c.logTag, "Checking for compiled package '%s/%s'", pkg.Name(), pkg.Fingerprint())
 
 		record, found, err := c.compiledPackageRepo.Find(pkg)
 		if err != nil {
-			return bosherr.WrapErrorf(err, "Attempting to find compiled package '%s'", pkg.Name())
+			return installed, bosherr.WrapErrorf(err, "Attempting to find compiled package '%s'", pkg.Name())
 		} else if !found {
-			return bosherr.Errorf("Finding compiled package '%s'", pkg.Name())
+			return installed, bosherr.Errorf("Finding compiled package '%s'", pkg.Name())
 		}
 
 		c.logger.Debug(c.logTag, "Installing package '%s/%s'", pkg.Name(), pkg.Fingerprint())
 
 		err = c.blobExtractor.Extract(record.BlobID, record.BlobSHA1, filepath.Join(c.packagesDir, pkg.Name()))
 		if err != nil {
-			return bosherr.WrapErrorf(err, "Installing package '%s' into '%s'", pkg.Name(), c.packagesDir)
+			c.removeDir(filepath.Join(c.packagesDir, pkg.Name()))
+			return installed, bosherr.WrapErrorf(err, "Installing package '%s' into '%s'", pkg.Name(), c.packagesDir)
+		}
+
+		c.installed[pkg.Name()] = 1
+		installed = append(installed, pkg)
+	}
+
+	return installed, nil
+}
+
+// uninstallPackages removes the packages from packagesDir once no other compilation uses them
+func (c *compiler) uninstallPackages(packages []birelpkg.Compilable) {
+	c.installedLock.Lock()
+	defer c.installedLock.Unlock()
+
+	for _, pkg := range packages {
+		c.installed[pkg.Name()]--
+
+		if c.installed[pkg.Name()] == 0 {
+			delete(c.installed, pkg.Name())
+			c.removeDir(filepath.Join(c.packagesDir, pkg.Name()))
 		}
 	}
+}
 
-	return nil
+func (c *compiler) removeDir(dir string) {
+	if err := c.fileSystem.RemoveAll(dir); err != nil {
+		c.logger.Warn(c.logTag, "Failed to remove '%s': %s", dir, err.Error())
+	}
 }
@@ -20,6 +20,7 @@ import (
 	. "github.com/cloudfoundry/bosh-cli/release/resource"
 	bistatepkg "github.com/cloudfoundry/bosh-cli/state/pkg"
 	mock_state_package "github.com/cloudfoundry/bosh-cli/state/pkg/mocks"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
 )
 
 var _ = Describe("PackageCompiler", func() {
@@ -79,6 +80,7 @@ var _ = Describe("PackageCompiler", func() {
 			blobstore,
 			mockCompiledPackageRepo,
 			fakeExtractor,
+			nil,
 			logger,
 		)
 	})
@@ -219,6 +221,46 @@ var _ = Describe("PackageCompiler", func() {
 			Expect(fs.FileExists(packagesDir)).To(BeFalse())
 		})
 
+		Context("when streaming compile logs", func() {
+			var (
+				ui *fakeui.FakeUI
+			)
+
+			BeforeEach(func() {
+				ui = &fakeui.FakeUI{}
+
+				compiler = NewPackageCompiler(
+					runner,
+					packagesDir,
+					fs,
+					compressor,
+					blobstore,
+					mockCompiledPackageRepo,
+					fakeExtractor,
+					ui,
+					logger,
+				)
+
+				runner.AddCmdResult("bash -x packaging", fakesys.FakeCmdResult{
+					Stdout: "+ tar xzf src.tgz\n+ make\nmake: done",
+					Stderr: "warning: deprecated\n",
+				})
+			})
+
+			It("prints each line of the packaging script output prefixed with the package name", func() {
+				_, _, err := compiler.Compile(pkg)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(ui.Said).To(Equal([]string{
+					"\n",
+					"pkg1-name | + tar xzf src.tgz",
+					"pkg1-name | + make",
+					"pkg1-name | warning: deprecated",
+					"pkg1-name | make: done",
+				}))
+			})
+		})
+
 		Context("when dependency installation fails", func() {
 			JustBeforeEach(func() {
 				fakeExtractor.ExtractReturns(errors.New("fake-install-error"))
@@ -325,4 +367,53 @@ var _ = Describe("PackageCompiler", func() {
 			})
 		})
 	})
+
+	Describe("Compile with a compiled package", func() {
+		var compiledPkg *birelpkg.CompiledPackage
+
+		BeforeEach(func() {
+			compiledPkg = birelpkg.NewCompiledPackageWithArchive(
+				"compiled-pkg-name", "fake-fingerprint", "ubuntu-xenial/97.1", "/compiled-pkg.tgz", "fake-sha1", nil)
+		})
+
+		It("adds the archive to the blobstore without compiling", func() {
+			mockCompiledPackageRepo.EXPECT().Find(compiledPkg).Return(bistatepkg.CompiledPackageRecord{}, false, nil)
+
+			record := bistatepkg.CompiledPackageRecord{
+				BlobID:   "fake-blob-id",
+				BlobSHA1: "fakefingerprint",
+			}
+			mockCompiledPackageRepo.EXPECT().Save(compiledPkg, record)
+
+			actualRecord, isCompiledPackage, err := compiler.Compile(compiledPkg)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actualRecord).To(Equal(record))
+			Expect(isCompiledPackage).To(BeTrue())
+
+			Expect(blobstore.CreateArgsForCall(0)).To(Equal("/compiled-pkg.tgz"))
+			Expect(runner.RunComplexCommands).To(BeEmpty())
+			Expect(fakeExtractor.ExtractCallCount()).To(Equal(0))
+		})
+
+		It("reuses the archive already in the compiled package repo", func() {
+			record := bistatepkg.CompiledPackageRecord{BlobID: "fake-existing-blob-id", BlobSHA1: "fake-sha1"}
+			mockCompiledPackageRepo.EXPECT().Find(compiledPkg).Return(record, true, nil)
+
+			actualRecord, isCompiledPackage, err := compiler.Compile(compiledPkg)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actualRecord).To(Equal(record))
+			Expect(isCompiledPackage).To(BeTrue())
+
+			Expect(blobstore.CreateCallCount()).To(Equal(0))
+		})
+
+		It("returns an error when adding the archive to the blobstore fails", func() {
+			mockCompiledPackageRepo.EXPECT().Find(compiledPkg).Return(bistatepkg.CompiledPackageRecord{}, false, nil)
+			blobstore.CreateReturns("", boshcrypto.MultipleDigest{}, errors.New("fake-error"))
+
+			_, _, err := compiler.Compile(compiledPkg)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Creating blob: fake-error"))
+		})
+	})
 })
@@ -0,0 +1,166 @@
+package pkg
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshcmd "github.com/cloudfoundry/bosh-utils/fileutil"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// deterministicModTime is recorded for every entry so that archives do not depend on when files were written
+var deterministicModTime = time.Unix(0, 0).UTC()
+
+type deterministicCompressor struct {
+	boshcmd.Compressor
+	fs boshsys.FileSystem
+}
+
+// NewDeterministicCompressor returns a Compressor whose tarballs only depend on the
+// names, contents and executable bits of the compressed files: entries are sorted,
+// modification times and ownership are fixed and modes are normalized to 0755 or 0644.
+// Compressing the same files twice therefore yields the same SHA.
+// Specific files, decompression and clean up are delegated to compressor.
+func NewDeterministicCompressor(compressor boshcmd.Compressor, fs boshsys.FileSystem) boshcmd.Compressor {
+	return deterministicCompressor{Compressor: compressor, fs: fs}
+}
+
+func (c deterministicCompressor) CompressFilesInDir(dir string) (string, error) {
+	tarball, err := c.fs.TempFile("bosh-cli-DeterministicCompressor-CompressFilesInDir")
+	if err != nil {
+		return "", bosherr.WrapError(err, "Creating temporary file for tarball")
+	}
+
+	defer tarball.Close()
+
+	err = c.writeTarball(dir, tarball)
+	if err != nil {
+		_ = c.fs.RemoveAll(tarball.Name())
+		return "", bosherr.WrapErrorf(err, "Compressing files in '%s'", dir)
+	}
+
+	return tarball.Name(), nil
+}
+
+func (c deterministicCompressor) writeTarball(dir string, out io.Writer) error {
+	var paths []string
+
+	err := c.fs.Walk(dir, func(path string, _ os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		paths = append(paths, path)
+
+		return nil
+	})
+	if err != nil {
+		return bosherr.WrapError(err, "Listing files")
+	}
+
+	sort.Strings(paths)
+
+	// A zero gzip header carries neither a file name nor a modification time
+	gzipWriter := gzip.NewWriter(out)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	for _, path := range paths {
+		err = c.writeEntry(tarWriter, dir, path)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tarWriter.Close()
+	if err != nil {
+		return bosherr.WrapError(err, "Closing tar writer")
+	}
+
+	err = gzipWriter.Close()
+	if err != nil {
+		return bosherr.WrapError(err, "Closing gzip writer")
+	}
+
+	return nil
+}
+
+func (c deterministicCompressor) writeEntry(tarWriter *tar.Writer, dir, path string) error {
+	info, err := c.fs.Lstat(path)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Getting file info for '%s'", path)
+	}
+
+	relPath, err := filepath.Rel(dir, path)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Getting relative path for '%s'", path)
+	}
+
+	// Entries are named like the ones created by 'tar -C dir .'
+	header := &tar.Header{
+		Name:    "./" + filepath.ToSlash(relPath),
+		ModTime: deterministicModTime,
+	}
+
+	switch {
+	case info.IsDir():
+		header.Typeflag = tar.TypeDir
+		header.Mode = 0755
+
+		if relPath == "." {
+			header.Name = "./"
+		} else {
+			header.Name += "/"
+		}
+
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := c.fs.Readlink(path)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Reading symlink '%s'", path)
+		}
+
+		header.Typeflag = tar.TypeSymlink
+		header.Linkname = target
+		header.Mode = 0777
+
+	case info.Mode().IsRegular():
+		header.Typeflag = tar.TypeReg
+		header.Size = info.Size()
+		header.Mode = 0644
+
+		if info.Mode()&0111 != 0 {
+			header.Mode = 0755
+		}
+
+	default:
+		return bosherr.Errorf("Unsupported file type for '%s': %s", path, info.Mode().String())
+	}
+
+	err = tarWriter.WriteHeader(header)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing tar header for '%s'", path)
+	}
+
+	if header.Typeflag != tar.TypeReg {
+		return nil
+	}
+
+	file, err := c.fs.OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Opening '%s'", path)
+	}
+
+	defer file.Close()
+
+	_, err = io.Copy(tarWriter, file)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing '%s' to tarball", path)
+	}
+
+	return nil
+}
@@ -0,0 +1,231 @@
+package pkg_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	fakeblobstore "github.com/cloudfoundry/bosh-utils/blobstore/fakes"
+	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
+	boshcmd "github.com/cloudfoundry/bosh-utils/fileutil"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/installation/blobextract/fakeblobextract"
+	. "github.com/cloudfoundry/bosh-cli/installation/pkg"
+	birelpkg "github.com/cloudfoundry/bosh-cli/release/pkg"
+	. "github.com/cloudfoundry/bosh-cli/release/resource"
+	bistatepkg "github.com/cloudfoundry/bosh-cli/state/pkg"
+	mock_state_package "github.com/cloudfoundry/bosh-cli/state/pkg/mocks"
+)
+
+var _ = Describe("DeterministicCompressor", func() {
+	var (
+		fs         boshsys.FileSystem
+		compressor boshcmd.Compressor
+		tmpDir     string
+	)
+
+	BeforeEach(func() {
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		fs = boshsys.NewOsFileSystem(logger)
+		compressor = NewDeterministicCompressor(boshcmd.NewTarballCompressor(boshsys.NewExecCmdRunner(logger), fs), fs)
+
+		var err error
+		tmpDir, err = fs.TempDir("deterministic-compressor-test")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(fs.RemoveAll(tmpDir)).To(Succeed())
+	})
+
+	writeFiles := func(dir string, modTime time.Time, fileMode os.FileMode) {
+		Expect(fs.MkdirAll(filepath.Join(dir, "bin"), 0700)).To(Succeed())
+		Expect(fs.WriteFileString(filepath.Join(dir, "bin", "run"), "#!/bin/sh\n")).To(Succeed())
+		Expect(fs.Chmod(filepath.Join(dir, "bin", "run"), fileMode|0100)).To(Succeed())
+		Expect(fs.WriteFileString(filepath.Join(dir, "README"), "hello")).To(Succeed())
+		Expect(fs.Chmod(filepath.Join(dir, "README"), fileMode)).To(Succeed())
+		Expect(fs.Symlink("bin/run", filepath.Join(dir, "run"))).To(Succeed())
+
+		for _, path := range []string{"bin/run", "bin", "README", "."} {
+			Expect(os.Chtimes(filepath.Join(dir, path), modTime, modTime)).To(Succeed())
+		}
+	}
+
+	sha1OfFile := func(path string) string {
+		contents, err := fs.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+
+		return fmt.Sprintf("%x", sha1.Sum(contents))
+	}
+
+	It("creates the same tarball for the same files regardless of their timestamps and permissions", func() {
+		dir1 := filepath.Join(tmpDir, "first")
+		dir2 := filepath.Join(tmpDir, "second")
+
+		writeFiles(dir1, time.Unix(1500000000, 0), 0600)
+		writeFiles(dir2, time.Unix(1600000000, 0), 0664)
+
+		tarball1, err := compressor.CompressFilesInDir(dir1)
+		Expect(err).ToNot(HaveOccurred())
+		defer compressor.CleanUp(tarball1)
+
+		tarball2, err := compressor.CompressFilesInDir(dir2)
+		Expect(err).ToNot(HaveOccurred())
+		defer compressor.CleanUp(tarball2)
+
+		Expect(sha1OfFile(tarball1)).To(Equal(sha1OfFile(tarball2)))
+	})
+
+	It("writes sorted entries with normalized modes, ownership and timestamps", func() {
+		dir := filepath.Join(tmpDir, "package")
+		writeFiles(dir, time.Unix(1500000000, 0), 0600)
+
+		tarball, err := compressor.CompressFilesInDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+		defer compressor.CleanUp(tarball)
+
+		file, err := os.Open(tarball)
+		Expect(err).ToNot(HaveOccurred())
+		defer file.Close()
+
+		gzipReader, err := gzip.NewReader(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gzipReader.ModTime.IsZero()).To(BeTrue())
+
+		tarReader := tar.NewReader(gzipReader)
+
+		var entries []string
+
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				break
+			}
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(header.ModTime.Unix()).To(Equal(int64(0)))
+			Expect(header.Uid).To(Equal(0))
+			Expect(header.Gid).To(Equal(0))
+			Expect(header.Uname).To(BeEmpty())
+			Expect(header.Gname).To(BeEmpty())
+
+			entries = append(entries, fmt.Sprintf("%s %o %s", header.Name, header.Mode, header.Linkname))
+		}
+
+		Expect(entries).To(Equal([]string{
+			"./ 755 ",
+			"./README 644 ",
+			"./bin/ 755 ",
+			"./bin/run 755 ",
+			"./run 777 bin/run",
+		}))
+	})
+
+	It("can be decompressed with the wrapped compressor", func() {
+		dir := filepath.Join(tmpDir, "package")
+		writeFiles(dir, time.Unix(1500000000, 0), 0600)
+
+		tarball, err := compressor.CompressFilesInDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+		defer compressor.CleanUp(tarball)
+
+		extractedDir := filepath.Join(tmpDir, "extracted")
+		Expect(fs.MkdirAll(extractedDir, 0755)).To(Succeed())
+
+		err = compressor.DecompressFileToDir(tarball, extractedDir, boshcmd.CompressorOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
+		contents, err := fs.ReadFileString(filepath.Join(extractedDir, "README"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(contents).To(Equal("hello"))
+
+		target, err := fs.Readlink(filepath.Join(extractedDir, "run"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(target).To(Equal("bin/run"))
+	})
+
+	It("returns an error when the directory does not exist", func() {
+		_, err := compressor.CompressFilesInDir(filepath.Join(tmpDir, "missing"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Listing files"))
+	})
+
+	Context("when used by the package compiler", func() {
+		var (
+			mockCtrl                *gomock.Controller
+			mockCompiledPackageRepo *mock_state_package.MockCompiledPackageRepo
+			blobstore               *fakeblobstore.FakeDigestBlobstore
+			compiler                bistatepkg.Compiler
+			pkg                     *birelpkg.Package
+		)
+
+		BeforeEach(func() {
+			mockCtrl = gomock.NewController(GinkgoT())
+			mockCompiledPackageRepo = mock_state_package.NewMockCompiledPackageRepo(mockCtrl)
+			mockCompiledPackageRepo.EXPECT().Find(gomock.Any()).Return(bistatepkg.CompiledPackageRecord{}, false, nil).AnyTimes()
+			mockCompiledPackageRepo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+			blobstore = &fakeblobstore.FakeDigestBlobstore{}
+			blobstore.CreateStub = func(path string) (string, boshcrypto.MultipleDigest, error) {
+				digest := boshcrypto.MustNewMultipleDigest(boshcrypto.NewDigest(boshcrypto.DigestAlgorithmSHA1, sha1OfFile(path)))
+				return "fake-blob-id", digest, nil
+			}
+
+			logger := boshlog.NewLogger(boshlog.LevelNone)
+
+			compiler = NewPackageCompiler(
+				boshsys.NewExecCmdRunner(logger),
+				filepath.Join(tmpDir, "packages"),
+				fs,
+				compressor,
+				blobstore,
+				mockCompiledPackageRepo,
+				&fakeblobextract.FakeExtractor{},
+				nil,
+				logger,
+			)
+
+			srcDir := filepath.Join(tmpDir, "src")
+			Expect(fs.MkdirAll(srcDir, 0755)).To(Succeed())
+
+			// Every compile writes files with a different modification time
+			err := ioutil.WriteFile(filepath.Join(srcDir, "packaging"), []byte(`
+set -e
+mkdir -p "$BOSH_INSTALL_TARGET/bin"
+echo hello > "$BOSH_INSTALL_TARGET/bin/hello"
+chmod +x "$BOSH_INSTALL_TARGET/bin/hello"
+touch -d "@$RANDOM" "$BOSH_INSTALL_TARGET/bin/hello"
+`), 0644)
+			Expect(err).ToNot(HaveOccurred())
+
+			pkg = birelpkg.NewExtractedPackage(NewResource("pkg1-name", "", nil), nil, srcDir, fs)
+		})
+
+		AfterEach(func() {
+			mockCtrl.Finish()
+		})
+
+		It("produces the same archive SHA when compiling the same package twice", func() {
+			record1, _, err := compiler.Compile(pkg)
+			Expect(err).ToNot(HaveOccurred())
+
+			record2, _, err := compiler.Compile(pkg)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(blobstore.CreateCallCount()).To(Equal(2))
+			Expect(record1.BlobSHA1).ToNot(BeEmpty())
+			Expect(record2.BlobSHA1).To(Equal(record1.BlobSHA1))
+		})
+	})
+})
@@ -30,13 +30,53 @@ func NewReleaseFetcher(
 }
 
 func (f ReleaseFetcher) DownloadAndExtract(releaseRef manifest.ReleaseRef, stage ui.Stage) error {
+	release, err := f.fetch(releaseRef, stage)
+	if release != nil {
+		f.releaseManager.Add(release)
+	}
+
+	return err
+}
+
+// DownloadAndExtractAll fetches releases with up to workers of them at a time.
+// Releases are added in the given order, regardless of which finished first,
+// including those fetched before one failed so that they are cleaned up.
+func (f ReleaseFetcher) DownloadAndExtractAll(releaseRefs []manifest.ReleaseRef, workers int, stage ui.Stage) error {
+	releases := make([]boshrel.Release, len(releaseRefs))
+	steps := []func(ui.Stage) error{}
+
+	for i, releaseRef := range releaseRefs {
+		i, releaseRef := i, releaseRef
+
+		steps = append(steps, func(stage ui.Stage) error {
+			var err error
+			releases[i], err = f.fetch(releaseRef, stage)
+			return err
+		})
+	}
+
+	err := ui.PerformParallel(stage, workers, steps)
+
+	for _, release := range releases {
+		if release != nil {
+			f.releaseManager.Add(release)
+		}
+	}
+
+	return err
+}
+
+// fetch returns the extracted release, which is also returned when its name is not the expected one
+func (f ReleaseFetcher) fetch(releaseRef manifest.ReleaseRef, stage ui.Stage) (boshrel.Release, error) {
 	releasePath, err := f.tarballProvider.Get(releaseRef, stage)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	var release boshrel.Release
+
 	err = stage.Perform(fmt.Sprintf("Validating release '%s'", releaseRef.Name), func() error {
-		release, err := f.releaseReader.Read(releasePath)
+		release, err = f.releaseReader.Read(releasePath)
 		if err != nil {
 			return bosherr.WrapErrorf(err, "Extracting release '%s'", releasePath)
 		}
@@ -46,10 +86,8 @@ func (f ReleaseFetcher) DownloadAndExtract(releaseRef manifest.ReleaseRef, stage
 			return bosherr.Errorf(errMsg, releaseRef.Name, release.Name())
 		}
 
-		f.releaseManager.Add(release)
-
 		return nil
 	})
 
-	return err
+	return release, err
 }
@@ -0,0 +1,100 @@
+package installation_test
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/installation"
+	mock_tarball "github.com/cloudfoundry/bosh-cli/installation/tarball/mocks"
+	boshrel "github.com/cloudfoundry/bosh-cli/release"
+	birelmanifest "github.com/cloudfoundry/bosh-cli/release/manifest"
+	fakerel "github.com/cloudfoundry/bosh-cli/release/releasefakes"
+	biui "github.com/cloudfoundry/bosh-cli/ui"
+)
+
+var _ = Describe("ReleaseFetcher", func() {
+	var (
+		mockCtrl            *gomock.Controller
+		mockTarballProvider *mock_tarball.MockProvider
+		releaseReader       *fakerel.FakeReader
+		releaseManager      ReleaseManager
+		releaseA, releaseB  *fakerel.FakeRelease
+		stage               biui.Stage
+		fetcher             ReleaseFetcher
+
+		releaseRefs []birelmanifest.ReleaseRef
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockTarballProvider = mock_tarball.NewMockProvider(mockCtrl)
+		releaseReader = &fakerel.FakeReader{}
+
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		releaseManager = NewReleaseManager(logger)
+		stage = biui.NewStage(biui.NewWriterUI(bytes.NewBufferString(""), bytes.NewBufferString(""), logger), fakeclock.NewFakeClock(time.Now()), logger)
+
+		releaseA = &fakerel.FakeRelease{}
+		releaseA.NameReturns("release-a")
+		releaseB = &fakerel.FakeRelease{}
+		releaseB.NameReturns("release-b")
+
+		releaseRefs = []birelmanifest.ReleaseRef{{Name: "release-a"}, {Name: "release-b"}}
+		mockTarballProvider.EXPECT().Get(releaseRefs[0], gomock.Any()).Return("/release-a.tgz", nil).AnyTimes()
+		mockTarballProvider.EXPECT().Get(releaseRefs[1], gomock.Any()).Return("/release-b.tgz", nil).AnyTimes()
+
+		fetcher = NewReleaseFetcher(mockTarballProvider, releaseReader, releaseManager)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Describe("DownloadAndExtractAll", func() {
+		It("adds releases in the given order even when a later one finishes first", func() {
+			releaseBRead := make(chan struct{})
+
+			releaseReader.ReadStub = func(path string) (boshrel.Release, error) {
+				if path == "/release-a.tgz" {
+					<-releaseBRead
+					return releaseA, nil
+				}
+				close(releaseBRead)
+				return releaseB, nil
+			}
+
+			err := fetcher.DownloadAndExtractAll(releaseRefs, 2, stage)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(releaseManager.List()).To(Equal([]boshrel.Release{releaseA, releaseB}))
+		})
+
+		It("adds releases that were extracted before one failed so that they are cleaned up", func() {
+			releaseReader.ReadStub = func(path string) (boshrel.Release, error) {
+				if path == "/release-a.tgz" {
+					return releaseA, nil
+				}
+				return nil, errors.New("fake-read-err")
+			}
+
+			err := fetcher.DownloadAndExtractAll(releaseRefs, 1, stage)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Extracting release '/release-b.tgz': fake-read-err"))
+			Expect(releaseManager.List()).To(Equal([]boshrel.Release{releaseA}))
+		})
+
+		It("returns an error when a release does not have the expected name", func() {
+			releaseReader.ReadReturns(releaseA, nil)
+
+			err := fetcher.DownloadAndExtractAll(releaseRefs[1:], 1, stage)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Release name 'release-b' does not match the name in release tarball 'release-a'"))
+		})
+	})
+})
@@ -15,6 +15,8 @@ import (
 type Cache interface {
 	Get(source Source) (path string, found bool)
 	Path(source Source) (path string)
+	// PartialPath is where an incomplete download of source is kept so that it can be resumed
+	PartialPath(source Source) (path string)
 	Save(sourcePath string, source Source) error
 }
 
@@ -64,3 +66,7 @@ func (c *cache) Path(source Source) string {
 	filename := fmt.Sprintf("%x-%s", string(urlSHA1[:]), source.GetSHA1())
 	return filepath.Join(c.basePath, filename)
 }
+
+func (c *cache) PartialPath(source Source) string {
+	return c.Path(source) + ".partial"
+}
@@ -1,9 +1,13 @@
 package tarball
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -26,12 +30,21 @@ type Provider interface {
 	Get(Source, biui.Stage) (path string, err error)
 }
 
+// Mirror serves the tarballs whose URL starts with URL under MirrorURL instead,
+// e.g. 'https://bosh.io/d/' mirrored at 'https://mirror.example.com/bosh.io/'
+type Mirror struct {
+	URL       string
+	MirrorURL string
+}
+
 type provider struct {
 	cache            Cache
 	fs               boshsys.FileSystem
 	httpClient       *httpclient.HTTPClient
+	mirrors          []Mirror
 	downloadAttempts int
 	delayTimeout     time.Duration
+	progress         biui.ProgressReporter
 	logger           boshlog.Logger
 	logTag           string
 }
@@ -40,16 +53,20 @@ func NewProvider(
 	cache Cache,
 	fs boshsys.FileSystem,
 	httpClient *httpclient.HTTPClient,
+	mirrors []Mirror,
 	downloadAttempts int,
 	delayTimeout time.Duration,
+	progress biui.ProgressReporter,
 	logger boshlog.Logger,
 ) Provider {
 	return &provider{
 		cache:            cache,
 		fs:               fs,
 		httpClient:       httpClient,
+		mirrors:          mirrors,
 		downloadAttempts: downloadAttempts,
 		delayTimeout:     delayTimeout,
+		progress:         progress,
 
 		logTag: "tarballProvider",
 		logger: logger,
@@ -74,17 +91,27 @@ func (p *provider) Get(source Source, stage biui.Stage) (string, error) {
 				return biui.NewSkipStageError(bosherr.Error("Already downloaded"), "Found in local cache")
 			}
 
-			retryStrategy := boshretry.NewAttemptRetryStrategy(
-				p.downloadAttempts, p.delayTimeout, p.downloadRetryable(source), p.logger)
+			var errs []error
+
+			for _, mirrorURL := range p.mirrorURLs(source) {
+				retryStrategy := boshretry.NewAttemptRetryStrategy(
+					p.downloadAttempts, p.delayTimeout, p.downloadRetryable(source, mirrorURL), p.logger)
 
-			err := retryStrategy.Try()
-			if err != nil {
-				return bosherr.WrapErrorf(err, "Failed to download from '%s'", source.GetURL())
+				err := retryStrategy.Try()
+				if err == nil {
+					p.logger.Debug(p.logTag, "Using the tarball downloaded from '%s'", mirrorURL)
+					return nil
+				}
+
+				p.logger.Warn(p.logTag, "Failed to download from '%s': %s", mirrorURL, err.Error())
+				errs = append(errs, bosherr.WrapErrorf(err, "Failed to download from '%s'", mirrorURL))
 			}
 
-			p.logger.Debug(p.logTag, "Using the downloaded tarball: '%s'", cachedPath)
+			if len(errs) == 1 {
+				return errs[0]
+			}
 
-			return nil
+			return bosherr.NewMultiError(errs...)
 		})
 		if err != nil {
 			return "", err
@@ -106,22 +133,50 @@ func (p *provider) Get(source Source, stage biui.Stage) (string, error) {
 	return expandedPath, nil
 }
 
-func (p *provider) downloadRetryable(source Source) boshretry.Retryable {
+// mirrorURLs returns the URL of source followed by the URLs of the mirrors
+// serving it, in the order they are tried, e.g. when the first one times out
+func (p *provider) mirrorURLs(source Source) []string {
+	urls := []string{source.GetURL()}
+
+	for _, mirror := range p.mirrors {
+		if strings.HasPrefix(source.GetURL(), mirror.URL) {
+			urls = append(urls, mirror.MirrorURL+strings.TrimPrefix(source.GetURL(), mirror.URL))
+		}
+	}
+
+	return urls
+}
+
+// partialDownload is persisted next to a partially downloaded tarball
+// so that download can be resumed by a later attempt or CLI invocation
+type partialDownload struct {
+	// URL is the mirror the bits were downloaded from
+	URL  string `json:"url"`
+	SHA1 string `json:"sha1"`
+
+	// Validator is ETag or Last-Modified of the partially downloaded resource
+	Validator string `json:"validator"`
+}
+
+func (p *provider) downloadRetryable(source Source, mirrorURL string) boshretry.Retryable {
 	return boshretry.NewRetryable(func() (bool, error) {
-		downloadedFile, err := p.fs.TempFile("tarballProvider")
+		partialPath := p.cache.PartialPath(source)
+
+		err := p.fs.MkdirAll(filepath.Dir(partialPath), os.FileMode(0766))
 		if err != nil {
-			return true, bosherr.WrapError(err, "Unable to create temporary file")
+			return true, bosherr.WrapError(err, "Creating directory for partial download")
 		}
 
-		defer func() {
-			downloadedFile.Close()
+		offset, validator := p.resumableOffset(source, mirrorURL, partialPath)
 
-			if err = p.fs.RemoveAll(downloadedFile.Name()); err != nil {
-				p.logger.Warn(p.logTag, "Failed to remove downloaded file: %s", err.Error())
+		response, err := p.httpClient.GetCustomized(mirrorURL, func(request *http.Request) {
+			if offset > 0 {
+				request.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+				if validator != "" {
+					request.Header.Set("If-Range", validator)
+				}
 			}
-		}()
-
-		response, err := p.httpClient.Get(source.GetURL())
+		})
 		if err != nil {
 			return true, bosherr.WrapError(err, "Unable to download")
 		}
@@ -132,28 +187,148 @@ func (p *provider) downloadRetryable(source Source) boshretry.Retryable {
 			}
 		}()
 
-		_, err = io.Copy(downloadedFile, response.Body)
+		flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+
+		switch {
+		case response.StatusCode == http.StatusPartialContent && p.startsAt(response, offset):
+			p.logger.Debug(p.logTag, "Resuming download of '%s' at byte %d", mirrorURL, offset)
+			flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+
+		case response.StatusCode == http.StatusPartialContent || response.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+			p.discardPartialDownload(partialPath)
+			return true, bosherr.Errorf("Unable to resume download at byte %d", offset)
+
+		case offset > 0 && response.StatusCode != http.StatusOK:
+			// Only a complete response replaces the partial download, other errors may be transient
+			return true, bosherr.Errorf("Unable to resume download at byte %d: server responded with status %d", offset, response.StatusCode)
+
+		case offset > 0:
+			p.logger.Debug(p.logTag, "Server did not resume download of '%s', downloading from the beginning", mirrorURL)
+		}
+
+		err = p.savePartialDownload(source, mirrorURL, partialPath, response)
+		if err != nil {
+			return true, err
+		}
+
+		downloadedFile, err := p.fs.OpenFile(partialPath, flags, os.FileMode(0644))
 		if err != nil {
-			return true, bosherr.WrapError(err, "Saving downloaded bits to temporary file")
+			return true, bosherr.WrapError(err, "Opening partial download file")
+		}
+
+		_, err = io.Copy(p.progress.ProgressWriter(fmt.Sprintf("Downloading %s", source.Description()), response.ContentLength, downloadedFile), response.Body)
+		downloadedFile.Close()
+		if err != nil {
+			// Partial download is kept so that next attempt can resume it
+			return true, bosherr.WrapError(err, "Saving downloaded bits to partial download file")
 		}
 
 		digest, err := boshcrypto.ParseMultipleDigest(source.GetSHA1())
 		if err != nil {
+			p.discardPartialDownload(partialPath)
 			return true, err
 		}
 
-		err = digest.VerifyFilePath(downloadedFile.Name(), p.fs)
+		err = digest.VerifyFilePath(partialPath, p.fs)
 		if err != nil {
+			p.discardPartialDownload(partialPath)
 			return true, bosherr.WrapError(err, "Verifying digest for downloaded file")
 		}
 
-		downloadedFile.Close()
-
-		err = p.cache.Save(downloadedFile.Name(), source)
+		err = p.cache.Save(partialPath, source)
 		if err != nil {
+			p.discardPartialDownload(partialPath)
 			return true, bosherr.WrapError(err, "Saving downloaded file in cache")
 		}
 
+		p.removePartialDownloadMetadata(partialPath)
+
 		return false, nil
 	})
 }
+
+func (p *provider) resumableOffset(source Source, mirrorURL string, partialPath string) (int64, string) {
+	if !p.fs.FileExists(partialPath) {
+		return 0, ""
+	}
+
+	var metadata partialDownload
+
+	metadataBytes, err := p.fs.ReadFile(p.partialMetadataPath(partialPath))
+	if err == nil {
+		err = json.Unmarshal(metadataBytes, &metadata)
+	}
+
+	if err != nil || !p.isMirror(source, metadata.URL) || metadata.SHA1 != source.GetSHA1() {
+		p.logger.Debug(p.logTag, "Discarding partial download '%s' that cannot be resumed", partialPath)
+		p.discardPartialDownload(partialPath)
+		return 0, ""
+	}
+
+	fileInfo, err := p.fs.Stat(partialPath)
+	if err != nil {
+		p.discardPartialDownload(partialPath)
+		return 0, ""
+	}
+
+	// Validator of another mirror would not match, the digest is verified after resuming instead
+	if metadata.URL != mirrorURL {
+		return fileInfo.Size(), ""
+	}
+
+	return fileInfo.Size(), metadata.Validator
+}
+
+func (p *provider) isMirror(source Source, mirrorURL string) bool {
+	for _, u := range p.mirrorURLs(source) {
+		if u == mirrorURL {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *provider) startsAt(response *http.Response, offset int64) bool {
+	return strings.HasPrefix(response.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset))
+}
+
+func (p *provider) savePartialDownload(source Source, mirrorURL string, partialPath string, response *http.Response) error {
+	validator := response.Header.Get("ETag")
+	if validator == "" {
+		validator = response.Header.Get("Last-Modified")
+	}
+
+	metadataBytes, err := json.Marshal(partialDownload{
+		URL:       mirrorURL,
+		SHA1:      source.GetSHA1(),
+		Validator: validator,
+	})
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling partial download metadata")
+	}
+
+	err = p.fs.WriteFile(p.partialMetadataPath(partialPath), metadataBytes)
+	if err != nil {
+		return bosherr.WrapError(err, "Saving partial download metadata")
+	}
+
+	return nil
+}
+
+func (p *provider) discardPartialDownload(partialPath string) {
+	if err := p.fs.RemoveAll(partialPath); err != nil {
+		p.logger.Warn(p.logTag, "Failed to remove partial download: %s", err.Error())
+	}
+
+	p.removePartialDownloadMetadata(partialPath)
+}
+
+func (p *provider) removePartialDownloadMetadata(partialPath string) {
+	if err := p.fs.RemoveAll(p.partialMetadataPath(partialPath)); err != nil {
+		p.logger.Warn(p.logTag, "Failed to remove partial download metadata: %s", err.Error())
+	}
+}
+
+func (p *provider) partialMetadataPath(partialPath string) string {
+	return partialPath + ".json"
+}
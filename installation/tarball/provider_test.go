@@ -2,7 +2,6 @@ package tarball_test
 
 import (
 	"crypto/sha1"
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -10,6 +9,7 @@ import (
 	"path/filepath"
 
 	. "github.com/cloudfoundry/bosh-cli/installation/tarball"
+	biui "github.com/cloudfoundry/bosh-cli/ui"
 	fakebiui "github.com/cloudfoundry/bosh-cli/ui/fakes"
 	"github.com/cloudfoundry/bosh-utils/httpclient"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
@@ -36,7 +36,7 @@ var _ = Describe("Provider", func() {
 		logger := boshlog.NewLogger(boshlog.LevelNone)
 		cache = NewCache(filepath.Join("/", "fake-base-path"), fs, logger)
 		httpClient := httpclient.NewHTTPClient(httpclient.DefaultClient, logger)
-		provider = NewProvider(cache, fs, httpClient, 3, 0, logger)
+		provider = NewProvider(cache, fs, httpClient, nil, 3, 0, biui.NewNoopProgressReporter(), logger)
 		fakeStage = fakebiui.NewFakeStage()
 	})
 
@@ -70,14 +70,35 @@ var _ = Describe("Provider", func() {
 		})
 
 		Context("when URL starts with http(s)://", func() {
+			var (
+				osFs     boshsys.FileSystem
+				basePath string
+			)
+
 			BeforeEach(func() {
+				logger := boshlog.NewLogger(boshlog.LevelNone)
+				osFs = boshsys.NewOsFileSystem(logger)
+
+				var err error
+				basePath, err = ioutil.TempDir("", "tarball-provider")
+				Expect(err).ToNot(HaveOccurred())
+
+				cache = NewCache(basePath, osFs, logger)
+				httpClient := httpclient.NewHTTPClient(httpclient.DefaultClient, logger)
+				provider = NewProvider(cache, osFs, httpClient, nil, 3, 0, biui.NewNoopProgressReporter(), logger)
+
 				source = newFakeSource(server.URL(), "da39a3ee5e6b4b0d3255bfef95601890afd80709", "fake-description")
 			})
 
+			AfterEach(func() {
+				os.RemoveAll(basePath)
+			})
+
 			Context("when tarball is present in cache", func() {
 				BeforeEach(func() {
-					fs.WriteFileString("fake-source-path", "")
-					cache.Save("fake-source-path", source)
+					sourcePath := filepath.Join(basePath, "fake-source-path")
+					Expect(osFs.WriteFileString(sourcePath, "")).To(Succeed())
+					Expect(cache.Save(sourcePath, source)).To(Succeed())
 				})
 
 				It("returns cached tarball path", func() {
@@ -85,7 +106,7 @@ var _ = Describe("Provider", func() {
 					Expect(err).ToNot(HaveOccurred())
 					shaSum := sha1.Sum([]byte(source.GetURL()))
 					expectedFileName := fmt.Sprintf("%x-da39a3ee5e6b4b0d3255bfef95601890afd80709", string(shaSum[:]))
-					Expect(path).To(Equal(filepath.Join("/", "fake-base-path", expectedFileName)))
+					Expect(path).To(Equal(filepath.Join(basePath, expectedFileName)))
 				})
 
 				It("skips downloading stage", func() {
@@ -99,28 +120,12 @@ var _ = Describe("Provider", func() {
 
 			Context("when tarball is not present in cache", func() {
 				var (
-					tempDownloadFilePath1 string
-					tempDownloadFilePath2 string
-					tempDownloadFilePath3 string
+					partialPath string
 				)
 
 				BeforeEach(func() {
-					tempDownloadFile1, err := ioutil.TempFile("", "temp-download-file1")
-					Expect(err).ToNot(HaveOccurred())
-					tempDownloadFile2, err := ioutil.TempFile("", "temp-download-file2")
-					Expect(err).ToNot(HaveOccurred())
-					tempDownloadFile3, err := ioutil.TempFile("", "temp-download-file3")
-					Expect(err).ToNot(HaveOccurred())
-					fs.ReturnTempFiles = []boshsys.File{tempDownloadFile1, tempDownloadFile2, tempDownloadFile3}
-					tempDownloadFilePath1 = tempDownloadFile1.Name()
-					tempDownloadFilePath2 = tempDownloadFile2.Name()
-					tempDownloadFilePath3 = tempDownloadFile3.Name()
-				})
-
-				AfterEach(func() {
-					os.RemoveAll(tempDownloadFilePath1)
-					os.RemoveAll(tempDownloadFilePath2)
-					os.RemoveAll(tempDownloadFilePath3)
+					source = newFakeSource(server.URL(), "fab3c263ec568e150550b814e84b7898d477c3c2", "fake-description")
+					partialPath = cache.PartialPath(source)
 				})
 
 				Context("when downloading succeds", func() {
@@ -139,9 +144,26 @@ var _ = Describe("Provider", func() {
 						path, err := provider.Get(source, fakeStage)
 						Expect(err).ToNot(HaveOccurred())
 						shaSum := sha1.Sum([]byte(source.GetURL()))
-						expectedFileName := fmt.Sprintf("%x-da39a3ee5e6b4b0d3255bfef95601890afd80709", string(shaSum[:]))
-						Expect(path).To(Equal(filepath.Join("/", "fake-base-path", expectedFileName)))
+						expectedFileName := fmt.Sprintf("%x-fab3c263ec568e150550b814e84b7898d477c3c2", string(shaSum[:]))
+						Expect(path).To(Equal(filepath.Join(basePath, expectedFileName)))
 						Expect(server.ReceivedRequests()).To(HaveLen(1))
+
+						contents, err := osFs.ReadFileString(path)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(contents).To(Equal("fake-body"))
+					})
+
+					It("does not request a range", func() {
+						_, err := provider.Get(source, fakeStage)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(server.ReceivedRequests()[0].Header.Get("Range")).To(BeEmpty())
+					})
+
+					It("does not leave a partial download", func() {
+						_, err := provider.Get(source, fakeStage)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(osFs.FileExists(partialPath)).To(BeFalse())
+						Expect(osFs.FileExists(partialPath + ".json")).To(BeFalse())
 					})
 
 					It("logs downloading stage", func() {
@@ -156,48 +178,30 @@ var _ = Describe("Provider", func() {
 					Context("when sha1 does not match", func() {
 						BeforeEach(func() {
 							source = newFakeSource(server.URL(), "expectedsha1", "fake-description")
+							partialPath = cache.PartialPath(source)
 						})
 
 						It("returns an error", func() {
 							_, err := provider.Get(source, fakeStage)
 							Expect(err).To(HaveOccurred())
-							Expect(err.Error()).To(ContainSubstring("Failed to download from '%s': Verifying digest for downloaded file: Expected stream to have digest 'expectedsha1' but was 'da39a3ee5e6b4b0d3255bfef95601890afd80709'", server.URL()))
+							Expect(err.Error()).To(ContainSubstring("Failed to download from '%s': Verifying digest for downloaded file: Expected stream to have digest 'expectedsha1' but was 'fab3c263ec568e150550b814e84b7898d477c3c2'", server.URL()))
 						})
 
-						It("retries downloading up to 3 times", func() {
+						It("retries downloading up to 3 times from the beginning", func() {
 							_, err := provider.Get(source, fakeStage)
 							Expect(err).To(HaveOccurred())
 
 							Expect(server.ReceivedRequests()).To(HaveLen(3))
+							for _, request := range server.ReceivedRequests() {
+								Expect(request.Header.Get("Range")).To(BeEmpty())
+							}
 						})
 
-						It("removes the downloaded file", func() {
+						It("removes the partial download", func() {
 							_, err := provider.Get(source, fakeStage)
 							Expect(err).To(HaveOccurred())
-							Expect(fs.FileExists(tempDownloadFilePath1)).To(BeFalse())
-							Expect(fs.FileExists(tempDownloadFilePath2)).To(BeFalse())
-							Expect(fs.FileExists(tempDownloadFilePath3)).To(BeFalse())
-						})
-					})
-
-					Context("when saving to cache fails", func() {
-						BeforeEach(func() {
-							// Creating cache base directory fails
-							fs.MkdirAllError = errors.New("fake-mkdir-error")
-						})
-
-						It("returns an error", func() {
-							_, err := provider.Get(source, fakeStage)
-							Expect(err).To(HaveOccurred())
-							Expect(err.Error()).To(ContainSubstring("fake-mkdir-error"))
-						})
-
-						It("removes the downloaded file", func() {
-							_, err := provider.Get(source, fakeStage)
-							Expect(err).To(HaveOccurred())
-							Expect(fs.FileExists(tempDownloadFilePath1)).To(BeFalse())
-							Expect(fs.FileExists(tempDownloadFilePath2)).To(BeFalse())
-							Expect(fs.FileExists(tempDownloadFilePath3)).To(BeFalse())
+							Expect(osFs.FileExists(partialPath)).To(BeFalse())
+							Expect(osFs.FileExists(partialPath + ".json")).To(BeFalse())
 						})
 					})
 				})
@@ -229,12 +233,310 @@ var _ = Describe("Provider", func() {
 						Expect(server.ReceivedRequests()).To(HaveLen(3))
 					})
 
-					It("removes the downloaded file", func() {
+					It("does not leave a partial download", func() {
 						_, err := provider.Get(source, fakeStage)
 						Expect(err).To(HaveOccurred())
-						Expect(fs.FileExists(tempDownloadFilePath1)).To(BeFalse())
-						Expect(fs.FileExists(tempDownloadFilePath2)).To(BeFalse())
-						Expect(fs.FileExists(tempDownloadFilePath3)).To(BeFalse())
+						Expect(osFs.FileExists(partialPath)).To(BeFalse())
+					})
+				})
+			})
+
+			Context("when mirrors serve the URL", func() {
+				var (
+					mirror      *ghttp.Server
+					partialPath string
+				)
+
+				disconnectingRequestHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					conn, _, err := w.(http.Hijacker).Hijack()
+					Expect(err).NotTo(HaveOccurred())
+
+					conn.Close()
+				})
+
+				BeforeEach(func() {
+					mirror = ghttp.NewServer()
+					source = newFakeSource(server.URL()+"/d/tarball", "fab3c263ec568e150550b814e84b7898d477c3c2", "fake-description")
+					partialPath = cache.PartialPath(source)
+
+					logger := boshlog.NewLogger(boshlog.LevelNone)
+					httpClient := httpclient.NewHTTPClient(httpclient.DefaultClient, logger)
+					mirrors := []Mirror{
+						{URL: server.URL() + "/d/", MirrorURL: mirror.URL() + "/mirror/"},
+						{URL: "https://example.com/", MirrorURL: "https://other-mirror.example.com/"},
+					}
+					provider = NewProvider(cache, osFs, httpClient, mirrors, 3, 0, biui.NewNoopProgressReporter(), logger)
+				})
+
+				AfterEach(func() {
+					mirror.Close()
+				})
+
+				It("downloads from the first mirror when it succeeds", func() {
+					server.AppendHandlers(ghttp.RespondWith(200, "fake-body"))
+
+					path, err := provider.Get(source, fakeStage)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(server.ReceivedRequests()).To(HaveLen(1))
+					Expect(mirror.ReceivedRequests()).To(BeEmpty())
+
+					contents, err := osFs.ReadFileString(path)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(contents).To(Equal("fake-body"))
+				})
+
+				It("falls back to the next mirror after retrying the first one", func() {
+					server.AppendHandlers(disconnectingRequestHandler, disconnectingRequestHandler, disconnectingRequestHandler)
+					mirror.AppendHandlers(ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/mirror/tarball"),
+						ghttp.RespondWith(200, "fake-body"),
+					))
+
+					path, err := provider.Get(source, fakeStage)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(server.ReceivedRequests()).To(HaveLen(3))
+					Expect(mirror.ReceivedRequests()).To(HaveLen(1))
+
+					contents, err := osFs.ReadFileString(path)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(contents).To(Equal("fake-body"))
+
+					Expect(fakeStage.PerformCalls).To(Equal([]*fakebiui.PerformCall{
+						{Name: "Downloading fake-description"},
+					}))
+				})
+
+				It("returns the errors of all mirrors when every mirror fails", func() {
+					server.AppendHandlers(disconnectingRequestHandler, disconnectingRequestHandler, disconnectingRequestHandler)
+					mirror.AppendHandlers(disconnectingRequestHandler, disconnectingRequestHandler, disconnectingRequestHandler)
+
+					_, err := provider.Get(source, fakeStage)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Failed to download from '%s/d/tarball'", server.URL()))
+					Expect(err.Error()).To(ContainSubstring("Failed to download from '%s/mirror/tarball'", mirror.URL()))
+				})
+
+				It("resumes a partial download of another mirror without its validator", func() {
+					Expect(osFs.WriteFileString(partialPath, "fake-")).To(Succeed())
+					Expect(osFs.WriteFileString(partialPath+".json", fmt.Sprintf(
+						`{"url":"%s/d/tarball","sha1":"fab3c263ec568e150550b814e84b7898d477c3c2","validator":"\"fake-etag\""}`, server.URL()))).To(Succeed())
+
+					server.AppendHandlers(disconnectingRequestHandler, disconnectingRequestHandler, disconnectingRequestHandler)
+					mirror.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyHeaderKV("Range", "bytes=5-"),
+							func(_ http.ResponseWriter, request *http.Request) {
+								Expect(request.Header.Get("If-Range")).To(BeEmpty())
+							},
+							ghttp.RespondWith(206, "body", http.Header{"Content-Range": {"bytes 5-8/9"}}),
+						),
+					)
+
+					path, err := provider.Get(source, fakeStage)
+					Expect(err).ToNot(HaveOccurred())
+
+					contents, err := osFs.ReadFileString(path)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(contents).To(Equal("fake-body"))
+				})
+
+				It("only tries mirrors serving the URL", func() {
+					source = newFakeSource(server.URL()+"/other/tarball", "fab3c263ec568e150550b814e84b7898d477c3c2", "fake-description")
+					server.AppendHandlers(disconnectingRequestHandler, disconnectingRequestHandler, disconnectingRequestHandler)
+
+					_, err := provider.Get(source, fakeStage)
+					Expect(err).To(HaveOccurred())
+					Expect(server.ReceivedRequests()).To(HaveLen(3))
+					Expect(mirror.ReceivedRequests()).To(BeEmpty())
+				})
+			})
+
+			Context("when a partial download exists", func() {
+				var (
+					partialPath string
+				)
+
+				BeforeEach(func() {
+					source = newFakeSource(server.URL(), "fab3c263ec568e150550b814e84b7898d477c3c2", "fake-description")
+					partialPath = cache.PartialPath(source)
+
+					Expect(osFs.WriteFileString(partialPath, "fake-")).To(Succeed())
+					Expect(osFs.WriteFileString(partialPath+".json", fmt.Sprintf(
+						`{"url":"%s","sha1":"fab3c263ec568e150550b814e84b7898d477c3c2","validator":"\"fake-etag\""}`, server.URL()))).To(Succeed())
+				})
+
+				Context("when the server supports range requests", func() {
+					BeforeEach(func() {
+						server.AppendHandlers(
+							ghttp.CombineHandlers(
+								ghttp.VerifyRequest("GET", "/"),
+								ghttp.VerifyHeaderKV("Range", "bytes=5-"),
+								ghttp.VerifyHeaderKV("If-Range", `"fake-etag"`),
+								ghttp.RespondWith(206, "body", http.Header{"Content-Range": {"bytes 5-8/9"}}),
+							),
+						)
+					})
+
+					It("resumes the download and saves the complete file in cache", func() {
+						path, err := provider.Get(source, fakeStage)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(server.ReceivedRequests()).To(HaveLen(1))
+
+						contents, err := osFs.ReadFileString(path)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(contents).To(Equal("fake-body"))
+
+						Expect(osFs.FileExists(partialPath)).To(BeFalse())
+						Expect(osFs.FileExists(partialPath + ".json")).To(BeFalse())
+					})
+				})
+
+				Context("when the server does not support range requests", func() {
+					BeforeEach(func() {
+						server.AppendHandlers(
+							ghttp.CombineHandlers(
+								ghttp.VerifyHeaderKV("Range", "bytes=5-"),
+								ghttp.RespondWith(200, "fake-body"),
+							),
+						)
+					})
+
+					It("downloads the complete file again", func() {
+						path, err := provider.Get(source, fakeStage)
+						Expect(err).ToNot(HaveOccurred())
+
+						contents, err := osFs.ReadFileString(path)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(contents).To(Equal("fake-body"))
+					})
+				})
+
+				Context("when the server fails to respond to the range request", func() {
+					BeforeEach(func() {
+						server.AppendHandlers(
+							ghttp.RespondWith(503, "fake-error"),
+							ghttp.CombineHandlers(
+								ghttp.VerifyHeaderKV("Range", "bytes=5-"),
+								ghttp.RespondWith(206, "body", http.Header{"Content-Range": {"bytes 5-8/9"}}),
+							),
+						)
+					})
+
+					It("keeps the partial download and resumes it on the next attempt", func() {
+						path, err := provider.Get(source, fakeStage)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(server.ReceivedRequests()).To(HaveLen(2))
+
+						contents, err := osFs.ReadFileString(path)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(contents).To(Equal("fake-body"))
+					})
+				})
+
+				Context("when the server cannot satisfy the range", func() {
+					BeforeEach(func() {
+						server.AppendHandlers(
+							ghttp.CombineHandlers(
+								ghttp.VerifyHeaderKV("Range", "bytes=5-"),
+								ghttp.RespondWith(416, ""),
+							),
+							ghttp.CombineHandlers(
+								func(_ http.ResponseWriter, request *http.Request) {
+									Expect(request.Header.Get("Range")).To(BeEmpty())
+								},
+								ghttp.RespondWith(200, "fake-body"),
+							),
+						)
+					})
+
+					It("discards the partial download and restarts it", func() {
+						path, err := provider.Get(source, fakeStage)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(server.ReceivedRequests()).To(HaveLen(2))
+
+						contents, err := osFs.ReadFileString(path)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(contents).To(Equal("fake-body"))
+					})
+				})
+
+				Context("when the resumed download does not match the sha1", func() {
+					BeforeEach(func() {
+						server.AppendHandlers(
+							ghttp.RespondWith(206, "other", http.Header{"Content-Range": {"bytes 5-9/10"}}),
+							ghttp.CombineHandlers(
+								func(_ http.ResponseWriter, request *http.Request) {
+									Expect(request.Header.Get("Range")).To(BeEmpty())
+								},
+								ghttp.RespondWith(200, "fake-body"),
+							),
+						)
+					})
+
+					It("discards the assembled file and restarts the download", func() {
+						path, err := provider.Get(source, fakeStage)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(server.ReceivedRequests()).To(HaveLen(2))
+
+						contents, err := osFs.ReadFileString(path)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(contents).To(Equal("fake-body"))
+					})
+				})
+
+				Context("when the partial download belongs to another source", func() {
+					BeforeEach(func() {
+						Expect(osFs.WriteFileString(partialPath+".json", `{"url":"other-url","sha1":"other-sha1"}`)).To(Succeed())
+
+						server.AppendHandlers(
+							ghttp.CombineHandlers(
+								func(_ http.ResponseWriter, request *http.Request) {
+									Expect(request.Header.Get("Range")).To(BeEmpty())
+								},
+								ghttp.RespondWith(200, "fake-body"),
+							),
+						)
+					})
+
+					It("downloads the complete file", func() {
+						path, err := provider.Get(source, fakeStage)
+						Expect(err).ToNot(HaveOccurred())
+
+						contents, err := osFs.ReadFileString(path)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(contents).To(Equal("fake-body"))
+					})
+				})
+
+				Context("when the download is interrupted again", func() {
+					BeforeEach(func() {
+						server.AppendHandlers(
+							http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+								w.Header().Set("Content-Range", "bytes 5-8/9")
+								w.Header().Set("Content-Length", "4")
+								w.WriteHeader(206)
+								w.Write([]byte("bo"))
+								w.(http.Flusher).Flush()
+
+								conn, _, err := w.(http.Hijacker).Hijack()
+								Expect(err).NotTo(HaveOccurred())
+								conn.Close()
+							}),
+							ghttp.CombineHandlers(
+								ghttp.VerifyHeaderKV("Range", "bytes=7-"),
+								ghttp.RespondWith(206, "dy", http.Header{"Content-Range": {"bytes 7-8/9"}}),
+							),
+						)
+					})
+
+					It("keeps the downloaded bits and resumes from them", func() {
+						path, err := provider.Get(source, fakeStage)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(server.ReceivedRequests()).To(HaveLen(2))
+
+						contents, err := osFs.ReadFileString(path)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(contents).To(Equal("fake-body"))
 					})
 				})
 			})
@@ -5,24 +5,63 @@ import (
 )
 
 type Target struct {
-	path string
+	path                      string
+	compiledPackagedIndexPath string
+	compiledPackageCachePath  string
+	tmpPath                   string
 }
 
 func NewTarget(path string) Target {
 	return Target{
-		path,
+		path: path,
 	}
 }
 
+// WithCompiledPackagedIndexPath returns a copy of the target that keeps its
+// compiled package index at the given path instead of inside the target.
+// The blobs the index refers to are kept next to it so that other
+// installations using the same index find them.
+func (t Target) WithCompiledPackagedIndexPath(path string) Target {
+	t.compiledPackagedIndexPath = path
+	return t
+}
+
+// WithCompiledPackageCachePath returns a copy of the target that keeps its
+// compiled package index and blobs in a cache directory that can be shared
+// by several installations, so that packages compiled for one are reused by the others.
+func (t Target) WithCompiledPackageCachePath(path string) Target {
+	t.compiledPackageCachePath = path
+	return t
+}
+
+// WithTmpPath returns a copy of the target that keeps its temporary files
+// at the given path instead of inside the target.
+func (t Target) WithTmpPath(path string) Target {
+	t.tmpPath = path
+	return t
+}
+
 func (t Target) Path() string {
 	return t.path
 }
 
 func (t Target) BlobstorePath() string {
+	if t.compiledPackagedIndexPath != "" {
+		return filepath.Join(filepath.Dir(t.compiledPackagedIndexPath), "blobs")
+	}
+	if t.compiledPackageCachePath != "" {
+		return filepath.Join(t.compiledPackageCachePath, "blobs")
+	}
 	return filepath.Join(t.path, "blobs")
 }
 
 func (t Target) CompiledPackagedIndexPath() string {
+	if t.compiledPackagedIndexPath != "" {
+		return t.compiledPackagedIndexPath
+	}
+	if t.compiledPackageCachePath != "" {
+		return filepath.Join(t.compiledPackageCachePath, "compiled_packages.json")
+	}
 	return filepath.Join(t.path, "compiled_packages.json")
 }
 
@@ -39,5 +78,8 @@ func (t Target) JobsPath() string {
 }
 
 func (t Target) TmpPath() string {
+	if t.tmpPath != "" {
+		return t.tmpPath
+	}
 	return filepath.Join(t.path, "tmp")
 }
@@ -1,10 +1,12 @@
 package installation
 
 import (
+	"os"
 	"path/filepath"
 
 	biconfig "github.com/cloudfoundry/bosh-cli/config"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
 	boshuuid "github.com/cloudfoundry/bosh-utils/uuid"
 )
 
@@ -16,21 +18,39 @@ type targetProvider struct {
 	deploymentStateService biconfig.DeploymentStateService
 	uuidGenerator          boshuuid.Generator
 	installationsRootPath  string
+
+	compiledPackagedIndexPath string
+	compiledPackageCachePath  string
+	tmpRootPath               string
+	fs                        boshsys.FileSystem
 }
 
 func NewTargetProvider(
 	deploymentStateService biconfig.DeploymentStateService,
 	uuidGenerator boshuuid.Generator,
 	installationsRootPath string,
+	compiledPackagedIndexPath string,
+	compiledPackageCachePath string,
+	tmpRootPath string,
+	fs boshsys.FileSystem,
 ) TargetProvider {
 	return &targetProvider{
 		deploymentStateService: deploymentStateService,
 		uuidGenerator:          uuidGenerator,
 		installationsRootPath:  installationsRootPath,
+
+		compiledPackagedIndexPath: compiledPackagedIndexPath,
+		compiledPackageCachePath:  compiledPackageCachePath,
+		tmpRootPath:               tmpRootPath,
+		fs:                        fs,
 	}
 }
 
 func (p *targetProvider) NewTarget() (Target, error) {
+	if p.compiledPackagedIndexPath != "" && p.compiledPackageCachePath != "" {
+		return Target{}, bosherr.Error("Expected only one of a compiled package index path and a compiled package cache path")
+	}
+
 	deploymentState, err := p.deploymentStateService.Load()
 	if err != nil {
 		return Target{}, bosherr.WrapError(err, "Loading deployment state")
@@ -50,5 +70,91 @@ func (p *targetProvider) NewTarget() (Target, error) {
 		}
 	}
 
-	return NewTarget(filepath.Join(p.installationsRootPath, installationID)), nil
+	target := NewTarget(filepath.Join(p.installationsRootPath, installationID))
+
+	if p.compiledPackagedIndexPath != "" {
+		indexPath, err := p.validatedCompiledPackagedIndexPath()
+		if err != nil {
+			return Target{}, err
+		}
+
+		target = target.WithCompiledPackagedIndexPath(indexPath)
+	}
+
+	if p.compiledPackageCachePath != "" {
+		cachePath, err := p.createdCompiledPackageCachePath()
+		if err != nil {
+			return Target{}, err
+		}
+
+		target = target.WithCompiledPackageCachePath(cachePath)
+	}
+
+	if p.tmpRootPath != "" {
+		target = target.WithTmpPath(filepath.Join(p.tmpRootPath, installationID))
+	}
+
+	return target, nil
+}
+
+func (p *targetProvider) createdCompiledPackageCachePath() (string, error) {
+	cachePath, err := p.fs.ExpandPath(p.compiledPackageCachePath)
+	if err != nil {
+		return "", bosherr.WrapErrorf(err, "Expanding compiled package cache path '%s'", p.compiledPackageCachePath)
+	}
+
+	if p.fs.FileExists(cachePath) {
+		fileInfo, err := p.fs.Stat(cachePath)
+		if err != nil {
+			return "", bosherr.WrapErrorf(err, "Checking compiled package cache '%s'", cachePath)
+		}
+
+		if !fileInfo.IsDir() {
+			return "", bosherr.Errorf("Compiled package cache path '%s' must be a directory", cachePath)
+		}
+
+		return cachePath, nil
+	}
+
+	err = p.fs.MkdirAll(cachePath, os.ModePerm)
+	if err != nil {
+		return "", bosherr.WrapErrorf(err, "Creating compiled package cache '%s'", cachePath)
+	}
+
+	return cachePath, nil
+}
+
+func (p *targetProvider) validatedCompiledPackagedIndexPath() (string, error) {
+	indexPath, err := p.fs.ExpandPath(p.compiledPackagedIndexPath)
+	if err != nil {
+		return "", bosherr.WrapErrorf(err, "Expanding compiled package index path '%s'", p.compiledPackagedIndexPath)
+	}
+
+	if p.fs.FileExists(indexPath) {
+		fileInfo, err := p.fs.Stat(indexPath)
+		if err != nil {
+			return "", bosherr.WrapErrorf(err, "Checking compiled package index '%s'", indexPath)
+		}
+
+		if fileInfo.IsDir() {
+			return "", bosherr.Errorf("Compiled package index path '%s' must be a file, not a directory", indexPath)
+		}
+	}
+
+	parentPath := filepath.Dir(indexPath)
+
+	if !p.fs.FileExists(parentPath) {
+		return "", bosherr.Errorf("Compiled package index directory '%s' does not exist", parentPath)
+	}
+
+	parentInfo, err := p.fs.Stat(parentPath)
+	if err != nil {
+		return "", bosherr.WrapErrorf(err, "Checking compiled package index directory '%s'", parentPath)
+	}
+
+	if !parentInfo.IsDir() {
+		return "", bosherr.Errorf("Compiled package index directory '%s' is not a directory", parentPath)
+	}
+
+	return indexPath, nil
 }
@@ -36,7 +36,7 @@ var _ = Describe("TargetProvider", func() {
 			logger,
 			configPath,
 		)
-		targetProvider = NewTargetProvider(deploymentStateService, fakeUUIDGenerator, installationsRootPath)
+		targetProvider = NewTargetProvider(deploymentStateService, fakeUUIDGenerator, installationsRootPath, "", "", "", fakeFS)
 	})
 
 	Context("when the installation_id exists in the deployment state", func() {
@@ -104,4 +104,96 @@ var _ = Describe("TargetProvider", func() {
 			Expect(deploymentState.InstallationID).To(Equal("fake-uuid-1"))
 		})
 	})
+
+	Context("when a compiled package index path is provided", func() {
+		var indexPath = filepath.Join("/", "shared", "compiled_packages.json")
+
+		BeforeEach(func() {
+			targetProvider = NewTargetProvider(deploymentStateService, fakeUUIDGenerator, installationsRootPath, indexPath, "", "", fakeFS)
+		})
+
+		It("returns a target using the provided compiled package index path", func() {
+			err := fakeFS.MkdirAll(filepath.Dir(indexPath), 0755)
+			Expect(err).ToNot(HaveOccurred())
+
+			target, err := targetProvider.NewTarget()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(target.Path()).To(Equal(filepath.Join("/", ".bosh", "installations", "fake-uuid-1")))
+			Expect(target.CompiledPackagedIndexPath()).To(Equal(indexPath))
+		})
+
+		It("returns an error when the index path is a directory", func() {
+			err := fakeFS.MkdirAll(indexPath, 0755)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = targetProvider.NewTarget()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Compiled package index path '/shared/compiled_packages.json' must be a file, not a directory"))
+		})
+
+		It("returns an error when the parent directory does not exist", func() {
+			_, err := targetProvider.NewTarget()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Compiled package index directory '/shared' does not exist"))
+		})
+
+		It("returns an error when the parent path is not a directory", func() {
+			err := fakeFS.WriteFileString(filepath.Dir(indexPath), "")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = targetProvider.NewTarget()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Compiled package index directory '/shared' is not a directory"))
+		})
+	})
+
+	Context("when a compiled package cache path is provided", func() {
+		var cachePath = filepath.Join("/", "shared", "cache")
+
+		BeforeEach(func() {
+			targetProvider = NewTargetProvider(deploymentStateService, fakeUUIDGenerator, installationsRootPath, "", cachePath, "", fakeFS)
+		})
+
+		It("returns a target using the compiled package cache and creates it", func() {
+			target, err := targetProvider.NewTarget()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(target.Path()).To(Equal(filepath.Join("/", ".bosh", "installations", "fake-uuid-1")))
+			Expect(target.CompiledPackagedIndexPath()).To(Equal(filepath.Join(cachePath, "compiled_packages.json")))
+			Expect(target.BlobstorePath()).To(Equal(filepath.Join(cachePath, "blobs")))
+			Expect(fakeFS.FileExists(cachePath)).To(BeTrue())
+		})
+
+		It("returns an error when the cache path is not a directory", func() {
+			err := fakeFS.WriteFileString(cachePath, "")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = targetProvider.NewTarget()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Compiled package cache path '/shared/cache' must be a directory"))
+		})
+
+		It("returns an error when a compiled package index path is provided as well", func() {
+			targetProvider = NewTargetProvider(deploymentStateService, fakeUUIDGenerator, installationsRootPath, "/shared/compiled_packages.json", cachePath, "", fakeFS)
+
+			_, err := targetProvider.NewTarget()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected only one of a compiled package index path and a compiled package cache path"))
+		})
+	})
+
+	Context("when a tmp root path is provided", func() {
+		BeforeEach(func() {
+			err := fakeFS.WriteFileString(configPath, `{"installation_id": "12345"}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			targetProvider = NewTargetProvider(deploymentStateService, fakeUUIDGenerator, installationsRootPath, "", "", filepath.Join("/", "big", "tmp"), fakeFS)
+		})
+
+		It("keeps temporary files of the target below it", func() {
+			target, err := targetProvider.NewTarget()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(target.Path()).To(Equal(filepath.Join("/", ".bosh", "installations", "12345")))
+			Expect(target.TmpPath()).To(Equal(filepath.Join("/", "big", "tmp", "12345")))
+		})
+	})
 })
@@ -23,6 +23,20 @@ var _ = Describe("Target", func() {
 			Expect(target.CompiledPackagedIndexPath()).To(Equal(filepath.Join("/", "home", "fake", "madcow", "compiled_packages.json")))
 		})
 
+		It("returns the overridden compiled packages index path", func() {
+			target = target.WithCompiledPackagedIndexPath("/shared/compiled_packages.json")
+			Expect(target.CompiledPackagedIndexPath()).To(Equal("/shared/compiled_packages.json"))
+			Expect(target.BlobstorePath()).To(Equal(filepath.Join("/", "shared", "blobs")))
+			Expect(target.Path()).To(Equal("/home/fake/madcow"))
+		})
+
+		It("keeps the compiled packages index and blobs in the compiled package cache", func() {
+			target = target.WithCompiledPackageCachePath("/shared/cache")
+			Expect(target.CompiledPackagedIndexPath()).To(Equal(filepath.Join("/", "shared", "cache", "compiled_packages.json")))
+			Expect(target.BlobstorePath()).To(Equal(filepath.Join("/", "shared", "cache", "blobs")))
+			Expect(target.PackagesPath()).To(Equal(filepath.Join("/", "home", "fake", "madcow", "packages")))
+		})
+
 		It("returns the templates index path", func() {
 			Expect(target.TemplatesIndexPath()).To(Equal(filepath.Join("/", "home", "fake", "madcow", "templates.json")))
 		})
@@ -7,6 +7,7 @@ import (
 	"text/template"
 	"time"
 
+	"code.cloudfoundry.org/clock"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	biproperty "github.com/cloudfoundry/bosh-utils/property"
@@ -54,6 +55,7 @@ import (
 	fakebistemcell "github.com/cloudfoundry/bosh-cli/stemcell/stemcellfakes"
 	biui "github.com/cloudfoundry/bosh-cli/ui"
 	fakebiui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
 	"github.com/cloudfoundry/bosh-utils/fileutil/fakes"
 )
 
@@ -307,7 +309,7 @@ cloud_provider:
 			installedJob.Name = "fake-cpi-release-job-name"
 			installedJob.Path = filepath.Join(target.JobsPath(), "fake-cpi-release-job-name")
 
-			installation := biinstall.NewInstallation(target, installedJob, installationManifest, registryServerManager)
+			installation := biinstall.NewInstallation(target, installedJob, nil, installationManifest, registryServerManager)
 
 			mockInstallerFactory.EXPECT().NewInstaller(target).Return(mockInstaller).AnyTimes()
 
@@ -379,24 +381,26 @@ cloud_provider:
 		}
 
 		var newCreateEnvCmd = func() *CreateEnvCmd {
-			deploymentParser := bideplmanifest.NewParser(fs, logger)
+			warnings := biwarn.NewWarnings(logger)
+			deploymentParser := bideplmanifest.NewParser(fs, warnings, logger)
 			releaseSetValidator := birelsetmanifest.NewValidator(logger)
 			releaseSetParser := birelsetmanifest.NewParser(fs, logger, releaseSetValidator)
 			fakeRegistryUUIDGenerator = fakeuuid.NewFakeGenerator()
 			fakeRegistryUUIDGenerator.GeneratedUUID = "registry-password"
 			installationValidator := biinstallmanifest.NewValidator(logger)
-			installationParser := biinstallmanifest.NewParser(fs, fakeRegistryUUIDGenerator, logger, installationValidator)
+			installationParser := biinstallmanifest.NewParser(fs, fakeRegistryUUIDGenerator, logger, installationValidator, "")
 
-			deploymentValidator := bideplmanifest.NewValidator(logger)
+			deploymentValidator := bideplmanifest.NewValidator(warnings, logger)
 
-			instanceFactory := biinstance.NewFactory(mockStateBuilderFactory)
+			instanceFactory := biinstance.NewFactory(mockStateBuilderFactory, 10*time.Minute)
 			instanceManagerFactory := biinstance.NewManagerFactory(sshTunnelFactory, instanceFactory, logger)
 
 			pingTimeout := 1 * time.Second
 			pingDelay := 100 * time.Millisecond
-			deploymentFactory := bidepl.NewFactory(pingTimeout, pingDelay)
+			deploymentFactory := bidepl.NewFactory(pingTimeout, pingDelay, clock.NewClock(), warnings, logger)
 
-			ui := biui.NewWriterUI(stdOut, stdErr, logger)
+			// Non-interactive like with --non-interactive, so that disk migrations are not confirmed
+			ui := biui.NewNonInteractiveUI(biui.NewWriterUI(stdOut, stdErr, logger))
 			doGet := func(deploymentManifestPath string, statePath string, deploymentVars boshtpl.Variables, deploymentOp patch.Op) DeploymentPreparer {
 				// todo: figure this out?
 				deploymentStateService = biconfig.NewFileSystemDeploymentStateService(fs, fakeUUIDGenerator, logger, biconfig.DeploymentStatePath(deploymentManifestPath, statePath))
@@ -411,7 +415,7 @@ cloud_provider:
 				stemcellManagerFactory = bistemcell.NewManagerFactory(stemcellRepo)
 				diskManagerFactory = bidisk.NewManagerFactory(diskRepo, logger)
 				diskDeployer = bivm.NewDiskDeployer(diskManagerFactory, diskRepo, logger, false)
-				vmManagerFactory = bivm.NewManagerFactory(vmRepo, stemcellRepo, diskDeployer, fakeAgentIDGenerator, fs, logger)
+				vmManagerFactory = bivm.NewManagerFactory(vmRepo, stemcellRepo, diskDeployer, fakeAgentIDGenerator, fs, warnings, logger)
 				deployer := bidepl.NewDeployer(
 					vmManagerFactory,
 					instanceManagerFactory,
@@ -419,7 +423,7 @@ cloud_provider:
 					logger,
 				)
 				tarballCache := bitarball.NewCache("fake-base-path", fs, logger)
-				tarballProvider := bitarball.NewProvider(tarballCache, fs, nil, 1, 0, logger)
+				tarballProvider := bitarball.NewProvider(tarballCache, fs, nil, nil, 1, 0, biui.NewNoopProgressReporter(), logger)
 
 				cpiInstaller := bicpirel.CpiInstaller{
 					ReleaseManager:   releaseManager,
@@ -441,6 +445,8 @@ cloud_provider:
 					deploymentValidator,
 					releaseManager,
 					bidepltpl.NewDeploymentTemplateFactory(fs),
+					nil,
+					logger,
 				)
 
 				installationUuidGenerator := fakeuuid.NewFakeGenerator()
@@ -449,6 +455,10 @@ cloud_provider:
 					deploymentStateService,
 					installationUuidGenerator,
 					filepath.Join("fake-install-dir"),
+					"",
+					"",
+					"",
+					fs,
 				)
 
 				tempRootConfigurator := NewTempRootConfigurator(fs)
@@ -457,6 +467,7 @@ cloud_provider:
 					ui,
 					logger,
 					"deployCmd",
+					fs,
 					deploymentStateService,
 					legacyDeploymentStateMigrator,
 					releaseManager,
@@ -467,6 +478,7 @@ cloud_provider:
 					vmManagerFactory,
 					mockBlobstoreFactory,
 					deployer,
+					bisshtunnel.NewPoolingFactory(sshTunnelFactory, logger),
 					deploymentManifestPath,
 					deploymentVars,
 					deploymentOp,
@@ -475,8 +487,12 @@ cloud_provider:
 					stemcellFetcher,
 					releaseSetAndInstallationManifestParser,
 					deploymentManifestParser,
+					deploymentValidator,
 					tempRootConfigurator,
 					targetProvider,
+					warnings,
+					false,
+					1,
 				)
 			}
 
@@ -511,6 +527,7 @@ cloud_provider:
 				mockAgentClient.EXPECT().Start(),
 				mockAgentClient.EXPECT().GetState().Return(agentRunningState, nil),
 				mockAgentClient.EXPECT().RunScript("post-start", map[string]interface{}{}),
+				mockAgentClient.EXPECT().GetState().Return(agentRunningState, nil),
 			)
 		}
 
@@ -563,6 +580,7 @@ cloud_provider:
 				mockAgentClient.EXPECT().Start(),
 				mockAgentClient.EXPECT().GetState().Return(agentRunningState, nil),
 				mockAgentClient.EXPECT().RunScript("post-start", map[string]interface{}{}),
+				mockAgentClient.EXPECT().GetState().Return(agentRunningState, nil),
 			)
 		}
 
@@ -611,6 +629,7 @@ cloud_provider:
 				mockAgentClient.EXPECT().Start(),
 				mockAgentClient.EXPECT().GetState().Return(agentRunningState, nil),
 				mockAgentClient.EXPECT().RunScript("post-start", map[string]interface{}{}),
+				mockAgentClient.EXPECT().GetState().Return(agentRunningState, nil),
 			)
 		}
 
@@ -731,6 +750,7 @@ cloud_provider:
 				mockAgentClient.EXPECT().Start(),
 				mockAgentClient.EXPECT().GetState().Return(agentRunningState, nil),
 				mockAgentClient.EXPECT().RunScript("post-start", map[string]interface{}{}),
+				mockAgentClient.EXPECT().GetState().Return(agentRunningState, nil),
 			)
 		}
 
@@ -758,6 +778,8 @@ cloud_provider:
 			fakeRepoUUIDGenerator = fakeuuid.NewFakeGenerator()
 
 			mockCloud = mock_cloud.NewMockCloud(mockCtrl)
+			mockCloud.EXPECT().Info().Return(bicloud.CPIInfo{APIVersion: 1}, nil).AnyTimes()
+			mockCloud.EXPECT().Quota().Return(bicloud.Quota{}, nil).AnyTimes()
 
 			registryServerManager = biregistry.NewServerManager(logger)
 
@@ -948,6 +970,11 @@ cloud_provider:
 						diskRecords, err := diskRepo.All()
 						Expect(err).ToNot(HaveOccurred())
 						Expect(diskRecords).To(HaveLen(2)) // current + unused
+
+						migratingDiskRecord, found, err := diskRepo.FindMigrating()
+						Expect(err).ToNot(HaveOccurred())
+						Expect(found).To(BeTrue())
+						Expect(migratingDiskRecord.CID).To(Equal("fake-disk-cid-2"))
 					})
 
 					It("deletes unused disks", func() {
@@ -996,5 +1023,5 @@ cloud_provider:
 })
 
 func newDeployOpts(manifestPath string, statePath string) CreateEnvOpts {
-	return CreateEnvOpts{StatePath: statePath, Args: CreateEnvArgs{Manifest: FileBytesWithPathArg{Path: manifestPath}}}
+	return CreateEnvOpts{StatePath: statePath, Args: CreateEnvArgs{Manifest: EnvManifestArg{Path: manifestPath}}}
 }
@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+// Field is a value that is logged next to a message, such as the output of a command.
+type Field struct {
+	Name  string
+	Value interface{}
+}
+
+// FieldLogger is implemented by loggers that keep fields apart from the message.
+type FieldLogger interface {
+	DebugWithFields(tag string, fields []Field, msg string, args ...interface{})
+}
+
+// DebugWithFields logs fields as separate values when the logger supports it,
+// otherwise they are appended to the message as "NAME: 'value'" lines.
+func DebugWithFields(logger boshlog.Logger, tag string, fields []Field, msg string, args ...interface{}) {
+	if fieldLogger, ok := logger.(FieldLogger); ok {
+		fieldLogger.DebugWithFields(tag, fields, msg, args...)
+		return
+	}
+
+	lines := []string{fmt.Sprintf(msg, args...)}
+	for _, field := range fields {
+		lines = append(lines, fmt.Sprintf("%s: '%v'", strings.ToUpper(field.Name), field.Value))
+	}
+
+	logger.Debug(tag, "%s", strings.Join(lines, "\n"))
+}
@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+type jsonLogger struct {
+	level       boshlog.LogLevel
+	forcedDebug bool
+	writer      io.Writer
+	timeService clock.Clock
+	writerLock  sync.Mutex
+}
+
+// NewJSONLogger returns a logger that writes each entry as one JSON object per line,
+// with the level, timestamp, tag and message, and details or fields as separate keys.
+func NewJSONLogger(level boshlog.LogLevel, writer io.Writer, timeService clock.Clock) boshlog.Logger {
+	return &jsonLogger{
+		level:       level,
+		writer:      writer,
+		timeService: timeService,
+	}
+}
+
+func (l *jsonLogger) Debug(tag, msg string, args ...interface{}) {
+	l.log(boshlog.LevelDebug, tag, fmt.Sprintf(msg, args...), nil)
+}
+
+func (l *jsonLogger) DebugWithDetails(tag, msg string, args ...interface{}) {
+	message, details := l.splitDetails(msg, args)
+	l.log(boshlog.LevelDebug, tag, message, []Field{{Name: "details", Value: details}})
+}
+
+func (l *jsonLogger) DebugWithFields(tag string, fields []Field, msg string, args ...interface{}) {
+	l.log(boshlog.LevelDebug, tag, fmt.Sprintf(msg, args...), fields)
+}
+
+func (l *jsonLogger) Info(tag, msg string, args ...interface{}) {
+	l.log(boshlog.LevelInfo, tag, fmt.Sprintf(msg, args...), nil)
+}
+
+func (l *jsonLogger) Warn(tag, msg string, args ...interface{}) {
+	l.log(boshlog.LevelWarn, tag, fmt.Sprintf(msg, args...), nil)
+}
+
+func (l *jsonLogger) Error(tag, msg string, args ...interface{}) {
+	l.log(boshlog.LevelError, tag, fmt.Sprintf(msg, args...), nil)
+}
+
+func (l *jsonLogger) ErrorWithDetails(tag, msg string, args ...interface{}) {
+	message, details := l.splitDetails(msg, args)
+	l.log(boshlog.LevelError, tag, message, []Field{{Name: "details", Value: details}})
+}
+
+func (l *jsonLogger) HandlePanic(tag string) {
+	if e := recover(); e != nil {
+		l.ErrorWithDetails(tag, "Panic: %v", e, string(debug.Stack()))
+		os.Exit(2)
+	}
+}
+
+func (l *jsonLogger) ToggleForcedDebug() {
+	l.forcedDebug = !l.forcedDebug
+}
+
+func (l *jsonLogger) Flush() error                       { return nil }
+func (l *jsonLogger) FlushTimeout(_ time.Duration) error { return nil }
+
+// splitDetails separates the last argument, which the text logger prints
+// as a block after the message, from the arguments of the message
+func (l *jsonLogger) splitDetails(msg string, args []interface{}) (string, string) {
+	if len(args) == 0 {
+		return msg, ""
+	}
+
+	last := len(args) - 1
+
+	details := args[last]
+	if bytes, ok := details.([]byte); ok {
+		details = string(bytes)
+	}
+
+	return fmt.Sprintf(msg, args[:last]...), fmt.Sprintf("%v", details)
+}
+
+func (l *jsonLogger) log(level boshlog.LogLevel, tag, message string, fields []Field) {
+	if l.level > level && !l.forcedDebug {
+		return
+	}
+
+	entry := map[string]interface{}{}
+
+	for _, field := range fields {
+		entry[field.Name] = field.Value
+	}
+
+	entry["time"] = l.timeService.Now().UTC().Format(time.RFC3339Nano)
+	entry["level"] = jsonLogLevelNames[level]
+	entry["tag"] = tag
+	entry["message"] = message
+
+	bytes, err := json.Marshal(entry)
+	if err != nil {
+		bytes, _ = json.Marshal(map[string]interface{}{
+			"time":    entry["time"],
+			"level":   entry["level"],
+			"tag":     tag,
+			"message": fmt.Sprintf("%s (fields could not be logged: %s)", message, err.Error()),
+		})
+	}
+
+	l.writerLock.Lock()
+	defer l.writerLock.Unlock()
+
+	_, _ = l.writer.Write(append(bytes, '\n'))
+}
+
+var jsonLogLevelNames = map[boshlog.LogLevel]string{
+	boshlog.LevelDebug: "DEBUG",
+	boshlog.LevelInfo:  "INFO",
+	boshlog.LevelWarn:  "WARN",
+	boshlog.LevelError: "ERROR",
+}
@@ -0,0 +1,140 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	bilog "github.com/cloudfoundry/bosh-cli/logger"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+var _ = Describe("JSONLogger", func() {
+	var (
+		out         *bytes.Buffer
+		timeService *fakeclock.FakeClock
+	)
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		timeService = fakeclock.NewFakeClock(time.Date(2017, 3, 4, 5, 6, 7, 0, time.UTC))
+	})
+
+	entries := func() []map[string]interface{} {
+		var result []map[string]interface{}
+
+		for _, line := range strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n") {
+			if line == "" {
+				continue
+			}
+
+			var entry map[string]interface{}
+			Expect(json.Unmarshal([]byte(line), &entry)).To(Succeed())
+			result = append(result, entry)
+		}
+
+		return result
+	}
+
+	It("writes one JSON object per entry with level, time, tag and message", func() {
+		logger := bilog.NewJSONLogger(boshlog.LevelDebug, out, timeService)
+
+		logger.Info("fake-tag", "fake-%s\nmessage", "info")
+		logger.Error("fake-tag", "fake-error")
+
+		Expect(strings.Count(out.String(), "\n")).To(Equal(2))
+		Expect(entries()).To(Equal([]map[string]interface{}{
+			{
+				"time":    "2017-03-04T05:06:07Z",
+				"level":   "INFO",
+				"tag":     "fake-tag",
+				"message": "fake-info\nmessage",
+			},
+			{
+				"time":    "2017-03-04T05:06:07Z",
+				"level":   "ERROR",
+				"tag":     "fake-tag",
+				"message": "fake-error",
+			},
+		}))
+	})
+
+	It("does not write entries below its level", func() {
+		logger := bilog.NewJSONLogger(boshlog.LevelWarn, out, timeService)
+
+		logger.Debug("fake-tag", "fake-debug")
+		logger.Info("fake-tag", "fake-info")
+		logger.Warn("fake-tag", "fake-warn")
+
+		Expect(entries()).To(HaveLen(1))
+		Expect(entries()[0]["level"]).To(Equal("WARN"))
+	})
+
+	It("writes all entries while debug is forced", func() {
+		logger := bilog.NewJSONLogger(boshlog.LevelNone, out, timeService)
+
+		logger.ToggleForcedDebug()
+		logger.Debug("fake-tag", "fake-debug")
+
+		logger.ToggleForcedDebug()
+		logger.Debug("fake-tag", "fake-debug")
+
+		Expect(entries()).To(HaveLen(1))
+	})
+
+	It("writes details as a separate key", func() {
+		logger := bilog.NewJSONLogger(boshlog.LevelDebug, out, timeService)
+
+		logger.ErrorWithDetails("fake-tag", "Panic: %s", "fake-panic", []byte("fake-stack"))
+
+		Expect(entries()).To(Equal([]map[string]interface{}{
+			{
+				"time":    "2017-03-04T05:06:07Z",
+				"level":   "ERROR",
+				"tag":     "fake-tag",
+				"message": "Panic: fake-panic",
+				"details": "fake-stack",
+			},
+		}))
+	})
+
+	It("writes fields as separate keys", func() {
+		logger := bilog.NewJSONLogger(boshlog.LevelDebug, out, timeService)
+
+		bilog.DebugWithFields(logger, "fake-tag", []bilog.Field{
+			{Name: "stdin", Value: `{"method":"info"}`},
+			{Name: "stdout", Value: "fake-stdout"},
+		}, "Exit Code %d", 0)
+
+		Expect(entries()).To(Equal([]map[string]interface{}{
+			{
+				"time":    "2017-03-04T05:06:07Z",
+				"level":   "DEBUG",
+				"tag":     "fake-tag",
+				"message": "Exit Code 0",
+				"stdin":   `{"method":"info"}`,
+				"stdout":  "fake-stdout",
+			},
+		}))
+	})
+})
+
+var _ = Describe("DebugWithFields", func() {
+	It("appends fields to the message for loggers without field support", func() {
+		out := &bytes.Buffer{}
+		logger := boshlog.NewWriterLogger(boshlog.LevelDebug, out)
+
+		bilog.DebugWithFields(logger, "fake-tag", []bilog.Field{
+			{Name: "stdin", Value: "fake-%s-stdin"},
+			{Name: "stdout", Value: "fake-stdout"},
+		}, "Exit Code %d", 1)
+
+		Expect(out.String()).To(ContainSubstring("[fake-tag] "))
+		Expect(out.String()).To(HaveSuffix("DEBUG - Exit Code 1\nSTDIN: 'fake-%s-stdin'\nSTDOUT: 'fake-stdout'\n"))
+	})
+})
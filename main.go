@@ -7,6 +7,7 @@ import (
 	"runtime/debug"
 	"syscall"
 
+	"code.cloudfoundry.org/clock"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	boshlogfile "github.com/cloudfoundry/bosh-utils/logger/file"
@@ -56,9 +57,22 @@ func newLogger() boshlog.Logger {
 		}
 	}
 
+	// JSON lines are meant for log ingestion; the default format stays readable for interactive use
+	logFormat := os.Getenv("BOSH_LOG_FORMAT")
+	if logFormat != "" && logFormat != "text" && logFormat != "json" {
+		err := bosherr.Errorf("Invalid BOSH_LOG_FORMAT value '%s', expected 'text' or 'json'", logFormat)
+		logger := boshlog.NewLogger(boshlog.LevelError)
+		ui := boshui.NewConsoleUI(logger)
+		fail(err, ui, logger)
+	}
+
 	logPath := os.Getenv("BOSH_LOG_PATH")
 	if logPath != "" {
-		return newSignalableFileLogger(logPath, level)
+		return newSignalableFileLogger(logPath, level, logFormat)
+	}
+
+	if logFormat == "json" {
+		return newSignalableLogger(bilog.NewJSONLogger(level, os.Stderr, clock.NewClock()))
 	}
 
 	return newSignalableLogger(boshlog.NewLogger(level))
@@ -71,14 +85,12 @@ func newSignalableLogger(logger boshlog.Logger) boshlog.Logger {
 	return signalableLogger
 }
 
-func newSignalableFileLogger(logPath string, level boshlog.LogLevel) boshlog.Logger {
+func newSignalableFileLogger(logPath string, level boshlog.LogLevel, logFormat string) boshlog.Logger {
 	// Log file logger errors to the STDERR logger
 	logger := boshlog.NewLogger(boshlog.LevelError)
 	fs := boshsys.NewOsFileSystem(logger)
 
-	// Log file will be closed by process exit
-	// Log file readable by all
-	logfileLogger, _, err := boshlogfile.New(level, logPath, boshlogfile.DefaultLogFileMode, fs)
+	logfileLogger, err := newFileLogger(logPath, level, logFormat, fs)
 	if err != nil {
 		logger := boshlog.NewLogger(boshlog.LevelError)
 		ui := boshui.NewConsoleUI(logger)
@@ -88,6 +100,22 @@ func newSignalableFileLogger(logPath string, level boshlog.LogLevel) boshlog.Log
 	return newSignalableLogger(logfileLogger)
 }
 
+func newFileLogger(logPath string, level boshlog.LogLevel, logFormat string, fs boshsys.FileSystem) (boshlog.Logger, error) {
+	// Log file will be closed by process exit
+	// Log file readable by all
+	if logFormat != "json" {
+		logfileLogger, _, err := boshlogfile.New(level, logPath, boshlogfile.DefaultLogFileMode, fs)
+		return logfileLogger, err
+	}
+
+	file, err := fs.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, boshlogfile.DefaultLogFileMode)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Failed to open log file '%s'", logPath)
+	}
+
+	return bilog.NewJSONLogger(level, file, clock.NewClock()), nil
+}
+
 func handlePanic() {
 	panic := recover()
 
@@ -115,6 +143,9 @@ func handlePanic() {
 }
 
 func fail(err error, ui boshui.UI, logger boshlog.Logger) {
+	if recorder, ok := ui.(boshui.FailureRecorder); ok {
+		recorder.RecordFailure()
+	}
 	if err != nil {
 		logger.Error("CLI", err.Error())
 		ui.ErrorLinef(boshuifmt.MultilineError(err))
@@ -0,0 +1,72 @@
+package offline
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// Guard forbids network access in offline mode and remembers every
+// operation that needed it, so that a run can report all of them at once
+type Guard struct {
+	needed []string
+	lock   sync.Mutex
+}
+
+func NewGuard() *Guard {
+	return &Guard{}
+}
+
+// Forbid records that the described operation needed network access
+// and returns the error to fail the operation with
+func (g *Guard) Forbid(description string) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.needed = append(g.needed, description)
+
+	return bosherr.Errorf("Network access is not allowed in offline mode: %s", description)
+}
+
+// HTTPClient returns a client that fails every request without connecting anywhere
+func (g *Guard) HTTPClient() *http.Client {
+	return &http.Client{Transport: forbiddingTransport{guard: g}}
+}
+
+// Needed returns the operations that were refused network access, in order
+func (g *Guard) Needed() []string {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	return append([]string{}, g.needed...)
+}
+
+// Err adds the operations that needed network access to err
+func (g *Guard) Err(err error) error {
+	needed := g.Needed()
+
+	if err == nil || len(needed) == 0 {
+		return err
+	}
+
+	return bosherr.Errorf("%s\n\nNetwork access was needed for:\n  - %s", err.Error(), strings.Join(needed, "\n  - "))
+}
+
+type forbiddingTransport struct {
+	guard *Guard
+}
+
+func (t forbiddingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Body != nil {
+		_ = request.Body.Close()
+	}
+
+	// Credentials in URLs, e.g. of a release mirror, are not reported
+	url := *request.URL
+	url.User = nil
+
+	return nil, t.guard.Forbid(fmt.Sprintf("%s %s", request.Method, url.String()))
+}
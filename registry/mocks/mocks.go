@@ -39,6 +39,16 @@ func (_mr *_MockServerRecorder) Stop() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Stop")
 }
 
+func (_m *MockServer) Scheme() string {
+	ret := _m.ctrl.Call(_m, "Scheme")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+func (_mr *_MockServerRecorder) Scheme() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Scheme")
+}
+
 // Mock of ServerManager interface
 type MockServerManager struct {
 	ctrl     *gomock.Controller
@@ -70,3 +80,14 @@ func (_m *MockServerManager) Start(_param0 string, _param1 string, _param2 strin
 func (_mr *_MockServerManagerRecorder) Start(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Start", arg0, arg1, arg2, arg3)
 }
+
+func (_m *MockServerManager) StartTLS(_param0 string, _param1 string, _param2 string, _param3 int, _param4 registry.RegistryTLSConfig) (registry.Server, error) {
+	ret := _m.ctrl.Call(_m, "StartTLS", _param0, _param1, _param2, _param3, _param4)
+	ret0, _ := ret[0].(registry.Server)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockServerManagerRecorder) StartTLS(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "StartTLS", arg0, arg1, arg2, arg3, arg4)
+}
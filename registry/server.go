@@ -1,6 +1,8 @@
 package registry
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net"
 	"net/http"
@@ -11,6 +13,39 @@ import (
 
 type ServerManager interface {
 	Start(string, string, string, int) (Server, error)
+	StartTLS(string, string, string, int, RegistryTLSConfig) (Server, error)
+}
+
+// RegistryTLSConfig holds PEM encoded credentials for serving the registry over HTTPS.
+// Clients must present a certificate signed by CA when it is set.
+type RegistryTLSConfig struct {
+	Certificate []byte
+	PrivateKey  []byte
+	CA          []byte
+}
+
+func (c RegistryTLSConfig) tlsConfig() (*tls.Config, error) {
+	certificate, err := tls.X509KeyPair(c.Certificate, c.PrivateKey)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Parsing registry certificate and private key")
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if len(c.CA) > 0 {
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(c.CA) {
+			return nil, bosherr.Error("Parsing registry client CA certificate")
+		}
+
+		config.ClientCAs = clientCAs
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
 }
 
 type serverManager struct {
@@ -28,10 +63,25 @@ func NewServerManager(logger boshlog.Logger) ServerManager {
 // Create starts a new server on a goroutine and returns it
 // The returned error is only for starting. Error while running is logged.
 func (s *serverManager) Start(username string, password string, host string, port int) (Server, error) {
+	return s.start(username, password, host, port, nil)
+}
+
+// StartTLS starts a new server like Start that serves HTTPS instead of HTTP
+func (s *serverManager) StartTLS(username string, password string, host string, port int, tlsConfig RegistryTLSConfig) (Server, error) {
+	config, err := tlsConfig.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.start(username, password, host, port, config)
+}
+
+func (s *serverManager) start(username string, password string, host string, port int, tlsConfig *tls.Config) (Server, error) {
 	startedCh := make(chan error)
 	server := &server{
-		logger: s.logger,
-		logTag: "registryServer",
+		tlsConfig: tlsConfig,
+		logger:    s.logger,
+		logTag:    "registryServer",
 	}
 	go func() {
 		err := server.start(username, password, host, port, startedCh)
@@ -52,12 +102,16 @@ func (s *serverManager) Start(username string, password string, host string, por
 
 type Server interface {
 	Stop() error
+
+	// Scheme is 'https' when the server was started with TLS and 'http' otherwise
+	Scheme() string
 }
 
 type server struct {
-	listener net.Listener
-	logger   boshlog.Logger
-	logTag   string
+	listener  net.Listener
+	tlsConfig *tls.Config
+	logger    boshlog.Logger
+	logTag    string
 }
 
 func NewServer(logger boshlog.Logger) Server {
@@ -76,6 +130,10 @@ func (s *server) start(username string, password string, host string, port int,
 		return nil
 	}
 
+	if s.tlsConfig != nil {
+		s.listener = tls.NewListener(s.listener, s.tlsConfig)
+	}
+
 	readyErrCh <- nil
 
 	httpServer := http.Server{}
@@ -102,3 +160,11 @@ func (s *server) Stop() error {
 
 	return nil
 }
+
+func (s *server) Scheme() string {
+	if s.tlsConfig != nil {
+		return "https"
+	}
+
+	return "http"
+}
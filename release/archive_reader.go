@@ -7,6 +7,7 @@ import (
 	boshcmd "github.com/cloudfoundry/bosh-utils/fileutil"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	"github.com/cloudfoundry/bosh-utils/work"
 
 	boshjob "github.com/cloudfoundry/bosh-cli/release/job"
 	boshlic "github.com/cloudfoundry/bosh-cli/release/license"
@@ -15,6 +16,9 @@ import (
 	. "github.com/cloudfoundry/bosh-cli/release/resource"
 )
 
+// archiveReaderParallel bounds how many job and package archives are read (and possibly extracted) at once
+const archiveReaderParallel = 5
+
 type ArchiveReader struct {
 	jobArchiveReader boshjob.ArchiveReader
 	pkgArchiveReader boshpkg.ArchiveReader
@@ -84,7 +88,7 @@ func (r ArchiveReader) cleanUp(extractPath string) {
 }
 
 func (r ArchiveReader) newRelease(manifest boshman.Manifest, extractPath string) (Release, error) {
-	var errs []error
+	errs := r.duplicateNameErrs(manifest)
 
 	packages, err := r.newPackages(manifest.Packages, extractPath)
 	if err != nil {
@@ -126,20 +130,77 @@ func (r ArchiveReader) newRelease(manifest boshman.Manifest, extractPath string)
 	return release, nil
 }
 
+// duplicateNameErrs returns an error for each job and package name that the manifest declares more than once,
+// since their archives would be extracted to, and packages compiled in, the same place
+func (r ArchiveReader) duplicateNameErrs(manifest boshman.Manifest) []error {
+	var jobNames, pkgNames, compiledPkgNames []string
+
+	for _, ref := range manifest.Jobs {
+		jobNames = append(jobNames, ref.Name)
+	}
+
+	for _, ref := range manifest.Packages {
+		pkgNames = append(pkgNames, ref.Name)
+	}
+
+	for _, ref := range manifest.CompiledPkgs {
+		compiledPkgNames = append(compiledPkgNames, ref.Name)
+	}
+
+	var errs []error
+
+	for _, name := range duplicateNames(jobNames) {
+		errs = append(errs, bosherr.Errorf("Expected job '%s' to be declared only once in release manifest", name))
+	}
+
+	for _, name := range duplicateNames(pkgNames) {
+		errs = append(errs, bosherr.Errorf("Expected package '%s' to be declared only once in release manifest", name))
+	}
+
+	for _, name := range duplicateNames(compiledPkgNames) {
+		errs = append(errs, bosherr.Errorf("Expected compiled package '%s' to be declared only once in release manifest", name))
+	}
+
+	return errs
+}
+
+// duplicateNames returns names that appear more than once, in the order of their first duplicate
+func duplicateNames(names []string) []string {
+	var duplicates []string
+
+	seen := map[string]int{}
+
+	for _, name := range names {
+		seen[name]++
+		if seen[name] == 2 {
+			duplicates = append(duplicates, name)
+		}
+	}
+
+	return duplicates
+}
+
 func (r ArchiveReader) newJobs(pkgs []boshpkg.Compilable, refs []boshman.JobRef, extractPath string) ([]*boshjob.Job, error) {
 	var jobs []*boshjob.Job
 	var errs []error
 
-	for _, ref := range refs {
-		archivePath := filepath.Join(extractPath, "jobs", ref.Name+".tgz")
+	readJobs := make([]*boshjob.Job, len(refs))
+	readErrs := make([]error, len(refs))
 
-		job, err := r.jobArchiveReader.Read(ref, archivePath)
-		if err != nil {
-			errs = append(errs, bosherr.WrapErrorf(err, "Reading job '%s' from archive", ref.Name))
+	r.parallelDo(len(refs), func(i int) {
+		archivePath := filepath.Join(extractPath, "jobs", refs[i].Name+".tgz")
+		readJobs[i], readErrs[i] = r.jobArchiveReader.Read(refs[i], archivePath)
+	})
+
+	for i, ref := range refs {
+		if readErrs[i] != nil {
+			errs = append(errs, bosherr.WrapErrorf(readErrs[i], "Reading job '%s' from archive", ref.Name))
 			continue
 		}
 
-		err = job.AttachCompilablePackages(pkgs)
+		job := readJobs[i]
+
+		err := job.AttachCompilablePackages(pkgs)
 		if err != nil {
 			errs = append(errs, err)
 			continue
@@ -159,16 +220,21 @@ func (r ArchiveReader) newPackages(refs []boshman.PackageRef, extractPath string
 	var packages []*boshpkg.Package
 	var errs []error
 
-	for _, ref := range refs {
-		archivePath := filepath.Join(extractPath, "packages", ref.Name+".tgz")
+	readPkgs := make([]*boshpkg.Package, len(refs))
+	readErrs := make([]error, len(refs))
 
-		pkg, err := r.pkgArchiveReader.Read(ref, archivePath)
-		if err != nil {
-			errs = append(errs, bosherr.WrapErrorf(err, "Reading package '%s' from archive", ref.Name))
+	r.parallelDo(len(refs), func(i int) {
+		archivePath := filepath.Join(extractPath, "packages", refs[i].Name+".tgz")
+		readPkgs[i], readErrs[i] = r.pkgArchiveReader.Read(refs[i], archivePath)
+	})
+
+	for i, ref := range refs {
+		if readErrs[i] != nil {
+			errs = append(errs, bosherr.WrapErrorf(readErrs[i], "Reading package '%s' from archive", ref.Name))
 			continue
 		}
 
-		packages = append(packages, pkg)
+		packages = append(packages, readPkgs[i])
 	}
 
 	for _, pkg := range packages {
@@ -185,6 +251,24 @@ func (r ArchiveReader) newPackages(refs []boshman.PackageRef, extractPath string
 	return packages, nil
 }
 
+// parallelDo calls fn for every index in [0, count) using a bounded number of workers.
+// Results are expected to be stored by index so that ordering and error reporting stay deterministic.
+func (r ArchiveReader) parallelDo(count int, fn func(int)) {
+	pool := work.Pool{Count: archiveReaderParallel}
+
+	var tasks []func() error
+
+	for i := 0; i < count; i++ {
+		i := i
+		tasks = append(tasks, func() error {
+			fn(i)
+			return nil
+		})
+	}
+
+	_ = pool.ParallelDo(tasks...)
+}
+
 func (r ArchiveReader) newCompiledPackages(refs []boshman.CompiledPackageRef, extractPath string) ([]*boshpkg.CompiledPackage, error) {
 	var compiledPkgs []*boshpkg.CompiledPackage
 	var errs []error
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"time"
 
 	fakecmd "github.com/cloudfoundry/bosh-utils/fileutil/fakes"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
@@ -167,6 +168,35 @@ packages:
 					Expect(fs.FileExists(filepath.Join("/", "extracted", "release"))).To(BeFalse())
 				})
 
+				It("reads job archives concurrently", func() {
+					started := make(chan struct{}, 2)
+
+					jobReader.ReadStub = func(jobRef boshman.JobRef, path string) (*boshjob.Job, error) {
+						started <- struct{}{}
+
+						// Both jobs must be in flight at the same time for either to succeed
+						timeout := time.After(5 * time.Second)
+						for len(started) < 2 {
+							select {
+							case <-timeout:
+								return nil, errors.New("jobs were not read concurrently")
+							case <-time.After(time.Millisecond):
+							}
+						}
+
+						return boshjob.NewJob(NewResource(jobRef.Name, jobRef.Fingerprint, nil)), nil
+					}
+
+					pkgReader.ReadStub = func(pkgRef boshman.PackageRef, path string) (*boshpkg.Package, error) {
+						return boshpkg.NewPackage(NewResource(pkgRef.Name, pkgRef.Fingerprint, nil), pkgRef.Dependencies), nil
+					}
+
+					release, err := act()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(release.Jobs()[0].Name()).To(Equal("job1"))
+					Expect(release.Jobs()[1].Name()).To(Equal("job2"))
+				})
+
 				It("returns error if job's pkg dependencies cannot be satisfied", func() {
 					job1 := boshjob.NewJob(NewResource("job1", "job1-fp", nil))
 					job1.PackageNames = []string{"pkg-with-other-name"}
@@ -357,6 +387,53 @@ compiled_packages:
 				})
 			})
 
+			Context("when manifest declares jobs and packages more than once", func() {
+				BeforeEach(func() {
+					fs.WriteFileString(filepath.Join("/", "extracted", "release", "release.MF"), `---
+name: release
+version: version
+
+jobs:
+- name: job1
+  fingerprint: job1-fp
+- name: job2
+  fingerprint: job2-fp
+- name: job1
+  fingerprint: job1-other-fp
+
+packages:
+- name: pkg1
+  fingerprint: pkg1-fp
+- name: pkg2
+  fingerprint: pkg2-fp
+- name: pkg2
+  fingerprint: pkg2-other-fp
+`)
+				})
+
+				It("returns an error naming each duplicate together with other errors", func() {
+					jobReader.ReadStub = func(jobRef boshman.JobRef, path string) (*boshjob.Job, error) {
+						job := boshjob.NewJob(NewResource(jobRef.Name, jobRef.Fingerprint, nil))
+						job.PackageNames = []string{"missing-pkg"}
+						return job, nil
+					}
+
+					pkgReader.ReadStub = func(pkgRef boshman.PackageRef, path string) (*boshpkg.Package, error) {
+						return boshpkg.NewPackage(NewResource(pkgRef.Name, pkgRef.Fingerprint, nil), nil), nil
+					}
+
+					_, err := act()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Expected job 'job1' to be declared only once in release manifest"))
+					Expect(err.Error()).To(ContainSubstring("Expected package 'pkg2' to be declared only once in release manifest"))
+					Expect(err.Error()).ToNot(ContainSubstring("'job2' to be declared"))
+					Expect(err.Error()).ToNot(ContainSubstring("'pkg1' to be declared"))
+					Expect(err.Error()).To(ContainSubstring("Expected to find package 'missing-pkg' since it's a dependency of job 'job2'"))
+
+					Expect(fs.FileExists(filepath.Join("/", "extracted", "release"))).To(BeFalse())
+				})
+			})
+
 			Context("when the release manifest is invalid", func() {
 				BeforeEach(func() {
 					fs.WriteFileString(filepath.Join("/", "extracted", "release", "release.MF"), "-")
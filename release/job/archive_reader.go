@@ -3,6 +3,7 @@ package job
 import (
 	"path/filepath"
 
+	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshcmd "github.com/cloudfoundry/bosh-utils/fileutil"
 	biproperty "github.com/cloudfoundry/bosh-utils/property"
@@ -35,6 +36,17 @@ func (r ArchiveReaderImpl) Read(ref boshman.JobRef, path string) (*Job, error) {
 	job := NewJob(NewResourceWithBuiltArchive(ref.Name, ref.Fingerprint, path, ref.SHA1))
 
 	if r.extract {
+		digest, err := boshcrypto.ParseMultipleDigest(ref.SHA1)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Parsing digest of job '%s'", ref.Name)
+		}
+
+		err = digest.VerifyFilePath(path, r.fs)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Verifying job archive '%s'", path)
+		}
+
+		// Each job gets its own temp directory so concurrent extractions never share a target
 		extractPath, err := r.fs.TempDir("bosh-release-job")
 		if err != nil {
 			return nil, bosherr.WrapErrorf(err, "Creating temp directory to extract job '%s'", path)
@@ -46,6 +58,7 @@ func (r ArchiveReaderImpl) Read(ref boshman.JobRef, path string) (*Job, error) {
 
 		err = r.compressor.DecompressFileToDir(path, extractPath, boshcmd.CompressorOptions{})
 		if err != nil {
+			_ = job.CleanUp()
 			return nil, bosherr.WrapErrorf(err, "Extracting job archive '%s'", path)
 		}
 
@@ -53,6 +66,7 @@ func (r ArchiveReaderImpl) Read(ref boshman.JobRef, path string) (*Job, error) {
 
 		manifest, err := boshjobman.NewManifestFromPath(specPath, r.fs)
 		if err != nil {
+			_ = job.CleanUp()
 			return nil, err
 		}
 
@@ -65,6 +79,7 @@ func (r ArchiveReaderImpl) Read(ref boshman.JobRef, path string) (*Job, error) {
 			defaultValue, err := biproperty.Build(rawPropertyDef.Default)
 			if err != nil {
 				errMsg := "Parsing job '%s' property '%s' default: %#v"
+				_ = job.CleanUp()
 				return nil, bosherr.WrapErrorf(err, errMsg, job.Name(), propertyName, rawPropertyDef.Default)
 			}
 
@@ -75,6 +90,10 @@ func (r ArchiveReaderImpl) Read(ref boshman.JobRef, path string) (*Job, error) {
 		}
 
 		job.Properties = properties
+
+		for _, stemcell := range manifest.Stemcells {
+			job.Stemcells = append(job.Stemcells, StemcellConstraint{OS: stemcell.OS, Version: stemcell.Version})
+		}
 	}
 
 	return job, nil
@@ -38,6 +38,9 @@ var _ = Describe("ArchiveReaderImpl", func() {
 		BeforeEach(func() {
 			reader = NewArchiveReaderImpl(true, compressor, fs)
 			fs.TempDirDir = "/extracted/job"
+
+			ref.SHA1 = "071dbd386f7bda5226e1b23454328e6c934710e7"
+			fs.WriteFileString("archive-path", "archive-content")
 		})
 
 		It("returns a job with the details from the manifest", func() {
@@ -49,6 +52,9 @@ properties:
   prop:
     description: prop-desc
     default: prop-default
+stemcells:
+- os: ubuntu-xenial
+  version: ">= 97"
 `)
 
 			job, err := reader.Read(ref, "archive-path")
@@ -57,7 +63,7 @@ properties:
 			Expect(job.Name()).To(Equal("name"))
 			Expect(job.Fingerprint()).To(Equal("fp"))
 			Expect(job.ArchivePath()).To(Equal("archive-path"))
-			Expect(job.ArchiveDigest()).To(Equal("archive-sha1"))
+			Expect(job.ArchiveDigest()).To(Equal("071dbd386f7bda5226e1b23454328e6c934710e7"))
 
 			Expect(job.Templates).To(Equal(map[string]string{"src": "dst"}))
 			Expect(job.PackageNames).To(Equal([]string{"pkg"}))
@@ -67,6 +73,7 @@ properties:
 					Default:     biproperty.Property("prop-default"),
 				},
 			}))
+			Expect(job.Stemcells).To(Equal([]StemcellConstraint{{OS: "ubuntu-xenial", Version: ">= 97"}}))
 
 			Expect(job.ExtractedPath()).To(Equal("/extracted/job"))
 
@@ -91,6 +98,24 @@ properties:
 			Expect(err.Error()).To(ContainSubstring("fake-err"))
 		})
 
+		It("removes the extraction directory when the job archive is not a valid tar", func() {
+			fs.MkdirAll("/extracted/job", os.ModeDir)
+			compressor.DecompressFileToDirErr = errors.New("fake-err")
+
+			_, err := reader.Read(ref, "archive-path")
+			Expect(err).To(HaveOccurred())
+			Expect(fs.FileExists("/extracted/job")).To(BeFalse())
+		})
+
+		It("returns error without extracting when the job archive does not match its digest", func() {
+			fs.WriteFileString("archive-path", "tampered-content")
+
+			_, err := reader.Read(ref, "archive-path")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Verifying job archive 'archive-path'"))
+			Expect(compressor.DecompressFileToDirTarballPaths).To(BeEmpty())
+		})
+
 		It("returns a job that can be cleaned up", func() {
 			fs.WriteFileString("/extracted/job/job.MF", "")
 			fs.MkdirAll("/extracted/job", os.ModeDir)
@@ -1,6 +1,10 @@
 package job
 
 import (
+	"path/filepath"
+	"regexp"
+	"sort"
+
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	biproperty "github.com/cloudfoundry/bosh-utils/property"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
@@ -11,6 +15,9 @@ import (
 	crypto2 "github.com/cloudfoundry/bosh-utils/crypto"
 )
 
+// propertyWithoutDefaultRegexp matches p("name") calls that pass no default value
+var propertyWithoutDefaultRegexp = regexp.MustCompile(`\bp\(\s*["']([^"']+)["']\s*\)`)
+
 type ByName []*Job
 
 func (a ByName) Len() int           { return len(a) }
@@ -24,6 +31,7 @@ type Job struct {
 	PackageNames []string
 	Packages     []boshpkg.Compilable
 	Properties   map[string]PropertyDefinition
+	Stemcells    []StemcellConstraint
 
 	extractedPath string
 	fs            boshsys.FileSystem
@@ -80,6 +88,7 @@ func (j *Job) RehashWithCalculator(calculator crypto.DigestCalculator, archiveFi
 		PackageNames: j.PackageNames,
 		Packages:     j.Packages,
 		Properties:   j.Properties,
+		Stemcells:    j.Stemcells,
 
 		extractedPath: j.extractedPath,
 		fs:            j.fs,
@@ -87,6 +96,8 @@ func (j *Job) RehashWithCalculator(calculator crypto.DigestCalculator, archiveFi
 }
 
 func (j *Job) AttachCompilablePackages(packages []boshpkg.Compilable) error {
+	var errs []error
+
 	for _, pkgName := range j.PackageNames {
 		var found bool
 
@@ -100,15 +111,50 @@ func (j *Job) AttachCompilablePackages(packages []boshpkg.Compilable) error {
 
 		if !found {
 			errMsg := "Expected to find package '%s' since it's a dependency of job '%s'"
-			return bosherr.Errorf(errMsg, pkgName, j.Name())
+			errs = append(errs, bosherr.Errorf(errMsg, pkgName, j.Name()))
 		}
 	}
 
+	if len(errs) > 0 {
+		return bosherr.NewMultiError(errs...)
+	}
+
 	return nil
 }
 
 func (j Job) ExtractedPath() string { return j.extractedPath }
 
+// PropertiesUsedWithoutDefault returns the properties that templates read with
+// p("name") without a fallback value; rendering fails if they are not set.
+// Properties read with if_p or with a fallback value are optional.
+// Jobs that are not extracted have no templates to look at.
+func (j Job) PropertiesUsedWithoutDefault() ([]string, error) {
+	if j.fs == nil || len(j.extractedPath) == 0 {
+		return nil, nil
+	}
+
+	found := map[string]struct{}{}
+
+	for src := range j.Templates {
+		content, err := j.fs.ReadFileString(filepath.Join(j.extractedPath, "templates", src))
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Reading template '%s' of job '%s'", src, j.Name())
+		}
+
+		for _, match := range propertyWithoutDefaultRegexp.FindAllStringSubmatch(content, -1) {
+			found[match[1]] = struct{}{}
+		}
+	}
+
+	names := []string{}
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
 func (j Job) CleanUp() error {
 	if j.fs != nil && len(j.extractedPath) > 0 {
 		return j.fs.RemoveAll(j.extractedPath)
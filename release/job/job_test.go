@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -118,6 +119,56 @@ var _ = Describe("Job", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(Equal("Expected to find package 'pkg1' since it's a dependency of job 'name'"))
 		})
+
+		It("returns an error for each compiled package that cannot be found", func() {
+			job := NewJob(NewResource("name", "fp", nil))
+			job.PackageNames = []string{"pkg1", "pkg2", "pkg3"}
+
+			pkg2 := boshpkg.NewCompiledPackageWithArchive("pkg2", "fp", "", "path", "sha1", nil)
+
+			err := job.AttachCompilablePackages([]boshpkg.Compilable{pkg2})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected to find package 'pkg1' since it's a dependency of job 'name'\n" +
+				"Expected to find package 'pkg3' since it's a dependency of job 'name'"))
+		})
+	})
+
+	Describe("PropertiesUsedWithoutDefault", func() {
+		It("returns properties read without a fallback value", func() {
+			fs := fakesys.NewFakeFileSystem()
+			fs.WriteFileString("/extracted/templates/config.yml.erb", `
+port: <%= p("app.port") %>
+host: <%= p('app.host', 'localhost') %>
+<% if_p("app.tls.cert") do |cert| %>cert: <%= cert %><% end %>
+name: <%= p( 'app.name' ) %>
+`)
+			fs.WriteFileString("/extracted/templates/ctl.erb", `exec app --port <%= p("app.port") %>`)
+
+			job := NewExtractedJob(NewResourceWithBuiltArchive("name", "fp", "path", "sha1"), "/extracted", fs)
+			job.Templates = map[string]string{"config.yml.erb": "config/config.yml", "ctl.erb": "bin/ctl"}
+
+			names, err := job.PropertiesUsedWithoutDefault()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(names).To(Equal([]string{"app.name", "app.port"}))
+		})
+
+		It("returns error if a template cannot be read", func() {
+			job := NewExtractedJob(NewResourceWithBuiltArchive("name", "fp", "path", "sha1"), "/extracted", fakesys.NewFakeFileSystem())
+			job.Templates = map[string]string{"missing.erb": "config/missing"}
+
+			_, err := job.PropertiesUsedWithoutDefault()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Reading template 'missing.erb' of job 'name'"))
+		})
+
+		It("returns nothing for jobs that are not extracted", func() {
+			job := NewJob(NewResourceWithBuiltArchive("name", "fp", "path", "sha1"))
+			job.Templates = map[string]string{"config.yml.erb": "config/config.yml"}
+
+			names, err := job.PropertiesUsedWithoutDefault()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(names).To(BeEmpty())
+		})
 	})
 
 	Describe("CleanUp", func() {
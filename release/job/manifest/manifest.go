@@ -11,6 +11,7 @@ type Manifest struct {
 	Templates  map[string]string             `yaml:"templates"`
 	Packages   []string                      `yaml:"packages"`
 	Properties map[string]PropertyDefinition `yaml:"properties"`
+	Stemcells  []StemcellDefinition          `yaml:"stemcells"`
 }
 
 type PropertyDefinition struct {
@@ -18,6 +19,11 @@ type PropertyDefinition struct {
 	Default     interface{} `yaml:"default"`
 }
 
+type StemcellDefinition struct {
+	OS      string `yaml:"os"`
+	Version string `yaml:"version"`
+}
+
 func NewManifestFromPath(path string, fs boshsys.FileSystem) (Manifest, error) {
 	var manifest Manifest
 
@@ -0,0 +1,65 @@
+package job
+
+import (
+	boshpkg "github.com/cloudfoundry/bosh-cli/release/pkg"
+)
+
+// PackageConflict is a package that two jobs to be installed on the same VM
+// require with different fingerprints, e.g. because they come from different
+// releases. Only one of them can be installed under /var/vcap/packages.
+type PackageConflict struct {
+	Name string
+
+	// JobIndex and OtherJobIndex index the jobs given to FindPackageConflicts
+	JobIndex    int
+	Fingerprint string
+
+	OtherJobIndex    int
+	OtherFingerprint string
+}
+
+// FindPackageConflicts returns the packages, including the dependencies of packages,
+// that jobs require by the same name but with different fingerprints.
+// Each conflict is reported for the job requiring the package after another job did.
+func FindPackageConflicts(jobs []Job) []PackageConflict {
+	type packageOwner struct {
+		jobIndex    int
+		fingerprint string
+	}
+
+	owners := map[string]packageOwner{}
+	conflicts := []PackageConflict{}
+
+	var require func(jobIndex int, pkg boshpkg.Compilable)
+	require = func(jobIndex int, pkg boshpkg.Compilable) {
+		owner, found := owners[pkg.Name()]
+		if found {
+			if owner.fingerprint != pkg.Fingerprint() {
+				conflicts = append(conflicts, PackageConflict{
+					Name:             pkg.Name(),
+					JobIndex:         jobIndex,
+					Fingerprint:      pkg.Fingerprint(),
+					OtherJobIndex:    owner.jobIndex,
+					OtherFingerprint: owner.fingerprint,
+				})
+			}
+
+			// Dependencies were required along with the package before, which also ends cycles
+			return
+		}
+
+		owners[pkg.Name()] = packageOwner{jobIndex: jobIndex, fingerprint: pkg.Fingerprint()}
+
+		for _, dependency := range pkg.Deps() {
+			require(jobIndex, dependency)
+		}
+	}
+
+	for jobIndex, job := range jobs {
+		for _, pkg := range job.Packages {
+			require(jobIndex, pkg)
+		}
+	}
+
+	return conflicts
+}
@@ -0,0 +1,56 @@
+package job_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/release/job"
+	boshpkg "github.com/cloudfoundry/bosh-cli/release/pkg"
+	. "github.com/cloudfoundry/bosh-cli/release/resource"
+)
+
+var _ = Describe("FindPackageConflicts", func() {
+	newPkg := func(name, fp string, deps ...*boshpkg.Package) *boshpkg.Package {
+		depNames := []string{}
+		for _, dep := range deps {
+			depNames = append(depNames, dep.Name())
+		}
+
+		pkg := boshpkg.NewPackage(NewResourceWithBuiltArchive(name, fp, "path", "sha1"), depNames)
+		Expect(pkg.AttachDependencies(deps)).To(Succeed())
+		return pkg
+	}
+
+	newJob := func(name string, pkgs ...*boshpkg.Package) Job {
+		job := NewJob(NewResourceWithBuiltArchive(name, "fp", "path", "sha1"))
+		for _, pkg := range pkgs {
+			job.PackageNames = append(job.PackageNames, pkg.Name())
+		}
+		Expect(job.AttachPackages(pkgs)).To(Succeed())
+		return *job
+	}
+
+	It("returns no conflicts when jobs require the same packages", func() {
+		shared := newPkg("shared", "shared-fp")
+
+		Expect(FindPackageConflicts([]Job{
+			newJob("job1", newPkg("pkg1", "pkg1-fp", shared)),
+			newJob("job2", shared),
+		})).To(BeEmpty())
+	})
+
+	It("returns packages, including dependencies, required with different fingerprints", func() {
+		jobs := []Job{
+			newJob("job1", newPkg("pkg1", "pkg1-fp", newPkg("dep", "dep-fp"))),
+			newJob("job2", newPkg("pkg2", "pkg2-fp", newPkg("dep", "other-dep-fp"))),
+		}
+
+		Expect(FindPackageConflicts(jobs)).To(Equal([]PackageConflict{{
+			Name:             "dep",
+			JobIndex:         1,
+			Fingerprint:      "other-dep-fp",
+			OtherJobIndex:    0,
+			OtherFingerprint: "dep-fp",
+		}}))
+	})
+})
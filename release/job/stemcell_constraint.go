@@ -0,0 +1,88 @@
+package job
+
+import (
+	"fmt"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	semver "github.com/cppforlife/go-semi-semantic/version"
+)
+
+// StemcellConstraint is a stemcell supported by a job, declared in the
+// 'stemcells' section of its spec. Version is either empty, matching any
+// version, or a comma separated list of comparisons that must all hold,
+// e.g. '>= 3468, < 3500'. A version without an operator must match exactly.
+type StemcellConstraint struct {
+	OS      string
+	Version string
+}
+
+var stemcellVersionOperators = []string{">=", "<=", ">", "<", "="}
+
+func (c StemcellConstraint) String() string {
+	if c.Version == "" {
+		return c.OS
+	}
+	return fmt.Sprintf("%s %s", c.OS, c.Version)
+}
+
+// Matches reports whether the stemcell with the given OS and version satisfies the constraint
+func (c StemcellConstraint) Matches(os string, version string) (bool, error) {
+	if c.OS != os {
+		return false, nil
+	}
+
+	if strings.TrimSpace(c.Version) == "" {
+		return true, nil
+	}
+
+	stemcellVersion, err := semver.NewVersionFromString(version)
+	if err != nil {
+		return false, bosherr.WrapErrorf(err, "Parsing stemcell version '%s'", version)
+	}
+
+	for _, comparison := range strings.Split(c.Version, ",") {
+		matched, err := c.compare(stemcellVersion, strings.TrimSpace(comparison))
+		if err != nil {
+			return false, err
+		}
+
+		if !matched {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (c StemcellConstraint) compare(stemcellVersion semver.Version, comparison string) (bool, error) {
+	operator := "="
+
+	for _, op := range stemcellVersionOperators {
+		if strings.HasPrefix(comparison, op) {
+			operator = op
+			comparison = strings.TrimSpace(strings.TrimPrefix(comparison, op))
+			break
+		}
+	}
+
+	version, err := semver.NewVersionFromString(comparison)
+	if err != nil {
+		return false, bosherr.WrapErrorf(err, "Parsing stemcell version constraint '%s' for OS '%s'", c.Version, c.OS)
+	}
+
+	result := stemcellVersion.Compare(version)
+
+	switch operator {
+	case ">=":
+		return result >= 0, nil
+	case "<=":
+		return result <= 0, nil
+	case ">":
+		return result > 0, nil
+	case "<":
+		return result < 0, nil
+	default:
+		return result == 0, nil
+	}
+}
@@ -0,0 +1,43 @@
+package job_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/release/job"
+)
+
+var _ = Describe("StemcellConstraint", func() {
+	Describe("Matches", func() {
+		It("matches any version of the OS when no version is given", func() {
+			Expect(StemcellConstraint{OS: "ubuntu-xenial"}.Matches("ubuntu-xenial", "97.12")).To(BeTrue())
+			Expect(StemcellConstraint{OS: "ubuntu-xenial"}.Matches("ubuntu-trusty", "3468.1")).To(BeFalse())
+		})
+
+		It("matches versions exactly when no operator is given", func() {
+			constraint := StemcellConstraint{OS: "ubuntu-trusty", Version: "3468.1"}
+
+			Expect(constraint.Matches("ubuntu-trusty", "3468.1")).To(BeTrue())
+			Expect(constraint.Matches("ubuntu-trusty", "3468.10")).To(BeFalse())
+		})
+
+		It("matches versions satisfying all comparisons", func() {
+			constraint := StemcellConstraint{OS: "ubuntu-trusty", Version: ">= 3468, < 3500"}
+
+			Expect(constraint.Matches("ubuntu-trusty", "3468")).To(BeTrue())
+			Expect(constraint.Matches("ubuntu-trusty", "3499.9")).To(BeTrue())
+			Expect(constraint.Matches("ubuntu-trusty", "3467.99")).To(BeFalse())
+			Expect(constraint.Matches("ubuntu-trusty", "3500")).To(BeFalse())
+		})
+
+		It("returns an error when versions cannot be parsed", func() {
+			_, err := StemcellConstraint{OS: "ubuntu-trusty", Version: "> x!"}.Matches("ubuntu-trusty", "3468")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Parsing stemcell version constraint '> x!' for OS 'ubuntu-trusty'"))
+
+			_, err = StemcellConstraint{OS: "ubuntu-trusty", Version: ">= 3468"}.Matches("ubuntu-trusty", "")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Parsing stemcell version ''"))
+		})
+	})
+})
@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshcmd "github.com/cloudfoundry/bosh-utils/fileutil"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
@@ -33,6 +34,17 @@ func (r ArchiveReaderImpl) Read(ref boshman.PackageRef, path string) (*Package,
 	pkg := NewPackage(resource, ref.Dependencies)
 
 	if r.extract {
+		digest, err := boshcrypto.ParseMultipleDigest(ref.SHA1)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Parsing digest of package '%s'", ref.Name)
+		}
+
+		err = digest.VerifyFilePath(path, r.fs)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Verifying package archive '%s'", path)
+		}
+
+		// Each package gets its own temp directory so concurrent extractions never share a target
 		extractPath, err := r.fs.TempDir("bosh-release-pkg")
 		if err != nil {
 			return nil, bosherr.WrapErrorf(err, "Creating temp directory to extract package '%s'", path)
@@ -44,6 +56,7 @@ func (r ArchiveReaderImpl) Read(ref boshman.PackageRef, path string) (*Package,
 
 		err = r.compressor.DecompressFileToDir(path, extractPath, boshcmd.CompressorOptions{})
 		if err != nil {
+			_ = pkg.CleanUp()
 			return nil, bosherr.WrapErrorf(err, "Extracting package '%s'", ref.Name)
 		}
 	}
@@ -38,6 +38,9 @@ var _ = Describe("ArchiveReaderImpl", func() {
 		BeforeEach(func() {
 			reader = NewArchiveReaderImpl(true, compressor, fs)
 			fs.TempDirDir = "/extracted/pkg"
+
+			ref.SHA1 = "071dbd386f7bda5226e1b23454328e6c934710e7"
+			fs.WriteFileString("archive-path", "archive-content")
 		})
 
 		It("returns a package", func() {
@@ -47,7 +50,7 @@ var _ = Describe("ArchiveReaderImpl", func() {
 			Expect(pkg.Name()).To(Equal("name"))
 			Expect(pkg.Fingerprint()).To(Equal("fp"))
 			Expect(pkg.ArchivePath()).To(Equal("archive-path"))
-			Expect(pkg.ArchiveDigest()).To(Equal("archive-sha1"))
+			Expect(pkg.ArchiveDigest()).To(Equal("071dbd386f7bda5226e1b23454328e6c934710e7"))
 			Expect(pkg.DependencyNames()).To(Equal([]string{"pkg1"}))
 			Expect(pkg.ExtractedPath()).To(Equal("/extracted/pkg"))
 
@@ -64,6 +67,24 @@ var _ = Describe("ArchiveReaderImpl", func() {
 			Expect(err.Error()).To(ContainSubstring("fake-err"))
 		})
 
+		It("removes the extraction directory when the package archive is not a valid tar", func() {
+			fs.MkdirAll("/extracted/pkg", os.ModeDir)
+			compressor.DecompressFileToDirErr = errors.New("fake-err")
+
+			_, err := reader.Read(ref, "archive-path")
+			Expect(err).To(HaveOccurred())
+			Expect(fs.FileExists("/extracted/pkg")).To(BeFalse())
+		})
+
+		It("returns error without extracting when the package archive does not match its digest", func() {
+			fs.WriteFileString("archive-path", "tampered-content")
+
+			_, err := reader.Read(ref, "archive-path")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Verifying package archive 'archive-path'"))
+			Expect(compressor.DecompressFileToDirTarballPaths).To(BeEmpty())
+		})
+
 		It("returns a package that can be cleaned up", func() {
 			fs.MkdirAll("/extracted/pkg", os.ModeDir)
 
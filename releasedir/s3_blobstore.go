@@ -77,12 +77,76 @@ func (b S3Blobstore) Create(path string) (string, error) {
 	return blobID, nil
 }
 
+// Download copies the object stored under name instead of a generated blob ID to path
+// and returns false when there is none
+func (b S3Blobstore) Download(name string, path string) (bool, error) {
+	client, err := b.client()
+	if err != nil {
+		return false, err
+	}
+
+	exists, err := client.Exists(name)
+	if err != nil {
+		return false, bosherr.WrapErrorf(err, "Checking for object '%s'", name)
+	}
+
+	if !exists {
+		return false, nil
+	}
+
+	file, err := b.fs.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return false, bosherr.WrapError(err, "Creating destination file")
+	}
+
+	defer file.Close()
+
+	err = client.Get(name, file)
+	if err != nil {
+		return false, bosherr.WrapErrorf(err, "Downloading object '%s'", name)
+	}
+
+	return true, nil
+}
+
+// Upload stores the file at path under name instead of a generated blob ID
+func (b S3Blobstore) Upload(name string, path string) error {
+	client, err := b.client()
+	if err != nil {
+		return err
+	}
+
+	file, err := b.fs.OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		return bosherr.WrapError(err, "Opening source file")
+	}
+
+	defer file.Close()
+
+	err = client.Put(file, name)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Uploading object '%s'", name)
+	}
+
+	return nil
+}
+
 func (b S3Blobstore) CleanUp(path string) error {
 	return b.fs.RemoveAll(path)
 }
 
 func (b S3Blobstore) Delete(blobID string) error {
-	panic("Not implemented")
+	client, err := b.client()
+	if err != nil {
+		return err
+	}
+
+	err = client.Delete(blobID)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Deleting blob '%s'", blobID)
+	}
+
+	return nil
 }
 
 func (b S3Blobstore) Validate() error {
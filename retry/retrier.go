@@ -0,0 +1,112 @@
+package retry
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
+)
+
+// Config controls how operations that fail with a transient error, such as
+// CPI calls that are safe to repeat and blobstore downloads and uploads,
+// are attempted again
+type Config struct {
+	// Retries is the number of attempts made after the first one failed
+	Retries int
+
+	// Delay is the wait before the first retry, doubled after each retry up to MaxDelay
+	Delay    time.Duration
+	MaxDelay time.Duration
+
+	// Timeout, when positive, makes attempts until it has passed instead of Retries times.
+	// The delay before the last attempt is shortened so that it is made right before giving up.
+	Timeout time.Duration
+}
+
+func NewDefaultConfig() Config {
+	return Config{
+		Retries:  3,
+		Delay:    1 * time.Second,
+		MaxDelay: 30 * time.Second,
+	}
+}
+
+// RetryableErrorFunc decides whether an attempt that failed with err may be made again
+type RetryableErrorFunc func(err error) bool
+
+// Clock is the part of clock.Clock that retries wait with
+type Clock interface {
+	Now() time.Time
+	Sleep(time.Duration)
+}
+
+type Retrier interface {
+	Retry(description string, retryable RetryableErrorFunc, attempt func() error) error
+}
+
+type retrier struct {
+	config      Config
+	timeService Clock
+	warnings    biwarn.Warnings
+	logger      boshlog.Logger
+	logTag      string
+}
+
+// NewRetrier returns a Retrier that reports each retry through warnings.
+// Without warnings retries are only logged, e.g. for waiting on something that is expected to take a while.
+func NewRetrier(config Config, timeService Clock, warnings biwarn.Warnings, logger boshlog.Logger) Retrier {
+	return retrier{
+		config:      config,
+		timeService: timeService,
+		warnings:    warnings,
+		logger:      logger,
+		logTag:      "retrier",
+	}
+}
+
+// Retry makes attempts until one succeeds, fails with an error that is not retryable,
+// or the retries or the timeout are used up, in which case the last error is returned
+func (r retrier) Retry(description string, retryable RetryableErrorFunc, attempt func() error) error {
+	attempts := r.config.Retries + 1
+	delay := r.config.Delay
+	deadline := r.timeService.Now().Add(r.config.Timeout)
+
+	for i := 1; ; i++ {
+		err := attempt()
+		if err == nil || !retryable(err) {
+			return err
+		}
+
+		attemptNumber := fmt.Sprintf("%d of %d", i, attempts)
+
+		if r.config.Timeout > 0 {
+			remaining := deadline.Sub(r.timeService.Now())
+			if remaining <= 0 {
+				r.logger.Debug(r.logTag, "Attempt %d of %s failed, giving up after %s: %s", i, description, r.config.Timeout, err.Error())
+				return err
+			}
+
+			if delay > remaining {
+				delay = remaining
+			}
+
+			attemptNumber = strconv.Itoa(i)
+		} else if i >= attempts {
+			return err
+		}
+
+		r.logger.Debug(r.logTag, "Attempt %s of %s failed, retrying in %s: %s", attemptNumber, description, delay, err.Error())
+		if r.warnings != nil {
+			r.warnings.Warn(r.logTag, "Retrying %s in %s after attempt %s failed: %s", description, delay, attemptNumber, err.Error())
+		}
+		r.timeService.Sleep(delay)
+
+		delay *= 2
+		if delay > r.config.MaxDelay {
+			delay = r.config.MaxDelay
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package retry_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/retry"
+	biwarn "github.com/cloudfoundry/bosh-cli/warnings"
+)
+
+type advancingClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *advancingClock) Sleep(d time.Duration) {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+}
+
+func (c *advancingClock) Now() time.Time { return c.now }
+
+var _ = Describe("Retrier", func() {
+	var (
+		timeService *fakeclock.FakeClock
+		warnings    biwarn.Warnings
+		logger      boshlog.Logger
+		retrier     Retrier
+
+		attempts    int
+		attemptErrs []error
+		retryable   RetryableErrorFunc
+	)
+
+	attempt := func() error {
+		attempts++
+		if len(attemptErrs) == 0 {
+			return nil
+		}
+		err := attemptErrs[0]
+		attemptErrs = attemptErrs[1:]
+		return err
+	}
+
+	BeforeEach(func() {
+		timeService = fakeclock.NewFakeClock(time.Now())
+		logger = boshlog.NewLogger(boshlog.LevelNone)
+		warnings = biwarn.NewWarnings(logger)
+
+		retrier = NewRetrier(Config{Retries: 2, Delay: 1 * time.Second, MaxDelay: 30 * time.Second}, timeService, warnings, logger)
+
+		attempts = 0
+		attemptErrs = nil
+		retryable = func(error) bool { return true }
+	})
+
+	It("attempts once when the attempt succeeds", func() {
+		err := retrier.Retry("fake-operation", retryable, attempt)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(attempts).To(Equal(1))
+		Expect(warnings.List()).To(BeEmpty())
+	})
+
+	It("retries with a doubling delay and warns about each retry", func() {
+		attemptErrs = []error{errors.New("fake-err-1"), errors.New("fake-err-2")}
+
+		errCh := make(chan error)
+		go func() { errCh <- retrier.Retry("fake-operation", retryable, attempt) }()
+
+		Eventually(timeService.WatcherCount).Should(Equal(1))
+		timeService.Increment(1 * time.Second)
+
+		Eventually(timeService.WatcherCount).Should(Equal(1))
+		timeService.Increment(1 * time.Second)
+		Consistently(errCh).ShouldNot(Receive())
+		timeService.Increment(1 * time.Second)
+
+		Eventually(errCh).Should(Receive(BeNil()))
+		Expect(attempts).To(Equal(3))
+
+		Expect(warnings.List()).To(Equal([]biwarn.Warning{
+			{Source: "retrier", Message: "Retrying fake-operation in 1s after attempt 1 of 3 failed: fake-err-1"},
+			{Source: "retrier", Message: "Retrying fake-operation in 2s after attempt 2 of 3 failed: fake-err-2"},
+		}))
+	})
+
+	It("caps the delay", func() {
+		retrier = NewRetrier(Config{Retries: 2, Delay: 20 * time.Second, MaxDelay: 30 * time.Second}, timeService, warnings, logger)
+		attemptErrs = []error{errors.New("fake-err-1"), errors.New("fake-err-2")}
+
+		errCh := make(chan error)
+		go func() { errCh <- retrier.Retry("fake-operation", retryable, attempt) }()
+
+		Eventually(timeService.WatcherCount).Should(Equal(1))
+		timeService.Increment(20 * time.Second)
+
+		Eventually(timeService.WatcherCount).Should(Equal(1))
+		timeService.Increment(30 * time.Second)
+
+		Eventually(errCh).Should(Receive(BeNil()))
+		Expect(warnings.List()[1].Message).To(ContainSubstring("in 30s"))
+	})
+
+	It("returns the last error when the retries are used up", func() {
+		retrier = NewRetrier(Config{Retries: 1}, timeService, warnings, logger)
+		attemptErrs = []error{errors.New("fake-err-1"), errors.New("fake-err-2"), errors.New("fake-err-3")}
+
+		err := retrier.Retry("fake-operation", retryable, attempt)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("fake-err-2"))
+		Expect(attempts).To(Equal(2))
+	})
+
+	It("does not retry errors that are not retryable", func() {
+		attemptErrs = []error{errors.New("fake-err")}
+		retryable = func(err error) bool { return err.Error() != "fake-err" }
+
+		err := retrier.Retry("fake-operation", retryable, attempt)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("fake-err"))
+		Expect(attempts).To(Equal(1))
+		Expect(warnings.List()).To(BeEmpty())
+	})
+
+	Context("with a timeout", func() {
+		var clock *advancingClock
+
+		BeforeEach(func() {
+			clock = &advancingClock{now: time.Now()}
+			attemptErrs = []error{
+				errors.New("fake-err"), errors.New("fake-err"), errors.New("fake-err"),
+				errors.New("fake-err"), errors.New("fake-err"), errors.New("fake-err"),
+			}
+		})
+
+		It("retries beyond the number of retries until the timeout has passed", func() {
+			retrier = NewRetrier(Config{Delay: 500 * time.Millisecond, MaxDelay: 3 * time.Second, Timeout: time.Minute}, clock, warnings, logger)
+			attemptErrs = attemptErrs[:5]
+
+			err := retrier.Retry("fake-operation", retryable, attempt)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(attempts).To(Equal(6))
+			Expect(clock.sleeps).To(Equal([]time.Duration{
+				500 * time.Millisecond, time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second,
+			}))
+			Expect(warnings.List()[0].Message).To(Equal("Retrying fake-operation in 500ms after attempt 1 failed: fake-err"))
+		})
+
+		It("returns the last error once the timeout has passed, without sleeping past it", func() {
+			retrier = NewRetrier(Config{Delay: time.Second, MaxDelay: 4 * time.Second, Timeout: 10 * time.Second}, clock, warnings, logger)
+
+			err := retrier.Retry("fake-operation", retryable, attempt)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("fake-err"))
+
+			Expect(clock.sleeps).To(Equal([]time.Duration{
+				time.Second, 2 * time.Second, 4 * time.Second, 3 * time.Second,
+			}))
+			Expect(attempts).To(Equal(5))
+		})
+	})
+
+	It("only logs retries without warnings", func() {
+		retrier = NewRetrier(Config{Retries: 1, Delay: time.Second}, &advancingClock{}, nil, logger)
+		attemptErrs = []error{errors.New("fake-err")}
+
+		err := retrier.Retry("fake-operation", retryable, attempt)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(attempts).To(Equal(2))
+	})
+})
@@ -30,7 +30,8 @@ type ClientImpl struct {
 	timeService              clock.Clock
 	startDialDelay           time.Duration
 
-	client *ssh.Client
+	client        *ssh.Client
+	bastionClient *ssh.Client
 
 	logTag string
 	logger boshlog.Logger
@@ -76,6 +77,13 @@ func (s *ClientImpl) Start() error {
 
 	var err error
 
+	if s.opts.Bastion.Host != "" {
+		s.bastionClient, err = s.connectBastion()
+		if err != nil {
+			return err
+		}
+	}
+
 	for i := 0; ; i++ {
 		s.logger.Debug(s.logTag, "Making attempt #%d", i)
 
@@ -85,6 +93,10 @@ func (s *ClientImpl) Start() error {
 		}
 
 		if !retryStrategy.IsRetryable(err) {
+			if s.bastionClient != nil {
+				_ = s.bastionClient.Close()
+			}
+
 			return bosherr.WrapError(err, "Failed to connect to remote server")
 		}
 
@@ -105,16 +117,59 @@ func (s *ClientImpl) Listen(n, addr string) (net.Listener, error) {
 }
 
 func (s *ClientImpl) Stop() error {
+	var errs []error
+
 	if s.client != nil {
-		return s.client.Close()
+		if err := s.client.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if s.bastionClient != nil {
+		if err := s.bastionClient.Close(); err != nil {
+			errs = append(errs, bosherr.WrapError(err, "Closing connection to bastion host"))
+		}
 	}
+
+	if len(errs) > 0 {
+		return bosherr.NewMultiError(errs...)
+	}
+
 	return nil
 }
 
+// connectBastion connects to the bastion host without retrying, since unlike
+// a VM that is still booting it is expected to be reachable already
+func (s *ClientImpl) connectBastion() (*ssh.Client, error) {
+	bastionAddr := net.JoinHostPort(s.opts.Bastion.Host, fmt.Sprintf("%d", s.opts.Bastion.Port))
+
+	signer, err := ssh.ParsePrivateKey([]byte(s.opts.Bastion.PrivateKey))
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Parsing bastion host private key")
+	}
+
+	sshConfig := &ssh.ClientConfig{
+		User:            s.opts.Bastion.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}
+
+	s.logger.Debug(s.logTag, "Dialing bastion host at %s", bastionAddr)
+
+	client, err := s.newClient("tcp", bastionAddr, sshConfig)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Failed to connect to bastion host '%s'", bastionAddr)
+	}
+
+	return client, nil
+}
+
 func (s *ClientImpl) newClient(network, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
 	dialFunc := net.Dial
 
-	if !s.opts.DisableSOCKS {
+	if s.bastionClient != nil {
+		dialFunc = s.bastionClient.Dial
+	} else if !s.opts.DisableSOCKS {
 		socksProxy := proxy.NewSocks5Proxy(proxy.NewHostKey(), nil)
 		dialFunc = boshhttp.SOCKS5DialFuncFromEnvironment(net.Dial, socksProxy)
 	}
@@ -0,0 +1,241 @@
+package ssh_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ssh"
+
+	. "github.com/cloudfoundry/bosh-cli/ssh"
+)
+
+// testSSHServer accepts any public key and, when forwarding is enabled,
+// forwards 'direct-tcpip' channels like a bastion host
+type testSSHServer struct {
+	listener net.Listener
+	config   *ssh.ServerConfig
+	forward  bool
+
+	lock      sync.Mutex
+	users     []string
+	forwarded []string
+}
+
+func newTestSSHServer(forward bool) *testSSHServer {
+	hostKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).ToNot(HaveOccurred())
+
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	Expect(err).ToNot(HaveOccurred())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+
+	server := &testSSHServer{listener: listener, forward: forward}
+
+	server.config = &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, _ ssh.PublicKey) (*ssh.Permissions, error) {
+			server.lock.Lock()
+			server.users = append(server.users, conn.User())
+			server.lock.Unlock()
+
+			return nil, nil
+		},
+	}
+	server.config.AddHostKey(hostSigner)
+
+	go server.serve()
+
+	return server
+}
+
+func (s *testSSHServer) Host() string {
+	host, _, _ := net.SplitHostPort(s.listener.Addr().String())
+	return host
+}
+
+func (s *testSSHServer) Port() int {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+	return portNum
+}
+
+func (s *testSSHServer) Users() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]string{}, s.users...)
+}
+
+func (s *testSSHServer) Forwarded() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]string{}, s.forwarded...)
+}
+
+func (s *testSSHServer) Close() {
+	_ = s.listener.Close()
+}
+
+func (s *testSSHServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go s.handle(conn)
+	}
+}
+
+func (s *testSSHServer) handle(conn net.Conn) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		return
+	}
+
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if !s.forward || newChannel.ChannelType() != "direct-tcpip" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "not supported")
+			continue
+		}
+
+		var payload struct {
+			DestAddr string
+			DestPort uint32
+			OrigAddr string
+			OrigPort uint32
+		}
+
+		err := ssh.Unmarshal(newChannel.ExtraData(), &payload)
+		if err != nil {
+			_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+
+		destAddr := net.JoinHostPort(payload.DestAddr, fmt.Sprintf("%d", payload.DestPort))
+
+		s.lock.Lock()
+		s.forwarded = append(s.forwarded, destAddr)
+		s.lock.Unlock()
+
+		destConn, err := net.Dial("tcp", destAddr)
+		if err != nil {
+			_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+
+		channel, channelReqs, err := newChannel.Accept()
+		if err != nil {
+			_ = destConn.Close()
+			continue
+		}
+
+		go ssh.DiscardRequests(channelReqs)
+
+		go func() {
+			_, _ = io.Copy(channel, destConn)
+			_ = channel.Close()
+		}()
+
+		go func() {
+			_, _ = io.Copy(destConn, channel)
+			_ = destConn.Close()
+		}()
+	}
+}
+
+func privateKeyPEM() string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).ToNot(HaveOccurred())
+
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))
+}
+
+var _ = Describe("Client", func() {
+	var (
+		bastion *testSSHServer
+		vm      *testSSHServer
+
+		clientFactory ClientFactory
+		opts          ClientOpts
+	)
+
+	BeforeEach(func() {
+		bastion = newTestSSHServer(true)
+		vm = newTestSSHServer(false)
+
+		clientFactory = NewClientFactory(boshlog.NewLogger(boshlog.LevelNone))
+
+		opts = ClientOpts{
+			Host:       vm.Host(),
+			Port:       vm.Port(),
+			User:       "fake-vm-user",
+			PrivateKey: privateKeyPEM(),
+
+			DisableSOCKS: true,
+
+			Bastion: BastionOpts{
+				Host:       bastion.Host(),
+				Port:       bastion.Port(),
+				User:       "fake-bastion-user",
+				PrivateKey: privateKeyPEM(),
+			},
+		}
+	})
+
+	AfterEach(func() {
+		bastion.Close()
+		vm.Close()
+	})
+
+	Context("when a bastion is configured", func() {
+		It("connects to the host through the bastion", func() {
+			client := clientFactory.New(opts)
+
+			err := client.Start()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(bastion.Users()).To(Equal([]string{"fake-bastion-user"}))
+			Expect(bastion.Forwarded()).To(Equal([]string{net.JoinHostPort(vm.Host(), fmt.Sprintf("%d", vm.Port()))}))
+			Expect(vm.Users()).To(Equal([]string{"fake-vm-user"}))
+
+			Expect(client.Stop()).To(Succeed())
+		})
+
+		It("returns a bastion error without connecting to the host when the bastion is not reachable", func() {
+			bastion.Close()
+
+			err := clientFactory.New(opts).Start()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("Failed to connect to bastion host '%s'", net.JoinHostPort(bastion.Host(), fmt.Sprintf("%d", bastion.Port())))))
+
+			Expect(vm.Users()).To(BeEmpty())
+		})
+
+		It("returns an error when the bastion private key is invalid", func() {
+			opts.Bastion.PrivateKey = "fake-invalid-key"
+
+			err := clientFactory.New(opts).Start()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Parsing bastion host private key"))
+			Expect(err.Error()).ToNot(ContainSubstring("fake-invalid-key"))
+		})
+	})
+})
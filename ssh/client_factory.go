@@ -16,6 +16,18 @@ type ClientOpts struct {
 	PrivateKey string
 
 	DisableSOCKS bool
+
+	// Bastion, when its Host is set, is connected to first and
+	// used to reach Host, like 'ssh -J'
+	Bastion BastionOpts
+}
+
+type BastionOpts struct {
+	Host string
+	Port int
+
+	User       string
+	PrivateKey string
 }
 
 type ClientFactory struct {
@@ -26,21 +26,37 @@ type DependencyCompiler interface {
 
 type dependencyCompiler struct {
 	packageCompiler bistatepkg.Compiler
+	parallel        int
 
 	logTag string
 	logger boshlog.Logger
 }
 
+// NewDependencyCompiler returns a DependencyCompiler that compiles one package at a time
 func NewDependencyCompiler(packageCompiler bistatepkg.Compiler, logger boshlog.Logger) DependencyCompiler {
+	return NewParallelDependencyCompiler(packageCompiler, 1, logger)
+}
+
+// NewParallelDependencyCompiler returns a DependencyCompiler that compiles up to parallel packages
+// at the same time. A package starts compiling once all of its dependencies have been compiled,
+// so packageCompiler must support concurrent compilation of independent packages.
+func NewParallelDependencyCompiler(packageCompiler bistatepkg.Compiler, parallel int, logger boshlog.Logger) DependencyCompiler {
+	if parallel < 1 {
+		parallel = 1
+	}
+
 	return &dependencyCompiler{
 		packageCompiler: packageCompiler,
+		parallel:        parallel,
 
 		logTag: "dependencyCompiler",
 		logger: logger,
 	}
 }
 
-// Compile resolves and compiles all transitive dependencies of multiple release jobs
+// Compile resolves and compiles all transitive dependencies of multiple release jobs.
+// The jobs may come from different releases; their packages are installed side by side
+// on the VM by name, so packages sharing a name must have the same fingerprint.
 func (c *dependencyCompiler) Compile(jobs []bireljob.Job, stage biui.Stage) ([]CompiledPackageRef, error) {
 	compileOrderReleasePackages, err := c.resolveJobCompilationDependencies(jobs)
 	if err != nil {
@@ -57,6 +73,20 @@ func (c *dependencyCompiler) Compile(jobs []bireljob.Job, stage biui.Stage) ([]C
 
 // resolveJobPackageCompilationDependencies returns all packages required by all specified jobs, in compilation order (reverse dependency order)
 func (c *dependencyCompiler) resolveJobCompilationDependencies(jobs []bireljob.Job) ([]birelpkg.Compilable, error) {
+	// only one package of a name can be installed on the VM
+	conflicts := bireljob.FindPackageConflicts(jobs)
+	if len(conflicts) > 0 {
+		errs := []error{}
+
+		for _, conflict := range conflicts {
+			errs = append(errs, bosherr.Errorf(
+				"Package '%s' required by job '%s' has fingerprint '%s' which conflicts with fingerprint '%s' required by job '%s'",
+				conflict.Name, jobs[conflict.JobIndex].Name(), conflict.Fingerprint, conflict.OtherFingerprint, jobs[conflict.OtherJobIndex].Name()))
+		}
+
+		return nil, bosherr.NewMultiError(errs...)
+	}
+
 	// collect and de-dupe all required packages (dependencies of jobs)
 	packageMap := map[string]birelpkg.Compilable{}
 
@@ -104,39 +134,147 @@ func (c *dependencyCompiler) resolvePackageDependencies(releasePackage birelpkg.
 	}
 }
 
-// compilePackages compiles the specified packages, in the order specified, uploads them to the Blobstore, and returns the blob references
+type packageCompilation struct {
+	pkg birelpkg.Compilable
+
+	// done is closed once record, isAlreadyCompiled and err are set
+	done              chan struct{}
+	record            bistatepkg.CompiledPackageRecord
+	isAlreadyCompiled bool
+	err               error
+}
+
+// compilePackages compiles the specified packages, uploads them to the Blobstore, and returns the blob references.
+// Packages are reported to the stage one at a time, in the order their compilation started,
+// so that concurrent compilations do not interleave their progress lines.
 func (c *dependencyCompiler) compilePackages(requiredPackages []birelpkg.Compilable, stage biui.Stage) ([]CompiledPackageRef, error) {
 	packageRefs := make([]CompiledPackageRef, 0, len(requiredPackages))
 
-	for _, pkg := range requiredPackages {
+	started := make(chan *packageCompilation, len(requiredPackages))
+	scheduleErrCh := make(chan error, 1)
+
+	go func() {
+		scheduleErrCh <- c.scheduleCompilations(requiredPackages, started)
+		close(started)
+	}()
+
+	for compilation := range started {
+		compilation := compilation
+		pkg := compilation.pkg
 		stepName := fmt.Sprintf("Compiling package '%s/%s'", pkg.Name(), pkg.Fingerprint())
 
-		err := stage.Perform(stepName, func() error {
-			compiledPackageRecord, isAlreadyCompiled, err := c.packageCompiler.Compile(pkg)
-			if err != nil {
-				return err
+		_ = stage.Perform(stepName, func() error {
+			<-compilation.done
+
+			if compilation.err != nil {
+				return compilation.err
 			}
 
 			packageRef := CompiledPackageRef{
 				Name:        pkg.Name(),
 				Version:     pkg.Fingerprint(),
-				BlobstoreID: compiledPackageRecord.BlobID,
-				SHA1:        compiledPackageRecord.BlobSHA1,
+				BlobstoreID: compilation.record.BlobID,
+				SHA1:        compilation.record.BlobSHA1,
 			}
 			packageRefs = append(packageRefs, packageRef)
 
-			if isAlreadyCompiled {
+			if compilation.isAlreadyCompiled {
 				return biui.NewSkipStageError(bosherr.Error(fmt.Sprintf("Package '%s' is already compiled. Skipped compilation", pkg.Name())), "Package already compiled")
 			}
 
 			return nil
 		})
-		if err != nil {
-			return nil, err
-		}
+	}
+
+	err := <-scheduleErrCh
+	if err != nil {
+		return nil, err
 	}
 
 	return packageRefs, nil
 }
 
+// scheduleCompilations compiles packages with at most c.parallel compilations running at the same time,
+// starting each package once all of its dependencies have been compiled. Compilations are sent to started
+// when they start. Once a compilation fails no more compilations are started, and after the running ones
+// have finished the first error is returned.
+func (c *dependencyCompiler) scheduleCompilations(packages []birelpkg.Compilable, started chan<- *packageCompilation) error {
+	pending := append([]birelpkg.Compilable{}, packages...)
+	compiled := map[string]bool{}
+	finished := make(chan *packageCompilation)
+	running := 0
+
+	var firstErr error
+
+	for {
+		for firstErr == nil && running < c.parallel {
+			i := c.nextCompilablePackage(pending, compiled)
+			if i < 0 {
+				break
+			}
+
+			compilation := &packageCompilation{pkg: pending[i], done: make(chan struct{})}
+			pending = append(pending[:i], pending[i+1:]...)
+
+			c.logger.Debug(c.logTag, "Starting compilation of package '%s/%s'", compilation.pkg.Name(), compilation.pkg.Fingerprint())
+
+			started <- compilation
+			running++
+
+			go func() {
+				compilation.record, compilation.isAlreadyCompiled, compilation.err = c.packageCompiler.Compile(compilation.pkg)
+				close(compilation.done)
+				finished <- compilation
+			}()
+		}
+
+		if running == 0 {
+			break
+		}
+
+		compilation := <-finished
+		running--
+
+		if compilation.err != nil {
+			if firstErr == nil {
+				firstErr = compilation.err
+			}
+			continue
+		}
+
+		compiled[c.pkgKey(compilation.pkg)] = true
+	}
+
+	if firstErr == nil && len(pending) > 0 {
+		names := []string{}
+		for _, pkg := range pending {
+			names = append(names, pkg.Name())
+		}
+
+		firstErr = bosherr.Errorf("Packages '%s' depend on packages that cannot be compiled", strings.Join(names, "', '"))
+	}
+
+	return firstErr
+}
+
+// nextCompilablePackage returns the index of the first package whose dependencies have all been compiled, or -1
+func (c *dependencyCompiler) nextCompilablePackage(pending []birelpkg.Compilable, compiled map[string]bool) int {
+	for i, pkg := range pending {
+		compilable := true
+
+		for _, dependency := range pkg.Deps() {
+			if !compiled[c.pkgKey(dependency)] {
+				compilable = false
+				break
+			}
+		}
+
+		if compilable {
+			return i
+		}
+	}
+
+	return -1
+}
+
 func (c *dependencyCompiler) pkgKey(pkg birelpkg.Compilable) string { return pkg.Name() }
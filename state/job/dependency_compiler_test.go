@@ -1,12 +1,15 @@
 package job_test
 
 import (
+	"errors"
+
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	boshreljob "github.com/cloudfoundry/bosh-cli/release/job"
+	birelpkg "github.com/cloudfoundry/bosh-cli/release/pkg"
 	boshrelpkg "github.com/cloudfoundry/bosh-cli/release/pkg"
 	. "github.com/cloudfoundry/bosh-cli/release/resource"
 	. "github.com/cloudfoundry/bosh-cli/state/job"
@@ -184,6 +187,31 @@ var _ = Describe("DependencyCompiler", func() {
 		})
 	})
 
+	Context("when jobs from different releases require different packages with the same name", func() {
+		JustBeforeEach(func() {
+			otherPkg1 := newPkg("pkg1-name", "other-pkg1-fp", nil)
+			otherPkg2 := newPkg("pkg2-name", "other-pkg2-fp", []string{"pkg1-name"})
+			otherPkg2.AttachDependencies([]*boshrelpkg.Package{otherPkg1})
+
+			job2 := boshreljob.NewJob(NewResourceWithBuiltArchive("job2-name", "job2-fp", "", ""))
+			job2.PackageNames = []string{"pkg2-name"}
+			job2.AttachPackages([]*boshrelpkg.Package{otherPkg2})
+			jobs = append(jobs, *job2)
+		})
+
+		It("returns an error naming all conflicting packages and does not compile anything", func() {
+			expectCompilePkg1.Times(0)
+			expectCompilePkg2.Times(0)
+
+			_, err := dependencyCompiler.Compile(jobs, stage)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(
+				"Package 'pkg2-name' required by job 'job2-name' has fingerprint 'other-pkg2-fp' which conflicts with fingerprint 'pkg2-fp' required by job 'cpi'"))
+			Expect(err.Error()).ToNot(ContainSubstring("Package 'pkg1-name'"))
+			Expect(stage.PerformCalls).To(BeEmpty())
+		})
+	})
+
 	Context("when multiple packages depend on the same package", func() {
 		var (
 			pkg3              *boshrelpkg.Package
@@ -215,4 +243,120 @@ var _ = Describe("DependencyCompiler", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 	})
+
+	Context("when compiling packages in parallel", func() {
+		var (
+			pkgA, pkgB, pkgC *boshrelpkg.Package
+
+			started chan string
+			release chan struct{}
+		)
+
+		blockingCompile := func(pkg birelpkg.Compilable) {
+			started <- pkg.Name()
+			<-release
+		}
+
+		compileInBackground := func() chan error {
+			errCh := make(chan error, 1)
+
+			go func() {
+				defer GinkgoRecover()
+				_, err := dependencyCompiler.Compile(jobs, stage)
+				errCh <- err
+			}()
+
+			return errCh
+		}
+
+		BeforeEach(func() {
+			dependencyCompiler = NewParallelDependencyCompiler(mockPackageCompiler, 2, logger)
+
+			started = make(chan string, 3)
+			release = make(chan struct{})
+
+			pkgA = newPkg("pkgA-name", "pkgA-fp", nil)
+			pkgB = newPkg("pkgB-name", "pkgB-fp", nil)
+			pkgC = newPkg("pkgC-name", "pkgC-fp", []string{"pkgA-name", "pkgB-name"})
+			pkgC.AttachDependencies([]*boshrelpkg.Package{pkgA, pkgB})
+
+			job = boshreljob.NewJob(NewResourceWithBuiltArchive("cpi", "job-fp", "path", "sha1"))
+			job.PackageNames = []string{"pkgC-name"}
+			job.AttachPackages([]*boshrelpkg.Package{pkgC})
+			jobs = []boshreljob.Job{*job}
+		})
+
+		It("compiles independent packages at the same time and dependent packages afterwards", func() {
+			mockPackageCompiler.EXPECT().Compile(pkgA).Do(blockingCompile).Return(bistatepkg.CompiledPackageRecord{BlobID: "blob-a"}, false, nil)
+			mockPackageCompiler.EXPECT().Compile(pkgB).Do(blockingCompile).Return(bistatepkg.CompiledPackageRecord{BlobID: "blob-b"}, false, nil)
+			mockPackageCompiler.EXPECT().Compile(pkgC).Do(func(pkg birelpkg.Compilable) { started <- pkg.Name() }).Return(bistatepkg.CompiledPackageRecord{BlobID: "blob-c"}, false, nil)
+
+			errCh := compileInBackground()
+
+			var first, second string
+			Eventually(started).Should(Receive(&first))
+			Eventually(started).Should(Receive(&second))
+			Expect([]string{first, second}).To(ConsistOf("pkgA-name", "pkgB-name"))
+			Consistently(started).ShouldNot(Receive())
+
+			close(release)
+
+			Eventually(errCh).Should(Receive(BeNil()))
+			Expect(started).To(Receive(Equal("pkgC-name")))
+
+			Expect(stage.PerformCalls).To(HaveLen(3))
+			Expect(stage.PerformCalls[:2]).To(ConsistOf(
+				&fakeui.PerformCall{Name: "Compiling package 'pkgA-name/pkgA-fp'"},
+				&fakeui.PerformCall{Name: "Compiling package 'pkgB-name/pkgB-fp'"},
+			))
+			Expect(stage.PerformCalls[2]).To(Equal(&fakeui.PerformCall{Name: "Compiling package 'pkgC-name/pkgC-fp'"}))
+		})
+
+		It("does not compile more packages at the same time than allowed", func() {
+			dependencyCompiler = NewParallelDependencyCompiler(mockPackageCompiler, 1, logger)
+
+			mockPackageCompiler.EXPECT().Compile(pkgA).Do(blockingCompile).Return(bistatepkg.CompiledPackageRecord{}, false, nil)
+			mockPackageCompiler.EXPECT().Compile(pkgB).Do(blockingCompile).Return(bistatepkg.CompiledPackageRecord{}, false, nil)
+			mockPackageCompiler.EXPECT().Compile(pkgC).Return(bistatepkg.CompiledPackageRecord{}, false, nil)
+
+			errCh := compileInBackground()
+
+			Eventually(started).Should(Receive())
+			Consistently(started).ShouldNot(Receive())
+
+			release <- struct{}{}
+			Eventually(started).Should(Receive())
+
+			release <- struct{}{}
+			Eventually(errCh).Should(Receive(BeNil()))
+		})
+
+		It("does not start pending packages once a compile fails and returns the first error", func() {
+			mockPackageCompiler.EXPECT().Compile(pkgA).Do(blockingCompile).Return(bistatepkg.CompiledPackageRecord{}, false, errors.New("fake-compile-a-err"))
+			mockPackageCompiler.EXPECT().Compile(pkgB).Do(blockingCompile).Return(bistatepkg.CompiledPackageRecord{}, false, nil)
+			mockPackageCompiler.EXPECT().Compile(pkgC).Times(0)
+
+			errCh := compileInBackground()
+
+			Eventually(started).Should(Receive())
+			Eventually(started).Should(Receive())
+
+			release <- struct{}{}
+			release <- struct{}{}
+
+			var err error
+			Eventually(errCh).Should(Receive(&err))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-compile-a-err"))
+
+			Expect(stage.PerformCalls).To(HaveLen(2))
+			for _, call := range stage.PerformCalls {
+				if call.Name == "Compiling package 'pkgA-name/pkgA-fp'" {
+					Expect(call.Error).To(MatchError("fake-compile-a-err"))
+				} else {
+					Expect(call.Error).ToNot(HaveOccurred())
+				}
+			}
+		})
+	})
 })
@@ -18,6 +18,8 @@ type CompiledPackageRecord struct {
 type CompiledPackageRepo interface {
 	Save(birelpkg.Compilable, CompiledPackageRecord) error
 	Find(birelpkg.Compilable) (CompiledPackageRecord, bool, error)
+	// Prune deletes records whose blobs are not in keepBlobIDs and returns deleted records
+	Prune(keepBlobIDs []string) ([]CompiledPackageRecord, error)
 }
 
 type compiledPackageRepo struct {
@@ -53,6 +55,44 @@ func (cpr *compiledPackageRepo) Find(pkg birelpkg.Compilable) (CompiledPackageRe
 	return record, true, nil
 }
 
+func (cpr *compiledPackageRepo) Prune(keepBlobIDs []string) ([]CompiledPackageRecord, error) {
+	var prunedRecords []CompiledPackageRecord
+
+	kept := map[string]bool{}
+	for _, blobID := range keepBlobIDs {
+		kept[blobID] = true
+	}
+
+	var keys []packageToCompiledPackageKey
+
+	err := cpr.index.Keys(&keys)
+	if err != nil {
+		return prunedRecords, bosherr.WrapError(err, "Listing compiled packages")
+	}
+
+	for _, key := range keys {
+		var record CompiledPackageRecord
+
+		err := cpr.index.Find(key, &record)
+		if err != nil {
+			return prunedRecords, bosherr.WrapErrorf(err, "Finding compiled package '%s/%s'", key.PackageName, key.PackageFingerprint)
+		}
+
+		if kept[record.BlobID] {
+			continue
+		}
+
+		err = cpr.index.Delete(key)
+		if err != nil {
+			return prunedRecords, bosherr.WrapErrorf(err, "Deleting compiled package '%s/%s'", key.PackageName, key.PackageFingerprint)
+		}
+
+		prunedRecords = append(prunedRecords, record)
+	}
+
+	return prunedRecords, nil
+}
+
 type packageToCompiledPackageKey struct {
 	PackageName string
 	// Fingerprint of a package captures the sorted names of its dependencies
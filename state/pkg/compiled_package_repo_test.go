@@ -148,4 +148,49 @@ var _ = Describe("CompiledPackageRepo", func() {
 			Expect(err.Error()).To(ContainSubstring("Finding compiled package"))
 		})
 	})
+
+	Context("Prune", func() {
+		It("deletes records whose blobs are not kept and returns them", func() {
+			keptPkg := newPkg("kept-name", "kept-fp", nil)
+			prunedPkg := newPkg("pruned-name", "pruned-fp", nil)
+
+			keptRecord := CompiledPackageRecord{BlobID: "kept-blob-id", BlobSHA1: "kept-sha1"}
+			prunedRecord := CompiledPackageRecord{BlobID: "pruned-blob-id", BlobSHA1: "pruned-sha1"}
+
+			Expect(compiledPackageRepo.Save(keptPkg, keptRecord)).To(Succeed())
+			Expect(compiledPackageRepo.Save(prunedPkg, prunedRecord)).To(Succeed())
+
+			pruned, err := compiledPackageRepo.Prune([]string{"kept-blob-id"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pruned).To(Equal([]CompiledPackageRecord{prunedRecord}))
+
+			result, found, err := compiledPackageRepo.Find(keptPkg)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(result).To(Equal(keptRecord))
+
+			_, found, err = compiledPackageRepo.Find(prunedPkg)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+
+		It("returns no records when everything is kept", func() {
+			pkg := newPkg("pkg-name", "pkg-fp", nil)
+
+			Expect(compiledPackageRepo.Save(pkg, CompiledPackageRecord{BlobID: "blob-id"})).To(Succeed())
+
+			pruned, err := compiledPackageRepo.Prune([]string{"blob-id"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pruned).To(BeEmpty())
+		})
+
+		It("returns error when reading from index fails", func() {
+			fs.WriteFileString("/index_file", "[]")
+			fs.ReadFileError = errors.New("fake-error")
+
+			_, err := compiledPackageRepo.Prune(nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Listing compiled packages"))
+		})
+	})
 })
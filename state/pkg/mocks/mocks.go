@@ -75,6 +75,17 @@ func (_mr *_MockCompiledPackageRepoRecorder) Find(arg0 interface{}) *gomock.Call
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Find", arg0)
 }
 
+func (_m *MockCompiledPackageRepo) Prune(_param0 []string) ([]pkg0.CompiledPackageRecord, error) {
+	ret := _m.ctrl.Call(_m, "Prune", _param0)
+	ret0, _ := ret[0].([]pkg0.CompiledPackageRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockCompiledPackageRepoRecorder) Prune(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Prune", arg0)
+}
+
 func (_m *MockCompiledPackageRepo) Save(_param0 pkg.Compilable, _param1 pkg0.CompiledPackageRecord) error {
 	ret := _m.ctrl.Call(_m, "Save", _param0, _param1)
 	ret0, _ := ret[0].(error)
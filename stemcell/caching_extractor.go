@@ -0,0 +1,278 @@
+package stemcell
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshcmd "github.com/cloudfoundry/bosh-utils/fileutil"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+
+	biutil "github.com/cloudfoundry/bosh-cli/common/util"
+	bicrypto "github.com/cloudfoundry/bosh-cli/crypto"
+)
+
+// DefaultCachedStemcells is how many extracted stemcells are kept by default
+const DefaultCachedStemcells = 3
+
+const extractionLockPollInterval = 500 * time.Millisecond
+
+type cachingExtractor struct {
+	reader           Reader
+	compressor       boshcmd.Compressor
+	digestCalculator bicrypto.DigestCalculator
+	fs               boshsys.FileSystem
+	cacheDir         string
+	keep             int
+	reextract        bool
+	logTag           string
+	logger           boshlog.Logger
+}
+
+// NewCachingExtractor returns an Extractor that keeps extracted stemcells in cacheDir,
+// in a directory named after the digest of the stemcell tarball, so that
+// extracting the same tarball again reuses the directory.
+// A marker file is written next to the directory once extraction completed;
+// a directory without marker is left over from an interrupted extraction and is extracted again.
+// With reextract the cached directory is never reused.
+// Cleanup of the returned stemcells keeps the cached directory.
+//
+// Each directory is locked by a file next to it, exclusively while extracting
+// so that concurrent runs do not extract the same tarball into it at once,
+// and shared until the returned stemcell is cleaned up.
+// After each extraction, directories other than the keep most recently used ones,
+// as well as those left over from interrupted extractions, are removed unless
+// they are locked by another run.
+func NewCachingExtractor(
+	reader Reader,
+	compressor boshcmd.Compressor,
+	digestCalculator bicrypto.DigestCalculator,
+	fs boshsys.FileSystem,
+	cacheDir string,
+	keep int,
+	reextract bool,
+	logger boshlog.Logger,
+) Extractor {
+	return &cachingExtractor{
+		reader:           reader,
+		compressor:       compressor,
+		digestCalculator: digestCalculator,
+		fs:               fs,
+		cacheDir:         cacheDir,
+		keep:             keep,
+		reextract:        reextract,
+		logTag:           "cachingStemcellExtractor",
+		logger:           logger,
+	}
+}
+
+func (e *cachingExtractor) Extract(tarballPath string) (ExtractedStemcell, error) {
+	digest, err := e.digestCalculator.Calculate(tarballPath)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Calculating digest of stemcell '%s'", tarballPath)
+	}
+
+	extractedPath := filepath.Join(e.cacheDir, digest)
+
+	lockFile, err := e.lock(extractedPath)
+	if err != nil {
+		return nil, err
+	}
+
+	stemcell, err := e.extract(tarballPath, digest, extractedPath)
+	if err != nil {
+		_ = lockFile.Close()
+		return nil, err
+	}
+
+	_, err = biutil.TryLockFile(lockFile, false)
+	if err != nil {
+		_ = lockFile.Close()
+		return nil, bosherr.WrapErrorf(err, "Sharing lock of stemcell extraction '%s'", extractedPath)
+	}
+
+	e.prune(digest)
+
+	return cachedExtractedStemcell{stemcell, lockFile}, nil
+}
+
+// lock waits for other runs extracting to or using extractedPath
+func (e *cachingExtractor) lock(extractedPath string) (boshsys.File, error) {
+	lockPath := extractedPath + ".lock"
+
+	err := e.fs.MkdirAll(e.cacheDir, 0755)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Creating stemcell cache dir '%s'", e.cacheDir)
+	}
+
+	lockFile, err := e.fs.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Opening stemcell extraction lock file '%s'", lockPath)
+	}
+
+	for waiting := false; ; waiting = true {
+		locked, err := biutil.TryLockFile(lockFile, true)
+		if err != nil {
+			_ = lockFile.Close()
+			return nil, bosherr.WrapErrorf(err, "Locking stemcell extraction '%s'", extractedPath)
+		}
+
+		if locked {
+			return lockFile, nil
+		}
+
+		if !waiting {
+			e.logger.Info(e.logTag, "Waiting for another run using stemcell extraction '%s'", extractedPath)
+		}
+
+		time.Sleep(extractionLockPollInterval)
+	}
+}
+
+func (e *cachingExtractor) extract(tarballPath, digest, extractedPath string) (ExtractedStemcell, error) {
+	completedPath := extractedPath + ".completed"
+
+	if !e.reextract && e.fs.FileExists(completedPath) {
+		stemcell, err := readExtractedStemcell(extractedPath, e.compressor, e.fs)
+		if err == nil {
+			e.logger.Debug(e.logTag, "Reusing stemcell '%s' extracted to '%s'", tarballPath, extractedPath)
+
+			// Rewriting the marker records when the extraction was last used for pruning
+			err = e.fs.WriteFileString(completedPath, digest)
+			if err != nil {
+				e.logger.Warn(e.logTag, "Marking stemcell extraction '%s' as used: %s", extractedPath, err.Error())
+			}
+
+			return stemcell, nil
+		}
+
+		e.logger.Warn(e.logTag, "Extracting stemcell '%s' again since reading the cached extraction failed: %s", tarballPath, err.Error())
+	}
+
+	err := e.fs.RemoveAll(completedPath)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Removing '%s'", completedPath)
+	}
+
+	err = e.fs.RemoveAll(extractedPath)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Removing previously extracted stemcell '%s'", extractedPath)
+	}
+
+	err = e.fs.MkdirAll(extractedPath, 0755)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Creating dir for stemcell extraction '%s'", extractedPath)
+	}
+
+	stemcell, err := e.reader.Read(tarballPath, extractedPath)
+	if err != nil {
+		_ = e.fs.RemoveAll(extractedPath)
+		return nil, bosherr.WrapErrorf(err, "reading extracted stemcell manifest in '%s'", extractedPath)
+	}
+
+	err = e.fs.WriteFileString(completedPath, digest)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Marking stemcell extraction '%s' as completed", extractedPath)
+	}
+
+	return stemcell, nil
+}
+
+type cachedExtraction struct {
+	path   string
+	usedAt time.Time
+}
+
+// prune removes extractions beyond the keep most recently used ones, counting
+// the one of currentDigest, and those that did not complete.
+// Failing to prune is only logged since the cache still works.
+func (e *cachingExtractor) prune(currentDigest string) {
+	paths, err := e.fs.Glob(filepath.Join(e.cacheDir, "*"))
+	if err != nil {
+		e.logger.Warn(e.logTag, "Listing extracted stemcells in '%s': %s", e.cacheDir, err.Error())
+		return
+	}
+
+	var completed []cachedExtraction
+	var removable []string
+
+	for _, path := range paths {
+		name := filepath.Base(path)
+
+		// Markers and lock files are removed along with their extraction
+		if name == currentDigest || filepath.Ext(name) != "" {
+			continue
+		}
+
+		if !e.fs.FileExists(path + ".completed") {
+			removable = append(removable, path)
+			continue
+		}
+
+		info, err := e.fs.Stat(path + ".completed")
+		if err != nil {
+			e.logger.Warn(e.logTag, "Checking use of stemcell extraction '%s': %s", path, err.Error())
+			continue
+		}
+
+		completed = append(completed, cachedExtraction{path: path, usedAt: info.ModTime()})
+	}
+
+	sort.SliceStable(completed, func(i, j int) bool {
+		return completed[i].usedAt.After(completed[j].usedAt)
+	})
+
+	for i, extraction := range completed {
+		if i+1 >= e.keep {
+			removable = append(removable, extraction.path)
+		}
+	}
+
+	for _, path := range removable {
+		e.remove(path)
+	}
+}
+
+// remove removes an extraction unless another run uses it.
+// Its lock file is kept so that runs waiting for it lock the same file.
+func (e *cachingExtractor) remove(extractedPath string) {
+	lockPath := extractedPath + ".lock"
+
+	lockFile, err := e.fs.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		e.logger.Warn(e.logTag, "Opening stemcell extraction lock file '%s': %s", lockPath, err.Error())
+		return
+	}
+
+	defer func() { _ = lockFile.Close() }()
+
+	locked, err := biutil.TryLockFile(lockFile, true)
+	if err != nil || !locked {
+		e.logger.Debug(e.logTag, "Keeping stemcell extraction '%s' used by another run", extractedPath)
+		return
+	}
+
+	e.logger.Debug(e.logTag, "Pruning stemcell extraction '%s'", extractedPath)
+
+	err = e.fs.RemoveAll(extractedPath + ".completed")
+	if err == nil {
+		err = e.fs.RemoveAll(extractedPath)
+	}
+	if err != nil {
+		e.logger.Warn(e.logTag, "Pruning stemcell extraction '%s': %s", extractedPath, err.Error())
+	}
+}
+
+type cachedExtractedStemcell struct {
+	ExtractedStemcell
+	lockFile boshsys.File
+}
+
+// Cleanup keeps the extracted stemcell so that it can be reused,
+// and lets other runs prune it
+func (s cachedExtractedStemcell) Cleanup() error {
+	return s.lockFile.Close()
+}
@@ -0,0 +1,188 @@
+package stemcell_test
+
+import (
+	"errors"
+	"time"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	fakebicrypto "github.com/cloudfoundry/bosh-cli/crypto/fakes"
+	. "github.com/cloudfoundry/bosh-cli/stemcell"
+	fakebistemcell "github.com/cloudfoundry/bosh-cli/stemcell/stemcellfakes"
+)
+
+var _ = Describe("CachingExtractor", func() {
+	var (
+		fs               *fakesys.FakeFileSystem
+		reader           *fakebistemcell.FakeStemcellReader
+		digestCalculator *fakebicrypto.FakeDigestCalculator
+		reextract        bool
+
+		tarballPath   = "/stemcell.tgz"
+		extractedPath = "/cache/fake-sha1"
+		completedPath = "/cache/fake-sha1.completed"
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		reader = fakebistemcell.NewFakeReader()
+		digestCalculator = fakebicrypto.NewFakeDigestCalculator()
+		digestCalculator.SetCalculateBehavior(map[string]fakebicrypto.CalculateInput{
+			tarballPath: {DigestStr: "fake-sha1"},
+		})
+		reextract = false
+
+		reader.SetReadBehavior(tarballPath, extractedPath,
+			NewExtractedStemcell(Manifest{Name: "fake-stemcell-name"}, extractedPath, nil, fs), nil)
+	})
+
+	extract := func() (ExtractedStemcell, error) {
+		extractor := NewCachingExtractor(reader, nil, digestCalculator, fs, "/cache", 2, reextract, boshlog.NewLogger(boshlog.LevelNone))
+		return extractor.Extract(tarballPath)
+	}
+
+	It("extracts into a directory named after the tarball digest and marks it as completed", func() {
+		stemcell, err := extract()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stemcell.Manifest().Name).To(Equal("fake-stemcell-name"))
+		Expect(stemcell.GetExtractedPath()).To(Equal(extractedPath))
+
+		Expect(reader.ReadInputs).To(HaveLen(1))
+		Expect(fs.ReadFileString(completedPath)).To(Equal("fake-sha1"))
+	})
+
+	It("keeps the extracted directory on cleanup", func() {
+		stemcell, err := extract()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(stemcell.Cleanup()).To(Succeed())
+		Expect(fs.FileExists(extractedPath)).To(BeTrue())
+	})
+
+	It("holds the lock of the extraction until cleanup", func() {
+		stemcell, err := extract()
+		Expect(err).ToNot(HaveOccurred())
+
+		lockStats, err := fs.FindFileStats("/cache/fake-sha1.lock")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lockStats.Open).To(BeTrue())
+
+		Expect(stemcell.Cleanup()).To(Succeed())
+		Expect(lockStats.Open).To(BeFalse())
+	})
+
+	It("prunes least recently used and interrupted extractions beyond the ones to keep", func() {
+		for digest, usedAt := range map[string]time.Time{
+			"old-sha1":   time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			"newer-sha1": time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		} {
+			fs.WriteFileString("/cache/"+digest+"/stemcell.MF", "name: fake-cached-stemcell-name")
+			fs.WriteFileString("/cache/"+digest+".completed", digest)
+			fs.WriteFileString("/cache/"+digest+".lock", "")
+
+			markerStats, err := fs.FindFileStats("/cache/" + digest + ".completed")
+			Expect(err).ToNot(HaveOccurred())
+			markerStats.ModTime = usedAt
+		}
+		fs.WriteFileString("/cache/interrupted-sha1/partial-image", "fake-partial-image")
+
+		fs.SetGlob("/cache/*", []string{
+			"/cache/fake-sha1", "/cache/fake-sha1.completed", "/cache/fake-sha1.lock",
+			"/cache/interrupted-sha1",
+			"/cache/newer-sha1", "/cache/newer-sha1.completed", "/cache/newer-sha1.lock",
+			"/cache/old-sha1", "/cache/old-sha1.completed", "/cache/old-sha1.lock",
+		})
+
+		_, err := extract()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fs.FileExists(extractedPath)).To(BeTrue())
+		Expect(fs.FileExists("/cache/newer-sha1")).To(BeTrue())
+		Expect(fs.FileExists("/cache/newer-sha1.completed")).To(BeTrue())
+
+		Expect(fs.FileExists("/cache/old-sha1")).To(BeFalse())
+		Expect(fs.FileExists("/cache/old-sha1.completed")).To(BeFalse())
+		Expect(fs.FileExists("/cache/old-sha1.lock")).To(BeTrue())
+		Expect(fs.FileExists("/cache/interrupted-sha1")).To(BeFalse())
+	})
+
+	It("keeps extracting when the cache cannot be listed for pruning", func() {
+		fs.GlobErr = errors.New("fake-glob-error")
+
+		stemcell, err := extract()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stemcell.GetExtractedPath()).To(Equal(extractedPath))
+	})
+
+	Context("when the tarball was extracted before", func() {
+		BeforeEach(func() {
+			fs.WriteFileString(extractedPath+"/stemcell.MF", "name: fake-cached-stemcell-name")
+			fs.WriteFileString(completedPath, "fake-sha1")
+		})
+
+		It("reuses the extracted directory", func() {
+			stemcell, err := extract()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stemcell.Manifest().Name).To(Equal("fake-cached-stemcell-name"))
+			Expect(stemcell.GetExtractedPath()).To(Equal(extractedPath))
+
+			Expect(reader.ReadInputs).To(BeEmpty())
+		})
+
+		It("records the use of the extracted directory", func() {
+			markerStats, err := fs.FindFileStats(completedPath)
+			Expect(err).ToNot(HaveOccurred())
+			markerStats.Content = nil
+
+			_, err = extract()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fs.ReadFileString(completedPath)).To(Equal("fake-sha1"))
+		})
+
+		It("extracts again when asked to", func() {
+			reextract = true
+
+			stemcell, err := extract()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stemcell.Manifest().Name).To(Equal("fake-stemcell-name"))
+
+			Expect(reader.ReadInputs).To(HaveLen(1))
+			Expect(fs.FileExists(extractedPath + "/stemcell.MF")).To(BeFalse())
+		})
+	})
+
+	It("extracts again when a previous extraction did not complete", func() {
+		fs.WriteFileString(extractedPath+"/partial-image", "fake-partial-image")
+
+		_, err := extract()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(reader.ReadInputs).To(HaveLen(1))
+		Expect(fs.FileExists(extractedPath + "/partial-image")).To(BeFalse())
+	})
+
+	It("removes the extracted directory without marking it as completed when extraction fails", func() {
+		reader.SetReadBehavior(tarballPath, extractedPath, nil, errors.New("fake-read-error"))
+
+		_, err := extract()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-read-error"))
+
+		Expect(fs.FileExists(extractedPath)).To(BeFalse())
+		Expect(fs.FileExists(completedPath)).To(BeFalse())
+	})
+
+	It("returns an error when the digest cannot be calculated", func() {
+		digestCalculator.SetCalculateBehavior(map[string]fakebicrypto.CalculateInput{
+			tarballPath: {Err: errors.New("fake-digest-error")},
+		})
+
+		_, err := extract()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-digest-error"))
+		Expect(reader.ReadInputs).To(BeEmpty())
+	})
+})
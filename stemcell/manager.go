@@ -12,7 +12,7 @@ import (
 
 type Manager interface {
 	FindCurrent() ([]CloudStemcell, error)
-	Upload(ExtractedStemcell, biui.Stage) (CloudStemcell, error)
+	Upload(ExtractedStemcell, map[string]string, biui.Stage) (CloudStemcell, error)
 	FindUnused() ([]CloudStemcell, error)
 	DeleteUnused(biui.Stage) error
 }
@@ -46,9 +46,9 @@ func (m *manager) FindCurrent() ([]CloudStemcell, error) {
 }
 
 // Upload stemcell to an IAAS. It does the following steps:
-// 1) uploads the stemcell to the cloud (if needed),
+// 1) uploads the stemcell to the cloud (if needed), passing tags to the CPI as 'tags' in its cloud properties,
 // 2) saves a record of the uploaded stemcell in the repo
-func (m *manager) Upload(extractedStemcell ExtractedStemcell, uploadStage biui.Stage) (cloudStemcell CloudStemcell, err error) {
+func (m *manager) Upload(extractedStemcell ExtractedStemcell, tags map[string]string, uploadStage biui.Stage) (cloudStemcell CloudStemcell, err error) {
 	manifest := extractedStemcell.Manifest()
 	stageName := fmt.Sprintf("Uploading stemcell '%s/%s'", manifest.Name, manifest.Version)
 	err = uploadStage.Perform(stageName, func() error {
@@ -62,7 +62,7 @@ func (m *manager) Upload(extractedStemcell ExtractedStemcell, uploadStage biui.S
 			return biui.NewSkipStageError(bosherr.Errorf("Found stemcell: %#v", foundStemcellRecord), "Stemcell already uploaded")
 		}
 
-		cid, err := m.cloud.CreateStemcell(filepath.Join(extractedStemcell.GetExtractedPath(), "image"), manifest.CloudProperties)
+		cid, err := m.cloud.CreateStemcell(filepath.Join(extractedStemcell.GetExtractedPath(), "image"), bicloud.CloudPropertiesWithTags(manifest.CloudProperties, tags))
 		if err != nil {
 			return bosherr.WrapErrorf(err, "creating stemcell (%s %s)", manifest.Name, manifest.Version)
 		}
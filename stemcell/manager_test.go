@@ -80,7 +80,7 @@ var _ = Describe("Manager", func() {
 		})
 
 		It("uploads the stemcell to the infrastructure and returns the cid", func() {
-			cloudStemcell, err := manager.Upload(expectedExtractedStemcell, fakeStage)
+			cloudStemcell, err := manager.Upload(expectedExtractedStemcell, nil, fakeStage)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(cloudStemcell).To(Equal(expectedCloudStemcell))
 
@@ -94,8 +94,35 @@ var _ = Describe("Manager", func() {
 			}))
 		})
 
+		It("passes tags to the infrastructure in the stemcell cloud properties", func() {
+			expectedExtractedStemcell.SetCloudProperties(biproperty.Map{
+				"fake-prop-key": "fake-prop-value",
+				"tags":          map[interface{}]interface{}{"stemcell-tag": "stemcell-value", "owner": "stemcell-owner"},
+			})
+
+			_, err := manager.Upload(expectedExtractedStemcell, map[string]string{"owner": "fake-owner", "cost-center": "fake-cost-center"}, fakeStage)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeCloud.CreateStemcellInputs).To(Equal([]fakebicloud.CreateStemcellInput{
+				{
+					ImagePath: filepath.Join(tempExtractionDir, "image"),
+					CloudProperties: biproperty.Map{
+						"fake-prop-key": "fake-prop-value",
+						"tags": biproperty.Map{
+							"stemcell-tag": "stemcell-value",
+							"owner":        "fake-owner",
+							"cost-center":  "fake-cost-center",
+						},
+					},
+				},
+			}))
+			Expect(expectedExtractedStemcell.Manifest().CloudProperties["tags"]).To(Equal(
+				map[interface{}]interface{}{"stemcell-tag": "stemcell-value", "owner": "stemcell-owner"},
+			))
+		})
+
 		It("saves the stemcell record in the stemcellRepo", func() {
-			cloudStemcell, err := manager.Upload(expectedExtractedStemcell, fakeStage)
+			cloudStemcell, err := manager.Upload(expectedExtractedStemcell, nil, fakeStage)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(cloudStemcell).To(Equal(expectedCloudStemcell))
 
@@ -111,7 +138,7 @@ var _ = Describe("Manager", func() {
 		})
 
 		It("prints uploading ui stage", func() {
-			_, err := manager.Upload(expectedExtractedStemcell, fakeStage)
+			_, err := manager.Upload(expectedExtractedStemcell, nil, fakeStage)
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(fakeStage.PerformCalls).To(Equal([]*fakebiui.PerformCall{
@@ -121,7 +148,7 @@ var _ = Describe("Manager", func() {
 
 		It("when the upload fails, prints failed uploading ui stage", func() {
 			fakeCloud.CreateStemcellErr = errors.New("fake-create-error")
-			_, err := manager.Upload(expectedExtractedStemcell, fakeStage)
+			_, err := manager.Upload(expectedExtractedStemcell, nil, fakeStage)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("fake-create-error"))
 
@@ -132,7 +159,7 @@ var _ = Describe("Manager", func() {
 
 		It("when the stemcellRepo save fails, logs uploading start and failure events to the eventLogger", func() {
 			fs.WriteFileError = errors.New("fake-save-error")
-			_, err := manager.Upload(expectedExtractedStemcell, fakeStage)
+			_, err := manager.Upload(expectedExtractedStemcell, nil, fakeStage)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("fake-save-error"))
 
@@ -153,20 +180,20 @@ var _ = Describe("Manager", func() {
 			})
 
 			It("returns the existing cloud stemcell", func() {
-				stemcell, err := manager.Upload(expectedExtractedStemcell, fakeStage)
+				stemcell, err := manager.Upload(expectedExtractedStemcell, nil, fakeStage)
 				Expect(err).ToNot(HaveOccurred())
 				foundStemcell := NewCloudStemcell(foundStemcellRecord, stemcellRepo, fakeCloud)
 				Expect(stemcell).To(Equal(foundStemcell))
 			})
 
 			It("does not re-upload the stemcell to the infrastructure", func() {
-				_, err := manager.Upload(expectedExtractedStemcell, fakeStage)
+				_, err := manager.Upload(expectedExtractedStemcell, nil, fakeStage)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(fakeCloud.CreateStemcellInputs).To(HaveLen(0))
 			})
 
 			It("logs skipping uploading events to the eventLogger", func() {
-				_, err := manager.Upload(expectedExtractedStemcell, fakeStage)
+				_, err := manager.Upload(expectedExtractedStemcell, nil, fakeStage)
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(fakeStage.PerformCalls[0].Name).To(Equal("Uploading stemcell 'fake-stemcell-name/fake-stemcell-version'"))
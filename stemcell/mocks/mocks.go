@@ -133,13 +133,13 @@ func (_mr *_MockManagerRecorder) FindUnused() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "FindUnused")
 }
 
-func (_m *MockManager) Upload(_param0 stemcell.ExtractedStemcell, _param1 ui.Stage) (stemcell.CloudStemcell, error) {
-	ret := _m.ctrl.Call(_m, "Upload", _param0, _param1)
+func (_m *MockManager) Upload(_param0 stemcell.ExtractedStemcell, _param1 map[string]string, _param2 ui.Stage) (stemcell.CloudStemcell, error) {
+	ret := _m.ctrl.Call(_m, "Upload", _param0, _param1, _param2)
 	ret0, _ := ret[0].(stemcell.CloudStemcell)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-func (_mr *_MockManagerRecorder) Upload(arg0, arg1 interface{}) *gomock.Call {
-	return _mr.mock.ctrl.RecordCall(_mr.mock, "Upload", arg0, arg1)
+func (_mr *_MockManagerRecorder) Upload(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Upload", arg0, arg1, arg2)
 }
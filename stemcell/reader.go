@@ -31,10 +31,15 @@ func (s reader) Read(stemcellTarballPath string, extractedPath string) (Extracte
 		return nil, bosherr.WrapErrorf(err, "Extracting stemcell from '%s' to '%s'", stemcellTarballPath, extractedPath)
 	}
 
+	return readExtractedStemcell(extractedPath, s.compressor, s.fs)
+}
+
+// readExtractedStemcell parses the manifest of a stemcell already extracted to extractedPath
+func readExtractedStemcell(extractedPath string, compressor boshcmd.Compressor, fs boshsys.FileSystem) (ExtractedStemcell, error) {
 	var manifest Manifest
 	manifestPath := filepath.Join(extractedPath, "stemcell.MF")
 
-	manifestContents, err := s.fs.ReadFile(manifestPath)
+	manifestContents, err := fs.ReadFile(manifestPath)
 	if err != nil {
 		return nil, bosherr.WrapErrorf(err, "Reading stemcell manifest '%s'", manifestPath)
 	}
@@ -47,8 +52,8 @@ func (s reader) Read(stemcellTarballPath string, extractedPath string) (Extracte
 	stemcell := NewExtractedStemcell(
 		manifest,
 		extractedPath,
-		s.compressor,
-		s.fs,
+		compressor,
+		fs,
 	)
 	return stemcell, nil
 }
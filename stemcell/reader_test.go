@@ -30,6 +30,7 @@ version: '2690'
 operating_system: ubuntu-trusty
 sha1: sha
 bosh_protocol: 1
+api_version: 2
 stemcell_formats: ['aws-raw']
 cloud_properties:
   infrastructure: aws
@@ -56,6 +57,7 @@ cloud_properties:
 				OS:              "ubuntu-trusty",
 				SHA1:            "sha",
 				BoshProtocol:    "1",
+				APIVersion:      2,
 				StemcellFormats: []string{"aws-raw"},
 				CloudProperties: biproperty.Map{
 					"infrastructure": "aws",
@@ -39,6 +39,7 @@ type Manifest struct {
 	OS              string         `yaml:"operating_system"`
 	SHA1            string         `yaml:"sha1"`
 	BoshProtocol    string         `yaml:"bosh_protocol"`
+	APIVersion      int            `yaml:"api_version,omitempty"`
 	StemcellFormats []string       `yaml:"stemcell_formats,omitempty"`
 	CloudProperties biproperty.Map `yaml:"cloud_properties"`
 }
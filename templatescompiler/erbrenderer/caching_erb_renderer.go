@@ -0,0 +1,185 @@
+package erbrenderer
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// DefaultTemplateCacheMaxAge is how long cached renderings are kept after they were cached
+const DefaultTemplateCacheMaxAge = 30 * 24 * time.Hour
+
+type cachingERBRenderer struct {
+	renderer    ERBRenderer
+	fs          boshsys.FileSystem
+	timeService clock.Clock
+	cacheDir    string
+	maxAge      time.Duration
+	rerender    bool
+	logTag      string
+	logger      boshlog.Logger
+
+	pruneOnce *sync.Once
+}
+
+// NewCachingERBRenderer returns an ERBRenderer that keeps rendered templates in cacheDir,
+// in files named after the digest of the template, its evaluation context and the renderer script,
+// so that rendering the same template with the same context again copies the cached file instead of running ruby.
+// With rerender templates are always rendered and the cached files are replaced.
+// Renderings cached more than maxAge ago are removed the first time a template is rendered.
+// Rendered templates may contain credentials, so cacheDir and the cached files are only accessible by the current user.
+func NewCachingERBRenderer(
+	renderer ERBRenderer,
+	fs boshsys.FileSystem,
+	timeService clock.Clock,
+	cacheDir string,
+	maxAge time.Duration,
+	rerender bool,
+	logger boshlog.Logger,
+) ERBRenderer {
+	return cachingERBRenderer{
+		renderer:    renderer,
+		fs:          fs,
+		timeService: timeService,
+		cacheDir:    cacheDir,
+		maxAge:      maxAge,
+		rerender:    rerender,
+		logTag:      "cachingERBRenderer",
+		logger:      logger,
+
+		pruneOnce: &sync.Once{},
+	}
+}
+
+func (r cachingERBRenderer) Render(srcPath, dstPath string, context TemplateEvaluationContext) error {
+	r.pruneOnce.Do(r.prune)
+
+	template, err := r.fs.ReadFile(srcPath)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Reading template '%s'", srcPath)
+	}
+
+	contextJSON, err := context.MarshalJSON()
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling context")
+	}
+
+	digest, err := boshcrypto.DigestAlgorithmSHA1.CreateDigest(io.MultiReader(
+		strings.NewReader(templateEvaluationContextRb),
+		bytes.NewReader(template),
+		bytes.NewReader(contextJSON),
+	))
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Calculating digest of template '%s'", srcPath)
+	}
+
+	cachedPath := filepath.Join(r.cacheDir, digest.String())
+
+	if !r.rerender && r.fs.FileExists(cachedPath) {
+		err := r.fs.CopyFile(cachedPath, dstPath)
+		if err == nil {
+			r.logger.Debug(r.logTag, "Reusing template %s rendered to '%s'", dstPath, cachedPath)
+			return nil
+		}
+
+		r.logger.Warn(r.logTag, "Rendering template %s again since copying the cached rendering failed: %s", dstPath, err.Error())
+	}
+
+	// The already marshalled context is rendered since contexts may generate values, e.g. instance IDs,
+	// and the cached file must match the digest
+	err = r.renderer.Render(srcPath, dstPath, marshalledContext(contextJSON))
+	if err != nil {
+		return err
+	}
+
+	err = r.cache(dstPath, cachedPath)
+	if err != nil {
+		r.logger.Warn(r.logTag, "Failed to cache rendered template %s: %s", dstPath, err.Error())
+	}
+
+	return nil
+}
+
+// cache writes to a temporary file first so that an interrupted write is never reused
+func (r cachingERBRenderer) cache(renderedPath, cachedPath string) error {
+	err := r.fs.MkdirAll(r.cacheDir, 0700)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Creating template cache '%s'", r.cacheDir)
+	}
+
+	// Caches created by earlier versions may be accessible by others
+	err = r.fs.Chmod(r.cacheDir, 0700)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Restricting access to template cache '%s'", r.cacheDir)
+	}
+
+	rendered, err := r.fs.ReadFile(renderedPath)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Reading rendered template '%s'", renderedPath)
+	}
+
+	partialPath := cachedPath + ".partial"
+
+	err = r.fs.RemoveAll(partialPath)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Removing '%s'", partialPath)
+	}
+
+	partialFile, err := r.fs.OpenFile(partialPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Creating '%s'", partialPath)
+	}
+
+	_, err = partialFile.Write(rendered)
+	closeErr := partialFile.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing rendered template to '%s'", partialPath)
+	}
+
+	return r.fs.Rename(partialPath, cachedPath)
+}
+
+// prune removes renderings, including interrupted ones, that were cached more than maxAge ago.
+// Renderings still in use are rendered and cached again afterwards.
+// Failing to prune is only logged since the cache still works.
+func (r cachingERBRenderer) prune() {
+	paths, err := r.fs.Glob(filepath.Join(r.cacheDir, "*"))
+	if err != nil {
+		r.logger.Warn(r.logTag, "Listing cached renderings in '%s': %s", r.cacheDir, err.Error())
+		return
+	}
+
+	for _, path := range paths {
+		info, err := r.fs.Stat(path)
+		if err != nil {
+			r.logger.Warn(r.logTag, "Checking age of cached rendering '%s': %s", path, err.Error())
+			continue
+		}
+
+		if r.timeService.Since(info.ModTime()) < r.maxAge {
+			continue
+		}
+
+		err = r.fs.RemoveAll(path)
+		if err != nil {
+			r.logger.Warn(r.logTag, "Removing cached rendering '%s': %s", path, err.Error())
+		}
+	}
+}
+
+type marshalledContext []byte
+
+func (c marshalledContext) MarshalJSON() ([]byte, error) { return c, nil }
@@ -0,0 +1,200 @@
+package erbrenderer_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/templatescompiler/erbrenderer"
+)
+
+type countingERBRenderer struct {
+	fs       *fakesys.FakeFileSystem
+	rendered int
+	err      error
+}
+
+func (r *countingERBRenderer) Render(srcPath, dstPath string, context TemplateEvaluationContext) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	r.rendered++
+
+	contextJSON, err := context.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	template, err := r.fs.ReadFileString(srcPath)
+	if err != nil {
+		return err
+	}
+
+	return r.fs.WriteFileString(dstPath, fmt.Sprintf("%s rendered with %s", template, contextJSON))
+}
+
+type staticContext string
+
+func (c staticContext) MarshalJSON() ([]byte, error) { return []byte(c), nil }
+
+type generatingContext struct {
+	generated *int
+}
+
+func (c generatingContext) MarshalJSON() ([]byte, error) {
+	*c.generated++
+	return []byte(fmt.Sprintf(`{"id":"%d"}`, *c.generated)), nil
+}
+
+var _ = Describe("CachingERBRenderer", func() {
+	var (
+		fs       *fakesys.FakeFileSystem
+		renderer *countingERBRenderer
+		clock    *fakeclock.FakeClock
+		logger   boshlog.Logger
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		renderer = &countingERBRenderer{fs: fs}
+		clock = fakeclock.NewFakeClock(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+		logger = boshlog.NewLogger(boshlog.LevelNone)
+
+		fs.WriteFileString("/templates/config.erb", "fake-template")
+	})
+
+	newCachingRenderer := func(rerender bool) ERBRenderer {
+		return NewCachingERBRenderer(renderer, fs, clock, "/cache", 24*time.Hour, rerender, logger)
+	}
+
+	It("reuses the rendering of an unchanged template and context", func() {
+		err := newCachingRenderer(false).Render("/templates/config.erb", "/rendered-1/config", staticContext(`{"a":1}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		err = newCachingRenderer(false).Render("/templates/config.erb", "/rendered-2/config", staticContext(`{"a":1}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(renderer.rendered).To(Equal(1))
+		Expect(fs.ReadFileString("/rendered-2/config")).To(Equal(`fake-template rendered with {"a":1}`))
+	})
+
+	It("renders again when the context changed", func() {
+		err := newCachingRenderer(false).Render("/templates/config.erb", "/rendered-1/config", staticContext(`{"a":1}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		err = newCachingRenderer(false).Render("/templates/config.erb", "/rendered-2/config", staticContext(`{"a":2}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(renderer.rendered).To(Equal(2))
+		Expect(fs.ReadFileString("/rendered-2/config")).To(Equal(`fake-template rendered with {"a":2}`))
+	})
+
+	It("renders again when the template changed", func() {
+		err := newCachingRenderer(false).Render("/templates/config.erb", "/rendered-1/config", staticContext(`{"a":1}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		fs.WriteFileString("/templates/config.erb", "fake-changed-template")
+
+		err = newCachingRenderer(false).Render("/templates/config.erb", "/rendered-2/config", staticContext(`{"a":1}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(renderer.rendered).To(Equal(2))
+		Expect(fs.ReadFileString("/rendered-2/config")).To(Equal(`fake-changed-template rendered with {"a":1}`))
+	})
+
+	It("always renders with rerender and refreshes the cache", func() {
+		err := newCachingRenderer(false).Render("/templates/config.erb", "/rendered-1/config", staticContext(`{"a":1}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		err = newCachingRenderer(true).Render("/templates/config.erb", "/rendered-2/config", staticContext(`{"a":1}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(renderer.rendered).To(Equal(2))
+	})
+
+	It("renders the context that was digested", func() {
+		var generated int
+
+		err := newCachingRenderer(false).Render("/templates/config.erb", "/rendered/config", generatingContext{generated: &generated})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(generated).To(Equal(1))
+		Expect(fs.ReadFileString("/rendered/config")).To(Equal(`fake-template rendered with {"id":"1"}`))
+	})
+
+	It("keeps the cache only accessible by the current user", func() {
+		Expect(fs.MkdirAll("/cache", 0755)).To(Succeed())
+
+		err := newCachingRenderer(false).Render("/templates/config.erb", "/rendered/config", staticContext(`{}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fs.GetFileTestStat("/cache").FileMode).To(Equal(os.FileMode(0700)))
+
+		Expect(fs.RenameNewPaths).To(HaveLen(1))
+		Expect(fs.GetFileTestStat(fs.RenameNewPaths[0]).FileMode).To(Equal(os.FileMode(0600)))
+	})
+
+	It("removes renderings cached more than the maximum age ago, once per renderer", func() {
+		fs.WriteFileString("/cache/old", "old-rendering")
+		fs.GetFileTestStat("/cache/old").ModTime = clock.Now().Add(-25 * time.Hour)
+		fs.WriteFileString("/cache/old.partial", "interrupted-rendering")
+		fs.GetFileTestStat("/cache/old.partial").ModTime = clock.Now().Add(-25 * time.Hour)
+		fs.WriteFileString("/cache/recent", "recent-rendering")
+		fs.GetFileTestStat("/cache/recent").ModTime = clock.Now().Add(-23 * time.Hour)
+		fs.SetGlob("/cache/*", []string{"/cache/old", "/cache/old.partial", "/cache/recent"})
+
+		cachingRenderer := newCachingRenderer(false)
+
+		err := cachingRenderer.Render("/templates/config.erb", "/rendered-1/config", staticContext(`{}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fs.FileExists("/cache/old")).To(BeFalse())
+		Expect(fs.FileExists("/cache/old.partial")).To(BeFalse())
+		Expect(fs.FileExists("/cache/recent")).To(BeTrue())
+
+		fs.WriteFileString("/cache/old", "old-rendering")
+
+		err = cachingRenderer.Render("/templates/config.erb", "/rendered-2/config", staticContext(`{}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fs.FileExists("/cache/old")).To(BeTrue())
+	})
+
+	It("does not rewrite reused renderings", func() {
+		err := newCachingRenderer(false).Render("/templates/config.erb", "/rendered-1/config", staticContext(`{}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		writes := fs.WriteFileCallCount
+
+		err = newCachingRenderer(false).Render("/templates/config.erb", "/rendered-2/config", staticContext(`{}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(renderer.rendered).To(Equal(1))
+		Expect(fs.WriteFileCallCount).To(Equal(writes))
+		Expect(fs.RenameNewPaths).To(HaveLen(1))
+	})
+
+	It("does not cache failed renderings", func() {
+		renderer.err = errors.New("fake-render-err")
+
+		err := newCachingRenderer(false).Render("/templates/config.erb", "/rendered/config", staticContext(`{}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("fake-render-err"))
+
+		Expect(fs.FileExists("/cache")).To(BeFalse())
+	})
+
+	It("returns an error when the template cannot be read", func() {
+		err := newCachingRenderer(false).Render("/templates/missing.erb", "/rendered/config", staticContext(`{}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Reading template '/templates/missing.erb'"))
+	})
+})
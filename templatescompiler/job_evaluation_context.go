@@ -18,6 +18,7 @@ type jobEvaluationContext struct {
 	globalProperties     biproperty.Map
 	deploymentName       string
 	address              string
+	instance             InstanceSpec
 	uuidGen              boshuuid.Generator
 	logger               boshlog.Logger
 	logTag               string
@@ -26,6 +27,7 @@ type jobEvaluationContext struct {
 // RootContext is exposed as an open struct in ERB templates.
 // It must stay same to provide backwards compatible API.
 type RootContext struct {
+	Name       string     `json:"name,omitempty"`
 	Index      int        `json:"index"`
 	ID         string     `json:"id"`
 	AZ         string     `json:"az"`
@@ -44,6 +46,16 @@ type RootContext struct {
 	DefaultProperties biproperty.Map  `json:"default_properties"` // values from release's job's spec
 }
 
+// InstanceSpec describes the instance a job is being rendered for.
+// Empty ID and AZ fall back to a generated UUID and "unknown".
+type InstanceSpec struct {
+	Name      string
+	Index     int
+	ID        string
+	AZ        string
+	Bootstrap bool
+}
+
 type jobContext struct {
 	Name string `json:"name"`
 }
@@ -61,6 +73,7 @@ func NewJobEvaluationContext(
 	globalProperties biproperty.Map,
 	deploymentName string,
 	address string,
+	instance InstanceSpec,
 	uuidGen boshuuid.Generator,
 	logger boshlog.Logger,
 ) bierbrenderer.TemplateEvaluationContext {
@@ -71,6 +84,7 @@ func NewJobEvaluationContext(
 		globalProperties:     globalProperties,
 		deploymentName:       deploymentName,
 		address:              address,
+		instance:             instance,
 		uuidGen:              uuidGen,
 		logTag:               "jobEvaluationContext",
 		logger:               logger,
@@ -82,9 +96,11 @@ func (ec jobEvaluationContext) MarshalJSON() ([]byte, error) {
 	var err error
 
 	context := RootContext{
-		Index:             0,
-		AZ:                "unknown",
-		Bootstrap:         true,
+		Name:              ec.instance.Name,
+		Index:             ec.instance.Index,
+		ID:                ec.instance.ID,
+		AZ:                ec.instance.AZ,
+		Bootstrap:         ec.instance.Bootstrap,
 		JobContext:        jobContext{Name: ec.releaseJob.Name()},
 		Deployment:        ec.deploymentName,
 		NetworkContexts:   ec.buildNetworkContexts(),
@@ -98,9 +114,15 @@ func (ec jobEvaluationContext) MarshalJSON() ([]byte, error) {
 		context.Address = ec.address
 	}
 
-	context.ID, err = ec.uuidGen.Generate()
-	if err != nil {
-		return []byte{}, bosherr.WrapErrorf(err, "Setting job eval context's ID to UUID: %#v", context)
+	if len(context.AZ) == 0 {
+		context.AZ = "unknown"
+	}
+
+	if len(context.ID) == 0 {
+		context.ID, err = ec.uuidGen.Generate()
+		if err != nil {
+			return []byte{}, bosherr.WrapErrorf(err, "Setting job eval context's ID to UUID: %#v", context)
+		}
 	}
 
 	ec.logger.Debug(ec.logTag, "Marshalling context %#v", context)
@@ -29,6 +29,7 @@ var _ = Describe("JobEvaluationContext", func() {
 		deploymentProperties    biproperty.Map
 		erbRenderer             erbrenderer.ERBRenderer
 		jobEvaluationContext    bierbrenderer.TemplateEvaluationContext
+		instance                InstanceSpec
 		uuidGen                 *fakeuuid.FakeGenerator
 	)
 
@@ -47,6 +48,7 @@ var _ = Describe("JobEvaluationContext", func() {
 
 		instanceGroupProperties = biproperty.Map{}
 
+		instance = InstanceSpec{Bootstrap: true}
 		uuidGen = fakeuuid.NewFakeGenerator()
 		jobProperties = nil
 	})
@@ -61,6 +63,7 @@ var _ = Describe("JobEvaluationContext", func() {
 			deploymentProperties,
 			"fake-deployment-name",
 			"1.2.3.4",
+			instance,
 			uuidGen,
 			logger,
 		)
@@ -112,7 +115,34 @@ var _ = Describe("JobEvaluationContext", func() {
 		})
 	})
 
-	getValueFor := func(key string) string {
+	Context("when an instance is given", func() {
+		BeforeEach(func() {
+			instance = InstanceSpec{
+				Name:      "fake-instance-group",
+				Index:     1,
+				ID:        "fake-instance-id",
+				AZ:        "fake-az",
+				Bootstrap: false,
+			}
+		})
+
+		It("uses the instance values in the spec", func() {
+			generatedContext := act()
+			Expect(generatedContext.Name).To(Equal("fake-instance-group"))
+			Expect(generatedContext.Index).To(Equal(1))
+			Expect(generatedContext.ID).To(Equal("fake-instance-id"))
+			Expect(generatedContext.AZ).To(Equal("fake-az"))
+			Expect(generatedContext.Bootstrap).To(BeFalse())
+		})
+
+		It("does not generate an ID", func() {
+			uuidGen.GenerateError = errors.Error("boom")
+			_, err := jobEvaluationContext.MarshalJSON()
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	render := func(erbContents string) string {
 		logger := boshlog.NewLogger(boshlog.LevelNone)
 		fs := boshsys.NewOsFileSystem(logger)
 		commandRunner := boshsys.NewExecCmdRunner(logger)
@@ -122,7 +152,6 @@ var _ = Describe("JobEvaluationContext", func() {
 		Expect(err).ToNot(HaveOccurred())
 		defer os.Remove(srcFile.Name())
 
-		erbContents := fmt.Sprintf("<%%= p('%s') %%>", key)
 		_, err = srcFile.WriteString(erbContents)
 		Expect(err).ToNot(HaveOccurred())
 
@@ -139,6 +168,7 @@ var _ = Describe("JobEvaluationContext", func() {
 			deploymentProperties,
 			"fake-deployment-name",
 			"1.2.3.4",
+			instance,
 			uuidGen,
 			logger,
 		)
@@ -150,6 +180,27 @@ var _ = Describe("JobEvaluationContext", func() {
 		return (string)(contents)
 	}
 
+	getValueFor := func(key string) string {
+		return render(fmt.Sprintf("<%%= p('%s') %%>", key))
+	}
+
+	Context("when a template references instance spec values", func() {
+		BeforeEach(func() {
+			instance = InstanceSpec{
+				Name:      "fake-instance-group",
+				Index:     0,
+				ID:        "0",
+				AZ:        "unknown",
+				Bootstrap: true,
+			}
+		})
+
+		It("renders them", func() {
+			contents := render("<%= spec.name %>/<%= spec.index %> <%= spec.id %> <%= spec.az %> <%= spec.bootstrap %>")
+			Expect(contents).To(Equal("fake-instance-group/0 0 unknown true"))
+		})
+	})
+
 	Context("when a deployment and instance group set a property", func() {
 		BeforeEach(func() {
 			deploymentProperties = biproperty.Map{
@@ -204,6 +255,53 @@ var _ = Describe("JobEvaluationContext", func() {
 		})
 	})
 
+	Context("when the release's default value is a hash", func() {
+		BeforeEach(func() {
+			releaseJob.Properties["property3"] = boshreljob.PropertyDefinition{
+				Default: map[string]interface{}{
+					"user": "default-user",
+					"tls": map[string]interface{}{
+						"enabled": false,
+						"port":    4222,
+					},
+				},
+			}
+		})
+
+		It("uses the default value when the property is not set", func() {
+			Expect(render("<%= p('property3.user') %> <%= p('property3.tls.port') %>")).To(Equal("default-user 4222"))
+		})
+
+		Context("when the manifest sets part of the property", func() {
+			BeforeEach(func() {
+				jobProperties = &biproperty.Map{
+					"property3": biproperty.Map{
+						"user": "job-user",
+						"tls": biproperty.Map{
+							"enabled": true,
+						},
+					},
+				}
+			})
+
+			It("replaces the whole default value with the manifest value", func() {
+				Expect(render("<%= p('property3.user') %> <%= p('property3.tls.enabled') %> <%= p('property3.tls.port', 'unset') %>")).To(Equal("job-user true unset"))
+			})
+		})
+
+		Context("when the manifest sets the property to a non-hash value", func() {
+			BeforeEach(func() {
+				jobProperties = &biproperty.Map{
+					"property3": "job-value",
+				}
+			})
+
+			It("uses the manifest value", func() {
+				Expect(getValueFor("property3")).To(Equal("job-value"))
+			})
+		})
+	})
+
 	Context("when a job sets a property", func() {
 		BeforeEach(func() {
 			jobProperties = &biproperty.Map{
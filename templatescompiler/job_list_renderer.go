@@ -15,6 +15,7 @@ type JobListRenderer interface {
 		globalProperties biproperty.Map,
 		deploymentName string,
 		address string,
+		instance InstanceSpec,
 	) (RenderedJobList, error)
 }
 
@@ -42,13 +43,14 @@ func (r *jobListRenderer) Render(
 	globalProperties biproperty.Map,
 	deploymentName string,
 	address string,
+	instance InstanceSpec,
 ) (RenderedJobList, error) {
 	r.logger.Debug(r.logTag, "Rendering job list: deploymentName='%s' jobProperties=%#v globalProperties=%#v", deploymentName, jobProperties, globalProperties)
 	renderedJobList := NewRenderedJobList()
 
 	// render all the jobs' templates
 	for _, releaseJob := range releaseJobs {
-		renderedJob, err := r.jobRenderer.Render(releaseJob, releaseJobProperties[releaseJob.Name()], jobProperties, globalProperties, deploymentName, address)
+		renderedJob, err := r.jobRenderer.Render(releaseJob, releaseJobProperties[releaseJob.Name()], jobProperties, globalProperties, deploymentName, address, instance)
 		if err != nil {
 			defer renderedJobList.DeleteSilently()
 			return renderedJobList, bosherr.WrapErrorf(err, "Rendering templates for job '%s/%s'", releaseJob.Name(), releaseJob.Fingerprint())
@@ -11,18 +11,34 @@ type ColorUI struct {
 	okFunc   func(string, ...interface{}) string
 	errFunc  func(string, ...interface{}) string
 	boldFunc func(string, ...interface{}) string
+
+	colorOut bool
+	colorErr bool
 }
 
 func NewColorUI(parent UI) UI {
+	return newColorUI(parent, true, true)
+}
+
+// newColorUI only colors tables and error blocks when colorOut is set and error lines when colorErr is set
+func newColorUI(parent UI, colorOut, colorErr bool) UI {
 	return &ColorUI{
 		parent:   parent,
 		okFunc:   color.New(color.FgGreen).SprintfFunc(),
 		errFunc:  color.New(color.FgRed).SprintfFunc(),
 		boldFunc: color.New(color.Bold).SprintfFunc(),
+
+		colorOut: colorOut,
+		colorErr: colorErr,
 	}
 }
 
 func (ui *ColorUI) ErrorLinef(pattern string, args ...interface{}) {
+	if !ui.colorErr {
+		ui.parent.ErrorLinef(pattern, args...)
+		return
+	}
+
 	ui.parent.ErrorLinef("%s", ui.errFunc(pattern, args...))
 }
 
@@ -42,11 +58,22 @@ func (ui *ColorUI) PrintBlock(block []byte) {
 	ui.parent.PrintBlock(block)
 }
 
+// PrintErrorBlock is colored like the output since error blocks are written to stdout
 func (ui *ColorUI) PrintErrorBlock(block string) {
+	if !ui.colorOut {
+		ui.parent.PrintErrorBlock(block)
+		return
+	}
+
 	ui.parent.PrintErrorBlock(ui.errFunc("%s", block))
 }
 
 func (ui *ColorUI) PrintTable(table Table) {
+	if !ui.colorOut {
+		ui.parent.PrintTable(table)
+		return
+	}
+
 	table.HeaderFormatFunc = ui.boldFunc
 
 	for k, s := range table.Sections {
@@ -1,36 +1,41 @@
 package ui
 
 import (
+	"code.cloudfoundry.org/clock"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 
 	. "github.com/cloudfoundry/bosh-cli/ui/table"
 )
 
 type ConfUI struct {
-	parent      UI
-	isTTY       bool
-	logger      boshlog.Logger
-	showColumns []Header
+	parent       UI
+	isTTY        bool
+	isErrTTY     bool
+	colorEnabled bool
+	jsonEnabled  bool
+	logger       boshlog.Logger
+	showColumns  []Header
 }
 
 func NewConfUI(logger boshlog.Logger) *ConfUI {
-	var ui UI
-
-	writerUI := NewConsoleUI(logger)
-	ui = NewPaddingUI(writerUI)
+	return NewWriterConfUI(NewConsoleUI(logger), logger)
+}
 
+func NewWriterConfUI(writerUI *WriterUI, logger boshlog.Logger) *ConfUI {
 	return &ConfUI{
-		parent: ui,
-		isTTY:  writerUI.IsTTY(),
-		logger: logger,
+		parent:   NewPaddingUI(writerUI),
+		isTTY:    writerUI.IsTTY(),
+		isErrTTY: writerUI.IsErrTTY(),
+		logger:   logger,
 	}
 }
 
 func NewWrappingConfUI(parent UI, logger boshlog.Logger) *ConfUI {
 	return &ConfUI{
-		parent: parent,
-		isTTY:  true,
-		logger: logger,
+		parent:   parent,
+		isTTY:    true,
+		isErrTTY: true,
+		logger:   logger,
 	}
 }
 
@@ -40,12 +45,31 @@ func (ui *ConfUI) EnableTTY(force bool) {
 	}
 }
 
+// EnableColor colors output and errors that are written to a terminal;
+// output redirected to files or pipes is left without escape sequences
 func (ui *ConfUI) EnableColor() {
-	ui.parent = NewColorUI(ui.parent)
+	if ui.isTTY || ui.isErrTTY {
+		ui.parent = newColorUI(ui.parent, ui.isTTY, ui.isErrTTY)
+		ui.colorEnabled = true
+	}
 }
 
 func (ui *ConfUI) EnableJSON() {
 	ui.parent = NewJSONUI(ui.parent, ui.logger)
+	ui.jsonEnabled = true
+}
+
+// ProgressReporter draws progress bars on terminals, prints progress on separate lines
+// when output is redirected or colors are disabled, and shows no progress in JSON output
+func (ui *ConfUI) ProgressReporter(timeService clock.Clock) ProgressReporter {
+	switch {
+	case ui.jsonEnabled:
+		return NewNoopProgressReporter()
+	case ui.isTTY && ui.colorEnabled:
+		return NewBarProgressReporter(ui, timeService)
+	default:
+		return NewTextProgressReporter(ui, timeService, progressLineInterval)
+	}
 }
 
 func (ui *ConfUI) ShowColumns(columns []Header) {
@@ -111,6 +135,12 @@ func (ui *ConfUI) IsInteractive() bool {
 	return ui.parent.IsInteractive()
 }
 
+func (ui *ConfUI) RecordFailure() {
+	if recorder, ok := ui.parent.(FailureRecorder); ok {
+		recorder.RecordFailure()
+	}
+}
+
 func (ui *ConfUI) Flush() {
 	ui.parent.Flush()
 }
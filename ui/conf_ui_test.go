@@ -0,0 +1,99 @@
+package ui_test
+
+import (
+	"bytes"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/fatih/color"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/ui"
+	. "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+var _ = Describe("ConfUI", func() {
+	var (
+		uiOut, uiErr *bytes.Buffer
+		logger       boshlog.Logger
+		table        Table
+		prevNoColor  bool
+	)
+
+	BeforeEach(func() {
+		uiOut = bytes.NewBufferString("")
+		uiErr = bytes.NewBufferString("")
+		logger = boshlog.NewLogger(boshlog.LevelNone)
+
+		table = Table{
+			Header: []Header{NewHeader("Header1")},
+			Rows: [][]Value{
+				{NewValueFmt(NewValueString("failing"), true)},
+				{NewValueFmt(NewValueString("passing"), false)},
+			},
+		}
+
+		// Behave as if stdout of the process was a terminal
+		prevNoColor = color.NoColor
+		color.NoColor = false
+	})
+
+	AfterEach(func() {
+		color.NoColor = prevNoColor
+	})
+
+	Describe("EnableColor", func() {
+		It("does not write escape sequences when writing to non-terminals", func() {
+			ui := NewWriterConfUI(NewWriterUI(uiOut, uiErr, logger), logger)
+			ui.EnableColor()
+
+			ui.PrintTable(table)
+			ui.ErrorLinef("fake-error")
+			ui.PrintErrorBlock("fake-error-block")
+
+			Expect(uiOut.String()).To(ContainSubstring("failing"))
+			Expect(uiOut.String()).ToNot(ContainSubstring("\x1b["))
+			Expect(uiErr.String()).To(ContainSubstring("fake-error"))
+			Expect(uiErr.String()).ToNot(ContainSubstring("\x1b["))
+		})
+
+		It("colors tables and errors of wrapped UIs", func() {
+			ui := NewWrappingConfUI(NewWriterUI(uiOut, uiErr, logger), logger)
+			ui.EnableColor()
+
+			ui.PrintTable(table)
+			ui.ErrorLinef("fake-error")
+
+			Expect(uiOut.String()).To(ContainSubstring("\x1b[1mHeader1"))
+			Expect(uiOut.String()).To(ContainSubstring("\x1b[31mfailing"))
+			Expect(uiOut.String()).To(ContainSubstring("\x1b[32mpassing"))
+			Expect(uiErr.String()).To(Equal("\x1b[31mfake-error\x1b[0m\n"))
+		})
+	})
+
+	Describe("EnableJSON", func() {
+		It("writes the output document to stdout and the error document to stderr once the command failed", func() {
+			ui := NewWriterConfUI(NewWriterUI(uiOut, uiErr, logger), logger)
+			ui.EnableJSON()
+
+			ui.PrintLinef("fake-line")
+			ui.ErrorLinef("fake-error")
+			ui.RecordFailure()
+			ui.Flush()
+
+			Expect(uiOut.String()).To(ContainSubstring(`"fake-line"`))
+			Expect(uiOut.String()).ToNot(ContainSubstring(`{"error"`))
+			Expect(uiErr.String()).To(Equal("{\"error\":\"fake-error\"}\n"))
+		})
+
+		It("does not write an error document when the command succeeded", func() {
+			ui := NewWriterConfUI(NewWriterUI(uiOut, uiErr, logger), logger)
+			ui.EnableJSON()
+
+			ui.ErrorLinef("fake-error")
+			ui.Flush()
+
+			Expect(uiErr.String()).To(BeEmpty())
+		})
+	})
+})
@@ -27,3 +27,9 @@ type UI interface {
 
 	Flush()
 }
+
+// FailureRecorder is implemented by UIs that print a summary of the
+// command's error lines when they are flushed after the command failed
+type FailureRecorder interface {
+	RecordFailure()
+}
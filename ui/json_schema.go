@@ -0,0 +1,106 @@
+package ui
+
+import (
+	. "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+// JSONSchemaVersion is included in every document printed by the JSON UI.
+// It must be increased whenever a field is removed, renamed or changes meaning;
+// adding fields or tables does not require a new version.
+const JSONSchemaVersion = "1"
+
+// TableSchema describes a table that a command prints
+type TableSchema struct {
+	Content string
+	Columns []Header
+}
+
+// JSONSchema returns a JSON schema (draft-07) describing the document printed
+// by the JSON UI for a command. Tables that are not described are still
+// allowed, so that commands without a table schema validate as well.
+func JSONSchema(command string, tables []TableSchema) map[string]interface{} {
+	tableSchemas := []interface{}{}
+
+	for _, table := range tables {
+		tableSchemas = append(tableSchemas, table.jsonSchema())
+	}
+
+	tableSchemas = append(tableSchemas, TableSchema{}.jsonSchema())
+
+	return map[string]interface{}{
+		"$schema":  "http://json-schema.org/draft-07/schema#",
+		"title":    "bosh " + command + " --json",
+		"type":     "object",
+		"required": []string{"schemaVersion"},
+		"properties": map[string]interface{}{
+			"schemaVersion": map[string]interface{}{"const": JSONSchemaVersion},
+			"Tables": map[string]interface{}{
+				"type":  []string{"array", "null"},
+				"items": map[string]interface{}{"anyOf": tableSchemas},
+			},
+			"Blocks": stringsSchema(),
+			"Lines":  stringsSchema(),
+		},
+	}
+}
+
+// jsonSchema describes a table with the given columns, or any table if there are none
+func (s TableSchema) jsonSchema() map[string]interface{} {
+	headerSchema := map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": map[string]interface{}{"type": "string"},
+	}
+
+	rowSchema := map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": map[string]interface{}{"type": "string"},
+	}
+
+	contentSchema := map[string]interface{}{"type": "string"}
+
+	if len(s.Columns) > 0 {
+		headerProperties := map[string]interface{}{}
+		rowProperties := map[string]interface{}{}
+
+		// Columns may be hidden with --column, so none of them are required
+		for _, column := range s.Columns {
+			headerProperties[column.Key] = map[string]interface{}{"const": column.Title}
+			rowProperties[column.Key] = map[string]interface{}{"type": "string"}
+		}
+
+		headerSchema = map[string]interface{}{
+			"type":                 "object",
+			"properties":           headerProperties,
+			"additionalProperties": false,
+		}
+
+		rowSchema = map[string]interface{}{
+			"type":                 "object",
+			"properties":           rowProperties,
+			"additionalProperties": false,
+		}
+
+		contentSchema = map[string]interface{}{"const": s.Content}
+	}
+
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []string{"Content", "Header", "Rows"},
+		"properties": map[string]interface{}{
+			"Content": contentSchema,
+			"Header":  headerSchema,
+			"Rows": map[string]interface{}{
+				"type":  "array",
+				"items": rowSchema,
+			},
+			"Notes": stringsSchema(),
+		},
+	}
+}
+
+func stringsSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":  []string{"array", "null"},
+		"items": map[string]interface{}{"type": "string"},
+	}
+}
@@ -0,0 +1,56 @@
+package ui_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/ui"
+	. "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+var _ = Describe("JSONSchema", func() {
+	properties := func(schema map[string]interface{}) map[string]interface{} {
+		return schema["properties"].(map[string]interface{})
+	}
+
+	tableSchemas := func(schema map[string]interface{}) []interface{} {
+		tables := properties(schema)["Tables"].(map[string]interface{})
+		return tables["items"].(map[string]interface{})["anyOf"].([]interface{})
+	}
+
+	It("requires the current schema version", func() {
+		schema := JSONSchema("fake-command", nil)
+
+		Expect(schema["title"]).To(Equal("bosh fake-command --json"))
+		Expect(schema["required"]).To(Equal([]string{"schemaVersion"}))
+		Expect(properties(schema)["schemaVersion"]).To(Equal(map[string]interface{}{"const": JSONSchemaVersion}))
+	})
+
+	It("describes the columns of each table by their keys", func() {
+		schema := JSONSchema("fake-command", []TableSchema{
+			{Content: "things", Columns: []Header{NewHeader("Name"), NewHeader("Created At")}},
+		})
+
+		tables := tableSchemas(schema)
+		Expect(tables).To(HaveLen(2))
+
+		table := properties(tables[0].(map[string]interface{}))
+		Expect(table["Content"]).To(Equal(map[string]interface{}{"const": "things"}))
+		Expect(table["Header"]).To(Equal(map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"name":       map[string]interface{}{"const": "Name"},
+				"created_at": map[string]interface{}{"const": "Created At"},
+			},
+			"additionalProperties": false,
+		}))
+	})
+
+	It("allows tables that are not described", func() {
+		tables := tableSchemas(JSONSchema("fake-command", nil))
+		Expect(tables).To(HaveLen(1))
+
+		table := properties(tables[0].(map[string]interface{}))
+		Expect(table["Content"]).To(Equal(map[string]interface{}{"type": "string"}))
+	})
+})
@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+type jsonStageEventWriter struct {
+	writer io.Writer
+	lock   sync.Mutex
+
+	logTag string
+	logger boshlog.Logger
+}
+
+// jsonStageEvent is written for every stage as it starts and ends.
+// Stage holds the names of the enclosing stages and Task the name of the stage itself;
+// a top level stage has no Task.
+type jsonStageEvent struct {
+	Time  time.Time `json:"time"`
+	Stage string    `json:"stage"`
+	Task  string    `json:"task,omitempty"`
+	State string    `json:"state"`
+
+	// Duration is given in seconds once the stage ended
+	Duration *float64 `json:"duration,omitempty"`
+	Message  string   `json:"message,omitempty"`
+}
+
+// NewJSONStageEventWriter returns a StageEventRecorder that writes each stage event
+// to writer right away, as one line of JSON, so that progress can be followed by machines
+func NewJSONStageEventWriter(writer io.Writer, logger boshlog.Logger) StageEventRecorder {
+	return &jsonStageEventWriter{writer: writer, logTag: "jsonStageEventWriter", logger: logger}
+}
+
+func (w *jsonStageEventWriter) RecordStageEvent(event StageEvent) {
+	if len(event.Stages) == 0 {
+		return
+	}
+
+	jsonEvent := jsonStageEvent{
+		Time:    event.Time,
+		Stage:   event.Stages[0],
+		State:   event.Type,
+		Message: event.Message,
+	}
+
+	if len(event.Stages) > 1 {
+		jsonEvent.Stage = strings.Join(event.Stages[:len(event.Stages)-1], " / ")
+		jsonEvent.Task = event.Stages[len(event.Stages)-1]
+	}
+
+	if event.Type != StageStarted {
+		seconds := event.Duration.Seconds()
+		jsonEvent.Duration = &seconds
+	}
+
+	bytes, err := json.Marshal(jsonEvent)
+	if err != nil {
+		w.logger.Error(w.logTag, "Failed to marshal stage event: %s", err.Error())
+		return
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	_, err = w.writer.Write(append(bytes, '\n'))
+	if err != nil {
+		w.logger.Error(w.logTag, "Failed to write stage event: %s", err.Error())
+	}
+}
+
+type multiStageEventRecorder []StageEventRecorder
+
+// NewMultiStageEventRecorder returns a StageEventRecorder telling every one of recorders about each event
+func NewMultiStageEventRecorder(recorders ...StageEventRecorder) StageEventRecorder {
+	return multiStageEventRecorder(recorders)
+}
+
+func (r multiStageEventRecorder) RecordStageEvent(event StageEvent) {
+	for _, recorder := range r {
+		recorder.RecordStageEvent(event)
+	}
+}
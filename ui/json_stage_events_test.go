@@ -0,0 +1,77 @@
+package ui_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/ui"
+)
+
+var _ = Describe("JSONStageEventWriter", func() {
+	var (
+		fakeTimeService *fakeclock.FakeClock
+		eventsOut       *bytes.Buffer
+		stage           Stage
+	)
+
+	BeforeEach(func() {
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		fakeTimeService = fakeclock.NewFakeClock(time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC))
+		eventsOut = bytes.NewBufferString("")
+
+		ui := NewWriterUI(bytes.NewBufferString(""), bytes.NewBufferString(""), logger)
+		stage = NewRecordingStage(ui, fakeTimeService, logger, NewJSONStageEventWriter(eventsOut, logger))
+	})
+
+	It("writes a line of JSON as each stage and task starts and ends, with durations once they ended", func() {
+		err := stage.PerformComplex("installing CPI", func(stage Stage) error {
+			err := stage.Perform("Compiling package 'ruby'", func() error {
+				fakeTimeService.Increment(1500 * time.Millisecond)
+				return nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			return stage.Perform("Rendering job templates", func() error {
+				fakeTimeService.Increment(2 * time.Second)
+				return errors.New("fake-render-error")
+			})
+		})
+		Expect(err).To(HaveOccurred())
+
+		Expect(strings.Split(strings.TrimSuffix(eventsOut.String(), "\n"), "\n")).To(Equal([]string{
+			`{"time":"2017-01-02T03:04:05Z","stage":"installing CPI","state":"started"}`,
+			`{"time":"2017-01-02T03:04:05Z","stage":"installing CPI","task":"Compiling package 'ruby'","state":"started"}`,
+			`{"time":"2017-01-02T03:04:06.5Z","stage":"installing CPI","task":"Compiling package 'ruby'","state":"finished","duration":1.5}`,
+			`{"time":"2017-01-02T03:04:06.5Z","stage":"installing CPI","task":"Rendering job templates","state":"started"}`,
+			`{"time":"2017-01-02T03:04:08.5Z","stage":"installing CPI","task":"Rendering job templates","state":"failed","duration":2,"message":"fake-render-error"}`,
+			`{"time":"2017-01-02T03:04:08.5Z","stage":"installing CPI","state":"failed","duration":3.5,"message":"fake-render-error"}`,
+		}))
+	})
+
+	It("reports a zero duration for stages that ended right away", func() {
+		err := stage.Perform("Validating", func() error { return nil })
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(eventsOut.String()).To(ContainSubstring(`"state":"finished","duration":0}`))
+	})
+})
+
+var _ = Describe("MultiStageEventRecorder", func() {
+	It("tells every recorder about each event", func() {
+		recorder1 := &fakeStageEventRecorder{}
+		recorder2 := &fakeStageEventRecorder{}
+
+		event := StageEvent{Type: StageStarted, Stages: []string{"fake-stage"}}
+		NewMultiStageEventRecorder(recorder1, recorder2).RecordStageEvent(event)
+
+		Expect(recorder1.events).To(Equal([]StageEvent{event}))
+		Expect(recorder2.events).To(Equal([]StageEvent{event}))
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strings"
 
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 
@@ -15,16 +16,27 @@ type jsonUI struct {
 	parent UI
 	uiResp uiResp
 
+	// errorLines are also printed to stderr as an error document once the
+	// command failed, so that parsers can tell a failure apart without reading Lines
+	errorLines []string
+	failed     bool
+
 	logTag string
 	logger boshlog.Logger
 }
 
 type uiResp struct {
+	SchemaVersion string `json:"schemaVersion"`
+
 	Tables []tableResp
 	Blocks []string
 	Lines  []string
 }
 
+type errorResp struct {
+	Error string `json:"error"`
+}
+
 type tableResp struct {
 	Content string
 	Header  map[string]string
@@ -38,6 +50,11 @@ func NewJSONUI(parent UI, logger boshlog.Logger) UI {
 
 func (ui *jsonUI) ErrorLinef(pattern string, args ...interface{}) {
 	ui.addLine(pattern, args)
+	ui.errorLines = append(ui.errorLines, fmt.Sprintf(pattern, args...))
+}
+
+func (ui *jsonUI) RecordFailure() {
+	ui.failed = true
 }
 
 func (ui *jsonUI) PrintLinef(pattern string, args ...interface{}) {
@@ -124,6 +141,8 @@ func (ui *jsonUI) Flush() {
 	defer ui.parent.Flush()
 
 	if !reflect.DeepEqual(ui.uiResp, uiResp{}) {
+		ui.uiResp.SchemaVersion = JSONSchemaVersion
+
 		bytes, err := json.MarshalIndent(ui.uiResp, "", "    ")
 		if err != nil {
 			ui.logger.Error(ui.logTag, "Failed to marshal UI response")
@@ -132,6 +151,16 @@ func (ui *jsonUI) Flush() {
 
 		ui.parent.PrintBlock(bytes)
 	}
+
+	if ui.failed && len(ui.errorLines) > 0 {
+		bytes, err := json.Marshal(errorResp{Error: strings.Join(ui.errorLines, "\n")})
+		if err != nil {
+			ui.logger.Error(ui.logTag, "Failed to marshal UI error response")
+			return
+		}
+
+		ui.parent.ErrorLinef("%s", bytes)
+	}
 }
 
 func (ui *jsonUI) stringRows(header []Header, rows [][]Value) []map[string]string {
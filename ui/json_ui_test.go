@@ -32,6 +32,8 @@ var _ = Describe("JSONUI", func() {
 	}
 
 	type uiResp struct {
+		SchemaVersion string `json:"schemaVersion"`
+
 		Tables []tableResp
 		Blocks []string
 		Lines  []string
@@ -55,9 +57,28 @@ var _ = Describe("JSONUI", func() {
 			ui.ErrorLinef("fake-line1")
 			ui.ErrorLinef("fake-line2")
 			Expect(finalOutput()).To(Equal(uiResp{
-				Lines: []string{"fake-line1", "fake-line2"},
+				SchemaVersion: "1",
+				Lines:         []string{"fake-line1", "fake-line2"},
 			}))
 		})
+
+		It("outputs an error document as an error line when the command failed", func() {
+			ui.ErrorLinef("fake-line1")
+			ui.ErrorLinef("fake-%s", "line2")
+			ui.(FailureRecorder).RecordFailure()
+			ui.Flush()
+
+			Expect(parentUI.Blocks).To(HaveLen(1))
+			Expect(parentUI.Errors).To(Equal([]string{`{"error":"fake-line1\nfake-line2"}`}))
+		})
+
+		It("does not output an error document when the command did not fail", func() {
+			ui.ErrorLinef("fake-line1")
+			ui.Flush()
+
+			Expect(parentUI.Blocks).To(HaveLen(1))
+			Expect(parentUI.Errors).To(BeEmpty())
+		})
 	})
 
 	Describe("PrintLinef", func() {
@@ -65,7 +86,8 @@ var _ = Describe("JSONUI", func() {
 			ui.PrintLinef("fake-line1")
 			ui.PrintLinef("fake-line2")
 			Expect(finalOutput()).To(Equal(uiResp{
-				Lines: []string{"fake-line1", "fake-line2"},
+				SchemaVersion: "1",
+				Lines:         []string{"fake-line1", "fake-line2"},
 			}))
 		})
 	})
@@ -75,7 +97,8 @@ var _ = Describe("JSONUI", func() {
 			ui.BeginLinef("fake-line1")
 			ui.BeginLinef("fake-line2")
 			Expect(finalOutput()).To(Equal(uiResp{
-				Lines: []string{"fake-line1", "fake-line2"},
+				SchemaVersion: "1",
+				Lines:         []string{"fake-line1", "fake-line2"},
 			}))
 		})
 	})
@@ -85,7 +108,8 @@ var _ = Describe("JSONUI", func() {
 			ui.EndLinef("fake-line1")
 			ui.EndLinef("fake-line2")
 			Expect(finalOutput()).To(Equal(uiResp{
-				Lines: []string{"fake-line1", "fake-line2"},
+				SchemaVersion: "1",
+				Lines:         []string{"fake-line1", "fake-line2"},
 			}))
 		})
 	})
@@ -95,7 +119,8 @@ var _ = Describe("JSONUI", func() {
 			ui.PrintBlock([]byte("fake-block1"))
 			ui.PrintBlock([]byte("fake-block2"))
 			Expect(finalOutput()).To(Equal(uiResp{
-				Blocks: []string{"fake-block1", "fake-block2"},
+				SchemaVersion: "1",
+				Blocks:        []string{"fake-block1", "fake-block2"},
 			}))
 		})
 	})
@@ -105,7 +130,8 @@ var _ = Describe("JSONUI", func() {
 			ui.PrintErrorBlock("fake-block1")
 			ui.PrintErrorBlock("fake-block2")
 			Expect(finalOutput()).To(Equal(uiResp{
-				Blocks: []string{"fake-block1", "fake-block2"},
+				SchemaVersion: "1",
+				Blocks:        []string{"fake-block1", "fake-block2"},
 			}))
 		})
 	})
@@ -132,6 +158,7 @@ var _ = Describe("JSONUI", func() {
 			ui.PrintTable(table2)
 
 			Expect(finalOutput()).To(Equal(uiResp{
+				SchemaVersion: "1",
 				Tables: []tableResp{
 					{
 						Content: "things",
@@ -167,6 +194,7 @@ var _ = Describe("JSONUI", func() {
 			ui.PrintTable(table)
 
 			Expect(finalOutput()).To(Equal(uiResp{
+				SchemaVersion: "1",
 				Tables: []tableResp{
 					{
 						Content: "things",
@@ -205,6 +233,7 @@ var _ = Describe("JSONUI", func() {
 			ui.PrintTable(table2)
 
 			Expect(finalOutput()).To(Equal(uiResp{
+				SchemaVersion: "1",
 				Tables: []tableResp{
 					{
 						Content: "things",
@@ -279,6 +308,7 @@ var _ = Describe("JSONUI", func() {
 			ui.PrintTable(table)
 
 			Expect(finalOutput()).To(Equal(uiResp{
+				SchemaVersion: "1",
 				Tables: []tableResp{
 					{
 						Content: "things",
@@ -332,10 +362,17 @@ var _ = Describe("JSONUI", func() {
 			Expect(parentUI.Said).To(BeEmpty())
 		})
 
+		It("does not output an error document when no errors were recorded", func() {
+			ui.PrintLinef("fake-line1")
+			ui.Flush()
+			Expect(parentUI.Blocks).To(HaveLen(1))
+		})
+
 		It("outputs everything when something was recorded", func() {
 			ui.PrintLinef("fake-line1")
 			ui.Flush()
 			Expect(parentUI.Blocks[0]).To(Equal(`{
+    "schemaVersion": "1",
     "Tables": null,
     "Blocks": null,
     "Lines": [
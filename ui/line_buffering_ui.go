@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	. "github.com/cloudfoundry/bosh-cli/ui/table"
+)
+
+// lineBufferingUI holds back text until its line is complete and then prints the
+// whole line at once, so that lines of stages performed concurrently do not interleave
+type lineBufferingUI struct {
+	parent UI
+	lock   *sync.Mutex
+
+	pending string
+}
+
+func newLineBufferingUI(parent UI, lock *sync.Mutex) *lineBufferingUI {
+	return &lineBufferingUI{parent: parent, lock: lock}
+}
+
+func (ui *lineBufferingUI) ErrorLinef(pattern string, args ...interface{}) {
+	ui.lock.Lock()
+	defer ui.lock.Unlock()
+	ui.parent.ErrorLinef(pattern, args...)
+}
+
+func (ui *lineBufferingUI) PrintLinef(pattern string, args ...interface{}) {
+	ui.lock.Lock()
+	defer ui.lock.Unlock()
+	ui.parent.PrintLinef(pattern, args...)
+}
+
+func (ui *lineBufferingUI) BeginLinef(pattern string, args ...interface{}) {
+	ui.pending += fmt.Sprintf(pattern, args...)
+
+	if strings.HasSuffix(ui.pending, "\n") {
+		ui.lock.Lock()
+		defer ui.lock.Unlock()
+		ui.parent.BeginLinef("%s", ui.pending)
+		ui.pending = ""
+	}
+}
+
+func (ui *lineBufferingUI) EndLinef(pattern string, args ...interface{}) {
+	ui.lock.Lock()
+	defer ui.lock.Unlock()
+	ui.parent.EndLinef("%s", ui.pending+fmt.Sprintf(pattern, args...))
+	ui.pending = ""
+}
+
+func (ui *lineBufferingUI) PrintBlock(block []byte) {
+	ui.lock.Lock()
+	defer ui.lock.Unlock()
+	ui.parent.PrintBlock(block)
+}
+
+func (ui *lineBufferingUI) PrintErrorBlock(block string) {
+	ui.lock.Lock()
+	defer ui.lock.Unlock()
+	ui.parent.PrintErrorBlock(block)
+}
+
+func (ui *lineBufferingUI) PrintTable(table Table) {
+	ui.lock.Lock()
+	defer ui.lock.Unlock()
+	ui.parent.PrintTable(table)
+}
+
+func (ui *lineBufferingUI) AskForText(label string) (string, error) {
+	ui.lock.Lock()
+	defer ui.lock.Unlock()
+	return ui.parent.AskForText(label)
+}
+
+func (ui *lineBufferingUI) AskForChoice(label string, options []string) (int, error) {
+	ui.lock.Lock()
+	defer ui.lock.Unlock()
+	return ui.parent.AskForChoice(label, options)
+}
+
+func (ui *lineBufferingUI) AskForPassword(label string) (string, error) {
+	ui.lock.Lock()
+	defer ui.lock.Unlock()
+	return ui.parent.AskForPassword(label)
+}
+
+func (ui *lineBufferingUI) AskForConfirmation() error {
+	ui.lock.Lock()
+	defer ui.lock.Unlock()
+	return ui.parent.AskForConfirmation()
+}
+
+func (ui *lineBufferingUI) IsInteractive() bool {
+	return ui.parent.IsInteractive()
+}
+
+func (ui *lineBufferingUI) Flush() {
+	ui.lock.Lock()
+	defer ui.lock.Unlock()
+	ui.parent.Flush()
+}
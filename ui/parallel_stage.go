@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"sync"
+)
+
+// PerformParallel performs steps with up to workers of them running at a time.
+// Each step gets a stage of its own that prints its lines only once they are complete,
+// e.g. 'Downloading release... Finished', so that concurrent steps do not interleave.
+// No new steps are started once one failed; the first error in step order is returned.
+// With a single worker, or a stage that cannot be shared, steps are performed in order on s.
+func PerformParallel(s Stage, workers int, steps []func(Stage) error) error {
+	parent, ok := s.(*stage)
+	if !ok || workers <= 1 || len(steps) <= 1 {
+		for _, step := range steps {
+			err := step(s)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if !parent.simpleMode {
+		parent.ui.BeginLinef("\n")
+		parent.simpleMode = true
+	}
+
+	var (
+		outputLock sync.Mutex
+		errsLock   sync.Mutex
+		wg         sync.WaitGroup
+	)
+
+	errs := make([]error, len(steps))
+	stages := make([]*stage, len(steps))
+	slots := make(chan struct{}, workers)
+
+	failed := func() bool {
+		errsLock.Lock()
+		defer errsLock.Unlock()
+
+		for _, err := range errs {
+			if err != nil {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i, step := range steps {
+		slots <- struct{}{}
+
+		if failed() {
+			<-slots
+			break
+		}
+
+		stages[i] = parent.newConcurrentStage(&outputLock)
+
+		wg.Add(1)
+		go func(i int, step func(Stage) error) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			err := step(stages[i])
+
+			errsLock.Lock()
+			errs[i] = err
+			errsLock.Unlock()
+		}(i, step)
+	}
+
+	wg.Wait()
+
+	for _, stepStage := range stages {
+		if stepStage != nil && !stepStage.simpleMode {
+			parent.simpleMode = false
+		}
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newConcurrentStage returns a stage at the same level as s whose lines are printed
+// whole while holding lock, to be used next to other concurrent stages
+func (s *stage) newConcurrentStage(lock *sync.Mutex) *stage {
+	return &stage{
+		ui:          newLineBufferingUI(s.ui, lock),
+		timeService: s.timeService,
+
+		logTag: s.logTag,
+		logger: s.logger,
+
+		recorder: s.recorder,
+		names:    s.names,
+
+		simpleMode: true,
+	}
+}
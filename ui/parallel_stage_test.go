@@ -0,0 +1,129 @@
+package ui_test
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/ui"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+)
+
+var _ = Describe("PerformParallel", func() {
+	var (
+		uiOut           *bytes.Buffer
+		stage           Stage
+		fakeTimeService *fakeclock.FakeClock
+	)
+
+	BeforeEach(func() {
+		uiOut = bytes.NewBufferString("")
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		fakeTimeService = fakeclock.NewFakeClock(time.Now())
+		stage = NewStage(NewWriterUI(uiOut, bytes.NewBufferString(""), logger), fakeTimeService, logger)
+	})
+
+	It("prints whole lines of steps that run at the same time", func() {
+		firstStarted := make(chan struct{})
+		secondFinished := make(chan struct{})
+
+		err := PerformParallel(stage, 2, []func(Stage) error{
+			func(stepStage Stage) error {
+				return stepStage.Perform("First step", func() error {
+					close(firstStarted)
+					<-secondFinished
+					return nil
+				})
+			},
+			func(stepStage Stage) error {
+				<-firstStarted
+				err := stepStage.Perform("Second step", func() error { return nil })
+				close(secondFinished)
+				return err
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(uiOut.String()).To(Equal(
+			"Second step... Finished (00:00:00)\n" +
+				"First step... Finished (00:00:00)\n",
+		))
+	})
+
+	It("runs no more than the given number of steps at a time", func() {
+		var (
+			lock          sync.Mutex
+			running, peak int
+		)
+
+		steps := []func(Stage) error{}
+		for i := 0; i < 6; i++ {
+			steps = append(steps, func(Stage) error {
+				lock.Lock()
+				running++
+				if running > peak {
+					peak = running
+				}
+				lock.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				lock.Lock()
+				running--
+				lock.Unlock()
+				return nil
+			})
+		}
+
+		err := PerformParallel(stage, 2, steps)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(peak).To(Equal(2))
+	})
+
+	It("returns the error of the first failed step and starts no more steps", func() {
+		started := []int{}
+		var lock sync.Mutex
+
+		step := func(i int, err error) func(Stage) error {
+			return func(Stage) error {
+				lock.Lock()
+				started = append(started, i)
+				lock.Unlock()
+				return err
+			}
+		}
+
+		err := PerformParallel(stage, 1, []func(Stage) error{
+			step(0, nil), step(1, errors.New("fake-err")), step(2, nil),
+		})
+		Expect(err).To(MatchError("fake-err"))
+		Expect(started).To(Equal([]int{0, 1}))
+
+		started = []int{}
+		err = PerformParallel(stage, 2, []func(Stage) error{
+			step(0, errors.New("fake-err-0")), step(1, errors.New("fake-err-1")),
+		})
+		Expect(err).To(MatchError("fake-err-0"))
+		Expect(started).To(ConsistOf(0, 1))
+	})
+
+	It("performs steps in order on stages that cannot be shared", func() {
+		fakeStage := fakeui.NewFakeStage()
+
+		err := PerformParallel(fakeStage, 3, []func(Stage) error{
+			func(stepStage Stage) error { return stepStage.Perform("First step", func() error { return nil }) },
+			func(stepStage Stage) error { return stepStage.Perform("Second step", func() error { return nil }) },
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fakeStage.PerformCalls).To(HaveLen(2))
+		Expect(fakeStage.PerformCalls[0].Name).To(Equal("First step"))
+		Expect(fakeStage.PerformCalls[1].Name).To(Equal("Second step"))
+	})
+})
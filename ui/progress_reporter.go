@@ -0,0 +1,253 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"github.com/dustin/go-humanize"
+)
+
+// ProgressReporter shows how much of a transfer of known size is done
+// while it is read from reader or written to writer.
+// Progress is done once size bytes were transferred or reader returned EOF.
+type ProgressReporter interface {
+	ProgressReader(description string, size int64, reader io.Reader) io.Reader
+	ProgressWriter(description string, size int64, writer io.Writer) io.Writer
+}
+
+const (
+	progressBarInterval  = 250 * time.Millisecond
+	progressLineInterval = 10 * time.Second
+	progressBarWidth     = 20
+)
+
+type progress interface {
+	Add(n int)
+	Finish()
+}
+
+type progressFactory func(description string, size int64) progress
+
+type progressReporter struct {
+	newProgress progressFactory
+}
+
+// NewBarProgressReporter draws a bar behind the line that is currently being printed,
+// e.g. the name of a stage, and erases it once the transfer is done.
+// Transfers that finish within a fraction of a second are not shown.
+func NewBarProgressReporter(ui UI, timeService clock.Clock) ProgressReporter {
+	return progressReporter{
+		newProgress: func(_ string, size int64) progress {
+			return &barProgress{ui: ui, timeService: timeService, size: size, startTime: timeService.Now()}
+		},
+	}
+}
+
+// NewTextProgressReporter prints how much was transferred on a separate line every interval
+// for output that is not shown on a terminal, which cannot redraw a bar
+func NewTextProgressReporter(ui UI, timeService clock.Clock, interval time.Duration) ProgressReporter {
+	return progressReporter{
+		newProgress: func(description string, size int64) progress {
+			return &textProgress{
+				ui:          ui,
+				timeService: timeService,
+				interval:    interval,
+				description: description,
+				size:        size,
+				lastPrinted: timeService.Now(),
+			}
+		},
+	}
+}
+
+func NewNoopProgressReporter() ProgressReporter { return noopProgressReporter{} }
+
+func (r progressReporter) ProgressReader(description string, size int64, reader io.Reader) io.Reader {
+	if size <= 0 {
+		return reader
+	}
+
+	return progressReader{reader: reader, progress: r.newProgress(description, size)}
+}
+
+func (r progressReporter) ProgressWriter(description string, size int64, writer io.Writer) io.Writer {
+	if size <= 0 {
+		return writer
+	}
+
+	return progressWriter{writer: writer, progress: r.newProgress(description, size)}
+}
+
+type progressReader struct {
+	reader   io.Reader
+	progress progress
+}
+
+func (r progressReader) Read(bs []byte) (int, error) {
+	n, err := r.reader.Read(bs)
+	r.progress.Add(n)
+
+	if err == io.EOF {
+		r.progress.Finish()
+	}
+
+	return n, err
+}
+
+type progressWriter struct {
+	writer   io.Writer
+	progress progress
+}
+
+func (w progressWriter) Write(bs []byte) (int, error) {
+	n, err := w.writer.Write(bs)
+	w.progress.Add(n)
+	return n, err
+}
+
+type barProgress struct {
+	ui          UI
+	timeService clock.Clock
+
+	size        int64
+	transferred int64
+	startTime   time.Time
+	lastDrawn   time.Time
+
+	// drawnWidth is the number of characters to erase before drawing again
+	drawnWidth int
+	finished   bool
+}
+
+func (p *barProgress) Add(n int) {
+	if p.finished {
+		return
+	}
+
+	p.transferred += int64(n)
+
+	if p.transferred >= p.size {
+		p.Finish()
+		return
+	}
+
+	now := p.timeService.Now()
+
+	if now.Sub(p.startTime) < progressBarInterval || now.Sub(p.lastDrawn) < progressBarInterval {
+		return
+	}
+
+	p.lastDrawn = now
+	p.draw(p.frame(now))
+}
+
+func (p *barProgress) Finish() {
+	if p.finished {
+		return
+	}
+
+	p.finished = true
+
+	if p.drawnWidth > 0 {
+		p.draw("")
+	}
+}
+
+// frame is kept short so that it does not wrap behind long lines, which could not be erased
+func (p *barProgress) frame(now time.Time) string {
+	ratio := float64(p.transferred) / float64(p.size)
+	filled := int(ratio * progressBarWidth)
+
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+
+	frame := fmt.Sprintf(" [%s] %3d%%", bar, int(ratio*100))
+
+	elapsed := now.Sub(p.startTime).Seconds()
+	if elapsed > 0 {
+		frame += fmt.Sprintf(" %s/s", humanize.Bytes(uint64(float64(p.transferred)/elapsed)))
+	}
+
+	return frame
+}
+
+// draw replaces the previous frame by moving back over it,
+// so that the text before the bar is kept
+func (p *barProgress) draw(frame string) {
+	text := strings.Repeat("\b", p.drawnWidth) + frame
+
+	if len(frame) < p.drawnWidth {
+		padding := p.drawnWidth - len(frame)
+		text += strings.Repeat(" ", padding) + strings.Repeat("\b", padding)
+	}
+
+	p.ui.BeginLinef("%s", text)
+	p.drawnWidth = len(frame)
+}
+
+type textProgress struct {
+	ui          UI
+	timeService clock.Clock
+	interval    time.Duration
+
+	description string
+	size        int64
+	transferred int64
+	lastPrinted time.Time
+
+	printed  bool
+	finished bool
+}
+
+func (p *textProgress) Add(n int) {
+	if p.finished {
+		return
+	}
+
+	p.transferred += int64(n)
+
+	if p.transferred >= p.size {
+		p.Finish()
+		return
+	}
+
+	now := p.timeService.Now()
+
+	if now.Sub(p.lastPrinted) >= p.interval {
+		p.lastPrinted = now
+		p.print()
+	}
+}
+
+// Finish only prints when progress was printed before
+// so that short transfers do not add lines
+func (p *textProgress) Finish() {
+	if p.finished {
+		return
+	}
+
+	p.finished = true
+
+	if p.printed {
+		p.print()
+	}
+}
+
+func (p *textProgress) print() {
+	p.printed = true
+
+	p.ui.BeginLinef("\n%s: %s of %s (%d%%)", p.description,
+		humanize.Bytes(uint64(p.transferred)), humanize.Bytes(uint64(p.size)), p.transferred*100/p.size)
+}
+
+type noopProgressReporter struct{}
+
+func (noopProgressReporter) ProgressReader(_ string, _ int64, reader io.Reader) io.Reader {
+	return reader
+}
+
+func (noopProgressReporter) ProgressWriter(_ string, _ int64, writer io.Writer) io.Writer {
+	return writer
+}
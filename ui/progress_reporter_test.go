@@ -0,0 +1,167 @@
+package ui_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/ui"
+	fakeui "github.com/cloudfoundry/bosh-cli/ui/fakes"
+)
+
+var _ = Describe("ProgressReporter", func() {
+	var (
+		ui        *fakeui.FakeUI
+		fakeClock *fakeclock.FakeClock
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+	})
+
+	write := func(writer interface {
+		Write([]byte) (int, error)
+	}, n int) {
+		_, err := writer.Write(make([]byte, n))
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	Describe("BarProgressReporter", func() {
+		var reporter ProgressReporter
+
+		BeforeEach(func() {
+			reporter = NewBarProgressReporter(ui, fakeClock)
+		})
+
+		It("draws throttled frames over each other and erases the bar when done", func() {
+			writer := reporter.ProgressWriter("fake-desc", 100, ioutil.Discard)
+
+			write(writer, 10)
+			Expect(ui.Said).To(BeEmpty())
+
+			fakeClock.Increment(300 * time.Millisecond)
+			write(writer, 10)
+			Expect(ui.Said).To(HaveLen(1))
+			Expect(ui.Said[0]).To(HavePrefix(" [====                ]  20% "))
+			firstWidth := len(ui.Said[0])
+
+			fakeClock.Increment(100 * time.Millisecond)
+			write(writer, 10)
+			Expect(ui.Said).To(HaveLen(1))
+
+			fakeClock.Increment(200 * time.Millisecond)
+			write(writer, 10)
+			Expect(ui.Said).To(HaveLen(2))
+			Expect(ui.Said[1]).To(HavePrefix(strings.Repeat("\b", firstWidth) + " [========            ]  40% "))
+
+			write(writer, 60)
+			Expect(ui.Said).To(HaveLen(3))
+			Expect(strings.Trim(ui.Said[2], "\b ")).To(BeEmpty())
+		})
+
+		It("does not draw transfers that finish quickly", func() {
+			reader := reporter.ProgressReader("fake-desc", 100, bytes.NewReader(make([]byte, 100)))
+
+			content, err := ioutil.ReadAll(reader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(content).To(HaveLen(100))
+
+			Expect(ui.Said).To(BeEmpty())
+		})
+
+		It("does not track transfers of unknown size", func() {
+			Expect(reporter.ProgressWriter("fake-desc", 0, ioutil.Discard)).To(Equal(ioutil.Discard))
+		})
+	})
+
+	Describe("TextProgressReporter", func() {
+		var reporter ProgressReporter
+
+		BeforeEach(func() {
+			reporter = NewTextProgressReporter(ui, fakeClock, 10*time.Second)
+		})
+
+		It("prints progress every interval and once more when done", func() {
+			writer := reporter.ProgressWriter("fake-desc", 100, ioutil.Discard)
+
+			write(writer, 10)
+			Expect(ui.Said).To(BeEmpty())
+
+			fakeClock.Increment(10 * time.Second)
+			write(writer, 10)
+			Expect(ui.Said).To(Equal([]string{"\nfake-desc: 20 B of 100 B (20%)"}))
+
+			write(writer, 80)
+			Expect(ui.Said).To(Equal([]string{
+				"\nfake-desc: 20 B of 100 B (20%)",
+				"\nfake-desc: 100 B of 100 B (100%)",
+			}))
+		})
+
+		It("finishes when the reader is exhausted", func() {
+			reader := reporter.ProgressReader("fake-desc", 100, bytes.NewReader(make([]byte, 50)))
+
+			fakeClock.Increment(10 * time.Second)
+
+			_, err := ioutil.ReadAll(reader)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Said).To(Equal([]string{
+				"\nfake-desc: 50 B of 100 B (50%)",
+				"\nfake-desc: 50 B of 100 B (50%)",
+			}))
+		})
+
+		It("does not print transfers that finish within the interval", func() {
+			write(reporter.ProgressWriter("fake-desc", 100, ioutil.Discard), 100)
+			Expect(ui.Said).To(BeEmpty())
+		})
+	})
+
+	Describe("NoopProgressReporter", func() {
+		It("returns readers and writers as they are", func() {
+			reporter := NewNoopProgressReporter()
+			reader := bytes.NewReader(nil)
+
+			Expect(reporter.ProgressReader("fake-desc", 100, reader)).To(Equal(reader))
+			Expect(reporter.ProgressWriter("fake-desc", 100, ioutil.Discard)).To(Equal(ioutil.Discard))
+		})
+	})
+
+	Describe("ConfUI.ProgressReporter", func() {
+		var (
+			uiOut  *bytes.Buffer
+			confUI *ConfUI
+		)
+
+		BeforeEach(func() {
+			uiOut = bytes.NewBufferString("")
+			logger := boshlog.NewLogger(boshlog.LevelNone)
+			confUI = NewWriterConfUI(NewWriterUI(uiOut, bytes.NewBufferString(""), logger), logger)
+		})
+
+		It("does not report progress in JSON output", func() {
+			confUI.EnableJSON()
+
+			reporter := confUI.ProgressReporter(fakeClock)
+			Expect(reporter.ProgressWriter("fake-desc", 100, ioutil.Discard)).To(Equal(ioutil.Discard))
+		})
+
+		It("prints progress lines when output is not a terminal", func() {
+			reporter := confUI.ProgressReporter(fakeClock)
+			writer := reporter.ProgressWriter("fake-desc", 100, ioutil.Discard)
+
+			fakeClock.Increment(time.Minute)
+			write(writer, 10)
+
+			Expect(uiOut.String()).To(ContainSubstring("fake-desc: 10 B of 100 B (10%)"))
+		})
+	})
+})
@@ -20,6 +20,9 @@ type stage struct {
 	logTag string
 	logger boshlog.Logger
 
+	recorder StageEventRecorder
+	names    []string
+
 	simpleMode bool
 }
 
@@ -35,6 +38,21 @@ func NewStage(ui UI, timeService clock.Clock, logger boshlog.Logger) Stage {
 	}
 }
 
+// NewRecordingStage returns a Stage that also tells recorder about every stage, and nested stage, as it starts and ends
+func NewRecordingStage(ui UI, timeService clock.Clock, logger boshlog.Logger, recorder StageEventRecorder) Stage {
+	return &stage{
+		ui:          ui,
+		timeService: timeService,
+
+		logTag: "stage",
+		logger: logger,
+
+		recorder: recorder,
+
+		simpleMode: true,
+	}
+}
+
 func (s *stage) Perform(name string, closure func() error) error {
 	if !s.simpleMode {
 		// enter simple mode (only line break if exiting complex mode)
@@ -44,17 +62,21 @@ func (s *stage) Perform(name string, closure func() error) error {
 
 	s.ui.BeginLinef("%s...", name)
 	startTime := s.timeService.Now()
+	s.record(StageStarted, name, false, startTime, "")
 	err := closure()
 	if err != nil {
 		if skipErr, ok := err.(SkipStageError); ok {
 			s.ui.EndLinef(" Skipped [%s] (%s)", skipErr.SkipMessage(), s.elapsedSince(startTime))
 			s.logger.Info(s.logTag, "Skipped stage '%s': %s", name, skipErr.Error())
+			s.record(StageSkipped, name, false, startTime, skipErr.SkipMessage())
 			return nil
 		}
 		s.ui.EndLinef(" Failed (%s)", s.elapsedSince(startTime))
+		s.record(StageFailed, name, false, startTime, err.Error())
 		return err
 	}
 	s.ui.EndLinef(" Finished (%s)", s.elapsedSince(startTime))
+	s.record(StageFinished, name, false, startTime, "")
 	return nil
 }
 
@@ -65,12 +87,15 @@ func (s *stage) PerformComplex(name string, closure func(Stage) error) error {
 
 	s.ui.BeginLinef("Started %s\n", name)
 	startTime := s.timeService.Now()
-	err := closure(s.newSubStage())
+	s.record(StageStarted, name, true, startTime, "")
+	err := closure(s.newSubStage(name))
 	if err != nil {
 		s.ui.BeginLinef("Failed %s (%s)\n", name, s.elapsedSince(startTime))
+		s.record(StageFailed, name, true, startTime, err.Error())
 		return err
 	}
 	s.ui.BeginLinef("Finished %s (%s)\n", name, s.elapsedSince(startTime))
+	s.record(StageFinished, name, true, startTime, "")
 	return nil
 }
 
@@ -80,6 +105,28 @@ func (s *stage) elapsedSince(startTime time.Time) string {
 	return biuifmt.Duration(duration)
 }
 
-func (s *stage) newSubStage() Stage {
-	return NewStage(NewIndentingUI(s.ui), s.timeService, s.logger)
+func (s *stage) record(eventType string, name string, complex bool, startTime time.Time, message string) {
+	if s.recorder == nil {
+		return
+	}
+
+	event := StageEvent{
+		Time:    s.timeService.Now(),
+		Type:    eventType,
+		Stages:  append(append([]string{}, s.names...), name),
+		Complex: complex,
+		Message: message,
+	}
+
+	if eventType != StageStarted {
+		event.Duration = event.Time.Sub(startTime)
+	}
+
+	s.recorder.RecordStageEvent(event)
+}
+
+func (s *stage) newSubStage(name string) Stage {
+	subStage := NewRecordingStage(NewIndentingUI(s.ui), s.timeService, s.logger, s.recorder).(*stage)
+	subStage.names = append(append([]string{}, s.names...), name)
+	return subStage
 }
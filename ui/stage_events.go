@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+const (
+	StageStarted  = "started"
+	StageFinished = "finished"
+	StageFailed   = "failed"
+	StageSkipped  = "skipped"
+)
+
+// StageEvent is a stage starting or ending.
+// Stages holds the names of the enclosing stages followed by the name of the stage itself.
+type StageEvent struct {
+	Time     time.Time     `json:"time"`
+	Type     string        `json:"type"`
+	Stages   []string      `json:"stages"`
+	Complex  bool          `json:"complex,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+	Message  string        `json:"message,omitempty"`
+}
+
+type StageEventRecorder interface {
+	RecordStageEvent(StageEvent)
+}
+
+// ReplayStageEvents renders recorded stage events through a Stage,
+// printing what was shown while the stages originally ran.
+func ReplayStageEvents(ui UI, events []StageEvent, logger boshlog.Logger) {
+	replayClock := &stageReplayClock{}
+	stage := NewStage(ui, replayClock, logger)
+
+	for _, node := range newStageEventTree(events) {
+		node.replay(stage, replayClock)
+	}
+}
+
+type stageEventNode struct {
+	started  StageEvent
+	ended    *StageEvent
+	children []*stageEventNode
+}
+
+func newStageEventTree(events []StageEvent) []*stageEventNode {
+	var (
+		roots []*stageEventNode
+		open  []*stageEventNode
+	)
+
+	for i := range events {
+		event := events[i]
+		depth := len(event.Stages) - 1
+
+		if depth < 0 || len(open) < depth {
+			continue
+		}
+
+		if event.Type != StageStarted {
+			if len(open) > depth {
+				open[depth].ended = &event
+				open = open[:depth]
+			}
+			continue
+		}
+
+		// Stages that never ended, e.g. because the CLI was interrupted, are closed by the next stage at their depth
+		open = open[:depth]
+
+		node := &stageEventNode{started: event}
+
+		if depth == 0 {
+			roots = append(roots, node)
+		} else {
+			parent := open[depth-1]
+			parent.children = append(parent.children, node)
+		}
+
+		open = append(open, node)
+	}
+
+	return roots
+}
+
+func (n *stageEventNode) replay(stage Stage, replayClock *stageReplayClock) {
+	name := n.started.Stages[len(n.started.Stages)-1]
+
+	replayClock.now = n.started.Time
+
+	if n.started.Complex {
+		_ = stage.PerformComplex(name, func(subStage Stage) error {
+			for _, child := range n.children {
+				child.replay(subStage, replayClock)
+			}
+			return n.end(replayClock)
+		})
+		return
+	}
+
+	_ = stage.Perform(name, func() error {
+		return n.end(replayClock)
+	})
+}
+
+func (n *stageEventNode) end(replayClock *stageReplayClock) error {
+	if n.ended == nil {
+		replayClock.now = n.started.Time
+		return errors.New("Stage did not finish")
+	}
+
+	replayClock.now = n.started.Time.Add(n.ended.Duration)
+
+	switch n.ended.Type {
+	case StageFailed:
+		return errors.New(n.ended.Message)
+	case StageSkipped:
+		return NewSkipStageError(errors.New(n.ended.Message), n.ended.Message)
+	}
+
+	return nil
+}
+
+// stageReplayClock reports the recorded times of the stage being replayed
+type stageReplayClock struct {
+	clock.Clock
+	now time.Time
+}
+
+func (c *stageReplayClock) Now() time.Time {
+	return c.now
+}
@@ -0,0 +1,100 @@
+package ui_test
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	. "github.com/cloudfoundry/bosh-cli/ui"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+type fakeStageEventRecorder struct {
+	events []StageEvent
+}
+
+func (r *fakeStageEventRecorder) RecordStageEvent(event StageEvent) {
+	r.events = append(r.events, event)
+}
+
+var _ = Describe("Stage events", func() {
+	var (
+		logger          boshlog.Logger
+		fakeTimeService *fakeclock.FakeClock
+		recorder        *fakeStageEventRecorder
+
+		uiOut, uiErr *bytes.Buffer
+		ui           UI
+		stage        Stage
+	)
+
+	BeforeEach(func() {
+		logger = boshlog.NewLogger(boshlog.LevelNone)
+		fakeTimeService = fakeclock.NewFakeClock(time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC))
+		recorder = &fakeStageEventRecorder{}
+
+		uiOut = bytes.NewBufferString("")
+		uiErr = bytes.NewBufferString("")
+		ui = NewWriterUI(uiOut, uiErr, logger)
+
+		stage = NewRecordingStage(ui, fakeTimeService, logger, recorder)
+	})
+
+	performStages := func() {
+		_ = stage.PerformComplex("deploying", func(stage Stage) error {
+			_ = stage.Perform("Creating VM", func() error {
+				fakeTimeService.Increment(time.Minute)
+				return nil
+			})
+			_ = stage.Perform("Compiling", func() error {
+				return NewSkipStageError(errors.New("fake-skip-err"), "already compiled")
+			})
+			return stage.Perform("Starting jobs", func() error {
+				fakeTimeService.Increment(2 * time.Second)
+				return errors.New("fake-start-err")
+			})
+		})
+	}
+
+	It("records every stage as it starts and ends", func() {
+		performStages()
+
+		startTime := time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		Expect(recorder.events).To(Equal([]StageEvent{
+			{Time: startTime, Type: StageStarted, Stages: []string{"deploying"}, Complex: true},
+			{Time: startTime, Type: StageStarted, Stages: []string{"deploying", "Creating VM"}},
+			{Time: startTime.Add(time.Minute), Type: StageFinished, Stages: []string{"deploying", "Creating VM"}, Duration: time.Minute},
+			{Time: startTime.Add(time.Minute), Type: StageStarted, Stages: []string{"deploying", "Compiling"}},
+			{Time: startTime.Add(time.Minute), Type: StageSkipped, Stages: []string{"deploying", "Compiling"}, Message: "already compiled"},
+			{Time: startTime.Add(time.Minute), Type: StageStarted, Stages: []string{"deploying", "Starting jobs"}},
+			{Time: startTime.Add(62 * time.Second), Type: StageFailed, Stages: []string{"deploying", "Starting jobs"}, Duration: 2 * time.Second, Message: "fake-start-err"},
+			{Time: startTime.Add(62 * time.Second), Type: StageFailed, Stages: []string{"deploying"}, Complex: true, Duration: 62 * time.Second, Message: "fake-start-err"},
+		}))
+	})
+
+	It("replays recorded events with the same output", func() {
+		performStages()
+
+		originalOut := uiOut.String()
+		uiOut.Reset()
+
+		ReplayStageEvents(ui, recorder.events, logger)
+
+		Expect(uiOut.String()).To(Equal(originalOut))
+		Expect(originalOut).To(ContainSubstring("Creating VM... Finished (00:01:00)"))
+		Expect(originalOut).To(ContainSubstring("Failed deploying (00:01:02)"))
+	})
+
+	It("replays stages that never ended as failed", func() {
+		ReplayStageEvents(ui, []StageEvent{
+			{Time: time.Now(), Type: StageStarted, Stages: []string{"Creating VM"}},
+		}, logger)
+
+		Expect(uiOut.String()).To(Equal("Creating VM... Failed (00:00:00)\n"))
+	})
+})
@@ -36,7 +36,16 @@ func NewWriterUI(outWriter, errWriter io.Writer, logger boshlog.Logger) *WriterU
 }
 
 func (ui *WriterUI) IsTTY() bool {
-	file, ok := ui.outWriter.(*os.File)
+	return isTerminal(ui.outWriter)
+}
+
+// IsErrTTY reports whether errors are written to a terminal
+func (ui *WriterUI) IsErrTTY() bool {
+	return isTerminal(ui.errWriter)
+}
+
+func isTerminal(writer io.Writer) bool {
+	file, ok := writer.(*os.File)
 
 	return ok && isatty.IsTerminal(file.Fd())
 }
@@ -0,0 +1,59 @@
+package warnings
+
+import (
+	"fmt"
+	"sync"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+// Warning is a non-fatal problem noticed while validating or deploying,
+// e.g. a deprecated manifest key or a property whose value could not be checked.
+type Warning struct {
+	Source  string `json:"source"`
+	Message string `json:"message"`
+}
+
+// Warnings collects warnings from wherever they are raised so that they can be
+// shown together once a command finishes instead of scrolling by in its output.
+type Warnings interface {
+	Warn(source string, msg string, args ...interface{})
+	List() []Warning
+}
+
+type warnings struct {
+	logger boshlog.Logger
+	logTag string
+
+	list []Warning
+	lock sync.Mutex
+}
+
+func NewWarnings(logger boshlog.Logger) Warnings {
+	return &warnings{
+		logger: logger,
+		logTag: "warnings",
+		list:   []Warning{},
+	}
+}
+
+func (w *warnings) Warn(source string, msg string, args ...interface{}) {
+	warning := Warning{
+		Source:  source,
+		Message: fmt.Sprintf(msg, args...),
+	}
+
+	w.logger.Warn(w.logTag, "%s: %s", warning.Source, warning.Message)
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.list = append(w.list, warning)
+}
+
+func (w *warnings) List() []Warning {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return append([]Warning{}, w.list...)
+}
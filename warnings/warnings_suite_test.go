@@ -0,0 +1,12 @@
+package warnings_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestWarnings(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Warnings Suite")
+}
@@ -0,0 +1,43 @@
+package warnings_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+
+	. "github.com/cloudfoundry/bosh-cli/warnings"
+)
+
+var _ = Describe("Warnings", func() {
+	var (
+		warnings Warnings
+	)
+
+	BeforeEach(func() {
+		warnings = NewWarnings(boshlog.NewLogger(boshlog.LevelNone))
+	})
+
+	It("is empty initially", func() {
+		Expect(warnings.List()).To(BeEmpty())
+	})
+
+	It("lists warnings in the order they were raised", func() {
+		warnings.Warn("fake-source", "fake-message %d", 1)
+		warnings.Warn("other-source", "other-message")
+
+		Expect(warnings.List()).To(Equal([]Warning{
+			{Source: "fake-source", Message: "fake-message 1"},
+			{Source: "other-source", Message: "other-message"},
+		}))
+	})
+
+	It("does not let callers modify collected warnings", func() {
+		warnings.Warn("fake-source", "fake-message")
+
+		list := warnings.List()
+		list[0].Message = "modified"
+
+		Expect(warnings.List()[0].Message).To(Equal("fake-message"))
+	})
+})